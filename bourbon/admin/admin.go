@@ -0,0 +1,202 @@
+// Package admin generates CRUD pages for registered models, in the spirit of the
+// Django admin.
+package admin
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Options configures how a model appears in the admin. Field names are column
+// names or Go field names.
+type Options struct {
+	Name         string   // URL segment and permission name, default the table name
+	Label        string   // heading, default derived from Name
+	ListFields   []string // list page columns, default the first six columns
+	SearchFields []string // text columns matched by the search box
+	Filters      []string // columns filtered by exact value
+	Fields       []string // form fields, default every editable column
+	ReadOnly     []string // form fields shown but not editable
+	OrderBy      string   // default list order, e.g. "-created_at"; default newest first
+	PerPage      int      // rows per list page, default 25
+}
+
+// ModelAdmin is a model registered with the admin
+type ModelAdmin struct {
+	Name  string
+	Label string
+
+	model reflect.Type
+	opts  Options
+
+	schema   *schema.Schema
+	pk       *schema.Field
+	list     []*schema.Field
+	search   []*schema.Field
+	filters  []*schema.Field
+	form     []*schema.Field
+	readOnly map[string]bool
+}
+
+var (
+	models      []*ModelAdmin
+	modelsMutex sync.RWMutex
+)
+
+// Register adds a model to the admin. Registering the same model again replaces its options:
+//
+//	admin.Register(&User{}, admin.Options{
+//	    ListFields:   []string{"id", "email", "is_active", "created_at"},
+//	    SearchFields: []string{"email", "name"},
+//	    Filters:      []string{"is_active"},
+//	})
+func Register(model interface{}, opts ...Options) *ModelAdmin {
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	m := &ModelAdmin{model: t}
+	if len(opts) > 0 {
+		m.opts = opts[0]
+	}
+	m.Name = m.opts.Name
+	if m.Name == "" {
+		if tabler, ok := reflect.New(t).Interface().(schema.Tabler); ok {
+			m.Name = tabler.TableName()
+		} else {
+			m.Name = schema.NamingStrategy{}.TableName(t.Name())
+		}
+	}
+	m.Label = m.opts.Label
+	if m.Label == "" {
+		m.Label = humanize(m.Name)
+	}
+
+	modelsMutex.Lock()
+	defer modelsMutex.Unlock()
+	for i, existing := range models {
+		if existing.model == t {
+			models[i] = m
+			return m
+		}
+	}
+	models = append(models, m)
+	return m
+}
+
+// Models returns the registered models in registration order
+func Models() []*ModelAdmin {
+	modelsMutex.RLock()
+	defer modelsMutex.RUnlock()
+	return append([]*ModelAdmin(nil), models...)
+}
+
+// Permission returns the permission needed for action ("view", "add", "change"
+// or "delete") on the model, e.g. "admin.users.change"
+func (m *ModelAdmin) Permission(action string) string {
+	return "admin." + m.Name + "." + action
+}
+
+// bind parses the model's schema and resolves the configured fields
+func (m *ModelAdmin) bind(db *gorm.DB, cache *sync.Map) error {
+	s, err := schema.Parse(reflect.New(m.model).Interface(), cache, db.NamingStrategy)
+	if err != nil {
+		return fmt.Errorf("admin: %s: %w", m.Name, err)
+	}
+	if len(s.PrimaryFields) != 1 {
+		return fmt.Errorf("admin: %s needs exactly one primary key", m.Name)
+	}
+	m.schema = s
+	m.pk = s.PrimaryFields[0]
+
+	lookup := func(names []string) ([]*schema.Field, error) {
+		fields := make([]*schema.Field, 0, len(names))
+		for _, name := range names {
+			field := s.LookUpField(name)
+			if field == nil || field.DBName == "" {
+				return nil, fmt.Errorf("admin: %s has no column %q", m.Name, name)
+			}
+			fields = append(fields, field)
+		}
+		return fields, nil
+	}
+
+	var columns []*schema.Field
+	for _, field := range s.Fields {
+		if field.DBName != "" && field.Readable && !isDeletedAt(field) {
+			columns = append(columns, field)
+		}
+	}
+
+	if m.list, err = lookup(m.opts.ListFields); err != nil {
+		return err
+	}
+	if len(m.list) == 0 {
+		m.list = columns[:min(len(columns), 6)]
+	}
+	if m.search, err = lookup(m.opts.SearchFields); err != nil {
+		return err
+	}
+	if m.filters, err = lookup(m.opts.Filters); err != nil {
+		return err
+	}
+	if m.form, err = lookup(m.opts.Fields); err != nil {
+		return err
+	}
+	if len(m.form) == 0 {
+		for _, field := range columns {
+			if editable(field) {
+				m.form = append(m.form, field)
+			}
+		}
+	}
+	readOnly, err := lookup(m.opts.ReadOnly)
+	if err != nil {
+		return err
+	}
+	m.readOnly = make(map[string]bool)
+	for _, field := range readOnly {
+		m.readOnly[field.DBName] = true
+	}
+	for _, field := range m.form {
+		if _, ok := inputType(field.FieldType); !ok {
+			m.readOnly[field.DBName] = true
+		}
+	}
+	return nil
+}
+
+// editable reports whether a column belongs on the default form
+func editable(field *schema.Field) bool {
+	if field.PrimaryKey || field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 {
+		return false
+	}
+	if !field.Creatable && !field.Updatable {
+		return false
+	}
+	_, ok := inputType(field.FieldType)
+	return ok
+}
+
+func isDeletedAt(field *schema.Field) bool {
+	t := field.FieldType
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t == reflect.TypeOf(gorm.DeletedAt{})
+}
+
+// humanize turns "order_items" into "Order items"
+func humanize(name string) string {
+	name = strings.ReplaceAll(name, "_", " ")
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,161 @@
+package admin
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// datetimeLayout is the value format of <input type="datetime-local">
+const datetimeLayout = "2006-01-02T15:04"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// inputType returns the HTML input type for a field type, or false when the admin
+// cannot edit it
+func inputType(t reflect.Type) (string, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return "datetime-local", true
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "text", true
+	case reflect.Bool:
+		return "checkbox", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number", true
+	}
+	return "", false
+}
+
+// setValue parses raw into v. An empty value sets pointers to nil and other
+// non-string types to their zero value.
+func setValue(v reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+	if v.Kind() == reflect.Ptr {
+		if raw == "" {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		elem := reflect.New(v.Type().Elem())
+		if err := setValue(elem.Elem(), raw); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+	if raw == "" && v.Kind() != reflect.String {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	if v.Type() == timeType {
+		t, err := time.ParseInLocation(datetimeLayout, raw, time.Local)
+		if err != nil {
+			return fmt.Errorf("enter a date and time")
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			// Checkboxes post "on"
+			b = raw == "on"
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("enter a whole number")
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("enter a positive whole number")
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("enter a number")
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// formValue formats v for an input of the given type
+func formValue(v reflect.Value, input string) string {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+		return t.In(time.Local).Format(datetimeLayout)
+	}
+	if input == "checkbox" {
+		return ""
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// displayValue formats v for the list and read-only fields
+func displayValue(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch value := v.Interface().(type) {
+	case time.Time:
+		if value.IsZero() {
+			return ""
+		}
+		return value.Format("2006-01-02 15:04")
+	case bool:
+		if value {
+			return "Yes"
+		}
+		return "No"
+	case fmt.Stringer:
+		return value.String()
+	}
+	if v.Kind() == reflect.Struct || v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		return ""
+	}
+	s := fmt.Sprint(v.Interface())
+	if runes := []rune(s); len(runes) > 100 {
+		s = string(runes[:97]) + "..."
+	}
+	return s
+}
+
+func checked(v reflect.Value) bool {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+	return v.Kind() == reflect.Bool && v.Bool()
+}
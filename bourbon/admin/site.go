@@ -0,0 +1,601 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/auth"
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PermissionAccess is needed for every admin page. Model pages also need the model's
+// permission: "admin.<name>.view", ".add", ".change" or ".delete".
+const PermissionAccess = "admin.access"
+
+// AuthenticateFunc checks login credentials and returns the user ID
+type AuthenticateFunc func(ctx context.Context, username, password string) (uint, error)
+
+// Config configures the admin site
+type Config struct {
+	Prefix    string           // URL prefix, default "/admin"
+	Title     string           // site title, default "Administration"
+	Templates bourbon.Renderer // admin/<page>.html templates here replace the built-in ones
+
+	// Authorizer answers permission checks when the request has none attached,
+	// default auth.NewAuthorizer(db)
+	Authorizer *auth.Authorizer
+
+	// Authenticate enables the login page. Without it the admin relies on
+	// middleware that sets the user with auth.WithUserID.
+	Authenticate AuthenticateFunc
+	SecretKey    string        // signs session cookies and CSRF tokens
+	CookieName   string        // default "bourbon_admin"
+	SessionTTL   time.Duration // default 1 hour
+}
+
+// Site serves the admin pages for the registered models
+type Site struct {
+	db         *gorm.DB
+	cfg        Config
+	key        []byte
+	authorizer *auth.Authorizer
+	models     map[string]*ModelAdmin
+	order      []*ModelAdmin
+}
+
+// Mount registers the admin routes for every model registered so far:
+//
+//	GET       /admin                     models the user can view
+//	GET       /admin/{model}             list, with ?q= search, f_<column>= filters and ?o= ordering
+//	GET, POST /admin/{model}/new         create
+//	GET, POST /admin/{model}/{id}        edit
+//	GET, POST /admin/{model}/{id}/delete delete
+//	GET, POST /admin/login, POST /admin/logout
+func Mount(router *bourbon.Router, db *gorm.DB, cfg Config, middleware ...bourbon.MiddlewareFunc) (*Site, error) {
+	if cfg.Prefix == "" {
+		cfg.Prefix = "/admin"
+	}
+	cfg.Prefix = "/" + strings.Trim(cfg.Prefix, "/")
+	if cfg.Title == "" {
+		cfg.Title = "Administration"
+	}
+	if cfg.CookieName == "" {
+		cfg.CookieName = "bourbon_admin"
+	}
+	if cfg.SessionTTL <= 0 {
+		cfg.SessionTTL = time.Hour
+	}
+
+	s := &Site{db: db, cfg: cfg, authorizer: cfg.Authorizer, models: make(map[string]*ModelAdmin)}
+	if s.authorizer == nil {
+		s.authorizer = auth.NewAuthorizer(db)
+	}
+	s.key = []byte(cfg.SecretKey)
+	if len(s.key) == 0 {
+		// Sessions will not survive a restart
+		s.key = make([]byte, 32)
+		if _, err := rand.Read(s.key); err != nil {
+			return nil, err
+		}
+	}
+
+	cache := &sync.Map{}
+	for _, m := range Models() {
+		if err := m.bind(db, cache); err != nil {
+			return nil, err
+		}
+		if _, exists := s.models[m.Name]; exists {
+			return nil, fmt.Errorf("admin: two models are named %q", m.Name)
+		}
+		s.models[m.Name] = m
+		s.order = append(s.order, m)
+	}
+
+	group := router.Group(cfg.Prefix, middleware...)
+	if cfg.Authenticate != nil {
+		group.Get("/login", s.loginForm)
+		group.Post("/login", s.login)
+	}
+	group.Use(s.requireAccess)
+	group.Get("", s.index)
+	group.Post("/logout", s.logout)
+	group.Get("/{model}", s.list)
+	group.Get("/{model}/new", s.createForm)
+	group.Post("/{model}/new", s.save)
+	group.Get("/{model}/{id}", s.editForm)
+	group.Post("/{model}/{id}", s.save)
+	group.Get("/{model}/{id}/delete", s.deleteForm)
+	group.Post("/{model}/{id}/delete", s.delete)
+	return s, nil
+}
+
+// Page is the data passed to the admin templates
+type Page struct {
+	Title     string
+	Prefix    string
+	UserID    uint
+	CSRFToken string
+	CanLogout bool
+	Models    []*ModelAdmin // models the user can view
+	Model     *ModelAdmin
+	Notice    string
+	Error     string
+
+	// List page
+	Columns []Column
+	Rows    []Row
+	Total   int64
+	Query   string
+	Filters []Filter
+	CanAdd  bool
+	PrevURL string
+	NextURL string
+
+	// Form and delete pages
+	RecordID  string
+	Record    string
+	Fields    []FormField
+	CanChange bool
+	CanDelete bool
+
+	// Login page
+	Next     string
+	Username string
+}
+
+// Column is a list page column; URL sorts the list by it
+type Column struct {
+	Name  string
+	Label string
+	URL   string
+}
+
+// Row is a list page row
+type Row struct {
+	ID    string
+	Cells []string
+}
+
+// Filter is a list page filter
+type Filter struct {
+	Name    string // query parameter
+	Label   string
+	Value   string
+	Choices []string // for booleans; free text otherwise
+}
+
+// FormField is a form input
+type FormField struct {
+	Name     string
+	Label    string
+	Input    string // HTML input type
+	Value    string
+	Checked  bool
+	ReadOnly bool
+	Error    string
+}
+
+var notices = map[string]string{
+	"created": "The record was created.",
+	"saved":   "The record was saved.",
+	"deleted": "The record was deleted.",
+}
+
+func (s *Site) page(c *bourbon.Context) *Page {
+	p := &Page{Title: s.cfg.Title, Prefix: s.cfg.Prefix, CanLogout: s.cfg.Authenticate != nil, Notice: notices[c.Query("notice")]}
+	if userID, ok := auth.UserIDFromContext(c.Request.Context()); ok {
+		p.UserID = userID
+		p.CSRFToken = s.csrfToken(c.Request, userID)
+		for _, m := range s.order {
+			if auth.Can(c.Request.Context(), m.Permission("view")) {
+				p.Models = append(p.Models, m)
+			}
+		}
+	}
+	return p
+}
+
+// render writes an admin page, preferring admin/<name>.html from the template engine
+func (s *Site) render(c *bourbon.Context, status int, name string, p *Page) error {
+	if s.cfg.Templates != nil && bourbon.HasTemplate(s.cfg.Templates, "admin/"+name+".html") {
+		html, err := s.cfg.Templates.Render("admin/"+name+".html", p)
+		if err != nil {
+			return err
+		}
+		return c.HTML(status, html)
+	}
+	var buf bytes.Buffer
+	if err := defaultTemplates.ExecuteTemplate(&buf, name, p); err != nil {
+		return err
+	}
+	return c.HTML(status, buf.String())
+}
+
+// requireAccess resolves the user from the session cookie, checks PermissionAccess
+// and verifies the CSRF token of POST requests
+func (s *Site) requireAccess(next bourbon.HandlerFunc) bourbon.HandlerFunc {
+	return func(c *bourbon.Context) error {
+		ctx := c.Request.Context()
+		if _, ok := auth.AuthorizerFromContext(ctx); !ok {
+			ctx = auth.WithAuthorizer(ctx, s.authorizer)
+		}
+		userID, ok := auth.UserIDFromContext(ctx)
+		if !ok && s.cfg.Authenticate != nil {
+			if userID, ok = s.sessionUser(c.Request); ok {
+				ctx = auth.WithUserID(ctx, userID)
+			}
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		if !ok {
+			if s.cfg.Authenticate != nil {
+				return c.Redirect(http.StatusSeeOther, s.cfg.Prefix+"/login?next="+url.QueryEscape(c.Request.URL.RequestURI()))
+			}
+			return c.String(http.StatusUnauthorized, "Unauthorized")
+		}
+		if allowed, err := auth.Check(ctx, PermissionAccess); err != nil {
+			return err
+		} else if !allowed {
+			return c.String(http.StatusForbidden, "Forbidden")
+		}
+		if c.Request.Method == http.MethodPost && !s.validCSRF(c.Request, userID) {
+			return c.String(http.StatusForbidden, "Invalid or missing CSRF token")
+		}
+		return next(c)
+	}
+}
+
+// model returns the model named in the URL if the user holds the action's permission
+func (s *Site) model(c *bourbon.Context, action string) (*ModelAdmin, error) {
+	m, ok := s.models[c.Param("model")]
+	if !ok {
+		return nil, c.String(http.StatusNotFound, "Not Found")
+	}
+	if allowed, err := auth.Check(c.Request.Context(), m.Permission(action)); err != nil {
+		return nil, err
+	} else if !allowed {
+		return nil, c.String(http.StatusForbidden, "Forbidden")
+	}
+	return m, nil
+}
+
+func (s *Site) index(c *bourbon.Context) error {
+	return s.render(c, http.StatusOK, "index", s.page(c))
+}
+
+func (s *Site) loginForm(c *bourbon.Context) error {
+	p := s.page(c)
+	p.Next = c.Query("next")
+	return s.render(c, http.StatusOK, "login", p)
+}
+
+func (s *Site) login(c *bourbon.Context) error {
+	username, password := c.FormValue("username"), c.FormValue("password")
+	userID, err := s.cfg.Authenticate(c.Request.Context(), username, password)
+	if err != nil {
+		p := s.page(c)
+		p.Next = c.FormValue("next")
+		p.Username = username
+		p.Error = "Invalid username or password."
+		return s.render(c, http.StatusUnauthorized, "login", p)
+	}
+	s.setSession(c.Writer, c.Request, userID)
+
+	next := c.FormValue("next")
+	if !strings.HasPrefix(next, s.cfg.Prefix) || strings.HasPrefix(next, "//") {
+		next = s.cfg.Prefix
+	}
+	return c.Redirect(http.StatusSeeOther, next)
+}
+
+func (s *Site) logout(c *bourbon.Context) error {
+	s.clearSession(c.Writer)
+	if s.cfg.Authenticate != nil {
+		return c.Redirect(http.StatusSeeOther, s.cfg.Prefix+"/login")
+	}
+	return c.Redirect(http.StatusSeeOther, s.cfg.Prefix)
+}
+
+func (s *Site) list(c *bourbon.Context) error {
+	m, err := s.model(c, "view")
+	if m == nil {
+		return err
+	}
+	ctx := c.Request.Context()
+	p := s.page(c)
+	p.Model = m
+	p.CanAdd = auth.Can(ctx, m.Permission("add"))
+	p.Query = strings.TrimSpace(c.Query("q"))
+
+	db := s.db.WithContext(ctx).Model(reflect.New(m.model).Interface())
+	if p.Query != "" && len(m.search) > 0 {
+		var conditions []clause.Expression
+		for _, field := range m.search {
+			conditions = append(conditions, clause.Like{Column: clause.Column{Name: field.DBName}, Value: "%" + p.Query + "%"})
+		}
+		db = db.Where(clause.Or(conditions...))
+	}
+
+	params := url.Values{}
+	if p.Query != "" {
+		params.Set("q", p.Query)
+	}
+	for _, field := range m.filters {
+		name := "f_" + field.DBName
+		filter := Filter{Name: name, Label: humanize(field.DBName), Value: c.Query(name)}
+		if input, _ := inputType(field.FieldType); input == "checkbox" {
+			filter.Choices = []string{"true", "false"}
+		}
+		p.Filters = append(p.Filters, filter)
+		if filter.Value == "" {
+			continue
+		}
+		params.Set(name, filter.Value)
+		value := reflect.New(field.FieldType).Elem()
+		if err := setValue(value, filter.Value); err != nil {
+			p.Error = fmt.Sprintf("%s: %s", filter.Label, err)
+			continue
+		}
+		db = db.Where(clause.Eq{Column: clause.Column{Name: field.DBName}, Value: value.Interface()})
+	}
+
+	if err := db.Count(&p.Total).Error; err != nil {
+		return err
+	}
+
+	order := c.Query("o", m.opts.OrderBy)
+	orderField, desc := m.pk, true
+	if order != "" {
+		name := strings.TrimPrefix(order, "-")
+		if field := m.schema.LookUpField(name); field != nil && field.DBName != "" {
+			orderField, desc = field, strings.HasPrefix(order, "-")
+		}
+	}
+	if c.Query("o") != "" {
+		params.Set("o", c.Query("o"))
+	}
+
+	perPage := m.opts.PerPage
+	if perPage <= 0 {
+		perPage = 25
+	}
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+
+	records := reflect.New(reflect.SliceOf(m.model))
+	err = db.Order(clause.OrderByColumn{Column: clause.Column{Name: orderField.DBName}, Desc: desc}).
+		Limit(perPage).Offset((page - 1) * perPage).
+		Find(records.Interface()).Error
+	if err != nil {
+		return err
+	}
+
+	listURL := func(change func(q url.Values)) string {
+		q := url.Values{}
+		for k, v := range params {
+			q[k] = v
+		}
+		change(q)
+		if len(q) == 0 {
+			return s.cfg.Prefix + "/" + m.Name
+		}
+		return s.cfg.Prefix + "/" + m.Name + "?" + q.Encode()
+	}
+	for _, field := range m.list {
+		sort := field.DBName
+		if orderField == field && !desc {
+			sort = "-" + sort
+		}
+		p.Columns = append(p.Columns, Column{
+			Name:  field.DBName,
+			Label: humanize(field.DBName),
+			URL: listURL(func(q url.Values) {
+				q.Set("o", sort)
+				q.Del("page")
+			}),
+		})
+	}
+	rows := records.Elem()
+	for i := 0; i < rows.Len(); i++ {
+		record := rows.Index(i)
+		row := Row{ID: displayValue(m.pk.ReflectValueOf(ctx, record))}
+		for _, field := range m.list {
+			row.Cells = append(row.Cells, displayValue(field.ReflectValueOf(ctx, record)))
+		}
+		p.Rows = append(p.Rows, row)
+	}
+	if page > 1 {
+		p.PrevURL = listURL(func(q url.Values) { q.Set("page", strconv.Itoa(page-1)) })
+	}
+	if int64(page*perPage) < p.Total {
+		p.NextURL = listURL(func(q url.Values) { q.Set("page", strconv.Itoa(page+1)) })
+	}
+	return s.render(c, http.StatusOK, "list", p)
+}
+
+// find loads the record named in the URL into a new model value
+func (s *Site) find(c *bourbon.Context, m *ModelAdmin) (reflect.Value, error) {
+	record := reflect.New(m.model)
+	id := reflect.New(m.pk.FieldType).Elem()
+	if err := setValue(id, c.Param("id")); err != nil {
+		return record, gorm.ErrRecordNotFound
+	}
+	err := s.db.WithContext(c.Request.Context()).
+		Where(clause.Eq{Column: clause.Column{Name: m.pk.DBName}, Value: id.Interface()}).
+		First(record.Interface()).Error
+	return record, err
+}
+
+// formPage fills the form fields of m from record
+func (s *Site) formPage(c *bourbon.Context, m *ModelAdmin, record reflect.Value, errs map[string]string) *Page {
+	ctx := c.Request.Context()
+	p := s.page(c)
+	p.Model = m
+	p.CanChange = auth.Can(ctx, m.Permission("change"))
+	p.CanDelete = auth.Can(ctx, m.Permission("delete"))
+	if id := m.pk.ReflectValueOf(ctx, record.Elem()); !id.IsZero() {
+		p.RecordID = displayValue(id)
+		p.Record = m.Label + " " + p.RecordID
+	}
+	for _, field := range m.form {
+		value := field.ReflectValueOf(ctx, record.Elem())
+		input, _ := inputType(field.FieldType)
+		f := FormField{
+			Name:     field.DBName,
+			Label:    humanize(field.DBName),
+			Input:    input,
+			Value:    formValue(value, input),
+			Checked:  checked(value),
+			ReadOnly: m.readOnly[field.DBName] || p.RecordID != "" && !p.CanChange,
+			Error:    errs[field.DBName],
+		}
+		if f.ReadOnly {
+			f.Value = displayValue(value)
+		}
+		p.Fields = append(p.Fields, f)
+	}
+	return p
+}
+
+func (s *Site) createForm(c *bourbon.Context) error {
+	m, err := s.model(c, "add")
+	if m == nil {
+		return err
+	}
+	return s.render(c, http.StatusOK, "form", s.formPage(c, m, reflect.New(m.model), nil))
+}
+
+func (s *Site) editForm(c *bourbon.Context) error {
+	m, err := s.model(c, "view")
+	if m == nil {
+		return err
+	}
+	record, err := s.find(c, m)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.String(http.StatusNotFound, "Not Found")
+	}
+	if err != nil {
+		return err
+	}
+	return s.render(c, http.StatusOK, "form", s.formPage(c, m, record, nil))
+}
+
+// save creates a record (POST /{model}/new) or updates one (POST /{model}/{id})
+func (s *Site) save(c *bourbon.Context) error {
+	creating := c.Param("id") == ""
+	action := "change"
+	if creating {
+		action = "add"
+	}
+	m, err := s.model(c, action)
+	if m == nil {
+		return err
+	}
+
+	ctx := c.Request.Context()
+	record := reflect.New(m.model)
+	if !creating {
+		record, err = s.find(c, m)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.String(http.StatusNotFound, "Not Found")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := c.Request.ParseForm(); err != nil {
+		return c.String(http.StatusBadRequest, "Bad Request")
+	}
+	errs := make(map[string]string)
+	var columns []string
+	for _, field := range m.form {
+		if m.readOnly[field.DBName] {
+			continue
+		}
+		raw := c.Request.PostForm.Get(field.DBName)
+		if input, _ := inputType(field.FieldType); input == "checkbox" {
+			raw = strconv.FormatBool(c.Request.PostForm.Has(field.DBName))
+		}
+		if err := setValue(field.ReflectValueOf(ctx, record.Elem()), raw); err != nil {
+			errs[field.DBName] = err.Error()
+		}
+		columns = append(columns, field.DBName)
+	}
+
+	status := http.StatusUnprocessableEntity
+	if len(errs) == 0 {
+		db := s.db.WithContext(ctx)
+		if creating {
+			err = db.Create(record.Interface()).Error
+		} else {
+			err = db.Model(record.Interface()).Select(columns).Updates(record.Interface()).Error
+		}
+		if err == nil {
+			notice := "saved"
+			if creating {
+				notice = "created"
+			}
+			return c.Redirect(http.StatusSeeOther, s.cfg.Prefix+"/"+m.Name+"?notice="+notice)
+		}
+		status = http.StatusBadRequest
+	}
+
+	p := s.formPage(c, m, record, errs)
+	if creating {
+		p.RecordID, p.Record = "", ""
+	}
+	if err != nil {
+		p.Error = err.Error()
+	} else {
+		p.Error = "Please correct the errors below."
+	}
+	return s.render(c, status, "form", p)
+}
+
+func (s *Site) deleteForm(c *bourbon.Context) error {
+	m, err := s.model(c, "delete")
+	if m == nil {
+		return err
+	}
+	record, err := s.find(c, m)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.String(http.StatusNotFound, "Not Found")
+	}
+	if err != nil {
+		return err
+	}
+	return s.render(c, http.StatusOK, "delete", s.formPage(c, m, record, nil))
+}
+
+func (s *Site) delete(c *bourbon.Context) error {
+	m, err := s.model(c, "delete")
+	if m == nil {
+		return err
+	}
+	record, err := s.find(c, m)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.String(http.StatusNotFound, "Not Found")
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.db.WithContext(c.Request.Context()).Delete(record.Interface()).Error; err != nil {
+		return err
+	}
+	return c.Redirect(http.StatusSeeOther, s.cfg.Prefix+"/"+m.Name+"?notice=deleted")
+}
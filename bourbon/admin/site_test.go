@@ -0,0 +1,299 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/auth"
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type article struct {
+	ID        uint
+	Title     string
+	Views     int
+	Published bool
+}
+
+const (
+	editorID = 1 // may do anything with articles
+	viewerID = 2 // may only view articles
+	guestID  = 3 // may not open the admin
+)
+
+// newTestSite mounts an admin for articles. Requests name their user in the
+// X-User header, like an app's own authentication middleware would.
+func newTestSite(t *testing.T) (*bourbon.Router, *Site, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "admin.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&article{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	authorizer := auth.NewAuthorizer(db)
+	grants := map[string][]string{
+		"editor": {PermissionAccess, "admin.articles.*"},
+		"viewer": {PermissionAccess, "admin.articles.view"},
+	}
+	for role, permissions := range grants {
+		if _, err := authorizer.CreateRole(role, ""); err != nil {
+			t.Fatal(err)
+		}
+		for _, permission := range permissions {
+			if err := authorizer.Grant(role, permission); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := authorizer.AssignRole(editorID, "editor"); err != nil {
+		t.Fatal(err)
+	}
+	if err := authorizer.AssignRole(viewerID, "viewer"); err != nil {
+		t.Fatal(err)
+	}
+
+	modelsMutex.Lock()
+	saved := models
+	models = nil
+	modelsMutex.Unlock()
+	t.Cleanup(func() {
+		modelsMutex.Lock()
+		models = saved
+		modelsMutex.Unlock()
+	})
+	Register(&article{}, Options{SearchFields: []string{"title"}})
+
+	router := bourbon.NewRouter()
+	user := func(next bourbon.HandlerFunc) bourbon.HandlerFunc {
+		return func(c *bourbon.Context) error {
+			if id, err := strconv.Atoi(c.Request.Header.Get("X-User")); err == nil {
+				c.Request = c.Request.WithContext(auth.WithUserID(c.Request.Context(), uint(id)))
+			}
+			return next(c)
+		}
+	}
+	site, err := Mount(router, db, Config{Authorizer: authorizer, SecretKey: "test"}, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return router, site, db
+}
+
+func adminGet(router http.Handler, target string, userID uint) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if userID != 0 {
+		req.Header.Set("X-User", strconv.Itoa(int(userID)))
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// adminPost submits a form with a valid CSRF token for the user
+func adminPost(router http.Handler, site *Site, target string, userID uint, form url.Values) *httptest.ResponseRecorder {
+	if userID != 0 {
+		form.Set("csrf_token", site.csrfToken(httptest.NewRequest(http.MethodGet, target, nil), userID))
+	}
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if userID != 0 {
+		req.Header.Set("X-User", strconv.Itoa(int(userID)))
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdminAccess(t *testing.T) {
+	router, _, db := newTestSite(t)
+	db.Create(&article{Title: "Hello"})
+
+	tests := []struct {
+		name   string
+		target string
+		userID uint
+		want   int
+	}{
+		{"unauthenticated index", "/admin", 0, http.StatusUnauthorized},
+		{"unauthenticated list", "/admin/articles", 0, http.StatusUnauthorized},
+		{"without admin access", "/admin", guestID, http.StatusForbidden},
+		{"index", "/admin", viewerID, http.StatusOK},
+		{"list", "/admin/articles", viewerID, http.StatusOK},
+		{"edit form is viewable", "/admin/articles/1", viewerID, http.StatusOK},
+		{"create needs add", "/admin/articles/new", viewerID, http.StatusForbidden},
+		{"delete needs delete", "/admin/articles/1/delete", viewerID, http.StatusForbidden},
+		{"unknown model", "/admin/widgets", editorID, http.StatusNotFound},
+		{"unknown record", "/admin/articles/99", editorID, http.StatusNotFound},
+		{"invalid id", "/admin/articles/abc", editorID, http.StatusNotFound},
+		{"create form", "/admin/articles/new", editorID, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := adminGet(router, tt.target, tt.userID); rec.Code != tt.want {
+				t.Errorf("GET %s = %d, want %d", tt.target, rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdminLoginRedirect(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "admin.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+
+	router := bourbon.NewRouter()
+	_, err = Mount(router, db, Config{
+		SecretKey:    "test",
+		Authenticate: func(ctx context.Context, username, password string) (uint, error) { return 0, errors.New("no") },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := adminGet(router, "/admin/articles?q=x", 0)
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/admin/login?next="+url.QueryEscape("/admin/articles?q=x") {
+		t.Errorf("GET = %d Location %q, want a redirect to the login page", rec.Code, rec.Header().Get("Location"))
+	}
+	if rec := adminGet(router, "/admin/login", 0); rec.Code != http.StatusOK {
+		t.Errorf("GET /admin/login = %d", rec.Code)
+	}
+}
+
+func TestAdminCreate(t *testing.T) {
+	router, site, db := newTestSite(t)
+
+	rec := adminPost(router, site, "/admin/articles/new", editorID, url.Values{"title": {"Hello"}, "views": {"many"}})
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "enter a whole number") {
+		t.Fatalf("invalid create = %d, want %d with the field error:\n%s", rec.Code, http.StatusUnprocessableEntity, rec.Body.String())
+	}
+	var count int64
+	db.Model(&article{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("an invalid form created %d records", count)
+	}
+
+	rec = adminPost(router, site, "/admin/articles/new", editorID, url.Values{"title": {"Hello"}, "views": {"3"}, "published": {"on"}})
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/admin/articles?notice=created" {
+		t.Fatalf("create = %d Location %q", rec.Code, rec.Header().Get("Location"))
+	}
+	var created article
+	if err := db.First(&created).Error; err != nil {
+		t.Fatal(err)
+	}
+	if created.Title != "Hello" || created.Views != 3 || !created.Published {
+		t.Errorf("created %+v", created)
+	}
+
+	if rec := adminPost(router, site, "/admin/articles/new", viewerID, url.Values{"title": {"x"}}); rec.Code != http.StatusForbidden {
+		t.Errorf("create without add permission = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestAdminUpdate(t *testing.T) {
+	router, site, db := newTestSite(t)
+	db.Create(&article{Title: "Hello", Views: 3, Published: true})
+
+	rec := adminPost(router, site, "/admin/articles/1", editorID, url.Values{"title": {"Changed"}, "views": {"1.5"}})
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("invalid update = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	var stored article
+	db.First(&stored, 1)
+	if stored.Title != "Hello" {
+		t.Fatalf("an invalid form saved %+v", stored)
+	}
+
+	// An unchecked checkbox is not posted and clears the column
+	rec = adminPost(router, site, "/admin/articles/1", editorID, url.Values{"title": {"Changed"}, "views": {"4"}})
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/admin/articles?notice=saved" {
+		t.Fatalf("update = %d Location %q", rec.Code, rec.Header().Get("Location"))
+	}
+	db.First(&stored, 1)
+	if stored.Title != "Changed" || stored.Views != 4 || stored.Published {
+		t.Errorf("updated %+v", stored)
+	}
+
+	if rec := adminPost(router, site, "/admin/articles/1", viewerID, url.Values{"title": {"x"}}); rec.Code != http.StatusForbidden {
+		t.Errorf("update without change permission = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	if rec := adminPost(router, site, "/admin/articles/99", editorID, url.Values{"title": {"x"}}); rec.Code != http.StatusNotFound {
+		t.Errorf("update of a missing record = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestAdminCSRF(t *testing.T) {
+	router, _, db := newTestSite(t)
+
+	form := url.Values{"title": {"Hello"}, "csrf_token": {"forged"}}
+	req := httptest.NewRequest(http.MethodPost, "/admin/articles/new", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-User", strconv.Itoa(editorID))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("POST with a forged token = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	var count int64
+	db.Model(&article{}).Count(&count)
+	if count != 0 {
+		t.Errorf("a forged form created %d records", count)
+	}
+}
+
+func TestAdminDelete(t *testing.T) {
+	router, site, db := newTestSite(t)
+	db.Create(&article{Title: "Hello"})
+
+	if rec := adminPost(router, site, "/admin/articles/1/delete", viewerID, url.Values{}); rec.Code != http.StatusForbidden {
+		t.Errorf("delete without permission = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	if rec := adminGet(router, "/admin/articles/1/delete", editorID); rec.Code != http.StatusOK {
+		t.Errorf("delete confirmation = %d", rec.Code)
+	}
+	rec := adminPost(router, site, "/admin/articles/1/delete", editorID, url.Values{})
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/admin/articles?notice=deleted" {
+		t.Fatalf("delete = %d Location %q", rec.Code, rec.Header().Get("Location"))
+	}
+	var count int64
+	db.Model(&article{}).Count(&count)
+	if count != 0 {
+		t.Errorf("%d records left after delete", count)
+	}
+	if rec := adminPost(router, site, "/admin/articles/1/delete", editorID, url.Values{}); rec.Code != http.StatusNotFound {
+		t.Errorf("deleting again = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestAdminList(t *testing.T) {
+	router, _, db := newTestSite(t)
+	db.Create(&[]article{{Title: "Go tips"}, {Title: "Rust notes"}, {Title: "More Go"}})
+
+	rec := adminGet(router, "/admin/articles?q=Go", viewerID)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list = %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "Go tips") || !strings.Contains(body, "More Go") || strings.Contains(body, "Rust notes") {
+		t.Errorf("search for Go listed:\n%s", body)
+	}
+}
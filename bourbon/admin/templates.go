@@ -0,0 +1,140 @@
+package admin
+
+import "html/template"
+
+// defaultTemplates are used for pages without an admin/<page>.html override
+var defaultTemplates = template.Must(template.New("admin").Parse(`
+{{define "head"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{if .Model}}{{.Model.Label}} | {{end}}{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 0; color: #222; background: #f7f7f7; }
+header { background: #2d3748; color: #fff; padding: 12px 32px; display: flex; align-items: center; gap: 24px; }
+header a { color: #fff; text-decoration: none; }
+header .title { font-weight: 600; font-size: 17px; }
+header form { margin-left: auto; }
+header button { background: none; border: 1px solid #a0aec0; color: #fff; padding: 3px 10px; cursor: pointer; }
+.layout { display: flex; }
+nav { width: 200px; padding: 16px 0 16px 32px; }
+nav a { display: block; padding: 4px 0; color: #2b6cb0; text-decoration: none; }
+main { flex: 1; padding: 16px 32px; }
+h1 { font-size: 22px; font-weight: 500; }
+table { border-collapse: collapse; width: 100%; font-size: 14px; background: #fff; }
+th, td { padding: 7px 10px; border-bottom: 1px solid #eee; text-align: left; }
+th { background: #edf2f7; }
+th a { color: #222; }
+a { color: #2b6cb0; }
+.toolbar { display: flex; gap: 8px; align-items: center; margin-bottom: 12px; flex-wrap: wrap; }
+.toolbar .add { margin-left: auto; }
+.notice { background: #c6f6d5; padding: 8px 12px; margin-bottom: 12px; }
+.error { background: #fed7d7; padding: 8px 12px; margin-bottom: 12px; }
+.field { margin-bottom: 12px; }
+.field label { display: block; font-weight: 600; margin-bottom: 4px; font-size: 14px; }
+.field input[type=text], .field input[type=number], .field input[type=datetime-local], .field input[type=password] { width: 100%; max-width: 420px; padding: 6px; }
+.field .message { color: #c53030; font-size: 13px; }
+.button { background: #2b6cb0; color: #fff; border: 0; padding: 7px 16px; cursor: pointer; text-decoration: none; display: inline-block; }
+.danger { background: #c53030; }
+.pager a { margin-right: 12px; }
+.login { max-width: 360px; margin: 60px auto; background: #fff; padding: 24px 32px; }
+</style>
+</head>
+<body>
+<header>
+<a class="title" href="{{.Prefix}}">{{.Title}}</a>
+{{if and .UserID .CanLogout}}<form method="post" action="{{.Prefix}}/logout"><input type="hidden" name="csrf_token" value="{{.CSRFToken}}"><button type="submit">Log out</button></form>{{end}}
+</header>
+<div class="layout">
+<nav>{{range .Models}}<a href="{{$.Prefix}}/{{.Name}}">{{.Label}}</a>{{end}}</nav>
+<main>
+{{with .Notice}}<p class="notice">{{.}}</p>{{end}}
+{{with .Error}}<p class="error">{{.}}</p>{{end}}{{end}}
+
+{{define "foot"}}</main>
+</div>
+</body>
+</html>{{end}}
+
+{{define "index"}}{{template "head" .}}
+<h1>Site administration</h1>
+{{if .Models}}
+<table>
+{{range .Models}}<tr><td><a href="{{$.Prefix}}/{{.Name}}">{{.Label}}</a></td></tr>{{end}}
+</table>
+{{else}}<p>You do not have permission to view any models.</p>{{end}}
+{{template "foot"}}{{end}}
+
+{{define "list"}}{{template "head" .}}
+<h1>{{.Model.Label}}</h1>
+<form class="toolbar" method="get" action="{{.Prefix}}/{{.Model.Name}}">
+<input name="q" value="{{.Query}}" placeholder="Search">
+{{range .Filters}}
+{{if .Choices}}<select name="{{.Name}}"><option value="">{{.Label}}: all</option>{{$f := .}}{{range .Choices}}<option value="{{.}}"{{if eq . $f.Value}} selected{{end}}>{{$f.Label}}: {{.}}</option>{{end}}</select>
+{{else}}<input name="{{.Name}}" value="{{.Value}}" placeholder="{{.Label}}">{{end}}
+{{end}}
+<button type="submit">Filter</button>
+{{if .CanAdd}}<a class="button add" href="{{.Prefix}}/{{.Model.Name}}/new">Add</a>{{end}}
+</form>
+<p>{{.Total}} records</p>
+<table>
+<tr>{{range .Columns}}<th><a href="{{.URL}}">{{.Label}}</a></th>{{end}}</tr>
+{{range .Rows}}{{$id := .ID}}
+<tr>{{range $i, $cell := .Cells}}<td>{{if eq $i 0}}<a href="{{$.Prefix}}/{{$.Model.Name}}/{{$id}}">{{if $cell}}{{$cell}}{{else}}#{{$id}}{{end}}</a>{{else}}{{$cell}}{{end}}</td>{{end}}</tr>
+{{end}}
+</table>
+<p class="pager">{{with .PrevURL}}<a href="{{.}}">&larr; Previous</a>{{end}}{{with .NextURL}}<a href="{{.}}">Next &rarr;</a>{{end}}</p>
+{{template "foot"}}{{end}}
+
+{{define "form"}}{{template "head" .}}
+<h1>{{if .RecordID}}{{.Record}}{{else}}Add {{.Model.Label}}{{end}}</h1>
+<form method="post" action="{{.Prefix}}/{{.Model.Name}}/{{if .RecordID}}{{.RecordID}}{{else}}new{{end}}">
+<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+{{range .Fields}}
+<div class="field">
+<label for="f-{{.Name}}">{{.Label}}</label>
+{{if .ReadOnly}}<div>{{.Value}}</div>
+{{else if eq .Input "checkbox"}}<input id="f-{{.Name}}" type="checkbox" name="{{.Name}}"{{if .Checked}} checked{{end}}>
+{{else}}<input id="f-{{.Name}}" type="{{.Input}}" name="{{.Name}}" value="{{.Value}}"{{if eq .Input "number"}} step="any"{{end}}>{{end}}
+{{with .Error}}<div class="message">{{.}}</div>{{end}}
+</div>
+{{end}}
+{{if or (not .RecordID) .CanChange}}<button class="button" type="submit">Save</button>{{end}}
+{{if and .RecordID .CanDelete}}<a class="button danger" href="{{.Prefix}}/{{.Model.Name}}/{{.RecordID}}/delete">Delete</a>{{end}}
+</form>
+{{template "foot"}}{{end}}
+
+{{define "delete"}}{{template "head" .}}
+<h1>Delete {{.Record}}?</h1>
+<table>{{range .Fields}}<tr><th>{{.Label}}</th><td>{{if eq .Input "checkbox"}}{{if .Checked}}Yes{{else}}No{{end}}{{else}}{{.Value}}{{end}}</td></tr>{{end}}</table>
+<form method="post" action="{{.Prefix}}/{{.Model.Name}}/{{.RecordID}}/delete">
+<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+<p><button class="button danger" type="submit">Yes, delete it</button> <a href="{{.Prefix}}/{{.Model.Name}}/{{.RecordID}}">Cancel</a></p>
+</form>
+{{template "foot"}}{{end}}
+
+{{define "login"}}<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Log in | {{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; background: #f7f7f7; color: #222; }
+.login { max-width: 360px; margin: 60px auto; background: #fff; padding: 24px 32px; }
+.field { margin-bottom: 12px; }
+.field label { display: block; font-weight: 600; margin-bottom: 4px; }
+.field input { width: 100%; padding: 6px; box-sizing: border-box; }
+.error { background: #fed7d7; padding: 8px 12px; }
+button { background: #2b6cb0; color: #fff; border: 0; padding: 7px 16px; cursor: pointer; }
+</style></head>
+<body>
+<form class="login" method="post" action="{{.Prefix}}/login">
+<h1>{{.Title}}</h1>
+{{with .Error}}<p class="error">{{.}}</p>{{end}}
+<input type="hidden" name="next" value="{{.Next}}">
+<div class="field"><label for="username">Username</label><input id="username" name="username" value="{{.Username}}" autofocus></div>
+<div class="field"><label for="password">Password</label><input id="password" type="password" name="password"></div>
+<button type="submit">Log in</button>
+</form>
+</body>
+</html>{{end}}
+`))
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/openapi"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/serializer"
+)
+
+// Filter lookups, written as ?column__lookup=value
+var lookups = map[string]string{
+	"exact":    "=",
+	"ne":       "!=",
+	"gt":       ">",
+	"gte":      ">=",
+	"lt":       "<",
+	"lte":      "<=",
+	"in":       "in",
+	"contains": "like",
+	"isnull":   "is null",
+}
+
+// listOptions turns the filter, search and ordering query parameters into query options.
+// Parameters naming columns outside the whitelists are ignored.
+func (h *Handler[T]) listOptions(c *bourbon.Context) ([]orm.QueryOption, error) {
+	var opts []orm.QueryOption
+	query := c.Request.URL.Query()
+
+	for _, name := range h.opts.Filters {
+		field := h.column(name)
+		for key, values := range query {
+			column, lookup, _ := strings.Cut(key, "__")
+			if column != name || len(values) == 0 {
+				continue
+			}
+			if lookup == "" {
+				lookup = "exact"
+			}
+			op, ok := lookups[lookup]
+			if !ok {
+				return nil, fmt.Errorf("unknown lookup %q for %s", lookup, name)
+			}
+			raw := values[len(values)-1]
+
+			switch lookup {
+			case "in":
+				var in []interface{}
+				for _, part := range strings.Split(raw, ",") {
+					v, err := parseValue(field.FieldType, strings.TrimSpace(part))
+					if err != nil {
+						return nil, fmt.Errorf("invalid value for %s: %q", key, part)
+					}
+					in = append(in, v)
+				}
+				opts = append(opts, orm.In(field.DBName, in))
+			case "contains":
+				opts = append(opts, orm.Like(field.DBName, "%"+raw+"%"))
+			case "isnull":
+				isNull, err := parseValue(reflect.TypeOf(true), raw)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value for %s: %q", key, raw)
+				}
+				if !isNull.(bool) {
+					op = "is not null"
+				}
+				opts = append(opts, orm.Where(field.DBName, op, nil))
+			default:
+				v, err := parseValue(field.FieldType, raw)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value for %s: %q", key, raw)
+				}
+				opts = append(opts, orm.Where(field.DBName, op, v))
+			}
+		}
+	}
+
+	if term := strings.TrimSpace(c.Query("search")); term != "" && len(h.opts.Search) > 0 {
+		var matches []orm.QueryOption
+		for _, name := range h.opts.Search {
+			matches = append(matches, orm.Like(h.column(name).DBName, "%"+term+"%"))
+		}
+		opts = append(opts, orm.Or(matches...))
+	}
+
+	// The default ordering comes from code, so only ?ordering= is checked against the whitelist
+	ordering, trusted := c.Query("ordering"), false
+	if ordering == "" {
+		ordering, trusted = h.opts.DefaultOrdering, true
+	}
+	ordered := false
+	for _, part := range strings.Split(ordering, ",") {
+		part = strings.TrimSpace(part)
+		desc := strings.HasPrefix(part, "-")
+		name := strings.TrimPrefix(part, "-")
+		if name == "" || !(trusted || h.orderable(name)) {
+			continue
+		}
+		if field := h.column(name); field != nil {
+			opts = append(opts, orm.OrderBy(field.DBName, desc))
+			ordered = true
+		}
+	}
+	if !ordered {
+		opts = append(opts, orm.OrderBy(h.pk.DBName, false))
+	}
+	return opts, nil
+}
+
+func (h *Handler[T]) orderable(name string) bool {
+	for _, allowed := range h.opts.Ordering {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// listParams documents the query parameters of the list endpoint
+type listParams[T any] struct {
+	h *Handler[T]
+}
+
+func (p listParams[T]) OpenAPIParameters() []openapi.Parameter {
+	params := []openapi.Parameter{
+		{Name: "page", In: "query", Description: "Page number, from 1", Schema: &openapi.Schema{Type: "integer"}},
+		{Name: "per_page", In: "query", Description: fmt.Sprintf("Records per page, at most %d", p.h.opts.MaxPerPage), Schema: &openapi.Schema{Type: "integer"}},
+	}
+	if len(p.h.opts.Search) > 0 {
+		params = append(params, openapi.Parameter{
+			Name: "search", In: "query", Description: "Matches " + strings.Join(p.h.opts.Search, ", "),
+			Schema: &openapi.Schema{Type: "string"},
+		})
+	}
+	if len(p.h.opts.Ordering) > 0 {
+		params = append(params, openapi.Parameter{
+			Name: "ordering", In: "query", Description: "Comma-separated columns, prefixed with - for descending: " + strings.Join(p.h.opts.Ordering, ", "),
+			Schema: &openapi.Schema{Type: "string"},
+		})
+	}
+	for _, name := range p.h.opts.Filters {
+		field := p.h.column(name)
+		params = append(params, openapi.Parameter{
+			Name: field.DBName, In: "query",
+			Description: "Also " + field.DBName + "__ne, __gt, __gte, __lt, __lte, __in, __contains and __isnull",
+			Schema:      openapi.SchemaOf(field.FieldType),
+		})
+	}
+	return params
+}
+
+// listSchema documents the page returned by the list endpoint
+type listSchema[T any] struct {
+	s *serializer.Serializer[T]
+}
+
+func (l listSchema[T]) OpenAPISchema() *openapi.Schema {
+	return &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"items":       {Type: "array", Items: l.s.OpenAPISchema()},
+			"total":       {Type: "integer", Format: "int64"},
+			"page":        {Type: "integer"},
+			"per_page":    {Type: "integer"},
+			"total_pages": {Type: "integer"},
+		},
+		Required: []string{"items", "total", "page", "per_page", "total_pages"},
+	}
+}
+
+// badRequest answers a malformed query
+func badRequest(c *bourbon.Context, err error) error {
+	return c.JSON(http.StatusBadRequest, bourbon.H{"error": err.Error()})
+}
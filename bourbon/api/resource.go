@@ -0,0 +1,416 @@
+// Package api provides generic REST resource controllers built on the orm
+// repository and serializer layers.
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/auth"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/serializer"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/validation"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Actions a resource can serve
+const (
+	ActionList    = "list"
+	ActionGet     = "retrieve"
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDestroy = "destroy"
+)
+
+// Routes is implemented by *bourbon.Router and *bourbon.Group
+type Routes interface {
+	Get(pattern string, handler bourbon.HandlerFunc) *bourbon.Route
+	Post(pattern string, handler bourbon.HandlerFunc) *bourbon.Route
+	Put(pattern string, handler bourbon.HandlerFunc) *bourbon.Route
+	Patch(pattern string, handler bourbon.HandlerFunc) *bourbon.Route
+	Delete(pattern string, handler bourbon.HandlerFunc) *bourbon.Route
+}
+
+// Options configures a resource. Filter, search and ordering names are column names.
+type Options[T any] struct {
+	Serializer *serializer.Serializer[T] // default serializer.Auto[T]()
+	Repository orm.Repository[T]         // default orm.NewRepository[T](db)
+
+	Filters         []string // ?status=open, ?age__gte=18, ?id__in=1,2
+	Search          []string // columns matched by ?search=
+	Ordering        []string // columns allowed in ?ordering=-created_at,name
+	DefaultOrdering string   // default the primary key, ascending
+	Preload         []string // associations loaded with every record
+	PerPage         int      // default 20
+	MaxPerPage      int      // cap for ?per_page=, default 100
+
+	// Only restricts the served actions, e.g. []string{api.ActionList, api.ActionGet}
+	Only []string
+
+	// Permission enables checks of "<permission>.view", ".add", ".change" and
+	// ".delete" with auth.Can, e.g. "posts"
+	Permission string
+
+	// Scope narrows every query, e.g. to records owned by the current user
+	Scope func(c *bourbon.Context) []orm.QueryOption
+
+	// BeforeSave runs after input is validated and before the record is created or updated
+	BeforeSave func(c *bourbon.Context, model *T) error
+
+	// Tag groups the routes in OpenAPI documents, default the table name
+	Tag string
+}
+
+// Handler serves the resource's endpoints. Its methods can also be mounted by hand.
+type Handler[T any] struct {
+	opts       Options[T]
+	serializer *serializer.Serializer[T]
+	repo       orm.Repository[T]
+	schema     *schema.Schema
+	pk         *schema.Field
+}
+
+// Resource registers REST endpoints for model T under path:
+//
+//	GET    /users       list, with filtering, search, ordering and pagination
+//	POST   /users       create
+//	GET    /users/{id}  retrieve
+//	PUT    /users/{id}  update
+//	PATCH  /users/{id}  partial update
+//	DELETE /users/{id}  delete
+//
+// For example:
+//
+//	api.Resource(app.Router.Group("/api"), "/users", app.DB, api.Options[User]{
+//	    Serializer: UserSerializer,
+//	    Filters:    []string{"is_active", "created_at"},
+//	    Search:     []string{"email", "name"},
+//	    Ordering:   []string{"created_at", "email"},
+//	})
+func Resource[T any](routes Routes, path string, db *gorm.DB, opts ...Options[T]) (*Handler[T], error) {
+	h, err := NewHandler[T](db, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	path = "/" + strings.Trim(path, "/")
+	item := path + "/{id}"
+	label := humanize(h.schema.Table)
+	tag := h.opts.Tag
+	if tag == "" {
+		tag = h.schema.Table
+	}
+
+	if h.serves(ActionList) {
+		routes.Get(path, h.List).Summary("List "+label).Tags(tag).
+			QueryParams(listParams[T]{h}).Response(http.StatusOK, listSchema[T]{h.serializer})
+	}
+	if h.serves(ActionCreate) {
+		routes.Post(path, h.Create).Summary("Create "+label).Tags(tag).
+			Request(h.serializer).Response(http.StatusCreated, h.serializer).Response(http.StatusUnprocessableEntity, validation.Errors{})
+	}
+	if h.serves(ActionGet) {
+		routes.Get(item, h.Retrieve).Summary("Get "+label).Tags(tag).
+			Response(http.StatusOK, h.serializer).Response(http.StatusNotFound, bourbon.H{})
+	}
+	if h.serves(ActionUpdate) {
+		routes.Put(item, h.Update).Summary("Update "+label).Tags(tag).
+			Request(h.serializer).Response(http.StatusOK, h.serializer).Response(http.StatusUnprocessableEntity, validation.Errors{})
+		routes.Patch(item, h.PartialUpdate).Summary("Partially update "+label).Tags(tag).
+			Request(h.serializer).Response(http.StatusOK, h.serializer).Response(http.StatusUnprocessableEntity, validation.Errors{})
+	}
+	if h.serves(ActionDestroy) {
+		routes.Delete(item, h.Destroy).Summary("Delete "+label).Tags(tag).
+			Response(http.StatusNoContent, nil).Response(http.StatusNotFound, bourbon.H{})
+	}
+	return h, nil
+}
+
+// NewHandler creates the handler of a resource without registering routes
+func NewHandler[T any](db *gorm.DB, opts ...Options[T]) (*Handler[T], error) {
+	h := &Handler[T]{}
+	if len(opts) > 0 {
+		h.opts = opts[0]
+	}
+	h.serializer = h.opts.Serializer
+	if h.serializer == nil {
+		h.serializer = serializer.Auto[T]()
+	}
+	h.repo = h.opts.Repository
+	if h.repo == nil {
+		h.repo = orm.NewRepository[T](db)
+	}
+	if h.opts.PerPage <= 0 {
+		h.opts.PerPage = 20
+	}
+	if h.opts.MaxPerPage <= 0 {
+		h.opts.MaxPerPage = 100
+	}
+
+	s, err := schema.Parse(new(T), &sync.Map{}, db.NamingStrategy)
+	if err != nil {
+		return nil, fmt.Errorf("api: %w", err)
+	}
+	if len(s.PrimaryFields) != 1 {
+		return nil, fmt.Errorf("api: %s needs exactly one primary key", s.Name)
+	}
+	h.schema = s
+	h.pk = s.PrimaryFields[0]
+
+	for _, names := range [][]string{h.opts.Filters, h.opts.Search, h.opts.Ordering} {
+		for _, name := range names {
+			if h.column(name) == nil {
+				return nil, fmt.Errorf("api: %s has no column %q", s.Name, name)
+			}
+		}
+	}
+	return h, nil
+}
+
+func (h *Handler[T]) serves(action string) bool {
+	if len(h.opts.Only) == 0 {
+		return true
+	}
+	for _, only := range h.opts.Only {
+		if only == action {
+			return true
+		}
+	}
+	return false
+}
+
+// column returns the field for a column or Go field name
+func (h *Handler[T]) column(name string) *schema.Field {
+	field := h.schema.LookUpField(name)
+	if field == nil || field.DBName == "" {
+		return nil
+	}
+	return field
+}
+
+// allowed checks the resource permission for action (view, add, change or delete)
+func (h *Handler[T]) allowed(c *bourbon.Context, action string) (bool, error) {
+	if h.opts.Permission == "" {
+		return true, nil
+	}
+	return auth.Check(c.Request.Context(), h.opts.Permission+"."+action)
+}
+
+// deny responds to a request allowed refused, or returns the error checking it
+func deny(c *bourbon.Context, err error) error {
+	if err != nil {
+		return err
+	}
+	return forbidden(c)
+}
+
+func (h *Handler[T]) scope(c *bourbon.Context) []orm.QueryOption {
+	var opts []orm.QueryOption
+	if h.opts.Scope != nil {
+		opts = append(opts, h.opts.Scope(c)...)
+	}
+	for _, association := range h.opts.Preload {
+		opts = append(opts, orm.Preload(association))
+	}
+	return opts
+}
+
+// List serves a page of records:
+//
+//	{"items": [...], "total": 42, "page": 1, "per_page": 20, "total_pages": 3}
+func (h *Handler[T]) List(c *bourbon.Context) error {
+	if allowed, err := h.allowed(c, "view"); err != nil || !allowed {
+		return deny(c, err)
+	}
+	opts, err := h.listOptions(c)
+	if err != nil {
+		return badRequest(c, err)
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	perPage, _ := strconv.Atoi(c.Query("per_page", strconv.Itoa(h.opts.PerPage)))
+	if perPage <= 0 {
+		perPage = h.opts.PerPage
+	}
+	perPage = min(perPage, h.opts.MaxPerPage)
+
+	result, err := h.repo.Paginate(c.Request.Context(), page, perPage, append(h.scope(c), opts...)...)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, bourbon.H{
+		"items":       h.serializer.SerializeMany(result.Items),
+		"total":       result.Total,
+		"page":        result.Page,
+		"per_page":    result.PerPage,
+		"total_pages": result.TotalPages,
+	})
+}
+
+// Retrieve serves one record
+func (h *Handler[T]) Retrieve(c *bourbon.Context) error {
+	if allowed, err := h.allowed(c, "view"); err != nil || !allowed {
+		return deny(c, err)
+	}
+	model, err := h.find(c)
+	if err != nil {
+		return h.findError(c, err)
+	}
+	return c.JSON(http.StatusOK, h.serializer.Serialize(model))
+}
+
+// Create validates the body and inserts a record
+func (h *Handler[T]) Create(c *bourbon.Context) error {
+	if allowed, err := h.allowed(c, "add"); err != nil || !allowed {
+		return deny(c, err)
+	}
+	model := new(T)
+	if err := h.serializer.Decode(c.Request.Body, model); err != nil {
+		return h.inputError(c, err)
+	}
+	if h.opts.BeforeSave != nil {
+		if err := h.opts.BeforeSave(c, model); err != nil {
+			return h.inputError(c, err)
+		}
+	}
+	if err := h.repo.Create(c.Request.Context(), model); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, h.serializer.Serialize(model))
+}
+
+// Update replaces the writable fields of a record
+func (h *Handler[T]) Update(c *bourbon.Context) error {
+	return h.update(c, false)
+}
+
+// PartialUpdate changes the fields present in the body
+func (h *Handler[T]) PartialUpdate(c *bourbon.Context) error {
+	return h.update(c, true)
+}
+
+func (h *Handler[T]) update(c *bourbon.Context, partial bool) error {
+	if allowed, err := h.allowed(c, "change"); err != nil || !allowed {
+		return deny(c, err)
+	}
+	model, err := h.find(c)
+	if err != nil {
+		return h.findError(c, err)
+	}
+
+	if partial {
+		err = h.serializer.DecodePartial(c.Request.Body, model)
+	} else {
+		err = h.serializer.Decode(c.Request.Body, model)
+	}
+	if err != nil {
+		return h.inputError(c, err)
+	}
+	if h.opts.BeforeSave != nil {
+		if err := h.opts.BeforeSave(c, model); err != nil {
+			return h.inputError(c, err)
+		}
+	}
+	if err := h.repo.Update(c.Request.Context(), model); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, h.serializer.Serialize(model))
+}
+
+// Destroy deletes a record
+func (h *Handler[T]) Destroy(c *bourbon.Context) error {
+	if allowed, err := h.allowed(c, "delete"); err != nil || !allowed {
+		return deny(c, err)
+	}
+	model, err := h.find(c)
+	if err != nil {
+		return h.findError(c, err)
+	}
+	if err := h.repo.Delete(c.Request.Context(), model); err != nil {
+		return err
+	}
+	c.Status(http.StatusNoContent)
+	return nil
+}
+
+// find loads the record named by the {id} path parameter within the scope
+func (h *Handler[T]) find(c *bourbon.Context) (*T, error) {
+	id, err := parseValue(h.pk.FieldType, c.Param("id"))
+	if err != nil {
+		return nil, orm.ErrNotFound
+	}
+	opts := append(h.scope(c), orm.Eq(h.pk.DBName, id))
+	return h.repo.First(c.Request.Context(), opts...)
+}
+
+func (h *Handler[T]) findError(c *bourbon.Context, err error) error {
+	if errors.Is(err, orm.ErrNotFound) {
+		return c.JSON(http.StatusNotFound, bourbon.H{"error": "not found"})
+	}
+	return err
+}
+
+// inputError answers validation errors with 422 and other errors with 400
+func (h *Handler[T]) inputError(c *bourbon.Context, err error) error {
+	var errs validation.Errors
+	if errors.As(err, &errs) {
+		return c.JSON(http.StatusUnprocessableEntity, errs)
+	}
+	return badRequest(c, err)
+}
+
+func forbidden(c *bourbon.Context) error {
+	return c.JSON(http.StatusForbidden, bourbon.H{"error": "forbidden"})
+}
+
+// humanize turns "order_items" into "order items"
+func humanize(name string) string {
+	return strings.ReplaceAll(name, "_", " ")
+}
+
+// parseValue converts a query string value to the Go type of a column
+func parseValue(t reflect.Type, raw string) (interface{}, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+		v.SetFloat(f)
+	default:
+		// Times and other types are compared as strings by the database
+		return raw, nil
+	}
+	return v.Interface(), nil
+}
@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const apiKeyContextKey contextKey = "bourbon.auth.api_key"
+
+var (
+	// ErrInvalidAPIKey is returned when a key is malformed, unknown or does not match
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrAPIKeyExpired is returned when a key is past its expiry time
+	ErrAPIKeyExpired = errors.New("API key expired")
+	// ErrAPIKeyRevoked is returned when a key has been revoked
+	ErrAPIKeyRevoked = errors.New("API key revoked")
+)
+
+// APIKey is a hashed machine-to-machine credential.
+// Only the SHA-256 hash of the secret is stored; the plaintext is shown once at creation.
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	Prefix     string     `gorm:"size:16;uniqueIndex;not null" json:"prefix"`
+	Hash       string     `gorm:"size:64;not null" json:"-"`
+	Scopes     string     `gorm:"size:500" json:"scopes"`
+	UserID     *uint      `gorm:"index" json:"user_id,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ScopeList returns the key's scopes as a slice
+func (k *APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return nil
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// HasScope reports whether the key grants the scope. A "*" scope grants everything.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.ScopeList() {
+		if s == "*" || s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyOptions configures a newly created API key
+type APIKeyOptions struct {
+	Scopes []string
+	UserID *uint
+	TTL    time.Duration // zero means the key never expires
+}
+
+// APIKeyStore creates, validates and revokes API keys
+type APIKeyStore struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyStore creates a new database-backed API key store
+func NewAPIKeyStore(db *gorm.DB) *APIKeyStore {
+	return &APIKeyStore{db: db}
+}
+
+// Migrate creates the api_keys table
+func (s *APIKeyStore) Migrate() error {
+	return s.db.AutoMigrate(&APIKey{})
+}
+
+// Create generates a new key and returns the stored record with the plaintext key.
+// The plaintext has the form "<prefix>.<secret>" and cannot be recovered later.
+func (s *APIKeyStore) Create(name string, opts APIKeyOptions) (*APIKey, string, error) {
+	prefix, err := randomHex(8)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &APIKey{
+		Name:   name,
+		Prefix: prefix,
+		Hash:   hashSecret(secret),
+		Scopes: strings.Join(opts.Scopes, ","),
+		UserID: opts.UserID,
+	}
+	if opts.TTL > 0 {
+		expires := time.Now().Add(opts.TTL)
+		key.ExpiresAt = &expires
+	}
+
+	if err := s.db.Create(key).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to store API key: %w", err)
+	}
+
+	return key, prefix + "." + secret, nil
+}
+
+// Validate checks a plaintext key and records its use
+func (s *APIKeyStore) Validate(raw string) (*APIKey, error) {
+	prefix, secret, ok := strings.Cut(strings.TrimSpace(raw), ".")
+	if !ok || prefix == "" || secret == "" {
+		return nil, ErrInvalidAPIKey
+	}
+
+	var key APIKey
+	if err := s.db.Where("prefix = ?", prefix).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashSecret(secret))) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+	if key.RevokedAt != nil {
+		return nil, ErrAPIKeyRevoked
+	}
+
+	now := time.Now()
+	if key.ExpiresAt != nil && now.After(*key.ExpiresAt) {
+		return nil, ErrAPIKeyExpired
+	}
+
+	key.LastUsedAt = &now
+	if err := s.db.Model(&key).UpdateColumn("last_used_at", now).Error; err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// Revoke revokes the key with the given prefix
+func (s *APIKeyStore) Revoke(prefix string) error {
+	result := s.db.Model(&APIKey{}).
+		Where("prefix = ? AND revoked_at IS NULL", prefix).
+		UpdateColumn("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: no active key with prefix %s", ErrInvalidAPIKey, prefix)
+	}
+	return nil
+}
+
+// List returns all keys, newest first
+func (s *APIKeyStore) List() ([]APIKey, error) {
+	var keys []APIKey
+	err := s.db.Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+// WithAPIKey returns a copy of ctx carrying the authenticated API key
+func WithAPIKey(ctx context.Context, key *APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey, key)
+}
+
+// APIKeyFromContext returns the API key that authenticated the request, if any
+func APIKeyFromContext(ctx context.Context) (*APIKey, bool) {
+	key, ok := ctx.Value(apiKeyContextKey).(*APIKey)
+	return key, ok && key != nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n/2)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random key: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,59 @@
+package auth
+
+import "context"
+
+type contextKey string
+
+const (
+	userIDKey     contextKey = "bourbon.auth.user_id"
+	authorizerKey contextKey = "bourbon.auth.authorizer"
+)
+
+// WithUserID returns a copy of ctx carrying the authenticated user ID.
+// Authentication middleware calls this once the user has been identified.
+func WithUserID(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserIDFromContext returns the authenticated user ID, if any
+func UserIDFromContext(ctx context.Context) (uint, bool) {
+	id, ok := ctx.Value(userIDKey).(uint)
+	return id, ok
+}
+
+// WithAuthorizer returns a copy of ctx carrying the given authorizer
+func WithAuthorizer(ctx context.Context, a *Authorizer) context.Context {
+	return context.WithValue(ctx, authorizerKey, a)
+}
+
+// AuthorizerFromContext returns the authorizer attached to ctx, if any
+func AuthorizerFromContext(ctx context.Context) (*Authorizer, bool) {
+	a, ok := ctx.Value(authorizerKey).(*Authorizer)
+	return a, ok && a != nil
+}
+
+// Can reports whether the user authenticated on ctx holds the permission.
+// It returns false when no user or authorizer is attached to ctx, and when the
+// permissions can't be loaded; use Check where that failure must not pass for a denial.
+func Can(ctx context.Context, permission string) bool {
+	allowed, err := Check(ctx, permission)
+	return err == nil && allowed
+}
+
+// Check reports whether the user authenticated on ctx holds the permission.
+// It returns false when no user or authorizer is attached to ctx, and the
+// authorizer's error when the permissions can't be loaded, e.g. when the
+// database is unavailable.
+func Check(ctx context.Context, permission string) (bool, error) {
+	userID, ok := UserIDFromContext(ctx)
+	if !ok {
+		return false, nil
+	}
+
+	a, ok := AuthorizerFromContext(ctx)
+	if !ok {
+		return false, nil
+	}
+
+	return a.Can(userID, permission)
+}
@@ -0,0 +1,179 @@
+// Package auth provides role and permission based authorization for Bourbon
+// applications.
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Role groups a set of permissions that can be assigned to users
+type Role struct {
+	ID          uint         `gorm:"primaryKey" json:"id"`
+	Name        string       `gorm:"size:100;uniqueIndex;not null" json:"name"`
+	Description string       `gorm:"size:255" json:"description"`
+	Permissions []Permission `gorm:"many2many:role_permissions" json:"permissions,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Permission is a named capability such as "posts.edit".
+// A name ending in ".*" grants every permission under that prefix, and "*" grants everything.
+type Permission struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"size:150;uniqueIndex;not null" json:"name"`
+	Description string    `gorm:"size:255" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// UserRole assigns a role to a user
+type UserRole struct {
+	UserID    uint      `gorm:"primaryKey" json:"user_id"`
+	RoleID    uint      `gorm:"primaryKey" json:"role_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ErrRoleNotFound is returned when a role lookup fails
+var ErrRoleNotFound = errors.New("role not found")
+
+// Migrate creates the roles, permissions and assignment tables
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(&Role{}, &Permission{}, &UserRole{})
+}
+
+// Authorizer answers permission checks against the database
+type Authorizer struct {
+	db *gorm.DB
+}
+
+// NewAuthorizer creates a new authorizer backed by db
+func NewAuthorizer(db *gorm.DB) *Authorizer {
+	return &Authorizer{db: db}
+}
+
+// CreateRole creates a role if it does not already exist and returns it
+func (a *Authorizer) CreateRole(name, description string) (*Role, error) {
+	role := Role{Name: name}
+	if err := a.db.Where(Role{Name: name}).Attrs(Role{Description: description}).FirstOrCreate(&role).Error; err != nil {
+		return nil, fmt.Errorf("failed to create role %s: %w", name, err)
+	}
+	return &role, nil
+}
+
+// FindRole returns the role with the given name
+func (a *Authorizer) FindRole(name string) (*Role, error) {
+	var role Role
+	err := a.db.Where("name = ?", name).First(&role).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrRoleNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// Grant gives the named role a permission, creating the permission if needed
+func (a *Authorizer) Grant(roleName, permission string) error {
+	role, err := a.FindRole(roleName)
+	if err != nil {
+		return err
+	}
+
+	perm := Permission{Name: permission}
+	if err := a.db.Where(Permission{Name: permission}).FirstOrCreate(&perm).Error; err != nil {
+		return fmt.Errorf("failed to create permission %s: %w", permission, err)
+	}
+
+	return a.db.Model(role).Association("Permissions").Append(&perm)
+}
+
+// Revoke removes a permission from the named role
+func (a *Authorizer) Revoke(roleName, permission string) error {
+	role, err := a.FindRole(roleName)
+	if err != nil {
+		return err
+	}
+
+	var perm Permission
+	if err := a.db.Where("name = ?", permission).First(&perm).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return a.db.Model(role).Association("Permissions").Delete(&perm)
+}
+
+// AssignRole gives a user the named role
+func (a *Authorizer) AssignRole(userID uint, roleName string) error {
+	role, err := a.FindRole(roleName)
+	if err != nil {
+		return err
+	}
+
+	assignment := UserRole{UserID: userID, RoleID: role.ID}
+	return a.db.Where(assignment).FirstOrCreate(&assignment).Error
+}
+
+// RemoveRole takes the named role away from a user
+func (a *Authorizer) RemoveRole(userID uint, roleName string) error {
+	role, err := a.FindRole(roleName)
+	if err != nil {
+		return err
+	}
+	return a.db.Where("user_id = ? AND role_id = ?", userID, role.ID).Delete(&UserRole{}).Error
+}
+
+// HasRole reports whether a user has been assigned the named role
+func (a *Authorizer) HasRole(userID uint, roleName string) (bool, error) {
+	var count int64
+	err := a.db.Model(&UserRole{}).
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("user_roles.user_id = ? AND roles.name = ?", userID, roleName).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Permissions returns the names of all permissions granted to a user through their roles
+func (a *Authorizer) Permissions(userID uint) ([]string, error) {
+	var names []string
+	err := a.db.Model(&Permission{}).
+		Distinct("permissions.name").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Pluck("permissions.name", &names).Error
+	return names, err
+}
+
+// Can reports whether a user holds the permission, honouring wildcard grants
+func (a *Authorizer) Can(userID uint, permission string) (bool, error) {
+	granted, err := a.Permissions(userID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, name := range granted {
+		if matchPermission(name, permission) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchPermission reports whether a granted permission covers the requested one
+func matchPermission(granted, requested string) bool {
+	if granted == "*" || granted == requested {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(granted, ".*"); ok {
+		return strings.HasPrefix(requested, prefix+".")
+	}
+	return false
+}
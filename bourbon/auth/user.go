@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/utils"
+	"gorm.io/gorm"
+)
+
+// SuperuserRole is the role granted "*" to users created with UserOptions.Superuser
+const SuperuserRole = "superuser"
+
+var (
+	// ErrInvalidCredentials is returned when an email and password do not match an active user
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	// ErrUserExists is returned when creating a user with an email already in use
+	ErrUserExists = errors.New("a user with this email already exists")
+)
+
+// User is a login account with a bcrypt password hash. Applications with their own
+// user model can ignore it; it exists so a fresh deployment can log into the admin.
+type User struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Email       string     `gorm:"size:255;uniqueIndex;not null" json:"email"`
+	Password    string     `gorm:"size:255;not null" json:"-"`
+	IsActive    bool       `gorm:"not null;default:true" json:"is_active"`
+	IsSuperuser bool       `gorm:"not null;default:false" json:"is_superuser"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName keeps the table apart from an application's own users table
+func (User) TableName() string {
+	return "auth_users"
+}
+
+// UserOptions configures a newly created user
+type UserOptions struct {
+	Superuser bool // also assign SuperuserRole, which is granted "*"
+}
+
+// UserStore creates and authenticates users
+type UserStore struct {
+	db *gorm.DB
+}
+
+// NewUserStore creates a new database-backed user store
+func NewUserStore(db *gorm.DB) *UserStore {
+	return &UserStore{db: db}
+}
+
+// Migrate creates the auth_users table and the RBAC tables
+func (s *UserStore) Migrate() error {
+	if err := Migrate(s.db); err != nil {
+		return err
+	}
+	return s.db.AutoMigrate(&User{})
+}
+
+// Create hashes password and inserts the user. With opts.Superuser the user is
+// assigned SuperuserRole, which is created and granted "*" if needed.
+func (s *UserStore) Create(email, password string, opts UserOptions) (*User, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" || !strings.Contains(email, "@") {
+		return nil, fmt.Errorf("invalid email address: %q", email)
+	}
+	if password == "" {
+		return nil, errors.New("password cannot be empty")
+	}
+
+	var count int64
+	if err := s.db.Model(&User{}).Where("email = ?", email).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrUserExists
+	}
+
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	user := &User{Email: email, Password: hash, IsActive: true, IsSuperuser: opts.Superuser}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if !opts.Superuser {
+			return nil
+		}
+		authorizer := NewAuthorizer(tx)
+		if _, err := authorizer.CreateRole(SuperuserRole, "Full access"); err != nil {
+			return err
+		}
+		if err := authorizer.Grant(SuperuserRole, "*"); err != nil {
+			return err
+		}
+		return authorizer.AssignRole(user.ID, SuperuserRole)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// SetPassword replaces the user's password
+func (s *UserStore) SetPassword(userID uint, password string) error {
+	if password == "" {
+		return errors.New("password cannot be empty")
+	}
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	result := s.db.Model(&User{}).Where("id = ?", userID).Update("password", hash)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Authenticate returns the ID of the active user with the given email and password.
+// Its signature matches admin.AuthenticateFunc.
+func (s *UserStore) Authenticate(ctx context.Context, email, password string) (uint, error) {
+	var user User
+	err := s.db.WithContext(ctx).Where("email = ?", strings.ToLower(strings.TrimSpace(email))).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, ErrInvalidCredentials
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !user.IsActive || !utils.VerifyPassword(password, user.Password) {
+		return 0, ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	s.db.WithContext(ctx).Model(&user).Update("last_login_at", now)
+	return user.ID, nil
+}
@@ -0,0 +1,201 @@
+// Package bench holds the benchmarks of the request hot path: routing, the
+// middleware chain, JSON responses and template rendering. They run with
+// go test -bench . -benchmem ./bourbon/bench, or through bourbon bench:compare,
+// which checks them against a recorded baseline.
+//
+// Every benchmark serves a request built once with fixed inputs to a response
+// writer that discards the body, so the numbers measure the framework rather
+// than httptest or the network.
+package bench
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	bourbonHttp "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/middleware"
+)
+
+// Benchmark is a named benchmark of the suite
+type Benchmark struct {
+	Name string
+	Func func(b *testing.B)
+}
+
+// Benchmarks is the suite, in the order it runs
+var Benchmarks = []Benchmark{
+	{Name: "RouterStatic", Func: benchmarkRouterStatic},
+	{Name: "RouterParam", Func: benchmarkRouterParam},
+	{Name: "MiddlewareChain", Func: benchmarkMiddlewareChain},
+	{Name: "JSON", Func: benchmarkJSON},
+	{Name: "Template", Func: benchmarkTemplate},
+}
+
+// routeCount is the number of resources the routing benchmarks register, five
+// routes each, so lookups happen in a table the size of a real application's
+const routeCount = 20
+
+// newRouter returns a router with routeCount resources, whose handlers answer
+// with an empty 200
+func newRouter() *bourbonHttp.Router {
+	router := bourbonHttp.NewRouter()
+	ok := func(c *bourbonHttp.Context) error {
+		c.Status(http.StatusOK)
+		return nil
+	}
+	for i := 0; i < routeCount; i++ {
+		prefix := fmt.Sprintf("/api/v1/resource%d", i)
+		router.Get(prefix, ok)
+		router.Post(prefix, ok)
+		router.Get(prefix+"/{id}", ok)
+		router.Put(prefix+"/{id}", ok)
+		router.Delete(prefix+"/{id}", ok)
+	}
+	return router
+}
+
+// serve serves req b.N times, failing the benchmark unless it answers status
+func serve(b *testing.B, handler http.Handler, req *http.Request, status int) {
+	b.Helper()
+	w := newDiscardWriter()
+	handler.ServeHTTP(w, req)
+	if w.status != status {
+		b.Fatalf("%s %s: status %d, want %d", req.Method, req.URL.Path, w.status, status)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.reset()
+		handler.ServeHTTP(w, req)
+	}
+}
+
+func benchmarkRouterStatic(b *testing.B) {
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/resource%d", routeCount/2), nil)
+	serve(b, newRouter(), req, http.StatusOK)
+}
+
+func benchmarkRouterParam(b *testing.B) {
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/resource%d/42", routeCount/2), nil)
+	serve(b, newRouter(), req, http.StatusOK)
+}
+
+// benchmarkMiddlewareChain serves a route through five global http middlewares,
+// as the application chains them, and five route middlewares
+func benchmarkMiddlewareChain(b *testing.B) {
+	router := newRouter()
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("middleware%d", i)
+		router.Use(func(next bourbonHttp.HandlerFunc) bourbonHttp.HandlerFunc {
+			return func(c *bourbonHttp.Context) error {
+				c.Set(key, true)
+				return next(c)
+			}
+		})
+	}
+	global := make([]middleware.Middleware, 5)
+	for i := range global {
+		header := fmt.Sprintf("X-Middleware-%d", i)
+		global[i] = func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(header, "1")
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/resource%d/42", routeCount/2), nil)
+	serve(b, middleware.Chain(router, global...), req, http.StatusOK)
+}
+
+// post is the payload of the JSON and template benchmarks
+type post struct {
+	ID       int      `json:"id"`
+	Title    string   `json:"title"`
+	Body     string   `json:"body"`
+	Tags     []string `json:"tags"`
+	Comments int      `json:"comments"`
+}
+
+// posts returns a page of 20 posts
+func posts() []post {
+	page := make([]post, 20)
+	for i := range page {
+		page[i] = post{
+			ID:       i + 1,
+			Title:    fmt.Sprintf("Post number %d", i+1),
+			Body:     "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor.",
+			Tags:     []string{"go", "web", "bourbon"},
+			Comments: i * 3,
+		}
+	}
+	return page
+}
+
+func benchmarkJSON(b *testing.B) {
+	router := newRouter()
+	page := posts()
+	router.Get("/posts", func(c *bourbonHttp.Context) error {
+		return c.JSON(http.StatusOK, bourbonHttp.H{"data": page, "total": len(page)})
+	})
+	serve(b, router, httptest.NewRequest(http.MethodGet, "/posts", nil), http.StatusOK)
+}
+
+// templates is a layout and a page listing posts, like a generated project's
+var templates = fstest.MapFS{
+	"layout.html": {Data: []byte(`{{define "layout"}}<!DOCTYPE html>
+<html><head><title>{{.title}}</title></head>
+<body><main>{{template "content" .}}</main></body></html>{{end}}`)},
+	"posts.html": {Data: []byte(`{{template "layout" .}}
+{{define "content"}}<h1>{{.title}}</h1>
+<ul>{{range .posts}}<li id="post-{{.ID}}"><h2>{{.Title}}</h2><p>{{.Body}}</p>
+{{range .Tags}}<span>{{.}}</span>{{end}} {{.Comments}} comments</li>{{end}}</ul>{{end}}`)},
+}
+
+func benchmarkTemplate(b *testing.B) {
+	engine := bourbonHttp.NewTemplateEngineFS(templates, ".html")
+	if err := engine.Load(); err != nil {
+		b.Fatal(err)
+	}
+	router := newRouter()
+	router.TemplateEngine = engine
+	page := posts()
+	router.Get("/posts", func(c *bourbonHttp.Context) error {
+		return c.Render("posts.html", bourbonHttp.H{"title": "Posts", "posts": page})
+	})
+	serve(b, router, httptest.NewRequest(http.MethodGet, "/posts", nil), http.StatusOK)
+}
+
+// discardWriter is a response writer that keeps the status and drops the body
+type discardWriter struct {
+	header http.Header
+	status int
+}
+
+func newDiscardWriter() *discardWriter {
+	return &discardWriter{header: make(http.Header)}
+}
+
+// reset clears the response between iterations, keeping the header map
+func (w *discardWriter) reset() {
+	clear(w.header)
+	w.status = 0
+}
+
+func (w *discardWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *discardWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return len(p), nil
+}
@@ -0,0 +1,9 @@
+package bench
+
+import "testing"
+
+func BenchmarkSuite(b *testing.B) {
+	for _, benchmark := range Benchmarks {
+		b.Run(benchmark.Name, benchmark.Func)
+	}
+}
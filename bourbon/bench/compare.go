@@ -0,0 +1,160 @@
+package bench
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+// Result is a benchmark's cost per request
+type Result struct {
+	Name        string  `json:"name"`
+	NsPerOp     float64 `json:"ns_per_op"`
+	AllocsPerOp int64   `json:"allocs_per_op"`
+	BytesPerOp  int64   `json:"bytes_per_op"`
+}
+
+// Run runs the benchmarks whose name matches filter, all when it is nil, count
+// times each. The runs take turns, so a slow spell of the machine affects every
+// benchmark a little rather than one a lot, and a result is the run with the
+// median time.
+func Run(filter *regexp.Regexp, count int) []Result {
+	if count < 1 {
+		count = 1
+	}
+	var selected []Benchmark
+	for _, benchmark := range Benchmarks {
+		if filter == nil || filter.MatchString(benchmark.Name) {
+			selected = append(selected, benchmark)
+		}
+	}
+
+	runs := make([][]Result, len(selected))
+	for i := 0; i < count; i++ {
+		for j, benchmark := range selected {
+			// Garbage left by the previous run is not this one's to collect
+			runtime.GC()
+			r := testing.Benchmark(benchmark.Func)
+			runs[j] = append(runs[j], Result{
+				Name:        benchmark.Name,
+				NsPerOp:     float64(r.T.Nanoseconds()) / float64(max(r.N, 1)),
+				AllocsPerOp: r.AllocsPerOp(),
+				BytesPerOp:  r.AllocedBytesPerOp(),
+			})
+		}
+	}
+
+	results := make([]Result, len(selected))
+	for j := range runs {
+		slices.SortFunc(runs[j], func(a, b Result) int {
+			return cmp.Compare(a.NsPerOp, b.NsPerOp)
+		})
+		results[j] = runs[j][count/2]
+	}
+	return results
+}
+
+// Baseline is a set of results recorded to compare later runs with, along with
+// the machine they were recorded on
+type Baseline struct {
+	GoVersion string   `json:"go_version"`
+	Platform  string   `json:"platform"`
+	CPUs      int      `json:"cpus"`
+	Results   []Result `json:"results"`
+}
+
+// NewBaseline returns a baseline of results recorded on this machine
+func NewBaseline(results []Result) *Baseline {
+	return &Baseline{
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+		Results:   results,
+	}
+}
+
+// ReadBaseline reads a baseline written by Write
+func ReadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to read baseline %s: %w", path, err)
+	}
+	return &baseline, nil
+}
+
+// Write writes the baseline as JSON
+func (b *Baseline) Write(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// SameMachine reports whether the baseline was recorded with this Go version,
+// platform and CPU count, without which timings don't compare
+func (b *Baseline) SameMachine() bool {
+	current := NewBaseline(nil)
+	return b.GoVersion == current.GoVersion && b.Platform == current.Platform && b.CPUs == current.CPUs
+}
+
+// Budget is how much worse than the baseline a benchmark may get
+type Budget struct {
+	// Slowdown is the fraction time per request may grow by, e.g. 0.1 for 10%
+	Slowdown float64
+	// Allocs is the number of allocations per request that may be added
+	Allocs int64
+}
+
+// Change compares a benchmark's result with its baseline. Baseline is nil for a
+// benchmark the baseline doesn't have.
+type Change struct {
+	Name     string
+	Baseline *Result
+	Current  Result
+	// Regressions says how the benchmark went over the budget, empty when it didn't
+	Regressions []string
+}
+
+// Slowdown returns the fraction time per request grew by
+func (c Change) Slowdown() float64 {
+	if c.Baseline == nil || c.Baseline.NsPerOp == 0 {
+		return 0
+	}
+	return c.Current.NsPerOp/c.Baseline.NsPerOp - 1
+}
+
+// Compare compares results with the baseline's
+func Compare(baseline *Baseline, results []Result, budget Budget) []Change {
+	recorded := make(map[string]Result, len(baseline.Results))
+	for _, r := range baseline.Results {
+		recorded[r.Name] = r
+	}
+
+	changes := make([]Change, 0, len(results))
+	for _, current := range results {
+		change := Change{Name: current.Name, Current: current}
+		if before, ok := recorded[current.Name]; ok {
+			change.Baseline = &before
+			if slowdown := change.Slowdown(); slowdown > budget.Slowdown {
+				change.Regressions = append(change.Regressions,
+					fmt.Sprintf("%.0f%% slower, budget %.0f%%", slowdown*100, budget.Slowdown*100))
+			}
+			if added := current.AllocsPerOp - before.AllocsPerOp; added > budget.Allocs {
+				change.Regressions = append(change.Regressions,
+					fmt.Sprintf("%d more allocs/op, budget %d", added, budget.Allocs))
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrMiss is returned by Get when the key is not cached or has expired
+var ErrMiss = errors.New("cache: miss")
+
+// Forever stores an entry without expiry
+const Forever time.Duration = -1
+
+// Store is a cache backend holding raw bytes
+type Store interface {
+	// Get returns the value of key, or ErrMiss
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores value under key for ttl (no expiry when ttl <= 0) and records it under tags
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags []string) error
+	// Delete removes keys; missing keys are ignored
+	Delete(ctx context.Context, keys ...string) error
+	// InvalidateTags removes every entry stored with one of tags
+	InvalidateTags(ctx context.Context, tags ...string) error
+	// Flush removes every entry
+	Flush(ctx context.Context) error
+	// Close releases the backend
+	Close() error
+}
+
+// Config selects and configures the cache backend
+type Config struct {
+	Driver     string        // memory (default) or redis
+	Prefix     string        // prepended to Redis keys, e.g. "myapp:"
+	DefaultTTL time.Duration // used when Set or Remember gets a zero TTL (default 5m)
+	Redis      RedisConfig
+}
+
+// RedisConfig holds the Redis connection settings
+type RedisConfig struct {
+	Addr     string
+	Username string
+	Password string
+	DB       int
+}
+
+// Cache stores JSON-encoded values in a Store. Values written through a tagged cache
+// (Tags) can be removed together with InvalidateTags.
+type Cache struct {
+	store      Store
+	defaultTTL time.Duration
+	tags       []string
+}
+
+// New wraps store; defaultTTL applies when a TTL of zero is given
+func New(store Store, defaultTTL time.Duration) *Cache {
+	if defaultTTL == 0 {
+		defaultTTL = 5 * time.Minute
+	}
+	return &Cache{store: store, defaultTTL: defaultTTL}
+}
+
+// Open creates a cache for the configured driver
+func Open(cfg Config) (*Cache, error) {
+	switch cfg.Driver {
+	case "", "memory":
+		return New(NewMemoryStore(time.Minute), cfg.DefaultTTL), nil
+	case "redis":
+		return New(NewRedisStore(cfg.Redis, cfg.Prefix), cfg.DefaultTTL), nil
+	default:
+		return nil, fmt.Errorf("unsupported cache driver: %s (use memory or redis)", cfg.Driver)
+	}
+}
+
+// Store returns the backend
+func (c *Cache) Store() Store {
+	return c.store
+}
+
+// Tags returns a cache whose Set and Remember record entries under tags:
+//
+//	app.Cache.Tags("posts").Set(ctx, "posts:recent", posts, 10*time.Minute)
+//	app.Cache.InvalidateTags(ctx, "posts") // after a post changes
+func (c *Cache) Tags(tags ...string) *Cache {
+	return &Cache{
+		store:      c.store,
+		defaultTTL: c.defaultTTL,
+		tags:       append(append([]string(nil), c.tags...), tags...),
+	}
+}
+
+// Get decodes the value of key into dest and returns ErrMiss when it is not cached
+func (c *Cache) Get(ctx context.Context, key string, dest interface{}) error {
+	data, err := c.store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("cache: failed to decode %s: %w", key, err)
+	}
+	return nil
+}
+
+// Set stores value under key for ttl; zero uses the default TTL and Forever never expires
+func (c *Cache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("cache: failed to encode %s: %w", key, err)
+	}
+	return c.SetBytes(ctx, key, data, ttl)
+}
+
+// GetBytes returns the raw value of key
+func (c *Cache) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	return c.store.Get(ctx, key)
+}
+
+// SetBytes stores a raw value without JSON encoding
+func (c *Cache) SetBytes(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.store.Set(ctx, key, value, c.ttl(ttl), c.tags)
+}
+
+// Has reports whether key is cached
+func (c *Cache) Has(ctx context.Context, key string) bool {
+	_, err := c.store.Get(ctx, key)
+	return err == nil
+}
+
+// Delete removes keys
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	return c.store.Delete(ctx, keys...)
+}
+
+// Remember decodes the cached value of key into dest, or calls fn, caches its result
+// for ttl and decodes that into dest:
+//
+//	var stats DashboardStats
+//	err := app.Cache.Remember(ctx, "dashboard:stats", time.Minute, &stats, func() (interface{}, error) {
+//	    return loadStats(ctx)
+//	})
+func (c *Cache) Remember(ctx context.Context, key string, ttl time.Duration, dest interface{}, fn func() (interface{}, error)) error {
+	err := c.Get(ctx, key, dest)
+	if err == nil || !errors.Is(err, ErrMiss) {
+		return err
+	}
+
+	value, err := fn()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("cache: failed to encode %s: %w", key, err)
+	}
+	if err := c.SetBytes(ctx, key, data, ttl); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dest)
+}
+
+// InvalidateTags removes every entry stored with one of tags
+func (c *Cache) InvalidateTags(ctx context.Context, tags ...string) error {
+	return c.store.InvalidateTags(ctx, tags...)
+}
+
+// Flush removes every entry
+func (c *Cache) Flush(ctx context.Context) error {
+	return c.store.Flush(ctx)
+}
+
+// Ping checks that the backend is reachable; the memory store always is
+func (c *Cache) Ping(ctx context.Context) error {
+	if p, ok := c.store.(interface{ Ping(context.Context) error }); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// Close releases the backend
+func (c *Cache) Close() error {
+	return c.store.Close()
+}
+
+func (c *Cache) ttl(ttl time.Duration) time.Duration {
+	switch {
+	case ttl == 0:
+		return c.defaultTTL
+	case ttl < 0:
+		return 0
+	}
+	return ttl
+}
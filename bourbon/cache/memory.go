@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryItem struct {
+	value   []byte
+	expires time.Time // zero when the entry never expires
+	tags    []string
+}
+
+func (i memoryItem) expired(now time.Time) bool {
+	return !i.expires.IsZero() && now.After(i.expires)
+}
+
+// MemoryStore keeps entries in process memory, suitable for single-instance deployments
+type MemoryStore struct {
+	mu    sync.RWMutex
+	items map[string]memoryItem
+	tags  map[string]map[string]struct{}
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewMemoryStore creates a memory store that removes expired entries every cleanupInterval
+func NewMemoryStore(cleanupInterval time.Duration) *MemoryStore {
+	s := &MemoryStore{
+		items: make(map[string]memoryItem),
+		tags:  make(map[string]map[string]struct{}),
+		stop:  make(chan struct{}),
+	}
+	if cleanupInterval > 0 {
+		go s.janitor(cleanupInterval)
+	}
+	return s
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.RLock()
+	item, ok := s.items[key]
+	s.mu.RUnlock()
+	if !ok || item.expired(time.Now()) {
+		return nil, ErrMiss
+	}
+	return item.value, nil
+}
+
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags []string) error {
+	item := memoryItem{value: append([]byte(nil), value...), tags: tags}
+	if ttl > 0 {
+		item.expires = time.Now().Add(ttl)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleteLocked(key)
+	s.items[key] = item
+	for _, tag := range tags {
+		if s.tags[tag] == nil {
+			s.tags[tag] = make(map[string]struct{})
+		}
+		s.tags[tag][key] = struct{}{}
+	}
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		s.deleteLocked(key)
+	}
+	return nil
+}
+
+func (s *MemoryStore) InvalidateTags(ctx context.Context, tags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tag := range tags {
+		for key := range s.tags[tag] {
+			s.deleteLocked(key)
+		}
+		delete(s.tags, tag)
+	}
+	return nil
+}
+
+func (s *MemoryStore) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = make(map[string]memoryItem)
+	s.tags = make(map[string]map[string]struct{})
+	return nil
+}
+
+// Close stops the cleanup goroutine
+func (s *MemoryStore) Close() error {
+	s.closeOnce.Do(func() { close(s.stop) })
+	return nil
+}
+
+// deleteLocked removes key and its tag memberships; s.mu must be held
+func (s *MemoryStore) deleteLocked(key string) {
+	item, ok := s.items[key]
+	if !ok {
+		return
+	}
+	delete(s.items, key)
+	for _, tag := range item.tags {
+		if keys := s.tags[tag]; keys != nil {
+			delete(keys, key)
+			if len(keys) == 0 {
+				delete(s.tags, tag)
+			}
+		}
+	}
+}
+
+func (s *MemoryStore) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for key, item := range s.items {
+				if item.expired(now) {
+					s.deleteLocked(key)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore keeps entries in Redis, shared by every instance of the application.
+// Tags are Redis sets listing the keys stored with them.
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisStore connects to Redis; keys are stored under prefix
+func NewRedisStore(cfg RedisConfig, prefix string) *RedisStore {
+	if cfg.Addr == "" {
+		cfg.Addr = "localhost:6379"
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+	return NewRedisStoreWithClient(client, prefix)
+}
+
+// NewRedisStoreWithClient uses an existing client, e.g. a cluster or sentinel client
+func NewRedisStoreWithClient(client redis.UniversalClient, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Client returns the underlying Redis client
+func (s *RedisStore) Client() redis.UniversalClient {
+	return s.client
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMiss
+	}
+	return value, err
+}
+
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags []string) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, s.prefix+key, value, ttl)
+		for _, tag := range tags {
+			pipe.SAdd(ctx, s.tagKey(tag), key)
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return s.client.Del(ctx, s.prefixed(keys)...).Err()
+}
+
+func (s *RedisStore) InvalidateTags(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		keys, err := s.client.SMembers(ctx, s.tagKey(tag)).Result()
+		if err != nil {
+			return err
+		}
+		if err := s.client.Del(ctx, append(s.prefixed(keys), s.tagKey(tag))...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush removes the keys under the store's prefix
+func (s *RedisStore) Flush(ctx context.Context) error {
+	iter := s.client.Scan(ctx, 0, s.prefix+"*", 500).Iterator()
+	batch := make([]string, 0, 500)
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == cap(batch) {
+			if err := s.client.Del(ctx, batch...).Err(); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		return s.client.Del(ctx, batch...).Err()
+	}
+	return nil
+}
+
+// Ping checks the Redis connection
+func (s *RedisStore) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+func (s *RedisStore) tagKey(tag string) string {
+	return s.prefix + "tag:" + tag
+}
+
+func (s *RedisStore) prefixed(keys []string) []string {
+	result := make([]string, len(keys))
+	for i, key := range keys {
+		result[i] = s.prefix + key
+	}
+	return result
+}
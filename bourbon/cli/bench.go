@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"text/tabwriter"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/bench"
+	"github.com/spf13/cobra"
+)
+
+var benchCompareCmd = &cobra.Command{
+	Use:   "bench:compare",
+	Short: "Benchmark the request hot path against a recorded baseline",
+	Long: `Runs the benchmarks of the bench package: routing, the middleware chain, JSON
+responses and template rendering. With --save it records the results as the
+baseline. Otherwise it compares them with the baseline and fails when a benchmark
+got slower than --max-slowdown or allocates more than --max-allocs extra per
+request. Record the baseline on the same machine: timings from another Go
+version, platform or CPU count don't compare, and only allocations are checked.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		path, _ := flags.GetString("baseline")
+		save, _ := flags.GetBool("save")
+		count, _ := flags.GetInt("count")
+		run, _ := flags.GetString("run")
+		maxSlowdown, _ := flags.GetFloat64("max-slowdown")
+		maxAllocs, _ := flags.GetInt64("max-allocs")
+
+		var filter *regexp.Regexp
+		if run != "" {
+			var err error
+			if filter, err = regexp.Compile(run); err != nil {
+				return fmt.Errorf("invalid --run: %w", err)
+			}
+		}
+
+		var baseline *bench.Baseline
+		if !save {
+			var err error
+			baseline, err = bench.ReadBaseline(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("no baseline at %s: record one with bench:compare --save", path)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		fmt.Fprintf(os.Stderr, "Running benchmarks, %d runs each...\n", count)
+		results := bench.Run(filter, count)
+		if len(results) == 0 {
+			return fmt.Errorf("no benchmark matches --run %q", run)
+		}
+
+		if save {
+			if err := bench.NewBaseline(results).Write(path); err != nil {
+				return err
+			}
+			printBenchResults(results)
+			fmt.Printf("\nSaved the baseline to %s\n", path)
+			return nil
+		}
+
+		budget := bench.Budget{Slowdown: maxSlowdown / 100, Allocs: maxAllocs}
+		sameMachine := baseline.SameMachine()
+		if !sameMachine {
+			// Timings from another machine would fail or pass at random
+			budget.Slowdown = 1e9
+			current := bench.NewBaseline(nil)
+			fmt.Printf("The baseline was recorded with %s on %s with %d CPUs, and this is %s on %s with %d CPUs.\n",
+				baseline.GoVersion, baseline.Platform, baseline.CPUs, current.GoVersion, current.Platform, current.CPUs)
+			fmt.Print("Only allocations are checked.\n\n")
+		}
+		changes := bench.Compare(baseline, results, budget)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "BENCHMARK\tNS/OP\tDELTA\tALLOCS/OP\tB/OP\tSTATUS")
+		regressed := 0
+		for _, change := range changes {
+			status, delta := "ok", "~"
+			before := bench.Result{NsPerOp: change.Current.NsPerOp, AllocsPerOp: change.Current.AllocsPerOp, BytesPerOp: change.Current.BytesPerOp}
+			if change.Baseline == nil {
+				status = "new"
+			} else {
+				before = *change.Baseline
+				delta = fmt.Sprintf("%+.1f%%", change.Slowdown()*100)
+			}
+			if len(change.Regressions) > 0 {
+				regressed++
+				status = "REGRESSED: " + change.Regressions[0]
+				for _, regression := range change.Regressions[1:] {
+					status += "; " + regression
+				}
+			}
+			fmt.Fprintf(w, "%s\t%.0f -> %.0f\t%s\t%d -> %d\t%d -> %d\t%s\n", change.Name,
+				before.NsPerOp, change.Current.NsPerOp, delta,
+				before.AllocsPerOp, change.Current.AllocsPerOp,
+				before.BytesPerOp, change.Current.BytesPerOp, status)
+		}
+		w.Flush()
+
+		if regressed > 0 {
+			return fmt.Errorf("%d of %d benchmarks regressed beyond the budget", regressed, len(changes))
+		}
+		fmt.Println("\nNo regressions")
+		return nil
+	},
+}
+
+func printBenchResults(results []bench.Result) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BENCHMARK\tNS/OP\tALLOCS/OP\tB/OP")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%.0f\t%d\t%d\n", r.Name, r.NsPerOp, r.AllocsPerOp, r.BytesPerOp)
+	}
+	w.Flush()
+}
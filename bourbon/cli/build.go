@@ -0,0 +1,291 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// corePackage is where the build information variables live
+	corePackage = "github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	// embedFile is generated next to main.go for the duration of a build
+	embedFile = "zz_bourbon_embed.go"
+	// embedTag enables embedFile, so a stale copy never affects go build
+	embedTag = "bourbon_embed"
+)
+
+// commonTargets are the platforms --target common builds for
+var commonTargets = []string{"linux/amd64", "linux/arm64", "darwin/amd64", "darwin/arm64", "windows/amd64"}
+
+// driverTags are the build tags the orm package needs for each database driver
+var driverTags = map[string]string{
+	"sqlite":    "sqlite",
+	"postgres":  "postgres",
+	"cockroach": "postgres",
+	"mysql":     "mysql",
+}
+
+var buildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Compile the project into a production binary",
+	Long: `Compiles the project in the current directory into a single binary in dist/.
+The database driver's build tags come from settings.toml, the template and
+static directories are embedded, and the version, commit and build time are
+stamped into the binary (see the version command and /healthz).`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := os.Stat("go.mod"); os.IsNotExist(err) {
+			return fmt.Errorf("must run from project root (go.mod not found)")
+		}
+		flags := cmd.Flags()
+		settings, _ := flags.GetString("settings")
+		cfg, err := core.LoadConfig(settings)
+		if err != nil {
+			return err
+		}
+
+		opts := buildOptions{Name: projectDirName()}
+		opts.Output, _ = flags.GetString("output")
+		opts.Targets, _ = flags.GetStringSlice("target")
+		opts.Version, _ = flags.GetString("version")
+		extraTags, _ := flags.GetStringSlice("tags")
+		noEmbed, _ := flags.GetBool("no-embed")
+
+		opts.Driver = cfg.Database.Driver
+		if opts.Driver == "" {
+			opts.Driver = "sqlite"
+		}
+		if tag := driverTags[opts.Driver]; tag != "" {
+			opts.Tags = append(opts.Tags, tag)
+		}
+		opts.Tags = append(opts.Tags, extraTags...)
+
+		if !noEmbed {
+			opts.Templates = embeddableDir(cfg.Templates.Directory)
+			opts.Static = embeddableDir(cfg.Static.Directory)
+		}
+		if opts.Version == "" {
+			opts.Version = gitOutput("describe", "--tags", "--always", "--dirty")
+		}
+		if opts.Version == "" {
+			opts.Version = "dev"
+		}
+		opts.Commit = gitOutput("rev-parse", "--short", "HEAD")
+
+		return buildProject(opts)
+	},
+}
+
+// buildOptions describes a bourbon build
+type buildOptions struct {
+	Name      string   // binary name
+	Output    string   // binary path for a single target, or the directory for several
+	Targets   []string // os/arch pairs; the host platform if empty
+	Driver    string   // database driver from the settings
+	Tags      []string // build tags
+	Templates string   // template directory to embed, empty for none
+	Static    string   // static directory to embed, empty for none
+	Version   string
+	Commit    string
+}
+
+// buildProject compiles the project once for each target
+func buildProject(opts buildOptions) error {
+	targets, err := expandTargets(opts.Targets)
+	if err != nil {
+		return err
+	}
+
+	// go-sqlite3 needs cgo, and cross-compiling it a C compiler for the target
+	if opts.Driver == "sqlite" && os.Getenv("CC") == "" {
+		for _, target := range targets {
+			if target != hostTarget() {
+				return fmt.Errorf("%s: SQLite needs cgo, so cross-compiling needs a C compiler for the target in CC (e.g. CC=\"zig cc -target x86_64-linux-musl\")", target)
+			}
+		}
+	}
+
+	tags := slices.Clone(opts.Tags)
+	if opts.Templates != "" || opts.Static != "" {
+		if err := writeEmbedFile(opts.Templates, opts.Static); err != nil {
+			return err
+		}
+		defer os.Remove(embedFile)
+		tags = append(tags, embedTag)
+	}
+
+	ldflags := strings.Join([]string{
+		"-s", "-w",
+		fmt.Sprintf("-X %s.Version=%s", corePackage, opts.Version),
+		fmt.Sprintf("-X %s.Commit=%s", corePackage, opts.Commit),
+		fmt.Sprintf("-X %s.BuildTime=%s", corePackage, time.Now().UTC().Format(time.RFC3339)),
+	}, " ")
+
+	fmt.Printf("🥃 Building %s %s (driver %s)\n", opts.Name, opts.Version, opts.Driver)
+	for _, dir := range []string{opts.Templates, opts.Static} {
+		if dir != "" {
+			fmt.Printf("Embedding %s/\n", dir)
+		}
+	}
+
+	for _, target := range targets {
+		output := buildOutput(opts, target, len(targets) > 1)
+		goos, goarch, _ := strings.Cut(target, "/")
+
+		env := append(os.Environ(), "GOOS="+goos, "GOARCH="+goarch, "CGO_ENABLED=0")
+		if opts.Driver == "sqlite" {
+			env = append(env, "CGO_ENABLED=1")
+		}
+
+		args := []string{"build", "-trimpath", "-ldflags", ldflags}
+		if len(tags) > 0 {
+			args = append(args, "-tags", strings.Join(tags, ","))
+		}
+		args = append(args, "-o", output, ".")
+
+		build := exec.Command("go", args...)
+		build.Env = env
+		build.Stdout = os.Stdout
+		build.Stderr = os.Stderr
+		if err := build.Run(); err != nil {
+			return fmt.Errorf("build for %s failed: %w", target, err)
+		}
+		fmt.Printf("Built %s (%s)\n", output, target)
+	}
+	return nil
+}
+
+// expandTargets validates os/arch targets, expanding "common" and defaulting
+// to the host platform
+func expandTargets(targets []string) ([]string, error) {
+	var expanded []string
+	for _, target := range targets {
+		if target == "common" {
+			expanded = append(expanded, commonTargets...)
+			continue
+		}
+		goos, goarch, ok := strings.Cut(target, "/")
+		if !ok || goos == "" || goarch == "" {
+			return nil, fmt.Errorf("invalid target %q: use os/arch, e.g. linux/amd64, or common", target)
+		}
+		expanded = append(expanded, target)
+	}
+	if len(expanded) == 0 {
+		expanded = []string{hostTarget()}
+	}
+	slices.Sort(expanded)
+	return slices.Compact(expanded), nil
+}
+
+func hostTarget() string {
+	return runtime.GOOS + "/" + runtime.GOARCH
+}
+
+// buildOutput is the binary path for a target: --output or dist/<name> for a
+// single build, <name>-<os>-<arch> in --output or dist/ for several
+func buildOutput(opts buildOptions, target string, multiple bool) string {
+	goos, goarch, _ := strings.Cut(target, "/")
+	name := opts.Name
+	if multiple {
+		name = fmt.Sprintf("%s-%s-%s", name, goos, goarch)
+	}
+	if goos == "windows" {
+		name += ".exe"
+	}
+	switch {
+	case multiple && opts.Output != "":
+		return filepath.Join(opts.Output, name)
+	case opts.Output != "":
+		return opts.Output
+	}
+	return filepath.Join("dist", name)
+}
+
+// embeddableDir returns dir when go:embed can include it: an existing
+// directory inside the project
+func embeddableDir(dir string) string {
+	if dir == "" || filepath.IsAbs(dir) || !isDir(dir) {
+		return ""
+	}
+	dir = filepath.ToSlash(filepath.Clean(dir))
+	if dir == "." || strings.HasPrefix(dir, "../") {
+		return ""
+	}
+	return dir
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// projectDirName names the binary after the project directory
+func projectDirName() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "app"
+	}
+	return filepath.Base(dir)
+}
+
+// gitOutput runs git in the project and returns its trimmed output, or "" when
+// git or the repository isn't there
+func gitOutput(args ...string) string {
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+var embedTemplate = template.Must(template.New("embed").Parse(`//go:build ` + embedTag + `
+
+// Code generated by bourbon build. DO NOT EDIT.
+
+package main
+
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+)
+{{if .Templates}}
+//go:embed all:{{.Templates}}
+var bourbonTemplates embed.FS
+{{end}}{{if .Static}}
+//go:embed all:{{.Static}}
+var bourbonStatic embed.FS
+{{end}}
+func init() {
+	var templates, static fs.FS
+{{- if .Templates}}
+	templates, _ = fs.Sub(bourbonTemplates, {{printf "%q" .Templates}})
+{{- end}}
+{{- if .Static}}
+	static, _ = fs.Sub(bourbonStatic, {{printf "%q" .Static}})
+{{- end}}
+	core.UseEmbeddedAssets(templates, static)
+}
+`))
+
+// writeEmbedFile generates the file embedding the template and static
+// directories into the main package
+func writeEmbedFile(templates, static string) error {
+	f, err := os.Create(embedFile)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", embedFile, err)
+	}
+	defer f.Close()
+	return embedTemplate.Execute(f, map[string]string{"Templates": templates, "Static": static})
+}
@@ -1,9 +1,17 @@
 package cli
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"time"
 
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/migrationgen"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/dev"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/gomod"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/scaffold"
 	"github.com/spf13/cobra"
 )
 
@@ -26,10 +34,18 @@ var versionCmd = &cobra.Command{
 var newCmd = &cobra.Command{
 	Use:   "new [project-name]",
 	Short: "Create a new project",
+	Long:  "Creates a new project. In a terminal it asks for each option not given as a flag; --yes takes the defaults instead.",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		db, _ := cmd.Flags().GetString("db")
-		createProjectWithDB(args[0], db)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := newProjectOptions(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		if opts.Template != "" {
+			return createProjectFromTemplate(opts)
+		}
+		createProject(opts)
+		return nil
 	},
 }
 
@@ -37,47 +53,361 @@ var createAppCmd = &cobra.Command{
 	Use:   "create:app [app-name]",
 	Short: "Create a new application module",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		createApp(args[0])
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return scaffold.CreateApp(args[0])
 	},
 }
 
 var makeMigrationCmd = &cobra.Command{
-	Use:   "make:migration",
+	Use:   "make:migration [name]",
 	Short: "Create migrations (auto-detects changes if no app specified)",
-	Run: func(cmd *cobra.Command, args []string) {
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
 		app, _ := cmd.Flags().GetString("app")
 		name, _ := cmd.Flags().GetString("name")
 		force, _ := cmd.Flags().GetBool("force")
+		sql, _ := cmd.Flags().GetBool("sql")
+		data, _ := cmd.Flags().GetBool("data")
+		yes, _ := cmd.Flags().GetBool("yes")
+		noInput, _ := cmd.Flags().GetBool("no-input")
+		jsonOut, _ := cmd.Flags().GetBool("json")
+		if len(args) > 0 {
+			name = args[0]
+		}
+
+		if _, err := os.Stat("go.mod"); os.IsNotExist(err) {
+			return fmt.Errorf("must run from project root (go.mod not found)")
+		}
+
+		detect := app == "" && !sql && !data
+		if app == "" && !detect {
+			defaultApp, err := migrationgen.DefaultApp()
+			if err != nil {
+				return err
+			}
+			app = defaultApp
+		}
+
+		g := &migrationgen.Generator{Force: force}
+		switch {
+		case yes:
+			g.Input = migrationgen.AssumeYes
+		case noInput || jsonOut:
+			g.Input = migrationgen.NoInput
+		}
+		// Progress goes to stderr, leaving stdout to the JSON
+		stdout := os.Stdout
+		if jsonOut {
+			os.Stdout = os.Stderr
+		}
 
-		if app == "" {
+		var err error
+		switch {
+		case detect:
 			// Auto-detect changes in all apps (like Django)
-			makeMigrationsForAllApps(name, force)
-		} else {
-			// Create migration for specific app
-			makeMigrationForApp(app, name, force)
+			err = g.All(name)
+		case sql:
+			err = g.SQL(app, name)
+		case data:
+			err = g.Data(app, name)
+		default:
+			err = g.App(app, name)
+		}
+		os.Stdout = stdout
+		if err != nil || !jsonOut {
+			return err
+		}
+
+		created := g.Created
+		if created == nil {
+			created = []migrationgen.Migration{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]any{"migrations": created})
+	},
+}
+
+var makeControllerCmd = &cobra.Command{
+	Use:   "make:controller [name]",
+	Short: "Create a controller in an app",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := scaffoldApp(cmd)
+		if err != nil {
+			return err
+		}
+		resource, _ := cmd.Flags().GetBool("resource")
+		if err := scaffold.Controller(app, args[0], resource); err != nil {
+			return err
+		}
+		if test, _ := cmd.Flags().GetBool("test"); test {
+			return scaffold.Test(app, args[0])
+		}
+		return nil
+	},
+}
+
+var makeModelCmd = &cobra.Command{
+	Use:   "make:model [name] [field:type[:modifier...]...]",
+	Short: "Create a model in an app",
+	Long:  "Creates a model embedding BaseModel. Fields are name:type[:modifier...], with types string, text, int, int64, uint, float, decimal, bool, time, datetime, date, json and references[:Model], and modifiers unique, index, required, nullable and default=<value>.",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := scaffoldApp(cmd)
+		if err != nil {
+			return err
+		}
+		fields, _ := cmd.Flags().GetStringSlice("fields")
+		return scaffold.Model(app, args[0], append(fields, args[1:]...))
+	},
+}
+
+var makeMiddlewareCmd = &cobra.Command{
+	Use:   "make:middleware [name]",
+	Short: "Create a middleware in an app",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := scaffoldApp(cmd)
+		if err != nil {
+			return err
+		}
+		return scaffold.Middleware(app, args[0])
+	},
+}
+
+var makeCommandCmd = &cobra.Command{
+	Use:   "make:command [name]",
+	Short: "Create a CLI command in an app",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := scaffoldApp(cmd)
+		if err != nil {
+			return err
+		}
+		return scaffold.Command(app, args[0])
+	},
+}
+
+var makeJobCmd = &cobra.Command{
+	Use:   "make:job [name]",
+	Short: "Create a background job in an app",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := scaffoldApp(cmd)
+		if err != nil {
+			return err
+		}
+		return scaffold.Job(app, args[0])
+	},
+}
+
+var makeTestCmd = &cobra.Command{
+	Use:   "make:test [controller]",
+	Short: "Create a test for a controller's routes",
+	Long:  "Creates a table-driven test requesting each route of a controller with bourbontest. The routes are read from where the app registers the controller, or are the ones make:controller suggests.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := scaffoldApp(cmd)
+		if err != nil {
+			return err
+		}
+		return scaffold.Test(app, args[0])
+	},
+}
+
+var generateDockerCmd = &cobra.Command{
+	Use:   "generate:docker",
+	Short: "Create a Dockerfile, .dockerignore and docker-compose.yml from settings.toml",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		deployment, err := projectDeployment(cmd)
+		if err != nil {
+			return err
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		paths, err := scaffold.Docker(".", deployment, force)
+		if err != nil {
+			return err
+		}
+		printCreated(paths)
+		fmt.Println("\nRun it with: docker compose up --build")
+		fmt.Println("Migrate with: docker compose run --rm app ./server migrate")
+		return nil
+	},
+}
+
+var generateK8sCmd = &cobra.Command{
+	Use:   "generate:k8s",
+	Short: "Create Kubernetes manifests in k8s/ from settings.toml",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		deployment, err := projectDeployment(cmd)
+		if err != nil {
+			return err
+		}
+		deployment.Image, _ = cmd.Flags().GetString("image")
+		deployment.Replicas, _ = cmd.Flags().GetInt("replicas")
+		force, _ := cmd.Flags().GetBool("force")
+		paths, err := scaffold.Kubernetes(".", deployment, force)
+		if err != nil {
+			return err
+		}
+		printCreated(paths)
+		fmt.Printf("\nCreate the secrets, then apply: kubectl create secret generic %s-secrets --from-literal=SECRET_KEY=...\n", deployment.Name)
+		fmt.Println("  kubectl apply -f k8s/")
+		return nil
+	},
+}
+
+// projectDeployment reads the deployment parameters from --settings
+func projectDeployment(cmd *cobra.Command) (*scaffold.Deployment, error) {
+	if _, err := os.Stat("go.mod"); os.IsNotExist(err) {
+		return nil, fmt.Errorf("must run from project root (go.mod not found)")
+	}
+	settings, _ := cmd.Flags().GetString("settings")
+	return scaffold.DeploymentFromSettings(".", settings)
+}
+
+func printCreated(paths []string) {
+	for _, path := range paths {
+		fmt.Printf("Created %s\n", path)
+	}
+}
+
+// scaffoldApp returns the --app flag of a generator, or the first app
+func scaffoldApp(cmd *cobra.Command) (string, error) {
+	if _, err := scaffold.ModulePath(); err != nil {
+		return "", err
+	}
+	if app, _ := cmd.Flags().GetString("app"); app != "" {
+		return app, nil
+	}
+	return migrationgen.DefaultApp()
+}
+
+// tidyProject runs go mod tidy after a generator when its --tidy flag is set, so
+// go.mod gets the modules the generated files import
+func tidyProject(cmd *cobra.Command, args []string) error {
+	if tidy, _ := cmd.Flags().GetBool("tidy"); !tidy {
+		return nil
+	}
+	mod, err := gomod.Read(".")
+	if err != nil {
+		return err
+	}
+	return mod.Tidy()
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve [-- app args]",
+	Short: "Run the project with live reload",
+	Long:  "Runs the project in the current directory, rebuilding and restarting it when Go files change and reloading browsers when templates or static files change.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reloadAddr, _ := cmd.Flags().GetString("livereload")
+		delay, _ := cmd.Flags().GetDuration("delay")
+		ignore, _ := cmd.Flags().GetStringSlice("ignore")
+		tags, _ := cmd.Flags().GetString("tags")
+
+		cfg := dev.Config{LiveReloadAddr: reloadAddr, Delay: delay, Ignore: ignore, Args: args}
+		if tags != "" {
+			cfg.BuildFlags = []string{"-tags", tags}
 		}
+		return dev.Run(cfg)
 	},
 }
 
 func init() {
 	makeMigrationCmd.Flags().String("app", "", "Application name (optional, auto-detects all apps if not provided)")
-	makeMigrationCmd.Flags().String("name", "", "Migration name (optional, uses sequential numbering if not provided)")
-	makeMigrationCmd.Flags().Bool("force", false, "Force migration creation even if no changes detected")
+	makeMigrationCmd.Flags().String("name", "", "Migration name (optional, the ID is just the timestamp if not provided)")
+	makeMigrationCmd.Flags().Bool("force", false, "Create an empty migration when no changes are detected")
+	makeMigrationCmd.Flags().Bool("sql", false, "Create empty .up.sql and .down.sql files instead of detecting model changes")
+	makeMigrationCmd.Flags().Bool("data", false, "Create an empty data migration instead of detecting model changes")
+	makeMigrationCmd.Flags().BoolP("yes", "y", false, "Don't ask; accept destructive changes")
+	makeMigrationCmd.Flags().Bool("no-input", false, "Don't ask; assume no renames and refuse destructive changes")
+	makeMigrationCmd.Flags().Bool("json", false, "Print the created migrations as JSON (implies --no-input)")
+
+	for _, generator := range []*cobra.Command{makeControllerCmd, makeModelCmd, makeMiddlewareCmd, makeCommandCmd, makeJobCmd, makeTestCmd} {
+		generator.Flags().String("app", "", "Application name (optional, the first app if not provided)")
+	}
+	for _, generator := range []*cobra.Command{createAppCmd, makeMigrationCmd, makeControllerCmd, makeModelCmd, makeMiddlewareCmd, makeCommandCmd, makeJobCmd, makeTestCmd} {
+		generator.Flags().Bool("tidy", false, "Run go mod tidy afterwards, and go mod vendor in a vendored project")
+		generator.PostRunE = tidyProject
+	}
+	makeControllerCmd.Flags().Bool("resource", false, "Add Show, Store, Update and Destroy handlers")
+	makeControllerCmd.Flags().Bool("test", false, "Also create a test requesting its routes")
+	makeModelCmd.Flags().StringSlice("fields", nil, "Comma-separated fields, e.g. title:string:required,body:text")
+
+	for _, generator := range []*cobra.Command{generateDockerCmd, generateK8sCmd} {
+		generator.Flags().String("settings", "settings.toml", "Settings file to read the app name, port and database from")
+		generator.Flags().Bool("force", false, "Replace existing files")
+	}
+	generateK8sCmd.Flags().String("image", "", "Image to deploy (optional, <app-name>:latest if not provided)")
+	generateK8sCmd.Flags().Int("replicas", 2, "Number of pods (SQLite projects always run one)")
 
-	newCmd.Flags().String("db", "sqlite", "Database driver (sqlite, postgres, mysql)")
+	buildCmd.Flags().StringP("output", "o", "", "Binary to write (default: dist/<project>), or the directory when building for several targets")
+	buildCmd.Flags().StringSlice("target", nil, "os/arch to build for, repeatable, or common for linux, darwin and windows on amd64 and arm64 (default: this machine)")
+	buildCmd.Flags().StringSlice("tags", nil, "Build tags to add to the database driver's")
+	buildCmd.Flags().String("version", "", "Version to stamp (default: git describe, or dev)")
+	buildCmd.Flags().String("settings", "settings.toml", "Settings file to read the database driver and asset directories from")
+	buildCmd.Flags().Bool("no-embed", false, "Read templates and static files from disk at runtime instead of embedding them")
+
+	benchCompareCmd.Flags().String("baseline", "bench-baseline.json", "Baseline file to compare with, or to write with --save")
+	benchCompareCmd.Flags().Bool("save", false, "Record the results as the baseline instead of comparing")
+	benchCompareCmd.Flags().Int("count", 5, "Runs of each benchmark; the median is kept")
+	benchCompareCmd.Flags().String("run", "", "Only run the benchmarks matching this regular expression")
+	benchCompareCmd.Flags().Float64("max-slowdown", 10, "Percent a benchmark may get slower by")
+	benchCompareCmd.Flags().Int64("max-allocs", 0, "Allocations per request a benchmark may add")
+
+	upgradeCmd.Flags().Bool("dry-run", false, "List the rewrites and manual steps without changing anything")
+	upgradeCmd.Flags().String("settings", "settings.toml", "Settings file to check against the new release")
+	upgradeCmd.Long += upgradeCodemods()
+
+	newCmd.Flags().String("db", "sqlite", "Database driver (sqlite, postgres, mysql, sqlserver, cockroach)")
+	newCmd.Flags().String("module", "", "Module path (optional, the project name if not provided)")
+	newCmd.Flags().String("mode", "html", "Project mode (html renders templates, api serves JSON)")
+	newCmd.Flags().Bool("auth", false, "Add user auth endpoints (register, login and me)")
+	newCmd.Flags().Bool("docker", false, "Add a Dockerfile and docker-compose.yml")
+	newCmd.Flags().String("bourbon-path", "", "Local Bourbon checkout to use through a replace directive")
+	newCmd.Flags().String("template", "", "Template repository (github.com/org/repo[@ref] or a git URL) or directory to create the project from")
+	newCmd.Flags().BoolP("yes", "y", false, "Don't ask; use the flags and defaults")
+
+	serveCmd.Flags().String("livereload", "127.0.0.1:35729", `Browser live-reload address, or "-" to disable it`)
+	serveCmd.Flags().Duration("delay", 200*time.Millisecond, "Quiet time before acting on changes")
+	serveCmd.Flags().StringSlice("ignore", nil, "Directories not to watch")
+	serveCmd.Flags().String("tags", "", "Build tags")
 
 	rootCmd.AddCommand(
 		versionCmd,
 		newCmd,
 		createAppCmd,
 		makeMigrationCmd,
+		makeControllerCmd,
+		makeModelCmd,
+		makeMiddlewareCmd,
+		makeCommandCmd,
+		makeJobCmd,
+		makeTestCmd,
+		generateDockerCmd,
+		generateK8sCmd,
+		serveCmd,
+		buildCmd,
+		upgradeCmd,
+		benchCompareCmd,
 	)
 }
 
 func Run() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+	cleanup := addProjectCommands(os.Args[1:])
+	err := rootCmd.Execute()
+	cleanup()
+	if err != nil {
+		// A project command that failed has printed its own error
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			os.Exit(exit.ExitCode())
+		}
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
@@ -4,24 +4,52 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"unicode"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/scaffold"
 )
 
-func createProjectWithDB(name, database string) {
-	// Validate database choice
-	validDatabases := map[string]bool{
-		"sqlite":   true,
-		"postgres": true,
-		"mysql":    true,
-	}
+// projectDatabases are the drivers a new project can use
+var projectDatabases = []string{"sqlite", "postgres", "mysql", "sqlserver", "cockroach"}
+
+// projectModes are the kinds of project bourbon new creates: html renders
+// templates, api only serves JSON
+var projectModes = []string{"html", "api"}
+
+// projectOptions are the choices bourbon new makes a project from
+type projectOptions struct {
+	Name        string
+	Module      string // module path; the project name if empty
+	Database    string
+	Mode        string
+	Auth        bool   // add auth.go with register, login and me endpoints
+	Docker      bool   // add a Dockerfile and docker-compose.yml
+	BourbonPath string // local Bourbon checkout for a replace directive
+	Template    string // template repository or directory to copy instead
+}
 
-	if !validDatabases[database] {
-		fmt.Printf("Error: Invalid database '%s'. Must be: sqlite, postgres, or mysql\n", database)
+func createProject(opts projectOptions) {
+	name := opts.Name
+	if !slices.Contains(projectDatabases, opts.Database) {
+		fmt.Printf("Error: Invalid database '%s'. Must be: sqlite, postgres, mysql, sqlserver, or cockroach\n", opts.Database)
+		return
+	}
+	if !slices.Contains(projectModes, opts.Mode) {
+		fmt.Printf("Error: Invalid mode '%s'. Must be: html or api\n", opts.Mode)
+		return
+	}
+	if opts.Module == "" {
+		opts.Module = name
+	}
+	if err := checkModulePath(opts.Module); err != nil {
+		fmt.Printf("Error: Invalid module path: %v\n", err)
 		return
 	}
 
 	fmt.Printf("🥃 Creating new Bourbon project: %s\n", name)
-	fmt.Printf("📦 Database: %s\n", database)
+	fmt.Printf("📦 Database: %s\n", opts.Database)
 
 	if err := os.MkdirAll(name, 0755); err != nil {
 		fmt.Printf("Error creating project directory: %v\n", err)
@@ -29,17 +57,18 @@ func createProjectWithDB(name, database string) {
 	}
 
 	appName := strings.ReplaceAll(name, "-", "")
+	html := opts.Mode == "html"
 
 	dirs := []string{
-		"static/css",
-		"static/js",
-		"templates",
 		"storage",
 		"storage/logs",
 		".bourbon",
 		filepath.Join("apps", appName),
 		filepath.Join("apps", appName, "migrations"),
 	}
+	if html {
+		dirs = append(dirs, "static/css", "static/js", "templates")
+	}
 	for _, dir := range dirs {
 		path := filepath.Join(name, dir)
 		if err := os.MkdirAll(path, 0755); err != nil {
@@ -50,49 +79,84 @@ func createProjectWithDB(name, database string) {
 
 	// Select driver import based on database
 	var driverImport string
-	switch database {
+	switch opts.Database {
 	case "sqlite":
 		driverImport = `_ "github.com/ishubhamsingh2e/bourbon/bourbon/drivers/sqlite"`
 	case "postgres":
 		driverImport = `_ "github.com/ishubhamsingh2e/bourbon/bourbon/drivers/postgres"`
 	case "mysql":
 		driverImport = `_ "github.com/ishubhamsingh2e/bourbon/bourbon/drivers/mysql"`
+	case "sqlserver":
+		driverImport = `_ "github.com/ishubhamsingh2e/bourbon/bourbon/drivers/sqlserver"`
+	case "cockroach":
+		driverImport = `_ "github.com/ishubhamsingh2e/bourbon/bourbon/drivers/cockroach"`
 	}
 
 	// Select settings template based on database
 	var settingsContent string
-	switch database {
+	switch opts.Database {
 	case "sqlite":
 		settingsContent = settingsTemplateSQLite
 	case "postgres":
 		settingsContent = settingsTemplatePostgres
 	case "mysql":
 		settingsContent = settingsTemplateMySQL
+	case "sqlserver":
+		settingsContent = postgresSettingsFor("sqlserver", 1433, "sa", "change-me")
+	case "cockroach":
+		settingsContent = postgresSettingsFor("cockroach", 26257, "root", "")
+	}
+	if !html {
+		settingsContent = strings.Replace(settingsContent, htmlSettingsSection, apiSettingsSection, 1)
+	}
+
+	// Without a local checkout, go mod tidy adds the latest Bourbon release
+	localBourbon := ""
+	if opts.BourbonPath != "" {
+		path, err := filepath.Abs(opts.BourbonPath)
+		if err != nil {
+			fmt.Printf("Error resolving %s: %v\n", opts.BourbonPath, err)
+			return
+		}
+		localBourbon = fmt.Sprintf("\nrequire github.com/ishubhamsingh2e/bourbon v0.0.0\n\n// Local Bourbon checkout\nreplace github.com/ishubhamsingh2e/bourbon => %s\n", path)
+	}
+
+	mainContent := mainTemplate
+	if opts.Auth {
+		mainContent = strings.Replace(mainContent, "\t\tSetupMiddleware(app)\n", "\t\tSetupMiddleware(app)\n\t\tif err := SetupAuth(app); err != nil {\n\t\t\treturn err\n\t\t}\n", 1)
 	}
 
 	files := map[string]string{
-		"main.go":                                mainTemplate,
-		"middleware.go":                          middlewareTemplate,
-		"settings.toml":                          settingsContent,
-		"go.mod":                                 goModTemplate,
-		".gitignore":                             gitignoreTemplate,
-		"README.md":                              readmeTemplate,
-		filepath.Join("templates", "index.html"): indexHTMLTemplate,
-		filepath.Join("static", "css", "style.css"):                   cssTemplate,
-		filepath.Join("storage", ".gitkeep"):                          "",
+		"main.go":                            mainContent,
+		"middleware.go":                      middlewareTemplate,
+		"settings.toml":                      settingsContent,
+		"go.mod":                             goModTemplate,
+		".gitignore":                         gitignoreTemplate,
+		"README.md":                          readmeTemplate,
+		filepath.Join("storage", ".gitkeep"): "",
 		filepath.Join("storage", "logs", ".gitkeep"):                  "",
 		filepath.Join("apps", appName, "models.go"):                   appModelsTemplate,
-		filepath.Join("apps", appName, "controllers.go"):              appControllersTemplate,
 		filepath.Join("apps", appName, "routes.go"):                   appRoutesTemplate,
 		filepath.Join("apps", appName, "migrations", "migrations.go"): migrationsPackageTemplate,
 	}
+	if html {
+		files[filepath.Join("templates", "index.html")] = indexHTMLTemplate
+		files[filepath.Join("static", "css", "style.css")] = cssTemplate
+		files[filepath.Join("apps", appName, "controllers.go")] = appControllersTemplate
+	} else {
+		files[filepath.Join("apps", appName, "controllers.go")] = appAPIControllersTemplate
+	}
+	if opts.Auth {
+		files["auth.go"] = authTemplate
+	}
 
 	data := map[string]string{
 		"ProjectName":  name,
-		"ModulePath":   fmt.Sprintf("github.com/yourusername/%s", name),
+		"ModulePath":   opts.Module,
 		"AppName":      appName,
-		"Database":     database,
+		"Database":     opts.Database,
 		"DriverImport": driverImport,
+		"LocalBourbon": localBourbon,
 	}
 
 	for filename, templateStr := range files {
@@ -104,15 +168,42 @@ func createProjectWithDB(name, database string) {
 		}
 	}
 
+	if opts.Docker {
+		deployment, err := scaffold.DeploymentFromSettings(name, "settings.toml")
+		if err == nil {
+			_, err = scaffold.Docker(name, deployment, false)
+		}
+		if err != nil {
+			fmt.Printf("Error creating Docker files: %v\n", err)
+			return
+		}
+	}
+
 	fmt.Printf("\n✅ Project '%s' created successfully!\n\n", name)
 	fmt.Println("📋 Next steps:")
 	fmt.Printf("  cd %s\n", name)
 	fmt.Println("  go mod tidy                      # Install dependencies")
 	fmt.Println("  go run . make:migration          # Create migrations")
 	fmt.Println("  go run .                         # Start server")
+	if opts.Docker {
+		fmt.Println("  docker compose up --build        # Or run it in Docker")
+	}
 	fmt.Println("\n🥃 Happy coding with Bourbon!")
 }
 
+// checkModulePath rejects module paths go mod would not accept
+func checkModulePath(path string) error {
+	if strings.HasPrefix(path, "/") || strings.HasPrefix(path, ".") || strings.HasSuffix(path, "/") || strings.Contains(path, "//") {
+		return fmt.Errorf("%q must be a slash-separated path such as github.com/you/project", path)
+	}
+	for _, r := range path {
+		if unicode.IsSpace(r) || r == '\\' || r == '"' || r == '`' || r == '\'' {
+			return fmt.Errorf("%q must not contain %q", path, r)
+		}
+	}
+	return nil
+}
+
 func renderTemplate(tmpl string, data map[string]string) string {
 	result := tmpl
 	for key, value := range data {
@@ -162,6 +253,10 @@ path = "storage/database.db"
 
 [database.options]
 log_queries = false
+slow_query_threshold = "200ms"
+
+[apps]
+installed = ["{{.AppName}}"]
 
 # Middleware configuration
 # Middlewares are registered in middleware.go and enabled here
@@ -201,6 +296,16 @@ allowed_hosts = ["localhost", "127.0.0.1"]
 cors_origins = ["http://localhost:3000"]
 `
 
+// postgresSettingsFor adapts the PostgreSQL settings to another server database
+func postgresSettingsFor(driver string, port int, user, password string) string {
+	return strings.NewReplacer(
+		`driver = "postgres"`, fmt.Sprintf("driver = %q", driver),
+		"port = 5432", fmt.Sprintf("port = %d", port),
+		`user = "postgres"`, fmt.Sprintf("user = %q", user),
+		`password = "postgres"`, fmt.Sprintf("password = %q", password),
+	).Replace(settingsTemplatePostgres)
+}
+
 const settingsTemplatePostgres = `[app]
 name = "{{.ProjectName}}"
 debug = true
@@ -229,6 +334,10 @@ conn_max_lifetime = 3600
 [database.options]
 ssl_mode = "disable"
 log_queries = false
+slow_query_threshold = "200ms"
+
+[apps]
+installed = ["{{.AppName}}"]
 
 # Middleware configuration
 # Middlewares are registered in middleware.go and enabled here
@@ -298,6 +407,10 @@ charset = "utf8mb4"
 parse_time = "true"
 loc = "Local"
 log_queries = false
+slow_query_threshold = "200ms"
+
+[apps]
+installed = ["{{.AppName}}"]
 
 # Middleware configuration
 # Middlewares are registered in middleware.go and enabled here
@@ -362,18 +475,8 @@ const indexHTMLTemplate = `<!DOCTYPE html>
 
 const goModTemplate = `module {{.ModulePath}}
 
-go 1.21
-
-require (
-	github.com/ishubhamsingh2e/bourbon v1.0.0
-	gorm.io/driver/sqlite v1.5.4
-	gorm.io/gorm v1.25.5
-)
-
-// LOCAL DEVELOPMENT: Uncomment the line below and fix the path
-// Once Bourbon is published to GitHub, you can remove this line
-replace github.com/ishubhamsingh2e/bourbon => /Volumes/External/Git/Bourbon
-`
+go 1.23
+{{.LocalBourbon}}`
 
 const gitignoreTemplate = `# Binaries
 *.exe
@@ -383,6 +486,13 @@ const gitignoreTemplate = `# Binaries
 main
 {{.ProjectName}}
 
+# Dev server build
+tmp/
+
+# bourbon build output
+dist/
+zz_bourbon_embed.go
+
 # Test files
 *.test
 *.out
@@ -405,6 +515,9 @@ Thumbs.db
 storage/database.db
 storage/logs/
 
+# Local secrets
+.env
+
 # Bourbon state (local development)
 .bourbon/
 `
@@ -429,6 +542,9 @@ go run .
 
 Your app will be running at http://localhost:8000
 
+During development, ` + "`go run . dev`" + ` (or ` + "`bourbon serve`" + `) rebuilds and restarts the app when Go files change,
+and reloads the browser when templates or static files change.
+
 ## Available Commands
 
 ### Migration Commands
@@ -496,8 +612,8 @@ In ` + "`middleware.go`" + `:
 ` + "```go" + `
 func SetupMiddleware(app *core.Application) {
 	// Register built-in middleware
-	app.RegisterMiddleware("recovery", middleware.Recovery(app.Logger, app.ErrorStore))
-	app.RegisterMiddleware("logger", middleware.Logger(app.Logger, app.ErrorStore))
+	app.RegisterMiddleware("recovery", app.Recoverer())
+	app.RegisterMiddleware("logger", app.AccessLogger())
 	
 	// Register custom middleware
 	app.RegisterMiddleware("custom", MyCustomMiddleware())
@@ -589,6 +705,7 @@ func main() {
 package main
 
 import (
+	"flag"
 	"fmt"
 	"{{.ModulePath}}/apps/{{.AppName}}"
 	_ "{{.ModulePath}}/apps/{{.AppName}}/migrations"
@@ -597,15 +714,28 @@ import (
 )
 
 func init() {
-	// Register a database seed command
-	cmd.RegisterCommand("seed", func(args []string) error {
-		app := core.NewApplication("./settings.toml")
-		if err := app.ConnectDB(); err != nil {
-			return err
-		}
-		fmt.Println("Seeding database...")
-		// Your seeding logic
-		return nil
+	// Register a command that needs the database
+	cmd.Register(&cmd.Command{
+		Name:        "users:count",
+		Description: "Count users",
+		Flags: func(fs *flag.FlagSet) {
+			fs.Bool("active", false, "Only count active users")
+		},
+		Args: cmd.NoArgs,
+		Run: func(c *cmd.CommandContext) error {
+			app, err := c.App()
+			if err != nil {
+				return err
+			}
+			query := app.DB.Table("users")
+			if c.Bool("active") {
+				query = query.Where("is_active = ?", true)
+			}
+			var count int64
+			query.Count(&count)
+			fmt.Printf("%d users\n", count)
+			return nil
+		},
 	})
 }
 
@@ -619,7 +749,11 @@ func main() {
 }
 ` + "```" + `
 
-Then run: ` + "`go run main.go seed`" + `
+Then run: ` + "`go run main.go users:count --active`" + `. ` + "`go run main.go help`" + ` lists every command, and
+` + "`go run main.go help users:count`" + ` shows its flags.
+
+For seed data, use the built-in seeders instead: ` + "`go run main.go make:seeder users`" + ` creates
+` + "`apps/<app>/seeders/users.go`" + `, and ` + "`go run main.go seed`" + ` (or ` + "`seed --only=users`" + `) runs them.
 
 ### Full Control
 
@@ -918,14 +1052,16 @@ const middlewareTemplate = `package main
 
 import (
 	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core/registry"
 	"github.com/ishubhamsingh2e/bourbon/bourbon/middleware"
 )
 
 
 func SetupMiddleware(app *core.Application) {
 	// Register built-in middleware
-	app.RegisterMiddleware("recovery", middleware.Recovery(app.Logger, app.ErrorStore))
-	app.RegisterMiddleware("logger", middleware.Logger(app.Logger, app.ErrorStore))
+	// Recovery has the highest priority so it always wraps everything else
+	app.RegisterMiddleware("recovery", app.Recoverer(), registry.Priority(1000))
+	app.RegisterMiddleware("logger", app.AccessLogger())
 	
 	// CORS middleware - configure based on your needs
 	corsOrigin := "*"
@@ -936,10 +1072,10 @@ func SetupMiddleware(app *core.Application) {
 	
 	// Register your custom middleware here
 	// Example:
-	// app.RegisterMiddleware("custom", MyCustomMiddleware())
+	// app.RegisterMiddleware("custom", MyCustomMiddleware(), registry.Except("/health"), registry.Only("POST"))
 	
 	// Load middleware based on settings.toml configuration
-	// Middleware are applied in the order listed in settings.toml
+	// Middleware run by priority, then in the order listed in settings.toml
 	for _, name := range app.Config.Middleware.Enabled {
 		if err := app.UseMiddleware(name); err != nil {
 			app.Logger.Warn("Failed to load middleware: " + name)
@@ -947,3 +1083,136 @@ func SetupMiddleware(app *core.Application) {
 	}
 }
 `
+
+// htmlSettingsSection configures templates and static files in the settings
+// templates; API projects replace it with apiSettingsSection
+const htmlSettingsSection = `[templates]
+directory = "templates"
+extension = ".html"
+auto_reload = true
+
+[static]
+directory = "static"
+url_prefix = "/static"
+`
+
+const apiSettingsSection = `# API mode: no templates or static files
+[templates]
+directory = ""
+
+[static]
+directory = ""
+`
+
+const appAPIControllersTemplate = `package {{.AppName}}
+
+import (
+	"net/http"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	bourbonHttp "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+)
+
+type HomeController struct {
+	App *core.Application
+}
+
+func NewHomeController(app *core.Application) *HomeController {
+	return &HomeController{App: app}
+}
+
+func (c *HomeController) Index(ctx *bourbonHttp.Context) error {
+	return ctx.JSON(http.StatusOK, bourbonHttp.H{
+		"app":     "{{.ProjectName}}",
+		"message": "Your Bourbon API is running!",
+	})
+}
+
+func (c *HomeController) HealthCheck(ctx *bourbonHttp.Context) error {
+	return ctx.JSON(http.StatusOK, bourbonHttp.H{
+		"status": "healthy",
+		"app":    c.App.Config.App.Name,
+	})
+}
+`
+
+const authTemplate = `package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/auth"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+	bourbonHttp "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/middleware"
+)
+
+// tokenTTL is how long a token from /auth/login stays valid
+const tokenTTL = 30 * 24 * time.Hour
+
+type credentials struct {
+	Email    string ` + "`json:\"email\"`" + `
+	Password string ` + "`json:\"password\"`" + `
+}
+
+// SetupAuth creates the auth tables and adds the auth endpoints:
+//
+//	POST /auth/register  {"email", "password"} creates a user
+//	POST /auth/login     {"email", "password"} returns a token
+//	GET  /auth/me        the user of the token in the X-API-Key header
+//
+// Tokens are API keys belonging to the user. Protect other route groups the way
+// /auth/me is, with bourbonHttp.Adapt(middleware.APIKeyAuth(keys)).
+func SetupAuth(app *core.Application) error {
+	db := orm.Primary(app.DB)
+	users := auth.NewUserStore(db)
+	keys := auth.NewAPIKeyStore(db)
+	if err := users.Migrate(); err != nil {
+		return err
+	}
+	if err := keys.Migrate(); err != nil {
+		return err
+	}
+
+	group := app.Router.Group("/auth")
+	group.Post("/register", func(ctx *bourbonHttp.Context) error {
+		var input credentials
+		if err := ctx.Bind(&input); err != nil || len(input.Password) < 8 {
+			return ctx.JSON(http.StatusBadRequest, bourbonHttp.H{"error": "an email and a password of at least 8 characters are required"})
+		}
+		user, err := users.Create(input.Email, input.Password, auth.UserOptions{})
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, bourbonHttp.H{"error": err.Error()})
+		}
+		return ctx.JSON(http.StatusCreated, user)
+	})
+	group.Post("/login", func(ctx *bourbonHttp.Context) error {
+		var input credentials
+		if err := ctx.Bind(&input); err != nil {
+			return ctx.JSON(http.StatusBadRequest, bourbonHttp.H{"error": "invalid JSON"})
+		}
+		userID, err := users.Authenticate(ctx.Request.Context(), input.Email, input.Password)
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			return ctx.JSON(http.StatusUnauthorized, bourbonHttp.H{"error": err.Error()})
+		}
+		if err != nil {
+			return err
+		}
+		_, token, err := keys.Create("login", auth.APIKeyOptions{UserID: &userID, TTL: tokenTTL})
+		if err != nil {
+			return err
+		}
+		return ctx.JSON(http.StatusOK, bourbonHttp.H{"token": token})
+	})
+
+	protected := app.Router.Group("/auth", bourbonHttp.Adapt(middleware.APIKeyAuth(keys)))
+	protected.Get("/me", func(ctx *bourbonHttp.Context) error {
+		userID, _ := ctx.UserID()
+		return ctx.JSON(http.StatusOK, bourbonHttp.H{"user_id": userID})
+	})
+	return nil
+}
+`
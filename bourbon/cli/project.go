@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+
+	projectcmd "github.com/ishubhamsingh2e/bourbon/bourbon/cmd"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/gomod"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// cliGroup holds the commands the CLI runs itself
+	cliGroup = "cli"
+	// projectGroup holds the commands the project binary runs
+	projectGroup = "project"
+)
+
+// addProjectCommands adds the commands of the project's own registry that the
+// CLI doesn't have, so `bourbon migrate` runs the project's migrate and the
+// help lists the same commands as `go run . help`. In a project they come from
+// the project binary, with its custom commands, which is built for the purpose;
+// elsewhere the built-in ones are listed. Nothing is built when args name one of
+// the CLI's own commands. The returned function removes the binary.
+func addProjectCommands(args []string) func() {
+	if found, _, err := rootCmd.Find(args); err == nil && found != rootCmd {
+		return func() {}
+	}
+
+	commands := projectcmd.Commands()
+	var binary string
+	unavailable := errors.New("project commands run from the root of a Bourbon project, next to go.mod")
+	cleanup := func() {}
+	if inProject() {
+		path, remove, err := projectBinary()
+		if err != nil {
+			if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+				fmt.Fprintln(os.Stderr, "Only the built-in project commands are listed, as the project doesn't build (go build . shows why)")
+			}
+			unavailable = err
+		} else {
+			binary, unavailable, cleanup = path, nil, remove
+			if listed, err := listProjectCommands(binary); err == nil {
+				commands = listed
+			}
+		}
+	}
+	run := func(args ...string) error {
+		if unavailable != nil {
+			return unavailable
+		}
+		return runProject(binary, args)
+	}
+
+	rootCmd.AddGroup(
+		&cobra.Group{ID: cliGroup, Title: "Commands:"},
+		&cobra.Group{ID: projectGroup, Title: "Project commands (run by the project, also as go run . <command>):"},
+	)
+	rootCmd.SetHelpCommandGroupID(cliGroup)
+	rootCmd.SetCompletionCommandGroupID(cliGroup)
+	own := map[string]bool{"help": true, "completion": true}
+	for _, command := range rootCmd.Commands() {
+		command.GroupID = cliGroup
+		own[command.Name()] = true
+	}
+
+	for _, info := range commands {
+		if own[info.Name] {
+			continue
+		}
+		name := info.Name
+		use := name
+		if info.Usage != "" {
+			use += " " + info.Usage
+		}
+		command := &cobra.Command{
+			Use:                use,
+			Short:              info.Description,
+			GroupID:            projectGroup,
+			DisableFlagParsing: true,
+			SilenceErrors:      true,
+			SilenceUsage:       true,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return run(append([]string{name}, args...)...)
+			},
+		}
+		// The project knows the command's flags
+		command.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+			if unavailable != nil {
+				fmt.Printf("%s\n\n%v\n", info.Description, unavailable)
+				return
+			}
+			run("help", name)
+		})
+		rootCmd.AddCommand(command)
+	}
+	return cleanup
+}
+
+// inProject reports whether the current directory is the root of a project:
+// its go.mod requires Bourbon, or a fork of it, and it has a main.go
+func inProject() bool {
+	mod, err := gomod.Read(".")
+	if err != nil {
+		return false
+	}
+	if _, ok := mod.Requires[mod.Framework()]; !ok {
+		return false
+	}
+	_, err = os.Stat("main.go")
+	return err == nil
+}
+
+// projectBinary builds the project in the current directory into a temporary
+// directory, which remove deletes
+func projectBinary() (binary string, remove func(), err error) {
+	dir, err := os.MkdirTemp("", "bourbon-project-")
+	if err != nil {
+		return "", nil, err
+	}
+	binary = filepath.Join(dir, projectDirName())
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+
+	var output bytes.Buffer
+	build := exec.Command("go", "build", "-o", binary, ".")
+	build.Stdout = &output
+	build.Stderr = &output
+	if err := build.Run(); err != nil {
+		os.RemoveAll(dir)
+		return "", nil, fmt.Errorf("the project doesn't build:\n%s", bytes.TrimSpace(output.Bytes()))
+	}
+	return binary, func() { os.RemoveAll(dir) }, nil
+}
+
+// listProjectCommands asks the project binary for its commands
+func listProjectCommands(binary string) ([]projectcmd.CommandInfo, error) {
+	output, err := exec.Command(binary, "help", "--json").Output()
+	if err != nil {
+		return nil, err
+	}
+	var commands []projectcmd.CommandInfo
+	if err := json.Unmarshal(output, &commands); err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+// runProject runs the project binary in the foreground. Ctrl-C goes to it, so
+// the CLI waits for it to stop instead of exiting first.
+func runProject(binary string, args []string) error {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	run := exec.Command(binary, args...)
+	run.Stdin = os.Stdin
+	run.Stdout = os.Stdout
+	run.Stderr = os.Stderr
+	return run.Run()
+}
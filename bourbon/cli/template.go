@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// templateModule matches the module directive of a template's go.mod
+	templateModule = regexp.MustCompile(`(?m)^module\s+"?([^\s"]+)"?\s*$`)
+	// bourbonReplace matches a replace directive for Bourbon in a go.mod
+	bourbonReplace = regexp.MustCompile(`(?m)^replace\s+github\.com/ishubhamsingh2e/bourbon\s.*\n?`)
+)
+
+// createProjectFromTemplate creates a project from a template repository
+// instead of the built-in layout. The template is a git URL, a host/org/repo
+// path such as github.com/org/bourbon-starter (optionally with @ref for a
+// branch or tag), or a local directory.
+//
+// Every file and path name gets the same {{.ProjectName}}, {{.ModulePath}},
+// {{.AppName}}, {{.Database}} and {{.DriverImport}} placeholders as the built-in
+// templates. When the template's go.mod names a real module, such as the
+// template repository's own path, that path is replaced by the project's too,
+// so a template can be a working project itself.
+func createProjectFromTemplate(opts projectOptions) error {
+	name := opts.Name
+	if entries, err := os.ReadDir(name); err == nil && len(entries) > 0 {
+		return fmt.Errorf("%s already exists and is not empty", name)
+	}
+	if !slices.Contains(projectDatabases, opts.Database) {
+		return fmt.Errorf("invalid database %q: must be one of %s", opts.Database, strings.Join(projectDatabases, ", "))
+	}
+	if opts.Module == "" {
+		opts.Module = name
+	}
+	if err := checkModulePath(opts.Module); err != nil {
+		return fmt.Errorf("invalid module path: %w", err)
+	}
+
+	source, cleanup, err := fetchTemplate(opts.Template)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	fmt.Printf("🥃 Creating new Bourbon project: %s\n", name)
+	fmt.Printf("📦 Template: %s\n", opts.Template)
+
+	appName := strings.ReplaceAll(name, "-", "")
+	data := map[string]string{
+		"ProjectName":  name,
+		"ModulePath":   opts.Module,
+		"AppName":      appName,
+		"Database":     opts.Database,
+		"DriverImport": fmt.Sprintf(`_ "github.com/ishubhamsingh2e/bourbon/bourbon/drivers/%s"`, opts.Database),
+	}
+
+	// The template's own module path, replaced like a placeholder
+	var templatePath string
+	if content, err := os.ReadFile(filepath.Join(source, "go.mod")); err == nil {
+		if match := templateModule.FindSubmatch(content); match != nil && !strings.Contains(string(match[1]), "{{") {
+			templatePath = string(match[1])
+		}
+	}
+
+	err = filepath.WalkDir(source, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(source, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		target := filepath.Join(name, renderTemplate(rel, data))
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		// Binary files are copied as they are
+		if !bytes.ContainsRune(content, 0) {
+			text := renderTemplate(string(content), data)
+			if templatePath != "" && (rel == "go.mod" || strings.HasSuffix(rel, ".go")) {
+				text = replaceModulePath(text, templatePath, opts.Module)
+			}
+			content = []byte(text)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, info.Mode().Perm())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy template: %w", err)
+	}
+
+	if opts.BourbonPath != "" {
+		if err := addLocalBourbon(filepath.Join(name, "go.mod"), opts.BourbonPath); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("\n✅ Project '%s' created successfully!\n\n", name)
+	fmt.Println("📋 Next steps:")
+	fmt.Printf("  cd %s\n", name)
+	fmt.Println("  go mod tidy                      # Install dependencies")
+	fmt.Println("  go run .                         # Start server")
+	fmt.Println("\n🥃 Happy coding with Bourbon!")
+	return nil
+}
+
+// fetchTemplate returns the directory of a template, cloning it first when it
+// is remote, and a function removing the clone
+func fetchTemplate(template string) (string, func(), error) {
+	noop := func() {}
+	if info, err := os.Stat(template); err == nil && info.IsDir() {
+		return template, noop, nil
+	}
+
+	url, ref := template, ""
+	if i := strings.LastIndex(url, "@"); i > 0 && !strings.Contains(url[i:], "/") && !strings.Contains(url[i:], ":") {
+		url, ref = url[:i], url[i+1:]
+	}
+	if !strings.Contains(url, "://") && !strings.HasPrefix(url, "git@") {
+		url = "https://" + strings.TrimSuffix(url, ".git") + ".git"
+	}
+
+	dir, err := os.MkdirTemp("", "bourbon-template-")
+	if err != nil {
+		return "", noop, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	args := []string{"-c", "advice.detachedHead=false", "clone", "--quiet", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, url, dir)
+	fmt.Printf("Cloning %s...\n", url)
+	clone := exec.Command("git", args...)
+	clone.Stderr = os.Stderr
+	if err := clone.Run(); err != nil {
+		cleanup()
+		return "", noop, fmt.Errorf("failed to clone template %s: %w", template, err)
+	}
+	return dir, cleanup, nil
+}
+
+// replaceModulePath rewrites from and the import paths under it to to,
+// leaving paths that only share a prefix, like from-extra, alone
+func replaceModulePath(text, from, to string) string {
+	pattern := regexp.MustCompile(regexp.QuoteMeta(from) + `([/"\s]|$)`)
+	return pattern.ReplaceAllString(text, to+"${1}")
+}
+
+// addLocalBourbon points go.mod at a local Bourbon checkout
+func addLocalBourbon(goModPath, bourbonPath string) error {
+	path, err := filepath.Abs(bourbonPath)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(goModPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	text := strings.TrimRight(bourbonReplace.ReplaceAllString(string(content), ""), "\n")
+	if !strings.Contains(text, "github.com/ishubhamsingh2e/bourbon ") {
+		text += "\n\nrequire github.com/ishubhamsingh2e/bourbon v0.0.0"
+	}
+	text += fmt.Sprintf("\n\n// Local Bourbon checkout\nreplace github.com/ishubhamsingh2e/bourbon => %s\n", path)
+	return os.WriteFile(goModPath, []byte(text), 0644)
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/upgrade"
+	"github.com/spf13/cobra"
+)
+
+const bourbonModule = "github.com/ishubhamsingh2e/bourbon"
+
+var (
+	// bourbonRequire matches the required Bourbon version in a go.mod
+	bourbonRequire = regexp.MustCompile(`(?m)^\s*(?:require\s+)?github\.com/ishubhamsingh2e/bourbon\s+(\S+)`)
+	// bourbonReplacePath matches the target of a replace directive for Bourbon
+	bourbonReplacePath = regexp.MustCompile(`(?m)^replace\s+github\.com/ishubhamsingh2e/bourbon(?:\s+\S+)?\s+=>\s+(\S+)\s*$`)
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade [version]",
+	Short: "Upgrade the project to a newer Bourbon release",
+	Long: `Upgrades the project in the current directory to a Bourbon release, the latest
+by default: it updates the dependency with go get, rewrites the code for known
+breaking changes, and lists what has to be changed by hand. Check the rewrites
+with git diff before committing them.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		goMod, err := os.ReadFile("go.mod")
+		if os.IsNotExist(err) {
+			return fmt.Errorf("must run from project root (go.mod not found)")
+		}
+		if err != nil {
+			return err
+		}
+		version := "latest"
+		if len(args) == 1 {
+			version = args[0]
+		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		settings, _ := cmd.Flags().GetString("settings")
+
+		var manual []upgrade.Note
+		from := requiredBourbon(goMod)
+		if match := bourbonReplacePath.FindSubmatch(goMod); match != nil {
+			// go get can't move a replaced module
+			path := string(match[1])
+			if _, err := os.Stat(path); err != nil {
+				manual = append(manual, upgrade.Note{Position: "go.mod", Message: fmt.Sprintf("Bourbon is replaced by %s, which doesn't exist: remove the replace directive and run bourbon upgrade again", path)})
+			} else {
+				fmt.Printf("Bourbon comes from the local checkout at %s; update it there\n", path)
+			}
+		} else if dryRun {
+			fmt.Printf("Would upgrade Bourbon %s to %s\n", from, version)
+		} else {
+			fmt.Printf("🥃 Upgrading Bourbon %s to %s\n", from, version)
+			get := exec.Command("go", "get", bourbonModule+"@"+version)
+			get.Stdout = os.Stdout
+			get.Stderr = os.Stderr
+			if err := get.Run(); err != nil {
+				return fmt.Errorf("go get %s@%s failed: %w", bourbonModule, version, err)
+			}
+			if goMod, err = os.ReadFile("go.mod"); err == nil {
+				fmt.Printf("Now on %s\n", requiredBourbon(goMod))
+			}
+		}
+
+		report, err := upgrade.Run(".", dryRun)
+		if err != nil {
+			return err
+		}
+		manual = append(manual, report.Manual...)
+		if _, err := core.LoadConfig(settings); err != nil {
+			manual = append(manual, upgrade.Note{Position: settings, Message: err.Error()})
+		}
+
+		verb := "Rewrote"
+		if dryRun {
+			verb = "Would rewrite"
+		}
+		if len(report.Changes) > 0 {
+			fmt.Println()
+			for _, change := range report.Changes {
+				fmt.Printf("%s %s: %s (%d)\n", verb, change.Path, change.Codemod, change.Count)
+			}
+		}
+
+		if !dryRun && len(manual) == 0 {
+			build := exec.Command("go", "build", "./...")
+			build.Stderr = os.Stderr
+			if err := build.Run(); err != nil {
+				manual = append(manual, upgrade.Note{Message: "go build ./... fails: fix the errors above"})
+			}
+		}
+
+		if len(manual) > 0 {
+			fmt.Println("\nManual steps:")
+			for _, note := range manual {
+				if note.Position != "" {
+					fmt.Printf("  %s: %s\n", note.Position, note.Message)
+				} else {
+					fmt.Printf("  %s\n", note.Message)
+				}
+			}
+			return fmt.Errorf("%d manual step(s) left", len(manual))
+		}
+		switch {
+		case len(report.Changes) == 0:
+			fmt.Println("\nNo code changes needed")
+		case dryRun:
+			fmt.Println("\nNothing was changed (--dry-run)")
+			return nil
+		}
+		fmt.Println("\n✅ Upgrade complete. Review the changes with git diff.")
+		return nil
+	},
+}
+
+// requiredBourbon returns the Bourbon version go.mod requires
+func requiredBourbon(goMod []byte) string {
+	if match := bourbonRequire.FindSubmatch(goMod); match != nil {
+		return string(match[1])
+	}
+	return "(not required)"
+}
+
+// upgradeCodemods lists the codemods for the help text
+func upgradeCodemods() string {
+	var b strings.Builder
+	b.WriteString("\n\nCodemods:\n")
+	for _, codemod := range upgrade.Codemods() {
+		fmt.Fprintf(&b, "  %-14s %s\n", codemod.Name, codemod.Summary)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
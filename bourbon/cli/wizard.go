@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// newProjectOptions reads the options of bourbon new from its flags. Run in a
+// terminal without --yes, it asks for each option that wasn't given as a flag.
+func newProjectOptions(cmd *cobra.Command, name string) (projectOptions, error) {
+	flags := cmd.Flags()
+	opts := projectOptions{Name: name}
+	opts.Module, _ = flags.GetString("module")
+	opts.Database, _ = flags.GetString("db")
+	opts.Mode, _ = flags.GetString("mode")
+	opts.Auth, _ = flags.GetBool("auth")
+	opts.Docker, _ = flags.GetBool("docker")
+	opts.BourbonPath, _ = flags.GetString("bourbon-path")
+	opts.Template, _ = flags.GetString("template")
+
+	if yes, _ := flags.GetBool("yes"); yes || !isTerminal(os.Stdin) {
+		return opts, nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	var err error
+	if !flags.Changed("module") {
+		if opts.Module, err = ask(reader, "Module path", name); err != nil {
+			return opts, err
+		}
+	}
+	if !flags.Changed("db") {
+		if opts.Database, err = choose(reader, "Database", projectDatabases, opts.Database); err != nil {
+			return opts, err
+		}
+	}
+	// A template decides the rest of the layout
+	if opts.Template != "" {
+		fmt.Println()
+		return opts, nil
+	}
+	if !flags.Changed("mode") {
+		if opts.Mode, err = choose(reader, "Mode (html renders templates, api serves JSON)", projectModes, opts.Mode); err != nil {
+			return opts, err
+		}
+	}
+	if !flags.Changed("auth") {
+		if opts.Auth, err = confirm(reader, "Add user auth (register, login and me endpoints)?", opts.Auth); err != nil {
+			return opts, err
+		}
+	}
+	if !flags.Changed("docker") {
+		if opts.Docker, err = confirm(reader, "Add a Dockerfile and docker-compose.yml?", opts.Docker); err != nil {
+			return opts, err
+		}
+	}
+	fmt.Println()
+	return opts, nil
+}
+
+// isTerminal reports whether f is a terminal rather than a pipe or file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// ask prompts for a line of input, returning def when it is empty
+func ask(reader *bufio.Reader, label, def string) (string, error) {
+	fmt.Printf("%s [%s]: ", label, def)
+	line, err := reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	if line = strings.TrimSpace(line); line != "" {
+		return line, nil
+	}
+	return def, nil
+}
+
+// choose asks until the answer is one of choices
+func choose(reader *bufio.Reader, label string, choices []string, def string) (string, error) {
+	for {
+		answer, err := ask(reader, fmt.Sprintf("%s (%s)", label, strings.Join(choices, ", ")), def)
+		if err != nil {
+			return "", err
+		}
+		if answer = strings.ToLower(answer); slices.Contains(choices, answer) {
+			return answer, nil
+		}
+		fmt.Printf("Choose one of: %s\n", strings.Join(choices, ", "))
+	}
+}
+
+// confirm asks a yes/no question
+func confirm(reader *bufio.Reader, label string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		answer, err := ask(reader, label, hint)
+		if err != nil {
+			return false, err
+		}
+		if answer == hint {
+			return def, nil
+		}
+		switch strings.ToLower(answer) {
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Println("Answer y or n.")
+	}
+}
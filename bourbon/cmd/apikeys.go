@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/auth"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+)
+
+// newAPIKeyStore connects to the database and ensures the api_keys table exists
+func newAPIKeyStore() (*auth.APIKeyStore, error) {
+	app := core.NewApplication(SettingsPath())
+
+	if err := app.ConnectDB(); err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	store := auth.NewAPIKeyStore(orm.Primary(app.DB))
+	if err := store.Migrate(); err != nil {
+		return nil, fmt.Errorf("failed to migrate api_keys table: %w", err)
+	}
+	return store, nil
+}
+
+// handleAPIKeyCreate handles the apikey:create command
+func handleAPIKeyCreate(args []string) error {
+	fs := flag.NewFlagSet("apikey:create", flag.ContinueOnError)
+	scopes := fs.String("scopes", "", "Comma-separated scopes granted to the key")
+	expires := fs.Duration("expires", 0, "Key lifetime, e.g. 720h (default: never expires)")
+	userID := fs.Uint("user", 0, "ID of the user the key acts as")
+
+	name, rest := splitPositional(args)
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("usage: apikey:create <name> [--scopes=a,b] [--expires=720h] [--user=ID]")
+	}
+
+	opts := auth.APIKeyOptions{TTL: *expires}
+	if *scopes != "" {
+		opts.Scopes = strings.Split(*scopes, ",")
+	}
+	if *userID > 0 {
+		id := *userID
+		opts.UserID = &id
+	}
+
+	store, err := newAPIKeyStore()
+	if err != nil {
+		return err
+	}
+
+	key, plaintext, err := store.Create(name, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("API key created: %s (prefix: %s)\n", key.Name, key.Prefix)
+	fmt.Printf("\n  %s\n\n", plaintext)
+	fmt.Println("Store this key now - it cannot be shown again.")
+	return nil
+}
+
+// handleAPIKeyRevoke handles the apikey:revoke command
+func handleAPIKeyRevoke(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: apikey:revoke <prefix>")
+	}
+
+	store, err := newAPIKeyStore()
+	if err != nil {
+		return err
+	}
+
+	if err := store.Revoke(args[0]); err != nil {
+		return err
+	}
+
+	fmt.Printf("API key %s revoked\n", args[0])
+	return nil
+}
+
+// handleAPIKeyList handles the apikey:list command
+func handleAPIKeyList(args []string) error {
+	store, err := newAPIKeyStore()
+	if err != nil {
+		return err
+	}
+
+	keys, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		fmt.Println("No API keys found")
+		return nil
+	}
+
+	for _, key := range keys {
+		status := "active"
+		switch {
+		case key.RevokedAt != nil:
+			status = "revoked"
+		case key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt):
+			status = "expired"
+		}
+
+		lastUsed := "never"
+		if key.LastUsedAt != nil {
+			lastUsed = key.LastUsedAt.Format("2006-01-02 15:04:05")
+		}
+
+		fmt.Printf("  %s  %-20s [%s] scopes=%s last_used=%s\n", key.Prefix, key.Name, status, key.Scopes, lastUsed)
+	}
+	return nil
+}
+
+// splitPositional separates a leading positional argument from trailing flags
+func splitPositional(args []string) (string, []string) {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		return args[0], args[1:]
+	}
+	return "", args
+}
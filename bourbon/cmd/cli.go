@@ -1,12 +1,15 @@
 package cmd
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core/registry"
 	_ "github.com/ishubhamsingh2e/bourbon/bourbon/database/drivers"
-	"github.com/ishubhamsingh2e/bourbon/bourbon/middleware"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/migrationgen"
 	"go.uber.org/zap"
 )
 
@@ -14,23 +17,101 @@ import (
 type CommandHandler func(args []string) error
 
 // commandRegistry holds all registered commands
-var commandRegistry = map[string]CommandHandler{
-	"make:migration":   handleMakeMigration,
-	"migrate":          handleMigrate,
-	"migrate:status":   handleMigrateStatus,
-	"migrate:rollback": handleMigrateRollback,
+var commandRegistry = map[string]*Command{
+	"make:migration":         builtin(handleMakeMigration, "Create migrations for model changes"),
+	"migrate":                builtin(handleMigrate, "Run pending migrations"),
+	"migrate:status":         builtin(handleMigrateStatus, "List applied and pending migrations"),
+	"migrate:rollback":       builtin(handleMigrateRollback, "Roll back the latest migrations"),
+	"migrate:fresh":          builtin(handleMigrateFresh, "Drop every table and run all migrations"),
+	"migrate:reset":          builtin(handleMigrateReset, "Roll back every migration and run them all again"),
+	"migrate:sql":            builtin(handleMigrateSQL, "Print the SQL pending migrations would run"),
+	"migrate:plan":           builtin(handleMigratePlan, "List the migrations migrate would run, in order"),
+	"migrate:lint":           builtin(handleMigrateLint, "Check migrations for operations that lock or lose data"),
+	"migrate:mark-applied":   builtin(handleMigrateMarkApplied, "Record migrations as applied without running them"),
+	"migrate:mark-unapplied": builtin(handleMigrateMarkUnapplied, "Record migrations as not applied without rolling them back"),
+	"migrate:repair":         builtin(handleMigrateRepair, "Report recorded migrations that no longer exist"),
+	"user:create":            builtin(handleUserCreate, "Create a user"),
+	"createsuperuser":        builtin(handleCreateSuperuser, "Create a user with the superuser role"),
+	"role:create":            builtin(handleRoleCreate, "Create a role"),
+	"role:assign":            builtin(handleRoleAssign, "Give a user a role"),
+	"permission:grant":       builtin(handlePermissionGrant, "Grant a role a permission"),
+	"permission:revoke":      builtin(handlePermissionRevoke, "Revoke a permission from a role"),
+	"apikey:create":          builtin(handleAPIKeyCreate, "Create an API key"),
+	"apikey:revoke":          builtin(handleAPIKeyRevoke, "Revoke an API key"),
+	"apikey:list":            builtin(handleAPIKeyList, "List API keys"),
+	"collectstatic":          builtin(handleCollectStatic, "Copy static files to static.collect_dir with hashed names"),
+	"seed":                   builtin(handleSeed, "Run seeders"),
+	"seed:run":               builtin(handleSeed, "Run seeders (same as seed)"),
+	"make:seeder":            builtin(handleMakeSeeder, "Create a seeder"),
+	"make:controller":        builtin(handleMakeController, "Create a controller"),
+	"make:model":             builtin(handleMakeModel, "Create a model"),
+	"make:middleware":        builtin(handleMakeMiddleware, "Create a middleware"),
+	"make:command":           builtin(handleMakeCommand, "Create a command"),
+	"make:job":               builtin(handleMakeJob, "Create a job"),
+	"make:test":              builtin(handleMakeTest, "Create a test for a controller's routes"),
+	"db:prune":               builtin(handleDBPrune, "Delete rows soft-deleted longer ago than the retention window"),
+	"search:reindex":         builtin(handleSearchReindex, "Rebuild search indexes"),
+	"openapi:generate":       builtin(handleOpenAPIGenerate, "Write the OpenAPI document for the routes"),
+	"dev":                    builtin(handleDev, "Run the app under the dev server with live reload"),
+	"config:key":             builtin(handleConfigKey, "Print a new master key"),
+	"config:encrypt":         builtin(handleConfigEncrypt, "Encrypt a settings value"),
+	"config:decrypt":         builtin(handleConfigDecrypt, "Decrypt a settings value"),
+	"config:rotate":          builtin(handleConfigRotate, "Re-encrypt settings values with a new master key"),
+	"shell":                  builtin(handleShell, "Start a console over the app's database"),
+	"version":                builtin(handleVersion, "Print the build version"),
+	"create:app":             createAppCommand,
+	"generate:docker":        generateDockerCommand,
+	"generate:k8s":           generateK8sCommand,
+	"worker":                 workerCommand,
+	"stream:consume":         streamConsumeCommand,
 }
 
-// RegisterCommand allows users to register custom commands
+func init() {
+	// help lists the registry, so it can't be in its initializer
+	commandRegistry["help"] = helpCommand
+	for name, command := range commandRegistry {
+		command.Name = name
+	}
+}
+
+// builtin wraps a built-in command's handler
+func builtin(handler CommandHandler, description string) *Command {
+	return &Command{Description: description, handler: handler}
+}
+
+// RegisterCommand allows users to register custom commands. Register takes a
+// Command with flags, argument checks and help instead.
 func RegisterCommand(name string, handler CommandHandler) {
-	commandRegistry[name] = handler
+	commandRegistry[name] = &Command{Name: name, handler: handler}
+}
+
+// ConfigEnv names the settings file to load instead of the one passed to Run,
+// for deployments that don't run from the project directory
+const ConfigEnv = "BOURBON_CONFIG"
+
+// defaultSettings is the settings file passed to Run
+var defaultSettings = "./settings.toml"
+
+// SettingsPath returns the settings file the server and commands load: the
+// --config flag, BOURBON_CONFIG, or the path passed to Run
+func SettingsPath() string {
+	if path := os.Getenv(ConfigEnv); path != "" {
+		return path
+	}
+	return defaultSettings
 }
 
 // Run is the main entry point for Bourbon applications
 // It handles both CLI commands and server startup
 func Run(configPath string) {
-	if len(os.Args) > 1 {
-		if err := HandleCommand(os.Args[1:]); err != nil {
+	defaultSettings = configPath
+	args, err := parseConfigFlag(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(args) > 0 {
+		if err := HandleCommand(args); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -38,58 +119,104 @@ func Run(configPath string) {
 	}
 
 	// Normal server startup
-	StartServer(configPath)
+	StartServer(SettingsPath())
+}
+
+// parseConfigFlag removes a --config flag given before the command and sets
+// BOURBON_CONFIG from it, so processes the command starts, such as the app
+// under the dev server, load the same file
+func parseConfigFlag(args []string) ([]string, error) {
+	for len(args) > 0 {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[0], "-"), "=")
+		if !strings.HasPrefix(args[0], "-") || name != "config" {
+			break
+		}
+		args = args[1:]
+		if !hasValue {
+			if len(args) == 0 {
+				return nil, fmt.Errorf("--config needs a settings file")
+			}
+			value, args = args[0], args[1:]
+		}
+		if _, err := os.Stat(value); err != nil {
+			return nil, fmt.Errorf("--config: %w", err)
+		}
+		os.Setenv(ConfigEnv, value)
+	}
+	return args, nil
 }
 
-// HandleCommand processes CLI commands
+// HandleCommand processes CLI commands. A --config flag may come before the
+// command.
 func HandleCommand(args []string) error {
+	args, err := parseConfigFlag(args)
+	if err != nil {
+		return err
+	}
 	if len(args) == 0 {
 		return fmt.Errorf("no command specified")
 	}
 
-	command := args[0]
-	handler, exists := commandRegistry[command]
+	name := args[0]
+	if name == "-h" || name == "--help" {
+		name = "help"
+	}
+	command, exists := commandRegistry[name]
 	if !exists {
-		return fmt.Errorf("unknown command: %s", command)
+		return fmt.Errorf("unknown command: %s (run help for the list)", name)
 	}
 
-	return handler(args[1:])
+	if err := command.execute(args[1:]); err != nil && !isHelp(err) {
+		return err
+	}
+	return nil
 }
 
 // StartServer initializes and starts the Bourbon server
 func StartServer(configPath string) {
+	app, err := setupApp(configPath)
+	if err != nil {
+		app.Logger.Error("Failed to start", zap.Error(err))
+		os.Exit(1)
+	}
+
+	// Start the server
+	if err := app.Run(); err != nil {
+		app.Logger.Error("Server error", zap.Error(err))
+	}
+}
+
+// setupApp connects the database and registers middleware and routes, as the server does
+func setupApp(configPath string) (*core.Application, error) {
 	app := core.NewApplication(configPath)
 
 	// Initialize database
 	if err := app.ConnectDB(); err != nil {
-		app.Logger.Error("Failed to connect to database", zap.Error(err))
-		os.Exit(1)
+		return app, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	// Liveness and readiness probes: /healthz and /readyz
+	app.MountHealth()
+
 	// Call custom initialization hook if registered
 	// This is where user's middleware.go SetupMiddleware is called
 	if customInit != nil {
 		if err := customInit(app); err != nil {
-			app.Logger.Error("Custom initialization failed", zap.Error(err))
-			os.Exit(1)
+			return app, fmt.Errorf("custom initialization failed: %w", err)
 		}
 	} else {
 		// If no custom init, setup default middlewares as fallback
 		SetupDefaultMiddlewares(app)
 	}
-
-	// Start the server
-	if err := app.Run(); err != nil {
-		app.Logger.Error("Server error", zap.Error(err))
-	}
+	return app, nil
 }
 
 // SetupDefaultMiddlewares configures the default middleware stack
 func SetupDefaultMiddlewares(app *core.Application) {
-	app.RegisterMiddleware("recovery", middleware.Recovery(app.Logger, app.ErrorStore))
+	app.RegisterMiddleware("recovery", app.Recoverer(), registry.Priority(1000))
 	app.UseMiddleware("recovery")
 
-	app.RegisterMiddleware("logger", middleware.Logger(app.Logger, app.ErrorStore))
+	app.RegisterMiddleware("logger", app.AccessLogger())
 	app.UseMiddleware("logger")
 }
 
@@ -103,21 +230,104 @@ func SetCustomInit(fn func(*core.Application) error) {
 
 // handleMakeMigration handles the make:migration command
 func handleMakeMigration(args []string) error {
-	name := ""
-	if len(args) > 0 {
-		name = args[0]
+	fs := flag.NewFlagSet("make:migration", flag.ContinueOnError)
+	appName := fs.String("app", "", "App to create the migration in (default: every app with changes)")
+	nameFlag := fs.String("name", "", "Migration name (same as the positional name)")
+	force := fs.Bool("force", false, "Create an empty migration when no changes are detected")
+	sql := fs.Bool("sql", false, "Create empty .up.sql and .down.sql files instead of detecting model changes")
+	data := fs.Bool("data", false, "Create an empty data migration instead of detecting model changes")
+	yes := fs.Bool("yes", false, "Don't ask; accept destructive changes")
+	noInput := fs.Bool("no-input", false, "Don't ask; assume no renames and refuse destructive changes")
+	jsonOut := fs.Bool("json", false, "Print the created migrations as JSON (implies --no-input)")
+	tidyProject := tidyFlag(fs)
+
+	name, rest := splitPositional(args)
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+	if name == "" && fs.NArg() > 0 {
+		name = fs.Arg(0)
+	}
+	if name == "" {
+		name = *nameFlag
+	}
+
+	g := &migrationgen.Generator{Force: *force}
+	switch {
+	case *yes:
+		g.Input = migrationgen.AssumeYes
+	case *noInput || *jsonOut:
+		g.Input = migrationgen.NoInput
+	}
+	var write func(v any) error
+	if *jsonOut {
+		write = jsonOutput()
+	}
+
+	if err := generateMigration(g, *appName, name, *sql, *data); err != nil {
+		return err
+	}
+	if err := tidy(*tidyProject); err != nil {
+		return err
+	}
+	if write != nil {
+		created := g.Created
+		if created == nil {
+			created = []migrationgen.Migration{}
+		}
+		return write(map[string]any{"migrations": created})
+	}
+	return nil
+}
+
+// generateMigration runs the generator make:migration's flags select
+func generateMigration(g *migrationgen.Generator, appName, name string, sql, data bool) error {
+	if appName == "" && !sql && !data {
+		return g.All(name)
+	}
+	if appName == "" {
+		defaultApp, err := migrationgen.DefaultApp()
+		if err != nil {
+			return err
+		}
+		appName = defaultApp
+	}
+	switch {
+	case sql:
+		return g.SQL(appName, name)
+	case data:
+		return g.Data(appName, name)
 	}
-	return GenerateMigration(name)
+	return g.App(appName, name)
 }
 
 // handleMigrate handles the migrate command
 func handleMigrate(args []string) error {
-	app := core.NewApplication("./settings.toml")
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	fake := fs.String("fake", "", "Mark pending migrations up to this ID as applied without running them")
+	jsonOut := fs.Bool("json", false, "Print the applied migrations as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *jsonOut {
+		return migrationsJSON("applied", func(app *core.Application) error {
+			if *fake != "" {
+				return core.FakeMigrations(app, *fake)
+			}
+			return core.RunMigrations(app)
+		})
+	}
+
+	app := core.NewApplication(SettingsPath())
 
 	if err := app.ConnectDB(); err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	if *fake != "" {
+		return core.FakeMigrations(app, *fake)
+	}
+
 	fmt.Println("Running migrations...")
 	if err := core.RunMigrations(app); err != nil {
 		return fmt.Errorf("migration failed: %w", err)
@@ -129,25 +339,230 @@ func handleMigrate(args []string) error {
 
 // handleMigrateStatus handles the migrate:status command
 func handleMigrateStatus(args []string) error {
-	app := core.NewApplication("./settings.toml")
+	fs := flag.NewFlagSet("migrate:status", flag.ContinueOnError)
+	jsonOut := fs.Bool("json", false, "Print the migrations as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var write func(v any) error
+	if *jsonOut {
+		write = jsonOutput()
+	}
+
+	app := core.NewApplication(SettingsPath())
 
 	if err := app.ConnectDB(); err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	if write != nil {
+		migrations, err := core.MigrationStatus(app)
+		if err != nil {
+			return err
+		}
+		return write(map[string]any{"migrations": migrations})
+	}
 	return core.ShowMigrationStatus(app)
 }
 
+// handleMigrateSQL handles the migrate:sql command: it prints the SQL the pending
+// migrations would run, without running it
+func handleMigrateSQL(args []string) error {
+	fs := flag.NewFlagSet("migrate:sql", flag.ContinueOnError)
+	rollback := fs.Bool("rollback", false, "Print the SQL migrate:rollback would run instead")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	app := core.NewApplication(SettingsPath())
+
+	if err := app.ConnectDB(); err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	return core.ShowMigrationSQL(app, *rollback)
+}
+
+// handleMigratePlan handles the migrate:plan command
+func handleMigratePlan(args []string) error {
+	fs := flag.NewFlagSet("migrate:plan", flag.ContinueOnError)
+	check := fs.Bool("check", false, "Exit with an error when migrations are pending")
+	jsonOut := fs.Bool("json", false, "Print the pending migrations as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var write func(v any) error
+	if *jsonOut {
+		write = jsonOutput()
+	}
+
+	app := core.NewApplication(SettingsPath())
+
+	if err := app.ConnectDB(); err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	if write != nil {
+		pending, err := core.MigrationPlan(app)
+		if err != nil {
+			return err
+		}
+		if pending == nil {
+			pending = []core.PlannedMigration{}
+		}
+		if err := write(map[string]any{"pending": pending}); err != nil {
+			return err
+		}
+		if *check && len(pending) > 0 {
+			return fmt.Errorf("%d unapplied migration(s)", len(pending))
+		}
+		return nil
+	}
+	return core.ShowMigrationPlan(app, *check)
+}
+
+// handleMigrateLint handles the migrate:lint command
+func handleMigrateLint(args []string) error {
+	fs := flag.NewFlagSet("migrate:lint", flag.ContinueOnError)
+	check := fs.Bool("check", false, "Exit with an error when unsafe operations are found")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	app := core.NewApplication(SettingsPath())
+
+	if err := app.ConnectDB(); err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	return core.LintMigrations(app, *check)
+}
+
+// handleMigrateMarkApplied handles the migrate:mark-applied command
+func handleMigrateMarkApplied(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate:mark-applied <id> [id...]")
+	}
+	app := core.NewApplication(SettingsPath())
+
+	if err := app.ConnectDB(); err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	return core.MarkMigrationsApplied(app, args)
+}
+
+// handleMigrateMarkUnapplied handles the migrate:mark-unapplied command
+func handleMigrateMarkUnapplied(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate:mark-unapplied <id> [id...]")
+	}
+	app := core.NewApplication(SettingsPath())
+
+	if err := app.ConnectDB(); err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	return core.MarkMigrationsUnapplied(app, args)
+}
+
+// handleMigrateRepair handles the migrate:repair command
+func handleMigrateRepair(args []string) error {
+	fs := flag.NewFlagSet("migrate:repair", flag.ContinueOnError)
+	fix := fs.Bool("fix", false, "Remove applied IDs that no migration registers")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	app := core.NewApplication(SettingsPath())
+
+	if err := app.ConnectDB(); err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	return core.RepairMigrations(app, *fix)
+}
+
+// handleMigrateFresh handles the migrate:fresh command: it drops every table and
+// runs all migrations again
+func handleMigrateFresh(args []string) error {
+	return rebuildDatabase("migrate:fresh", args, func(app *core.Application) error {
+		fmt.Println("Dropping all tables...")
+		return core.DropAllTables(app)
+	})
+}
+
+// handleMigrateReset handles the migrate:reset command: it rolls back every
+// migration and runs them all again
+func handleMigrateReset(args []string) error {
+	return rebuildDatabase("migrate:reset", args, func(app *core.Application) error {
+		fmt.Println("Rolling back all migrations...")
+		return core.ResetMigrations(app)
+	})
+}
+
+// rebuildDatabase empties the database with clear, then runs all migrations and,
+// with --seed, the seeders. It refuses to run with debug off unless --force is
+// given, since everything in the database is lost.
+func rebuildDatabase(name string, args []string, clear func(app *core.Application) error) error {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	seed := fs.Bool("seed", false, "Run the seeders afterwards")
+	force := fs.Bool("force", false, "Run even when app.debug is off")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	app := core.NewApplication(SettingsPath())
+	if !app.Config.App.Debug && !*force {
+		return fmt.Errorf("%s deletes all data and is meant for development; app.debug is off, pass --force to run it anyway", name)
+	}
+
+	if err := app.ConnectDB(); err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	if err := clear(app); err != nil {
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+
+	fmt.Println("Running migrations...")
+	if err := core.RunMigrations(app); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
+	if *seed {
+		return seedDatabase(app, nil)
+	}
+	return nil
+}
+
 // handleMigrateRollback handles the migrate:rollback command
 func handleMigrateRollback(args []string) error {
-	app := core.NewApplication("./settings.toml")
+	fs := flag.NewFlagSet("migrate:rollback", flag.ContinueOnError)
+	steps := fs.Int("steps", 0, "Number of migrations to roll back (default 1, or all after --to)")
+	appName := fs.String("app", "", "Only roll back this app's migrations")
+	to := fs.String("to", "", "Roll back the migrations after this ID")
+	jsonOut := fs.Bool("json", false, "Print the rolled back migrations as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *steps < 0 {
+		return fmt.Errorf("--steps must be positive")
+	}
+	if *jsonOut {
+		return migrationsJSON("rolled_back", func(app *core.Application) error {
+			return core.RollbackMigrations(app, *appName, *to, *steps)
+		})
+	}
+
+	app := core.NewApplication(SettingsPath())
 
 	if err := app.ConnectDB(); err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	fmt.Println("Rolling back last migration...")
-	if err := core.RollbackLastMigration(app); err != nil {
+	fmt.Println("Rolling back migrations...")
+	if err := core.RollbackMigrations(app, *appName, *to, *steps); err != nil {
 		return fmt.Errorf("rollback failed: %w", err)
 	}
 
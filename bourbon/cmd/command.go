@@ -0,0 +1,346 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+)
+
+// Command is a CLI command with its own flags, argument checks and help:
+//
+//	cmd.Register(&cmd.Command{
+//	    Name:        "reports:send",
+//	    Usage:       "<email>...",
+//	    Description: "Email the weekly report",
+//	    Flags: func(fs *flag.FlagSet) {
+//	        fs.Bool("dry-run", false, "Print the report instead of sending it")
+//	    },
+//	    Args: cmd.MinimumArgs(1),
+//	    Run: func(c *cmd.CommandContext) error {
+//	        app, err := c.App()
+//	        if err != nil {
+//	            return err
+//	        }
+//	        return reports.Send(app, c.Args, c.Bool("dry-run"))
+//	    },
+//	})
+type Command struct {
+	Name        string
+	Usage       string                 // arguments after the name, e.g. "<email> [role]"
+	Description string                 // one line for the help listing
+	Long        string                 // shown by help <name> under the description
+	Flags       func(fs *flag.FlagSet) // defines the command's flags
+	Args        ArgsValidator          // checks the positional arguments; any are accepted if nil
+	Run         func(c *CommandContext) error
+
+	// handler runs commands given as a plain function, by RegisterCommand and
+	// the built-in commands, which parse their own flags
+	handler CommandHandler
+}
+
+// ArgsValidator checks a command's positional arguments
+type ArgsValidator func(args []string) error
+
+// NoArgs accepts no positional arguments
+func NoArgs(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("unexpected argument %q", args[0])
+	}
+	return nil
+}
+
+// ExactArgs accepts exactly n positional arguments
+func ExactArgs(n int) ArgsValidator {
+	return RangeArgs(n, n)
+}
+
+// MinimumArgs accepts at least n positional arguments
+func MinimumArgs(n int) ArgsValidator {
+	return RangeArgs(n, -1)
+}
+
+// MaximumArgs accepts at most n positional arguments
+func MaximumArgs(n int) ArgsValidator {
+	return RangeArgs(0, n)
+}
+
+// RangeArgs accepts min to max positional arguments; max < 0 means no limit
+func RangeArgs(min, max int) ArgsValidator {
+	return func(args []string) error {
+		switch {
+		case len(args) < min && min == max:
+			return fmt.Errorf("expected %d argument(s), got %d", min, len(args))
+		case len(args) < min:
+			return fmt.Errorf("expected at least %d argument(s), got %d", min, len(args))
+		case max >= 0 && len(args) > max:
+			return fmt.Errorf("expected at most %d argument(s), got %d", max, len(args))
+		}
+		return nil
+	}
+}
+
+// CommandContext is what a Command's Run gets: its arguments, its parsed flags
+// and the application
+type CommandContext struct {
+	Command *Command
+	Args    []string      // positional arguments, with the flags removed
+	Flags   *flag.FlagSet // parsed flags
+
+	app *core.Application
+}
+
+// Arg returns the i-th positional argument, or "" when there are fewer
+func (c *CommandContext) Arg(i int) string {
+	if i < len(c.Args) {
+		return c.Args[i]
+	}
+	return ""
+}
+
+// App sets up the application like the server does, connecting the database
+// and running the SetCustomInit function, the first time it is called
+func (c *CommandContext) App() (*core.Application, error) {
+	if c.app != nil {
+		return c.app, nil
+	}
+	app, err := setupApp(SettingsPath())
+	if err != nil {
+		return nil, err
+	}
+	c.app = app
+	return app, nil
+}
+
+// String returns the value of a string flag
+func (c *CommandContext) String(name string) string {
+	return c.flag(name).(string)
+}
+
+// Bool returns the value of a bool flag
+func (c *CommandContext) Bool(name string) bool {
+	return c.flag(name).(bool)
+}
+
+// Int returns the value of an int flag
+func (c *CommandContext) Int(name string) int {
+	return c.flag(name).(int)
+}
+
+// Duration returns the value of a duration flag
+func (c *CommandContext) Duration(name string) time.Duration {
+	return c.flag(name).(time.Duration)
+}
+
+// flag returns a flag's value, panicking when the command doesn't define it,
+// which is a mistake in the command rather than in its input
+func (c *CommandContext) flag(name string) any {
+	f := c.Flags.Lookup(name)
+	if f == nil {
+		panic(fmt.Sprintf("cmd: %s has no --%s flag", c.Command.Name, name))
+	}
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		panic(fmt.Sprintf("cmd: --%s of %s has no typed value", name, c.Command.Name))
+	}
+	return getter.Get()
+}
+
+// Register adds a command, replacing a built-in or registered one of the same name
+func Register(command *Command) {
+	if command.Name == "" || (command.Run == nil && command.handler == nil) {
+		panic("cmd: Register needs a command with a Name and a Run function")
+	}
+	commandRegistry[command.Name] = command
+}
+
+// flagSet creates the command's flag set, with -h printing its help
+func (c *Command) flagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet(c.Name, flag.ContinueOnError)
+	if c.Flags != nil {
+		c.Flags(fs)
+	}
+	fs.Usage = func() { c.printHelp(fs.Output()) }
+	return fs
+}
+
+// execute parses the arguments and runs the command. Flags may come before,
+// between or after the positional arguments; everything after -- is positional.
+func (c *Command) execute(args []string) error {
+	if c.handler != nil {
+		return c.handler(args)
+	}
+
+	fs := c.flagSet()
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			break
+		}
+		// fs.Parse stops at the first positional argument, or after --
+		if len(args) > len(rest) && args[len(args)-len(rest)-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+
+	if c.Args != nil {
+		if err := c.Args(positional); err != nil {
+			return fmt.Errorf("%s: %w\nusage: %s", c.Name, err, c.synopsis())
+		}
+	}
+	return c.Run(&CommandContext{Command: c, Args: positional, Flags: fs})
+}
+
+// synopsis is the command line for the command's usage
+func (c *Command) synopsis() string {
+	line := "go run . " + c.Name
+	if c.Flags != nil {
+		line += " [flags]"
+	}
+	if c.Usage != "" {
+		line += " " + c.Usage
+	}
+	return line
+}
+
+// printHelp prints the usage, description and flags of the command
+func (c *Command) printHelp(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s\n", c.synopsis())
+	if c.Description != "" {
+		fmt.Fprintf(w, "\n%s\n", c.Description)
+	}
+	if c.Long != "" {
+		fmt.Fprintf(w, "\n%s\n", strings.TrimSpace(c.Long))
+	}
+	if c.handler != nil {
+		fmt.Fprintf(w, "\nRun go run . %s -h for its flags.\n", c.Name)
+		return
+	}
+	fs := flag.NewFlagSet(c.Name, flag.ContinueOnError)
+	if c.Flags != nil {
+		c.Flags(fs)
+	}
+	hasFlags := false
+	fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		fmt.Fprintln(w, "\nFlags:")
+		fs.SetOutput(w)
+		fs.PrintDefaults()
+	}
+}
+
+// helpCommand is the help command. It is added to the registry in init, as it
+// lists the registry.
+var helpCommand = &Command{
+	Usage:       "[command]",
+	Description: "List commands, or show a command's help",
+	Flags: func(fs *flag.FlagSet) {
+		fs.Bool("json", false, "List the commands as JSON, for the bourbon CLI")
+	},
+	Args: MaximumArgs(1),
+	Run: func(c *CommandContext) error {
+		if len(c.Args) > 0 {
+			command, ok := commandRegistry[c.Arg(0)]
+			if !ok {
+				return fmt.Errorf("unknown command: %s", c.Arg(0))
+			}
+			command.printHelp(os.Stdout)
+			return nil
+		}
+		if c.Bool("json") {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(Commands())
+		}
+		printCommands(os.Stdout)
+		return nil
+	},
+}
+
+// CommandInfo describes a command for listings, such as the bourbon CLI's
+type CommandInfo struct {
+	Name        string `json:"name"`
+	Usage       string `json:"usage,omitempty"`
+	Description string `json:"description"`
+}
+
+// Commands returns the built-in and registered commands, sorted by name
+func Commands() []CommandInfo {
+	commands := make([]CommandInfo, 0, len(commandRegistry))
+	for name, command := range commandRegistry {
+		commands = append(commands, CommandInfo{Name: name, Usage: command.Usage, Description: command.Description})
+	}
+	sort.Slice(commands, func(i, j int) bool { return commands[i].Name < commands[j].Name })
+	return commands
+}
+
+// cliCommands are the commands only the bourbon CLI has, as they create, build
+// or upgrade the project rather than run inside it
+var cliCommands = []CommandInfo{
+	{Name: "new", Usage: "<name>", Description: "Create a new project"},
+	{Name: "build", Description: "Compile the project into a production binary"},
+	{Name: "upgrade", Usage: "[version]", Description: "Upgrade the project to a newer Bourbon release"},
+	{Name: "serve", Description: "Run the project with live reload"},
+}
+
+// printCommands lists every command, grouped by the namespace before the colon
+func printCommands(w io.Writer) {
+	names := make([]string, 0, len(commandRegistry))
+	for name := range commandRegistry {
+		names = append(names, name)
+	}
+	namespace := func(name string) string {
+		if i := strings.Index(name, ":"); i > 0 {
+			return name[:i]
+		}
+		return ""
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if a, b := namespace(names[i]), namespace(names[j]); a != b {
+			return a < b
+		}
+		return names[i] < names[j]
+	})
+
+	fmt.Fprintln(w, "Usage: go run . [--config=settings.toml] [command] [flags] [args]")
+	fmt.Fprintln(w, "\nWithout a command the server starts. Run go run . help <command> for a command's flags.")
+	fmt.Fprintf(w, "--config, or the %s environment variable, selects the settings file (now %s).\n", ConfigEnv, SettingsPath())
+	fmt.Fprintln(w, "\nCommands:")
+	width := 0
+	for _, name := range names {
+		width = max(width, len(name))
+	}
+	current := ""
+	for _, name := range names {
+		if ns := namespace(name); ns != current {
+			current = ns
+			fmt.Fprintf(w, " %s\n", ns)
+		}
+		fmt.Fprintf(w, "  %-*s   %s\n", width, name, commandRegistry[name].Description)
+	}
+
+	fmt.Fprintln(w, "\nbourbon CLI commands, which also runs every command above as bourbon <command>:")
+	for _, command := range cliCommands {
+		fmt.Fprintf(w, "  %-*s   %s\n", width, command.Name, command.Description)
+	}
+}
+
+// isHelp reports whether err is the flag package's answer to -h or --help,
+// after which the help has already been printed
+func isHelp(err error) bool {
+	return errors.Is(err, flag.ErrHelp)
+}
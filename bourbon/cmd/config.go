@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+)
+
+// handleConfigKey handles the config:key command: it prints a new master key
+func handleConfigKey(args []string) error {
+	key, err := core.GenerateMasterKey()
+	if err != nil {
+		return err
+	}
+	fmt.Println(key)
+	fmt.Fprintf(os.Stderr, "Set %s to this key where the app runs; it is not stored anywhere else.\n", core.MasterKeyEnv)
+	return nil
+}
+
+// handleConfigEncrypt handles the config:encrypt command. The value is read from
+// stdin when no argument is given, so it does not end up in the shell history.
+func handleConfigEncrypt(args []string) error {
+	masterKey, err := masterKey()
+	if err != nil {
+		return err
+	}
+	value, err := argOrStdin(args, "config:encrypt [value]")
+	if err != nil {
+		return err
+	}
+	encrypted, err := core.EncryptValue(value, masterKey)
+	if err != nil {
+		return err
+	}
+	fmt.Println(encrypted)
+	return nil
+}
+
+// handleConfigDecrypt handles the config:decrypt command
+func handleConfigDecrypt(args []string) error {
+	masterKey, err := masterKey()
+	if err != nil {
+		return err
+	}
+	value, err := argOrStdin(args, "config:decrypt [enc:value]")
+	if err != nil {
+		return err
+	}
+	plaintext, err := core.DecryptValue(value, masterKey)
+	if err != nil {
+		return err
+	}
+	fmt.Println(plaintext)
+	return nil
+}
+
+// encryptedValue matches the enc: values in a settings file
+var encryptedValue = regexp.MustCompile(regexp.QuoteMeta(core.EncryptedPrefix) + `[A-Za-z0-9+/=]+`)
+
+// handleConfigRotate handles the config:rotate command: it re-encrypts the enc:
+// values in settings.toml and its overlays with a new master key, leaving the rest
+// of the files untouched
+func handleConfigRotate(args []string) error {
+	fs := flag.NewFlagSet("config:rotate", flag.ContinueOnError)
+	newKey := fs.String("new-key", "", "New master key (generate one with config:key)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *newKey == "" {
+		return fmt.Errorf("usage: config:rotate --new-key=KEY [settings files...]")
+	}
+	oldKey, err := masterKey()
+	if err != nil {
+		return err
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		settings := SettingsPath()
+		ext := filepath.Ext(settings)
+		overlays, _ := filepath.Glob(strings.TrimSuffix(settings, ext) + ".*" + ext)
+		files = append([]string{settings}, overlays...)
+	}
+
+	// Decrypt every file before writing any, so a wrong key changes nothing
+	rotated := make(map[string][]byte, len(files))
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		var rotateErr error
+		count := 0
+		updated := encryptedValue.ReplaceAllFunc(content, func(value []byte) []byte {
+			plaintext, err := core.DecryptValue(string(value), oldKey)
+			if err == nil {
+				var encrypted string
+				encrypted, err = core.EncryptValue(plaintext, *newKey)
+				value = []byte(encrypted)
+			}
+			if err != nil && rotateErr == nil {
+				rotateErr = fmt.Errorf("%s: %w", file, err)
+			}
+			count++
+			return value
+		})
+		if rotateErr != nil {
+			return rotateErr
+		}
+		if count > 0 {
+			rotated[file] = updated
+		}
+		fmt.Printf("%s: %d values\n", file, count)
+	}
+
+	for file, content := range rotated {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(file, content, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Re-encrypted. Set %s to the new key before the next deploy.\n", core.MasterKeyEnv)
+	return nil
+}
+
+// masterKey reads the master key from the environment or the .env file
+func masterKey() (string, error) {
+	if err := core.LoadEnvFile(core.EnvFile); err != nil {
+		return "", err
+	}
+	key := os.Getenv(core.MasterKeyEnv)
+	if key == "" {
+		return "", fmt.Errorf("%s is not set (generate a key with config:key)", core.MasterKeyEnv)
+	}
+	return key, nil
+}
+
+// argOrStdin returns the single argument, or the first line of stdin without it
+func argOrStdin(args []string, usage string) (string, error) {
+	switch len(args) {
+	case 0:
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" && err != nil {
+			return "", fmt.Errorf("usage: %s (or pass the value on stdin)", usage)
+		}
+		return line, nil
+	case 1:
+		return args[0], nil
+	default:
+		return "", fmt.Errorf("usage: %s", usage)
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/scaffold"
+)
+
+// deploymentFlags defines the flags both deployment generators take
+func deploymentFlags(fs *flag.FlagSet) {
+	fs.String("settings", SettingsPath(), "Settings file to read the app name, port and database from")
+	fs.Bool("force", false, "Replace existing files")
+}
+
+// generateDockerCommand is the generate:docker command
+var generateDockerCommand = &Command{
+	Description: "Create a Dockerfile, .dockerignore and docker-compose.yml from settings.toml",
+	Flags:       deploymentFlags,
+	Args:        NoArgs,
+	Run: func(c *CommandContext) error {
+		deployment, err := scaffold.DeploymentFromSettings(".", c.String("settings"))
+		if err != nil {
+			return err
+		}
+		paths, err := scaffold.Docker(".", deployment, c.Bool("force"))
+		if err != nil {
+			return err
+		}
+		printCreated(paths)
+		fmt.Println("\nRun it with: docker compose up --build")
+		fmt.Println("Migrate with: docker compose run --rm app ./server migrate")
+		return nil
+	},
+}
+
+// generateK8sCommand is the generate:k8s command
+var generateK8sCommand = &Command{
+	Description: "Create Kubernetes manifests in k8s/ from settings.toml",
+	Flags: func(fs *flag.FlagSet) {
+		deploymentFlags(fs)
+		fs.String("image", "", "Image to deploy (default: <app-name>:latest)")
+		fs.Int("replicas", 2, "Number of pods (SQLite projects always run one)")
+	},
+	Args: NoArgs,
+	Run: func(c *CommandContext) error {
+		deployment, err := scaffold.DeploymentFromSettings(".", c.String("settings"))
+		if err != nil {
+			return err
+		}
+		deployment.Image = c.String("image")
+		deployment.Replicas = c.Int("replicas")
+		paths, err := scaffold.Kubernetes(".", deployment, c.Bool("force"))
+		if err != nil {
+			return err
+		}
+		printCreated(paths)
+		fmt.Printf("\nCreate the secrets, then apply: kubectl create secret generic %s-secrets --from-literal=SECRET_KEY=...\n", deployment.Name)
+		fmt.Println("  kubectl apply -f k8s/")
+		return nil
+	},
+}
+
+func printCreated(paths []string) {
+	for _, path := range paths {
+		fmt.Printf("Created %s\n", path)
+	}
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"flag"
+	"strings"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/dev"
+)
+
+// handleDev handles the dev command: it runs the app under the dev server, which
+// rebuilds it when Go files change and reloads browsers when templates or static
+// files change. Arguments after the flags are passed to the app.
+func handleDev(args []string) error {
+	fs := flag.NewFlagSet("dev", flag.ContinueOnError)
+	reloadAddr := fs.String("livereload", "127.0.0.1:35729", `Browser live-reload address, or "-" to disable it`)
+	delay := fs.Duration("delay", 200*time.Millisecond, "Quiet time before acting on changes")
+	ignore := fs.String("ignore", "", "Comma-separated directories not to watch")
+	tags := fs.String("tags", "", "Build tags")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg := dev.Config{LiveReloadAddr: *reloadAddr, Delay: *delay, Args: fs.Args()}
+	if *ignore != "" {
+		cfg.Ignore = strings.Split(*ignore, ",")
+	}
+	if *tags != "" {
+		cfg.BuildFlags = []string{"-tags", *tags}
+	}
+	return dev.Run(cfg)
+}
@@ -4,6 +4,7 @@ package cmd
 // These examples are not executed - they're for documentation purposes.
 
 import (
+	"flag"
 	"fmt"
 
 	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
@@ -26,18 +27,42 @@ func exampleCustomInit() {
 // Example 2: Register custom commands
 // Add your own CLI commands
 func exampleCustomCommands() {
-	// Register a seed command
-	RegisterCommand("seed", func(args []string) error {
-		app := core.NewApplication("./settings.toml")
+	// Register a command that needs the database
+	RegisterCommand("users:count", func(args []string) error {
+		app := core.NewApplication(SettingsPath())
 		if err := app.ConnectDB(); err != nil {
 			return err
 		}
 
-		fmt.Println("Seeding database...")
-		// Your seeding logic here
+		var count int64
+		app.DB.Table("users").Count(&count)
+		fmt.Printf("%d users\n", count)
 		return nil
 	})
 
+	// Register a command with flags, argument checks and help;
+	// go run . help users:deactivate prints them
+	Register(&Command{
+		Name:        "users:deactivate",
+		Usage:       "<email>...",
+		Description: "Deactivate users",
+		Flags: func(fs *flag.FlagSet) {
+			fs.Bool("dry-run", false, "List the users without changing them")
+		},
+		Args: MinimumArgs(1),
+		Run: func(c *CommandContext) error {
+			app, err := c.App()
+			if err != nil {
+				return err
+			}
+			if c.Bool("dry-run") {
+				fmt.Printf("Would deactivate %v\n", c.Args)
+				return nil
+			}
+			return app.DB.Table("users").Where("email IN ?", c.Args).Update("is_active", false).Error
+		},
+	})
+
 	// Register a clear cache command
 	RegisterCommand("cache:clear", func(args []string) error {
 		fmt.Println("Clearing cache...")
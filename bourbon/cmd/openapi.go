@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+)
+
+// handleOpenAPIGenerate handles the openapi:generate command
+func handleOpenAPIGenerate(args []string) error {
+	fs := flag.NewFlagSet("openapi:generate", flag.ContinueOnError)
+	output := fs.String("output", "", "File to write (default: openapi.output or openapi.json)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	app, err := setupApp(SettingsPath())
+	if err != nil {
+		return err
+	}
+
+	path := *output
+	if path == "" {
+		path = app.Config.OpenAPI.Output
+	}
+	if path == "" {
+		path = "openapi.json"
+	}
+
+	doc := app.OpenAPI()
+	if err := doc.WriteFile(path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	fmt.Printf("Wrote %s with %d operations\n", path, len(doc.Operations()))
+	return nil
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+)
+
+// jsonOutput is for commands given --json. Until the returned function writes
+// the JSON document, everything the command prints, log lines included, goes
+// to stderr, so stdout holds the document alone.
+func jsonOutput() func(v any) error {
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	return func(v any) error {
+		os.Stdout = stdout
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+}
+
+// migrationsJSON runs a command that applies or rolls back migrations and
+// prints the IDs whose state changed under key, with the error when it failed
+// partway, as JSON
+func migrationsJSON(key string, run func(app *core.Application) error) error {
+	write := jsonOutput()
+
+	app := core.NewApplication(SettingsPath())
+	if err := app.ConnectDB(); err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	before, err := core.MigrationStatus(app)
+	if err != nil {
+		return err
+	}
+	runErr := run(app)
+	after, err := core.MigrationStatus(app)
+	if err != nil {
+		return err
+	}
+
+	applied := make(map[string]bool, len(before))
+	for _, m := range before {
+		applied[m.ID] = m.Applied
+	}
+	changed := []string{}
+	pending := 0
+	for _, m := range after {
+		if m.Applied != applied[m.ID] {
+			changed = append(changed, m.ID)
+		}
+		if !m.Applied {
+			pending++
+		}
+	}
+
+	result := map[string]any{key: changed, "pending": pending}
+	if runErr != nil {
+		result["error"] = runErr.Error()
+	}
+	if err := write(result); err != nil {
+		return err
+	}
+	return runErr
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/auth"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+)
+
+// newAuthorizer connects to the database and ensures the RBAC tables exist
+func newAuthorizer() (*auth.Authorizer, error) {
+	app := core.NewApplication(SettingsPath())
+
+	if err := app.ConnectDB(); err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	if err := auth.Migrate(orm.Primary(app.DB)); err != nil {
+		return nil, fmt.Errorf("failed to migrate authorization tables: %w", err)
+	}
+
+	return auth.NewAuthorizer(app.DB), nil
+}
+
+// handleRoleCreate handles the role:create command
+func handleRoleCreate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: role:create <name> [description]")
+	}
+
+	authorizer, err := newAuthorizer()
+	if err != nil {
+		return err
+	}
+
+	role, err := authorizer.CreateRole(args[0], strings.Join(args[1:], " "))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Role created: %s (ID: %d)\n", role.Name, role.ID)
+	return nil
+}
+
+// handlePermissionGrant handles the permission:grant command
+func handlePermissionGrant(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: permission:grant <role> <permission>")
+	}
+
+	authorizer, err := newAuthorizer()
+	if err != nil {
+		return err
+	}
+
+	if err := authorizer.Grant(args[0], args[1]); err != nil {
+		return err
+	}
+
+	fmt.Printf("Granted %s to role %s\n", args[1], args[0])
+	return nil
+}
+
+// handlePermissionRevoke handles the permission:revoke command
+func handlePermissionRevoke(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: permission:revoke <role> <permission>")
+	}
+
+	authorizer, err := newAuthorizer()
+	if err != nil {
+		return err
+	}
+
+	if err := authorizer.Revoke(args[0], args[1]); err != nil {
+		return err
+	}
+
+	fmt.Printf("Revoked %s from role %s\n", args[1], args[0])
+	return nil
+}
+
+// handleRoleAssign handles the role:assign command
+func handleRoleAssign(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: role:assign <user-id> <role>")
+	}
+
+	userID, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid user id: %s", args[0])
+	}
+
+	authorizer, err := newAuthorizer()
+	if err != nil {
+		return err
+	}
+
+	if err := authorizer.AssignRole(uint(userID), args[1]); err != nil {
+		return err
+	}
+
+	fmt.Printf("Assigned role %s to user %d\n", args[1], userID)
+	return nil
+}
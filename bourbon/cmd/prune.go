@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+)
+
+// handleDBPrune handles the db:prune command
+func handleDBPrune(args []string) error {
+	fs := flag.NewFlagSet("db:prune", flag.ContinueOnError)
+	days := fs.Int("days", 30, "Remove rows soft-deleted more than this many days ago")
+	tables := fs.String("table", "", "Comma-separated tables to prune (default: every table with deleted_at)")
+	dryRun := fs.Bool("dry-run", false, "Show how many rows would be removed without deleting them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *days < 0 {
+		return fmt.Errorf("--days must not be negative")
+	}
+
+	app := core.NewApplication(SettingsPath())
+	if err := app.ConnectDB(); err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	db := orm.Primary(app.DB)
+
+	var names []string
+	if *tables != "" {
+		for _, name := range strings.Split(*tables, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	} else {
+		found, err := orm.SoftDeleteTables(db)
+		if err != nil {
+			return fmt.Errorf("failed to list tables: %w", err)
+		}
+		names = found
+	}
+	if len(names) == 0 {
+		fmt.Println("No soft-delete tables found")
+		return nil
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -*days)
+	fmt.Printf("Pruning rows soft-deleted before %s\n", cutoff.Format(time.RFC3339))
+
+	var total int64
+	for _, table := range names {
+		var (
+			count int64
+			err   error
+		)
+		if *dryRun {
+			count, err = orm.CountPrunable(db, table, cutoff)
+		} else {
+			count, err = orm.PruneTable(db, table, cutoff)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to prune %s: %w", table, err)
+		}
+		total += count
+		fmt.Printf("  %-30s %d\n", table, count)
+	}
+
+	if *dryRun {
+		fmt.Printf("%d rows would be removed (dry run)\n", total)
+	} else {
+		fmt.Printf("Removed %d rows\n", total)
+	}
+	return nil
+}
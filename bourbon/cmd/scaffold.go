@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/migrationgen"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/gomod"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/scaffold"
+)
+
+// handleMakeController handles the make:controller command
+func handleMakeController(args []string) error {
+	fs := flag.NewFlagSet("make:controller", flag.ContinueOnError)
+	appName := fs.String("app", "", "App to create the controller in (default: the first app)")
+	tidyProject := tidyFlag(fs)
+	resource := fs.Bool("resource", false, "Add Show, Store, Update and Destroy handlers")
+	test := fs.Bool("test", false, "Also create a test requesting its routes")
+
+	name, err := parseScaffoldArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("usage: make:controller <name> [--app=name] [--resource] [--test] [--tidy]")
+	}
+	if err := defaultScaffoldApp(appName); err != nil {
+		return err
+	}
+	if err := scaffold.Controller(*appName, name, *resource); err != nil {
+		return err
+	}
+	if *test {
+		if err := scaffold.Test(*appName, name); err != nil {
+			return err
+		}
+	}
+	return tidy(*tidyProject)
+}
+
+// handleMakeTest handles the make:test command
+func handleMakeTest(args []string) error {
+	fs := flag.NewFlagSet("make:test", flag.ContinueOnError)
+	appName := fs.String("app", "", "App of the controller (default: the first app)")
+	tidyProject := tidyFlag(fs)
+
+	name, err := parseScaffoldArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("usage: make:test <controller> [--app=name] [--tidy]")
+	}
+	if err := defaultScaffoldApp(appName); err != nil {
+		return err
+	}
+	if err := scaffold.Test(*appName, name); err != nil {
+		return err
+	}
+	return tidy(*tidyProject)
+}
+
+// handleMakeModel handles the make:model command
+func handleMakeModel(args []string) error {
+	fs := flag.NewFlagSet("make:model", flag.ContinueOnError)
+	appName := fs.String("app", "", "App to create the model in (default: the first app)")
+	tidyProject := tidyFlag(fs)
+	fieldsFlag := fs.String("fields", "", "Comma-separated fields, e.g. title:string:required,body:text")
+
+	name, err := parseScaffoldArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("usage: make:model <name> [field:type[:modifier...]...] [--app=name] [--fields=a:string,b:int] [--tidy]")
+	}
+	if err := defaultScaffoldApp(appName); err != nil {
+		return err
+	}
+
+	var fields []string
+	if *fieldsFlag != "" {
+		fields = strings.Split(*fieldsFlag, ",")
+	}
+	fields = append(fields, fs.Args()...)
+	if err := scaffold.Model(*appName, name, fields); err != nil {
+		return err
+	}
+	return tidy(*tidyProject)
+}
+
+// handleMakeMiddleware handles the make:middleware command
+func handleMakeMiddleware(args []string) error {
+	fs := flag.NewFlagSet("make:middleware", flag.ContinueOnError)
+	appName := fs.String("app", "", "App to create the middleware in (default: the first app)")
+	tidyProject := tidyFlag(fs)
+
+	name, err := parseScaffoldArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("usage: make:middleware <name> [--app=name] [--tidy]")
+	}
+	if err := defaultScaffoldApp(appName); err != nil {
+		return err
+	}
+	if err := scaffold.Middleware(*appName, name); err != nil {
+		return err
+	}
+	return tidy(*tidyProject)
+}
+
+// handleMakeCommand handles the make:command command
+func handleMakeCommand(args []string) error {
+	fs := flag.NewFlagSet("make:command", flag.ContinueOnError)
+	appName := fs.String("app", "", "App to create the command in (default: the first app)")
+	tidyProject := tidyFlag(fs)
+
+	name, err := parseScaffoldArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("usage: make:command <name> [--app=name] [--tidy]")
+	}
+	if err := defaultScaffoldApp(appName); err != nil {
+		return err
+	}
+	if err := scaffold.Command(*appName, name); err != nil {
+		return err
+	}
+	return tidy(*tidyProject)
+}
+
+// handleMakeJob handles the make:job command
+func handleMakeJob(args []string) error {
+	fs := flag.NewFlagSet("make:job", flag.ContinueOnError)
+	appName := fs.String("app", "", "App to create the job in (default: the first app)")
+	tidyProject := tidyFlag(fs)
+
+	name, err := parseScaffoldArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("usage: make:job <name> [--app=name] [--tidy]")
+	}
+	if err := defaultScaffoldApp(appName); err != nil {
+		return err
+	}
+	if err := scaffold.Job(*appName, name); err != nil {
+		return err
+	}
+	return tidy(*tidyProject)
+}
+
+// parseScaffoldArgs parses a generator's flags and returns the name before
+// them. Flags may also follow further positional arguments, which are left in
+// fs.Args().
+func parseScaffoldArgs(fs *flag.FlagSet, args []string) (string, error) {
+	name, rest := splitPositional(args)
+	var positional []string
+	for {
+		if err := fs.Parse(rest); err != nil {
+			return "", err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		rest = fs.Args()[1:]
+	}
+	if err := fs.Parse(positional); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// defaultScaffoldApp sets appName to the first app when it is empty
+func defaultScaffoldApp(appName *string) error {
+	if *appName != "" {
+		return nil
+	}
+	defaultApp, err := migrationgen.DefaultApp()
+	if err != nil {
+		return err
+	}
+	*appName = defaultApp
+	return nil
+}
+
+// tidyFlag defines a generator's --tidy flag
+func tidyFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("tidy", false, "Run go mod tidy afterwards, and go mod vendor in a vendored project")
+}
+
+// tidy runs go mod tidy when a generator's --tidy flag is set, so go.mod gets
+// the modules the generated files import
+func tidy(enabled bool) error {
+	if !enabled {
+		return nil
+	}
+	mod, err := gomod.Read(".")
+	if err != nil {
+		return err
+	}
+	return mod.Tidy()
+}
+
+// createAppCommand is the create:app command
+var createAppCommand = &Command{
+	Usage:       "<name>",
+	Description: "Create an app in apps/ and install it",
+	Long:        "Creates apps/<name> with models, controllers, routes and migrations, adds it to [apps] installed in settings.toml and registers it in main.go.",
+	Flags: func(fs *flag.FlagSet) {
+		tidyFlag(fs)
+	},
+	Args: ExactArgs(1),
+	Run: func(c *CommandContext) error {
+		if err := scaffold.CreateApp(c.Arg(0)); err != nil {
+			return err
+		}
+		return tidy(c.Bool("tidy"))
+	},
+}
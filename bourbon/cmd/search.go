@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/search"
+)
+
+// handleSearchReindex handles the search:reindex command
+func handleSearchReindex(args []string) error {
+	fs := flag.NewFlagSet("search:reindex", flag.ContinueOnError)
+	only := fs.String("index", "", "Comma-separated indexes to rebuild (default: all registered)")
+	batch := fs.Int("batch", 500, "Rows loaded and indexed per batch")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	app := core.NewApplication(SettingsPath())
+	if err := app.ConnectDB(); err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	if app.Search == nil {
+		return fmt.Errorf("search is not available for %s; set driver under [search]", app.DB.Dialector.Name())
+	}
+
+	indexes := search.Indexes()
+	if *only != "" {
+		indexes = nil
+		for _, name := range strings.Split(*only, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				indexes = append(indexes, name)
+			}
+		}
+	}
+	if len(indexes) == 0 {
+		fmt.Println("No searchable models registered")
+		return nil
+	}
+
+	ctx := context.Background()
+	for _, index := range indexes {
+		count, err := search.Reindex(ctx, orm.Primary(app.DB), app.Search, index, *batch)
+		if err != nil {
+			return fmt.Errorf("failed to reindex %s: %w", index, err)
+		}
+		fmt.Printf("  %-30s %d documents\n", index, count)
+	}
+	fmt.Println("Reindex complete")
+	return nil
+}
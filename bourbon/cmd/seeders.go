@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/migrationgen"
+)
+
+// handleSeed handles the seed and seed:run commands
+func handleSeed(args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	only := fs.String("only", "", "Comma-separated seeders to run (their dependencies run too)")
+	list := fs.Bool("list", false, "List registered seeders in run order without running them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *list {
+		seeders, err := core.GetSeeders()
+		if err != nil {
+			return err
+		}
+		if len(seeders) == 0 {
+			fmt.Println("No seeders registered")
+			return nil
+		}
+		for _, s := range seeders {
+			if len(s.DependsOn) > 0 {
+				fmt.Printf("  %s (after %s)\n", s.Name, strings.Join(s.DependsOn, ", "))
+			} else {
+				fmt.Printf("  %s\n", s.Name)
+			}
+		}
+		return nil
+	}
+
+	var names []string
+	if *only != "" {
+		for _, name := range strings.Split(*only, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	app := core.NewApplication(SettingsPath())
+	if err := app.ConnectDB(); err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	return seedDatabase(app, names)
+}
+
+// seedDatabase runs the named seeders, or all of them when names is empty
+func seedDatabase(app *core.Application, names []string) error {
+	fmt.Println("Seeding database...")
+	err := core.RunSeeders(app.DB, names, func(name string) {
+		fmt.Printf("  Running seeder: %s\n", name)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Seeding completed successfully")
+	return nil
+}
+
+// handleMakeSeeder handles the make:seeder command
+func handleMakeSeeder(args []string) error {
+	var appName, name string
+	switch len(args) {
+	case 1:
+		defaultApp, err := migrationgen.DefaultApp()
+		if err != nil {
+			return err
+		}
+		appName, name = defaultApp, args[0]
+	case 2:
+		appName, name = args[0], args[1]
+	default:
+		return fmt.Errorf("usage: make:seeder [app] <name>")
+	}
+
+	return GenerateSeeder(appName, name)
+}
+
+// GenerateSeeder creates apps/<app>/seeders/<name>.go registering a seeder
+func GenerateSeeder(appName, name string) error {
+	cleanName := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", "_"))
+	if cleanName == "" {
+		return fmt.Errorf("seeder name is required")
+	}
+
+	seedersDir := filepath.Join("apps", appName, "seeders")
+	if err := os.MkdirAll(seedersDir, 0755); err != nil {
+		return fmt.Errorf("failed to create seeders directory: %w", err)
+	}
+
+	filePath := filepath.Join(seedersDir, cleanName+".go")
+	if _, err := os.Stat(filePath); err == nil {
+		return fmt.Errorf("seeder already exists: %s", filePath)
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create seeder file: %w", err)
+	}
+	defer file.Close()
+
+	data := map[string]string{
+		"Name":     cleanName,
+		"FuncName": "seed" + toPascalCase(cleanName),
+	}
+	if err := seederTemplate.Execute(file, data); err != nil {
+		return fmt.Errorf("failed to write seeder: %w", err)
+	}
+
+	fmt.Printf("Created seeder: %s\n", filePath)
+	if !seedersImported(appName) {
+		fmt.Printf("Import it in main.go so it registers:\n\n  _ \"<module>/apps/%s/seeders\"\n\n", appName)
+	}
+	return nil
+}
+
+// seedersImported reports whether main.go already imports the app's seeders package
+func seedersImported(appName string) bool {
+	data, err := os.ReadFile("main.go")
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(data), "/apps/"+appName+"/seeders\"")
+}
+
+// toPascalCase converts snake_case to PascalCase
+func toPascalCase(s string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(s, "_") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+var seederTemplate = template.Must(template.New("seeder").Parse(`package seeders
+
+import (
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	"gorm.io/gorm"
+)
+
+func init() {
+	// Add names of seeders that must run first, e.g. core.RegisterSeeder("{{.Name}}", {{.FuncName}}, "users")
+	core.RegisterSeeder("{{.Name}}", {{.FuncName}})
+}
+
+// {{.FuncName}} runs inside a transaction; returning an error rolls it back.
+// Prefer FirstOrCreate so the seeder can be run more than once.
+func {{.FuncName}}(db *gorm.DB) error {
+	return nil
+}
+`))
@@ -0,0 +1,420 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+	"gorm.io/gorm"
+)
+
+// ShellCommand is a project command available in the shell. db is the shell's
+// connection, a transaction under --sandbox.
+type ShellCommand func(app *core.Application, db *gorm.DB, args []string) error
+
+type shellCommand struct {
+	help string
+	run  ShellCommand
+}
+
+var shellCommands = map[string]shellCommand{}
+
+// RegisterShellCommand adds a command to the shell, e.g. a helper that loads
+// typed models:
+//
+//	cmd.RegisterShellCommand("publish", "publish <post-id>", func(app *core.Application, db *gorm.DB, args []string) error {
+//	    return db.Model(&blog.Post{}).Where("id = ?", args[0]).Update("published", true).Error
+//	})
+func RegisterShellCommand(name, help string, fn ShellCommand) {
+	shellCommands[name] = shellCommand{help: help, run: fn}
+}
+
+// queryKeywords start SQL statements that return rows; other SQL is executed
+var queryKeywords = []string{"select", "with", "pragma", "explain", "show", "values"}
+
+// sqlKeywords start statements the shell passes to the database as they are
+var sqlKeywords = append([]string{"insert", "update", "delete", "create", "drop", "alter", "truncate", "replace"}, queryKeywords...)
+
+// shellRowLimit is how many rows Model.all shows by default
+const shellRowLimit = 20
+
+// shell is an interactive session over the app's database
+type shell struct {
+	app    *core.Application
+	db     *gorm.DB
+	out    io.Writer
+	tables []string
+}
+
+// handleShell handles the shell command: a console over the app and its
+// database for poking at data and trying queries
+func handleShell(args []string) error {
+	fs := flag.NewFlagSet("shell", flag.ContinueOnError)
+	command := fs.String("c", "", "Run one command and exit")
+	sandbox := fs.Bool("sandbox", false, "Run in a transaction that is rolled back on exit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	app, err := setupApp(SettingsPath())
+	if err != nil {
+		return err
+	}
+	s := &shell{app: app, db: orm.Primary(app.DB), out: os.Stdout}
+	if *sandbox {
+		s.db = s.db.Begin()
+		if s.db.Error != nil {
+			return fmt.Errorf("failed to start the sandbox transaction: %w", s.db.Error)
+		}
+		defer s.db.Rollback()
+	}
+
+	if *command != "" {
+		return s.run(*command)
+	}
+
+	interactive := isTerminal(os.Stdin)
+	if interactive {
+		fmt.Printf("Bourbon shell for %s (%s, %s). Type help for commands, exit to leave.\n", app.Config.App.Name, app.Config.App.Env, app.DB.Dialector.Name())
+		if *sandbox {
+			fmt.Println("Sandbox: every change is rolled back on exit.")
+		}
+	}
+	prompt := app.Config.App.Name + "> "
+	if *sandbox {
+		prompt = app.Config.App.Name + " (sandbox)> "
+	}
+
+	// Scripted sessions stop at the first error; interactive ones report it
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		if interactive {
+			fmt.Print(prompt)
+		}
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		eof := err != nil
+
+		line = strings.TrimSuffix(strings.TrimSpace(line), ";")
+		switch line {
+		case "":
+		case "exit", "quit", `\q`:
+			return nil
+		default:
+			if err := s.run(line); err != nil {
+				if !interactive {
+					return err
+				}
+				fmt.Printf("Error: %v\n", err)
+			}
+		}
+		if eof {
+			if interactive {
+				fmt.Println()
+			}
+			return nil
+		}
+	}
+}
+
+// isTerminal reports whether f is a terminal rather than a pipe or file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// run runs one line: a shell command, a Model.method call or SQL
+func (s *shell) run(line string) error {
+	word, rest, _ := strings.Cut(line, " ")
+	rest = strings.TrimSpace(rest)
+
+	if slices.Contains(sqlKeywords, strings.ToLower(word)) {
+		return s.sql(line)
+	}
+	switch word {
+	case "help":
+		s.help()
+		return nil
+	case "tables":
+		return s.listTables()
+	case "routes":
+		return s.routes()
+	case "app":
+		s.info()
+		return nil
+	case "sql":
+		return s.sql(rest)
+	}
+	if command, ok := shellCommands[word]; ok {
+		return command.run(s.app, s.db, strings.Fields(rest))
+	}
+	if model, method, ok := strings.Cut(word, "."); ok {
+		return s.model(model, method, rest)
+	}
+	return fmt.Errorf("unknown command %q (type help for commands)", word)
+}
+
+func (s *shell) help() {
+	w := tabwriter.NewWriter(s.out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "SELECT ... / INSERT ... / ...\tRun SQL (also: sql <query>)")
+	fmt.Fprintln(w, "tables\tList tables")
+	fmt.Fprintln(w, "Model.columns\tDescribe a model's table, e.g. Post.columns")
+	fmt.Fprintln(w, "Model.count\tCount rows")
+	fmt.Fprintf(w, "Model.all [n]\tList rows, %d by default\n", shellRowLimit)
+	fmt.Fprintln(w, "Model.first, Model.last\tRow with the lowest or highest primary key")
+	fmt.Fprintln(w, "Model.find <id>\tRow by primary key")
+	fmt.Fprintln(w, "Model.where <condition>\tRows matching a SQL condition, e.g. Post.where published = true")
+	fmt.Fprintln(w, "routes\tList routes")
+	fmt.Fprintln(w, "app\tShow the app's name, environment and database")
+	names := make([]string, 0, len(shellCommands))
+	for name := range shellCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\t%s\n", name, shellCommands[name].help)
+	}
+	fmt.Fprintln(w, "exit\tLeave the shell")
+	w.Flush()
+	fmt.Fprintln(s.out, "\nModel is a model or table name: Post, post and posts all name the posts table.")
+}
+
+func (s *shell) info() {
+	cfg := s.app.Config
+	fmt.Fprintf(s.out, "App:      %s (%s, debug %t)\n", cfg.App.Name, cfg.App.Env, cfg.App.Debug)
+	fmt.Fprintf(s.out, "Database: %s %s\n", s.db.Dialector.Name(), firstNonEmpty(cfg.Database.Path, cfg.Database.Name))
+	fmt.Fprintf(s.out, "Routes:   %d\n", len(s.app.Router.GetRoutes()))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func (s *shell) routes() error {
+	w := tabwriter.NewWriter(s.out, 0, 0, 2, ' ', 0)
+	for _, route := range s.app.Router.GetRoutes() {
+		fmt.Fprintf(w, "%s\t%s\n", route.Method, route.Pattern)
+	}
+	return w.Flush()
+}
+
+func (s *shell) listTables() error {
+	tables, err := s.db.Migrator().GetTables()
+	if err != nil {
+		return err
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		fmt.Fprintln(s.out, table)
+	}
+	return nil
+}
+
+// sql runs a statement, printing the rows of a query or the rows affected
+func (s *shell) sql(query string) error {
+	if query == "" {
+		return fmt.Errorf("usage: sql <query>")
+	}
+	word, _, _ := strings.Cut(query, " ")
+	if slices.Contains(queryKeywords, strings.ToLower(word)) {
+		return s.printRows(s.db.Raw(query))
+	}
+	result := s.db.Exec(query)
+	if result.Error != nil {
+		return result.Error
+	}
+	// The table list may have changed
+	s.tables = nil
+	fmt.Fprintf(s.out, "%d row(s) affected\n", result.RowsAffected)
+	return nil
+}
+
+// model runs a Model.method call against the model's table
+func (s *shell) model(name, method, args string) error {
+	table, err := s.table(name)
+	if err != nil {
+		return err
+	}
+	query := s.db.Table(table)
+
+	switch method {
+	case "columns":
+		return s.columns(table)
+	case "count":
+		var count int64
+		if err := query.Count(&count).Error; err != nil {
+			return err
+		}
+		fmt.Fprintln(s.out, count)
+		return nil
+	case "all":
+		limit := shellRowLimit
+		if args != "" {
+			if limit, err = strconv.Atoi(args); err != nil || limit <= 0 {
+				return fmt.Errorf("usage: %s.all [n]", name)
+			}
+		}
+		return s.printRows(s.ordered(query, table, "").Limit(limit))
+	case "first":
+		return s.printRows(s.ordered(query, table, "").Limit(1))
+	case "last":
+		return s.printRows(s.ordered(query, table, " DESC").Limit(1))
+	case "find":
+		pk := s.primaryKey(table)
+		if args == "" || pk == "" {
+			return fmt.Errorf("usage: %s.find <id> (the table needs a primary key)", name)
+		}
+		return s.printRows(query.Where(s.db.Statement.Quote(pk)+" = ?", args))
+	case "where":
+		if args == "" {
+			return fmt.Errorf("usage: %s.where <condition>", name)
+		}
+		return s.printRows(s.ordered(query.Where(args), table, ""))
+	}
+	return fmt.Errorf("unknown method %s.%s (type help for commands)", name, method)
+}
+
+// ordered orders a query by the table's primary key when it has one
+func (s *shell) ordered(query *gorm.DB, table, direction string) *gorm.DB {
+	if pk := s.primaryKey(table); pk != "" {
+		return query.Order(s.db.Statement.Quote(pk) + direction)
+	}
+	return query
+}
+
+// table resolves a model or table name to a table of the database
+func (s *shell) table(name string) (string, error) {
+	if s.tables == nil {
+		tables, err := s.db.Migrator().GetTables()
+		if err != nil {
+			return "", err
+		}
+		s.tables = tables
+	}
+	for _, candidate := range []string{name, strings.ToLower(name), s.db.NamingStrategy.TableName(name)} {
+		if slices.Contains(s.tables, candidate) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no table for %q (tables lists them)", name)
+}
+
+func (s *shell) primaryKey(table string) string {
+	columns, err := s.db.Migrator().ColumnTypes(table)
+	if err != nil {
+		return ""
+	}
+	for _, column := range columns {
+		if pk, ok := column.PrimaryKey(); ok && pk {
+			return column.Name()
+		}
+	}
+	return ""
+}
+
+func (s *shell) columns(table string) error {
+	columns, err := s.db.Migrator().ColumnTypes(table)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(s.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COLUMN\tTYPE\tNULL\tKEY")
+	for _, column := range columns {
+		nullable, _ := column.Nullable()
+		key := ""
+		if pk, _ := column.PrimaryKey(); pk {
+			key = "PRI"
+		} else if unique, _ := column.Unique(); unique {
+			key = "UNI"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", column.Name(), strings.ToLower(column.DatabaseTypeName()), yesNo(nullable), key)
+	}
+	return w.Flush()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// printRows prints the rows of a query as a table
+func (s *shell) printRows(query *gorm.DB) error {
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(s.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(columns, "\t"))
+
+	values := make([]any, len(columns))
+	pointers := make([]any, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	count := 0
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+		cells := make([]string, len(values))
+		for i, value := range values {
+			cells[i] = formatCell(value)
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "(%d row(s))\n", count)
+	return nil
+}
+
+// formatCell renders a value on one line, shortening long text
+func formatCell(value any) string {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		text = string(v)
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05")
+	default:
+		text = fmt.Sprint(v)
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if len([]rune(text)) > 60 {
+		text = string([]rune(text)[:57]) + "..."
+	}
+	return text
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	bourbonHttp "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+)
+
+// handleCollectStatic handles the collectstatic command.
+// It copies static files into the collect directory with content-hashed names
+// and writes a manifest used by the static template function in production.
+func handleCollectStatic(args []string) error {
+	config, err := core.LoadConfig(SettingsPath())
+	if err != nil {
+		return err
+	}
+
+	source := config.Static.Directory
+	dest := config.Static.CollectDir
+	if source == "" || dest == "" {
+		return fmt.Errorf("static.directory and static.collect_dir must be set")
+	}
+
+	// collect_dir is deleted first, so it must not hold the sources or the project
+	if err := checkCollectDir(source, dest); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(dest); err != nil {
+		return fmt.Errorf("failed to clean %s: %w", dest, err)
+	}
+
+	manifest := make(map[string]string)
+
+	err = filepath.WalkDir(source, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		hashed := fingerprintName(name, content)
+
+		// Keep the original name as well so hand-written references still resolve
+		for _, target := range []string{name, hashed} {
+			out := filepath.Join(dest, filepath.FromSlash(target))
+			if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(out, content, 0644); err != nil {
+				return err
+			}
+		}
+
+		manifest[name] = hashed
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to collect static files: %w", err)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dest, bourbonHttp.ManifestFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	fmt.Printf("Collected %d static file(s) into %s\n", len(manifest), dest)
+	if config.Static.AssetHost != "" {
+		fmt.Printf("Upload %s to %s%s\n", dest, config.Static.AssetHost, config.Static.URLPrefix)
+	}
+	return nil
+}
+
+// fingerprintName inserts a short content hash before the file extension
+func fingerprintName(name string, content []byte) string {
+	sum := md5.Sum(content)
+	hash := hex.EncodeToString(sum[:])[:12]
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+// checkCollectDir refuses a collect_dir that is, contains or sits inside the
+// static directory, or that contains the working directory
+func checkCollectDir(source, dest string) error {
+	absSource, err := resolvePath(source)
+	if err != nil {
+		return err
+	}
+	absDest, err := resolvePath(dest)
+	if err != nil {
+		return err
+	}
+	if pathWithin(absDest, absSource) || pathWithin(absSource, absDest) {
+		return fmt.Errorf("static.collect_dir %q overlaps static.directory %q; refusing to delete it", dest, source)
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if wd, err = resolvePath(wd); err == nil && pathWithin(wd, absDest) {
+			return fmt.Errorf("static.collect_dir %q contains the project directory; refusing to delete it", dest)
+		}
+	}
+	return nil
+}
+
+// resolvePath returns path as an absolute path with symlinks resolved, as far
+// as it exists
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	// Resolve the longest existing prefix; the rest doesn't exist yet
+	existing, rest := abs, ""
+	for {
+		if resolved, err := filepath.EvalSymlinks(existing); err == nil {
+			return filepath.Join(resolved, rest), nil
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return abs, nil
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+}
+
+// pathWithin reports whether path is dir or inside it; both must be clean absolute paths
+func pathWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
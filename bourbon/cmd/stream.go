@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/stream"
+)
+
+// streamConsumeCommand is the stream:consume command
+var streamConsumeCommand = &Command{
+	Description: "Run the consumers registered with stream.Consume, without the HTTP server",
+	Long: `Boots the app as the server does, with the same settings and logging, then
+reads the topics from the broker configured under [stream] until interrupted.
+Messages being handled are acknowledged before it exits. Run several processes
+with the same group to share a topic's messages between them.`,
+	Flags: func(fs *flag.FlagSet) {
+		fs.String("topic", "", "Comma-separated topics to consume (default: all with consumers)")
+		fs.String("group", "", "Consumer group (default: stream.group, or app.name)")
+	},
+	Args: NoArgs,
+	Run: func(c *CommandContext) error {
+		app, err := c.App()
+		if err != nil {
+			return err
+		}
+		opts := stream.RunOptions{Group: c.String("group")}
+		for _, topic := range strings.Split(c.String("topic"), ",") {
+			if topic = strings.TrimSpace(topic); topic != "" {
+				opts.Topics = append(opts.Topics, topic)
+			}
+		}
+		return app.RunConsumers(opts)
+	},
+}
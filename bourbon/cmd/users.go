@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/auth"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+)
+
+// SuperuserPasswordEnv supplies the password to user:create --no-input
+const SuperuserPasswordEnv = "BOURBON_SUPERUSER_PASSWORD"
+
+// newUserStore connects to the database and ensures the user and RBAC tables exist
+func newUserStore() (*auth.UserStore, error) {
+	app := core.NewApplication(SettingsPath())
+
+	if err := app.ConnectDB(); err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	store := auth.NewUserStore(orm.Primary(app.DB))
+	if err := store.Migrate(); err != nil {
+		return nil, fmt.Errorf("failed to migrate auth_users table: %w", err)
+	}
+	return store, nil
+}
+
+// handleCreateSuperuser handles the createsuperuser command
+func handleCreateSuperuser(args []string) error {
+	return handleUserCreate(append([]string{"--admin"}, args...))
+}
+
+// handleUserCreate handles the user:create command
+func handleUserCreate(args []string) error {
+	fs := flag.NewFlagSet("user:create", flag.ContinueOnError)
+	email := fs.String("email", "", "Email address to log in with")
+	admin := fs.Bool("admin", false, "Grant the superuser role, which has every permission")
+	noInput := fs.Bool("no-input", false, "Do not prompt; read the password from "+SuperuserPasswordEnv)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	if *email == "" {
+		if *noInput {
+			return fmt.Errorf("usage: user:create --email=<email> [--admin] [--no-input]")
+		}
+		value, err := prompt(reader, "Email: ")
+		if err != nil {
+			return err
+		}
+		*email = value
+	}
+
+	var password string
+	if *noInput {
+		password = os.Getenv(SuperuserPasswordEnv)
+		if password == "" {
+			return fmt.Errorf("%s must be set with --no-input", SuperuserPasswordEnv)
+		}
+	} else {
+		var err error
+		if password, err = promptPassword(reader); err != nil {
+			return err
+		}
+	}
+
+	store, err := newUserStore()
+	if err != nil {
+		return err
+	}
+
+	user, err := store.Create(*email, password, auth.UserOptions{Superuser: *admin})
+	if err != nil {
+		return err
+	}
+
+	if *admin {
+		fmt.Printf("Superuser created: %s (ID: %d)\n", user.Email, user.ID)
+	} else {
+		fmt.Printf("User created: %s (ID: %d)\n", user.Email, user.ID)
+	}
+	return nil
+}
+
+// promptPassword asks for a password twice until both entries match
+func promptPassword(reader *bufio.Reader) (string, error) {
+	for {
+		password, err := promptHidden(reader, "Password: ")
+		if err != nil {
+			return "", err
+		}
+		if len(password) < 8 {
+			fmt.Println("Password must be at least 8 characters.")
+			continue
+		}
+		confirm, err := promptHidden(reader, "Password (again): ")
+		if err != nil {
+			return "", err
+		}
+		if password != confirm {
+			fmt.Println("Passwords do not match.")
+			continue
+		}
+		return password, nil
+	}
+}
+
+func prompt(reader *bufio.Reader, label string) (string, error) {
+	fmt.Print(label)
+	line, err := reader.ReadString('\n')
+	if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// promptHidden turns terminal echo off while reading, where stty is available
+func promptHidden(reader *bufio.Reader, label string) (string, error) {
+	if setEcho(false) == nil {
+		defer func() {
+			setEcho(true)
+			fmt.Println()
+		}()
+	}
+	return prompt(reader, label)
+}
+
+func setEcho(on bool) error {
+	mode := "-echo"
+	if on {
+		mode = "echo"
+	}
+	stty := exec.Command("stty", mode)
+	stty.Stdin = os.Stdin
+	return stty.Run()
+}
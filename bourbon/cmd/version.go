@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+)
+
+// handleVersion handles the version command, printing what bourbon build
+// stamped into the binary
+func handleVersion(args []string) error {
+	fmt.Print(core.Version)
+	if core.Commit != "" {
+		fmt.Printf(" (commit %s)", core.Commit)
+	}
+	if core.BuildTime != "" {
+		fmt.Printf(" built %s", core.BuildTime)
+	}
+	fmt.Println()
+	return nil
+}
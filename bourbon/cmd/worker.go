@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/jobs"
+)
+
+// workerCommand is the worker command
+var workerCommand = &Command{
+	Description: "Run the jobs pushed to app.Queue, without the HTTP server",
+	Long: `Boots the app as the server does, with the same settings, database and
+logging, then runs jobs until interrupted. Running jobs finish before it exits.
+Deploy the same binary as web servers and as workers.`,
+	Flags: func(fs *flag.FlagSet) {
+		fs.String("queue", "", "Comma-separated queues to run (default: jobs.queues)")
+		fs.Int("concurrency", 0, "Jobs to run at once (default: jobs.concurrency)")
+	},
+	Args: NoArgs,
+	Run: func(c *CommandContext) error {
+		app, err := c.App()
+		if err != nil {
+			return err
+		}
+		opts := jobs.WorkOptions{Concurrency: c.Int("concurrency")}
+		for _, queue := range strings.Split(c.String("queue"), ",") {
+			if queue = strings.TrimSpace(queue); queue != "" {
+				opts.Queues = append(opts.Queues, queue)
+			}
+		}
+		return app.RunWorker(opts)
+	},
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/admin"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/auth"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+)
+
+// MountAdmin serves the admin pages for the models registered with admin.Register.
+// Empty fields of cfg are filled from [admin], app.secret_key, security.session_timeout
+// and the app's template engine. Without cfg.Authenticate, the login page checks
+// the auth_users accounts created by the createsuperuser command.
+func (a *App) MountAdmin(cfg admin.Config, middleware ...bourbon.MiddlewareFunc) (*admin.Site, error) {
+	if a.DB == nil {
+		return nil, fmt.Errorf("admin requires a database connection")
+	}
+	if a.Config != nil {
+		if cfg.Prefix == "" {
+			cfg.Prefix = a.Config.Admin.Prefix
+		}
+		if cfg.Title == "" {
+			cfg.Title = a.Config.Admin.Title
+		}
+		if cfg.SecretKey == "" {
+			cfg.SecretKey = a.Config.App.SecretKey
+		}
+		if cfg.SessionTTL == 0 && a.Config.Security.SessionTimeout > 0 {
+			cfg.SessionTTL = time.Duration(a.Config.Security.SessionTimeout) * time.Second
+		}
+	}
+	if cfg.Authenticate == nil {
+		cfg.Authenticate = auth.NewUserStore(orm.Primary(a.DB)).Authenticate
+	}
+	if cfg.Templates == nil {
+		cfg.Templates = a.Router.TemplateEngine
+	}
+	return admin.Mount(a.Router, a.DB, cfg, middleware...)
+}
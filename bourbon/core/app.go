@@ -3,18 +3,33 @@ package core
 import (
 	"context"
 	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/ishubhamsingh2e/bourbon/bourbon/cache"
 	"github.com/ishubhamsingh2e/bourbon/bourbon/core/gormigrate"
 	"github.com/ishubhamsingh2e/bourbon/bourbon/core/registry"
 	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/dev"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/events"
 	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/jobs"
 	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/mail"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/middleware"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/notification"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/search"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/stream"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -26,14 +41,34 @@ type App struct {
 	Server             *http.Server                 // HTTP server
 	Logger             *logging.Logger              // Structured logger
 	ErrorStore         *logging.ErrorStore          // Error store for logging server errors to database
-	Registry           *registry.Registry           // Global registry for app components
+	Recorder           *middleware.RequestRecorder  // Recent requests in debug mode, see [logging.recorder]
+	Registry           *registry.Registry           // Named components and the typed service container
 	DB                 *gorm.DB                     // Database connection
+	DBHealth           *orm.HealthMonitor           // Database ping and pool stats, set by ConnectDB
+	Jobs               *jobs.LocalQueue             // Background jobs: async observers and mail
+	Queue              *jobs.DBQueue                // Jobs stored in the database and run by the worker command
+	Cache              *cache.Cache                 // Application cache configured under [cache]
+	Stream             stream.Broker                // Message broker configured under [stream]
+	Mailer             *mail.Mailer                 // Outgoing mail configured under [mail]
+	Notifier           *notification.Notifier       // Mail, Slack, webhook and SMS notifications
+	Events             *events.Bus                  // Application event bus
+	Search             search.Engine                // Full-text search engine, set by ConnectDB
 	BasePath           string                       // Base path for the application
 	Apps               []string                     // List of registered apps/modules
 	GormigrateRunner   *gormigrate.GormigrateRunner // Gormigrate migration runner
 	MiddlewareRegistry *registry.MiddlewareRegistry // Middleware registry
-	middlewareStack    []registry.MiddlewareFunc    // Ordered list of middlewares
+	Assets             *bourbon.StaticAssets        // Static asset URL resolver
+	Location           *time.Location               // Application timezone from app.timezone
+	staticRoot         string                       // Directory static files are served from
+	reloader           *dev.Reloader                // Browser refresh when not under the dev server
+	middlewareStack    []stackEntry                 // Enabled middlewares in the order they were added
 	middlewareMu       sync.RWMutex                 // Mutex for middleware stack
+	readinessChecks    map[string]ReadinessCheck    // Extra checks run by /readyz
+	readinessMu        sync.RWMutex                 // Mutex for readiness checks
+	configPath         string                       // Settings file, watched when app.watch_config is on
+	configHooks        []ConfigChangeFunc           // Called by ReloadConfig
+	configMu           sync.Mutex                   // Serializes settings reloads
+	handler            atomic.Value                 // handlerBox served by Run
 }
 
 type Application = App
@@ -41,26 +76,41 @@ type Application = App
 // NewApp creates a new instance of App with default values
 func NewApp() *App {
 	logger, _ := logging.NewLogger(logging.DefaultConfig())
-	return &App{
+	app := &App{
 		Router:             bourbon.NewRouter(),
 		Logger:             logger,
 		Registry:           registry.NewRegistry(),
 		BasePath:           ".",
 		Apps:               make([]string, 0),
 		MiddlewareRegistry: registry.NewMiddlewareRegistry(),
-		middlewareStack:    make([]registry.MiddlewareFunc, 0),
+		middlewareStack:    make([]stackEntry, 0),
 	}
+	app.Router.MiddlewareResolver = func(name string) (func(http.Handler) http.Handler, bool) {
+		mw, ok := app.MiddlewareRegistry.Get(name)
+		return mw, ok
+	}
+	app.registerServices()
+	return app
 }
 
 func NewApplication(configPath string) *Application {
-	app := NewApp()
-
 	config, err := LoadConfig(configPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
 		os.Exit(1)
 	}
 
+	app := NewApplicationWithConfig(config)
+	app.configPath = configPath
+	app.Logger.ToggleDebugOnSignal()
+	return app
+}
+
+// NewApplicationWithConfig creates an application from settings already loaded,
+// e.g. by LoadConfig and then adjusted for a test. There is no settings file to
+// watch or reload.
+func NewApplicationWithConfig(config *Config) *Application {
+	app := NewApp()
 	app.Config = config
 
 	// Initialize logger with config
@@ -73,8 +123,14 @@ func NewApplication(configPath string) *Application {
 		MaxBackups:  config.Logging.MaxBackups,
 		Compress:    config.Logging.Compress,
 		Level:       config.Logging.Level,
+		Format:      config.Logging.Format,
+		Output:      config.Logging.Output,
+		Levels:      config.Logging.Levels,
 		Development: config.App.Debug,
 	}
+	for _, sink := range config.Logging.Sinks {
+		loggerConfig.Sinks = append(loggerConfig.Sinks, logging.SinkConfig(sink))
+	}
 
 	logger, err := logging.NewLogger(loggerConfig)
 	if err != nil {
@@ -82,6 +138,12 @@ func NewApplication(configPath string) *Application {
 		os.Exit(1)
 	}
 	app.Logger = logger
+	app.Router.Logger = logger
+	if trusted, err := middleware.ParseCIDRs(config.Security.TrustedProxies); err != nil {
+		app.Logger.Warn("Invalid trusted proxies, ignoring forwarding headers", zap.Error(err))
+	} else {
+		app.Router.TrustedProxies = trusted
+	}
 
 	// Initialize error store if database error logging is enabled
 	if config.Logging.StoreErrorsInDB {
@@ -94,46 +156,166 @@ func NewApplication(configPath string) *Application {
 		}
 	}
 
-	if config.Templates.Directory != "" {
-		engine := bourbon.NewTemplateEngine(
-			config.Templates.Directory,
-			config.Templates.Extension,
-			config.Templates.AutoReload,
-		)
+	app.Jobs = jobs.NewLocalQueue(config.Jobs.Workers, app.Logger)
+	app.Queue = jobs.NewDBQueue(nil, jobs.DBQueueConfig{
+		MaxAttempts:  config.Jobs.MaxAttempts,
+		Timeout:      config.Jobs.Timeout,
+		PollInterval: config.Jobs.PollInterval,
+		Conn:         orm.Conn,
+	}, app.Logger)
+	orm.SetObserverQueue(app.Jobs)
+	app.initEvents()
+
+	app.initTimezone()
+	app.initStaticAssets()
+	app.registerIPFilters()
+	app.registerCSRF()
+	app.registerLiveReload()
+	app.registerDebugToolbar()
+	app.registerRequestRecorder()
+	app.initCache()
+	app.initStream()
+
+	app.Router.TemplateContext(app.templateDefaults)
+	if embeddedTemplates != nil {
+		if err := app.loadTemplates(embeddedTemplates); err != nil {
+			app.Logger.Warn("Failed to load embedded templates", zap.Error(err), zap.String("engine", config.Templates.Engine))
+		}
+	} else if config.Templates.Directory != "" {
+		if err := app.loadTemplates(nil); err != nil {
+			app.Logger.Warn("Failed to load templates", zap.Error(err), zap.String("engine", config.Templates.Engine), zap.String("directory", config.Templates.Directory))
+		}
+	}
 
-		if err := engine.Load(); err != nil {
-			app.Logger.Warn("Failed to load templates", zap.Error(err), zap.String("directory", config.Templates.Directory))
-		} else {
-			app.Router.TemplateEngine = engine
+	app.initMailer()
+	app.initNotifier()
+
+	if config.Logging.Alerts.Enabled {
+		if app.ErrorStore == nil {
+			// Alerts only need the error counts, not database storage
+			app.ErrorStore = logging.NewErrorStore(nil, false)
 		}
+		app.ErrorStore.EnableAlerts(app.alertConfig())
 	}
 
 	return app
 }
 
-// RegisterMiddleware registers a named middleware in the app's registry
-func (a *App) RegisterMiddleware(name string, middleware registry.MiddlewareFunc) {
-	a.MiddlewareRegistry.Register(name, middleware)
+// initEvents creates app.Events and subscribes the listeners named in [[events.listeners]]
+func (a *App) initEvents() {
+	a.Events = events.NewBus(a.Jobs, a.Logger)
+	for _, l := range a.Config.Events.Listeners {
+		a.Events.Bind(events.Binding{Event: l.Event, Listener: l.Listener, Queued: l.Queued})
+	}
+}
+
+// initMailer creates app.Mailer from [mail]; emails render through the template engine
+// and SendAsync runs on app.Jobs
+func (a *App) initMailer() {
+	cfg := a.Config.Mail
+	transport, err := mail.NewTransport(mail.Config{
+		Driver:     cfg.Driver,
+		Host:       cfg.Host,
+		Port:       cfg.Port,
+		Username:   cfg.Username,
+		Password:   cfg.Password,
+		Encryption: cfg.Encryption,
+		Directory:  cfg.Directory,
+		Timeout:    cfg.Timeout,
+	}, a.Logger)
+	if err != nil {
+		a.Logger.Warn("Failed to configure mail, using log transport", zap.Error(err))
+		transport = &mail.LogTransport{Logger: a.Logger}
+	}
+
+	opts := mail.Options{From: cfg.From, Queue: a.Jobs, Logger: a.Logger}
+	if a.Router.TemplateEngine != nil {
+		opts.Templates = a.Router.TemplateEngine
+	}
+	a.Mailer = mail.NewMailer(transport, opts)
+}
+
+// initCache opens the configured cache, uses it for cached repositories and registers
+// the "cache" response-caching middleware.
+// An unknown driver falls back to the memory store.
+func (a *App) initCache() {
+	cfg := a.Config.Cache
+	c, err := cache.Open(cache.Config{
+		Driver:     cfg.Driver,
+		Prefix:     cfg.Prefix,
+		DefaultTTL: cfg.DefaultTTL,
+		Redis:      cache.RedisConfig(cfg.Redis),
+	})
+	if err != nil {
+		a.Logger.Warn("Failed to open cache, using memory store", zap.Error(err))
+		c = cache.New(cache.NewMemoryStore(time.Minute), cfg.DefaultTTL)
+	}
+
+	a.Cache = c
+	a.Router.Cache = c
+	orm.SetQueryCache(c)
+	a.RegisterMiddleware("cache", middleware.ResponseCache(middleware.ResponseCacheConfig{Cache: c}))
+	if cfg.Driver == "redis" {
+		a.AddReadinessCheck("cache", c.Ping)
+	}
+}
+
+// initStream opens the broker configured under [stream].
+// An unknown driver falls back to the memory broker.
+func (a *App) initStream() {
+	cfg := a.Config.Stream
+	b, err := stream.Open(stream.Config{
+		Driver:     cfg.Driver,
+		ClaimAfter: cfg.ClaimAfter,
+		Redis:      stream.RedisConfig(cfg.Redis),
+		Options:    cfg.Options,
+	})
+	if err != nil {
+		a.Logger.Warn("Failed to open stream broker, using memory broker", zap.Error(err))
+		b = stream.NewMemoryBroker(cfg.ClaimAfter)
+	}
+	a.Stream = b
+}
+
+// stackEntry is a middleware enabled on the app together with its priority
+type stackEntry struct {
+	name       string
+	middleware registry.MiddlewareFunc
+	priority   int
+	entry      *registry.MiddlewareEntry // registered middleware, for its conditions
+}
+
+// RegisterMiddleware registers a named middleware in the app's registry.
+// Options set its priority and the requests it applies to:
+//
+//	app.RegisterMiddleware("csrf", csrf, registry.Priority(50), registry.Only("POST"), registry.Except("/webhooks/*"))
+func (a *App) RegisterMiddleware(name string, middleware registry.MiddlewareFunc, opts ...registry.MiddlewareOption) {
+	a.MiddlewareRegistry.Register(name, middleware, opts...)
 }
 
 // UseMiddleware adds a registered middleware to the stack by name
 func (a *App) UseMiddleware(name string) error {
-	middleware, exists := a.MiddlewareRegistry.Get(name)
+	entry, exists := a.MiddlewareRegistry.GetEntry(name)
 	if !exists {
 		return fmt.Errorf("middleware '%s' not registered", name)
 	}
 
 	a.middlewareMu.Lock()
 	defer a.middlewareMu.Unlock()
-	a.middlewareStack = append(a.middlewareStack, middleware)
+	a.middlewareStack = append(a.middlewareStack, stackEntry{
+		name:       name,
+		middleware: entry.Wrap(),
+		priority:   entry.Priority,
+		entry:      entry,
+	})
 	return nil
 }
 
-// UseMiddlewareFunc adds a middleware function directly to the stack
+// UseMiddlewareFunc adds a middleware function directly to the stack with priority 0
 func (a *App) UseMiddlewareFunc(middleware registry.MiddlewareFunc) {
 	a.middlewareMu.Lock()
 	defer a.middlewareMu.Unlock()
-	a.middlewareStack = append(a.middlewareStack, middleware)
+	a.middlewareStack = append(a.middlewareStack, stackEntry{middleware: middleware})
 }
 
 // Use is an alias for UseMiddlewareFunc for convenience
@@ -145,29 +327,50 @@ func (a *App) Use(middleware registry.MiddlewareFunc) {
 func (a *App) ClearMiddlewares() {
 	a.middlewareMu.Lock()
 	defer a.middlewareMu.Unlock()
-	a.middlewareStack = make([]registry.MiddlewareFunc, 0)
+	a.middlewareStack = make([]stackEntry, 0)
 }
 
-// GetMiddlewares returns a copy of the current middleware stack
+// GetMiddlewares returns a copy of the current middleware stack in execution order
 func (a *App) GetMiddlewares() []registry.MiddlewareFunc {
 	a.middlewareMu.RLock()
 	defer a.middlewareMu.RUnlock()
 
-	stack := make([]registry.MiddlewareFunc, len(a.middlewareStack))
-	copy(stack, a.middlewareStack)
+	ordered := a.orderedMiddlewares()
+	stack := make([]registry.MiddlewareFunc, len(ordered))
+	for i, entry := range ordered {
+		stack[i] = entry.middleware
+	}
 	return stack
 }
 
+// orderedMiddlewares returns the stack sorted by descending priority,
+// keeping insertion order for equal priorities. Callers must hold middlewareMu.
+func (a *App) orderedMiddlewares() []stackEntry {
+	ordered := make([]stackEntry, len(a.middlewareStack))
+	copy(ordered, a.middlewareStack)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].priority > ordered[j].priority
+	})
+	return ordered
+}
+
+// Handler returns the router wrapped in the enabled middleware, as Run serves
+// it, for serving the application in tests or from another server
+func (a *App) Handler() http.Handler {
+	return a.buildHandler()
+}
+
 // buildHandler applies all middlewares in the stack to the router
 func (a *App) buildHandler() http.Handler {
 	a.middlewareMu.RLock()
 	defer a.middlewareMu.RUnlock()
 
 	handler := http.Handler(a.Router)
+	ordered := a.orderedMiddlewares()
 
-	// Apply middlewares in reverse order (last registered wraps first)
-	for i := len(a.middlewareStack) - 1; i >= 0; i-- {
-		handler = a.middlewareStack[i](handler)
+	// Apply middlewares in reverse order so the first (highest priority) runs outermost
+	for i := len(ordered) - 1; i >= 0; i-- {
+		handler = ordered[i].middleware(handler)
 	}
 
 	return handler
@@ -179,6 +382,7 @@ func (a *App) LoadConfig(path string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 	a.Config = config
+	a.configPath = path
 	return nil
 }
 
@@ -188,10 +392,15 @@ func (a *App) RegisterApp(name string) {
 }
 
 func (app *Application) Run() error {
+	app.initOpenAPI()
+	app.initPprof()
+	app.initInspect()
+	app.initRequestDashboard()
 	app.printStartupBanner()
 
-	// Build handler with middleware stack
-	handler := app.buildHandler()
+	// Build handler with middleware stack; ReloadConfig swaps it when settings change
+	app.handler.Store(handlerBox{app.buildHandler()})
+	handler := http.HandlerFunc(app.serveCurrent)
 
 	// Create server if not already created
 	if app.Server == nil {
@@ -207,12 +416,53 @@ func (app *Application) Run() error {
 		app.Server.Handler = handler
 	}
 
-	if app.Config.Static.Directory != "" && app.Config.Static.URLPrefix != "" {
-		app.Static(app.Config.Static.URLPrefix, app.Config.Static.Directory)
+	if embeddedStatic != nil && app.Config.Static.URLPrefix != "" {
+		app.Router.StaticFS(app.Config.Static.URLPrefix, embeddedStatic)
+		app.Logger.Info("Static files mounted",
+			zap.String("prefix", app.Config.Static.URLPrefix),
+			zap.String("directory", "embedded"))
+	} else if app.staticRoot != "" && app.Config.Static.URLPrefix != "" {
+		app.Static(app.Config.Static.URLPrefix, app.staticRoot)
 		app.Logger.Info("Static files mounted",
 			zap.String("prefix", app.Config.Static.URLPrefix),
-			zap.String("directory", app.Config.Static.Directory))
+			zap.String("directory", app.staticRoot))
+	}
+
+	if app.Cache != nil {
+		defer app.Cache.Close()
+	}
+	if app.Stream != nil {
+		defer app.Stream.Close()
+	}
+	if closer, ok := app.Router.TemplateEngine.(io.Closer); ok {
+		// Stops watching the template directory
+		defer closer.Close()
 	}
+	if app.reloader != nil {
+		defer app.reloader.Close()
+	}
+	if app.Config.App.WatchConfig && app.configPath != "" {
+		if watcher, err := app.watchConfig(); err != nil {
+			app.Logger.Warn("Failed to watch settings", zap.Error(err))
+		} else {
+			defer watcher.Close()
+		}
+	}
+	if app.Events != nil {
+		for _, name := range app.Events.UnboundListeners() {
+			app.Logger.Warn("Event listener in [[events.listeners]] is not registered", zap.String("listener", name))
+		}
+	}
+	if app.Jobs != nil {
+		// Finish queued jobs after the server has drained its requests
+		defer app.Jobs.Close()
+	}
+	if app.DBHealth != nil && app.Config.Database.Health.Enabled {
+		app.DBHealth.Start()
+		defer app.DBHealth.Stop()
+	}
+
+	stopConsumers := app.startConsumers()
 
 	go func() {
 		if err := app.Server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -231,34 +481,217 @@ func (app *Application) Run() error {
 	defer cancel()
 
 	if err := app.Server.Shutdown(ctx); err != nil {
+		stopConsumers()
 		return fmt.Errorf("server forced to shutdown: %w", err)
 	}
+	// Finish the messages being handled before queued jobs
+	stopConsumers()
 
 	app.Logger.Info("Server stopped")
 	return nil
 }
 
+// initStaticAssets configures static URL generation.
+// In debug mode files are served locally from the static directory; otherwise
+// URLs use the configured asset host and the collectstatic manifest when present.
+func (a *App) initStaticAssets() {
+	cfg := a.Config.Static
+	a.staticRoot = cfg.Directory
+
+	if a.Config.App.Debug {
+		a.Assets = bourbon.NewStaticAssets(cfg.URLPrefix, "")
+		return
+	}
+
+	a.Assets = bourbon.NewStaticAssets(cfg.URLPrefix, cfg.AssetHost)
+	if cfg.CollectDir == "" {
+		return
+	}
+
+	manifestPath := filepath.Join(cfg.CollectDir, bourbon.ManifestFile)
+	if err := a.Assets.LoadManifest(manifestPath); err == nil {
+		a.staticRoot = cfg.CollectDir
+	} else if !os.IsNotExist(err) {
+		a.Logger.Warn("Failed to load static manifest", zap.Error(err), zap.String("path", manifestPath))
+	}
+}
+
+// AccessLogger returns the request logging middleware configured from [logging.access].
+// Pretty console output is only used when app.debug is enabled.
+func (a *App) AccessLogger() middleware.Middleware {
+	cfg := middleware.AccessLogConfig{}
+	if a.Config != nil {
+		access := a.Config.Logging.Access
+		cfg = middleware.AccessLogConfig{
+			Fields:    access.Fields,
+			Sample:    access.Sample,
+			SkipPaths: access.SkipPaths,
+			Pretty:    access.Pretty && a.Config.App.Debug,
+		}
+	}
+	cfg.TrustedProxies = a.Router.TrustedProxies
+	return middleware.AccessLog(a.Logger, a.ErrorStore, cfg)
+}
+
+// Recoverer returns the panic recovery middleware. With app.debug enabled it renders
+// a debug error page listing the registered routes.
+func (a *App) Recoverer() middleware.Middleware {
+	debug := a.Config != nil && a.Config.App.Debug
+	return middleware.RecoveryWithConfig(a.Logger, a.ErrorStore, middleware.RecoveryConfig{
+		Debug: debug,
+		Routes: func() []string {
+			var routes []string
+			for _, route := range a.Router.GetRoutes() {
+				routes = append(routes, route.Method+" "+route.Pattern)
+			}
+			return routes
+		},
+	})
+}
+
+// alertConfig builds the error alert settings from [logging.alerts]; alerts are sent
+// through app.Notifier
+func (a *App) alertConfig() logging.AlertConfig {
+	alerts := a.Config.Logging.Alerts
+	cfg := logging.AlertConfig{
+		AppName:   a.Config.App.Name,
+		Threshold: alerts.Threshold,
+		Window:    alerts.Window,
+		Cooldown:  alerts.Cooldown,
+		OnError: func(err error) {
+			a.Logger.Warn("Failed to send error alert", zap.Error(err))
+		},
+	}
+	if routes := a.alertRoutes(); len(routes) > 0 {
+		cfg.Notifiers = append(cfg.Notifiers, &alertNotifier{notifier: a.Notifier, routes: routes})
+	}
+	return cfg
+}
+
+// registerIPFilters registers each [security.ip_filters.<name>] list as middleware "ipfilter:<name>"
+func (a *App) registerIPFilters() {
+	for name, filter := range a.Config.Security.IPFilters {
+		mw, err := middleware.IPFilter(middleware.IPFilterConfig{
+			Allow:          filter.Allow,
+			Deny:           filter.Deny,
+			TrustedProxies: a.Config.Security.TrustedProxies,
+		})
+		if err != nil {
+			a.Logger.Warn("Invalid IP filter", zap.String("name", name), zap.Error(err))
+			continue
+		}
+		a.RegisterMiddleware("ipfilter:"+name, mw)
+	}
+}
+
+// registerCSRF registers the CSRF middleware as "csrf" and adds it to the global
+// stack when security.csrf_enabled is set
+func (a *App) registerCSRF() {
+	a.RegisterMiddleware("csrf", middleware.CSRF(middleware.CSRFConfig{}))
+	if a.Config.Security.CSRFEnabled {
+		_ = a.UseMiddleware("csrf")
+	}
+}
+
+// registerLiveReload injects the browser live-reload script in debug mode. Under the
+// dev server the script connects to it; otherwise the app watches its own template
+// and static directories. Templates are then always reloaded on change.
+func (a *App) registerLiveReload() {
+	if !a.Config.App.Debug {
+		return
+	}
+
+	var mw middleware.Middleware
+	if base := os.Getenv(dev.LiveReloadEnv); base != "" {
+		mw = middleware.LiveReload(dev.ScriptURL(base))
+	} else if a.Config.App.LiveReload {
+		reloader, err := dev.NewReloader(a.Config.Templates.Directory, a.Config.Static.Directory)
+		if err != nil {
+			a.Logger.Warn("Live reload disabled", zap.Error(err))
+			return
+		}
+		a.reloader = reloader
+		mw = reloader.Middleware()
+	} else {
+		return
+	}
+
+	a.Config.Templates.AutoReload = true
+	// Outside recovery, so debug error pages reload once the code is fixed
+	a.RegisterMiddleware("livereload", mw, registry.Priority(1001))
+	_ = a.UseMiddleware("livereload")
+}
+
+// registerDebugToolbar adds the debug toolbar to HTML pages in debug mode, and
+// the X-Bourbon-Queries headers to every response
+func (a *App) registerDebugToolbar() {
+	if a.Config.App.QueryInspector != nil {
+		a.Logger.Warn("app.query_inspector is deprecated, use app.debug_toolbar")
+	}
+	if !a.Config.App.Debug || !a.Config.App.DebugToolbar {
+		return
+	}
+
+	cfg := middleware.DebugToolbarConfig{
+		Logger:     a.Logger,
+		Middleware: a.requestMiddleware,
+	}
+	if a.Config.Logging.Recorder.Enabled {
+		cfg.RequestsURL = RequestDashboardPrefix
+	}
+	// Outside the recorder and recovery, so error pages get the toolbar and
+	// recorded responses don't
+	a.RegisterMiddleware("debug_toolbar", middleware.DebugToolbar(cfg), registry.Priority(1000), registry.Except(a.debugSkipPaths()...))
+	_ = a.UseMiddleware("debug_toolbar")
+}
+
 func (a *App) Static(prefix, root string) {
 	a.Router.Static(prefix, root)
 }
 
+// loadTemplates creates the configured template engine over fsys, or over
+// templates.directory when fsys is nil
+func (a *App) loadTemplates(fsys fs.FS) error {
+	cfg := a.Config.Templates
+	engine, err := bourbon.NewRenderer(cfg.Engine, bourbon.RendererConfig{
+		FS:         fsys,
+		Directory:  cfg.Directory,
+		Extension:  cfg.Extension,
+		AutoReload: cfg.AutoReload && fsys == nil,
+		Funcs:      template.FuncMap{"static": a.Assets.URL, "asset": a.Assets.URL, "date": bourbon.DateFunc(a.Location)},
+	})
+	if err != nil {
+		return err
+	}
+	if closer, ok := a.Router.TemplateEngine.(io.Closer); ok {
+		_ = closer.Close()
+	}
+	a.Router.TemplateEngine = engine
+	return nil
+}
+
+// SetTemplateFS renders templates from fsys, typically an embed.FS, so they are
+// compiled into the binary and parsed once at startup:
+//
+//	//go:embed templates
+//	var templateFiles embed.FS
+//
+//	sub, _ := fs.Sub(templateFiles, "templates")
+//	if err := app.SetTemplateFS(sub); err != nil { ... }
+func (a *App) SetTemplateFS(fsys fs.FS) error {
+	return a.loadTemplates(fsys)
+}
+
+// AddTemplateFunc adds a template function. Engines without custom functions ignore it.
 func (a *App) AddTemplateFunc(name string, fn interface{}) {
 	if a.Router.TemplateEngine != nil {
-		a.Router.TemplateEngine.AddFunc(name, fn)
-		if a.Config.Templates.AutoReload {
-			_ = a.Router.TemplateEngine.Load()
-		}
+		bourbon.AddRendererFunc(a.Router.TemplateEngine, name, fn)
 	}
 }
 
 func (a *App) AddTemplateFuncs(funcs map[string]interface{}) {
-	if a.Router.TemplateEngine != nil {
-		for name, fn := range funcs {
-			a.Router.TemplateEngine.AddFunc(name, fn)
-		}
-		if a.Config.Templates.AutoReload {
-			_ = a.Router.TemplateEngine.Load()
-		}
+	for name, fn := range funcs {
+		a.AddTemplateFunc(name, fn)
 	}
 }
 
@@ -282,29 +715,45 @@ func (app *Application) printStartupBanner() {
 	fmt.Println()
 }
 
+// ORMConfig converts the [database] settings to the orm package's configuration,
+// without a query logger
+func (d DatabaseConfig) ORMConfig() orm.DatabaseConfig {
+	config := orm.DatabaseConfig{
+		Driver:          d.Driver,
+		Host:            d.Host,
+		Port:            d.Port,
+		Name:            d.Name,
+		User:            d.User,
+		Password:        d.Password,
+		Path:            d.Path,
+		MaxOpenConns:    d.MaxOpenConns,
+		MaxIdleConns:    d.MaxIdleConns,
+		ConnMaxLifetime: d.ConnMaxLifetime,
+		Options: orm.DatabaseOptions{
+			SSLMode:            d.Options.SSLMode,
+			LogQueries:         d.Options.LogQueries,
+			SlowQueryThreshold: d.Options.SlowQueryThreshold,
+			Params:             make(map[string]string, len(d.Options.Params)),
+		},
+		Replicas: orm.ReplicasConfig{Policy: d.Replicas.Policy},
+	}
+	for key, value := range d.Options.Params {
+		config.Options.Params[key] = fmt.Sprint(value)
+	}
+	for _, node := range d.Replicas.Nodes {
+		config.Replicas.Nodes = append(config.Replicas.Nodes, orm.ReplicaConfig(node))
+	}
+	return config
+}
+
 // ConnectDB establishes database connection using the application configuration
 func (a *App) ConnectDB() error {
 	if a.Config == nil {
 		return fmt.Errorf("config not loaded")
 	}
 
-	// Convert Config.Database to orm.DatabaseConfig
-	dbConfig := orm.DatabaseConfig{
-		Driver:          a.Config.Database.Driver,
-		Host:            a.Config.Database.Host,
-		Port:            a.Config.Database.Port,
-		Name:            a.Config.Database.Name,
-		User:            a.Config.Database.User,
-		Password:        a.Config.Database.Password,
-		Path:            a.Config.Database.Path,
-		MaxOpenConns:    a.Config.Database.MaxOpenConns,
-		MaxIdleConns:    a.Config.Database.MaxIdleConns,
-		ConnMaxLifetime: a.Config.Database.ConnMaxLifetime,
-		Options: orm.DatabaseOptions{
-			SSLMode:    a.Config.Database.Options.SSLMode,
-			LogQueries: a.Config.Database.Options.LogQueries,
-		},
-	}
+	dbConfig := a.Config.Database.ORMConfig()
+	dbConfig.Logger = orm.NewQueryLogger(a.Logger, dbConfig.Options, a.Config.App.Debug)
 
 	db, err := orm.ConnectDatabase(dbConfig, a.Config.App.Debug)
 	if err != nil {
@@ -312,17 +761,73 @@ func (a *App) ConnectDB() error {
 	}
 
 	a.DB = db
+	a.RegisterMiddleware("transaction", middleware.Transaction(middleware.TransactionConfig{DB: db}))
+	if len(dbConfig.Replicas.Nodes) > 0 && a.Config.Database.Replicas.Sticky {
+		a.Use(middleware.StickyPrimary)
+	}
+
+	// Pinging starts with Run, so CLI commands do not spawn the monitor loop
+	a.DBHealth = orm.NewHealthMonitor(db, orm.HealthConfig{
+		Interval:   a.Config.Database.Health.Interval,
+		Timeout:    a.Config.Database.Health.Timeout,
+		MaxBackoff: a.Config.Database.Health.MaxBackoff,
+	}, a.Logger)
+	a.registerDBMetrics(a.DBHealth)
+
+	engine, err := search.Open(search.Config(a.Config.Search), db)
+	if err != nil {
+		// The database engine is optional on databases without full-text search
+		if len(search.Indexes()) > 0 {
+			a.Logger.Warn("Search is unavailable", zap.Error(err))
+		}
+	} else {
+		a.Search = engine
+		search.SetEngine(engine)
+	}
+
+	// The job queue and error store are created before the database is connected
+	if a.Queue != nil {
+		a.Queue.SetDB(orm.Primary(db))
+	}
+	if a.ErrorStore != nil {
+		a.ErrorStore.SetDB(orm.Primary(db))
+		if a.Config.Logging.StoreErrorsInDB {
+			if err := a.ErrorStore.Migrate(); err != nil {
+				a.Logger.Warn("Failed to migrate error logs table", zap.Error(err))
+			}
+		}
+	}
 	return nil
 }
 
+// WithTx runs fn in a database transaction; see orm.Transaction.
+// Calls made with a context that already carries a transaction use a savepoint.
+func (a *App) WithTx(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	if a.DB == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	return orm.Transaction(ctx, a.DB, fn)
+}
+
 // InitMigrations initializes the gormigrate runner with registered migrations
 func (a *App) InitMigrations() error {
 	if a.DB == nil {
 		return fmt.Errorf("database not initialized")
 	}
 
-	a.GormigrateRunner = gormigrate.NewGormigrateRunner(a.DB)
-	migrations := gormigrate.GetGormigrateMigrations()
+	if err := gormigrate.RegisterSQLMigrationDirs("."); err != nil {
+		return fmt.Errorf("failed to load SQL migrations: %w", err)
+	}
+
+	a.GormigrateRunner = gormigrate.NewGormigrateRunner(orm.Primary(a.DB))
+	a.GormigrateRunner.LockTimeout = a.Config.Database.MigrationLockTimeout
+	a.GormigrateRunner.Unlocked = a.Config.Database.MigrationUnlocked
+	// Each migration runs in its own transaction where schema changes can be rolled back
+	transactionalDDL := orm.FeaturesFor(a.DB.Dialector.Name()).TransactionalDDL
+	migrations, err := gormigrate.RunnableMigrations(transactionalDDL)
+	if err != nil {
+		return err
+	}
 
 	if len(migrations) > 0 {
 		a.GormigrateRunner.AddMigrations(migrations)
@@ -366,6 +871,16 @@ func (a *App) RollbackTo(migrationID string) error {
 	return a.GormigrateRunner.RollbackTo(migrationID)
 }
 
+// RollbackMigrations rolls back the given applied migrations in order
+func (a *App) RollbackMigrations(ids []string) error {
+	if a.GormigrateRunner == nil {
+		if err := a.InitMigrations(); err != nil {
+			return err
+		}
+	}
+	return a.GormigrateRunner.RollbackMigrations(ids)
+}
+
 // MigrateTo migrates to a specific migration
 func (a *App) MigrateTo(migrationID string) error {
 	if a.GormigrateRunner == nil {
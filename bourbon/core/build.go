@@ -0,0 +1,27 @@
+package core
+
+import "io/fs"
+
+// Build information, stamped into production binaries by bourbon build:
+//
+//	go build -ldflags "-X github.com/ishubhamsingh2e/bourbon/bourbon/core.Version=v1.2.0"
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Assets compiled into the binary, which replace the configured directories
+var (
+	embeddedTemplates fs.FS
+	embeddedStatic    fs.FS
+)
+
+// UseEmbeddedAssets serves templates and static files from the given file
+// systems, typically sub-trees of an embed.FS, instead of templates.directory
+// and static.directory. Either may be nil to keep reading from disk. It must be
+// called before NewApplication; bourbon build does so from a generated init.
+func UseEmbeddedAssets(templates, static fs.FS) {
+	embeddedTemplates = templates
+	embeddedStatic = static
+}
@@ -1,9 +1,11 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"strconv"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"time"
 
@@ -11,15 +13,26 @@ import (
 )
 
 type Config struct {
-	App        AppConfig        `mapstructure:"app"`
-	Server     ServerConfig     `mapstructure:"server"`
-	Database   DatabaseConfig   `mapstructure:"database"`
-	Apps       AppsConfig       `mapstructure:"apps"`
-	Middleware MiddlewareConfig `mapstructure:"middleware"`
-	Templates  TemplatesConfig  `mapstructure:"templates"`
-	Static     StaticConfig     `mapstructure:"static"`
-	Logging    LoggingConfig    `mapstructure:"logging"`
-	Security   SecurityConfig   `mapstructure:"security"`
+	App           AppConfig           `mapstructure:"app"`
+	Server        ServerConfig        `mapstructure:"server"`
+	Database      DatabaseConfig      `mapstructure:"database"`
+	Apps          AppsConfig          `mapstructure:"apps"`
+	Middleware    MiddlewareConfig    `mapstructure:"middleware"`
+	Templates     TemplatesConfig     `mapstructure:"templates"`
+	Static        StaticConfig        `mapstructure:"static"`
+	Logging       LoggingConfig       `mapstructure:"logging"`
+	Security      SecurityConfig      `mapstructure:"security"`
+	Mail          MailConfig          `mapstructure:"mail"`
+	Cache         CacheConfig         `mapstructure:"cache"`
+	Search        SearchConfig        `mapstructure:"search"`
+	Jobs          JobsConfig          `mapstructure:"jobs"`
+	Stream        StreamConfig        `mapstructure:"stream"`
+	Notifications NotificationsConfig `mapstructure:"notifications"`
+	Events        EventsConfig        `mapstructure:"events"`
+	Admin         AdminConfig         `mapstructure:"admin"`
+	OpenAPI       OpenAPIConfig       `mapstructure:"openapi"`
+
+	sections map[string]interface{} // decoded sections registered with RegisterConfigSection
 }
 
 type AppConfig struct {
@@ -28,6 +41,19 @@ type AppConfig struct {
 	Debug     bool   `mapstructure:"debug"`
 	SecretKey string `mapstructure:"secret_key"`
 	Timezone  string `mapstructure:"timezone"`
+	// LiveReload refreshes browsers when templates or static files change (debug only)
+	LiveReload bool `mapstructure:"live_reload"`
+	// DebugToolbar shows how each request was served in a toolbar on HTML pages,
+	// and its SQL in headers (debug only)
+	DebugToolbar bool `mapstructure:"debug_toolbar"`
+	// QueryInspector is the former name of DebugToolbar; when set it overrides it.
+	//
+	// Deprecated: use debug_toolbar.
+	QueryInspector *bool `mapstructure:"query_inspector"`
+	// StrictConfig rejects keys in settings.toml that no setting uses
+	StrictConfig bool `mapstructure:"strict_config"`
+	// WatchConfig reloads the settings when settings.toml changes
+	WatchConfig bool `mapstructure:"watch_config"`
 }
 
 type ServerConfig struct {
@@ -36,6 +62,15 @@ type ServerConfig struct {
 	ReadTimeout    int    `mapstructure:"read_timeout"`
 	WriteTimeout   int    `mapstructure:"write_timeout"`
 	MaxHeaderBytes int    `mapstructure:"max_header_bytes"`
+
+	// EnablePprof serves the profiling endpoints outside debug mode too, see MountPprof
+	EnablePprof bool `mapstructure:"enable_pprof"`
+	// PprofAllow are the addresses and CIDR ranges allowed to the profiling
+	// endpoints, loopback only when empty
+	PprofAllow []string `mapstructure:"pprof_allow"`
+	// PprofUser and PprofPassword require HTTP Basic auth on them too
+	PprofUser     string `mapstructure:"pprof_user"`
+	PprofPassword string `mapstructure:"pprof_password"`
 }
 
 type DatabaseConfig struct {
@@ -51,12 +86,50 @@ type DatabaseConfig struct {
 	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
 	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
 
-	Options DatabaseOptions `mapstructure:"options"`
+	// MigrationLockTimeout is how long migrate waits for another instance's migrations
+	MigrationLockTimeout time.Duration `mapstructure:"migration_lock_timeout"`
+	// MigrationUnlocked runs migrations without the migration lock
+	MigrationUnlocked bool `mapstructure:"migration_unlocked"`
+
+	Options  DatabaseOptions `mapstructure:"options"`
+	Replicas ReplicasConfig  `mapstructure:"replicas"`
+	Health   DBHealthConfig  `mapstructure:"health"`
+}
+
+// DBHealthConfig configures the background database ping under [database.health]
+type DBHealthConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	Interval   time.Duration `mapstructure:"interval"`
+	Timeout    time.Duration `mapstructure:"timeout"`
+	MaxBackoff time.Duration `mapstructure:"max_backoff"`
+}
+
+// ReplicasConfig configures read replicas under [database.replicas]
+type ReplicasConfig struct {
+	Nodes  []ReplicaConfig `mapstructure:"nodes"`
+	Policy string          `mapstructure:"policy"` // "random" or "round_robin"
+	// Sticky sends a request's reads to the primary after it writes
+	Sticky bool `mapstructure:"sticky"`
+}
+
+// ReplicaConfig is one [[database.replicas.nodes]] entry; empty fields inherit the primary's settings
+type ReplicaConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Name     string `mapstructure:"name"`
+	User     string `mapstructure:"user"`
+	Password string `mapstructure:"password"`
+	Path     string `mapstructure:"path"`
 }
 
 type DatabaseOptions struct {
-	SSLMode    string `mapstructure:"ssl_mode"`
-	LogQueries bool   `mapstructure:"log_queries"`
+	SSLMode            string        `mapstructure:"ssl_mode"`
+	LogQueries         bool          `mapstructure:"log_queries"`
+	SlowQueryThreshold time.Duration `mapstructure:"slow_query_threshold"` // e.g. "200ms"; 0 disables
+
+	// Params collects every other [database.options] key (charset, search_path,
+	// connect_timeout, tls_ca, ...) and is passed to the driver's DSN
+	Params map[string]interface{} `mapstructure:",remain"`
 }
 
 type AppsConfig struct {
@@ -64,10 +137,19 @@ type AppsConfig struct {
 }
 
 type MiddlewareConfig struct {
+	Enabled []string                         `mapstructure:"enabled"`
+	Groups  map[string]MiddlewareGroupConfig `mapstructure:"groups"`
+}
+
+// MiddlewareGroupConfig declares the middleware stack for a named route group,
+// e.g. [middleware.groups.api] prefix = "/api" enabled = ["jwt", "ratelimit"]
+type MiddlewareGroupConfig struct {
+	Prefix  string   `mapstructure:"prefix"`
 	Enabled []string `mapstructure:"enabled"`
 }
 
 type TemplatesConfig struct {
+	Engine     string   `mapstructure:"engine"` // registered template engine, default "html"
 	Directory  string   `mapstructure:"directory"`
 	Extension  string   `mapstructure:"extension"`
 	AutoReload bool     `mapstructure:"auto_reload"`
@@ -75,72 +157,323 @@ type TemplatesConfig struct {
 }
 
 type StaticConfig struct {
-	Directory string `mapstructure:"directory"`
-	URLPrefix string `mapstructure:"url_prefix"`
+	Directory  string `mapstructure:"directory"`
+	URLPrefix  string `mapstructure:"url_prefix"`
+	AssetHost  string `mapstructure:"asset_host"`  // CDN origin used in static URLs when debug is off
+	CollectDir string `mapstructure:"collect_dir"` // output directory for collectstatic
 }
 
 type LoggingConfig struct {
-	Level           string `mapstructure:"level"`
-	Format          string `mapstructure:"format"`
-	Output          string `mapstructure:"output"`
-	FileLogging     bool   `mapstructure:"file_logging"`
-	StoragePath     string `mapstructure:"storage_path"`
-	Rotation        string `mapstructure:"rotation"`        // hourly, daily, weekly, none
-	MaxSize         int    `mapstructure:"max_size"`        // MB
-	MaxAge          int    `mapstructure:"max_age"`         // days
-	MaxBackups      int    `mapstructure:"max_backups"`     // number of backups
-	Compress        bool   `mapstructure:"compress"`        // compress old logs
-	StoreErrorsInDB bool   `mapstructure:"store_errors_db"` // store 5xx errors in database
+	Level           string            `mapstructure:"level"`
+	Format          string            `mapstructure:"format"`
+	Output          string            `mapstructure:"output"`
+	FileLogging     bool              `mapstructure:"file_logging"`
+	StoragePath     string            `mapstructure:"storage_path"`
+	Rotation        string            `mapstructure:"rotation"`        // hourly, daily, weekly, none
+	MaxSize         int               `mapstructure:"max_size"`        // MB
+	MaxAge          int               `mapstructure:"max_age"`         // days
+	MaxBackups      int               `mapstructure:"max_backups"`     // number of backups
+	Compress        bool              `mapstructure:"compress"`        // compress old logs
+	StoreErrorsInDB bool              `mapstructure:"store_errors_db"` // store 5xx errors in database
+	Access          AccessLogConfig   `mapstructure:"access"`
+	Alerts          AlertsConfig      `mapstructure:"alerts"`
+	Recorder        RecorderConfig    `mapstructure:"recorder"`
+	Sinks           []LogSinkConfig   `mapstructure:"sinks"`
+	Levels          map[string]string `mapstructure:"levels"` // per-module overrides, e.g. http = "warn"
+}
+
+// LogSinkConfig declares one [[logging.sinks]] destination
+type LogSinkConfig struct {
+	Type    string            `mapstructure:"type"` // stdout, stderr, file, syslog, journald, loki, otlp
+	Level   string            `mapstructure:"level"`
+	Format  string            `mapstructure:"format"`
+	Address string            `mapstructure:"address"`
+	Tag     string            `mapstructure:"tag"`
+	Labels  map[string]string `mapstructure:"labels"`
+	Headers map[string]string `mapstructure:"headers"`
+}
+
+// AlertsConfig configures 5xx spike alerts
+type AlertsConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Threshold    int           `mapstructure:"threshold"`     // errors within window that trigger an alert
+	Window       time.Duration `mapstructure:"window"`        // e.g. "1m"
+	Cooldown     time.Duration `mapstructure:"cooldown"`      // minimum time between alerts
+	SlackWebhook string        `mapstructure:"slack_webhook"` // Slack incoming webhook URL
+	Email        []string      `mapstructure:"email"`         // recipients, sent via [mail]
+	Webhook      string        `mapstructure:"webhook"`       // URL receiving a JSON alert
+	SMS          string        `mapstructure:"sms"`           // phone number, sent via [notifications.sms]
+}
+
+// MailConfig configures app.Mailer under [mail]
+type MailConfig struct {
+	Driver     string        `mapstructure:"driver"` // smtp, log or file
+	Host       string        `mapstructure:"host"`
+	Port       int           `mapstructure:"port"`
+	Username   string        `mapstructure:"username"`
+	Password   string        `mapstructure:"password"`
+	Encryption string        `mapstructure:"encryption"` // starttls, tls or none
+	From       string        `mapstructure:"from"`
+	Directory  string        `mapstructure:"directory"` // output of the file driver
+	Timeout    time.Duration `mapstructure:"timeout"`
+}
+
+// CacheConfig configures app.Cache under [cache]
+type CacheConfig struct {
+	Driver     string           `mapstructure:"driver"` // memory or redis
+	Prefix     string           `mapstructure:"prefix"`
+	DefaultTTL time.Duration    `mapstructure:"default_ttl"`
+	Redis      CacheRedisConfig `mapstructure:"redis"`
+}
+
+// CacheRedisConfig holds the Redis connection under [cache.redis]
+type CacheRedisConfig struct {
+	Addr     string `mapstructure:"addr"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
+}
+
+// SearchConfig configures app.Search under [search]
+type SearchConfig struct {
+	Driver   string `mapstructure:"driver"` // database, meilisearch or elasticsearch
+	URL      string `mapstructure:"url"`
+	APIKey   string `mapstructure:"api_key"`
+	Language string `mapstructure:"language"`
+}
+
+// NotificationsConfig configures the channels of app.Notifier under [notifications]
+type NotificationsConfig struct {
+	SlackWebhook  string    `mapstructure:"slack_webhook"`  // default Slack webhook URL
+	WebhookURL    string    `mapstructure:"webhook_url"`    // default webhook URL
+	WebhookSecret string    `mapstructure:"webhook_secret"` // signs webhook bodies
+	SMS           SMSConfig `mapstructure:"sms"`
+}
+
+// SMSConfig configures the sms notification channel
+type SMSConfig struct {
+	Driver     string `mapstructure:"driver"` // twilio; empty disables sms
+	AccountSID string `mapstructure:"account_sid"`
+	AuthToken  string `mapstructure:"auth_token"`
+	From       string `mapstructure:"from"`
+}
+
+// EventsConfig wires named event listeners under [events]
+type EventsConfig struct {
+	Listeners []EventListenerConfig `mapstructure:"listeners"`
+}
+
+// EventListenerConfig subscribes a listener registered with app.Events.Register
+type EventListenerConfig struct {
+	Event    string `mapstructure:"event"` // event name or pattern, e.g. "user.*"
+	Listener string `mapstructure:"listener"`
+	Queued   bool   `mapstructure:"queued"`
+}
+
+// JobsConfig configures the in-process job queue and the database queue run by
+// the worker command under [jobs]
+type JobsConfig struct {
+	Workers int `mapstructure:"workers"`
+	// Queues and Concurrency are the worker command's defaults for --queue and --concurrency
+	Queues       []string      `mapstructure:"queues"`
+	Concurrency  int           `mapstructure:"concurrency"`
+	MaxAttempts  int           `mapstructure:"max_attempts"`
+	Timeout      time.Duration `mapstructure:"timeout"`       // e.g. "10m"; longer runs are retried
+	PollInterval time.Duration `mapstructure:"poll_interval"` // e.g. "1s"
+}
+
+// StreamConfig configures app.Stream and its consumers under [stream]
+type StreamConfig struct {
+	Driver string `mapstructure:"driver"` // memory, redis or one added with stream.RegisterBroker
+	// Group is the consumer group of consumers registered without stream.Group (default app.name)
+	Group string `mapstructure:"group"`
+	// ConsumeInServer runs the registered consumers in the server process too,
+	// instead of only with the stream:consume command
+	ConsumeInServer bool              `mapstructure:"consume_in_server"`
+	ClaimAfter      time.Duration     `mapstructure:"claim_after"` // e.g. "1m"; unacknowledged messages are delivered again after this
+	Redis           StreamRedisConfig `mapstructure:"redis"`
+	Options         map[string]string `mapstructure:"options"` // settings of brokers added with stream.RegisterBroker
+}
+
+// StreamRedisConfig holds the Redis connection under [stream.redis]
+type StreamRedisConfig struct {
+	Addr     string `mapstructure:"addr"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
+	MaxLen   int64  `mapstructure:"max_len"` // trims each stream to about this many entries; 0 keeps all
+}
+
+// AdminConfig configures the admin site mounted by app.MountAdmin under [admin]
+type AdminConfig struct {
+	Prefix string `mapstructure:"prefix"`
+	Title  string `mapstructure:"title"`
+}
+
+// OpenAPIConfig configures the generated API document under [openapi]
+type OpenAPIConfig struct {
+	Enabled     bool     `mapstructure:"enabled"` // serve the document and UI outside debug mode too
+	Title       string   `mapstructure:"title"`   // default app.name
+	Version     string   `mapstructure:"version"`
+	Description string   `mapstructure:"description"`
+	Servers     []string `mapstructure:"servers"`
+	Prefix      string   `mapstructure:"prefix"` // only document routes under this path
+	Path        string   `mapstructure:"path"`
+	DocsPath    string   `mapstructure:"docs_path"`
+	Output      string   `mapstructure:"output"` // file written at startup, if set
+}
+
+// AccessLogConfig configures the request access log
+type AccessLogConfig struct {
+	Fields    []string       `mapstructure:"fields"`     // latency, bytes, ip, user_agent, referer, request_id, query
+	Sample    map[string]int `mapstructure:"sample"`     // path prefix -> log 1 in N successful requests
+	SkipPaths []string       `mapstructure:"skip_paths"` // paths never logged
+	Pretty    bool           `mapstructure:"pretty"`     // colored one-line output when app.debug is on
+}
+
+// RecorderConfig configures the debug mode request recorder under [logging.recorder]
+type RecorderConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`       // record requests in debug mode
+	Size         int      `mapstructure:"size"`          // requests kept, the oldest dropped first
+	MaxBodySize  int      `mapstructure:"max_body_size"` // bytes kept per request and response body
+	SkipPaths    []string `mapstructure:"skip_paths"`    // paths not recorded; a trailing "*" matches a prefix
+	RedactFields []string `mapstructure:"redact_fields"` // fields and headers masked besides password, token, ...
 }
 
 type SecurityConfig struct {
-	AllowedHosts      []string `mapstructure:"allowed_hosts"`
-	CorsOrigins       []string `mapstructure:"cors_origins"`
-	CSRFEnabled       bool     `mapstructure:"csrf_enabled"`
-	SessionTimeout    int      `mapstructure:"session_timeout"`
-	SessionCookieName string   `mapstructure:"session_cookie_name"`
+	AllowedHosts      []string                  `mapstructure:"allowed_hosts"`
+	CorsOrigins       []string                  `mapstructure:"cors_origins"`
+	CSRFEnabled       bool                      `mapstructure:"csrf_enabled"`
+	SessionTimeout    int                       `mapstructure:"session_timeout"`
+	SessionCookieName string                    `mapstructure:"session_cookie_name"`
+	TrustedProxies    []string                  `mapstructure:"trusted_proxies"`
+	IPFilters         map[string]IPFilterConfig `mapstructure:"ip_filters"`
+}
+
+// IPFilterConfig declares a named IP allow/deny list, registered as middleware "ipfilter:<name>"
+type IPFilterConfig struct {
+	Allow []string `mapstructure:"allow"`
+	Deny  []string `mapstructure:"deny"`
 }
 
 func LoadConfig(configPath string) (*Config, error) {
-	v := viper.New()
+	v, problems, err := readConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Type errors are reported together with the other problems by validate
+	var config Config
+	decodeErr := v.Unmarshal(&config)
+	var sectionsErr error
+	config.sections, sectionsErr = decodeSections(v)
+	decodeErr = errors.Join(decodeErr, sectionsErr)
+	config.applyAliases()
+
+	if err := config.validate(configPath, v, decodeErr, problems); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// applyAliases maps deprecated keys onto the settings that replaced them
+func (c *Config) applyAliases() {
+	if c.App.QueryInspector != nil {
+		c.App.DebugToolbar = *c.App.QueryInspector
+	}
+}
 
+// readConfig loads the .env file next to the settings file, reads the settings and
+// the overlay for app.env with ${NAME} references expanded, and applies environment
+// overrides. Problems found in the files are returned for validate to report.
+func readConfig(configPath string) (*viper.Viper, configProblems, error) {
+	v := viper.New()
 	setGlobalDefaults(v)
+	setSectionDefaults(v)
+	bindEnv(v)
+
+	if configPath == "" {
+		return v, nil, nil
+	}
+	if err := LoadEnvFile(envFileFor(configPath)); err != nil {
+		return nil, nil, fmt.Errorf("failed to load %s: %w", EnvFile, err)
+	}
 
 	v.SetConfigFile(configPath)
 	v.SetConfigType("toml")
-
 	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return nil, fmt.Errorf("failed to read config file: %w", err)
+			return nil, nil, fmt.Errorf("failed to read config file: %w", err)
 		}
+		return v, nil, nil
+	}
+	files := []string{configPath}
+	problems, err := expandSettings(v, configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	v.SetEnvPrefix("BOURBON")
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	v.AutomaticEnv()
-
-	var config Config
-	if err := v.Unmarshal(&config); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	// The base file, BOURBON_APP_ENV or BOURBON_ENV select the overlay
+	if overlay := OverlayPath(configPath, v.GetString("app.env")); overlay != "" {
+		if _, err := os.Stat(overlay); err == nil {
+			v.SetConfigFile(overlay)
+			if err := v.MergeInConfig(); err != nil {
+				return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+			}
+			overlayProblems, err := expandSettings(v, overlay)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+			}
+			problems = append(problems, overlayProblems.in(overlay)...)
+			files = append(files, overlay)
+		}
 	}
 
-	config.loadEnvOverrides()
+	if v.GetBool("app.strict_config") {
+		for _, file := range files {
+			var unknown configProblems
+			if raw, err := readSettingsFile(file); err == nil {
+				unknown.unknownKeys(raw, reflect.TypeOf(Config{}), "")
+			}
+			if file != configPath {
+				unknown = unknown.in(file)
+			}
+			problems = append(problems, unknown...)
+		}
+	}
+	return v, problems, nil
+}
 
-	return &config, nil
+// OverlayPath returns the settings overlay for env next to the settings file, e.g.
+// settings.production.toml for settings.toml, or "" when env is empty
+func OverlayPath(configPath, env string) string {
+	if env == "" {
+		return ""
+	}
+	ext := filepath.Ext(configPath)
+	return strings.TrimSuffix(configPath, ext) + "." + env + ext
 }
 
 func setGlobalDefaults(v *viper.Viper) {
 	v.SetDefault("app.name", "bourbon-app")
 	v.SetDefault("app.env", "development")
 	v.SetDefault("app.debug", true)
-	v.SetDefault("app.secret_key", "change-me-in-production")
+	v.SetDefault("app.secret_key", defaultSecretKey)
 	v.SetDefault("app.timezone", "UTC")
+	v.SetDefault("app.live_reload", true)
+	v.SetDefault("app.debug_toolbar", true)
+	v.SetDefault("app.strict_config", true)
+	v.SetDefault("app.watch_config", true)
 
 	v.SetDefault("server.host", "0.0.0.0")
 	v.SetDefault("server.port", 8000)
 	v.SetDefault("server.read_timeout", 30)
 	v.SetDefault("server.write_timeout", 30)
 	v.SetDefault("server.max_header_bytes", 1048576)
+	v.SetDefault("server.enable_pprof", false)
+	v.SetDefault("server.pprof_allow", []string{})
+	v.SetDefault("server.pprof_user", "")
+	v.SetDefault("server.pprof_password", "")
 
 	v.SetDefault("database.driver", "sqlite")
 	v.SetDefault("database.host", "localhost")
@@ -152,22 +485,33 @@ func setGlobalDefaults(v *viper.Viper) {
 	v.SetDefault("database.max_open_conns", 25)
 	v.SetDefault("database.max_idle_conns", 5)
 	v.SetDefault("database.conn_max_lifetime", 3600)
+	v.SetDefault("database.migration_lock_timeout", "10m")
 	v.SetDefault("database.options.ssl_mode", "disable")
 	v.SetDefault("database.options.log_queries", false)
+	v.SetDefault("database.options.slow_query_threshold", "200ms")
+	v.SetDefault("database.replicas.policy", "random")
+	v.SetDefault("database.replicas.sticky", true)
+	v.SetDefault("database.health.enabled", true)
+	v.SetDefault("database.health.interval", "15s")
+	v.SetDefault("database.health.timeout", "3s")
+	v.SetDefault("database.health.max_backoff", "1m")
 
 	v.SetDefault("apps.installed", []string{})
 
 	v.SetDefault("middleware.enabled", []string{"Logger", "Recovery"})
 
+	v.SetDefault("templates.engine", "html")
 	v.SetDefault("templates.directory", "templates")
 	v.SetDefault("templates.extension", ".html")
 	v.SetDefault("templates.auto_reload", true)
 
 	v.SetDefault("static.directory", "static")
 	v.SetDefault("static.url_prefix", "/static")
+	v.SetDefault("static.asset_host", "")
+	v.SetDefault("static.collect_dir", "staticfiles")
 
 	v.SetDefault("logging.level", "info")
-	v.SetDefault("logging.format", "json")
+	v.SetDefault("logging.format", "")
 	v.SetDefault("logging.output", "stdout")
 	v.SetDefault("logging.file_logging", false)
 	v.SetDefault("logging.storage_path", "storage/logs")
@@ -177,58 +521,69 @@ func setGlobalDefaults(v *viper.Viper) {
 	v.SetDefault("logging.max_backups", 10)
 	v.SetDefault("logging.compress", true)
 	v.SetDefault("logging.store_errors_db", false)
+	v.SetDefault("logging.access.fields", []string{"latency", "bytes", "ip", "user_agent", "request_id"})
+	v.SetDefault("logging.access.pretty", true)
+	v.SetDefault("logging.alerts.enabled", false)
+	v.SetDefault("logging.alerts.threshold", 10)
+	v.SetDefault("logging.alerts.window", "1m")
+	v.SetDefault("logging.alerts.cooldown", "10m")
+	v.SetDefault("logging.recorder.enabled", true)
+	v.SetDefault("logging.recorder.size", 100)
+	v.SetDefault("logging.recorder.max_body_size", 64*1024)
+	v.SetDefault("logging.recorder.skip_paths", []string{})
+	v.SetDefault("logging.recorder.redact_fields", []string{})
+
+	// Mail defaults
+	v.SetDefault("mail.host", "localhost")
+	v.SetDefault("mail.port", 25)
+	v.SetDefault("mail.from", "bourbon@localhost")
+	v.SetDefault("mail.driver", "smtp")
+	v.SetDefault("mail.encryption", "starttls")
+	v.SetDefault("mail.directory", "storage/mail")
+	v.SetDefault("mail.timeout", "30s")
 
 	v.SetDefault("security.allowed_hosts", []string{"localhost", "127.0.0.1"})
 	v.SetDefault("security.cors_origins", []string{"*"})
 	v.SetDefault("security.csrf_enabled", false)
 	v.SetDefault("security.session_timeout", 3600)
+	v.SetDefault("security.trusted_proxies", []string{})
+
+	v.SetDefault("cache.driver", "memory")
+	v.SetDefault("cache.prefix", "bourbon:")
+	v.SetDefault("cache.default_ttl", "5m")
+	v.SetDefault("cache.redis.addr", "localhost:6379")
+	v.SetDefault("cache.redis.db", 0)
+
+	v.SetDefault("jobs.workers", 4)
+	v.SetDefault("jobs.queues", []string{"default"})
+	v.SetDefault("jobs.concurrency", 4)
+	v.SetDefault("jobs.max_attempts", 3)
+	v.SetDefault("jobs.timeout", "10m")
+	v.SetDefault("jobs.poll_interval", "1s")
+
+	v.SetDefault("stream.driver", "memory")
+	v.SetDefault("stream.group", "")
+	v.SetDefault("stream.consume_in_server", false)
+	v.SetDefault("stream.claim_after", "1m")
+	v.SetDefault("stream.redis.addr", "localhost:6379")
+	v.SetDefault("stream.redis.db", 0)
+	v.SetDefault("stream.redis.max_len", 0)
+
+	v.SetDefault("admin.prefix", "/admin")
+	v.SetDefault("admin.title", "Administration")
+
+	v.SetDefault("openapi.version", "1.0.0")
+	v.SetDefault("openapi.path", "/openapi.json")
+	v.SetDefault("openapi.docs_path", "/docs")
+
+	v.SetDefault("search.driver", "database")
+	v.SetDefault("search.language", "english")
 
 }
 
-func (c *Config) loadEnvOverrides() {
-	if host := os.Getenv("DB_HOST"); host != "" {
-		c.Database.Host = host
-	}
-	if port := os.Getenv("DB_PORT"); port != "" {
-		if p, err := strconv.Atoi(port); err == nil {
-			c.Database.Port = p
-		}
-	}
-	if name := os.Getenv("DB_NAME"); name != "" {
-		c.Database.Name = name
-	}
-	if user := os.Getenv("DB_USER"); user != "" {
-		c.Database.User = user
-	}
-	if pass := os.Getenv("DB_PASSWORD"); pass != "" {
-		c.Database.Password = pass
-	}
-
-	if debug := os.Getenv("DEBUG"); debug != "" {
-		c.App.Debug = debug == "true"
-	}
-	if secret := os.Getenv("SECRET_KEY"); secret != "" {
-		c.App.SecretKey = secret
-	}
-}
-
+// GetViper returns the settings as a viper instance, for sections registered without
+// a type (see RegisterConfigSection)
 func GetViper(configPath string) (*viper.Viper, error) {
-	v := viper.New()
-	setGlobalDefaults(v)
-
-	if configPath != "" {
-		v.SetConfigFile(configPath)
-		v.SetConfigType("toml")
-		if err := v.ReadInConfig(); err != nil {
-			if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-				return nil, err
-			}
-		}
-	}
-
-	v.SetEnvPrefix("BOURBON")
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	v.AutomaticEnv()
-
-	return v, nil
+	v, _, err := readConfig(configPath)
+	return v, err
 }
@@ -0,0 +1,205 @@
+package core
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+	"go.uber.org/zap"
+)
+
+// ConfigChangeFunc is called after the settings files changed, with the settings
+// before and after the change
+type ConfigChangeFunc func(old, new *Config)
+
+// OnConfigChange registers fn to run when the settings files change and the new
+// settings are valid. Log levels, [middleware] enabled and templates auto_reload are
+// applied before fn runs; other sections, such as [server] and [database], only take
+// effect after a restart unless fn acts on them.
+func (a *App) OnConfigChange(fn ConfigChangeFunc) {
+	a.configMu.Lock()
+	defer a.configMu.Unlock()
+	a.configHooks = append(a.configHooks, fn)
+}
+
+// watchConfig reloads the settings when settings.toml or the overlay for app.env
+// changes. Started by Run when app.watch_config is on.
+func (a *App) watchConfig() (io.Closer, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// Editors replace files when saving, so the directory is watched
+	if err := watcher.Add(filepath.Dir(a.configPath)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	files := map[string]bool{filepath.Clean(a.configPath): true}
+	if overlay := OverlayPath(a.configPath, a.Config.App.Env); overlay != "" {
+		files[filepath.Clean(overlay)] = true
+	}
+
+	go func() {
+		timer := time.NewTimer(time.Hour)
+		timer.Stop()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if files[filepath.Clean(event.Name)] && event.Op != fsnotify.Chmod {
+					timer.Reset(200 * time.Millisecond)
+				}
+			case <-timer.C:
+				a.ReloadConfig()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				a.Logger.Warn("Settings watcher error", zap.Error(err))
+			}
+		}
+	}()
+	return watcher, nil
+}
+
+// ReloadConfig reads the settings files again and applies the reload-safe sections.
+// Invalid settings are logged and the running settings are kept.
+func (a *App) ReloadConfig() {
+	if a.configPath == "" {
+		return
+	}
+	next, err := LoadConfig(a.configPath)
+	if err != nil {
+		a.Logger.Warn("Settings not reloaded", zap.Error(err))
+		return
+	}
+
+	a.configMu.Lock()
+	defer a.configMu.Unlock()
+
+	old := *a.Config
+	a.applyLogLevels(&old, next)
+	a.applyMiddleware(&old, next)
+	a.applyTemplates(next)
+
+	if sections := restartSections(&old, next); len(sections) > 0 {
+		a.Logger.Warn("Settings changed that need a restart", zap.Strings("sections", sections))
+	}
+	a.Logger.Info("Settings reloaded", zap.String("path", a.configPath))
+
+	for _, fn := range a.configHooks {
+		fn(&old, next)
+	}
+}
+
+func (a *App) applyLogLevels(old, next *Config) {
+	if next.Logging.Level != old.Logging.Level {
+		if err := a.Logger.SetLevel(next.Logging.Level); err != nil {
+			a.Logger.Warn("Failed to set log level", zap.Error(err))
+		}
+	}
+	for module := range old.Logging.Levels {
+		if _, ok := next.Logging.Levels[module]; !ok {
+			_ = a.Logger.SetModuleLevel(module, "")
+		}
+	}
+	for module, level := range next.Logging.Levels {
+		if err := a.Logger.SetModuleLevel(module, level); err != nil {
+			a.Logger.Warn("Failed to set log level", zap.String("module", module), zap.Error(err))
+		}
+	}
+	a.Config.Logging.Level = next.Logging.Level
+	a.Config.Logging.Levels = next.Logging.Levels
+}
+
+// applyMiddleware swaps the middlewares enabled in settings and rebuilds the handler
+// served by Run. Middlewares added by code are kept.
+func (a *App) applyMiddleware(old, next *Config) {
+	if reflect.DeepEqual(old.Middleware.Enabled, next.Middleware.Enabled) {
+		return
+	}
+
+	configured := make(map[string]bool)
+	for _, name := range old.Middleware.Enabled {
+		configured[name] = true
+	}
+	a.middlewareMu.Lock()
+	stack := a.middlewareStack[:0:0]
+	for _, entry := range a.middlewareStack {
+		if !configured[entry.name] {
+			stack = append(stack, entry)
+		}
+	}
+	a.middlewareStack = stack
+	a.middlewareMu.Unlock()
+
+	for _, name := range next.Middleware.Enabled {
+		if err := a.UseMiddleware(name); err != nil {
+			a.Logger.Warn("Failed to enable middleware", zap.String("middleware", name), zap.Error(err))
+		}
+	}
+	a.Config.Middleware.Enabled = next.Middleware.Enabled
+	a.handler.Store(handlerBox{a.buildHandler()})
+}
+
+func (a *App) applyTemplates(next *Config) {
+	autoReload := next.Templates.AutoReload
+	if _, ok := a.MiddlewareRegistry.Get("livereload"); ok {
+		// Live reload needs fresh templates
+		autoReload = true
+	}
+	if autoReload == a.Config.Templates.AutoReload {
+		return
+	}
+	if a.Router.TemplateEngine != nil {
+		bourbon.SetRendererAutoReload(a.Router.TemplateEngine, autoReload)
+	}
+	a.Config.Templates.AutoReload = autoReload
+}
+
+// restartSections lists the top-level sections that changed in ways ReloadConfig
+// does not apply
+func restartSections(old, next *Config) []string {
+	pending := *next
+	pending.Logging.Level = old.Logging.Level
+	pending.Logging.Levels = old.Logging.Levels
+	pending.Middleware.Enabled = old.Middleware.Enabled
+	pending.Templates.AutoReload = old.Templates.AutoReload
+
+	var sections []string
+	before, after := reflect.ValueOf(*old), reflect.ValueOf(pending)
+	for i := 0; i < before.NumField(); i++ {
+		if !before.Type().Field(i).IsExported() {
+			continue
+		}
+		if !reflect.DeepEqual(before.Field(i).Interface(), after.Field(i).Interface()) {
+			sections = append(sections, before.Type().Field(i).Tag.Get("mapstructure"))
+		}
+	}
+	for name, section := range next.sections {
+		if !reflect.DeepEqual(old.sections[name], section) {
+			sections = append(sections, name)
+		}
+	}
+	sort.Strings(sections)
+	return sections
+}
+
+// handlerBox gives atomic.Value a single concrete type to store
+type handlerBox struct {
+	http.Handler
+}
+
+// serveCurrent serves requests with the latest handler, so middleware changes
+// apply without restarting the server
+func (a *App) serveCurrent(w http.ResponseWriter, r *http.Request) {
+	a.handler.Load().(handlerBox).ServeHTTP(w, r)
+}
@@ -0,0 +1,170 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/validation"
+	"github.com/spf13/viper"
+)
+
+var (
+	configSectionsMu sync.RWMutex
+	configSections   = make(map[string]reflect.Value)
+)
+
+// RegisterConfigSection declares a top-level settings section owned by the app. The
+// prototype, a pointer to a struct, sets the type the section is decoded into and
+// its defaults:
+//
+//	type PaymentsConfig struct {
+//	    APIKey   string        `mapstructure:"api_key" validate:"required"`
+//	    Currency string        `mapstructure:"currency" validate:"len=3"`
+//	    Timeout  time.Duration `mapstructure:"timeout"`
+//	}
+//
+//	core.RegisterConfigSection("payments", &PaymentsConfig{Currency: "usd", Timeout: 10 * time.Second})
+//
+// The section is checked like the framework's own: unknown keys, types, `validate`
+// tags and a Validate() error method. Keys can be overridden with
+// BOURBON_<SECTION>_<KEY>. Read it with ConfigSection. A nil prototype only allows
+// the section, for apps that read it themselves with GetViper.
+// Call it before NewApplication, e.g. in an init function.
+func RegisterConfigSection(name string, prototype interface{}) {
+	var value reflect.Value
+	if prototype != nil {
+		value = reflect.ValueOf(prototype)
+		if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+			panic(fmt.Sprintf("core: config section %q needs a pointer to a struct, got %T", name, prototype))
+		}
+	}
+	configSectionsMu.Lock()
+	defer configSectionsMu.Unlock()
+	configSections[strings.ToLower(name)] = value
+}
+
+// registeredSection returns the prototype of a registered section, nil for one
+// registered without a type
+func registeredSection(name string) (*reflect.Value, bool) {
+	configSectionsMu.RLock()
+	defer configSectionsMu.RUnlock()
+	value, ok := configSections[name]
+	if !ok || !value.IsValid() {
+		return nil, ok
+	}
+	return &value, true
+}
+
+// typedSections returns the registered sections that have a type
+func typedSections() map[string]reflect.Value {
+	configSectionsMu.RLock()
+	defer configSectionsMu.RUnlock()
+	sections := make(map[string]reflect.Value, len(configSections))
+	for name, value := range configSections {
+		if value.IsValid() {
+			sections[name] = value
+		}
+	}
+	return sections
+}
+
+// ConfigSection returns the app's settings section registered with a *T prototype:
+//
+//	payments := core.ConfigSection[PaymentsConfig](app)
+//
+// It panics when no section of type T is registered. Changes to the section are not
+// applied by ReloadConfig; OnConfigChange receives them with Config.Section.
+func ConfigSection[T any](app *App) *T {
+	for _, value := range app.Config.sections {
+		if section, ok := value.(*T); ok {
+			return section
+		}
+	}
+	panic(fmt.Sprintf("core: no config section of type %s is registered", typeOf[T]()))
+}
+
+// Section returns the decoded value of a section registered with
+// RegisterConfigSection, a pointer of the prototype's type, or nil
+func (c *Config) Section(name string) interface{} {
+	return c.sections[strings.ToLower(name)]
+}
+
+// setSectionDefaults makes the prototype values the defaults of each section, which
+// also lets environment variables override its keys
+func setSectionDefaults(v *viper.Viper) {
+	for name, prototype := range typedSections() {
+		setStructDefaults(v, name, prototype.Elem())
+	}
+}
+
+func setStructDefaults(v *viper.Viper, prefix string, value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() || strings.HasSuffix(field.Tag.Get("mapstructure"), ",remain") {
+			continue
+		}
+		key := prefix + "." + settingsName(field)
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Time{}) {
+			setStructDefaults(v, key, value.Field(i))
+			continue
+		}
+		v.SetDefault(key, value.Field(i).Interface())
+	}
+}
+
+// decodeSections decodes each typed section into a copy of its prototype. Type
+// errors are returned for validate to report.
+func decodeSections(v *viper.Viper) (map[string]interface{}, error) {
+	sections := make(map[string]interface{})
+	var errs []error
+	for name, prototype := range typedSections() {
+		// Decoded as a field of a struct, so viper applies environment overrides
+		// and the error names carry the section name
+		holder := reflect.New(reflect.StructOf([]reflect.StructField{{
+			Name: "Section",
+			Type: prototype.Type().Elem(),
+			Tag:  reflect.StructTag(`mapstructure:"` + name + `"`),
+		}}))
+		holder.Elem().Field(0).Set(prototype.Elem())
+		if err := v.Unmarshal(holder.Interface()); err != nil {
+			errs = append(errs, err)
+		}
+		section := reflect.New(prototype.Type().Elem())
+		section.Elem().Set(holder.Elem().Field(0))
+		sections[name] = section.Interface()
+	}
+	return sections, errors.Join(errs...)
+}
+
+// sections checks the `validate` tags and Validate method of each typed section
+func (p *configProblems) sections(c *Config) {
+	for name, section := range c.sections {
+		value := reflect.ValueOf(section).Elem()
+		keys := make(map[string]string)
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			keys[validation.FieldName(field)] = settingsName(field)
+		}
+		for field, message := range validation.Struct(section) {
+			p.add(name+"."+keys[field], "%s", message)
+		}
+		if validator, ok := section.(interface{ Validate() error }); ok {
+			if err := validator.Validate(); err != nil {
+				p.add(name, "%v", err)
+			}
+		}
+	}
+}
+
+// settingsName returns the mapstructure name of a field
+func settingsName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name
+}
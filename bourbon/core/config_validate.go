@@ -0,0 +1,392 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/middleware"
+	"github.com/spf13/viper"
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultSecretKey is the placeholder secret_key; it must be replaced when debug is off
+const defaultSecretKey = "change-me-in-production"
+
+// ConfigError lists every problem found in the settings file, so they can all be
+// fixed before the next start
+type ConfigError struct {
+	Path     string
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	var b strings.Builder
+	if len(e.Problems) == 1 {
+		fmt.Fprintf(&b, "%s has 1 problem:", e.Path)
+	} else {
+		fmt.Fprintf(&b, "%s has %d problems:", e.Path, len(e.Problems))
+	}
+	for _, problem := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(problem)
+	}
+	return b.String()
+}
+
+// configProblem is one problem with a dotted settings key
+type configProblem struct {
+	key     string
+	message string
+}
+
+type configProblems []configProblem
+
+func (p *configProblems) add(key, format string, args ...interface{}) {
+	*p = append(*p, configProblem{key: key, message: fmt.Sprintf(format, args...)})
+}
+
+// in marks problems found in an overlay file
+func (p configProblems) in(file string) configProblems {
+	for i := range p {
+		p[i].message += " (in " + filepath.Base(file) + ")"
+	}
+	return p
+}
+
+// validate reports values of the wrong type, values outside what the framework
+// supports and settings that must be changed when debug is off, together with the
+// problems readConfig found in the files, such as unknown keys in strict mode.
+// decodeErr is the error returned by unmarshalling v into c.
+func (c *Config) validate(path string, v *viper.Viper, decodeErr error, problems configProblems) error {
+	problems.decodeErrors(v, decodeErr)
+	problems.values(c)
+	problems.sections(c)
+	if !c.App.Debug {
+		problems.production(c)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].key < problems[j].key })
+	err := &ConfigError{Path: path}
+	for _, p := range problems {
+		err.Problems = append(err.Problems, p.key+": "+p.message)
+	}
+	return err
+}
+
+// readSettingsFile returns the keys set in the file itself, without defaults or
+// environment variables
+func readSettingsFile(path string) (map[string]interface{}, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("toml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	return v.AllSettings(), nil
+}
+
+// unknownKeys walks raw alongside the struct type t it is decoded into
+func (p *configProblems) unknownKeys(raw map[string]interface{}, t reflect.Type, prefix string) {
+	fields, remain := settingsFields(t)
+	if remain {
+		return
+	}
+
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+		field, ok := fields[key]
+		if !ok {
+			if prefix == "" {
+				if section, registered := registeredSection(key); registered {
+					if table, isTable := raw[key].(map[string]interface{}); isTable && section != nil {
+						p.unknownKeys(table, section.Type().Elem(), name)
+					}
+					continue
+				}
+			}
+			if suggestion := closestKey(key, fields); suggestion != "" {
+				p.add(name, "unknown key (did you mean %q?)", suggestion)
+			} else if prefix == "" {
+				p.add(name, "unknown section (allow it with core.RegisterConfigSection)")
+			} else {
+				p.add(name, "unknown key")
+			}
+			continue
+		}
+		p.nested(raw[key], field, name)
+	}
+}
+
+// nested checks the keys of tables and arrays of tables decoded into structs
+func (p *configProblems) nested(value interface{}, t reflect.Type, name string) {
+	switch t.Kind() {
+	case reflect.Struct:
+		if table, ok := value.(map[string]interface{}); ok && t != reflect.TypeOf(time.Time{}) {
+			p.unknownKeys(table, t, name)
+		}
+	case reflect.Map:
+		table, ok := value.(map[string]interface{})
+		if !ok || t.Elem().Kind() != reflect.Struct {
+			return
+		}
+		for key, entry := range table {
+			p.nested(entry, t.Elem(), name+"."+key)
+		}
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.Struct {
+			return
+		}
+		for i, entry := range tableList(value) {
+			p.unknownKeys(entry, t.Elem(), fmt.Sprintf("%s[%d]", name, i))
+		}
+	}
+}
+
+// tableList returns the tables of a TOML array of tables
+func tableList(value interface{}) []map[string]interface{} {
+	switch list := value.(type) {
+	case []map[string]interface{}:
+		return list
+	case []interface{}:
+		tables := make([]map[string]interface{}, 0, len(list))
+		for _, item := range list {
+			if table, ok := item.(map[string]interface{}); ok {
+				tables = append(tables, table)
+			}
+		}
+		return tables
+	}
+	return nil
+}
+
+// settingsFields maps the mapstructure names of t's fields to their types, and
+// reports whether t collects unknown keys with ",remain"
+func settingsFields(t reflect.Type) (map[string]reflect.Type, bool) {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if strings.HasSuffix(field.Tag.Get("mapstructure"), ",remain") {
+			return nil, true
+		}
+		fields[settingsName(field)] = field.Type
+	}
+	return fields, false
+}
+
+// settingsField returns the type of the field a dotted key decodes into
+func settingsField(key string) (reflect.Type, bool) {
+	t := reflect.TypeOf(Config{})
+	parts := strings.Split(key, ".")
+	if section, ok := registeredSection(parts[0]); ok && section != nil {
+		t, parts = section.Type().Elem(), parts[1:]
+	}
+	for _, part := range parts {
+		if t.Kind() == reflect.Map {
+			t = t.Elem()
+			continue
+		}
+		if t.Kind() != reflect.Struct {
+			return nil, false
+		}
+		fields, _ := settingsFields(t)
+		field, ok := fields[part]
+		if !ok {
+			return nil, false
+		}
+		t = field
+	}
+	return t, true
+}
+
+// closestKey suggests the known key within two edits of key
+func closestKey(key string, fields map[string]reflect.Type) string {
+	best, bestDistance := "", 3
+	for name := range fields {
+		if d := editDistance(key, name); d < bestDistance || (d == bestDistance && name < best) {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// decodeErrors rewrites the type errors collected while unmarshalling
+func (p *configProblems) decodeErrors(v *viper.Viper, err error) {
+	for _, err := range flattenErrors(err) {
+		var named interface{ Name() string }
+		if !errors.As(err, &named) {
+			p.add("settings", "%v", err)
+			continue
+		}
+		key := named.Name()
+		if t, ok := settingsField(key); ok {
+			p.add(key, "expected %s, got %s", describeType(t), describeValue(v.Get(key)))
+		} else {
+			p.add(key, "%v", errors.Unwrap(err))
+		}
+	}
+}
+
+// flattenErrors returns the errors for each key, unwrapping the joined errors
+// mapstructure collects per struct
+func flattenErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(interface{ Name() string }); ok {
+		return []error{err}
+	}
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		var errs []error
+		for _, inner := range e.Unwrap() {
+			errs = append(errs, flattenErrors(inner)...)
+		}
+		return errs
+	case interface{ Unwrap() error }:
+		if inner := e.Unwrap(); inner != nil {
+			return flattenErrors(inner)
+		}
+	}
+	return []error{err}
+}
+
+func describeType(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return `a duration such as "30s"`
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a whole number"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice:
+		return "a list"
+	default:
+		return "a table"
+	}
+}
+
+func describeValue(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return fmt.Sprintf("%q", value)
+	case map[string]interface{}:
+		return "a table"
+	case []interface{}, []map[string]interface{}:
+		return "a list"
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+// values checks settings with a fixed set of supported values
+func (p *configProblems) values(c *Config) {
+	if c.Server.Port < 0 || c.Server.Port > 65535 {
+		p.add("server.port", "must be between 0 and 65535, got %d", c.Server.Port)
+	}
+	if (c.Server.PprofUser == "") != (c.Server.PprofPassword == "") {
+		p.add("server.pprof_user", "must be set together with server.pprof_password")
+	}
+	if _, err := middleware.ParseCIDRs(c.Server.PprofAllow); err != nil {
+		p.add("server.pprof_allow", "%v", err)
+	}
+	if _, err := middleware.ParseCIDRs(c.Security.TrustedProxies); err != nil {
+		p.add("security.trusted_proxies", "%v", err)
+	}
+	if err := checkLogLevel(c.Logging.Level); err != nil {
+		p.add("logging.level", "%v", err)
+	}
+	for module, level := range c.Logging.Levels {
+		if err := checkLogLevel(level); err != nil {
+			p.add("logging.levels."+module, "%v", err)
+		}
+	}
+	if c.Logging.Recorder.Size < 1 {
+		p.add("logging.recorder.size", "must be at least 1, got %d", c.Logging.Recorder.Size)
+	}
+	if c.Jobs.Concurrency < 1 {
+		p.add("jobs.concurrency", "must be at least 1, got %d", c.Jobs.Concurrency)
+	}
+	if c.Jobs.MaxAttempts < 1 {
+		p.add("jobs.max_attempts", "must be at least 1, got %d", c.Jobs.MaxAttempts)
+	}
+	p.oneOf("logging.rotation", c.Logging.Rotation, "hourly", "daily", "weekly", "none")
+	p.oneOf("database.replicas.policy", c.Database.Replicas.Policy, "random", "round_robin")
+	p.oneOf("cache.driver", c.Cache.Driver, "memory", "redis")
+	p.oneOf("mail.driver", c.Mail.Driver, "smtp", "log", "file")
+	p.oneOf("mail.encryption", c.Mail.Encryption, "starttls", "tls", "none")
+	p.oneOf("search.driver", c.Search.Driver, "database", "meilisearch", "elasticsearch")
+}
+
+// oneOf reports a value outside allowed; empty values use the framework default
+func (p *configProblems) oneOf(key, value string, allowed ...string) {
+	if value == "" {
+		return
+	}
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	p.add(key, "unsupported value %q (use %s)", value, strings.Join(allowed, ", "))
+}
+
+func checkLogLevel(level string) error {
+	if level == "" {
+		return nil
+	}
+	var l zapcore.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("unknown level %q (use debug, info, warn, error, dpanic, panic or fatal)", level)
+	}
+	return nil
+}
+
+// production checks settings that are only safe to leave at their defaults in debug mode
+func (p *configProblems) production(c *Config) {
+	if c.App.SecretKey == "" || c.App.SecretKey == defaultSecretKey {
+		p.add("app.secret_key", "must be set to a long random value when debug is off (or set SECRET_KEY)")
+	}
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// registerTestSection registers a config section for the length of a test
+func registerTestSection(t *testing.T, name string, prototype interface{}) {
+	t.Helper()
+	RegisterConfigSection(name, prototype)
+	t.Cleanup(func() {
+		configSectionsMu.Lock()
+		defer configSectionsMu.Unlock()
+		delete(configSections, name)
+	})
+}
+
+func TestUnknownKeys(t *testing.T) {
+	registerTestSection(t, "billing_test", &struct {
+		Currency string `mapstructure:"currency"`
+	}{})
+	registerTestSection(t, "untyped_test", nil)
+
+	raw := map[string]interface{}{
+		"app":    map[string]interface{}{"name": "shop", "debgu": true},
+		"server": map[string]interface{}{"port": 8000, "colour": "blue"},
+		"caches": map[string]interface{}{"driver": "redis"},
+		"security": map[string]interface{}{
+			"ip_filters": map[string]interface{}{
+				"admin": map[string]interface{}{"allow": []interface{}{"10.0.0.0/8"}, "alow": []interface{}{}},
+			},
+		},
+		"database": map[string]interface{}{
+			"replicas": map[string]interface{}{
+				"nodes": []interface{}{
+					map[string]interface{}{"host": "db1"},
+					map[string]interface{}{"hots": "db2"},
+				},
+			},
+		},
+		"billing_test": map[string]interface{}{"currency": "EUR", "curency": "USD"},
+		"untyped_test": map[string]interface{}{"anything": 1},
+		"xyzzy":        1,
+	}
+
+	var problems configProblems
+	problems.unknownKeys(raw, reflect.TypeOf(Config{}), "")
+
+	got := make([]string, 0, len(problems))
+	for _, p := range problems {
+		got = append(got, p.key+": "+p.message)
+	}
+	want := []string{
+		`app.debgu: unknown key (did you mean "debug"?)`,
+		`billing_test.curency: unknown key (did you mean "currency"?)`,
+		`caches: unknown key (did you mean "cache"?)`,
+		`database.replicas.nodes[1].hots: unknown key (did you mean "host"?)`,
+		`security.ip_filters.admin.alow: unknown key (did you mean "allow"?)`,
+		`server.colour: unknown key`,
+		`xyzzy: unknown section (allow it with core.RegisterConfigSection)`,
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, want) {
+		t.Errorf("unknownKeys() found\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestClosestKey(t *testing.T) {
+	fields := map[string]reflect.Type{
+		"host": nil, "port": nil, "read_timeout": nil, "write_timeout": nil,
+	}
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"host", "host"},
+		{"hots", "host"},
+		{"prot", "port"},
+		{"post", "host"}, // one edit from both; ties go to the first name
+		{"read_timout", "read_timeout"},
+		{"readtimeout", "read_timeout"},
+		{"timeout", ""},
+		{"xyz", ""},
+	}
+	for _, tt := range tests {
+		if got := closestKey(tt.key, fields); got != tt.want {
+			t.Errorf("closestKey(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
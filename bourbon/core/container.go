@@ -0,0 +1,147 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/cache"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core/registry"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/events"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/jobs"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/mail"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/notification"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/search"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/stream"
+	"gorm.io/gorm"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Provide registers the factory building services of type T. Services are singletons
+// unless registry.Transient is given. Providing T again replaces the factory, which
+// is how tests swap implementations:
+//
+//	core.Provide(app, func(app *core.App) (UserRepository, error) {
+//	    return NewGormUserRepository(app.DB), nil
+//	})
+func Provide[T any](app *App, factory func(app *App) (T, error), lifetime ...registry.Lifetime) {
+	l := registry.Singleton
+	if len(lifetime) > 0 {
+		l = lifetime[0]
+	}
+	app.Registry.Provide(typeOf[T](), l, func() (interface{}, error) {
+		return factory(app)
+	})
+}
+
+// ProvideValue registers an existing value as the service of type T
+func ProvideValue[T any](app *App, value T) {
+	app.Registry.Provide(typeOf[T](), registry.Singleton, func() (interface{}, error) {
+		return value, nil
+	})
+}
+
+// Resolve returns the service of type T
+func Resolve[T any](app *App) (T, error) {
+	var zero T
+	service, err := app.Registry.Resolve(typeOf[T]())
+	if err != nil {
+		return zero, err
+	}
+	if service == nil {
+		return zero, nil
+	}
+	return service.(T), nil
+}
+
+// MustResolve is like Resolve but panics on error
+func MustResolve[T any](app *App) T {
+	service, err := Resolve[T](app)
+	if err != nil {
+		panic(err)
+	}
+	return service
+}
+
+// Construct calls constructor with its parameters resolved from the container and
+// returns its result as T. The constructor returns T, or T and an error:
+//
+//	func NewUserController(users UserRepository, mailer *mail.Mailer) *UserController
+//
+//	users := core.MustConstruct[*UserController](app, NewUserController)
+func Construct[T any](app *App, constructor interface{}) (T, error) {
+	var zero T
+	fn := reflect.ValueOf(constructor)
+	ft := fn.Type()
+	if ft.Kind() != reflect.Func {
+		return zero, fmt.Errorf("core: constructor must be a function, got %s", ft)
+	}
+	if ft.NumOut() == 0 || ft.NumOut() > 2 || ft.NumOut() == 2 && ft.Out(1) != errorType {
+		return zero, fmt.Errorf("core: constructor %s must return a value or a value and an error", ft)
+	}
+	if !ft.Out(0).AssignableTo(typeOf[T]()) {
+		return zero, fmt.Errorf("core: constructor returns %s, not %s", ft.Out(0), typeOf[T]())
+	}
+
+	args := make([]reflect.Value, ft.NumIn())
+	for i := range args {
+		service, err := app.Registry.Resolve(ft.In(i))
+		if err != nil {
+			return zero, fmt.Errorf("core: constructor %s: %w", ft, err)
+		}
+		if service == nil {
+			args[i] = reflect.Zero(ft.In(i))
+		} else {
+			args[i] = reflect.ValueOf(service)
+		}
+	}
+
+	out := fn.Call(args)
+	if len(out) == 2 && !out[1].IsNil() {
+		return zero, out[1].Interface().(error)
+	}
+	var result T
+	reflect.ValueOf(&result).Elem().Set(out[0])
+	return result, nil
+}
+
+// MustConstruct is like Construct but panics on error
+func MustConstruct[T any](app *App, constructor interface{}) T {
+	result, err := Construct[T](app, constructor)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// registerServices provides the app's own services. They are transient so a field
+// set later, such as DB by ConnectDB, is picked up, and replacing the field replaces
+// the service.
+func (a *App) registerServices() {
+	provideField(a, func(a *App) *App { return a })
+	provideField(a, func(a *App) *Config { return a.Config })
+	provideField(a, func(a *App) *gorm.DB { return a.DB })
+	provideField(a, func(a *App) *logging.Logger { return a.Logger })
+	provideField(a, func(a *App) *cache.Cache { return a.Cache })
+	provideField(a, func(a *App) stream.Broker { return a.Stream })
+	provideField(a, func(a *App) *mail.Mailer { return a.Mailer })
+	provideField(a, func(a *App) *notification.Notifier { return a.Notifier })
+	provideField(a, func(a *App) *events.Bus { return a.Events })
+	provideField(a, func(a *App) search.Engine { return a.Search })
+	provideField(a, func(a *App) *jobs.DBQueue { return a.Queue })
+	provideField(a, func(a *App) jobs.Queue {
+		if a.Jobs == nil {
+			return nil
+		}
+		return a.Jobs
+	})
+}
+
+func provideField[T any](app *App, get func(a *App) T) {
+	Provide(app, func(a *App) (T, error) { return get(a), nil }, registry.Transient)
+}
@@ -0,0 +1,201 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvFile is the file of environment variables loaded next to settings.toml
+const EnvFile = ".env"
+
+// envAliases are environment variables read besides BOURBON_<SECTION>_<KEY>, which
+// takes precedence when both are set
+var envAliases = map[string]string{
+	"app.env":           "BOURBON_ENV",
+	"app.debug":         "DEBUG",
+	"app.secret_key":    "SECRET_KEY",
+	"database.host":     "DB_HOST",
+	"database.port":     "DB_PORT",
+	"database.name":     "DB_NAME",
+	"database.user":     "DB_USER",
+	"database.password": "DB_PASSWORD",
+}
+
+// LoadEnvFile sets the variables in a .env file that are not already set in the
+// environment, so real environment variables win. A missing file is not an error.
+//
+// Lines are KEY=value, optionally prefixed with "export". Values may be quoted:
+// double quotes understand \n, \t, \" and \\, single quotes are taken literally.
+// Lines starting with # and text after " #" in unquoted values are comments.
+func LoadEnvFile(path string) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		key, value, ok, err := parseEnvLine(scanner.Text())
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if !ok {
+			continue
+		}
+		if _, set := os.LookupEnv(key); !set {
+			os.Setenv(key, value)
+		}
+	}
+	return scanner.Err()
+}
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+func parseEnvLine(line string) (key, value string, ok bool, err error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false, nil
+	}
+	line = strings.TrimPrefix(line, "export ")
+
+	key, value, found := strings.Cut(line, "=")
+	key = strings.TrimSpace(key)
+	if !found || !envKeyPattern.MatchString(key) {
+		return "", "", false, fmt.Errorf("expected KEY=value, got %q", line)
+	}
+	value = strings.TrimSpace(value)
+
+	switch {
+	case strings.HasPrefix(value, `"`):
+		end := closingQuote(value)
+		if end < 0 {
+			return "", "", false, fmt.Errorf("unterminated quoted value for %s", key)
+		}
+		value = strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`).Replace(value[1:end])
+	case strings.HasPrefix(value, "'"):
+		end := strings.Index(value[1:], "'")
+		if end < 0 {
+			return "", "", false, fmt.Errorf("unterminated quoted value for %s", key)
+		}
+		value = value[1 : end+1]
+	default:
+		if i := strings.Index(value, " #"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+	}
+	return key, value, true, nil
+}
+
+// closingQuote returns the index of the double quote ending value, skipping escapes
+func closingQuote(value string) int {
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// envReference matches ${NAME} and ${NAME:-default}
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// expandSettings replaces ${NAME} references in the string values of the settings
+// file with environment variables, decrypts enc: values with the master key, and
+// merges the result into v. Unset variables without a default and values that
+// cannot be decrypted are reported as problems.
+func expandSettings(v *viper.Viper, path string) (configProblems, error) {
+	raw, err := readSettingsFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems configProblems
+	changed := false
+	var walk func(value interface{}, key string) interface{}
+	walk = func(value interface{}, key string) interface{} {
+		switch value := value.(type) {
+		case string:
+			if strings.Contains(value, "${") {
+				changed = true
+				value = envReference.ReplaceAllStringFunc(value, func(ref string) string {
+					match := envReference.FindStringSubmatch(ref)
+					if env, ok := os.LookupEnv(match[1]); ok {
+						return env
+					}
+					if match[2] == "" {
+						problems.add(key, "environment variable %s is not set (use ${%s:-default} for a fallback)", match[1], match[1])
+					}
+					return match[3]
+				})
+			}
+			if IsEncrypted(value) {
+				changed = true
+				plaintext, err := decryptSetting(value)
+				if err != nil {
+					problems.add(key, "%v", err)
+				}
+				return plaintext
+			}
+			return value
+		case map[string]interface{}:
+			for k, item := range value {
+				value[k] = walk(item, joinKey(key, k))
+			}
+		case []interface{}:
+			for i, item := range value {
+				value[i] = walk(item, fmt.Sprintf("%s[%d]", key, i))
+			}
+		case []map[string]interface{}:
+			for i, item := range value {
+				walk(item, fmt.Sprintf("%s[%d]", key, i))
+			}
+		}
+		return value
+	}
+	walk(raw, "")
+
+	if changed {
+		if err := v.MergeConfigMap(raw); err != nil {
+			return nil, err
+		}
+	}
+	return problems, nil
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// bindEnv makes every setting overridable with BOURBON_<SECTION>_<KEY>, plus the
+// aliases in envAliases
+func bindEnv(v *viper.Viper) {
+	v.SetEnvPrefix("BOURBON")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	for key, alias := range envAliases {
+		env := "BOURBON_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		_ = v.BindEnv(key, env, alias)
+	}
+}
+
+// envFileFor returns the .env file next to the settings file
+func envFileFor(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), EnvFile)
+}
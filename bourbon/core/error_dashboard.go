@@ -0,0 +1,225 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strconv"
+	"time"
+
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/middleware"
+)
+
+// ErrorDashboardPrefix is where MountErrorDashboard serves the error log UI
+const ErrorDashboardPrefix = "/_bourbon/errors"
+
+// errorDashboardPageSize is the number of error logs listed per page
+const errorDashboardPageSize = 50
+
+// MountErrorDashboard registers routes to browse, filter and purge stored error logs:
+//
+//	GET  /_bourbon/errors        list, filterable by status, path, from and to (YYYY-MM-DD)
+//	GET  /_bourbon/errors/{id}   detail with stack trace
+//	POST /_bourbon/errors/purge  delete entries older than "days"
+//
+// The purge form is checked for a CSRF token, whether or not security.csrf_enabled is set.
+// The dashboard exposes internal details, so protect it with middleware such as
+// BasicAuth or an IP filter. Outside debug mode, mounting it without middleware is refused.
+func (a *App) MountErrorDashboard(middleware ...bourbon.MiddlewareFunc) error {
+	if a.ErrorStore == nil {
+		return fmt.Errorf("error dashboard requires logging.store_errors_db = true")
+	}
+	if len(middleware) == 0 && (a.Config == nil || !a.Config.App.Debug) {
+		return fmt.Errorf("error dashboard must be protected by middleware outside debug mode")
+	}
+
+	group := a.Router.Group(ErrorDashboardPrefix, middleware...)
+	group.Use(dashboardCSRF())
+	group.Get("", a.errorDashboardList)
+	group.Post("/purge", a.errorDashboardPurge)
+	group.Get("/{id}", a.errorDashboardDetail)
+	return nil
+}
+
+func (a *App) errorDashboardList(c *bourbon.Context) error {
+	filter := logging.ErrorFilter{
+		Path:  c.Query("path"),
+		Limit: errorDashboardPageSize,
+	}
+	if status, err := strconv.Atoi(c.Query("status")); err == nil {
+		filter.Status = status
+	}
+	if from, err := time.Parse("2006-01-02", c.Query("from")); err == nil {
+		filter.From = from
+	}
+	if to, err := time.Parse("2006-01-02", c.Query("to")); err == nil {
+		filter.To = to.AddDate(0, 0, 1)
+	}
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	filter.Offset = (page - 1) * errorDashboardPageSize
+
+	logs, total, err := a.ErrorStore.Query(filter)
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	for _, key := range []string{"status", "path", "from", "to"} {
+		if v := c.Query(key); v != "" {
+			params.Set(key, v)
+		}
+	}
+	pageURL := func(p int) string {
+		q := url.Values{}
+		for k, v := range params {
+			q[k] = v
+		}
+		q.Set("page", strconv.Itoa(p))
+		return ErrorDashboardPrefix + "?" + q.Encode()
+	}
+
+	data := map[string]interface{}{
+		"Prefix":  ErrorDashboardPrefix,
+		"Logs":    logs,
+		"Total":   total,
+		"Page":    page,
+		"Status":  c.Query("status"),
+		"Path":    c.Query("path"),
+		"From":    c.Query("from"),
+		"To":      c.Query("to"),
+		"Purged":  c.Query("purged"),
+		"Enabled": a.ErrorStore.Enabled(),
+		"CSRF":    c.CSRFToken(),
+	}
+	if page > 1 {
+		data["PrevURL"] = pageURL(page - 1)
+	}
+	if int64(page*errorDashboardPageSize) < total {
+		data["NextURL"] = pageURL(page + 1)
+	}
+	return renderDashboard(c, errorDashboardTemplates, "list", data)
+}
+
+func (a *App) errorDashboardDetail(c *bourbon.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.String(404, "Not Found")
+	}
+	log, err := a.ErrorStore.Find(uint(id))
+	if err != nil {
+		return c.String(404, "Not Found")
+	}
+	return renderDashboard(c, errorDashboardTemplates, "detail", map[string]interface{}{
+		"Prefix": ErrorDashboardPrefix,
+		"Log":    log,
+	})
+}
+
+func (a *App) errorDashboardPurge(c *bourbon.Context) error {
+	days, err := strconv.Atoi(c.FormValue("days"))
+	if err != nil || days < 0 {
+		return c.String(400, "days must be a non-negative number")
+	}
+	if err := a.ErrorStore.Clean(time.Duration(days) * 24 * time.Hour); err != nil {
+		return err
+	}
+	return c.Redirect(303, ErrorDashboardPrefix+"?purged="+strconv.Itoa(days))
+}
+
+// dashboardCSRF checks the CSRF token of a dashboard's forms, which change state
+// and are otherwise open to any page in the developer's browser
+func dashboardCSRF() bourbon.MiddlewareFunc {
+	return bourbon.Adapt(middleware.CSRF(middleware.CSRFConfig{}))
+}
+
+func renderDashboard(c *bourbon.Context, templates *template.Template, name string, data interface{}) error {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return err
+	}
+	return c.HTML(200, buf.String())
+}
+
+var errorDashboardTemplates = template.Must(template.New("dashboard").Parse(`
+{{define "head"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Error log</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 0; color: #222; background: #fafafa; }
+header { background: #2d3748; color: #fff; padding: 14px 32px; }
+header a { color: #fff; text-decoration: none; font-weight: 600; }
+main { padding: 16px 32px; }
+form.filters input { padding: 4px 6px; margin-right: 6px; }
+table { border-collapse: collapse; width: 100%; font-size: 13px; background: #fff; }
+th, td { padding: 6px 8px; border-bottom: 1px solid #eee; text-align: left; vertical-align: top; }
+th { background: #f0f0f0; }
+.s5 { color: #b32d2e; font-weight: 600; }
+.s4 { color: #b7791f; font-weight: 600; }
+pre { background: #272822; color: #f8f8f2; padding: 12px; font-size: 12px; overflow-x: auto; }
+.notice { background: #fefcbf; padding: 8px 12px; margin-bottom: 12px; }
+.pager a { margin-right: 12px; }
+</style>
+</head>
+<body>
+<header><a href="{{.Prefix}}">Error log</a></header>
+<main>{{end}}
+
+{{define "foot"}}</main>
+</body>
+</html>{{end}}
+
+{{define "list"}}{{template "head" .}}
+{{if not .Enabled}}<p class="notice">Database error storage is disabled or the database is not connected.</p>{{end}}
+{{if .Purged}}<p class="notice">Purged entries older than {{.Purged}} days.</p>{{end}}
+<form class="filters" method="get" action="{{.Prefix}}">
+<input name="status" placeholder="Status" value="{{.Status}}" size="6">
+<input name="path" placeholder="Path contains" value="{{.Path}}">
+<input name="from" type="date" value="{{.From}}">
+<input name="to" type="date" value="{{.To}}">
+<button type="submit">Filter</button>
+</form>
+<p>{{.Total}} matching entries</p>
+<table>
+<tr><th>Time</th><th>Status</th><th>Level</th><th>Request</th><th>Message</th></tr>
+{{range .Logs}}
+<tr>
+<td><a href="{{$.Prefix}}/{{.ID}}">{{.Timestamp.Format "2006-01-02 15:04:05"}}</a></td>
+<td class="{{if ge .Status 500}}s5{{else if ge .Status 400}}s4{{end}}">{{.Status}}</td>
+<td>{{.Level}}</td>
+<td>{{.Method}} {{.Path}}</td>
+<td>{{.Message}}</td>
+</tr>
+{{end}}
+</table>
+<p class="pager">{{with .PrevURL}}<a href="{{.}}">&larr; Newer</a>{{end}}{{with .NextURL}}<a href="{{.}}">Older &rarr;</a>{{end}}</p>
+<form method="post" action="{{.Prefix}}/purge">
+<input type="hidden" name="csrf_token" value="{{.CSRF}}">
+Purge entries older than <input name="days" value="30" size="4"> days
+<button type="submit">Purge</button>
+</form>
+{{template "foot"}}{{end}}
+
+{{define "detail"}}{{template "head" .}}
+{{with .Log}}
+<h2>{{.Message}}</h2>
+<table>
+<tr><th>Time</th><td>{{.Timestamp.Format "2006-01-02 15:04:05 MST"}}</td></tr>
+<tr><th>Level</th><td>{{.Level}}</td></tr>
+<tr><th>Status</th><td>{{.Status}}</td></tr>
+<tr><th>Request</th><td>{{.Method}} {{.Path}}</td></tr>
+<tr><th>IP</th><td>{{.IP}}</td></tr>
+<tr><th>User agent</th><td>{{.UserAgent}}</td></tr>
+{{if .Extra}}<tr><th>Extra</th><td>{{.Extra}}</td></tr>{{end}}
+</table>
+{{if .Stack}}<h3>Stack trace</h3><pre>{{.Stack}}</pre>{{end}}
+{{end}}
+{{template "foot"}}{{end}}
+`))
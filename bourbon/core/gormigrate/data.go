@@ -0,0 +1,88 @@
+package gormigrate
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultBatchSize is the number of rows EachBatch loads at a time when given 0
+const DefaultBatchSize = 1000
+
+// progressInterval is how often Progress logs while a data migration runs
+const progressInterval = 2 * time.Second
+
+// EachBatch calls fn with the rows of T's table in primary key order, batchSize
+// rows at a time, and logs progress, for data migrations over tables too large to
+// load at once. fn may update the rows it gets through tx; returning an error
+// stops the iteration.
+func EachBatch[T any](tx *gorm.DB, batchSize int, fn func(tx *gorm.DB, rows []T) error) error {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(new(T)); err != nil {
+		return err
+	}
+
+	var total int64
+	if err := tx.Model(new(T)).Count(&total).Error; err != nil {
+		return err
+	}
+	progress := NewProgress(stmt.Table, total)
+
+	var rows []T
+	result := tx.Model(new(T)).FindInBatches(&rows, batchSize, func(_ *gorm.DB, _ int) error {
+		if err := fn(tx, rows); err != nil {
+			return err
+		}
+		progress.Add(len(rows))
+		return nil
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	progress.Done()
+	return nil
+}
+
+// Progress logs how far a data migration has got, at most every few seconds, for
+// loops EachBatch doesn't cover
+type Progress struct {
+	name   string
+	total  int64
+	done   int64
+	start  time.Time
+	logged time.Time
+}
+
+// NewProgress starts reporting progress over total rows; total may be 0 when unknown
+func NewProgress(name string, total int64) *Progress {
+	now := time.Now()
+	return &Progress{name: name, total: total, start: now, logged: now}
+}
+
+// Add records n more rows as processed
+func (p *Progress) Add(n int) {
+	p.done += int64(n)
+	if time.Since(p.logged) >= progressInterval {
+		p.log()
+	}
+}
+
+// Done logs the final count
+func (p *Progress) Done() {
+	p.log()
+}
+
+func (p *Progress) log() {
+	p.logged = time.Now()
+	elapsed := p.logged.Sub(p.start).Round(time.Millisecond)
+	if p.total > 0 {
+		log.Printf("%s: %d/%d rows (%d%%) in %s\n", p.name, p.done, p.total, p.done*100/p.total, elapsed)
+	} else {
+		log.Printf("%s: %d rows in %s\n", p.name, p.done, elapsed)
+	}
+}
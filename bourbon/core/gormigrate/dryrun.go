@@ -0,0 +1,115 @@
+package gormigrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// ErrDryRunReturning stops a dry run at a write whose returned rows the migration
+// needs, such as a Create with RETURNING, since it cannot be faked
+var ErrDryRunReturning = errors.New("dry run stopped at a statement that returns rows")
+
+// DryRun runs fn, usually a migration's Migrate or Rollback, and returns the
+// statements it would execute without executing them. Reads still go to the
+// database, so schema checks such as HasTable see the current schema; they do not
+// see changes made earlier in the same dry run.
+//
+// GORM's own DryRun session cannot be used here, because it also skips the reads
+// AutoMigrate and the Migrator rely on.
+func DryRun(db *gorm.DB, fn func(tx *gorm.DB) error) ([]string, error) {
+	pool := &dryRunPool{db: db, pool: db.Statement.ConnPool}
+	tx := db.Session(&gorm.Session{
+		DisableNestedTransaction: true,
+		Logger:                   db.Logger.LogMode(logger.Silent),
+	})
+	// Like Begin, swap the connection; looking like a transaction keeps the
+	// statements from being routed to another pool, and makes fn's own
+	// transactions run inline
+	tx.Statement.ConnPool = pool
+
+	err := fn(tx)
+	return pool.statements, err
+}
+
+// dryRunPool records writes and passes reads through
+type dryRunPool struct {
+	db         *gorm.DB
+	pool       gorm.ConnPool
+	statements []string
+}
+
+func (p *dryRunPool) record(query string, args []interface{}) {
+	p.statements = append(p.statements, p.db.Dialector.Explain(strings.TrimSpace(query), args...))
+}
+
+func (p *dryRunPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.pool.PrepareContext(ctx, query)
+}
+
+func (p *dryRunPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	p.record(query, args)
+	return driver.RowsAffected(0), nil
+}
+
+func (p *dryRunPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if !isRead(query) {
+		p.record(query, args)
+		return nil, ErrDryRunReturning
+	}
+	return p.pool.QueryContext(ctx, query, args...)
+}
+
+func (p *dryRunPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if isRead(query) {
+		return p.pool.QueryRowContext(ctx, query, args...)
+	}
+	p.record(query, args)
+	// A row that scans as sql.ErrNoRows, since *sql.Row cannot be built here
+	return p.pool.QueryRowContext(ctx, "SELECT 1 FROM (SELECT 1 AS x) t WHERE 1 = 0")
+}
+
+// Commit and Rollback end the transactions fn thinks it is in
+func (p *dryRunPool) Commit() error   { return nil }
+func (p *dryRunPool) Rollback() error { return nil }
+
+// isRead reports whether a query only reads
+func isRead(query string) bool {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "SELECT", "SHOW", "PRAGMA", "EXPLAIN", "DESCRIBE", "DESC", "VALUES":
+		return true
+	}
+	return false
+}
+
+var (
+	dropStatement  = regexp.MustCompile(`(?i)^\s*(DROP\s+(TABLE|SCHEMA|DATABASE)|TRUNCATE|DELETE)\b`)
+	alterDrop      = regexp.MustCompile(`(?i)^\s*ALTER\s+TABLE\s+.*?\bDROP\s+(\w+)`)
+	alterType      = regexp.MustCompile(`(?i)^\s*ALTER\s+TABLE\s+.*?\b(ALTER\s+COLUMN\s+\S+\s+(SET\s+DATA\s+)?TYPE|MODIFY|CHANGE)\b`)
+	keepsDataDrops = map[string]bool{
+		"CONSTRAINT": true, "INDEX": true, "KEY": true, "FOREIGN": true,
+		"PRIMARY": true, "CHECK": true, "DEFAULT": true, "NOT": true,
+	}
+)
+
+// IsDestructive reports whether a statement can lose data: dropping or emptying
+// tables, dropping columns, deleting rows or changing column types
+func IsDestructive(statement string) bool {
+	if dropStatement.MatchString(statement) || alterType.MatchString(statement) {
+		return true
+	}
+	if match := alterDrop.FindStringSubmatch(statement); match != nil {
+		return !keepsDataDrops[strings.ToUpper(match[1])]
+	}
+	return false
+}
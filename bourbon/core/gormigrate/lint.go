@@ -0,0 +1,172 @@
+package gormigrate
+
+import (
+	"regexp"
+	"strings"
+)
+
+// LintFinding is an operation that is unsafe while instances running the old and
+// the new code share the database, as they do during a rolling deploy
+type LintFinding struct {
+	Rule       string
+	Statement  string
+	Problem    string
+	Suggestion string
+}
+
+// lintAdvice explains each rule and the expand-contract alternative: expand the
+// schema so old and new code both work, deploy, then contract it in a later
+// migration once no instance uses the old shape
+var lintAdvice = map[string]struct{ problem, suggestion string }{
+	"drop-table": {
+		"Instances still running the old code query this table until they are replaced.",
+		"Remove the code using the table and deploy, then drop it in a later migration.",
+	},
+	"drop-column": {
+		"Instances still running the old code select and insert this column until they are replaced.",
+		"Remove the field (or tag it `gorm:\"-\"`) and deploy, then drop the column in a later migration.",
+	},
+	"rename": {
+		"Instances still running the old code use the old name.",
+		"Add the new table or column, write to both and backfill, switch reads over in the next deploy, then drop the old one.",
+	},
+	"not-null": {
+		"Inserts by the old code, which does not set the column, fail.",
+		"Give the column a DEFAULT, or make it NOT NULL in a later migration once every instance writes it.",
+	},
+	"type-change": {
+		"The table is rewritten under an exclusive lock, and the old code may write values the new type rejects.",
+		"Add a column of the new type, write to both and backfill it, switch reads over, then drop the old column.",
+	},
+	"table-copy": {
+		"MySQL copies the table for this ALTER and blocks writes until it finishes.",
+		"Add ALGORITHM=INPLACE, LOCK=NONE where MySQL supports it, or use an online schema change tool such as gh-ost.",
+	},
+	"blocking-index": {
+		"Building the index blocks writes to the table until it finishes.",
+		"Use CREATE INDEX CONCURRENTLY in a migration registered with core.NoTransaction() (or -- bourbon:no-transaction).",
+	},
+	"validating-constraint": {
+		"Adding the constraint checks every row while holding a lock that blocks writes.",
+		"Add it NOT VALID, then ALTER TABLE ... VALIDATE CONSTRAINT in a later migration.",
+	},
+}
+
+// ident matches a table or column name, quoted or not
+const ident = "[`\"\\[]?[\\w.]+[`\"\\]]?"
+
+var (
+	lintCreateTable = regexp.MustCompile(`(?i)^\s*CREATE\s+TABLE\s+(IF\s+NOT\s+EXISTS\s+)?(` + ident + `)`)
+	lintDropTable   = regexp.MustCompile(`(?i)^\s*DROP\s+TABLE\s+(IF\s+EXISTS\s+)?(` + ident + `)`)
+	lintAlterTable  = regexp.MustCompile(`(?i)^\s*ALTER\s+TABLE\s+(IF\s+EXISTS\s+)?(ONLY\s+)?(` + ident + `)`)
+	lintAddColumn   = regexp.MustCompile(`(?is)^\s*ALTER\s+TABLE\s+\S+\s+ADD\s+(COLUMN\s+)?(` + ident + `)\s+(.*)$`)
+	lintSetNotNull  = regexp.MustCompile(`(?i)\bALTER\s+COLUMN\s+\S+\s+SET\s+NOT\s+NULL\b`)
+	lintNotNull     = regexp.MustCompile(`(?i)\bNOT\s+NULL\b`)
+	lintDefault     = regexp.MustCompile(`(?i)\bDEFAULT\b`)
+	lintRename      = regexp.MustCompile(`(?i)^\s*ALTER\s+TABLE\s+\S+\s+(RENAME|CHANGE)\b`)
+	lintRenameTable = regexp.MustCompile(`(?i)^\s*RENAME\s+TABLE\s+(` + ident + `)`)
+	lintMySQLCopy   = regexp.MustCompile(`(?i)^\s*ALTER\s+TABLE\s+.*?\b(MODIFY|CHANGE|CONVERT\s+TO|(ADD|DROP)\s+PRIMARY\s+KEY)\b`)
+	lintOnline      = regexp.MustCompile(`(?i)\bALGORITHM\s*=\s*(INPLACE|INSTANT)\b`)
+	lintCreateIndex = regexp.MustCompile(`(?i)^\s*CREATE\s+(UNIQUE\s+)?INDEX\s+(CONCURRENTLY\s+)?.*?\bON\s+(ONLY\s+)?(` + ident + `)`)
+	lintConstraint  = regexp.MustCompile(`(?i)\bADD\s+CONSTRAINT\s+\S+\s+(FOREIGN\s+KEY|CHECK)\b`)
+	lintNotValid    = regexp.MustCompile(`(?i)\bNOT\s+VALID\b`)
+
+	// SQLite alters columns by copying the table to <table>__temp, dropping the
+	// original and renaming the copy, which is not a table drop or rename
+	lintSQLiteRebuild = regexp.MustCompile("(?i)^\\s*ALTER\\s+TABLE\\s+[`\"]?(\\w+)__temp[`\"]?\\s+RENAME\\s+TO\\b")
+)
+
+// Lint checks the statements of a migration, as returned by DryRun, for
+// operations unsafe during a rolling deploy on the given dialect (the name
+// returned by db.Dialector.Name()). Changes to tables created by the same
+// statements are not reported, since no running code uses them yet.
+func Lint(dialect string, statements []string) []LintFinding {
+	created := make(map[string]bool)
+	rebuilt := make(map[string]bool)
+	for _, statement := range statements {
+		if match := lintCreateTable.FindStringSubmatch(statement); match != nil {
+			created[tableName(match[2])] = true
+		}
+		if match := lintSQLiteRebuild.FindStringSubmatch(statement); match != nil {
+			rebuilt[strings.ToLower(match[1])] = true
+		}
+	}
+
+	var findings []LintFinding
+	add := func(rule, statement string) {
+		advice := lintAdvice[rule]
+		findings = append(findings, LintFinding{
+			Rule:       rule,
+			Statement:  statement,
+			Problem:    advice.problem,
+			Suggestion: advice.suggestion,
+		})
+	}
+	for _, statement := range statements {
+		if match := lintDropTable.FindStringSubmatch(statement); match != nil {
+			if table := tableName(match[2]); !created[table] && !rebuilt[table] {
+				add("drop-table", statement)
+			}
+			continue
+		}
+		if match := lintRenameTable.FindStringSubmatch(statement); match != nil {
+			if !created[tableName(match[1])] {
+				add("rename", statement)
+			}
+			continue
+		}
+		if match := lintCreateIndex.FindStringSubmatch(statement); match != nil {
+			if dialect == "postgres" && match[2] == "" && !created[tableName(match[4])] {
+				add("blocking-index", statement)
+			}
+			continue
+		}
+
+		match := lintAlterTable.FindStringSubmatch(statement)
+		if match == nil || created[tableName(match[3])] || lintSQLiteRebuild.MatchString(statement) {
+			continue
+		}
+		if drop := alterDrop.FindStringSubmatch(statement); drop != nil && !keepsDataDrops[strings.ToUpper(drop[1])] {
+			add("drop-column", statement)
+		}
+		if lintRename.MatchString(statement) {
+			add("rename", statement)
+		}
+		if column := lintAddColumn.FindStringSubmatch(statement); column != nil {
+			keyword := strings.ToUpper(column[2])
+			if !keepsDataDrops[keyword] && keyword != "UNIQUE" && lintNotNull.MatchString(column[3]) && !lintDefault.MatchString(column[3]) {
+				add("not-null", statement)
+			}
+		}
+		if lintSetNotNull.MatchString(statement) {
+			add("not-null", statement)
+		}
+		if alterType.MatchString(statement) && !lintRename.MatchString(statement) {
+			add("type-change", statement)
+		}
+		if dialect == "mysql" && lintMySQLCopy.MatchString(statement) && !lintOnline.MatchString(statement) {
+			add("table-copy", statement)
+		}
+		if dialect == "postgres" && lintConstraint.MatchString(statement) && !lintNotValid.MatchString(statement) {
+			add("validating-constraint", statement)
+		}
+	}
+	return findings
+}
+
+// tableName strips quotes and any schema from a table name
+func tableName(name string) string {
+	name = strings.Trim(name, "`\"[]")
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = strings.Trim(name[i+1:], "`\"[]")
+	}
+	return strings.ToLower(name)
+}
+
+// IgnoreLint stops migrate:lint reporting the given rules for a migration, e.g.
+// a column drop whose field was removed in an earlier deploy
+func IgnoreLint(rules ...string) MigrationOption {
+	return func(m *AppMigration) {
+		m.LintIgnore = append(m.LintIgnore, rules...)
+	}
+}
@@ -0,0 +1,60 @@
+package gormigrate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name       string
+		dialect    string
+		statements []string
+		rules      []string
+	}{
+		{"drop table", "postgres", []string{`DROP TABLE "posts"`}, []string{"drop-table"}},
+		{"drop column", "postgres", []string{`ALTER TABLE "posts" DROP COLUMN "body"`}, []string{"drop-column"}},
+		{"drop index", "postgres", []string{`ALTER TABLE "posts" DROP CONSTRAINT "fk_posts_user"`}, nil},
+		{"rename column", "postgres", []string{`ALTER TABLE "posts" RENAME COLUMN "body" TO "content"`}, []string{"rename"}},
+		{"rename table", "postgres", []string{`ALTER TABLE "articles" RENAME TO "posts"`}, []string{"rename"}},
+		{"mysql rename table", "mysql", []string{"RENAME TABLE `articles` TO `posts`"}, []string{"rename"}},
+		{"not null column", "postgres", []string{`ALTER TABLE "posts" ADD "rank" bigint NOT NULL`}, []string{"not-null"}},
+		{"not null with default", "postgres", []string{`ALTER TABLE "posts" ADD "rank" bigint NOT NULL DEFAULT 0`}, nil},
+		{"nullable column", "postgres", []string{`ALTER TABLE "posts" ADD "rank" bigint`}, nil},
+		{"set not null", "postgres", []string{`ALTER TABLE "posts" ALTER COLUMN "rank" SET NOT NULL`}, []string{"not-null"}},
+		{"type change", "postgres", []string{`ALTER TABLE "posts" ALTER COLUMN "views" TYPE bigint`}, []string{"type-change"}},
+		{"mysql modify", "mysql", []string{"ALTER TABLE `posts` MODIFY COLUMN `views` bigint"}, []string{"type-change", "table-copy"}},
+		{"mysql online", "mysql", []string{"ALTER TABLE `posts` MODIFY COLUMN `views` bigint, ALGORITHM=INPLACE, LOCK=NONE"}, []string{"type-change"}},
+		{"blocking index", "postgres", []string{`CREATE INDEX "idx_posts_slug" ON "posts" ("slug")`}, []string{"blocking-index"}},
+		{"concurrent index", "postgres", []string{`CREATE INDEX CONCURRENTLY "idx_posts_slug" ON "posts" ("slug")`}, nil},
+		{"sqlite index", "sqlite", []string{"CREATE INDEX `idx_posts_slug` ON `posts`(`slug`)"}, nil},
+		{"foreign key", "postgres", []string{`ALTER TABLE "posts" ADD CONSTRAINT "fk_posts_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")`}, []string{"validating-constraint"}},
+		{"foreign key not valid", "postgres", []string{`ALTER TABLE "posts" ADD CONSTRAINT "fk_posts_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") NOT VALID`}, nil},
+		{"new table", "postgres", []string{
+			`CREATE TABLE "tags" ("id" bigserial, "name" text, PRIMARY KEY ("id"))`,
+			`CREATE INDEX "idx_tags_name" ON "tags" ("name")`,
+			`ALTER TABLE "tags" ADD "slug" text NOT NULL`,
+		}, nil},
+		{"sqlite rebuild", "sqlite", []string{
+			"CREATE TABLE `posts__temp` (`id` integer,`views` integer NOT NULL,PRIMARY KEY (`id`))",
+			"INSERT INTO `posts__temp`(`id`,`views`) SELECT `id`,`views` FROM `posts`",
+			"DROP TABLE `posts`",
+			"ALTER TABLE `posts__temp` RENAME TO `posts`",
+		}, nil},
+		{"schema qualified", "postgres", []string{`DROP TABLE "public"."posts"`}, []string{"drop-table"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rules []string
+			for _, finding := range Lint(tt.dialect, tt.statements) {
+				if finding.Problem == "" || finding.Suggestion == "" {
+					t.Errorf("finding %s has no advice", finding.Rule)
+				}
+				rules = append(rules, finding.Rule)
+			}
+			if !reflect.DeepEqual(rules, tt.rules) {
+				t.Errorf("Lint = %v, want %v", rules, tt.rules)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package gormigrate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DependsOn makes the migration run after other migrations, usually another
+// app's migration creating a table its foreign keys reference. References are
+// "app:id", or just "id".
+func DependsOn(refs ...string) MigrationOption {
+	return func(m *AppMigration) {
+		m.DependsOn = append(m.DependsOn, refs...)
+	}
+}
+
+// OrderedMigrations returns the registered migrations in the order they run:
+// each after the ones it depends on and after the earlier migrations of its own
+// app, otherwise in registration order. Unknown references and cycles are errors.
+func OrderedMigrations() ([]*AppMigration, error) {
+	return orderMigrations(GetAppMigrations())
+}
+
+func orderMigrations(migrations []*AppMigration) ([]*AppMigration, error) {
+	index := make(map[string]int, len(migrations))
+	for i, m := range migrations {
+		index[m.ID] = i
+	}
+
+	// deps[i] lists the migrations that must run before migrations[i]
+	deps := make([][]int, len(migrations))
+	lastOfApp := make(map[string]int)
+	for i, m := range migrations {
+		if prev, ok := lastOfApp[m.AppName]; ok {
+			deps[i] = append(deps[i], prev)
+		}
+		lastOfApp[m.AppName] = i
+
+		for _, ref := range m.DependsOn {
+			appName, id, qualified := strings.Cut(ref, ":")
+			if !qualified {
+				id = appName
+			}
+			j, ok := index[id]
+			if !ok || (qualified && migrations[j].AppName != appName) {
+				return nil, fmt.Errorf("migration %s depends on unknown migration %s", m.ID, ref)
+			}
+			deps[i] = append(deps[i], j)
+		}
+	}
+
+	// Repeatedly take the earliest registered migration whose dependencies have
+	// run, so the order only changes where a dependency requires it
+	done := make([]bool, len(migrations))
+	ordered := make([]*AppMigration, 0, len(migrations))
+	for len(ordered) < len(migrations) {
+		next := -1
+		for i := range migrations {
+			if !done[i] && ready(deps[i], done) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			return nil, fmt.Errorf("migration dependency cycle (each depends on the next): %s", describeCycle(migrations, deps, done))
+		}
+		done[next] = true
+		ordered = append(ordered, migrations[next])
+	}
+	return ordered, nil
+}
+
+func ready(deps []int, done []bool) bool {
+	for _, dep := range deps {
+		if !done[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+// describeCycle follows unmet dependencies from the first blocked migration
+// until one repeats
+func describeCycle(migrations []*AppMigration, deps [][]int, done []bool) string {
+	start := 0
+	for done[start] {
+		start++
+	}
+	seen := make(map[int]int)
+	var path []int
+	for i := start; ; {
+		if at, ok := seen[i]; ok {
+			path = append(path[at:], i)
+			break
+		}
+		seen[i] = len(path)
+		path = append(path, i)
+		for _, dep := range deps[i] {
+			if !done[dep] {
+				i = dep
+				break
+			}
+		}
+	}
+
+	names := make([]string, len(path))
+	for k, i := range path {
+		names[k] = migrations[i].AppName + ":" + migrations[i].ID
+	}
+	return strings.Join(names, " -> ")
+}
@@ -4,12 +4,52 @@ import (
 	"sync"
 
 	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
 )
 
 // AppMigration wraps a gormigrate migration with app metadata
 type AppMigration struct {
 	*gormigrate.Migration
 	AppName string
+	// NoTransaction runs the migration outside a transaction, for statements
+	// databases refuse inside one, such as CREATE INDEX CONCURRENTLY
+	NoTransaction bool
+	// DependsOn lists migrations, as "app:id" or "id", that must run first
+	DependsOn []string
+	// LintIgnore lists migrate:lint rules not reported for the migration
+	LintIgnore []string
+}
+
+// MigrationOption configures a registered migration
+type MigrationOption func(*AppMigration)
+
+// NoTransaction runs the migration outside a transaction even on databases with
+// transactional DDL
+func NoTransaction() MigrationOption {
+	return func(m *AppMigration) {
+		m.NoTransaction = true
+	}
+}
+
+// Runnable returns the migration as the runner executes it: with Migrate and
+// Rollback inside a transaction when the database can roll back schema changes,
+// so a failing migration leaves nothing half-applied
+func (m *AppMigration) Runnable(transactionalDDL bool) *gormigrate.Migration {
+	if !transactionalDDL || m.NoTransaction {
+		return m.Migration
+	}
+	runnable := *m.Migration
+	if migrate := m.Migrate; migrate != nil {
+		runnable.Migrate = func(tx *gorm.DB) error {
+			return tx.Transaction(func(tx *gorm.DB) error { return migrate(tx) })
+		}
+	}
+	if rollback := m.Rollback; rollback != nil {
+		runnable.Rollback = func(tx *gorm.DB) error {
+			return tx.Transaction(func(tx *gorm.DB) error { return rollback(tx) })
+		}
+	}
+	return &runnable
 }
 
 // GormigrateMigrationRegistry holds all registered gormigrate migrations
@@ -35,13 +75,17 @@ func RegisterGormigrateMigration(migration *gormigrate.Migration) {
 }
 
 // RegisterAppMigration registers a migration with explicit app name
-func RegisterAppMigration(appName string, migration *gormigrate.Migration) {
-	gormigrateRegistry.mu.Lock()
-	defer gormigrateRegistry.mu.Unlock()
-	gormigrateRegistry.migrations = append(gormigrateRegistry.migrations, &AppMigration{
+func RegisterAppMigration(appName string, migration *gormigrate.Migration, opts ...MigrationOption) {
+	m := &AppMigration{
 		Migration: migration,
 		AppName:   appName,
-	})
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	gormigrateRegistry.mu.Lock()
+	defer gormigrateRegistry.mu.Unlock()
+	gormigrateRegistry.migrations = append(gormigrateRegistry.migrations, m)
 }
 
 // RegisterGormigrateMigrations registers multiple migrations at once
@@ -69,6 +113,21 @@ func GetGormigrateMigrations() []*gormigrate.Migration {
 	return result
 }
 
+// RunnableMigrations returns the registered migrations in execution order (see
+// OrderedMigrations), as the runner executes them (see AppMigration.Runnable)
+func RunnableMigrations(transactionalDDL bool) ([]*gormigrate.Migration, error) {
+	ordered, err := OrderedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*gormigrate.Migration, len(ordered))
+	for i, m := range ordered {
+		result[i] = m.Runnable(transactionalDDL)
+	}
+	return result, nil
+}
+
 // GetAppMigrations returns all registered migrations with app metadata
 func GetAppMigrations() []*AppMigration {
 	gormigrateRegistry.mu.RLock()
@@ -1,20 +1,34 @@
 package gormigrate
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/go-gormigrate/gormigrate/v2"
 	"github.com/ishubhamsingh2e/bourbon/bourbon/core/migration"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
 	"gorm.io/gorm"
 )
 
+// MigrationLock is the name of the lock held while migrations run
+const MigrationLock = "bourbon_migrations"
+
 // GormigrateRunner wraps gormigrate for managing migrations
 type GormigrateRunner struct {
 	db         *gorm.DB
 	migrator   *gormigrate.Gormigrate
 	migrations []*gormigrate.Migration
 	tracker    *migration.MigrationTracker
+
+	// LockTimeout is how long to wait while another process runs migrations
+	// (default 10 minutes)
+	LockTimeout time.Duration
+	// Unlocked runs migrations without the migration lock, for deploys that
+	// already run them from a single place. Without it, migrate fails when the
+	// lock cannot be taken.
+	Unlocked bool
 }
 
 // NewGormigrateRunner creates a new gormigrate-based migration runner
@@ -63,7 +77,7 @@ func (gr *GormigrateRunner) Migrate() error {
 	}
 
 	log.Println("Running migrations...")
-	if err := gr.migrator.Migrate(); err != nil {
+	if err := gr.locked(gr.migrator.Migrate); err != nil {
 		return fmt.Errorf("migration failed: %w", err)
 	}
 
@@ -80,7 +94,7 @@ func (gr *GormigrateRunner) RollbackLast() error {
 	}
 
 	log.Println("Rolling back last migration...")
-	if err := gr.migrator.RollbackLast(); err != nil {
+	if err := gr.locked(gr.migrator.RollbackLast); err != nil {
 		return fmt.Errorf("rollback failed: %w", err)
 	}
 
@@ -97,7 +111,51 @@ func (gr *GormigrateRunner) RollbackTo(migrationID string) error {
 	}
 
 	log.Printf("Rolling back to migration: %s...\n", migrationID)
-	if err := gr.migrator.RollbackTo(migrationID); err != nil {
+	if err := gr.locked(func() error { return gr.migrator.RollbackTo(migrationID) }); err != nil {
+		return fmt.Errorf("rollback failed: %w", err)
+	}
+
+	log.Println("Rollback completed successfully")
+	return nil
+}
+
+// RollbackMigrations rolls back applied migrations one at a time in the order
+// given, which callers choose (e.g. the last few of one app). Migrations another
+// process rolled back meanwhile are skipped.
+func (gr *GormigrateRunner) RollbackMigrations(ids []string) error {
+	if gr.migrator == nil {
+		if err := gr.Initialize(); err != nil {
+			return err
+		}
+	}
+
+	byID := make(map[string]*gormigrate.Migration, len(gr.migrations))
+	for _, m := range gr.migrations {
+		byID[m.ID] = m
+	}
+	for _, id := range ids {
+		if byID[id] == nil {
+			return fmt.Errorf("unknown migration: %s", id)
+		}
+	}
+
+	err := gr.locked(func() error {
+		for _, id := range ids {
+			var applied int64
+			if err := gr.db.Table("bourbon_migrations").Where("id = ?", id).Count(&applied).Error; err != nil {
+				return err
+			}
+			if applied == 0 {
+				continue
+			}
+			log.Printf("Rolling back migration: %s...\n", id)
+			if err := gr.migrator.RollbackMigration(byID[id]); err != nil {
+				return fmt.Errorf("%s: %w", id, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("rollback failed: %w", err)
 	}
 
@@ -114,7 +172,7 @@ func (gr *GormigrateRunner) MigrateTo(migrationID string) error {
 	}
 
 	log.Printf("Migrating to: %s...\n", migrationID)
-	if err := gr.migrator.MigrateTo(migrationID); err != nil {
+	if err := gr.locked(func() error { return gr.migrator.MigrateTo(migrationID) }); err != nil {
 		return fmt.Errorf("migration failed: %w", err)
 	}
 
@@ -122,6 +180,43 @@ func (gr *GormigrateRunner) MigrateTo(migrationID string) error {
 	return nil
 }
 
+// locked runs fn holding the migration lock, so instances deploying at the same
+// time migrate one after another; gormigrate skips what the first one applied
+func (gr *GormigrateRunner) locked(fn func() error) error {
+	if gr.Unlocked {
+		log.Println("Running migrations without a lock (database.migration_unlocked is set)")
+		return fn()
+	}
+
+	timeout := gr.LockTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Minute
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	lock, err := orm.NewLock(ctx, gr.db, MigrationLock)
+	if err != nil {
+		return fmt.Errorf("failed to prepare migration lock (set database.migration_unlocked to migrate without it): %w", err)
+	}
+
+	acquired, err := lock.TryLock(ctx)
+	if err == nil && !acquired {
+		log.Println("Waiting for another process to finish migrating...")
+		err = lock.Lock(ctx)
+	}
+	if err != nil {
+		lock.Unlock()
+		return err
+	}
+	defer func() {
+		if err := lock.Unlock(); err != nil {
+			log.Printf("Failed to release migration lock: %v\n", err)
+		}
+	}()
+	return fn()
+}
+
 // GetMigrations returns all registered migrations
 func (gr *GormigrateRunner) GetMigrations() []*gormigrate.Migration {
 	return gr.migrations
@@ -0,0 +1,339 @@
+package gormigrate
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// SQL migration files are named <id>.up.sql and <id>.down.sql, e.g.
+// 20240215120000_add_posts_index.up.sql. The down file is optional; without it
+// the migration cannot be rolled back.
+const (
+	sqlUpSuffix   = ".up.sql"
+	sqlDownSuffix = ".down.sql"
+
+	// noTransactionDirective in the first lines of a file runs it outside a
+	// transaction, for statements such as CREATE INDEX CONCURRENTLY
+	noTransactionDirective = "-- bourbon:no-transaction"
+
+	// dependsOnDirective, followed by "app:id" references, makes the migration
+	// run after them
+	dependsOnDirective = "-- bourbon:depends-on"
+
+	// lintIgnoreDirective, followed by rule names, stops migrate:lint reporting
+	// them for the migration
+	lintIgnoreDirective = "-- bourbon:lint-ignore"
+
+	// statementBegin and statementEnd keep the lines between them together as one
+	// statement, for procedures and triggers with semicolons in their body
+	statementBegin = "-- bourbon:statement-begin"
+	statementEnd   = "-- bourbon:statement-end"
+)
+
+// RegisterSQLMigrations registers the .sql migrations at the top of fsys for an
+// app, usually an embed.FS in the app's migrations package so the files ship
+// with the binary. Migrations with an ID already registered are skipped.
+func RegisterSQLMigrations(appName string, fsys fs.FS) error {
+	migrations, err := loadSQLMigrations(fsys)
+	if err != nil {
+		return err
+	}
+
+	gormigrateRegistry.mu.Lock()
+	defer gormigrateRegistry.mu.Unlock()
+	for _, m := range migrations {
+		m.AppName = appName
+		gormigrateRegistry.insertOrdered(m)
+	}
+	return nil
+}
+
+// RegisterSQLMigrationDirs registers the .sql migrations in apps/<app>/migrations
+// below root. Called before migrations run, so SQL files work without Go code.
+func RegisterSQLMigrationDirs(root string) error {
+	dirs, err := filepath.Glob(filepath.Join(root, "apps", "*", "migrations"))
+	if err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		appName := filepath.Base(filepath.Dir(dir))
+		if err := RegisterSQLMigrations(appName, os.DirFS(dir)); err != nil {
+			return fmt.Errorf("%s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// insertOrdered adds m before the first migration of the same app with a later
+// ID, so SQL and Go migrations of an app run in timestamp order. Go migrations
+// keep the order they registered in.
+func (r *GormigrateMigrationRegistry) insertOrdered(m *AppMigration) {
+	at := len(r.migrations)
+	for i, existing := range r.migrations {
+		if existing.ID == m.ID {
+			return
+		}
+		if at == len(r.migrations) && existing.AppName == m.AppName && existing.ID > m.ID {
+			at = i
+		}
+	}
+	r.migrations = append(r.migrations, nil)
+	copy(r.migrations[at+1:], r.migrations[at:])
+	r.migrations[at] = m
+}
+
+// LoadSQLMigrations reads the .sql migrations at the top of fsys, sorted by ID
+func LoadSQLMigrations(fsys fs.FS) ([]*gormigrate.Migration, error) {
+	migrations, err := loadSQLMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*gormigrate.Migration, len(migrations))
+	for i, m := range migrations {
+		result[i] = m.Migration
+	}
+	return result, nil
+}
+
+func loadSQLMigrations(fsys fs.FS) ([]*AppMigration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	downs := make(map[string]string)
+	var ids []string
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case entry.IsDir():
+		case strings.HasSuffix(name, sqlUpSuffix):
+			ids = append(ids, strings.TrimSuffix(name, sqlUpSuffix))
+		case strings.HasSuffix(name, sqlDownSuffix):
+			downs[strings.TrimSuffix(name, sqlDownSuffix)] = name
+		case path.Ext(name) == ".sql":
+			return nil, fmt.Errorf("%s: SQL migrations must end in %s or %s", name, sqlUpSuffix, sqlDownSuffix)
+		}
+	}
+	sort.Strings(ids)
+
+	migrations := make([]*AppMigration, 0, len(ids))
+	for _, id := range ids {
+		up, err := readSQLFile(fsys, id+sqlUpSuffix)
+		if err != nil {
+			return nil, err
+		}
+		m := &AppMigration{
+			Migration:     &gormigrate.Migration{ID: id, Migrate: up.run},
+			NoTransaction: up.noTransaction,
+			DependsOn:     up.dependsOn,
+			LintIgnore:    up.lintIgnore,
+		}
+		if name, ok := downs[id]; ok {
+			down, err := readSQLFile(fsys, name)
+			if err != nil {
+				return nil, err
+			}
+			m.Rollback = down.run
+			m.NoTransaction = m.NoTransaction || down.noTransaction
+			delete(downs, id)
+		}
+		migrations = append(migrations, m)
+	}
+	if len(downs) > 0 {
+		orphans := make([]string, 0, len(downs))
+		for _, name := range downs {
+			orphans = append(orphans, name)
+		}
+		sort.Strings(orphans)
+		return nil, fmt.Errorf("%s has no matching %s file", strings.Join(orphans, ", "), sqlUpSuffix)
+	}
+	return migrations, nil
+}
+
+// sqlFile is a parsed migration file
+type sqlFile struct {
+	name          string
+	statements    []string
+	noTransaction bool
+	dependsOn     []string
+	lintIgnore    []string
+}
+
+func readSQLFile(fsys fs.FS, name string) (*sqlFile, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	statements, err := splitSQL(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	file := &sqlFile{
+		name:          name,
+		statements:    statements,
+		noTransaction: strings.Contains(string(data), noTransactionDirective),
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if refs, ok := strings.CutPrefix(strings.TrimSpace(line), dependsOnDirective); ok {
+			file.dependsOn = append(file.dependsOn, strings.Fields(strings.ReplaceAll(refs, ",", " "))...)
+		}
+		if rules, ok := strings.CutPrefix(strings.TrimSpace(line), lintIgnoreDirective); ok {
+			file.lintIgnore = append(file.lintIgnore, strings.Fields(strings.ReplaceAll(rules, ",", " "))...)
+		}
+	}
+	return file, nil
+}
+
+// run executes the statements one at a time, since not every driver accepts
+// several statements per call. The runner wraps it in a transaction unless the
+// file opts out.
+func (f *sqlFile) run(tx *gorm.DB) error {
+	for i, statement := range f.statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return fmt.Errorf("%s: statement %d: %w", f.name, i+1, err)
+		}
+	}
+	return nil
+}
+
+// splitSQL splits a file into statements at semicolons outside quotes, comments,
+// Postgres dollar-quoted bodies and statement-begin/end blocks
+func splitSQL(sql string) ([]string, error) {
+	s := &sqlSplitter{}
+	inBlock := false
+	var block strings.Builder
+	for _, line := range strings.SplitAfter(sql, "\n") {
+		switch marker := strings.TrimSpace(line); {
+		case marker == statementBegin && !inBlock:
+			if !s.idle() {
+				return nil, fmt.Errorf("%s inside a statement", statementBegin)
+			}
+			s.flush()
+			inBlock = true
+		case marker == statementEnd && inBlock:
+			s.add(strings.TrimSuffix(strings.TrimSpace(block.String()), ";"))
+			block.Reset()
+			inBlock = false
+		case marker == statementEnd:
+			return nil, fmt.Errorf("%s without %s", statementEnd, statementBegin)
+		case inBlock:
+			block.WriteString(line)
+		default:
+			s.feed(line)
+		}
+	}
+
+	switch {
+	case inBlock:
+		return nil, fmt.Errorf("%s without %s", statementBegin, statementEnd)
+	case s.quote != 0:
+		return nil, fmt.Errorf("unterminated %c quote", s.quote)
+	case s.dollar != "":
+		return nil, fmt.Errorf("unterminated %s body", s.dollar)
+	case s.blockComment:
+		return nil, fmt.Errorf("unterminated /* comment")
+	}
+	s.flush()
+	return s.statements, nil
+}
+
+// sqlSplitter tracks quotes and comments across the lines of a file
+type sqlSplitter struct {
+	statements   []string
+	current      strings.Builder
+	quote        byte
+	dollar       string
+	blockComment bool
+}
+
+func (s *sqlSplitter) idle() bool {
+	return s.quote == 0 && s.dollar == "" && !s.blockComment
+}
+
+func (s *sqlSplitter) feed(line string) {
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case s.blockComment:
+			if strings.HasPrefix(line[i:], "*/") {
+				s.current.WriteString("*/")
+				i++
+				s.blockComment = false
+				continue
+			}
+		case s.quote != 0:
+			if c == '\\' && i+1 < len(line) {
+				s.current.WriteString(line[i : i+2])
+				i++
+				continue
+			}
+			if c == s.quote {
+				s.quote = 0
+			}
+		case s.dollar != "":
+			if strings.HasPrefix(line[i:], s.dollar) {
+				s.current.WriteString(s.dollar)
+				i += len(s.dollar) - 1
+				s.dollar = ""
+				continue
+			}
+		case strings.HasPrefix(line[i:], "--"):
+			s.current.WriteString(line[i:])
+			return
+		case strings.HasPrefix(line[i:], "/*"):
+			s.blockComment = true
+		case c == '\'' || c == '"' || c == '`':
+			s.quote = c
+		case c == '$':
+			if tag := dollarTag(line[i:]); tag != "" {
+				s.current.WriteString(tag)
+				i += len(tag) - 1
+				s.dollar = tag
+				continue
+			}
+		case c == ';':
+			s.flush()
+			continue
+		}
+		s.current.WriteByte(c)
+	}
+}
+
+func (s *sqlSplitter) flush() {
+	s.add(s.current.String())
+	s.current.Reset()
+}
+
+// add keeps statements that are more than comments
+func (s *sqlSplitter) add(statement string) {
+	statement = strings.TrimSpace(statement)
+	for _, line := range strings.Split(statement, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "--") {
+			s.statements = append(s.statements, statement)
+			return
+		}
+	}
+}
+
+// dollarTag returns the $tag$ or $$ opening a Postgres dollar-quoted string
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c == '$' {
+			return s[:i+1]
+		}
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 1 && c >= '0' && c <= '9') {
+			return ""
+		}
+	}
+	return ""
+}
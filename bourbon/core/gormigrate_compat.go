@@ -1,8 +1,11 @@
 package core
 
 import (
+	"io/fs"
+
 	"github.com/go-gormigrate/gormigrate/v2"
 	gormigratePackage "github.com/ishubhamsingh2e/bourbon/bourbon/core/gormigrate"
+	"gorm.io/gorm"
 )
 
 // Re-export gormigrate types and functions for backward compatibility
@@ -17,10 +20,28 @@ func RegisterGormigrateMigration(migration *gormigrate.Migration) {
 	gormigratePackage.RegisterGormigrateMigration(migration)
 }
 
+// MigrationOption is re-exported from gormigrate package
+type MigrationOption = gormigratePackage.MigrationOption
+
 // RegisterAppMigration registers a migration with explicit app name
 // This function is re-exported for backward compatibility
-func RegisterAppMigration(appName string, migration *gormigrate.Migration) {
-	gormigratePackage.RegisterAppMigration(appName, migration)
+func RegisterAppMigration(appName string, migration *gormigrate.Migration, opts ...MigrationOption) {
+	gormigratePackage.RegisterAppMigration(appName, migration, opts...)
+}
+
+// DependsOn makes a migration run after others, given as "app:id" or "id"
+func DependsOn(refs ...string) MigrationOption {
+	return gormigratePackage.DependsOn(refs...)
+}
+
+// NoTransaction runs a migration outside a transaction, e.g. for CREATE INDEX CONCURRENTLY
+func NoTransaction() MigrationOption {
+	return gormigratePackage.NoTransaction()
+}
+
+// IgnoreLint stops migrate:lint reporting the given rules for a migration
+func IgnoreLint(rules ...string) MigrationOption {
+	return gormigratePackage.IgnoreLint(rules...)
 }
 
 // RegisterGormigrateMigrations registers multiple migrations at once
@@ -29,6 +50,25 @@ func RegisterGormigrateMigrations(migrations []*gormigrate.Migration) {
 	gormigratePackage.RegisterGormigrateMigrations(migrations)
 }
 
+// RegisterSQLMigrations registers the .up.sql/.down.sql migrations in fsys for an app
+func RegisterSQLMigrations(appName string, fsys fs.FS) error {
+	return gormigratePackage.RegisterSQLMigrations(appName, fsys)
+}
+
+// EachBatch calls fn with the rows of T's table in primary key order, batchSize
+// rows at a time, logging progress; for data migrations
+func EachBatch[T any](tx *gorm.DB, batchSize int, fn func(tx *gorm.DB, rows []T) error) error {
+	return gormigratePackage.EachBatch(tx, batchSize, fn)
+}
+
+// Progress is re-exported from gormigrate package
+type Progress = gormigratePackage.Progress
+
+// NewProgress starts logging progress over total rows of a data migration
+func NewProgress(name string, total int64) *Progress {
+	return gormigratePackage.NewProgress(name, total)
+}
+
 // GetGormigrateMigrations returns all registered migrations
 // This function is re-exported for backward compatibility
 func GetGormigrateMigrations() []*gormigrate.Migration {
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/metrics"
+)
+
+// Health and metrics endpoint paths
+const (
+	HealthPath  = "/healthz"
+	ReadyPath   = "/readyz"
+	MetricsPath = "/metrics"
+)
+
+// ReadinessCheck reports whether a dependency can serve traffic
+type ReadinessCheck func(ctx context.Context) error
+
+// AddReadinessCheck adds a check to /readyz; the database check is built in
+func (a *App) AddReadinessCheck(name string, check ReadinessCheck) {
+	a.readinessMu.Lock()
+	defer a.readinessMu.Unlock()
+	if a.readinessChecks == nil {
+		a.readinessChecks = make(map[string]ReadinessCheck)
+	}
+	a.readinessChecks[name] = check
+}
+
+// MountHealth registers the liveness and readiness endpoints:
+//
+//	GET /healthz  200 while the process is serving, with the build version
+//	GET /readyz   200 when the database and readiness checks pass, 503 otherwise
+func (a *App) MountHealth() {
+	a.Router.Get(HealthPath, func(c *bourbon.Context) error {
+		return c.JSON(http.StatusOK, bourbon.H{"status": "ok", "version": Version})
+	})
+	a.Router.Get(ReadyPath, a.readyHandler)
+}
+
+// MountMetrics serves the metrics registry in the Prometheus text format at /metrics
+func (a *App) MountMetrics(middleware ...bourbon.MiddlewareFunc) {
+	handler := metrics.Default.Handler()
+	group := a.Router.Group(MetricsPath, middleware...)
+	group.Get("", func(c *bourbon.Context) error {
+		handler.ServeHTTP(c.Writer, c.Request)
+		return nil
+	})
+}
+
+func (a *App) readyHandler(c *bourbon.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	ready := true
+	checks := bourbon.H{}
+
+	if a.DBHealth != nil {
+		if a.DBHealth.Status().LastCheck.IsZero() {
+			// The monitor is not running; check now
+			_ = a.DBHealth.Check(ctx)
+		}
+		status := a.DBHealth.Status()
+		if !status.Healthy {
+			ready = false
+		}
+		checks["database"] = status
+	}
+
+	a.readinessMu.RLock()
+	names := make([]string, 0, len(a.readinessChecks))
+	for name := range a.readinessChecks {
+		names = append(names, name)
+	}
+	a.readinessMu.RUnlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		a.readinessMu.RLock()
+		check := a.readinessChecks[name]
+		a.readinessMu.RUnlock()
+
+		if err := check(ctx); err != nil {
+			ready = false
+			checks[name] = bourbon.H{"healthy": false, "error": err.Error()}
+		} else {
+			checks[name] = bourbon.H{"healthy": true}
+		}
+	}
+
+	status := http.StatusOK
+	state := "ready"
+	if !ready {
+		status = http.StatusServiceUnavailable
+		state = "unavailable"
+	}
+	return c.JSON(status, bourbon.H{"status": state, "checks": checks})
+}
+
+// registerDBMetrics exposes the connection pool in the default metrics registry
+func (a *App) registerDBMetrics(monitor *orm.HealthMonitor) {
+	reg := metrics.Default
+	reg.GaugeFunc("bourbon_db_up", "Whether the last database ping succeeded", func() float64 {
+		if monitor.Healthy() {
+			return 1
+		}
+		return 0
+	})
+	reg.GaugeFunc("bourbon_db_max_open_connections", "Maximum open connections to the database", func() float64 {
+		return float64(monitor.Stats().MaxOpen)
+	})
+	reg.GaugeFunc("bourbon_db_open_connections", "Established connections, in use and idle", func() float64 {
+		return float64(monitor.Stats().Open)
+	})
+	reg.GaugeFunc("bourbon_db_in_use_connections", "Connections currently in use", func() float64 {
+		return float64(monitor.Stats().InUse)
+	})
+	reg.GaugeFunc("bourbon_db_idle_connections", "Idle connections", func() float64 {
+		return float64(monitor.Stats().Idle)
+	})
+	reg.CounterFunc("bourbon_db_wait_count_total", "Connections waited for because the pool was exhausted", func() float64 {
+		return float64(monitor.Stats().WaitCount)
+	})
+	reg.CounterFunc("bourbon_db_wait_seconds_total", "Time spent waiting for a connection", func() float64 {
+		return monitor.Stats().WaitDuration.Seconds()
+	})
+}
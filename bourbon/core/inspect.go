@@ -0,0 +1,186 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+)
+
+// InspectPath is where MountInspect serves app.Inspect as JSON
+const InspectPath = "/_bourbon/routes"
+
+// Inspection describes how the app serves requests, for finding out why a
+// request 404s or skips a middleware
+type Inspection struct {
+	// Middleware is the global stack, outermost first
+	Middleware []InspectedMiddleware `json:"middleware"`
+	// RouterMiddleware was added with Router.Use; it runs inside the global
+	// stack on every route
+	RouterMiddleware []string `json:"router_middleware"`
+	// Groups are the route groups with routes, in the order of their first route
+	Groups []InspectedGroup `json:"groups"`
+	Routes []InspectedRoute `json:"routes"`
+	// Static are the URL prefixes served from files, which win over routes
+	Static []string `json:"static"`
+	// Templates are the names ctx.Render accepts
+	Templates []string `json:"templates"`
+}
+
+// InspectedMiddleware is a middleware of the global stack
+type InspectedMiddleware struct {
+	Name     string `json:"name"`
+	Priority int    `json:"priority"`
+	// Conditional is set for middleware registered with Only, Except, OnlyPaths
+	// or When, which skips some requests
+	Conditional bool `json:"conditional,omitempty"`
+}
+
+// InspectedGroup is a route group
+type InspectedGroup struct {
+	Prefix     string   `json:"prefix"`
+	Middleware []string `json:"middleware"`
+	Routes     int      `json:"routes"`
+}
+
+// InspectedRoute is a registered route
+type InspectedRoute struct {
+	Method  string `json:"method"`
+	Pattern string `json:"pattern"`
+	Handler string `json:"handler"`
+	Group   string `json:"group,omitempty"`
+	// Middleware is every middleware a request to the route runs through,
+	// outermost first: the global ones that apply to it, the router's, then the
+	// group's
+	Middleware []string `json:"middleware"`
+}
+
+// routeWildcard matches the wildcards of a route pattern
+var routeWildcard = regexp.MustCompile(`\{[^}]*\}`)
+
+// Inspect returns the registered routes with their groups and middleware, the
+// global middleware in the order it runs, and the template names
+func (a *App) Inspect() Inspection {
+	a.middlewareMu.RLock()
+	stack := a.orderedMiddlewares()
+	a.middlewareMu.RUnlock()
+
+	inspection := Inspection{
+		Middleware:       make([]InspectedMiddleware, 0, len(stack)),
+		RouterMiddleware: a.Router.MiddlewareNames(),
+		Groups:           []InspectedGroup{},
+		Routes:           []InspectedRoute{},
+		Static:           a.Router.StaticPrefixes(),
+		Templates:        bourbon.TemplateNames(a.Router.TemplateEngine),
+	}
+	for _, entry := range stack {
+		inspection.Middleware = append(inspection.Middleware, InspectedMiddleware{
+			Name:        stackEntryName(entry),
+			Priority:    entry.priority,
+			Conditional: entry.entry != nil && entry.entry.Conditional(),
+		})
+	}
+
+	groups := make(map[string]int)
+	for _, route := range a.Router.GetRoutes() {
+		// Conditions are checked against the pattern, with its wildcards filled in
+		sample := httptest.NewRequest(route.Method, "/", nil)
+		if strings.HasPrefix(route.Pattern, "/") {
+			sample.URL.Path = routeWildcard.ReplaceAllStringFunc(route.Pattern, func(wildcard string) string {
+				if wildcard == "{$}" {
+					return ""
+				}
+				return "x"
+			})
+		}
+
+		inspection.Routes = append(inspection.Routes, InspectedRoute{
+			Method:     route.Method,
+			Pattern:    route.Pattern,
+			Handler:    route.HandlerName,
+			Group:      route.Group,
+			Middleware: a.routeMiddleware(stack, sample, route),
+		})
+
+		if route.Group == "" {
+			continue
+		}
+		key := route.Group + "\x00" + strings.Join(route.Middleware, "\x00")
+		i, ok := groups[key]
+		if !ok {
+			i = len(inspection.Groups)
+			groups[key] = i
+			inspection.Groups = append(inspection.Groups, InspectedGroup{
+				Prefix:     route.Group,
+				Middleware: append([]string{}, route.Middleware...),
+			})
+		}
+		inspection.Groups[i].Routes++
+	}
+	if inspection.Templates == nil {
+		inspection.Templates = []string{}
+	}
+	return inspection
+}
+
+// routeMiddleware names the middleware r runs through on its way to route,
+// outermost first: the global ones that apply to it, the router's, then the group's
+func (a *App) routeMiddleware(stack []stackEntry, r *http.Request, route bourbon.Route) []string {
+	chain := []string{}
+	for _, entry := range stack {
+		if entry.entry == nil || entry.entry.Applies(r) {
+			chain = append(chain, stackEntryName(entry))
+		}
+	}
+	chain = append(chain, a.Router.MiddlewareNames()...)
+	return append(chain, route.Middleware...)
+}
+
+// requestMiddleware names the middleware a request to route ran through, for the debug toolbar
+func (a *App) requestMiddleware(r *http.Request, route bourbon.Route) []string {
+	a.middlewareMu.RLock()
+	stack := a.orderedMiddlewares()
+	a.middlewareMu.RUnlock()
+	return a.routeMiddleware(stack, r, route)
+}
+
+// stackEntryName returns the name a middleware was enabled under, or its
+// function's name for one added with Use
+func stackEntryName(entry stackEntry) string {
+	if entry.name != "" {
+		return entry.name
+	}
+	return bourbon.FuncName(entry.middleware)
+}
+
+// MountInspect serves app.Inspect as JSON at /_bourbon/routes. The routes
+// registered later are included, as it inspects the app on each request.
+//
+// Like the log level endpoint, it must be protected by middleware outside debug
+// mode. In debug mode the server mounts it at startup.
+func (a *App) MountInspect(middleware ...bourbon.MiddlewareFunc) error {
+	if len(middleware) == 0 && (a.Config == nil || !a.Config.App.Debug) {
+		return fmt.Errorf("route inspection endpoint must be protected by middleware outside debug mode")
+	}
+	for _, route := range a.Router.GetRoutes() {
+		if route.Method == http.MethodGet && route.Pattern == InspectPath {
+			return nil
+		}
+	}
+
+	group := a.Router.Group(InspectPath, middleware...)
+	group.Get("", func(c *bourbon.Context) error {
+		return c.JSON(http.StatusOK, a.Inspect())
+	}).Hidden()
+	return nil
+}
+
+// initInspect mounts the route inspection endpoint in debug mode. It runs at startup.
+func (a *App) initInspect() {
+	if a.Config.App.Debug {
+		_ = a.MountInspect()
+	}
+}
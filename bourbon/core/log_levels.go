@@ -0,0 +1,51 @@
+package core
+
+import (
+	"fmt"
+
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+)
+
+// LogLevelsPath is where MountLogLevels serves the runtime log level endpoint
+const LogLevelsPath = "/_bourbon/loglevel"
+
+// logLevelRequest is the body accepted by PUT /_bourbon/loglevel
+type logLevelRequest struct {
+	Module string `json:"module"` // empty changes the global level
+	Level  string `json:"level"`  // empty removes a module override
+}
+
+// MountLogLevels registers an endpoint to inspect and change log levels without restarting:
+//
+//	GET /_bourbon/loglevel                                      current levels
+//	PUT /_bourbon/loglevel  {"module": "database", "level": "debug"}
+//
+// Like the error dashboard, it must be protected by middleware outside debug mode.
+func (a *App) MountLogLevels(middleware ...bourbon.MiddlewareFunc) error {
+	if len(middleware) == 0 && (a.Config == nil || !a.Config.App.Debug) {
+		return fmt.Errorf("log level endpoint must be protected by middleware outside debug mode")
+	}
+
+	group := a.Router.Group(LogLevelsPath, middleware...)
+	group.Get("", func(c *bourbon.Context) error {
+		return c.JSON(200, a.Logger.Levels())
+	})
+	group.Put("", func(c *bourbon.Context) error {
+		var req logLevelRequest
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(400, bourbon.H{"error": "invalid JSON body"})
+		}
+
+		var err error
+		if req.Module == "" || req.Module == "default" {
+			err = a.Logger.SetLevel(req.Level)
+		} else {
+			err = a.Logger.SetModuleLevel(req.Module, req.Level)
+		}
+		if err != nil {
+			return c.JSON(400, bourbon.H{"error": err.Error()})
+		}
+		return c.JSON(200, a.Logger.Levels())
+	})
+	return nil
+}
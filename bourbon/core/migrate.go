@@ -1,12 +1,15 @@
 package core
 
 import (
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"math"
+	"sort"
 	"strings"
 
 	"github.com/ishubhamsingh2e/bourbon/bourbon/core/gormigrate"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+	"gorm.io/gorm"
 )
 
 // RunMigrations executes all pending migrations from registered apps
@@ -26,20 +29,17 @@ func RunMigrations(app *Application) error {
 	}
 
 	// Get registered migrations
-	appMigrations := gormigrate.GetAppMigrations()
+	appMigrations, err := gormigrate.OrderedMigrations()
+	if err != nil {
+		return err
+	}
 	if len(appMigrations) == 0 {
 		fmt.Println("WARNING: No migrations found!")
 		return nil
 	}
 
 	// Check which migrations are already applied (from gormigrate's table)
-	var appliedIDs []string
-	app.DB.Table("bourbon_migrations").Pluck("id", &appliedIDs)
-
-	appliedMap := make(map[string]bool)
-	for _, id := range appliedIDs {
-		appliedMap[id] = true
-	}
+	appliedMap := appliedMigrations(orm.Primary(app.DB))
 
 	// Count pending migrations
 	pendingCount := 0
@@ -77,8 +77,15 @@ func ShowMigrationStatus(app *Application) error {
 		return fmt.Errorf("database not initialized - call ConnectDB() first")
 	}
 
+	if err := gormigrate.RegisterSQLMigrationDirs("."); err != nil {
+		return fmt.Errorf("failed to load SQL migrations: %w", err)
+	}
+
 	// Get registered migrations grouped by app
-	appMigrations := gormigrate.GetAppMigrations()
+	appMigrations, err := gormigrate.OrderedMigrations()
+	if err != nil {
+		return err
+	}
 	if len(appMigrations) == 0 {
 		fmt.Println("WARNING: No migrations found!")
 		return nil
@@ -140,8 +147,10 @@ func ShowMigrationStatus(app *Application) error {
 	return nil
 }
 
-// RollbackLastMigration rolls back the last applied migration
-func RollbackLastMigration(app *Application) error {
+// ShowMigrationSQL prints the statements the pending migrations would execute,
+// without executing them. With rollback set it prints the statements
+// migrate:rollback would execute for the last applied migration instead.
+func ShowMigrationSQL(app *Application, rollback bool) error {
 	if app == nil {
 		return fmt.Errorf("application is nil")
 	}
@@ -150,20 +159,222 @@ func RollbackLastMigration(app *Application) error {
 		return fmt.Errorf("database not initialized - call ConnectDB() first")
 	}
 
-	if err := app.InitMigrations(); err != nil {
-		return fmt.Errorf("failed to initialize migrations: %w", err)
+	if err := gormigrate.RegisterSQLMigrationDirs("."); err != nil {
+		return fmt.Errorf("failed to load SQL migrations: %w", err)
 	}
 
-	if err := app.RollbackLast(); err != nil {
-		return fmt.Errorf("rollback failed: %w", err)
+	db := orm.Primary(app.DB)
+	appliedMap := appliedMigrations(db)
+
+	appMigrations, err := gormigrate.OrderedMigrations()
+	if err != nil {
+		return err
 	}
 
-	fmt.Println("Rollback completed successfully")
+	var selected []*gormigrate.AppMigration
+	if rollback {
+		for i := len(appMigrations) - 1; i >= 0; i-- {
+			if appliedMap[appMigrations[i].ID] {
+				selected = append(selected, appMigrations[i])
+				break
+			}
+		}
+	} else {
+		for _, m := range appMigrations {
+			if !appliedMap[m.ID] {
+				selected = append(selected, m)
+			}
+		}
+	}
+
+	if len(selected) == 0 {
+		if rollback {
+			fmt.Println("-- No applied migrations to roll back")
+		} else {
+			fmt.Println("-- All migrations already applied")
+		}
+		return nil
+	}
+
+	for _, m := range selected {
+		var fn func(*gorm.DB) error
+		switch {
+		case !rollback:
+			fn = m.Migrate
+		case m.Rollback != nil:
+			fn = m.Rollback
+		}
+		fmt.Printf("-- %s (app: %s)\n", m.ID, m.AppName)
+		if fn == nil {
+			fmt.Println("-- (no rollback defined; migrate:rollback will fail)")
+			fmt.Println()
+			continue
+		}
+
+		statements, err := gormigrate.DryRun(db, fn)
+		for _, statement := range statements {
+			fmt.Printf("%s;\n", statement)
+		}
+		if len(statements) == 0 && err == nil {
+			fmt.Println("-- (no statements)")
+		}
+		if err != nil {
+			if errors.Is(err, gormigrate.ErrDryRunReturning) {
+				fmt.Println("-- (stopped: the migration needs the rows returned by the statement above)")
+			} else {
+				return fmt.Errorf("%s: %w", m.ID, err)
+			}
+		}
+		fmt.Println()
+	}
 	return nil
 }
 
-// MigrateToVersion migrates to a specific migration ID
-func MigrateToVersion(app *Application, migrationID string) error {
+// MigrationInfo is a registered migration and whether it is applied
+type MigrationInfo struct {
+	ID         string `json:"id"`
+	App        string `json:"app"`
+	Applied    bool   `json:"applied"`
+	Reversible bool   `json:"reversible"`
+}
+
+// MigrationStatus returns the registered migrations in the order migrate runs
+// them, e.g. for tools reading migrate:status --json
+func MigrationStatus(app *Application) ([]MigrationInfo, error) {
+	if app == nil {
+		return nil, fmt.Errorf("application is nil")
+	}
+
+	if app.DB == nil {
+		return nil, fmt.Errorf("database not initialized - call ConnectDB() first")
+	}
+
+	if err := gormigrate.RegisterSQLMigrationDirs("."); err != nil {
+		return nil, fmt.Errorf("failed to load SQL migrations: %w", err)
+	}
+
+	appliedMap := appliedMigrations(orm.Primary(app.DB))
+	appMigrations, err := gormigrate.OrderedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]MigrationInfo, len(appMigrations))
+	for i, m := range appMigrations {
+		migrations[i] = MigrationInfo{ID: m.ID, App: m.AppName, Applied: appliedMap[m.ID], Reversible: m.Rollback != nil}
+	}
+	return migrations, nil
+}
+
+// PlannedMigration is a pending migration with what it would do
+type PlannedMigration struct {
+	ID          string   `json:"id"`
+	App         string   `json:"app"`
+	Destructive []string `json:"destructive,omitempty"` // first line of each destructive statement
+	Reversible  bool     `json:"reversible"`
+	Incomplete  string   `json:"incomplete,omitempty"` // why the dry run stopped early
+}
+
+// MigrationPlan returns the pending migrations in the order migrate runs them,
+// with the destructive statements each would execute
+func MigrationPlan(app *Application) ([]PlannedMigration, error) {
+	if app == nil {
+		return nil, fmt.Errorf("application is nil")
+	}
+
+	if app.DB == nil {
+		return nil, fmt.Errorf("database not initialized - call ConnectDB() first")
+	}
+
+	if err := gormigrate.RegisterSQLMigrationDirs("."); err != nil {
+		return nil, fmt.Errorf("failed to load SQL migrations: %w", err)
+	}
+
+	db := orm.Primary(app.DB)
+	appliedMap := appliedMigrations(db)
+
+	appMigrations, err := gormigrate.OrderedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var plan []PlannedMigration
+	for _, m := range appMigrations {
+		if appliedMap[m.ID] {
+			continue
+		}
+		planned := PlannedMigration{ID: m.ID, App: m.AppName, Reversible: m.Rollback != nil}
+		statements, err := gormigrate.DryRun(db, m.Migrate)
+		for _, statement := range statements {
+			if gormigrate.IsDestructive(statement) {
+				planned.Destructive = append(planned.Destructive, firstLine(statement))
+			}
+		}
+		if err != nil {
+			planned.Incomplete = err.Error()
+		}
+		plan = append(plan, planned)
+	}
+	return plan, nil
+}
+
+// ShowMigrationPlan lists the pending migrations in the order migrate runs them,
+// with the destructive statements each would execute. With check set it returns
+// an error when migrations are pending, so CI can fail on unapplied migrations.
+func ShowMigrationPlan(app *Application, check bool) error {
+	pending, err := MigrationPlan(app)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nMigration Plan\n")
+	fmt.Printf("════════════════════════════════════════════\n")
+	if len(pending) == 0 {
+		fmt.Println("No pending migrations")
+		return nil
+	}
+
+	perApp := make(map[string]int)
+	destructive := 0
+	for i, m := range pending {
+		perApp[m.App]++
+		fmt.Printf("  %2d. [%s] %s\n", i+1, m.App, m.ID)
+		for _, statement := range m.Destructive {
+			destructive++
+			fmt.Printf("      DESTRUCTIVE: %s\n", statement)
+		}
+		if m.Incomplete != "" {
+			fmt.Printf("      (plan incomplete: %s)\n", m.Incomplete)
+		}
+		if !m.Reversible {
+			fmt.Println("      IRREVERSIBLE: no rollback defined")
+		}
+	}
+
+	apps := make([]string, 0, len(perApp))
+	for appName := range perApp {
+		apps = append(apps, appName)
+	}
+	sort.Strings(apps)
+
+	fmt.Println("────────────────────────────────────────────")
+	fmt.Printf("Pending: %d", len(pending))
+	for _, appName := range apps {
+		fmt.Printf(", %s: %d", appName, perApp[appName])
+	}
+	fmt.Printf("\nDestructive statements: %d\n", destructive)
+
+	if check {
+		return fmt.Errorf("%d unapplied migration(s)", len(pending))
+	}
+	return nil
+}
+
+// LintMigrations reports operations in the pending migrations that are unsafe
+// during a rolling deploy, when old and new code share the database, with the
+// expand-contract alternative. With check set it returns an error when any are
+// found, so CI can block them.
+func LintMigrations(app *Application, check bool) error {
 	if app == nil {
 		return fmt.Errorf("application is nil")
 	}
@@ -172,21 +383,86 @@ func MigrateToVersion(app *Application, migrationID string) error {
 		return fmt.Errorf("database not initialized - call ConnectDB() first")
 	}
 
-	if err := app.InitMigrations(); err != nil {
-		return fmt.Errorf("failed to initialize migrations: %w", err)
+	if err := gormigrate.RegisterSQLMigrationDirs("."); err != nil {
+		return fmt.Errorf("failed to load SQL migrations: %w", err)
 	}
 
-	fmt.Printf("Migrating to: %s...\n", migrationID)
-	if err := app.MigrateTo(migrationID); err != nil {
-		return fmt.Errorf("migration failed: %w", err)
+	db := orm.Primary(app.DB)
+	appliedMap := appliedMigrations(db)
+
+	appMigrations, err := gormigrate.OrderedMigrations()
+	if err != nil {
+		return err
 	}
 
-	fmt.Println("Migration completed successfully")
+	fmt.Printf("\nMigration Lint\n")
+	fmt.Printf("════════════════════════════════════════════\n")
+
+	pending, unsafe := 0, 0
+	for _, m := range appMigrations {
+		if appliedMap[m.ID] {
+			continue
+		}
+		pending++
+
+		statements, err := gormigrate.DryRun(db, m.Migrate)
+		ignored := make(map[string]bool, len(m.LintIgnore))
+		for _, rule := range m.LintIgnore {
+			ignored[rule] = true
+		}
+		var findings []gormigrate.LintFinding
+		for _, finding := range gormigrate.Lint(db.Dialector.Name(), statements) {
+			if !ignored[finding.Rule] {
+				findings = append(findings, finding)
+			}
+		}
+		if len(findings) == 0 && err == nil {
+			continue
+		}
+
+		fmt.Printf("  [%s] %s\n", m.AppName, m.ID)
+		for _, finding := range findings {
+			unsafe++
+			fmt.Printf("      %s: %s\n", finding.Rule, firstLine(finding.Statement))
+			fmt.Printf("        %s\n", finding.Problem)
+			fmt.Printf("        Instead: %s\n", finding.Suggestion)
+		}
+		if err != nil {
+			fmt.Printf("      (lint incomplete: %v)\n", err)
+		}
+	}
+
+	fmt.Println("────────────────────────────────────────────")
+	fmt.Printf("Pending: %d, unsafe operations: %d\n", pending, unsafe)
+
+	if check && unsafe > 0 {
+		return fmt.Errorf("%d operation(s) unsafe for rolling deploys", unsafe)
+	}
 	return nil
 }
 
-// RollbackToVersion rolls back to a specific migration ID
-func RollbackToVersion(app *Application, migrationID string) error {
+// appliedMigrations returns the IDs recorded in gormigrate's table
+func appliedMigrations(db *gorm.DB) map[string]bool {
+	var appliedIDs []string
+	if db.Migrator().HasTable("bourbon_migrations") {
+		db.Table("bourbon_migrations").Pluck("id", &appliedIDs)
+	}
+	appliedMap := make(map[string]bool)
+	for _, id := range appliedIDs {
+		appliedMap[id] = true
+	}
+	return appliedMap
+}
+
+func firstLine(statement string) string {
+	if line, _, found := strings.Cut(statement, "\n"); found {
+		return line + " ..."
+	}
+	return statement
+}
+
+// RollbackLastMigration rolls back the last applied migration
+func RollbackLastMigration(app *Application) error {
 	if app == nil {
 		return fmt.Errorf("application is nil")
 	}
@@ -199,8 +475,7 @@ func RollbackToVersion(app *Application, migrationID string) error {
 		return fmt.Errorf("failed to initialize migrations: %w", err)
 	}
 
-	fmt.Printf("Rolling back to: %s...\n", migrationID)
-	if err := app.RollbackTo(migrationID); err != nil {
+	if err := app.RollbackLast(); err != nil {
 		return fmt.Errorf("rollback failed: %w", err)
 	}
 
@@ -208,68 +483,179 @@ func RollbackToVersion(app *Application, migrationID string) error {
 	return nil
 }
 
-// getProjectModule reads the go.mod file to get the module name
-func getProjectModule() (string, error) {
-	data, err := os.ReadFile("go.mod")
+// RollbackMigrations rolls back applied migrations, latest first. With appName set
+// only that app's migrations are rolled back. It stops after steps migrations, or
+// with to set, at that migration (exclusive); with neither it rolls back one.
+// Migrations without a rollback, or that applied migrations of other apps depend
+// on, stop it before anything runs.
+func RollbackMigrations(app *Application, appName, to string, steps int) error {
+	if app == nil {
+		return fmt.Errorf("application is nil")
+	}
+
+	if app.DB == nil {
+		return fmt.Errorf("database not initialized - call ConnectDB() first")
+	}
+
+	if err := app.InitMigrations(); err != nil {
+		return fmt.Errorf("failed to initialize migrations: %w", err)
+	}
+
+	appMigrations, err := gormigrate.OrderedMigrations()
 	if err != nil {
-		return "", fmt.Errorf("failed to read go.mod: %w", err)
+		return err
 	}
+	appliedMap := appliedMigrations(orm.Primary(app.DB))
 
-	lines := strings.Split(string(data), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "module ") {
-			return strings.TrimSpace(strings.TrimPrefix(line, "module ")), nil
+	if to != "" {
+		found := false
+		for _, m := range appMigrations {
+			if m.ID == to && (appName == "" || m.AppName == appName) {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown migration: %s", to)
 		}
 	}
+	if steps <= 0 && to == "" {
+		steps = 1
+	}
 
-	return "", fmt.Errorf("module not found in go.mod")
-}
+	var selected []*gormigrate.AppMigration
+	rolledBack := make(map[string]bool)
+	for i := len(appMigrations) - 1; i >= 0; i-- {
+		m := appMigrations[i]
+		if m.ID == to || (steps > 0 && len(selected) == steps) {
+			break
+		}
+		if !appliedMap[m.ID] || (appName != "" && m.AppName != appName) {
+			continue
+		}
+		if m.Rollback == nil {
+			return fmt.Errorf("migration %s has no rollback", m.ID)
+		}
+		selected = append(selected, m)
+		rolledBack[m.ID] = true
+	}
+	if len(selected) == 0 {
+		fmt.Println("No applied migrations to roll back")
+		return nil
+	}
 
-// hasModels checks if an app has models defined
-func hasModels(modelsPath string) bool {
-	if _, err := os.Stat(modelsPath); os.IsNotExist(err) {
-		return false
+	// A migration another app depends on has to wait until that app rolls back
+	for _, m := range appMigrations {
+		if !appliedMap[m.ID] || rolledBack[m.ID] {
+			continue
+		}
+		for _, ref := range m.DependsOn {
+			refApp, id, qualified := strings.Cut(ref, ":")
+			if !qualified {
+				id = refApp
+			}
+			if rolledBack[id] {
+				return fmt.Errorf("cannot roll back %s: applied migration %s (app: %s) depends on it", id, m.ID, m.AppName)
+			}
+		}
 	}
 
-	data, err := os.ReadFile(modelsPath)
-	if err != nil {
-		return false
+	ids := make([]string, len(selected))
+	for i, m := range selected {
+		ids[i] = m.ID
+		fmt.Printf("  [%s] %s\n", m.AppName, m.ID)
 	}
+	if err := app.RollbackMigrations(ids); err != nil {
+		return err
+	}
+
+	fmt.Printf("Rolled back %d migration(s)\n", len(ids))
+	return nil
+}
 
-	content := string(data)
-	return strings.Contains(content, "gorm.Model") ||
-		strings.Contains(content, "type") && strings.Contains(content, "struct")
+// ResetMigrations rolls back every applied migration, latest first, e.g. before
+// running them all again in development
+func ResetMigrations(app *Application) error {
+	return RollbackMigrations(app, "", "", math.MaxInt)
 }
 
-// hasModelChanges checks if app has uncommitted model changes (simplified check)
-func hasModelChanges(appName string) bool {
-	modelsPath := filepath.Join("apps", appName, "models.go")
-	migrationsDir := filepath.Join("apps", appName, "migrations")
+// DropAllTables drops every table in the primary database, bourbon_migrations
+// included, whether or not a migration created it
+func DropAllTables(app *Application) error {
+	if app == nil {
+		return fmt.Errorf("application is nil")
+	}
 
-	// Check if migrations directory exists
-	if _, err := os.Stat(migrationsDir); os.IsNotExist(err) {
-		// If no migrations exist but models do, there are changes
-		return hasModels(modelsPath)
+	if app.DB == nil {
+		return fmt.Errorf("database not initialized - call ConnectDB() first")
 	}
 
-	// Check if migrations directory has any migration files
-	entries, err := os.ReadDir(migrationsDir)
+	db := orm.Primary(app.DB)
+	tables, err := db.Migrator().GetTables()
 	if err != nil {
-		return false
+		return fmt.Errorf("failed to list tables: %w", err)
 	}
 
-	hasMigrationFiles := false
-	for _, entry := range entries {
-		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".go" {
-			name := entry.Name()
-			if name != ".gitkeep" && !strings.HasSuffix(name, "_test.go") {
-				hasMigrationFiles = true
-				break
-			}
+	// SQLite's own tables, such as sqlite_sequence, cannot be dropped
+	values := make([]interface{}, 0, len(tables))
+	for _, table := range tables {
+		if !strings.HasPrefix(table, "sqlite_") {
+			values = append(values, table)
 		}
 	}
+	if len(values) == 0 {
+		fmt.Println("No tables to drop")
+		return nil
+	}
+	if err := db.Migrator().DropTable(values...); err != nil {
+		return fmt.Errorf("failed to drop tables: %w", err)
+	}
+
+	fmt.Printf("Dropped %d table(s)\n", len(values))
+	return nil
+}
+
+// MigrateToVersion migrates to a specific migration ID
+func MigrateToVersion(app *Application, migrationID string) error {
+	if app == nil {
+		return fmt.Errorf("application is nil")
+	}
+
+	if app.DB == nil {
+		return fmt.Errorf("database not initialized - call ConnectDB() first")
+	}
+
+	if err := app.InitMigrations(); err != nil {
+		return fmt.Errorf("failed to initialize migrations: %w", err)
+	}
+
+	fmt.Printf("Migrating to: %s...\n", migrationID)
+	if err := app.MigrateTo(migrationID); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
+	fmt.Println("Migration completed successfully")
+	return nil
+}
 
-	// If models exist but no migration files, there are changes
-	return hasModels(modelsPath) && !hasMigrationFiles
+// RollbackToVersion rolls back to a specific migration ID
+func RollbackToVersion(app *Application, migrationID string) error {
+	if app == nil {
+		return fmt.Errorf("application is nil")
+	}
+
+	if app.DB == nil {
+		return fmt.Errorf("database not initialized - call ConnectDB() first")
+	}
+
+	if err := app.InitMigrations(); err != nil {
+		return fmt.Errorf("failed to initialize migrations: %w", err)
+	}
+
+	fmt.Printf("Rolling back to: %s...\n", migrationID)
+	if err := app.RollbackTo(migrationID); err != nil {
+		return fmt.Errorf("rollback failed: %w", err)
+	}
+
+	fmt.Println("Rollback completed successfully")
+	return nil
 }
@@ -0,0 +1,229 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core/gormigrate"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// migrationRecord is a row of gormigrate's bourbon_migrations table
+type migrationRecord struct {
+	ID string `gorm:"primaryKey;size:255"`
+}
+
+func (migrationRecord) TableName() string { return "bourbon_migrations" }
+
+// migrationState loads the registered migrations and opens the tracking table,
+// creating it when a database is adopted before any migration ran
+func migrationState(app *Application) (*gorm.DB, []*gormigrate.AppMigration, map[string]bool, error) {
+	if app == nil {
+		return nil, nil, nil, fmt.Errorf("application is nil")
+	}
+
+	if app.DB == nil {
+		return nil, nil, nil, fmt.Errorf("database not initialized - call ConnectDB() first")
+	}
+
+	if err := gormigrate.RegisterSQLMigrationDirs("."); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load SQL migrations: %w", err)
+	}
+
+	db := orm.Primary(app.DB)
+	if !db.Migrator().HasTable(&migrationRecord{}) {
+		if err := db.Migrator().CreateTable(&migrationRecord{}); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to create bourbon_migrations table: %w", err)
+		}
+	}
+	migrations, err := gormigrate.OrderedMigrations()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return db, migrations, appliedMigrations(db), nil
+}
+
+// FakeMigrations records the pending migrations up to and including upTo as
+// applied without running them, e.g. when adopting Bourbon on a database whose
+// schema already matches them
+func FakeMigrations(app *Application, upTo string) error {
+	db, migrations, applied, err := migrationState(app)
+	if err != nil {
+		return err
+	}
+
+	var ids []string
+	found := false
+	for _, m := range migrations {
+		if !applied[m.ID] {
+			ids = append(ids, m.ID)
+		}
+		if m.ID == upTo {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("unknown migration: %s", upTo)
+	}
+	if len(ids) == 0 {
+		fmt.Printf("%s is already applied\n", upTo)
+		return nil
+	}
+	if err := recordMigrations(db, ids); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		fmt.Printf("  [FAKED] %s\n", id)
+	}
+	fmt.Printf("Marked %d migration(s) as applied without running them\n", len(ids))
+	return nil
+}
+
+// MarkMigrationsApplied records registered migrations as applied without running them
+func MarkMigrationsApplied(app *Application, ids []string) error {
+	db, migrations, applied, err := migrationState(app)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(migrations))
+	for _, m := range migrations {
+		known[m.ID] = true
+	}
+	var marked []string
+	for _, id := range ids {
+		if !known[id] {
+			return fmt.Errorf("unknown migration: %s", id)
+		}
+		if applied[id] {
+			fmt.Printf("  [SKIPPED] %s is already applied\n", id)
+			continue
+		}
+		marked = append(marked, id)
+	}
+	if err := recordMigrations(db, marked); err != nil {
+		return err
+	}
+	for _, id := range marked {
+		fmt.Printf("  [APPLIED] %s\n", id)
+	}
+	return nil
+}
+
+// MarkMigrationsUnapplied removes migrations from the tracking table without
+// rolling them back, so migrate runs them again. IDs no longer registered can be
+// removed too.
+func MarkMigrationsUnapplied(app *Application, ids []string) error {
+	db, _, applied, err := migrationState(app)
+	if err != nil {
+		return err
+	}
+
+	var removed []string
+	for _, id := range ids {
+		if !applied[id] {
+			fmt.Printf("  [SKIPPED] %s is not applied\n", id)
+			continue
+		}
+		removed = append(removed, id)
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	if err := db.Where("id IN ?", removed).Delete(&migrationRecord{}).Error; err != nil {
+		return fmt.Errorf("failed to update bourbon_migrations: %w", err)
+	}
+	for _, id := range removed {
+		fmt.Printf("  [PENDING] %s\n", id)
+	}
+	return nil
+}
+
+// RepairMigrations compares the tracking table with the registered migrations and
+// reports drift: applied IDs that no migration registers, and pending migrations
+// ordered before applied ones. With fix set, the unknown IDs are removed.
+func RepairMigrations(app *Application, fix bool) error {
+	db, migrations, applied, err := migrationState(app)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(migrations))
+	for _, m := range migrations {
+		known[m.ID] = true
+	}
+	var unknown []string
+	for id := range applied {
+		if !known[id] {
+			unknown = append(unknown, id)
+		}
+	}
+	sort.Strings(unknown)
+
+	// Pending migrations before the last applied one were usually merged from
+	// another branch; migrate runs them, but after later ones
+	lastApplied := -1
+	for i, m := range migrations {
+		if applied[m.ID] {
+			lastApplied = i
+		}
+	}
+	var outOfOrder []*gormigrate.AppMigration
+	for _, m := range migrations[:lastApplied+1] {
+		if !applied[m.ID] {
+			outOfOrder = append(outOfOrder, m)
+		}
+	}
+
+	fmt.Printf("\nMigration Repair\n")
+	fmt.Printf("════════════════════════════════════════════\n")
+	if len(unknown) == 0 && len(outOfOrder) == 0 {
+		fmt.Println("No drift: bourbon_migrations matches the registered migrations")
+		return nil
+	}
+
+	if len(unknown) > 0 {
+		fmt.Printf("\nApplied but not registered (%d):\n", len(unknown))
+		for _, id := range unknown {
+			fmt.Printf("  - %s\n", id)
+		}
+		if fix {
+			if err := db.Where("id IN ?", unknown).Delete(&migrationRecord{}).Error; err != nil {
+				return fmt.Errorf("failed to update bourbon_migrations: %w", err)
+			}
+			fmt.Println("  Removed from bourbon_migrations")
+		} else {
+			fmt.Println("  Restore the migration files, or remove the entries with migrate:repair --fix")
+		}
+	}
+
+	if len(outOfOrder) > 0 {
+		fmt.Printf("\nPending before applied migrations (%d):\n", len(outOfOrder))
+		for _, m := range outOfOrder {
+			fmt.Printf("  - [%s] %s\n", m.AppName, m.ID)
+		}
+		fmt.Println("  migrate runs them next; use migrate:mark-applied if the schema already has them")
+	}
+
+	if !fix && len(unknown) > 0 {
+		return fmt.Errorf("%d unknown migration(s) in bourbon_migrations", len(unknown))
+	}
+	return nil
+}
+
+func recordMigrations(db *gorm.DB, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	records := make([]migrationRecord, len(ids))
+	for i, id := range ids {
+		records[i] = migrationRecord{ID: id}
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&records).Error; err != nil {
+		return fmt.Errorf("failed to update bourbon_migrations: %w", err)
+	}
+	return nil
+}
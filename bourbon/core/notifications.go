@@ -0,0 +1,102 @@
+package core
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/mail"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/notification"
+	"go.uber.org/zap"
+)
+
+// initNotifier creates app.Notifier with the built-in channels configured under
+// [notifications]. The mail channel sends through app.Mailer.
+func (a *App) initNotifier() {
+	cfg := a.Config.Notifications
+	n := notification.NewNotifier(notification.Options{Queue: a.Jobs, Logger: a.Logger})
+	n.Register(notification.Mail, &notification.MailChannel{Mailer: a.Mailer})
+	n.Register(notification.Slack, &notification.SlackChannel{WebhookURL: cfg.SlackWebhook})
+	n.Register(notification.Webhook, &notification.WebhookChannel{URL: cfg.WebhookURL, Secret: cfg.WebhookSecret})
+
+	switch cfg.SMS.Driver {
+	case "":
+	case "twilio":
+		n.Register(notification.SMS, &notification.SMSChannel{Sender: &notification.TwilioSender{
+			AccountSID: cfg.SMS.AccountSID,
+			AuthToken:  cfg.SMS.AuthToken,
+			From:       cfg.SMS.From,
+		}})
+	default:
+		a.Logger.Warn("Unknown SMS driver, sms notifications are disabled", zap.String("driver", cfg.SMS.Driver))
+	}
+	a.Notifier = n
+}
+
+// alertNotification sends an error alert through the channels its recipient has routes for
+type alertNotification struct {
+	alert *logging.ErrorAlert
+}
+
+func (n alertNotification) Via(to notification.Notifiable) []string {
+	var channels []string
+	for _, channel := range []string{notification.Mail, notification.Slack, notification.Webhook, notification.SMS} {
+		if to.NotificationRoute(channel) != "" {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
+}
+
+func (n alertNotification) ToMail(notification.Notifiable) *mail.Message {
+	return mail.NewMessage().SetSubject(n.alert.Subject()).SetText(n.alert.Summary())
+}
+
+func (n alertNotification) ToSlack(notification.Notifiable) *notification.SlackMessage {
+	return &notification.SlackMessage{Text: "```" + n.alert.Summary() + "```"}
+}
+
+func (n alertNotification) ToWebhook(notification.Notifiable) interface{} {
+	return map[string]interface{}{
+		"event":     "error_alert",
+		"app":       n.alert.AppName,
+		"count":     n.alert.Count,
+		"threshold": n.alert.Threshold,
+		"window":    n.alert.Window.String(),
+		"fired_at":  n.alert.FiredAt,
+		"recent":    n.alert.Recent,
+	}
+}
+
+func (n alertNotification) ToSMS(notification.Notifiable) string {
+	return n.alert.Subject()
+}
+
+// alertNotifier delivers error alerts to the [logging.alerts] recipients through app.Notifier
+type alertNotifier struct {
+	notifier *notification.Notifier
+	routes   notification.Routes
+}
+
+func (n *alertNotifier) Notify(alert *logging.ErrorAlert) error {
+	return n.notifier.Send(context.Background(), alertNotification{alert: alert}, n.routes)
+}
+
+// alertRoutes returns the alert recipients from [logging.alerts]
+func (a *App) alertRoutes() notification.Routes {
+	alerts := a.Config.Logging.Alerts
+	routes := notification.Routes{}
+	if len(alerts.Email) > 0 {
+		routes[notification.Mail] = strings.Join(alerts.Email, ", ")
+	}
+	if alerts.SlackWebhook != "" {
+		routes[notification.Slack] = alerts.SlackWebhook
+	}
+	if alerts.Webhook != "" {
+		routes[notification.Webhook] = alerts.Webhook
+	}
+	if alerts.SMS != "" {
+		routes[notification.SMS] = alerts.SMS
+	}
+	return routes
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"net/http"
+
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/openapi"
+	"go.uber.org/zap"
+)
+
+// OpenAPI generates an OpenAPI document from the registered routes and [openapi]
+func (a *App) OpenAPI() *openapi.Document {
+	opts := openapi.Options{}
+	if a.Config != nil {
+		cfg := a.Config.OpenAPI
+		opts = openapi.Options{
+			Title:       cfg.Title,
+			Version:     cfg.Version,
+			Description: cfg.Description,
+			Servers:     cfg.Servers,
+			Prefix:      cfg.Prefix,
+		}
+		if opts.Title == "" {
+			opts.Title = a.Config.App.Name
+		}
+	}
+	return openapi.Generate(a.Router.GetRoutes(), opts)
+}
+
+// MountOpenAPI serves the document at openapi.path (default /openapi.json) and
+// Swagger UI at openapi.docs_path (default /docs). The document is generated per
+// request, so routes registered after mounting are included.
+func (a *App) MountOpenAPI(middleware ...bourbon.MiddlewareFunc) {
+	specPath, docsPath := "/openapi.json", "/docs"
+	if a.Config != nil {
+		if a.Config.OpenAPI.Path != "" {
+			specPath = a.Config.OpenAPI.Path
+		}
+		if a.Config.OpenAPI.DocsPath != "" {
+			docsPath = a.Config.OpenAPI.DocsPath
+		}
+	}
+
+	for _, route := range a.Router.GetRoutes() {
+		if route.Method == http.MethodGet && (route.Pattern == specPath || route.Pattern == docsPath) {
+			a.Logger.Warn("OpenAPI route already registered, not mounting", zap.String("path", route.Pattern))
+			return
+		}
+	}
+
+	group := a.Router.Group("", middleware...)
+	group.Get(specPath, func(c *bourbon.Context) error {
+		return c.JSON(http.StatusOK, a.OpenAPI())
+	}).Hidden()
+	group.Get(docsPath, func(c *bourbon.Context) error {
+		page, err := openapi.SwaggerUI(a.OpenAPI().Info.Title, specPath)
+		if err != nil {
+			return err
+		}
+		return c.HTML(http.StatusOK, page)
+	}).Hidden()
+}
+
+// initOpenAPI writes openapi.output and, in debug mode or with openapi.enabled,
+// mounts the document and Swagger UI. It runs at startup, after routes are registered.
+func (a *App) initOpenAPI() {
+	cfg := a.Config.OpenAPI
+	if cfg.Output != "" {
+		if err := a.OpenAPI().WriteFile(cfg.Output); err != nil {
+			a.Logger.Warn("Failed to write OpenAPI document", zap.Error(err), zap.String("path", cfg.Output))
+		} else {
+			a.Logger.Info("OpenAPI document written", zap.String("path", cfg.Output))
+		}
+	}
+	if a.Config.App.Debug || cfg.Enabled {
+		a.MountOpenAPI()
+	}
+}
@@ -0,0 +1,187 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/middleware"
+)
+
+// RoutePolicy declares the cross-cutting behaviour of a group of routes.
+// It expands into a middleware chain in a fixed order: CORS, Auth, Cache, then Middleware.
+//
+//	api := app.Group("/api", core.RoutePolicy{Auth: "jwt", Cache: 5 * time.Minute, CORS: "public"})
+type RoutePolicy struct {
+	// Auth is the name of a registered authentication middleware, e.g. "jwt"
+	Auth string
+	// Cache sets a Cache-Control max-age on successful GET responses. It is
+	// private when Auth is set, so shared caches don't store per-user responses.
+	Cache time.Duration
+	// CORS names a CORS policy. "public" allows any origin; any other name
+	// refers to a middleware registered as "cors:<name>"
+	CORS string
+	// Middleware lists additional registered middleware names applied last
+	Middleware []string
+}
+
+// String returns a compact, human-readable description of the policy for audits and logs
+func (p RoutePolicy) String() string {
+	var parts []string
+	if p.CORS != "" {
+		parts = append(parts, "cors="+p.CORS)
+	}
+	if p.Auth != "" {
+		parts = append(parts, "auth="+p.Auth)
+	}
+	if p.Cache > 0 {
+		parts = append(parts, "cache="+p.Cache.String())
+	}
+	if len(p.Middleware) > 0 {
+		parts = append(parts, "middleware="+strings.Join(p.Middleware, ","))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, " ")
+}
+
+// Middlewares resolves the policy against the app's middleware registry
+func (p RoutePolicy) Middlewares(a *App) ([]bourbon.MiddlewareFunc, error) {
+	named, err := p.resolve(a)
+	if err != nil {
+		return nil, err
+	}
+	chain := make([]bourbon.MiddlewareFunc, len(named))
+	for i, mw := range named {
+		chain[i] = mw.middleware
+	}
+	return chain, nil
+}
+
+// policyMiddleware is a middleware of a policy's chain, with the name routes list it as
+type policyMiddleware struct {
+	name       string
+	middleware bourbon.MiddlewareFunc
+}
+
+// resolve builds the policy's chain, naming each middleware after its registry
+// name or the policy field it comes from
+func (p RoutePolicy) resolve(a *App) ([]policyMiddleware, error) {
+	var chain []policyMiddleware
+
+	if p.CORS != "" {
+		name := "cors:" + p.CORS
+		if mw, ok := a.MiddlewareRegistry.Get(name); ok {
+			chain = append(chain, policyMiddleware{name, bourbon.Adapt(mw)})
+		} else if p.CORS == "public" {
+			chain = append(chain, policyMiddleware{name, bourbon.Adapt(middleware.CORS("*"))})
+		} else {
+			return nil, fmt.Errorf("CORS policy '%s' not registered (register middleware '%s')", p.CORS, name)
+		}
+	}
+
+	if p.Auth != "" {
+		mw, ok := a.MiddlewareRegistry.Get(p.Auth)
+		if !ok {
+			return nil, fmt.Errorf("auth middleware '%s' not registered", p.Auth)
+		}
+		chain = append(chain, policyMiddleware{p.Auth, bourbon.Adapt(mw)})
+	}
+
+	if p.Cache > 0 {
+		cacheControl := middleware.CacheControl(p.Cache)
+		if p.Auth != "" {
+			cacheControl = middleware.PrivateCacheControl(p.Cache)
+		}
+		chain = append(chain, policyMiddleware{"cache=" + p.Cache.String(), bourbon.Adapt(cacheControl)})
+	}
+
+	for _, name := range p.Middleware {
+		mw, ok := a.MiddlewareRegistry.Get(name)
+		if !ok {
+			return nil, fmt.Errorf("middleware '%s' not registered", name)
+		}
+		chain = append(chain, policyMiddleware{name, bourbon.Adapt(mw)})
+	}
+
+	return chain, nil
+}
+
+// Group creates a route group whose routes are protected by the given policy.
+// It panics if the policy references unregistered middleware, since that is a
+// programming error that should surface at startup rather than at request time.
+// With CORS set, OPTIONS requests under the prefix are answered as preflights,
+// by the CORS middleware alone since browsers send them without credentials.
+func (a *App) Group(prefix string, policy RoutePolicy) *bourbon.Group {
+	chain, err := policy.resolve(a)
+	if err != nil {
+		panic(fmt.Sprintf("route group %s: %v", prefix, err))
+	}
+
+	a.Logger.Debug(fmt.Sprintf("Route group %s policy: %s", prefix, policy))
+	if policy.CORS != "" {
+		a.preflight(prefix, chain[0])
+	}
+	group := a.Router.Group(prefix)
+	for _, mw := range chain {
+		group.UseAs(mw.name, mw.middleware)
+	}
+	return group
+}
+
+// preflight registers OPTIONS routes for prefix and the paths under it, run by
+// the policy's CORS middleware. Groups sharing a prefix share the routes.
+func (a *App) preflight(prefix string, cors policyMiddleware) {
+	patterns := []string{"/{path...}"}
+	if path.Clean("/"+prefix) != "/" {
+		patterns = append(patterns, "")
+	}
+
+	group := a.Router.Group(prefix)
+	group.UseAs(cors.name, cors.middleware)
+	for _, pattern := range patterns {
+		full := path.Clean(prefix + pattern)
+		registered := false
+		for _, route := range a.Router.GetRoutes() {
+			if route.Method == http.MethodOptions && route.Pattern == full {
+				registered = true
+				break
+			}
+		}
+		if !registered {
+			group.Options(pattern, preflightHandler)
+		}
+	}
+}
+
+// preflightHandler answers preflights the CORS middleware passes on
+func preflightHandler(c *bourbon.Context) error {
+	c.Writer.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// ConfiguredGroup creates the route group declared under [middleware.groups.<name>]
+// in settings.toml, applying its enabled middleware by name. The prefix defaults to "/<name>".
+func (a *App) ConfiguredGroup(name string) (*bourbon.Group, error) {
+	cfg := MiddlewareGroupConfig{}
+	if a.Config != nil {
+		if groupCfg, ok := a.Config.Middleware.Groups[name]; ok {
+			cfg = groupCfg
+		}
+	}
+
+	prefix := cfg.Prefix
+	if prefix == "" {
+		prefix = "/" + name
+	}
+
+	group := a.Router.Group(prefix)
+	if err := group.UseNamed(cfg.Enabled...); err != nil {
+		return nil, fmt.Errorf("route group %s: %w", name, err)
+	}
+	return group, nil
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core/registry"
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+)
+
+func newPolicyApp() *App {
+	a := &App{
+		Router:             bourbon.NewRouter(),
+		MiddlewareRegistry: registry.NewMiddlewareRegistry(),
+		Logger:             logging.Nop(),
+	}
+	a.MiddlewareRegistry.Register("token", func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	return a
+}
+
+func serve(a *App, method, target string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	a.Router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGroupPolicyPreflight(t *testing.T) {
+	a := newPolicyApp()
+	api := a.Group("/api", RoutePolicy{CORS: "public", Auth: "token"})
+	api.Get("/items", func(c *bourbon.Context) error { return c.String(http.StatusOK, "items") })
+	api.Post("/items", func(c *bourbon.Context) error { return c.String(http.StatusCreated, "created") })
+	// A second group on the prefix reuses the preflight routes
+	a.Group("/api", RoutePolicy{CORS: "public"}).Get("/health", func(c *bourbon.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	preflight := http.Header{
+		"Origin":                        {"https://app.example.com"},
+		"Access-Control-Request-Method": {"POST"},
+	}
+	for _, target := range []string{"/api", "/api/items", "/api/items/1"} {
+		rec := serve(a, http.MethodOptions, target, preflight)
+		if rec.Code >= 300 {
+			t.Errorf("OPTIONS %s = %d, want a successful preflight", target, rec.Code)
+		}
+		if rec.Header().Get("Access-Control-Allow-Origin") != "*" || rec.Header().Get("Access-Control-Allow-Methods") == "" {
+			t.Errorf("OPTIONS %s is missing the CORS headers: %v", target, rec.Header())
+		}
+	}
+
+	if rec := serve(a, http.MethodPost, "/api/items", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("POST without credentials = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	rec := serve(a, http.MethodGet, "/api/items", http.Header{"Authorization": {"Bearer x"}})
+	if rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("GET = %d %v", rec.Code, rec.Header())
+	}
+	if rec := serve(a, http.MethodOptions, "/other", preflight); rec.Code != http.StatusNotFound {
+		t.Errorf("OPTIONS outside the group = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestGroupPolicyCacheControl(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RoutePolicy
+		want   string
+	}{
+		{"public", RoutePolicy{Cache: time.Minute}, "public, max-age=60"},
+		{"authenticated", RoutePolicy{Cache: time.Minute, Auth: "token"}, "private, max-age=60"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newPolicyApp()
+			a.Group("/pages", tt.policy).Get("/home", func(c *bourbon.Context) error {
+				return c.String(http.StatusOK, "home")
+			})
+			rec := serve(a, http.MethodGet, "/pages/home", http.Header{"Authorization": {"Bearer x"}})
+			if got := rec.Header().Get("Cache-Control"); got != tt.want {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
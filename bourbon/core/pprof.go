@@ -0,0 +1,120 @@
+package core
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/middleware"
+	"go.uber.org/zap"
+)
+
+// Profiling endpoint paths
+const (
+	PprofPath = "/_debug/pprof/"
+	VarsPath  = "/_debug/vars"
+)
+
+// pprofLoopback is who may reach the profiling endpoints when server.pprof_allow is empty
+var pprofLoopback = []string{"127.0.0.0/8", "::1"}
+
+// MountPprof serves the runtime profiles of net/http/pprof and the expvar
+// variables, so a running server can be profiled without redeploying it:
+//
+//	GET /_debug/pprof/                      index of the profiles
+//	GET /_debug/pprof/profile?seconds=10    CPU profile
+//	GET /_debug/pprof/heap                  memory profile, and goroutine, block, ...
+//	GET /_debug/vars                        expvar variables as JSON
+//
+// Only the addresses in server.pprof_allow, loopback by default, may use them,
+// and with server.pprof_user set they also need HTTP Basic auth. The middleware
+// runs after those checks.
+func (a *App) MountPprof(middleware ...bourbon.MiddlewareFunc) error {
+	for _, route := range a.Router.GetRoutes() {
+		if route.Method == http.MethodGet && route.Pattern == VarsPath {
+			a.Logger.Warn("Profiling endpoints already registered, not mounting", zap.String("path", route.Pattern))
+			return nil
+		}
+	}
+
+	group := a.Router.Group("")
+	if err := a.protectPprof(group); err != nil {
+		return err
+	}
+	group.Use(middleware...)
+
+	vars := expvar.Handler()
+	group.Get(VarsPath, func(c *bourbon.Context) error {
+		vars.ServeHTTP(c.Writer, c.Request)
+		return nil
+	}).Hidden()
+	group.Get(PprofPath+"{name...}", func(c *bourbon.Context) error {
+		pprofHandler(c.Param("name")).ServeHTTP(c.Writer, c.Request)
+		return nil
+	}).Hidden()
+	// go tool pprof looks up symbols with POST
+	group.Post(PprofPath+"symbol", func(c *bourbon.Context) error {
+		pprof.Symbol(c.Writer, c.Request)
+		return nil
+	}).Hidden()
+	return nil
+}
+
+// protectPprof adds the IP allowlist and, with server.pprof_user, Basic auth to
+// the group of the profiling endpoints
+func (a *App) protectPprof(group *bourbon.Group) error {
+	cfg := ServerConfig{}
+	var trusted []string
+	if a.Config != nil {
+		cfg = a.Config.Server
+		trusted = a.Config.Security.TrustedProxies
+	}
+	allow := cfg.PprofAllow
+	if len(allow) == 0 {
+		allow = pprofLoopback
+	}
+	filter, err := middleware.IPFilter(middleware.IPFilterConfig{Allow: allow, TrustedProxies: trusted})
+	if err != nil {
+		return fmt.Errorf("invalid server.pprof_allow: %w", err)
+	}
+
+	group.UseAs("ip_filter", bourbon.Adapt(filter))
+	if cfg.PprofUser != "" {
+		users := middleware.BasicAuthUsers(map[string]string{cfg.PprofUser: cfg.PprofPassword})
+		group.UseAs("basic_auth", bourbon.Adapt(middleware.BasicAuth("pprof", users)))
+	}
+	return nil
+}
+
+// pprofHandler returns the net/http/pprof handler of a profile name, the index
+// for an empty one. pprof.Index only serves profiles under /debug/pprof/.
+func pprofHandler(name string) http.Handler {
+	switch name {
+	case "":
+		return http.HandlerFunc(pprof.Index)
+	case "cmdline":
+		return http.HandlerFunc(pprof.Cmdline)
+	case "profile":
+		return http.HandlerFunc(pprof.Profile)
+	case "symbol":
+		return http.HandlerFunc(pprof.Symbol)
+	case "trace":
+		return http.HandlerFunc(pprof.Trace)
+	}
+	return pprof.Handler(name)
+}
+
+// initPprof mounts the profiling endpoints in debug mode or with
+// server.enable_pprof. It runs at startup.
+func (a *App) initPprof() {
+	if !a.Config.App.Debug && !a.Config.Server.EnablePprof {
+		return
+	}
+	if err := a.MountPprof(); err != nil {
+		a.Logger.Warn("Profiling endpoints not mounted", zap.Error(err))
+		return
+	}
+	a.Logger.Info("Profiling endpoints mounted", zap.String("path", PprofPath), zap.String("vars", VarsPath))
+}
@@ -0,0 +1,81 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrNotProvided is returned when resolving a type without a provider
+var ErrNotProvided = errors.New("no provider")
+
+// Lifetime controls how often a provider's factory runs
+type Lifetime int
+
+const (
+	// Singleton builds the service once, on first use
+	Singleton Lifetime = iota
+	// Transient builds a new service on every resolve
+	Transient
+)
+
+// Factory builds a service
+type Factory func() (interface{}, error)
+
+type provider struct {
+	lifetime Lifetime
+	factory  Factory
+	mu       sync.Mutex // held while a singleton is built
+	built    bool
+	instance interface{}
+}
+
+// Provide registers the factory for type t, replacing any previous provider.
+// A singleton factory must not resolve its own type.
+func (r *Registry) Provide(t reflect.Type, lifetime Lifetime, factory Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[t] = &provider{lifetime: lifetime, factory: factory}
+}
+
+// Provided reports whether type t has a provider
+func (r *Registry) Provided(t reflect.Type) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.providers[t]
+	return ok
+}
+
+// Resolve returns the service for type t, building it if needed
+func (r *Registry) Resolve(t reflect.Type) (interface{}, error) {
+	r.mu.RLock()
+	p, ok := r.providers[t]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("registry: %w for %s", ErrNotProvided, t)
+	}
+
+	if p.lifetime == Transient {
+		return p.build(t)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.built {
+		return p.instance, nil
+	}
+	instance, err := p.build(t)
+	if err != nil {
+		return nil, err
+	}
+	p.instance, p.built = instance, true
+	return instance, nil
+}
+
+func (p *provider) build(t reflect.Type) (interface{}, error) {
+	instance, err := p.factory()
+	if err != nil {
+		return nil, fmt.Errorf("registry: failed to build %s: %w", t, err)
+	}
+	return instance, nil
+}
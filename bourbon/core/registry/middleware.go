@@ -1,6 +1,8 @@
 package registry
 
 import (
+	"net/http"
+	"strings"
 	"sync"
 
 	"github.com/ishubhamsingh2e/bourbon/bourbon/middleware"
@@ -9,32 +11,146 @@ import (
 // MiddlewareFunc is an alias for the standard middleware function type
 type MiddlewareFunc = middleware.Middleware
 
+// MiddlewareEntry is a registered middleware together with its ordering and conditions
+type MiddlewareEntry struct {
+	Name       string
+	Handler    MiddlewareFunc
+	Priority   int // higher priority middleware wraps (runs before) lower priority middleware
+	predicates []func(*http.Request) bool
+}
+
+// MiddlewareOption configures a registered middleware
+type MiddlewareOption func(*MiddlewareEntry)
+
+// Priority sets the middleware priority. Higher values run earlier; equal
+// priorities keep the order in which middleware were enabled.
+func Priority(priority int) MiddlewareOption {
+	return func(e *MiddlewareEntry) {
+		e.Priority = priority
+	}
+}
+
+// Except skips the middleware for matching paths. A trailing "*" matches a prefix,
+// e.g. Except("/healthz", "/static/*").
+func Except(paths ...string) MiddlewareOption {
+	return When(func(r *http.Request) bool {
+		return !matchAnyPath(paths, r.URL.Path)
+	})
+}
+
+// OnlyPaths applies the middleware only to matching paths (same syntax as Except)
+func OnlyPaths(paths ...string) MiddlewareOption {
+	return When(func(r *http.Request) bool {
+		return matchAnyPath(paths, r.URL.Path)
+	})
+}
+
+// Only applies the middleware only to the given HTTP methods, e.g. Only("POST", "PUT")
+func Only(methods ...string) MiddlewareOption {
+	return When(func(r *http.Request) bool {
+		for _, m := range methods {
+			if strings.EqualFold(m, r.Method) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// When applies the middleware only when the predicate returns true
+func When(predicate func(*http.Request) bool) MiddlewareOption {
+	return func(e *MiddlewareEntry) {
+		e.predicates = append(e.predicates, predicate)
+	}
+}
+
+// Applies reports whether the middleware should run for the request
+func (e *MiddlewareEntry) Applies(r *http.Request) bool {
+	for _, predicate := range e.predicates {
+		if !predicate(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Conditional reports whether the middleware only applies to some requests
+func (e *MiddlewareEntry) Conditional() bool {
+	return len(e.predicates) > 0
+}
+
+// Wrap returns the middleware with its conditions applied. Requests that don't
+// match the predicates bypass the middleware entirely.
+func (e *MiddlewareEntry) Wrap() MiddlewareFunc {
+	if len(e.predicates) == 0 {
+		return e.Handler
+	}
+
+	return func(next http.Handler) http.Handler {
+		wrapped := e.Handler(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if e.Applies(r) {
+				wrapped.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func matchAnyPath(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if pattern == path {
+			return true
+		}
+	}
+	return false
+}
+
 // MiddlewareRegistry holds registered middlewares with their names
 type MiddlewareRegistry struct {
-	middlewares map[string]MiddlewareFunc
+	middlewares map[string]*MiddlewareEntry
 	mu          sync.RWMutex
 }
 
 // NewMiddlewareRegistry creates a new middleware registry
 func NewMiddlewareRegistry() *MiddlewareRegistry {
 	return &MiddlewareRegistry{
-		middlewares: make(map[string]MiddlewareFunc),
+		middlewares: make(map[string]*MiddlewareEntry),
 	}
 }
 
-// Register registers a named middleware function
-func (mr *MiddlewareRegistry) Register(name string, middleware MiddlewareFunc) {
+// Register registers a named middleware function with optional priority and conditions
+func (mr *MiddlewareRegistry) Register(name string, middleware MiddlewareFunc, opts ...MiddlewareOption) {
+	entry := &MiddlewareEntry{Name: name, Handler: middleware}
+	for _, opt := range opts {
+		opt(entry)
+	}
+
 	mr.mu.Lock()
 	defer mr.mu.Unlock()
-	mr.middlewares[name] = middleware
+	mr.middlewares[name] = entry
 }
 
-// Get retrieves a middleware by name
+// Get retrieves a middleware by name, with its conditions applied
 func (mr *MiddlewareRegistry) Get(name string) (MiddlewareFunc, bool) {
+	entry, exists := mr.GetEntry(name)
+	if !exists {
+		return nil, false
+	}
+	return entry.Wrap(), true
+}
+
+// GetEntry retrieves the full registration of a middleware by name
+func (mr *MiddlewareRegistry) GetEntry(name string) (*MiddlewareEntry, bool) {
 	mr.mu.RLock()
 	defer mr.mu.RUnlock()
-	middleware, exists := mr.middlewares[name]
-	return middleware, exists
+	entry, exists := mr.middlewares[name]
+	return entry, exists
 }
 
 // Has checks if a middleware is registered
@@ -68,8 +184,8 @@ func (mr *MiddlewareRegistry) List() []string {
 var globalMiddlewareRegistry = NewMiddlewareRegistry()
 
 // RegisterMiddleware registers a middleware globally
-func RegisterMiddleware(name string, mw MiddlewareFunc) {
-	globalMiddlewareRegistry.Register(name, mw)
+func RegisterMiddleware(name string, mw MiddlewareFunc, opts ...MiddlewareOption) {
+	globalMiddlewareRegistry.Register(name, mw, opts...)
 }
 
 // GetMiddleware retrieves a globally registered middleware
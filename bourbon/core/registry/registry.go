@@ -1,15 +1,20 @@
 package registry
 
-import "sync"
+import (
+	"reflect"
+	"sync"
+)
 
 type Registry struct {
-	services map[string]interface{}
-	mu       sync.RWMutex
+	services  map[string]interface{}
+	providers map[reflect.Type]*provider
+	mu        sync.RWMutex
 }
 
 func NewRegistry() *Registry {
 	return &Registry{
-		services: make(map[string]interface{}),
+		services:  make(map[string]interface{}),
+		providers: make(map[reflect.Type]*provider),
 	}
 }
 
@@ -0,0 +1,254 @@
+package core
+
+import (
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core/registry"
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/middleware"
+)
+
+// RequestDashboardPrefix is where MountRequestDashboard serves the recorded requests
+const RequestDashboardPrefix = "/_bourbon/requests"
+
+// registerRequestRecorder records requests in debug mode, as configured under
+// [logging.recorder]. The framework's own endpoints and static files are skipped.
+func (a *App) registerRequestRecorder() {
+	cfg := a.Config.Logging.Recorder
+	if !a.Config.App.Debug || !cfg.Enabled {
+		return
+	}
+
+	a.Recorder = middleware.NewRequestRecorder(middleware.RequestRecorderConfig{
+		Size:         cfg.Size,
+		MaxBodySize:  cfg.MaxBodySize,
+		RedactFields: cfg.RedactFields,
+	})
+	// Registered before recovery with its priority, so it runs outside it and
+	// records panics as the 500 recovery answers
+	skip := append(a.debugSkipPaths(), cfg.SkipPaths...)
+	a.RegisterMiddleware("recorder", a.Recorder.Middleware(), registry.Priority(1000), registry.Except(skip...))
+	_ = a.UseMiddleware("recorder")
+}
+
+// debugSkipPaths are the paths the debug mode middleware leave alone: the
+// framework's own endpoints and static files
+func (a *App) debugSkipPaths() []string {
+	skip := []string{"/_bourbon/*", "/_debug/*", "/favicon.ico"}
+	if prefix := strings.TrimSuffix(a.Config.Static.URLPrefix, "/"); prefix != "" {
+		skip = append(skip, prefix+"/*")
+	}
+	return skip
+}
+
+// MountRequestDashboard registers routes to browse and replay the requests
+// recorded in debug mode:
+//
+//	GET  /_bourbon/requests              list, filterable by method, status and path
+//	GET  /_bourbon/requests/{id}         headers, bodies, timings and SQL
+//	POST /_bourbon/requests/{id}/replay  serve the request again
+//	POST /_bourbon/requests/clear        drop the recorded requests
+//
+// Like the error dashboard, it must be protected by middleware outside debug mode,
+// and its forms are checked for a CSRF token.
+// In debug mode the server mounts it at startup.
+func (a *App) MountRequestDashboard(middleware ...bourbon.MiddlewareFunc) error {
+	if a.Recorder == nil {
+		return fmt.Errorf("request dashboard requires app.debug and logging.recorder.enabled")
+	}
+	if len(middleware) == 0 && (a.Config == nil || !a.Config.App.Debug) {
+		return fmt.Errorf("request dashboard must be protected by middleware outside debug mode")
+	}
+
+	group := a.Router.Group(RequestDashboardPrefix, middleware...)
+	group.Use(dashboardCSRF())
+	group.Get("", a.requestDashboardList).Hidden()
+	group.Post("/clear", a.requestDashboardClear).Hidden()
+	group.Get("/{id}", a.requestDashboardDetail).Hidden()
+	group.Post("/{id}/replay", a.requestDashboardReplay).Hidden()
+	return nil
+}
+
+// initRequestDashboard mounts the request dashboard when requests are recorded. It runs at startup.
+func (a *App) initRequestDashboard() {
+	if a.Recorder != nil {
+		_ = a.MountRequestDashboard()
+	}
+}
+
+func (a *App) requestDashboardList(c *bourbon.Context) error {
+	method := strings.ToUpper(c.Query("method"))
+	status, _ := strconv.Atoi(c.Query("status"))
+	path := c.Query("path")
+
+	recent := a.Recorder.Recent()
+	var requests []*middleware.RecordedRequest
+	for _, record := range recent {
+		if (method == "" || record.Method == method) &&
+			(status == 0 || record.Status == status) &&
+			strings.Contains(record.Path, path) {
+			requests = append(requests, record)
+		}
+	}
+
+	return renderDashboard(c, requestDashboardTemplates, "list", map[string]interface{}{
+		"Prefix":   RequestDashboardPrefix,
+		"Requests": requests,
+		"Recorded": len(recent),
+		"Size":     a.Config.Logging.Recorder.Size,
+		"Method":   method,
+		"Status":   c.Query("status"),
+		"Path":     path,
+		"CSRF":     c.CSRFToken(),
+	})
+}
+
+func (a *App) requestDashboardDetail(c *bourbon.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.String(404, "Not Found")
+	}
+	record, ok := a.Recorder.Find(id)
+	if !ok {
+		return c.String(404, "Not Found")
+	}
+	return renderDashboard(c, requestDashboardTemplates, "detail", map[string]interface{}{
+		"Prefix":  RequestDashboardPrefix,
+		"Request": record,
+		"CSRF":    c.CSRFToken(),
+	})
+}
+
+func (a *App) requestDashboardReplay(c *bourbon.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.String(404, "Not Found")
+	}
+	replay, err := a.Recorder.Replay(id, a.Handler())
+	if err != nil {
+		return c.String(400, err.Error())
+	}
+	return c.Redirect(303, fmt.Sprintf("%s/%d", RequestDashboardPrefix, replay.ID))
+}
+
+func (a *App) requestDashboardClear(c *bourbon.Context) error {
+	a.Recorder.Clear()
+	return c.Redirect(303, RequestDashboardPrefix)
+}
+
+// requestDashboardFuncs are the functions of the request dashboard's templates
+var requestDashboardFuncs = template.FuncMap{
+	// duration rounds to what matters when reading a request's timings
+	"duration": func(d time.Duration) time.Duration {
+		if d < time.Millisecond {
+			return d.Round(time.Microsecond)
+		}
+		return d.Round(10 * time.Microsecond)
+	},
+}
+
+var requestDashboardTemplates = template.Must(template.New("requests").Funcs(requestDashboardFuncs).Parse(`
+{{define "head"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Requests</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 0; color: #222; background: #fafafa; }
+header { background: #2d3748; color: #fff; padding: 14px 32px; }
+header a { color: #fff; text-decoration: none; font-weight: 600; }
+main { padding: 16px 32px; }
+form { display: inline; }
+form.filters input { padding: 4px 6px; margin-right: 6px; }
+table { border-collapse: collapse; width: 100%; font-size: 13px; background: #fff; margin-bottom: 16px; }
+th, td { padding: 6px 8px; border-bottom: 1px solid #eee; text-align: left; vertical-align: top; }
+th { background: #f0f0f0; }
+td.num { text-align: right; white-space: nowrap; }
+.s5 { color: #b32d2e; font-weight: 600; }
+.s4 { color: #b7791f; font-weight: 600; }
+pre { background: #272822; color: #f8f8f2; padding: 12px; font-size: 12px; overflow-x: auto; white-space: pre-wrap; word-break: break-all; }
+code { font-size: 12px; white-space: pre-wrap; word-break: break-all; }
+.notice { background: #fefcbf; padding: 8px 12px; margin-bottom: 12px; }
+.error { color: #b32d2e; }
+</style>
+</head>
+<body>
+<header><a href="{{.Prefix}}">Requests</a></header>
+<main>{{end}}
+
+{{define "foot"}}</main>
+</body>
+</html>{{end}}
+
+{{define "list"}}{{template "head" .}}
+<form class="filters" method="get" action="{{.Prefix}}">
+<input name="method" placeholder="Method" value="{{.Method}}" size="7">
+<input name="status" placeholder="Status" value="{{.Status}}" size="6">
+<input name="path" placeholder="Path contains" value="{{.Path}}">
+<button type="submit">Filter</button>
+</form>
+<form method="post" action="{{.Prefix}}/clear"><input type="hidden" name="csrf_token" value="{{.CSRF}}"><button type="submit">Clear</button></form>
+<p>{{len .Requests}} of the last {{.Recorded}} requests (up to {{.Size}} are kept)</p>
+<table>
+<tr><th>Time</th><th>Status</th><th>Request</th><th>Duration</th><th>Queries</th></tr>
+{{range .Requests}}
+<tr>
+<td><a href="{{$.Prefix}}/{{.ID}}">{{.Time.Format "15:04:05.000"}}</a>{{if .ReplayOf}} (replay){{end}}</td>
+<td class="{{if ge .Status 500}}s5{{else if ge .Status 400}}s4{{end}}">{{.Status}}</td>
+<td>{{.Method}} {{.Path}}{{if .Query}}?{{.Query}}{{end}}</td>
+<td class="num">{{duration .Duration}}</td>
+<td class="num">{{len .Queries}}{{if .Queries}} in {{duration .QueryTime}}{{end}}</td>
+</tr>
+{{end}}
+</table>
+{{template "foot"}}{{end}}
+
+{{define "detail"}}{{template "head" .}}
+{{with .Request}}
+<h2>{{.Method}} {{.Path}}{{if .Query}}?{{.Query}}{{end}}</h2>
+{{if .ReplayOf}}<p class="notice">A replay of <a href="{{$.Prefix}}/{{.ReplayOf}}">request {{.ReplayOf}}</a>.</p>{{end}}
+<div>{{if .Replayable}}<form method="post" action="{{$.Prefix}}/{{.ID}}/replay"><input type="hidden" name="csrf_token" value="{{$.CSRF}}"><button type="submit">Replay</button></form>
+{{else}}Its body was too large to keep, so it can't be replayed.{{end}}</div>
+<table>
+<tr><th>Time</th><td>{{.Time.Format "2006-01-02 15:04:05.000 MST"}}</td></tr>
+<tr><th>Status</th><td class="{{if ge .Status 500}}s5{{else if ge .Status 400}}s4{{end}}">{{.Status}}</td></tr>
+<tr><th>Duration</th><td>{{duration .Duration}}</td></tr>
+<tr><th>SQL</th><td>{{len .Queries}} queries in {{duration .QueryTime}}</td></tr>
+<tr><th>Remote address</th><td>{{.RemoteAddr}}</td></tr>
+</table>
+
+<h3>Request headers</h3>
+<table>
+{{range $name, $value := .RequestHeaders}}<tr><th>{{$name}}</th><td>{{$value}}</td></tr>{{end}}
+</table>
+{{if .RequestBody}}<h3>Request body{{if .RequestBodyTruncated}} (truncated){{end}}</h3><pre>{{.RequestBody}}</pre>{{end}}
+
+<h3>Response headers</h3>
+<table>
+{{range $name, $value := .ResponseHeaders}}<tr><th>{{$name}}</th><td>{{$value}}</td></tr>{{end}}
+</table>
+{{if .ResponseBody}}<h3>Response body{{if .ResponseBodyTruncated}} (truncated){{end}}</h3><pre>{{.ResponseBody}}</pre>{{end}}
+
+<h3>SQL</h3>
+{{if .Queries}}
+<table>
+<tr><th>Statement</th><th>Duration</th><th>Rows</th></tr>
+{{range .Queries}}
+<tr>
+<td><code>{{.SQL}}</code>{{if .Vars}}<div><code>{{range $i, $v := .Vars}}{{if $i}}, {{end}}{{$v}}{{end}}</code></div>{{end}}{{if .Error}}<div class="error">{{.Error}}</div>{{end}}</td>
+<td class="num">{{duration .Duration}}</td>
+<td class="num">{{if ge .Rows 0}}{{.Rows}}{{end}}</td>
+</tr>
+{{end}}
+</table>
+{{if .QueriesDropped}}<p class="notice">{{.QueriesDropped}} more queries were not kept.</p>{{end}}
+{{else}}
+<p>No queries ran with the request's context.</p>
+{{end}}
+{{end}}
+{{template "foot"}}{{end}}
+`))
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// MasterKeyEnv is the environment variable holding the key that decrypts enc:
+// values in the settings files
+const MasterKeyEnv = "BOURBON_MASTER_KEY"
+
+// EncryptedPrefix marks a settings value encrypted with EncryptValue
+const EncryptedPrefix = "enc:"
+
+// GenerateMasterKey returns a new random master key, base64 encoded
+func GenerateMasterKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// EncryptValue encrypts plaintext with AES-256-GCM under the base64 master key and
+// returns it as "enc:<base64>", ready to paste into a settings file
+func EncryptValue(plaintext, masterKey string) (string, error) {
+	gcm, err := masterCipher(masterKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return EncryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptValue decrypts a value returned by EncryptValue
+func DecryptValue(value, masterKey string) (string, error) {
+	if !IsEncrypted(value) {
+		return "", fmt.Errorf("value does not start with %q", EncryptedPrefix)
+	}
+	gcm, err := masterCipher(masterKey)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, EncryptedPrefix))
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("cannot decrypt value (wrong master key?)")
+	}
+	return string(plaintext), nil
+}
+
+// IsEncrypted reports whether value was produced by EncryptValue
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, EncryptedPrefix)
+}
+
+func masterCipher(masterKey string) (cipher.AEAD, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(masterKey))
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("master key must be 32 bytes, base64 encoded (generate one with config:key)")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// decryptSetting decrypts an enc: value from a settings file with the master key
+// in the environment
+func decryptSetting(value string) (string, error) {
+	masterKey, ok := os.LookupEnv(MasterKeyEnv)
+	if !ok || masterKey == "" {
+		return "", fmt.Errorf("value is encrypted but %s is not set", MasterKeyEnv)
+	}
+	return DecryptValue(value, masterKey)
+}
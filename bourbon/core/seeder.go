@@ -0,0 +1,118 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// SeederFunc populates the database
+type SeederFunc func(db *gorm.DB) error
+
+// Seeder is a named seeding step that runs after the seeders it depends on
+type Seeder struct {
+	Name      string
+	Run       SeederFunc
+	DependsOn []string
+}
+
+var (
+	seederRegistry = make(map[string]*Seeder)
+	seederMu       sync.RWMutex
+)
+
+// RegisterSeeder registers a seeder, typically from an init function in apps/<app>/seeders:
+//
+//	core.RegisterSeeder("posts", seedPosts, "users")
+func RegisterSeeder(name string, fn SeederFunc, dependsOn ...string) {
+	seederMu.Lock()
+	defer seederMu.Unlock()
+	seederRegistry[name] = &Seeder{Name: name, Run: fn, DependsOn: dependsOn}
+}
+
+// GetSeeders returns all registered seeders in dependency order
+func GetSeeders() ([]*Seeder, error) {
+	return orderSeeders(nil)
+}
+
+// RunSeeders runs the named seeders (and their dependencies) in dependency order,
+// or all seeders when none are named. Each seeder runs in its own transaction.
+// The callback, if non-nil, is called before each seeder runs.
+func RunSeeders(db *gorm.DB, only []string, before func(name string)) error {
+	seeders, err := orderSeeders(only)
+	if err != nil {
+		return err
+	}
+
+	for _, seeder := range seeders {
+		if before != nil {
+			before(seeder.Name)
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error { return seeder.Run(tx) }); err != nil {
+			return fmt.Errorf("seeder %s failed: %w", seeder.Name, err)
+		}
+	}
+	return nil
+}
+
+// orderSeeders topologically sorts the selected seeders and their dependencies.
+// Independent seeders run in alphabetical order so runs are deterministic.
+func orderSeeders(only []string) ([]*Seeder, error) {
+	seederMu.RLock()
+	defer seederMu.RUnlock()
+
+	roots := only
+	if len(roots) == 0 {
+		for name := range seederRegistry {
+			roots = append(roots, name)
+		}
+	}
+	roots = append([]string(nil), roots...)
+	sort.Strings(roots)
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var ordered []*Seeder
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("seeder dependency cycle: %v", append(path, name))
+		}
+
+		seeder, ok := seederRegistry[name]
+		if !ok {
+			if len(path) > 0 {
+				return fmt.Errorf("seeder %s depends on unknown seeder %s", path[len(path)-1], name)
+			}
+			return fmt.Errorf("unknown seeder: %s", name)
+		}
+
+		state[name] = visiting
+		deps := append([]string(nil), seeder.DependsOn...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = done
+		ordered = append(ordered, seeder)
+		return nil
+	}
+
+	for _, name := range roots {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
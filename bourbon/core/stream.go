@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/stream"
+	"go.uber.org/zap"
+)
+
+// RunConsumers runs the consumers registered with stream.Consume on app.Stream,
+// without the HTTP server, until interrupted or sent SIGTERM. It then stops
+// reading and waits for the messages being handled. Empty options are taken
+// from [stream].
+func (a *App) RunConsumers(opts stream.RunOptions) error {
+	opts = a.streamRunOptions(opts)
+	registered := stream.Topics()
+	for _, topic := range opts.Topics {
+		if !slices.Contains(registered, topic) {
+			return fmt.Errorf("no consumer registered for topic %s", topic)
+		}
+	}
+	if a.Config.Stream.Driver == "memory" {
+		a.Logger.Warn("The memory stream broker only delivers messages published by this process")
+	}
+
+	if a.Cache != nil {
+		defer a.Cache.Close()
+	}
+	if a.Jobs != nil {
+		defer a.Jobs.Close()
+	}
+	defer a.Stream.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	a.Logger.Info("Consumers started",
+		zap.Strings("topics", opts.Topics),
+		zap.String("group", opts.Group))
+	if err := stream.Run(ctx, a.Stream, opts); err != nil {
+		return err
+	}
+	a.Logger.Info("Consumers stopped")
+	return nil
+}
+
+// startConsumers runs the registered consumers in the server process when
+// stream.consume_in_server is set. The returned function stops them and waits
+// for the messages being handled.
+func (a *App) startConsumers() func() {
+	if !a.Config.Stream.ConsumeInServer || len(stream.Topics()) == 0 {
+		return func() {}
+	}
+
+	opts := a.streamRunOptions(stream.RunOptions{})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := stream.Run(ctx, a.Stream, opts); err != nil {
+			a.Logger.Error("Consumers failed", zap.Error(err))
+		}
+	}()
+	a.Logger.Info("Consumers started",
+		zap.Strings("topics", opts.Topics),
+		zap.String("group", opts.Group))
+
+	return func() {
+		cancel()
+		<-done
+		a.Logger.Info("Consumers stopped")
+	}
+}
+
+// streamRunOptions fills empty options from [stream]; the group defaults to app.name
+func (a *App) streamRunOptions(opts stream.RunOptions) stream.RunOptions {
+	if opts.Group == "" {
+		opts.Group = a.Config.Stream.Group
+	}
+	if opts.Group == "" {
+		opts.Group = a.Config.App.Name
+	}
+	if len(opts.Topics) == 0 {
+		opts.Topics = stream.Topics()
+	}
+	if opts.Logger == nil {
+		opts.Logger = a.Logger
+	}
+	return opts
+}
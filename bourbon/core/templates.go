@@ -0,0 +1,37 @@
+package core
+
+import (
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+)
+
+// TemplateContext adds data to every template rendered with ctx.Render, Django
+// context-processor style. The data passed to Render overrides processor keys.
+//
+//	app.TemplateContext(func(c *http.Context) http.H {
+//	    return http.H{"cart_count": carts.Count(c.Request.Context())}
+//	})
+func (a *App) TemplateContext(fn func(c *bourbon.Context) bourbon.H) {
+	a.Router.TemplateContext(fn)
+}
+
+// templateDefaults is the built-in context processor. Templates get:
+//
+//	.app      name, env and debug from [app]
+//	.user_id  the authenticated user's ID, when there is one
+//	.flashes  the pending flash messages, which are cleared by rendering them
+//	.timezone the request's timezone, for dateIn
+func (a *App) templateDefaults(c *bourbon.Context) bourbon.H {
+	data := bourbon.H{
+		"app": bourbon.H{
+			"name":  a.Config.App.Name,
+			"env":   a.Config.App.Env,
+			"debug": a.Config.App.Debug,
+		},
+		"flashes":  c.Flashes(),
+		"timezone": c.Location(),
+	}
+	if userID, ok := c.UserID(); ok {
+		data["user_id"] = userID
+	}
+	return data
+}
@@ -0,0 +1,39 @@
+package core
+
+import (
+	"time"
+
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+	"go.uber.org/zap"
+)
+
+// initTimezone loads app.timezone. Unknown names fall back to UTC with a warning;
+// binaries deployed without a zoneinfo database can embed one with
+// import _ "time/tzdata".
+func (a *App) initTimezone() {
+	loc, err := bourbon.LoadLocation(a.Config.App.Timezone)
+	if err != nil {
+		a.Logger.Warn("Unknown app timezone, using UTC", zap.String("timezone", a.Config.App.Timezone), zap.Error(err))
+		loc = time.UTC
+	}
+	a.Location = loc
+	a.Router.Location = loc
+}
+
+// Now returns the current time in the application timezone
+func (a *App) Now() time.Time {
+	return time.Now().In(a.location())
+}
+
+// Localize converts t to the application timezone, e.g. a UTC timestamp read from
+// the database. Handlers use ctx.Localize, which honours per-user timezones.
+func (a *App) Localize(t time.Time) time.Time {
+	return t.In(a.location())
+}
+
+func (a *App) location() *time.Location {
+	if a.Location == nil {
+		return time.UTC
+	}
+	return a.Location
+}
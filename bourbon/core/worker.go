@@ -0,0 +1,50 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/jobs"
+	"go.uber.org/zap"
+)
+
+// RunWorker runs the jobs pushed to app.Queue, without the HTTP server, until
+// interrupted or sent SIGTERM. It then stops taking jobs and waits for the
+// running ones, and for the jobs they queued on app.Jobs. Empty options are
+// taken from [jobs].
+func (a *App) RunWorker(opts jobs.WorkOptions) error {
+	if a.DB == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	if len(opts.Queues) == 0 {
+		opts.Queues = a.Config.Jobs.Queues
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = a.Config.Jobs.Concurrency
+	}
+
+	if a.Cache != nil {
+		defer a.Cache.Close()
+	}
+	if a.Jobs != nil {
+		defer a.Jobs.Close()
+	}
+	if err := a.Queue.Migrate(); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	a.Logger.Info("Worker started",
+		zap.Strings("queues", opts.Queues),
+		zap.Int("concurrency", opts.Concurrency))
+	if err := a.Queue.Work(ctx, opts); err != nil {
+		return err
+	}
+	a.Logger.Info("Worker stopped")
+	return nil
+}
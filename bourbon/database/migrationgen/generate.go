@@ -0,0 +1,518 @@
+// Package migrationgen generates migrations from model changes. It compares the
+// models of each app (see ScanModels) with the state recorded in .bourbon when the
+// last migration was generated, and writes a Go migration with the schema
+// operations in between. Both the bourbon CLI and an application's own
+// make:migration command use it.
+package migrationgen
+
+import (
+	"fmt"
+	"go/parser"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/gomod"
+)
+
+// Input says how the questions asked while generating a migration are answered
+type Input int
+
+const (
+	// Ask asks on stdin
+	Ask Input = iota
+	// NoInput never reads stdin, for CI: renames are not assumed, new NOT NULL
+	// columns are backfilled with the suggested value, and destructive changes
+	// are refused
+	NoInput
+	// AssumeYes is NoInput that goes ahead with destructive changes
+	AssumeYes
+)
+
+// Migration is a migration a Generator created
+type Migration struct {
+	App         string   `json:"app"`
+	ID          string   `json:"id"`
+	Kind        string   `json:"kind"` // schema, data or sql
+	Files       []string `json:"files"`
+	Models      []string `json:"models,omitempty"`
+	Destructive bool     `json:"destructive,omitempty"`
+}
+
+// Generator generates migrations. The zero value asks its questions on stdin,
+// as GenerateMigrations does.
+type Generator struct {
+	Input Input
+	Force bool // create an empty migration when the models haven't changed
+
+	// Created lists the migrations written, in order
+	Created []Migration
+}
+
+// GenerateMigration creates a new migration file in the default app
+func GenerateMigration(name string) error {
+	// Find the default app (first app in apps/ directory)
+	appName, err := DefaultApp()
+	if err != nil {
+		return err
+	}
+
+	return GenerateMigrationForApp(appName, name, false)
+}
+
+// GenerateMigrations creates a migration for each app whose models changed. With
+// force, apps with models but no changes get an empty migration.
+func GenerateMigrations(name string, force bool) error {
+	return (&Generator{Force: force}).All(name)
+}
+
+// GenerateMigrationForApp creates a new migration file for a specific app. With
+// force, an empty migration is created when the models have not changed.
+func GenerateMigrationForApp(appName, name string, force bool) error {
+	return (&Generator{Force: force}).App(appName, name)
+}
+
+// GenerateDataMigration creates an empty Go migration for changing rows rather
+// than the schema, e.g. backfills and transforms. It runs and is tracked like any
+// other migration, but leaves the model state alone.
+func GenerateDataMigration(appName, name string) error {
+	return (&Generator{}).Data(appName, name)
+}
+
+// GenerateSQLMigration creates empty up and down SQL files for an app. They run
+// with the Go migrations, in timestamp order, and are tracked the same way.
+func GenerateSQLMigration(appName, name string) error {
+	return (&Generator{}).SQL(appName, name)
+}
+
+// All creates a migration for each app whose models changed
+func (g *Generator) All(name string) error {
+	entries, err := os.ReadDir("apps")
+	if err != nil {
+		return fmt.Errorf("apps directory not found. Are you in the project root?")
+	}
+
+	found := false
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		models, err := ScanModels(entry.Name())
+		if err != nil {
+			return fmt.Errorf("%s: failed to scan models: %w", entry.Name(), err)
+		}
+		if len(models) == 0 {
+			continue
+		}
+		found = true
+
+		fmt.Printf("%s:\n", entry.Name())
+		if err := g.App(entry.Name(), name); err != nil {
+			return fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+	}
+	if !found {
+		return fmt.Errorf("no models found in apps/* - create models first")
+	}
+	return nil
+}
+
+// App creates a migration for the changes to an app's models
+func (g *Generator) App(appName, name string) error {
+	// Scan models to detect changes
+	models, err := ScanModels(appName)
+	if err != nil {
+		return fmt.Errorf("failed to scan models: %w", err)
+	}
+
+	if len(models) == 0 {
+		return fmt.Errorf("no models found in apps/%s - create models first", appName)
+	}
+
+	// Apps whose migrations an earlier bourbon CLI generated have no state yet
+	if err := adoptLegacyState(appName, models); err != nil {
+		return fmt.Errorf("failed to adopt %s: %w", legacyHashFile, err)
+	}
+
+	// Detect all changes
+	changes, err := DetectAllChanges(appName, models)
+	if err != nil {
+		return fmt.Errorf("failed to detect changes: %w", err)
+	}
+
+	if !changes.HasChanges() {
+		if g.Force {
+			if name == "" {
+				name = "empty"
+			}
+			return g.Data(appName, name)
+		}
+		fmt.Println("No changes detected - models are up to date")
+		return nil
+	}
+
+	// A deleted model with a new model of the same fields, or a deleted field with a
+	// new field of the same type and tag, may be a rename, which keeps the data
+	changes.ResolveModelRenames(g.confirmModelRename)
+	changes.ResolveRenames(g.confirmRename)
+
+	// New NOT NULL columns without a default need a value for existing rows
+	changes.ResolveBackfills(g.askBackfill)
+
+	for _, modelName := range changes.underivedReferences() {
+		fmt.Printf("Warning: foreign keys to %s reference table %s instead of %s; edit the generated constraints or rename the table\n",
+			modelName, toSnakeCase(changes.structName(modelName)), changes.tableName(modelName))
+	}
+
+	// Show all destructive changes and ask for confirmation
+	if changes.HasDestructiveChanges() {
+		fmt.Println("\nWARNING: Destructive changes detected!")
+
+		if len(changes.DeletedModels) > 0 {
+			fmt.Println("\nModels to be DELETED:")
+			for _, modelName := range changes.DeletedModels {
+				fmt.Printf("  - %s (table: %s)\n", modelName, changes.tableName(modelName))
+			}
+		}
+
+		if len(changes.DeletedFields) > 0 {
+			fmt.Println("\nFields to be DELETED:")
+			for modelName, fields := range changes.DeletedFields {
+				fmt.Printf("  Model: %s\n", modelName)
+				for _, field := range fields {
+					fmt.Printf("    - %s %s\n", field.Name, field.Type)
+				}
+			}
+		}
+
+		if typeChanges := changes.TypeChanges(); len(typeChanges) > 0 {
+			fmt.Println("\nFields to be ALTERED (existing values are converted to the new type):")
+			for modelName, fieldChanges := range typeChanges {
+				fmt.Printf("  Model: %s\n", modelName)
+				for _, change := range fieldChanges {
+					fmt.Printf("    - %s %s → %s\n", change.To.Name, change.From.Type, change.To.Type)
+				}
+			}
+		}
+
+		if len(changes.DroppedJoinTables) > 0 {
+			fmt.Println("\nJoin tables to be DELETED:")
+			for _, joinTable := range changes.DroppedJoinTables {
+				fmt.Printf("  - %s\n", joinTable.Name)
+			}
+		}
+
+		fmt.Println("\nThese changes CANNOT be undone!")
+		switch g.Input {
+		case NoInput:
+			return fmt.Errorf("destructive changes need confirmation: run again with --yes to accept them")
+		case AssumeYes:
+			fmt.Println("Continuing (--yes)")
+		default:
+			fmt.Print("\nContinue? (y/N): ")
+
+			var response string
+			fmt.Scanln(&response)
+
+			if strings.ToLower(response) != "y" {
+				fmt.Println("Migration cancelled.")
+				return nil
+			}
+		}
+	}
+
+	// Create migrations directory if it doesn't exist
+	migrationsDir := filepath.Join("apps", appName, "migrations")
+	if err := os.MkdirAll(migrationsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create migrations directory: %w", err)
+	}
+
+	// Generate filename and migration ID
+	migrationID := newMigrationID(name)
+	fileName := migrationID + ".go"
+
+	filePath := filepath.Join(migrationsDir, fileName)
+
+	// Generate migration code following gormigrate best practices
+	migrateCode := GenerateMigrationCodeFromChanges(changes)
+	rollbackCode := GenerateRollbackCodeFromChanges(changes)
+
+	// Check if we need time import (BaseModel timestamps or time.Time fields)
+	timeImport := ""
+	if changes.UsesTimePackage() {
+		timeImport = "\t\"time\"\n\n"
+	}
+
+	// Migration template following gormigrate best practices
+	template := fmt.Sprintf(`package migrations
+
+import (
+%s	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	"gorm.io/gorm"
+)
+
+func init() {
+	core.RegisterAppMigration(%q, &gormigrate.Migration{
+		ID: %q,
+		Migrate: func(tx *gorm.DB) error {
+%s
+		},
+		Rollback: func(tx *gorm.DB) error {
+%s
+		},
+	})
+}
+`, timeImport, appName, migrationID, migrateCode, rollbackCode)
+
+	// Write file
+	if err := writeGoFile(filePath, template); err != nil {
+		return fmt.Errorf("failed to write migration file: %w", err)
+	}
+
+	// Update migration state
+	if err := UpdateMigrationState(appName, models, migrationID); err != nil {
+		return fmt.Errorf("failed to update migration state: %w", err)
+	}
+
+	fmt.Printf("Created migration: %s\n", filePath)
+	fmt.Printf("  Models: %s\n", getModelNames(models))
+	migration := Migration{App: appName, ID: migrationID, Kind: "schema", Files: []string{filePath}, Destructive: changes.HasDestructiveChanges()}
+	for _, model := range models {
+		migration.Models = append(migration.Models, model.Name)
+	}
+	g.Created = append(g.Created, migration)
+	return nil
+}
+
+// Data creates an empty data migration for an app, see GenerateDataMigration
+func (g *Generator) Data(appName, name string) error {
+	if name == "" {
+		return fmt.Errorf("usage: make:migration <name> --data [--app=<app>]")
+	}
+
+	migrationsDir := filepath.Join("apps", appName, "migrations")
+	if err := os.MkdirAll(migrationsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create migrations directory: %w", err)
+	}
+
+	migrationID := newMigrationID(name)
+	filePath := filepath.Join(migrationsDir, migrationID+".go")
+
+	template := fmt.Sprintf(`package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	"gorm.io/gorm"
+)
+
+// Data migration: changes rows, not the schema
+func init() {
+	core.RegisterAppMigration(%q, &gormigrate.Migration{
+		ID: %q,
+		Migrate: func(tx *gorm.DB) error {
+			// Declare the columns you need and walk the table in batches, e.g.
+			//
+			//	type user struct {
+			//		ID    uint
+			//		Email string
+			//	}
+			//	return core.EachBatch(tx, 1000, func(tx *gorm.DB, users []user) error {
+			//		for _, u := range users {
+			//			email := strings.ToLower(u.Email)
+			//			if err := tx.Model(&u).Update("email", email).Error; err != nil {
+			//				return err
+			//			}
+			//		}
+			//		return nil
+			//	})
+			//
+			// For other loops, core.NewProgress logs how far the migration got.
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			// Undo the change, or return an error if it cannot be undone
+			return nil
+		},
+	})
+}
+`, appName, migrationID)
+
+	if err := writeGoFile(filePath, template); err != nil {
+		return fmt.Errorf("failed to write migration file: %w", err)
+	}
+
+	fmt.Printf("Created data migration: %s\n", filePath)
+	g.Created = append(g.Created, Migration{App: appName, ID: migrationID, Kind: "data", Files: []string{filePath}})
+	return nil
+}
+
+// writeGoFile writes a generated migration with its framework imports moved to
+// the path the project's go.mod requires the framework under, and prints a hint
+// when go.mod lacks a module it imports
+func writeGoFile(path, source string) error {
+	mod, err := gomod.Read(".")
+	if err != nil {
+		return os.WriteFile(path, []byte(source), 0644)
+	}
+	if err := os.WriteFile(path, mod.Rewrite([]byte(source)), 0644); err != nil {
+		return err
+	}
+	mod.Verify(path)
+	return nil
+}
+
+// SQL creates empty up and down SQL files for an app, see GenerateSQLMigration
+func (g *Generator) SQL(appName, name string) error {
+	if name == "" {
+		return fmt.Errorf("usage: make:migration <name> --sql [--app=<app>]")
+	}
+
+	migrationsDir := filepath.Join("apps", appName, "migrations")
+	if err := os.MkdirAll(migrationsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create migrations directory: %w", err)
+	}
+
+	migrationID := newMigrationID(name)
+
+	files := map[string]string{
+		migrationID + ".up.sql":   fmt.Sprintf("-- %s: applied by migrate\n\n", migrationID),
+		migrationID + ".down.sql": fmt.Sprintf("-- %s: reverted by migrate:rollback\n\n", migrationID),
+	}
+	migration := Migration{App: appName, ID: migrationID, Kind: "sql"}
+	for _, fileName := range []string{migrationID + ".up.sql", migrationID + ".down.sql"} {
+		filePath := filepath.Join(migrationsDir, fileName)
+		if err := os.WriteFile(filePath, []byte(files[fileName]), 0644); err != nil {
+			return fmt.Errorf("failed to write migration file: %w", err)
+		}
+		fmt.Printf("Created migration: %s\n", filePath)
+		migration.Files = append(migration.Files, filePath)
+	}
+	g.Created = append(g.Created, migration)
+	return nil
+}
+
+// newMigrationID returns <timestamp>_<name>, or just the timestamp without a
+// name. IDs must be unique across apps, so the timestamp moves on a second while
+// a migration file of any app already uses it, e.g. when several apps get a
+// migration at once.
+func newMigrationID(name string) string {
+	suffix := ""
+	if name != "" {
+		suffix = "_" + strings.ToLower(strings.ReplaceAll(name, " ", "_"))
+	}
+	at := time.Now()
+	for {
+		migrationID := at.Format("20060102150405") + suffix
+		if matches, _ := filepath.Glob(filepath.Join("apps", "*", "migrations", migrationID+".*")); len(matches) == 0 {
+			return migrationID
+		}
+		at = at.Add(time.Second)
+	}
+}
+
+// askBackfill asks for the Go expression existing rows get in a new NOT NULL column
+func (g *Generator) askBackfill(modelName string, field FieldInfo, suggestion string) string {
+	fmt.Printf("%s.%s (%s) is NOT NULL without a default, so existing rows need a value.\n", modelName, field.Name, field.Type)
+	if g.Input != Ask {
+		if suggestion != "" {
+			fmt.Printf("Filling them with %s\n", suggestion)
+		}
+		return suggestion
+	}
+	for {
+		if suggestion != "" {
+			fmt.Printf("Go expression to fill them with [%s]: ", suggestion)
+		} else {
+			fmt.Print("Go expression to fill them with: ")
+		}
+
+		value, eof := readLine()
+		if value == "" {
+			value = suggestion
+		}
+		if value != "" {
+			if _, err := parser.ParseExpr(value); err == nil {
+				return value
+			}
+			fmt.Printf("%q is not a Go expression\n", value)
+		}
+		if eof {
+			// Without input, add the column as declared; that fails on a table with rows
+			return ""
+		}
+	}
+}
+
+// readLine reads a line from stdin a byte at a time, so later prompts reading
+// with fmt.Scanln still see their own answers
+func readLine() (string, bool) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(b)
+		if n == 0 || err != nil {
+			return strings.TrimSpace(string(line)), true
+		}
+		if b[0] == '\n' {
+			return strings.TrimSpace(string(line)), false
+		}
+		line = append(line, b[0])
+	}
+}
+
+// confirmModelRename asks whether a model was renamed rather than replaced
+func (g *Generator) confirmModelRename(rename ModelRename) bool {
+	fmt.Printf("Did you rename model %s → %s (table %s → %s)? [y/N]: ",
+		rename.From, rename.To.Name, rename.FromTable, rename.To.Table())
+	if g.Input != Ask {
+		fmt.Println("N (no input)")
+		return false
+	}
+
+	var response string
+	fmt.Scanln(&response)
+	return strings.ToLower(response) == "y"
+}
+
+// confirmRename asks whether a field was renamed rather than replaced
+func (g *Generator) confirmRename(modelName string, rename FieldRename) bool {
+	fmt.Printf("Did you rename %s.%s → %s.%s (%s)? [y/N]: ",
+		modelName, rename.From.Name, modelName, rename.To.Name, rename.To.Type)
+	if g.Input != Ask {
+		fmt.Println("N (no input)")
+		return false
+	}
+
+	var response string
+	fmt.Scanln(&response)
+	return strings.ToLower(response) == "y"
+}
+
+// getModelNames returns a comma-separated list of model names
+func getModelNames(models []ModelInfo) string {
+	names := make([]string, len(models))
+	for i, model := range models {
+		names[i] = model.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// DefaultApp returns the first app found in apps/ directory
+func DefaultApp() (string, error) {
+	appsDir := "apps"
+	entries, err := os.ReadDir(appsDir)
+	if err != nil {
+		return "", fmt.Errorf("apps directory not found. Are you in the project root?")
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			return entry.Name(), nil
+		}
+	}
+
+	return "", fmt.Errorf("no apps found in apps/ directory")
+}
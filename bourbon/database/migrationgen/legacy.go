@@ -0,0 +1,121 @@
+package migrationgen
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// legacyHashFile is where earlier versions of the bourbon CLI kept an md5 of
+// models.go, instead of the per-field state in .bourbon. Their migrations are
+// named 0001_<name>.go and copy every model's struct, with BaseModel expanded.
+const legacyHashFile = ".models_hash"
+
+// legacyMigrationFile matches the file names of those migrations
+var legacyMigrationFile = regexp.MustCompile(`^\d{4}_.+\.go$`)
+
+// baseModelFields are the fields the legacy generator expanded BaseModel into
+var baseModelFields = map[string]string{
+	"ID":        "uint",
+	"CreatedAt": "time.Time",
+	"UpdatedAt": "time.Time",
+	"DeletedAt": "gorm.DeletedAt",
+}
+
+// adoptLegacyState records the models an app's legacy migrations created as its
+// migration state, so the next migration only contains what changed since. The
+// models come from models.go while its hash still matches, and otherwise from the
+// last legacy migration. The hash file is removed afterwards.
+func adoptLegacyState(appName string, models []ModelInfo) error {
+	migrationsDir := filepath.Join("apps", appName, "migrations")
+	hashPath := filepath.Join(migrationsDir, legacyHashFile)
+	hash, err := os.ReadFile(hashPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	state, err := LoadMigrationState()
+	if err != nil {
+		return err
+	}
+	if state.Apps[appName] == nil {
+		lastMigration := latestLegacyMigration(migrationsDir)
+
+		// Models moved out of models.go count as changed
+		baseline := models
+		content, err := os.ReadFile(filepath.Join("apps", appName, "models.go"))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		sum := md5.Sum(content)
+		if err != nil || hex.EncodeToString(sum[:]) != strings.TrimSpace(string(hash)) {
+			if lastMigration == "" {
+				return fmt.Errorf("models.go changed since the last migration, which was not found in %s", migrationsDir)
+			}
+			baseline, err = legacyMigratedModels(filepath.Join(migrationsDir, lastMigration))
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := UpdateMigrationState(appName, baseline, strings.TrimSuffix(lastMigration, ".go")); err != nil {
+			return err
+		}
+		fmt.Printf("Adopted %s from %s: %s\n", appName, legacyHashFile, getModelNames(baseline))
+	}
+	return os.Remove(hashPath)
+}
+
+// latestLegacyMigration returns the file name of the highest numbered legacy
+// migration, or "" when there is none
+func latestLegacyMigration(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && legacyMigrationFile.MatchString(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	return names[len(names)-1]
+}
+
+// legacyMigratedModels reads the models a legacy migration created, folding the
+// expanded BaseModel fields back into the embedded BaseModel
+func legacyMigratedModels(path string) ([]ModelInfo, error) {
+	models, err := scanModelFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, model := range models {
+		var fields []FieldInfo
+		expanded := 0
+		for _, field := range model.Fields {
+			if baseModelFields[field.Name] == field.Type {
+				expanded++
+				continue
+			}
+			fields = append(fields, field)
+		}
+		if expanded == len(baseModelFields) {
+			models[i].Fields = fields
+			models[i].HasBaseModel = true
+		}
+	}
+	return models, nil
+}
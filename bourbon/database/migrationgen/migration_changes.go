@@ -0,0 +1,522 @@
+package migrationgen
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MigrationChanges represents all types of changes detected
+type MigrationChanges struct {
+	NewModels      []ModelInfo              // Completely new models
+	DeletedModels  []string                 // Model names that were deleted
+	NewFields      map[string][]FieldInfo   // modelName -> new fields
+	DeletedFields  map[string][]FieldInfo   // modelName -> deleted fields
+	ModifiedFields map[string][]FieldChange // modelName -> modified fields (type or tag changed)
+	RenamedFields  map[string][]FieldRename // modelName -> renamed fields, confirmed by ResolveRenames
+	RenamedModels  []ModelRename            // Renamed models, confirmed by ResolveModelRenames
+	NewIndexes     map[string][]IndexInfo   // modelName -> indexes to create (new or changed)
+	DroppedIndexes map[string][]IndexInfo   // modelName -> indexes to drop (removed or changed)
+	NewChecks      map[string][]FieldInfo   // modelName -> fields of existing columns whose check constraint is created
+	DroppedChecks  map[string][]FieldInfo   // modelName -> fields of existing columns whose check constraint is dropped
+
+	TableForeignKeys   map[string][]ForeignKeyInfo // modelName -> foreign keys created with the new model's table
+	NewForeignKeys     []ForeignKeyInfo            // Foreign keys added to existing tables
+	DroppedForeignKeys []ForeignKeyInfo            // Foreign keys dropped from remaining tables
+	NewJoinTables      []JoinTableInfo             // many2many join tables to create
+	DroppedJoinTables  []JoinTableInfo             // many2many join tables to drop
+
+	Backfills map[string]map[string]string // modelName -> field -> Go expression filling existing rows of a new NOT NULL column
+
+	RenamedTables map[string]string // modelName -> previous table name, when the model's TableName changed
+
+	deletedModelFields map[string][]FieldInfo // fields of deleted models, from the stored state
+	tables             map[string]string      // modelName -> table name of the current models
+	storedTables       map[string]string      // modelName -> table name of the stored models
+}
+
+// ModelRename is a model whose name changed while its fields stayed the same
+type ModelRename struct {
+	From      string
+	FromTable string
+	To        ModelInfo
+}
+
+// FieldRename is a field whose name changed while its type and tag stayed the same
+type FieldRename struct {
+	From FieldInfo
+	To   FieldInfo
+}
+
+// FieldChange is a field whose type or tag changed
+type FieldChange struct {
+	From FieldInfo
+	To   FieldInfo
+}
+
+// columnSettings are the gorm tag settings that define a column, as opposed to
+// indexes and constraints, which are migrated on their own
+var columnSettings = []string{"type", "size", "precision", "scale", "not null", "default", "autoIncrement", "serializer"}
+
+// AltersColumn reports whether the change needs an AlterColumn: the type or a
+// setting defining the column changed
+func (c FieldChange) AltersColumn() bool {
+	if c.From.Type != c.To.Type {
+		return true
+	}
+	for _, key := range columnSettings {
+		from, hadSetting := c.From.GormSetting(key)
+		to, hasSetting := c.To.GormSetting(key)
+		if hadSetting != hasSetting || from != to {
+			return true
+		}
+	}
+	return false
+}
+
+// AlteredFields returns the modified fields that need an AlterColumn
+func (c *MigrationChanges) AlteredFields() map[string][]FieldChange {
+	altered := make(map[string][]FieldChange)
+	for modelName, fieldChanges := range c.ModifiedFields {
+		for _, change := range fieldChanges {
+			if change.AltersColumn() {
+				altered[modelName] = append(altered[modelName], change)
+			}
+		}
+	}
+	return altered
+}
+
+// DetectAllChanges performs comprehensive change detection
+func DetectAllChanges(appName string, currentModels []ModelInfo) (*MigrationChanges, error) {
+	changes := &MigrationChanges{
+		NewModels:      []ModelInfo{},
+		DeletedModels:  []string{},
+		NewFields:      make(map[string][]FieldInfo),
+		DeletedFields:  make(map[string][]FieldInfo),
+		ModifiedFields: make(map[string][]FieldChange),
+		RenamedFields:  make(map[string][]FieldRename),
+		Backfills:      make(map[string]map[string]string),
+		NewIndexes:     make(map[string][]IndexInfo),
+		DroppedIndexes: make(map[string][]IndexInfo),
+		NewChecks:      make(map[string][]FieldInfo),
+		DroppedChecks:  make(map[string][]FieldInfo),
+
+		TableForeignKeys: make(map[string][]ForeignKeyInfo),
+		RenamedTables:    make(map[string]string),
+
+		deletedModelFields: make(map[string][]FieldInfo),
+		tables:             make(map[string]string),
+		storedTables:       make(map[string]string),
+	}
+
+	state, err := LoadMigrationState()
+	if err != nil {
+		return nil, err
+	}
+
+	var storedModels []ModelInfo
+	if appState := state.Apps[appName]; appState != nil {
+		for _, stored := range appState.Models {
+			model := ModelInfo{Name: stored.Name, TableName: stored.Table}
+			for _, f := range stored.Fields {
+				model.Fields = append(model.Fields, fieldFromState(f))
+			}
+			storedModels = append(storedModels, model)
+			changes.storedTables[model.Name] = model.Table()
+		}
+		sort.Slice(storedModels, func(i, j int) bool {
+			return storedModels[i].Name < storedModels[j].Name
+		})
+	}
+
+	for _, model := range currentModels {
+		changes.tables[model.Name] = model.Table()
+	}
+
+	// Relations become foreign keys and join tables; the remaining fields are columns
+	changes.diffRelations(storedModels, currentModels)
+	storedColumns := withoutRelations(storedModels)
+	currentColumns := withoutRelations(currentModels)
+
+	// If no previous state, everything is new
+	if state.Apps[appName] == nil {
+		changes.NewModels = changes.orderNewModels(currentColumns)
+		return changes, nil
+	}
+
+	// Create lookups
+	currentModelMap := make(map[string]*ModelInfo)
+	for i := range currentColumns {
+		currentModelMap[currentColumns[i].Name] = &currentColumns[i]
+	}
+
+	storedModelMap := make(map[string]*ModelInfo)
+	for i := range storedColumns {
+		storedModelMap[storedColumns[i].Name] = &storedColumns[i]
+	}
+
+	// Detect new and modified models
+	for _, current := range currentColumns {
+		stored, exists := storedModelMap[current.Name]
+		if !exists {
+			// Completely new model
+			changes.NewModels = append(changes.NewModels, current)
+			continue
+		}
+
+		// A changed TableName moves the model to another table
+		if stored.Table() != current.Table() {
+			changes.RenamedTables[current.Name] = stored.Table()
+		}
+
+		// Model exists, check fields
+		storedFieldMap := make(map[string]FieldInfo)
+		for _, f := range stored.Fields {
+			storedFieldMap[f.Name] = f
+		}
+
+		currentFieldMap := make(map[string]FieldInfo)
+		for _, f := range current.Fields {
+			currentFieldMap[f.Name] = f
+		}
+
+		// Detect new and modified fields
+		for _, currentField := range current.Fields {
+			storedField, exists := storedFieldMap[currentField.Name]
+			if !exists {
+				// New field
+				changes.NewFields[current.Name] = append(changes.NewFields[current.Name], currentField)
+			} else if storedField.Type != currentField.Type || storedField.Tag != currentField.Tag {
+				// Modified field
+				changes.ModifiedFields[current.Name] = append(changes.ModifiedFields[current.Name], FieldChange{From: storedField, To: currentField})
+				changes.diffCheck(current.Name, storedField, currentField)
+			}
+		}
+
+		// Detect new, changed and removed indexes
+		changes.diffIndexes(current.Name, stored.Fields, current.Fields)
+
+		// Detect deleted fields
+		for _, storedField := range stored.Fields {
+			if _, exists := currentFieldMap[storedField.Name]; !exists {
+				// Deleted field
+				changes.DeletedFields[current.Name] = append(changes.DeletedFields[current.Name], storedField)
+			}
+		}
+	}
+	changes.NewModels = changes.orderNewModels(changes.NewModels)
+
+	// Detect deleted models
+	for _, stored := range storedColumns {
+		if _, exists := currentModelMap[stored.Name]; !exists {
+			changes.DeletedModels = append(changes.DeletedModels, stored.Name)
+			changes.deletedModelFields[stored.Name] = stored.Fields
+		}
+	}
+	changes.DeletedModels = orderDeletedModels(changes.DeletedModels, storedModels)
+	changes.withoutDeletedTables()
+
+	return changes, nil
+}
+
+func fieldFromState(f FieldState) FieldInfo {
+	return FieldInfo{Name: f.Name, Type: f.Type, Tag: f.Tag}
+}
+
+// diffIndexes records the indexes to create and drop for a model. A changed index is
+// dropped and created again.
+func (c *MigrationChanges) diffIndexes(modelName string, stored, current []FieldInfo) {
+	before := ModelIndexes(c.storedTables[modelName], stored)
+	after := ModelIndexes(c.tableName(modelName), current)
+
+	definitions := make(map[string]string, len(before))
+	for _, index := range before {
+		definitions[index.Name] = index.definition
+	}
+	for _, index := range after {
+		if definition, ok := definitions[index.Name]; !ok || definition != index.definition {
+			c.NewIndexes[modelName] = append(c.NewIndexes[modelName], index)
+		}
+	}
+
+	definitions = make(map[string]string, len(after))
+	for _, index := range after {
+		definitions[index.Name] = index.definition
+	}
+	for _, index := range before {
+		if definition, ok := definitions[index.Name]; !ok || definition != index.definition {
+			c.DroppedIndexes[modelName] = append(c.DroppedIndexes[modelName], index)
+		}
+	}
+}
+
+// diffCheck records a check constraint added, removed or changed on an existing column
+func (c *MigrationChanges) diffCheck(modelName string, stored, current FieldInfo) {
+	storedCheck, hadCheck := stored.GormSetting("check")
+	currentCheck, hasCheck := current.GormSetting("check")
+	if hadCheck == hasCheck && storedCheck == currentCheck {
+		return
+	}
+	if hadCheck {
+		c.DroppedChecks[modelName] = append(c.DroppedChecks[modelName], stored)
+	}
+	if hasCheck {
+		c.NewChecks[modelName] = append(c.NewChecks[modelName], current)
+	}
+}
+
+// HasChanges returns true if there are any changes
+func (c *MigrationChanges) HasChanges() bool {
+	return len(c.NewModels) > 0 ||
+		len(c.DeletedModels) > 0 ||
+		len(c.NewFields) > 0 ||
+		len(c.DeletedFields) > 0 ||
+		len(c.ModifiedFields) > 0 ||
+		len(c.RenamedFields) > 0 ||
+		len(c.RenamedModels) > 0 ||
+		len(c.RenamedTables) > 0 ||
+		len(c.NewIndexes) > 0 ||
+		len(c.DroppedIndexes) > 0 ||
+		len(c.NewChecks) > 0 ||
+		len(c.DroppedChecks) > 0 ||
+		len(c.TableForeignKeys) > 0 ||
+		len(c.NewForeignKeys) > 0 ||
+		len(c.DroppedForeignKeys) > 0 ||
+		len(c.NewJoinTables) > 0 ||
+		len(c.DroppedJoinTables) > 0
+}
+
+// ResolveModelRenames finds deleted models whose fields match a new model exactly
+// and asks confirm about each. Confirmed pairs become table renames instead of a
+// drop and a create, keeping the table's data.
+func (c *MigrationChanges) ResolveModelRenames(confirm func(rename ModelRename) bool) {
+	var deleted []string
+	for _, from := range c.DeletedModels {
+		fromHash := ComputeFieldSetHash(c.deletedModelFields[from])
+
+		renamed := false
+		for i, to := range c.NewModels {
+			if ComputeFieldSetHash(to.Fields) != fromHash {
+				continue
+			}
+			if rename := (ModelRename{From: from, FromTable: c.tableName(from), To: to}); confirm(rename) {
+				c.RenamedModels = append(c.RenamedModels, rename)
+				c.NewModels = append(c.NewModels[:i:i], c.NewModels[i+1:]...)
+				// The table keeps its foreign keys when renamed
+				delete(c.TableForeignKeys, to.Name)
+				renamed = true
+				break
+			}
+		}
+		if !renamed {
+			deleted = append(deleted, from)
+		}
+	}
+	c.DeletedModels = deleted
+}
+
+// ResolveBackfills asks for the value existing rows get in each new NOT NULL
+// column without a default, suggesting the zero value of simple types. The column
+// is then added nullable, filled, and made NOT NULL, since adding it directly fails
+// on a table with rows.
+func (c *MigrationChanges) ResolveBackfills(ask func(modelName string, field FieldInfo, suggestion string) string) {
+	models := make([]string, 0, len(c.NewFields))
+	for modelName := range c.NewFields {
+		models = append(models, modelName)
+	}
+	sort.Strings(models)
+
+	for _, modelName := range models {
+		for _, field := range c.NewFields[modelName] {
+			if !field.NeedsBackfill() {
+				continue
+			}
+			if c.Backfills[modelName] == nil {
+				c.Backfills[modelName] = make(map[string]string)
+			}
+			c.Backfills[modelName][field.Name] = ask(modelName, field, zeroValue(field.Type))
+		}
+	}
+}
+
+// zeroValue returns the Go zero value literal of simple types, or ""
+func zeroValue(typ string) string {
+	switch typ {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64", "byte", "rune":
+		return "0"
+	case "time.Time":
+		return "time.Now()"
+	}
+	return ""
+}
+
+// ResolveRenames finds deleted fields that look renamed, i.e. a new field of the same
+// model has the same type and tag, and asks confirm about each, most similar names
+// first. Confirmed pairs become renames instead of a drop and an add, keeping the
+// column's data.
+func (c *MigrationChanges) ResolveRenames(confirm func(modelName string, rename FieldRename) bool) {
+	models := make([]string, 0, len(c.DeletedFields))
+	for modelName := range c.DeletedFields {
+		if len(c.NewFields[modelName]) > 0 {
+			models = append(models, modelName)
+		}
+	}
+	sort.Strings(models)
+
+	for _, modelName := range models {
+		var candidates []FieldRename
+		for _, from := range c.DeletedFields[modelName] {
+			for _, to := range c.NewFields[modelName] {
+				if from.Type == to.Type && withoutColumnTag(from.Tag) == withoutColumnTag(to.Tag) {
+					candidates = append(candidates, FieldRename{From: from, To: to})
+				}
+			}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return nameSimilarity(candidates[i]) > nameSimilarity(candidates[j])
+		})
+
+		used := make(map[string]bool)
+		for _, candidate := range candidates {
+			if used["from:"+candidate.From.Name] || used["to:"+candidate.To.Name] {
+				continue
+			}
+			if !confirm(modelName, candidate) {
+				continue
+			}
+			used["from:"+candidate.From.Name] = true
+			used["to:"+candidate.To.Name] = true
+			c.RenamedFields[modelName] = append(c.RenamedFields[modelName], candidate)
+		}
+		if len(used) == 0 {
+			continue
+		}
+
+		removeFields(c.DeletedFields, modelName, used, "from:")
+		removeFields(c.NewFields, modelName, used, "to:")
+	}
+}
+
+// removeFields drops the used fields of a model, and the model once none are left
+func removeFields(fields map[string][]FieldInfo, modelName string, used map[string]bool, prefix string) {
+	var kept []FieldInfo
+	for _, field := range fields[modelName] {
+		if !used[prefix+field.Name] {
+			kept = append(kept, field)
+		}
+	}
+	if len(kept) == 0 {
+		delete(fields, modelName)
+	} else {
+		fields[modelName] = kept
+	}
+}
+
+var columnTag = regexp.MustCompile(`column:[^;"]*;?`)
+
+// withoutColumnTag drops the column name from a struct tag, so renaming the column
+// with the field still counts as a rename
+func withoutColumnTag(tag string) string {
+	return columnTag.ReplaceAllString(tag, "")
+}
+
+// nameSimilarity scores how alike two field names are by their shared prefix and
+// suffix, e.g. Email and EmailAddress share "Email"
+func nameSimilarity(rename FieldRename) int {
+	a, b := strings.ToLower(rename.From.Name), strings.ToLower(rename.To.Name)
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	return prefix + suffix
+}
+
+// HasDestructiveChanges returns true if there are any destructive changes
+func (c *MigrationChanges) HasDestructiveChanges() bool {
+	return len(c.DeletedModels) > 0 || len(c.DeletedFields) > 0 || len(c.DroppedJoinTables) > 0 ||
+		len(c.TypeChanges()) > 0
+}
+
+// TypeChanges returns the modified fields whose type changed, whose existing values
+// the database has to convert
+func (c *MigrationChanges) TypeChanges() map[string][]FieldChange {
+	changed := make(map[string][]FieldChange)
+	for modelName, fieldChanges := range c.ModifiedFields {
+		for _, change := range fieldChanges {
+			if change.From.Type != change.To.Type {
+				changed[modelName] = append(changed[modelName], change)
+			}
+		}
+	}
+	return changed
+}
+
+// UsesTimePackage reports whether generated code for these changes references the time package
+func (c *MigrationChanges) UsesTimePackage() bool {
+	for _, model := range c.NewModels {
+		if model.HasBaseModel || fieldsUseTime(model.Fields) {
+			return true
+		}
+	}
+	for _, fields := range c.NewFields {
+		if fieldsUseTime(fields) {
+			return true
+		}
+	}
+	for _, fields := range c.DeletedFields {
+		if fieldsUseTime(fields) {
+			return true
+		}
+	}
+	for _, renames := range c.RenamedFields {
+		for _, rename := range renames {
+			if fieldsUseTime([]FieldInfo{rename.From}) {
+				return true
+			}
+		}
+	}
+	for _, fieldChanges := range c.ModifiedFields {
+		for _, change := range fieldChanges {
+			if fieldsUseTime([]FieldInfo{change.From, change.To}) {
+				return true
+			}
+		}
+	}
+	for _, indexes := range []map[string][]IndexInfo{c.NewIndexes, c.DroppedIndexes} {
+		for _, modelIndexes := range indexes {
+			for _, index := range modelIndexes {
+				if fieldsUseTime(index.Fields) {
+					return true
+				}
+			}
+		}
+	}
+	for _, checks := range []map[string][]FieldInfo{c.NewChecks, c.DroppedChecks} {
+		for _, fields := range checks {
+			if fieldsUseTime(fields) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldsUseTime reports whether any field type comes from the time package
+func fieldsUseTime(fields []FieldInfo) bool {
+	for _, field := range fields {
+		if strings.Contains(field.Type, "time.") {
+			return true
+		}
+	}
+	return false
+}
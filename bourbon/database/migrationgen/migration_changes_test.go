@@ -0,0 +1,368 @@
+package migrationgen
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// inTempDir runs the test in an empty directory, where the migration state and
+// scanned apps are kept
+func inTempDir(t *testing.T) string {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return dir
+}
+
+// detect records stored as the migrated models of an app, or none when stored is
+// nil, and returns the changes that lead to current
+func detect(t *testing.T, stored, current []ModelInfo) *MigrationChanges {
+	t.Helper()
+	inTempDir(t)
+	if stored != nil {
+		if err := UpdateMigrationState("blog", stored, "20240101000000_initial"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	changes, err := DetectAllChanges("blog", current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return changes
+}
+
+// migrationCode returns the generated up and down code, failing the test unless
+// both parse as function bodies
+func migrationCode(t *testing.T, changes *MigrationChanges) (string, string) {
+	t.Helper()
+	up := GenerateMigrationCodeFromChanges(changes)
+	down := GenerateRollbackCodeFromChanges(changes)
+	for _, code := range []string{up, down} {
+		source := "package migrations\n\nfunc migrate(tx *gorm.DB) error {\n" + code + "\n}\n"
+		if _, err := parser.ParseFile(token.NewFileSet(), "migration.go", source, 0); err != nil {
+			t.Fatalf("generated code does not parse: %v\n%s", err, code)
+		}
+	}
+	return up, down
+}
+
+// assertContains fails the test for each fragment missing from code
+func assertContains(t *testing.T, name, code string, fragments ...string) {
+	t.Helper()
+	for _, fragment := range fragments {
+		if !strings.Contains(code, fragment) {
+			t.Errorf("%s code is missing %s:\n%s", name, fragment, code)
+		}
+	}
+}
+
+func TestResolveRenames(t *testing.T) {
+	stored := []ModelInfo{{Name: "User", Fields: []FieldInfo{
+		{Name: "Name", Type: "string"},
+		{Name: "Email", Type: "string", Tag: "`gorm:\"size:100\"`"},
+	}}}
+	current := []ModelInfo{{Name: "User", Fields: []FieldInfo{
+		{Name: "Name", Type: "string"},
+		{Name: "EmailAddress", Type: "string", Tag: "`gorm:\"column:email_address;size:100\"`"},
+	}}}
+
+	changes := detect(t, stored, current)
+	if len(changes.DeletedFields["User"]) != 1 || len(changes.NewFields["User"]) != 1 {
+		t.Fatalf("detected new %v and deleted %v", changes.NewFields, changes.DeletedFields)
+	}
+
+	var asked []string
+	changes.ResolveRenames(func(modelName string, rename FieldRename) bool {
+		asked = append(asked, modelName+"."+rename.From.Name+"->"+rename.To.Name)
+		return true
+	})
+	if want := []string{"User.Email->EmailAddress"}; !reflect.DeepEqual(asked, want) {
+		t.Errorf("asked about %v, want %v", asked, want)
+	}
+	if len(changes.NewFields) != 0 || len(changes.DeletedFields) != 0 || len(changes.RenamedFields["User"]) != 1 {
+		t.Fatalf("after confirming: new %v, deleted %v, renamed %v", changes.NewFields, changes.DeletedFields, changes.RenamedFields)
+	}
+	if changes.HasDestructiveChanges() {
+		t.Error("a confirmed rename is destructive")
+	}
+
+	up, down := migrationCode(t, changes)
+	assertContains(t, "up", up, `tx.Migrator().RenameColumn(&user{}, "Email", "EmailAddress")`)
+	assertContains(t, "down", down, `tx.Migrator().RenameColumn(&user{}, "EmailAddress", "Email")`)
+	if strings.Contains(up, "DropColumn") {
+		t.Errorf("a renamed column is dropped:\n%s", up)
+	}
+}
+
+func TestResolveRenamesCandidates(t *testing.T) {
+	stored := []ModelInfo{{Name: "User", Fields: []FieldInfo{
+		{Name: "Name", Type: "string"},
+		{Name: "Email", Type: "string"},
+		{Name: "Age", Type: "int"},
+	}}}
+	current := []ModelInfo{{Name: "User", Fields: []FieldInfo{
+		{Name: "EmailAddress", Type: "string"},
+		{Name: "Years", Type: "uint"},
+	}}}
+
+	// The most similar name is offered first, and a declined pair stays a drop and an add
+	changes := detect(t, stored, current)
+	var asked []string
+	changes.ResolveRenames(func(modelName string, rename FieldRename) bool {
+		asked = append(asked, rename.From.Name+"->"+rename.To.Name)
+		return rename.From.Name == "Name"
+	})
+	if want := []string{"Email->EmailAddress", "Name->EmailAddress"}; !reflect.DeepEqual(asked, want) {
+		t.Errorf("asked about %v, want %v", asked, want)
+	}
+
+	renamed := changes.RenamedFields["User"]
+	if len(renamed) != 1 || renamed[0].From.Name != "Name" {
+		t.Errorf("renamed %v", renamed)
+	}
+	var deleted []string
+	for _, field := range changes.DeletedFields["User"] {
+		deleted = append(deleted, field.Name)
+	}
+	if want := []string{"Email", "Age"}; !reflect.DeepEqual(deleted, want) {
+		t.Errorf("deleted %v, want %v", deleted, want)
+	}
+	if added := changes.NewFields["User"]; len(added) != 1 || added[0].Name != "Years" {
+		t.Errorf("new fields %v", added)
+	}
+}
+
+func TestResolveModelRenames(t *testing.T) {
+	fields := []FieldInfo{
+		{Name: "Title", Type: "string"},
+		{Name: "Body", Type: "string"},
+	}
+	stored := []ModelInfo{{Name: "Article", Fields: fields, HasBaseModel: true}}
+	current := []ModelInfo{{Name: "Post", Fields: fields, HasBaseModel: true}}
+
+	for _, confirmed := range []bool{true, false} {
+		changes := detect(t, stored, current)
+		var asked []string
+		changes.ResolveModelRenames(func(rename ModelRename) bool {
+			asked = append(asked, rename.From+"->"+rename.To.Name)
+			return confirmed
+		})
+		if want := []string{"Article->Post"}; !reflect.DeepEqual(asked, want) {
+			t.Errorf("asked about %v, want %v", asked, want)
+		}
+
+		up, down := migrationCode(t, changes)
+		if !confirmed {
+			if len(changes.NewModels) != 1 || !reflect.DeepEqual(changes.DeletedModels, []string{"Article"}) {
+				t.Errorf("declined: new %v, deleted %v", changes.NewModels, changes.DeletedModels)
+			}
+			assertContains(t, "up", up, `CreateTable(&post{})`, `DropTable("articles")`)
+			continue
+		}
+		if len(changes.NewModels) != 0 || len(changes.DeletedModels) != 0 || len(changes.RenamedModels) != 1 {
+			t.Fatalf("confirmed: new %v, deleted %v, renamed %v", changes.NewModels, changes.DeletedModels, changes.RenamedModels)
+		}
+		assertContains(t, "up", up, `tx.Migrator().RenameTable("articles", "posts")`)
+		assertContains(t, "down", down, `tx.Migrator().RenameTable("posts", "articles")`)
+		if strings.Contains(up, "DropTable") || strings.Contains(up, "CreateTable") {
+			t.Errorf("a renamed table is dropped or created:\n%s", up)
+		}
+	}
+
+	// Models whose fields differ are not offered as renames
+	changes := detect(t, stored, []ModelInfo{{Name: "Post", Fields: fields[:1], HasBaseModel: true}})
+	changes.ResolveModelRenames(func(rename ModelRename) bool {
+		t.Errorf("offered %s -> %s with different fields", rename.From, rename.To.Name)
+		return true
+	})
+}
+
+func TestIndexAndCheckChanges(t *testing.T) {
+	stored := []ModelInfo{{Name: "User", HasBaseModel: true, Fields: []FieldInfo{
+		{Name: "Email", Type: "string", Tag: "`gorm:\"index\"`"},
+		{Name: "Name", Type: "string", Tag: "`gorm:\"index\"`"},
+		{Name: "Age", Type: "int", Tag: "`gorm:\"check:age > 0\"`"},
+	}}}
+	current := []ModelInfo{{Name: "User", HasBaseModel: true, Fields: []FieldInfo{
+		{Name: "Email", Type: "string", Tag: "`gorm:\"uniqueIndex\"`"},
+		{Name: "Name", Type: "string"},
+		{Name: "Age", Type: "int", Tag: "`gorm:\"check:age >= 0\"`"},
+		{Name: "Score", Type: "int", Tag: "`gorm:\"index;check:score >= 0\"`"},
+	}}}
+
+	changes := detect(t, stored, current)
+	indexNames := func(indexes []IndexInfo) []string {
+		var names []string
+		for _, index := range indexes {
+			names = append(names, index.Name)
+		}
+		return names
+	}
+	if got, want := indexNames(changes.NewIndexes["User"]), []string{"idx_users_email", "idx_users_score"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("new indexes %v, want %v", got, want)
+	}
+	if got, want := indexNames(changes.DroppedIndexes["User"]), []string{"idx_users_email", "idx_users_name"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("dropped indexes %v, want %v", got, want)
+	}
+	if len(changes.NewChecks["User"]) != 1 || len(changes.DroppedChecks["User"]) != 1 {
+		t.Errorf("checks: new %v, dropped %v", changes.NewChecks, changes.DroppedChecks)
+	}
+	if len(changes.AlteredFields()) != 0 {
+		t.Errorf("index and check changes alter columns: %v", changes.AlteredFields())
+	}
+
+	up, down := migrationCode(t, changes)
+	assertContains(t, "up", up,
+		`if tx.Migrator().HasIndex(&user{}, "idx_users_name") {`,
+		`tx.Migrator().DropIndex(&user{}, "idx_users_email")`,
+		`tx.Migrator().CreateIndex(&user{}, "idx_users_email")`,
+		`tx.Migrator().DropConstraint(&user{}, "chk_users_age")`,
+		`tx.Migrator().CreateConstraint(&user{}, "chk_users_age")`,
+		`tx.Migrator().AddColumn(&user{}, "Score")`,
+		`tx.Migrator().CreateConstraint(&user{}, "chk_users_score")`,
+	)
+	assertContains(t, "down", down,
+		`tx.Migrator().CreateIndex(&user{}, "idx_users_name")`,
+		`tx.Migrator().DropIndex(&user{}, "idx_users_score")`,
+		`tx.Migrator().DropColumn(&user{}, "Score")`,
+	)
+
+	// Indexes are dropped before their columns change and created once they exist
+	if strings.Index(up, `DropIndex(&user{}, "idx_users_name")`) > strings.Index(up, `AddColumn(`) ||
+		strings.Index(up, `CreateIndex(&user{}, "idx_users_score")`) < strings.Index(up, `AddColumn(`) {
+		t.Errorf("indexes are out of order:\n%s", up)
+	}
+}
+
+func TestAlterColumn(t *testing.T) {
+	stored := []ModelInfo{{Name: "Post", HasBaseModel: true, Fields: []FieldInfo{
+		{Name: "Views", Type: "int"},
+		{Name: "Title", Type: "string", Tag: "`gorm:\"size:100\"`"},
+		{Name: "Slug", Type: "string", Tag: "`gorm:\"size:50\" json:\"slug\"`"},
+		{Name: "Status", Type: "string"},
+	}}}
+	current := []ModelInfo{{Name: "Post", HasBaseModel: true, Fields: []FieldInfo{
+		{Name: "Views", Type: "int64"},
+		{Name: "Title", Type: "string", Tag: "`gorm:\"size:200\"`"},
+		{Name: "Slug", Type: "string", Tag: "`gorm:\"size:50;index\" json:\"slug_name\"`"},
+		{Name: "Status", Type: "string", Tag: "`gorm:\"default:draft\"`"},
+	}}}
+
+	changes := detect(t, stored, current)
+	var altered []string
+	for _, change := range changes.AlteredFields()["Post"] {
+		altered = append(altered, change.To.Name)
+	}
+	if want := []string{"Views", "Title", "Status"}; !reflect.DeepEqual(altered, want) {
+		t.Errorf("altered %v, want %v", altered, want)
+	}
+	if typeChanges := changes.TypeChanges()["Post"]; len(typeChanges) != 1 || typeChanges[0].To.Name != "Views" {
+		t.Errorf("type changes %v", typeChanges)
+	}
+	if !changes.HasDestructiveChanges() {
+		t.Error("a type change is not destructive")
+	}
+
+	up, down := migrationCode(t, changes)
+	assertContains(t, "up", up,
+		"// Views changes from int to int64",
+		"Views int64\n",
+		`tx.Migrator().AlterColumn(&post{}, "Views")`,
+		"Title string `gorm:\"size:200\"`",
+		`tx.Migrator().AlterColumn(&post{}, "Status")`,
+	)
+	assertContains(t, "down", down, "Views int\n", "Title string `gorm:\"size:100\"`")
+	if strings.Contains(up, `AlterColumn(&post{}, "Slug")`) {
+		t.Errorf("an index or json tag change alters the column:\n%s", up)
+	}
+}
+
+func TestResolveBackfills(t *testing.T) {
+	stored := []ModelInfo{{Name: "Post", HasBaseModel: true, Fields: []FieldInfo{{Name: "Title", Type: "string"}}}}
+	current := []ModelInfo{{Name: "Post", HasBaseModel: true, Fields: []FieldInfo{
+		{Name: "Title", Type: "string"},
+		{Name: "Rank", Type: "int", Tag: "`gorm:\"not null\"`"},
+		{Name: "Kind", Type: "string", Tag: "`gorm:\"not null;default:'post'\"`"},
+		{Name: "Summary", Type: "string"},
+	}}}
+
+	changes := detect(t, stored, current)
+	var asked []string
+	changes.ResolveBackfills(func(modelName string, field FieldInfo, suggestion string) string {
+		asked = append(asked, modelName+"."+field.Name+"="+suggestion)
+		return "1"
+	})
+	if want := []string{"Post.Rank=0"}; !reflect.DeepEqual(asked, want) {
+		t.Errorf("asked about %v, want %v", asked, want)
+	}
+
+	// The column is added nullable, filled, then made NOT NULL
+	up, _ := migrationCode(t, changes)
+	steps := []string{
+		"Rank int `gorm:\"\"`",
+		`tx.Migrator().AddColumn(&post{}, "Rank")`,
+		`tx.Model(&post{}).Where("rank IS NULL").Update("rank", 1).Error`,
+		"Rank int `gorm:\"not null\"`",
+		`tx.Migrator().AlterColumn(&post{}, "Rank")`,
+	}
+	last := -1
+	for _, step := range steps {
+		i := strings.Index(up, step)
+		if i < 0 || i < last {
+			t.Errorf("missing or out of order: %s\n%s", step, up)
+		}
+		last = i
+	}
+	assertContains(t, "up", up, "Kind string `gorm:\"not null;default:'post'\"`", `tx.Migrator().AddColumn(&post{}, "Kind")`)
+
+	// Without a backfill value the column is added as declared
+	changes = detect(t, stored, current)
+	changes.ResolveBackfills(func(string, FieldInfo, string) string { return "" })
+	up, _ = migrationCode(t, changes)
+	if strings.Contains(up, "IS NULL") {
+		t.Errorf("an empty backfill value fills the column:\n%s", up)
+	}
+}
+
+func TestTableNameChanges(t *testing.T) {
+	fields := []FieldInfo{{Name: "Name", Type: "string"}}
+	stored := []ModelInfo{
+		{Name: "Person", HasBaseModel: true, Fields: fields},
+		{Name: "Entry", HasBaseModel: true, Fields: fields},
+	}
+	current := []ModelInfo{
+		{Name: "Person", HasBaseModel: true, Fields: fields, TableName: "members"},
+		{Name: "Entry", HasBaseModel: true, Fields: append(fields, FieldInfo{Name: "Body", Type: "string"}), TableName: "journal"},
+		{Name: "Category", HasBaseModel: true, Fields: fields, TableName: "blog_categories"},
+	}
+
+	changes := detect(t, stored, current)
+	if want := map[string]string{"Person": "people", "Entry": "entries"}; !reflect.DeepEqual(changes.RenamedTables, want) {
+		t.Errorf("renamed tables %v, want %v", changes.RenamedTables, want)
+	}
+
+	// Structs are named so GORM derives the table, or the table is given explicitly
+	up, down := migrationCode(t, changes)
+	assertContains(t, "up", up,
+		`tx.Migrator().RenameTable("people", "members")`,
+		`tx.Migrator().RenameTable("entries", "journal")`,
+		`tx.Table("journal").Migrator().AddColumn(&entry{}, "Body")`,
+		"type blog_category struct",
+		`tx.Migrator().CreateTable(&blog_category{})`,
+	)
+	assertContains(t, "down", down, `tx.Migrator().RenameTable("members", "people")`, `tx.Migrator().DropTable("blog_categories")`)
+	if strings.Index(up, "RenameTable") > strings.Index(up, "AddColumn") {
+		t.Errorf("a column is added before its table is renamed:\n%s", up)
+	}
+}
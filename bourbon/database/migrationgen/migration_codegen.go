@@ -0,0 +1,574 @@
+package migrationgen
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+
+	"github.com/jinzhu/inflection"
+)
+
+// GenerateMigrationCodeFromChanges generates migration code following gormigrate best practices
+func GenerateMigrationCodeFromChanges(changes *MigrationChanges) string {
+	var code strings.Builder
+
+	// Drop foreign keys and join tables of removed relations first, so they don't
+	// block the changes to the tables they reference
+	for _, fk := range changes.DroppedForeignKeys {
+		writeScoped(&code, changes.generateDropForeignKeyCode(fk))
+	}
+	for _, joinTable := range changes.DroppedJoinTables {
+		code.WriteString(generateDropJoinTableCode(joinTable))
+		code.WriteString("\n")
+	}
+
+	// Generate CreateTable for new models, referenced tables first
+	for _, model := range changes.NewModels {
+		writeScoped(&code, changes.generateCreateTableCode(model, changes.TableForeignKeys[model.Name]))
+	}
+
+	// Generate RenameTable for renamed models and changed TableName methods
+	for _, rename := range changes.RenamedModels {
+		code.WriteString(generateRenameTableCode(rename.FromTable, rename.To.Table()))
+		code.WriteString("\n")
+	}
+	for modelName, from := range changes.RenamedTables {
+		code.WriteString(generateRenameTableCode(from, changes.tableName(modelName)))
+		code.WriteString("\n")
+	}
+
+	// Drop changed and removed indexes and check constraints before their columns change
+	for modelName, indexes := range changes.DroppedIndexes {
+		for _, index := range indexes {
+			writeScoped(&code, changes.generateDropIndexCode(modelName, index))
+		}
+	}
+	for modelName, fields := range changes.DroppedChecks {
+		for _, field := range fields {
+			writeScoped(&code, changes.generateDropConstraintCode(modelName, field))
+		}
+	}
+
+	// Generate RenameColumn for renamed fields
+	for modelName, renames := range changes.RenamedFields {
+		for _, rename := range renames {
+			writeScoped(&code, changes.generateRenameColumnCode(modelName, rename.From, rename.To))
+		}
+	}
+
+	// Generate AddColumn for new fields
+	for modelName, fields := range changes.NewFields {
+		for _, field := range fields {
+			writeScoped(&code, changes.generateAddColumnCode(modelName, field, changes.Backfills[modelName][field.Name]))
+		}
+	}
+
+	// Generate AlterColumn for fields whose type or column settings changed
+	for modelName, fieldChanges := range changes.AlteredFields() {
+		for _, change := range fieldChanges {
+			writeScoped(&code, changes.generateAlterColumnCode(modelName, change.From, change.To))
+		}
+	}
+
+	// Generate DropColumn for deleted fields
+	for modelName, fields := range changes.DeletedFields {
+		for _, field := range fields {
+			writeScoped(&code, changes.generateDropColumnCode(modelName, field))
+		}
+	}
+
+	// Generate DropTable for deleted models
+	for _, modelName := range changes.DeletedModels {
+		code.WriteString(changes.generateDropTableCode(modelName))
+		code.WriteString("\n")
+	}
+
+	// Create new and changed check constraints and indexes once their columns exist.
+	// Constraints go first, since SQLite rebuilds the table for them and loses its indexes.
+	for modelName, fields := range changes.NewChecks {
+		for _, field := range fields {
+			writeScoped(&code, changes.generateCreateConstraintCode(modelName, field))
+		}
+	}
+	for modelName, indexes := range changes.NewIndexes {
+		for _, index := range indexes {
+			writeScoped(&code, changes.generateCreateIndexCode(modelName, index))
+		}
+	}
+
+	// Add foreign keys and join tables once the tables and columns they use exist
+	for _, fk := range changes.NewForeignKeys {
+		writeScoped(&code, changes.generateCreateForeignKeyCode(fk))
+	}
+	for _, joinTable := range changes.NewJoinTables {
+		writeScoped(&code, changes.generateCreateJoinTableCode(joinTable))
+	}
+
+	result := code.String()
+	if result == "" {
+		return "\t\treturn nil"
+	}
+
+	return strings.TrimSuffix(result, "\n") + "\n\t\treturn nil"
+}
+
+// GenerateRollbackCodeFromChanges generates rollback code
+func GenerateRollbackCodeFromChanges(changes *MigrationChanges) string {
+	var code strings.Builder
+
+	// Rollback: Drop join tables and foreign keys that were added
+	for _, joinTable := range changes.NewJoinTables {
+		code.WriteString(generateDropJoinTableCode(joinTable))
+		code.WriteString("\n")
+	}
+	for _, fk := range changes.NewForeignKeys {
+		writeScoped(&code, changes.generateDropForeignKeyCode(fk))
+	}
+
+	// Rollback: Drop tables that were created, referencing tables first
+	for i := len(changes.NewModels) - 1; i >= 0; i-- {
+		code.WriteString(changes.generateDropTableCode(changes.NewModels[i].Name))
+		code.WriteString("\n")
+	}
+
+	// Rollback: Drop indexes and check constraints that were created
+	for modelName, indexes := range changes.NewIndexes {
+		for _, index := range indexes {
+			writeScoped(&code, changes.generateDropIndexCode(modelName, index))
+		}
+	}
+	for modelName, fields := range changes.NewChecks {
+		for _, field := range fields {
+			writeScoped(&code, changes.generateDropConstraintCode(modelName, field))
+		}
+	}
+
+	// Rollback: Drop columns that were added
+	for modelName, fields := range changes.NewFields {
+		for _, field := range fields {
+			writeScoped(&code, changes.generateDropColumnCode(modelName, field))
+		}
+	}
+
+	// Rollback: Alter columns back
+	for modelName, fieldChanges := range changes.AlteredFields() {
+		for _, change := range fieldChanges {
+			writeScoped(&code, changes.generateAlterColumnCode(modelName, change.To, change.From))
+		}
+	}
+
+	// Rollback: Add back columns that were dropped
+	for modelName, fields := range changes.DeletedFields {
+		for _, field := range fields {
+			writeScoped(&code, changes.generateAddColumnCode(modelName, field, zeroValue(field.Type)))
+		}
+	}
+
+	// Rollback: Rename columns back
+	for modelName, renames := range changes.RenamedFields {
+		for _, rename := range renames {
+			writeScoped(&code, changes.generateRenameColumnCode(modelName, rename.To, rename.From))
+		}
+	}
+
+	// Rollback: Recreate check constraints and indexes that were dropped
+	for modelName, fields := range changes.DroppedChecks {
+		for _, field := range fields {
+			writeScoped(&code, changes.generateCreateConstraintCode(modelName, field))
+		}
+	}
+	for modelName, indexes := range changes.DroppedIndexes {
+		for _, index := range indexes {
+			writeScoped(&code, changes.generateCreateIndexCode(modelName, index))
+		}
+	}
+
+	// Rollback: Rename tables back
+	for _, rename := range changes.RenamedModels {
+		code.WriteString(generateRenameTableCode(rename.To.Table(), rename.FromTable))
+		code.WriteString("\n")
+	}
+	for modelName, from := range changes.RenamedTables {
+		code.WriteString(generateRenameTableCode(changes.tableName(modelName), from))
+		code.WriteString("\n")
+	}
+
+	// Rollback: Create tables that were dropped
+	// Note: This is imperfect as we don't have full model definition
+	for _, modelName := range changes.DeletedModels {
+		code.WriteString(fmt.Sprintf("\t\t// TODO: Recreate table %s\n", modelName))
+	}
+
+	// Rollback: Restore foreign keys and join tables that were dropped
+	for _, joinTable := range changes.DroppedJoinTables {
+		writeScoped(&code, changes.generateCreateJoinTableCode(joinTable))
+	}
+	for _, fk := range changes.DroppedForeignKeys {
+		writeScoped(&code, changes.generateCreateForeignKeyCode(fk))
+	}
+
+	result := code.String()
+	if result == "" {
+		return "\t\treturn nil"
+	}
+
+	return strings.TrimSuffix(result, "\n") + "\n\t\treturn nil"
+}
+
+// tableName returns a model's table, as its current TableName method (or the
+// stored one, for deleted models) or GORM's default naming gives it
+func (c *MigrationChanges) tableName(modelName string) string {
+	if table, ok := c.tables[modelName]; ok {
+		return table
+	}
+	if table, ok := c.storedTables[modelName]; ok {
+		return table
+	}
+	return toSnakeCase(modelName)
+}
+
+// structName returns the name of the minimal structs generated for a model. GORM
+// derives their table from it, so for a TableName override a name that GORM
+// derives the same table from is used where one exists (people -> person).
+func (c *MigrationChanges) structName(modelName string) string {
+	name := fieldToSnakeCase(modelName)
+	table := c.tableName(modelName)
+	if toSnakeCase(name) == table {
+		return name
+	}
+	if singular := inflection.Singular(table); token.IsIdentifier(singular) && toSnakeCase(singular) == table {
+		return singular
+	}
+	return name
+}
+
+// tx returns the receiver for operations on a model's table: tx, or tx.Table(...)
+// when GORM cannot derive the table from the struct name
+func (c *MigrationChanges) tx(modelName string) string {
+	table := c.tableName(modelName)
+	if toSnakeCase(c.structName(modelName)) == table {
+		return "tx"
+	}
+	return fmt.Sprintf("tx.Table(%q)", table)
+}
+
+// writeScoped writes an operation that declares a minimal model struct inside its
+// own block, so several operations on the same model can each declare it
+func writeScoped(code *strings.Builder, operation string) {
+	code.WriteString("\t\t{\n")
+	for _, line := range strings.Split(operation, "\n") {
+		code.WriteString("\t" + line + "\n")
+	}
+	code.WriteString("\t\t}\n")
+}
+
+// generateCreateTableCode generates CreateTable code with inline struct. Foreign
+// keys become belongs-to fields to minimal structs of the referenced models, so
+// GORM creates them with the table.
+func (c *MigrationChanges) generateCreateTableCode(model ModelInfo, foreignKeys []ForeignKeyInfo) string {
+	var code strings.Builder
+
+	c.writeReferencedStructs(&code, model.Name, foreignKeys)
+
+	// Define minimal struct with all fields
+	code.WriteString(fmt.Sprintf("\t\ttype %s struct {\n", c.structName(model.Name)))
+	if model.HasBaseModel {
+		code.WriteString("\t\t\tID        uint      `gorm:\"primarykey\"`\n")
+		code.WriteString("\t\t\tCreatedAt time.Time\n")
+		code.WriteString("\t\t\tUpdatedAt time.Time\n")
+		code.WriteString("\t\t\tDeletedAt gorm.DeletedAt `gorm:\"index\"`\n")
+	}
+
+	fields := model.Fields
+	for _, fk := range foreignKeys {
+		fields = append(fields, c.relationField(model.Name, fk))
+	}
+	for _, field := range fields {
+		tagStr := ""
+		if field.Tag != "" {
+			tagStr = " " + field.Tag
+		}
+		code.WriteString(fmt.Sprintf("\t\t\t%s %s%s\n", field.Name, field.Type, tagStr))
+	}
+
+	code.WriteString("\t\t}\n")
+	code.WriteString(fmt.Sprintf("\t\tif err := %s.Migrator().CreateTable(&%s{}); err != nil {\n", c.tx(model.Name), c.structName(model.Name)))
+	code.WriteString("\t\t\treturn err\n")
+	code.WriteString("\t\t}")
+
+	return code.String()
+}
+
+// writeForeignKeyStructs writes the structs GORM needs to find a foreign key by name:
+// the referenced model's key, and the owner's key field with a belongs-to field
+func (c *MigrationChanges) writeForeignKeyStructs(code *strings.Builder, fk ForeignKeyInfo) {
+	c.writeReferencedStructs(code, fk.Model, []ForeignKeyInfo{fk})
+
+	fields := []FieldInfo{{Name: fk.Field, Type: fk.FieldType}}
+	if fk.RefModel == fk.Model {
+		fields = appendKeyField(fields, fk.RefField, fk.RefType)
+	}
+	c.writeModelStruct(code, fk.Model, append(fields, c.relationField(fk.Model, fk)))
+}
+
+// generateCreateForeignKeyCode generates CreateConstraint code for a foreign key
+func (c *MigrationChanges) generateCreateForeignKeyCode(fk ForeignKeyInfo) string {
+	var code strings.Builder
+
+	c.writeForeignKeyStructs(&code, fk)
+	code.WriteString(fmt.Sprintf("\t\tif err := %s.Migrator().CreateConstraint(&%s{}, \"%s\"); err != nil {\n",
+		c.tx(fk.Model), c.structName(fk.Model), fk.Name))
+	code.WriteString("\t\t\treturn err\n")
+	code.WriteString("\t\t}")
+
+	return code.String()
+}
+
+// generateDropForeignKeyCode generates DropConstraint code for a foreign key,
+// skipping keys already gone
+func (c *MigrationChanges) generateDropForeignKeyCode(fk ForeignKeyInfo) string {
+	var code strings.Builder
+
+	c.writeForeignKeyStructs(&code, fk)
+	code.WriteString(fmt.Sprintf("\t\tif %s.Migrator().HasConstraint(&%s{}, \"%s\") {\n", c.tx(fk.Model), c.structName(fk.Model), fk.Name))
+	code.WriteString(fmt.Sprintf("\t\t\tif err := %s.Migrator().DropConstraint(&%s{}, \"%s\"); err != nil {\n",
+		c.tx(fk.Model), c.structName(fk.Model), fk.Name))
+	code.WriteString("\t\t\t\treturn err\n")
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t}")
+
+	return code.String()
+}
+
+// generateCreateJoinTableCode generates CreateTable code for a many2many join table,
+// keyed by both columns, with a foreign key to each side
+func (c *MigrationChanges) generateCreateJoinTableCode(joinTable JoinTableInfo) string {
+	var code strings.Builder
+
+	c.writeReferencedStructs(&code, "", joinTable.Keys[:])
+
+	code.WriteString("\t\ttype join_table struct {\n")
+	for _, fk := range joinTable.Keys {
+		code.WriteString(fmt.Sprintf("\t\t\t%s %s `gorm:\"primaryKey;autoIncrement:false\"`\n", fk.Field, fk.FieldType))
+	}
+	for _, fk := range joinTable.Keys {
+		field := c.relationField("", fk)
+		code.WriteString(fmt.Sprintf("\t\t\t%s %s %s\n", field.Name, field.Type, field.Tag))
+	}
+	code.WriteString("\t\t}\n")
+	code.WriteString(fmt.Sprintf("\t\tif err := tx.Table(\"%s\").Migrator().CreateTable(&join_table{}); err != nil {\n", joinTable.Name))
+	code.WriteString("\t\t\treturn err\n")
+	code.WriteString("\t\t}")
+
+	return code.String()
+}
+
+// generateDropJoinTableCode generates DropTable code for a many2many join table
+func generateDropJoinTableCode(joinTable JoinTableInfo) string {
+	return fmt.Sprintf("\t\tif err := tx.Migrator().DropTable(\"%s\"); err != nil {\n\t\t\treturn err\n\t\t}", joinTable.Name)
+}
+
+// generateAddColumnCode generates AddColumn code with minimal struct. A NOT NULL
+// column without a default is added nullable, filled with backfill and then made
+// NOT NULL, so existing rows don't make the AddColumn fail.
+func (c *MigrationChanges) generateAddColumnCode(modelName string, field FieldInfo, backfill string) string {
+	var code strings.Builder
+
+	added := field
+	if field.NeedsBackfill() && backfill != "" {
+		added = field.Nullable()
+	}
+
+	// Define minimal struct with only the field being added
+	code.WriteString(fmt.Sprintf("\t\ttype %s struct {\n", c.structName(modelName)))
+
+	tagStr := ""
+	if added.Tag != "" {
+		tagStr = " " + added.Tag
+	}
+	code.WriteString(fmt.Sprintf("\t\t\t%s %s%s\n", added.Name, added.Type, tagStr))
+	code.WriteString("\t\t}\n")
+	code.WriteString(fmt.Sprintf("\t\tif err := %s.Migrator().AddColumn(&%s{}, \"%s\"); err != nil {\n",
+		c.tx(modelName), c.structName(modelName), field.Name))
+	code.WriteString("\t\t\treturn err\n")
+	code.WriteString("\t\t}")
+
+	// Fill existing rows, then make the column NOT NULL
+	indent := "\t\t"
+	if added != field {
+		column := field.ColumnName()
+		code.WriteString(fmt.Sprintf("\n\t\tif err := %s.Model(&%s{}).Where(\"%s IS NULL\").Update(\"%s\", %s).Error; err != nil {\n",
+			c.tx(modelName), c.structName(modelName), column, column, backfill))
+		code.WriteString("\t\t\treturn err\n")
+		code.WriteString("\t\t}\n")
+		code.WriteString("\t\t{\n")
+		indent = "\t\t\t"
+		code.WriteString(fmt.Sprintf("%stype %s struct {\n", indent, c.structName(modelName)))
+		code.WriteString(fmt.Sprintf("%s\t%s %s %s\n", indent, field.Name, field.Type, field.Tag))
+		code.WriteString(fmt.Sprintf("%s}\n", indent))
+		code.WriteString(fmt.Sprintf("%sif err := %s.Migrator().AlterColumn(&%s{}, \"%s\"); err != nil {\n",
+			indent, c.tx(modelName), c.structName(modelName), field.Name))
+		code.WriteString(fmt.Sprintf("%s\treturn err\n", indent))
+		code.WriteString(fmt.Sprintf("%s}", indent))
+	}
+
+	// AddColumn does not create check constraints, so add them explicitly
+	if name, ok := field.CheckConstraintName(c.tableName(modelName)); ok {
+		code.WriteString(fmt.Sprintf("\n%sif err := %s.Migrator().CreateConstraint(&%s{}, \"%s\"); err != nil {\n",
+			indent, c.tx(modelName), c.structName(modelName), name))
+		code.WriteString(fmt.Sprintf("%s\treturn err\n", indent))
+		code.WriteString(fmt.Sprintf("%s}", indent))
+	}
+	if added != field {
+		code.WriteString("\n\t\t}")
+	}
+
+	return code.String()
+}
+
+// generateDropColumnCode generates DropColumn code with minimal struct
+func (c *MigrationChanges) generateDropColumnCode(modelName string, field FieldInfo) string {
+	var code strings.Builder
+
+	// Define minimal struct with only the field being dropped
+	code.WriteString(fmt.Sprintf("\t\ttype %s struct {\n", c.structName(modelName)))
+
+	tagStr := ""
+	if field.Tag != "" {
+		tagStr = " " + field.Tag
+	}
+	code.WriteString(fmt.Sprintf("\t\t\t%s %s%s\n", field.Name, field.Type, tagStr))
+	code.WriteString("\t\t}\n")
+
+	// Drop the check constraint first so databases that keep it don't reject the drop
+	if name, ok := field.CheckConstraintName(c.tableName(modelName)); ok {
+		code.WriteString(fmt.Sprintf("\t\tif %s.Migrator().HasConstraint(&%s{}, \"%s\") {\n", c.tx(modelName), c.structName(modelName), name))
+		code.WriteString(fmt.Sprintf("\t\t\tif err := %s.Migrator().DropConstraint(&%s{}, \"%s\"); err != nil {\n",
+			c.tx(modelName), c.structName(modelName), name))
+		code.WriteString("\t\t\t\treturn err\n")
+		code.WriteString("\t\t\t}\n")
+		code.WriteString("\t\t}\n")
+	}
+	code.WriteString(fmt.Sprintf("\t\tif err := %s.Migrator().DropColumn(&%s{}, \"%s\"); err != nil {\n",
+		c.tx(modelName), c.structName(modelName), field.Name))
+	code.WriteString("\t\t\treturn err\n")
+	code.WriteString("\t\t}")
+
+	return code.String()
+}
+
+// generateAlterColumnCode generates AlterColumn code with a minimal struct holding
+// the field as it becomes
+func (c *MigrationChanges) generateAlterColumnCode(modelName string, from, to FieldInfo) string {
+	var code strings.Builder
+
+	if from.Type != to.Type {
+		code.WriteString(fmt.Sprintf("\t\t// %s changes from %s to %s; the database converts existing values, and fails or truncates those it cannot\n",
+			to.Name, from.Type, to.Type))
+	}
+	c.writeModelStruct(&code, modelName, []FieldInfo{to})
+	code.WriteString(fmt.Sprintf("\t\tif err := %s.Migrator().AlterColumn(&%s{}, \"%s\"); err != nil {\n",
+		c.tx(modelName), c.structName(modelName), to.Name))
+	code.WriteString("\t\t\treturn err\n")
+	code.WriteString("\t\t}")
+
+	return code.String()
+}
+
+// generateRenameColumnCode generates RenameColumn code with a minimal struct holding
+// both fields, so GORM resolves both column names
+func (c *MigrationChanges) generateRenameColumnCode(modelName string, from, to FieldInfo) string {
+	var code strings.Builder
+
+	c.writeModelStruct(&code, modelName, []FieldInfo{from, to})
+	code.WriteString(fmt.Sprintf("\t\tif err := %s.Migrator().RenameColumn(&%s{}, \"%s\", \"%s\"); err != nil {\n",
+		c.tx(modelName), c.structName(modelName), from.Name, to.Name))
+	code.WriteString("\t\t\treturn err\n")
+	code.WriteString("\t\t}")
+
+	return code.String()
+}
+
+// writeModelStruct writes a minimal struct of the model with the given fields
+func (c *MigrationChanges) writeModelStruct(code *strings.Builder, modelName string, fields []FieldInfo) {
+	code.WriteString(fmt.Sprintf("\t\ttype %s struct {\n", c.structName(modelName)))
+	for _, field := range fields {
+		tagStr := ""
+		if field.Tag != "" {
+			tagStr = " " + field.Tag
+		}
+		code.WriteString(fmt.Sprintf("\t\t\t%s %s%s\n", field.Name, field.Type, tagStr))
+	}
+	code.WriteString("\t\t}\n")
+}
+
+// generateCreateIndexCode generates CreateIndex code with a struct of the index's
+// fields, from whose tags GORM builds the index
+func (c *MigrationChanges) generateCreateIndexCode(modelName string, index IndexInfo) string {
+	var code strings.Builder
+
+	c.writeModelStruct(&code, modelName, index.Fields)
+	code.WriteString(fmt.Sprintf("\t\tif err := %s.Migrator().CreateIndex(&%s{}, \"%s\"); err != nil {\n",
+		c.tx(modelName), c.structName(modelName), index.Name))
+	code.WriteString("\t\t\treturn err\n")
+	code.WriteString("\t\t}")
+
+	return code.String()
+}
+
+// generateDropIndexCode generates DropIndex code, skipping indexes already gone
+func (c *MigrationChanges) generateDropIndexCode(modelName string, index IndexInfo) string {
+	var code strings.Builder
+
+	c.writeModelStruct(&code, modelName, index.Fields)
+	code.WriteString(fmt.Sprintf("\t\tif %s.Migrator().HasIndex(&%s{}, \"%s\") {\n", c.tx(modelName), c.structName(modelName), index.Name))
+	code.WriteString(fmt.Sprintf("\t\t\tif err := %s.Migrator().DropIndex(&%s{}, \"%s\"); err != nil {\n",
+		c.tx(modelName), c.structName(modelName), index.Name))
+	code.WriteString("\t\t\t\treturn err\n")
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t}")
+
+	return code.String()
+}
+
+// generateCreateConstraintCode generates CreateConstraint code for a field's check tag
+func (c *MigrationChanges) generateCreateConstraintCode(modelName string, field FieldInfo) string {
+	var code strings.Builder
+
+	name, _ := field.CheckConstraintName(c.tableName(modelName))
+	c.writeModelStruct(&code, modelName, []FieldInfo{field})
+	code.WriteString(fmt.Sprintf("\t\tif err := %s.Migrator().CreateConstraint(&%s{}, \"%s\"); err != nil {\n",
+		c.tx(modelName), c.structName(modelName), name))
+	code.WriteString("\t\t\treturn err\n")
+	code.WriteString("\t\t}")
+
+	return code.String()
+}
+
+// generateDropConstraintCode generates DropConstraint code for a field's check tag,
+// skipping constraints already gone
+func (c *MigrationChanges) generateDropConstraintCode(modelName string, field FieldInfo) string {
+	var code strings.Builder
+
+	name, _ := field.CheckConstraintName(c.tableName(modelName))
+	c.writeModelStruct(&code, modelName, []FieldInfo{field})
+	code.WriteString(fmt.Sprintf("\t\tif %s.Migrator().HasConstraint(&%s{}, \"%s\") {\n", c.tx(modelName), c.structName(modelName), name))
+	code.WriteString(fmt.Sprintf("\t\t\tif err := %s.Migrator().DropConstraint(&%s{}, \"%s\"); err != nil {\n",
+		c.tx(modelName), c.structName(modelName), name))
+	code.WriteString("\t\t\t\treturn err\n")
+	code.WriteString("\t\t\t}\n")
+	code.WriteString("\t\t}")
+
+	return code.String()
+}
+
+// generateRenameTableCode generates RenameTable code
+func generateRenameTableCode(from, to string) string {
+	return fmt.Sprintf("\t\tif err := tx.Migrator().RenameTable(\"%s\", \"%s\"); err != nil {\n\t\t\treturn err\n\t\t}", from, to)
+}
+
+// generateDropTableCode generates DropTable code
+func (c *MigrationChanges) generateDropTableCode(modelName string) string {
+	tableName := c.tableName(modelName)
+	return fmt.Sprintf("\t\tif err := tx.Migrator().DropTable(\"%s\"); err != nil {\n\t\t\treturn err\n\t\t}", tableName)
+}
@@ -0,0 +1,461 @@
+package migrationgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// ForeignKeyInfo is a foreign key constraint a relation between models needs. It is
+// created through a belongs-to field on the model holding the key, whatever side
+// declared the relation.
+type ForeignKeyInfo struct {
+	Name      string // constraint name, as GORM names it
+	Model     string // model whose table holds the key
+	Field     string // field of Model holding the key, e.g. AuthorID
+	FieldType string
+	RefModel  string // model the key references
+	RefField  string // field of RefModel referenced, usually ID
+	RefType   string
+	Relation  string // belongs-to field name in generated structs
+	OnDelete  string
+	OnUpdate  string
+}
+
+// JoinTableInfo is the join table of a many2many relation, with a key to each side
+type JoinTableInfo struct {
+	Name string
+	Keys [2]ForeignKeyInfo // Model is empty; Field is the join table column
+}
+
+// ModelRelations returns the foreign keys and join tables that the relation fields
+// of models declare: belongs-to (Author User with AuthorID), has-one and has-many
+// (Posts []Post, keyed by Post.UserID) and many2many tags. Relations to models of
+// other apps are not followed.
+func ModelRelations(models []ModelInfo) ([]ForeignKeyInfo, []JoinTableInfo) {
+	byName := make(map[string]*ModelInfo, len(models))
+	for i := range models {
+		byName[models[i].Name] = &models[i]
+	}
+
+	var hasKeys, belongsKeys []ForeignKeyInfo
+	var joinTables []JoinTableInfo
+	for _, model := range models {
+		for _, field := range model.Fields {
+			target, many, ok := relatedModel(field, byName)
+			if !ok {
+				continue
+			}
+			constraint, _ := field.GormSetting("constraint")
+			if constraint == "-" {
+				continue
+			}
+			foreignKey, _ := field.GormSetting("foreignKey")
+			references, _ := field.GormSetting("references")
+
+			if joinTable, ok := field.GormSetting("many2many"); ok {
+				joinTables = append(joinTables, newJoinTable(joinTable, &model, target, field, constraint))
+				continue
+			}
+
+			// A belongs-to key sits on the declaring model; has-one and has-many keys
+			// sit on the target
+			belongs := false
+			if !many {
+				key := foreignKey
+				if key == "" {
+					key = field.Name + "ID"
+				}
+				_, belongs = fieldType(&model, key)
+			}
+
+			var fk ForeignKeyInfo
+			if belongs {
+				fk = ForeignKeyInfo{Model: model.Name, Field: foreignKey, RefModel: target.Name, RefField: references, Relation: field.Name}
+				if fk.Field == "" {
+					fk.Field = field.Name + "ID"
+				}
+			} else {
+				fk = ForeignKeyInfo{Model: target.Name, Field: foreignKey, RefModel: model.Name, RefField: references, Relation: model.Name + field.Name}
+				if fk.Field == "" {
+					fk.Field = model.Name + "ID"
+				}
+			}
+			if fk.RefField == "" {
+				fk.RefField = "ID"
+			}
+
+			var found bool
+			if fk.FieldType, found = fieldType(byName[fk.Model], fk.Field); !found {
+				continue
+			}
+			fk.RefType, _ = fieldType(byName[fk.RefModel], fk.RefField)
+			fk.Name, fk.OnDelete, fk.OnUpdate = parseConstraint(constraint, model.Table(), field.Name)
+
+			if belongs {
+				belongsKeys = append(belongsKeys, fk)
+			} else {
+				hasKeys = append(hasKeys, fk)
+			}
+		}
+	}
+
+	// A relation declared on both sides needs one key; like GORM, the has side's wins
+	foreignKeys := hasKeys
+	for _, fk := range belongsKeys {
+		duplicate := false
+		for _, has := range hasKeys {
+			if has.Model == fk.Model && has.Field == fk.Field && has.RefModel == fk.RefModel && has.RefField == fk.RefField {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			foreignKeys = append(foreignKeys, fk)
+		}
+	}
+	sort.SliceStable(foreignKeys, func(i, j int) bool { return foreignKeys[i].Name < foreignKeys[j].Name })
+	return foreignKeys, joinTables
+}
+
+// newJoinTable describes the join table of a many2many field, with columns named
+// the way GORM names them: PostID and TagID, or UserID and FriendID when a model
+// relates to itself
+func newJoinTable(name string, model, target *ModelInfo, field FieldInfo, constraint string) JoinTableInfo {
+	joinTable := JoinTableInfo{Name: name}
+
+	ownKey, ok := field.GormSetting("foreignKey")
+	if !ok {
+		ownKey = "ID"
+	}
+	refKey, ok := field.GormSetting("references")
+	if !ok {
+		refKey = "ID"
+	}
+	ownColumn, ok := field.GormSetting("joinForeignKey")
+	if !ok {
+		ownColumn = model.Name + ownKey
+	}
+	refColumn, ok := field.GormSetting("joinReferences")
+	if !ok {
+		refColumn = target.Name + refKey
+		if refColumn == ownColumn {
+			refColumn = strings.TrimSuffix(field.Name, "s") + refKey
+		}
+	}
+
+	_, onDelete, onUpdate := parseConstraint(constraint, name, "")
+	sides := []struct {
+		model       *ModelInfo
+		key, column string
+	}{{model, ownKey, ownColumn}, {target, refKey, refColumn}}
+	for i, side := range sides {
+		refType, _ := fieldType(side.model, side.key)
+		relation := strings.TrimSuffix(side.column, "ID")
+		if relation == side.column || relation == "" {
+			relation = side.column + "Ref"
+		}
+		joinTable.Keys[i] = ForeignKeyInfo{
+			Name:      columnNamer.RelationshipFKName(schema.Relationship{Name: relation, Schema: &schema.Schema{Table: name}}),
+			Field:     side.column,
+			FieldType: strings.TrimPrefix(refType, "*"),
+			RefModel:  side.model.Name,
+			RefField:  side.key,
+			RefType:   refType,
+			Relation:  relation,
+			OnDelete:  onDelete,
+			OnUpdate:  onUpdate,
+		}
+	}
+	return joinTable
+}
+
+// relatedModel returns the model a field's type refers to (T, *T, []T or []*T),
+// and whether it is a slice
+func relatedModel(field FieldInfo, models map[string]*ModelInfo) (*ModelInfo, bool, bool) {
+	typeName := field.Type
+	many := strings.HasPrefix(typeName, "[]")
+	typeName = strings.TrimPrefix(strings.TrimPrefix(typeName, "[]"), "*")
+	target, ok := models[typeName]
+	return target, many, ok
+}
+
+// fieldType returns the type of a model's field. ID is the uint key of BaseModel
+// when the model does not declare it.
+func fieldType(model *ModelInfo, name string) (string, bool) {
+	if model == nil {
+		return "", false
+	}
+	for _, field := range model.Fields {
+		if field.Name == name {
+			return field.Type, true
+		}
+	}
+	if name == "ID" && !hasPrimaryKeyField(model.Fields) {
+		return "uint", true
+	}
+	return "", false
+}
+
+// parseConstraint reads a constraint tag such as "OnDelete:CASCADE,OnUpdate:CASCADE"
+// or "fk_name,OnDelete:SET NULL". Without a name, GORM's fk_<table>_<relation> is used.
+func parseConstraint(constraint, tableName, relation string) (name, onDelete, onUpdate string) {
+	settings := schema.ParseTagSetting(constraint, ",")
+	if first, _, found := strings.Cut(constraint, ","); found && constraintNamePattern.MatchString(first) {
+		name = first
+	} else {
+		name = columnNamer.RelationshipFKName(schema.Relationship{Name: relation, Schema: &schema.Schema{Table: tableName}})
+	}
+	return name, settings["ONDELETE"], settings["ONUPDATE"]
+}
+
+// withoutRelations returns the models with only their column fields
+func withoutRelations(models []ModelInfo) []ModelInfo {
+	byName := make(map[string]*ModelInfo, len(models))
+	for i := range models {
+		byName[models[i].Name] = &models[i]
+	}
+
+	result := make([]ModelInfo, len(models))
+	for i, model := range models {
+		result[i] = model
+		result[i].Fields = nil
+		for _, field := range model.Fields {
+			if _, _, ok := relatedModel(field, byName); !ok {
+				result[i].Fields = append(result[i].Fields, field)
+			}
+		}
+	}
+	return result
+}
+
+// diffRelations records the foreign keys and join tables added, removed or changed
+// between the stored and current models
+func (c *MigrationChanges) diffRelations(stored, current []ModelInfo) {
+	storedKeys, storedJoins := ModelRelations(stored)
+	currentKeys, currentJoins := ModelRelations(current)
+
+	storedKeyMap := make(map[string]ForeignKeyInfo, len(storedKeys))
+	for _, fk := range storedKeys {
+		storedKeyMap[fk.Name] = fk
+	}
+	currentKeyMap := make(map[string]ForeignKeyInfo, len(currentKeys))
+	for _, fk := range currentKeys {
+		currentKeyMap[fk.Name] = fk
+		if old, ok := storedKeyMap[fk.Name]; !ok || old != fk {
+			c.NewForeignKeys = append(c.NewForeignKeys, fk)
+		}
+	}
+	for _, fk := range storedKeys {
+		if cur, ok := currentKeyMap[fk.Name]; !ok || cur != fk {
+			c.DroppedForeignKeys = append(c.DroppedForeignKeys, fk)
+		}
+	}
+
+	storedJoinMap := make(map[string]JoinTableInfo, len(storedJoins))
+	for _, joinTable := range storedJoins {
+		storedJoinMap[joinTable.Name] = joinTable
+	}
+	currentJoinMap := make(map[string]JoinTableInfo, len(currentJoins))
+	for _, joinTable := range currentJoins {
+		currentJoinMap[joinTable.Name] = joinTable
+		if old, ok := storedJoinMap[joinTable.Name]; !ok || old != joinTable {
+			c.NewJoinTables = append(c.NewJoinTables, joinTable)
+		}
+	}
+	for _, joinTable := range storedJoins {
+		if cur, ok := currentJoinMap[joinTable.Name]; !ok || cur != joinTable {
+			c.DroppedJoinTables = append(c.DroppedJoinTables, joinTable)
+		}
+	}
+}
+
+// orderNewModels sorts new models so each table is created after the tables it
+// references, and moves their foreign keys into CreateTable. Keys in a reference
+// cycle stay in NewForeignKeys and are added once all tables exist.
+func (c *MigrationChanges) orderNewModels(models []ModelInfo) []ModelInfo {
+	isNew := make(map[string]bool, len(models))
+	for _, model := range models {
+		isNew[model.Name] = true
+	}
+
+	created := make(map[string]bool, len(models))
+	ordered := make([]ModelInfo, 0, len(models))
+	for len(ordered) < len(models) {
+		// Take the first model whose referenced new tables exist; in a cycle, the
+		// first remaining model
+		next := -1
+		for i, model := range models {
+			if created[model.Name] {
+				continue
+			}
+			if next < 0 {
+				next = i
+			}
+			if c.referencesReady(model.Name, isNew, created) {
+				next = i
+				break
+			}
+		}
+		model := models[next]
+		created[model.Name] = true
+		ordered = append(ordered, model)
+
+		var deferred []ForeignKeyInfo
+		for _, fk := range c.NewForeignKeys {
+			if fk.Model == model.Name && (!isNew[fk.RefModel] || created[fk.RefModel]) {
+				c.TableForeignKeys[model.Name] = append(c.TableForeignKeys[model.Name], fk)
+			} else {
+				deferred = append(deferred, fk)
+			}
+		}
+		c.NewForeignKeys = deferred
+	}
+	return ordered
+}
+
+func (c *MigrationChanges) referencesReady(modelName string, isNew, created map[string]bool) bool {
+	for _, fk := range c.NewForeignKeys {
+		if fk.Model == modelName && fk.RefModel != modelName && isNew[fk.RefModel] && !created[fk.RefModel] {
+			return false
+		}
+	}
+	return true
+}
+
+// orderDeletedModels sorts deleted models so tables referencing others are dropped
+// first. Their foreign keys go with their tables.
+func orderDeletedModels(names []string, stored []ModelInfo) []string {
+	foreignKeys, _ := ModelRelations(stored)
+	remaining := make(map[string]bool, len(names))
+	for _, name := range names {
+		remaining[name] = true
+	}
+
+	sort.Strings(names)
+	ordered := make([]string, 0, len(names))
+	for len(ordered) < len(names) {
+		next := ""
+		for _, name := range names {
+			if !remaining[name] {
+				continue
+			}
+			if next == "" {
+				next = name
+			}
+			referenced := false
+			for _, fk := range foreignKeys {
+				if fk.RefModel == name && fk.Model != name && remaining[fk.Model] {
+					referenced = true
+					break
+				}
+			}
+			if !referenced {
+				next = name
+				break
+			}
+		}
+		remaining[next] = false
+		ordered = append(ordered, next)
+	}
+	return ordered
+}
+
+// underivedReferences returns the models that new foreign keys reference whose
+// TableName override GORM cannot derive from a struct name: the generated
+// constraints reference the default table name instead
+func (c *MigrationChanges) underivedReferences() []string {
+	var keys []ForeignKeyInfo
+	for _, foreignKeys := range c.TableForeignKeys {
+		keys = append(keys, foreignKeys...)
+	}
+	keys = append(keys, c.NewForeignKeys...)
+	for _, joinTable := range c.NewJoinTables {
+		keys = append(keys, joinTable.Keys[:]...)
+	}
+
+	seen := make(map[string]bool)
+	var models []string
+	for _, fk := range keys {
+		if !seen[fk.RefModel] && toSnakeCase(c.structName(fk.RefModel)) != c.tableName(fk.RefModel) {
+			seen[fk.RefModel] = true
+			models = append(models, fk.RefModel)
+		}
+	}
+	sort.Strings(models)
+	return models
+}
+
+// withoutDeletedTables drops the changes to tables that are dropped anyway
+func (c *MigrationChanges) withoutDeletedTables() {
+	deleted := make(map[string]bool, len(c.DeletedModels))
+	for _, name := range c.DeletedModels {
+		deleted[name] = true
+	}
+	var kept []ForeignKeyInfo
+	for _, fk := range c.DroppedForeignKeys {
+		if !deleted[fk.Model] {
+			kept = append(kept, fk)
+		}
+	}
+	c.DroppedForeignKeys = kept
+}
+
+// writeReferencedStructs writes minimal structs for the models the keys reference,
+// holding the referenced fields, except for owner, which references itself
+func (c *MigrationChanges) writeReferencedStructs(code *strings.Builder, owner string, foreignKeys []ForeignKeyInfo) {
+	var names []string
+	fields := make(map[string][]FieldInfo)
+	for _, fk := range foreignKeys {
+		if fk.RefModel == owner {
+			continue
+		}
+		if _, ok := fields[fk.RefModel]; !ok {
+			names = append(names, fk.RefModel)
+		}
+		fields[fk.RefModel] = appendKeyField(fields[fk.RefModel], fk.RefField, fk.RefType)
+	}
+	for _, name := range names {
+		c.writeModelStruct(code, name, fields[name])
+	}
+}
+
+// appendKeyField adds a referenced field, tagging ID as the primary key
+func appendKeyField(fields []FieldInfo, name, typ string) []FieldInfo {
+	for _, field := range fields {
+		if field.Name == name {
+			return fields
+		}
+	}
+	field := FieldInfo{Name: name, Type: typ}
+	if name == "ID" {
+		field.Tag = "`gorm:\"primarykey\"`"
+	}
+	return append(fields, field)
+}
+
+// relationField is the belongs-to field through which GORM creates a foreign key
+func (c *MigrationChanges) relationField(owner string, fk ForeignKeyInfo) FieldInfo {
+	typ := c.structName(fk.RefModel)
+	if fk.RefModel == owner {
+		typ = "*" + typ
+	}
+	// GORM only reads the name when a comma follows it
+	constraint := fk.Name + ","
+	if fk.OnDelete != "" {
+		constraint += "OnDelete:" + fk.OnDelete + ","
+	}
+	if fk.OnUpdate != "" {
+		constraint += "OnUpdate:" + fk.OnUpdate + ","
+	}
+	if fk.OnDelete != "" || fk.OnUpdate != "" {
+		constraint = strings.TrimSuffix(constraint, ",")
+	}
+	return FieldInfo{
+		Name: fk.Relation,
+		Type: typ,
+		Tag:  fmt.Sprintf("`gorm:\"foreignKey:%s;references:%s;constraint:%s\"`", fk.Field, fk.RefField, constraint),
+	}
+}
@@ -0,0 +1,117 @@
+package migrationgen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// blogModels are models related every way: has-many (User.Posts), belongs-to
+// (Comment.Post), many2many (Post.Tags) and a self-reference (User.Manager)
+func blogModels() []ModelInfo {
+	return []ModelInfo{
+		{Name: "Comment", HasBaseModel: true, Fields: []FieldInfo{
+			{Name: "Body", Type: "string"},
+			{Name: "PostID", Type: "uint"},
+			{Name: "Post", Type: "Post", Tag: "`gorm:\"constraint:OnDelete:CASCADE\"`"},
+		}},
+		{Name: "Post", HasBaseModel: true, Fields: []FieldInfo{
+			{Name: "Title", Type: "string"},
+			{Name: "UserID", Type: "uint"},
+			{Name: "Tags", Type: "[]Tag", Tag: "`gorm:\"many2many:post_tags\"`"},
+		}},
+		{Name: "Tag", HasBaseModel: true, Fields: []FieldInfo{
+			{Name: "Name", Type: "string"},
+		}},
+		{Name: "User", HasBaseModel: true, Fields: []FieldInfo{
+			{Name: "Email", Type: "string"},
+			{Name: "ManagerID", Type: "*uint"},
+			{Name: "Manager", Type: "*User"},
+			{Name: "Posts", Type: "[]Post"},
+		}},
+	}
+}
+
+func TestModelRelations(t *testing.T) {
+	foreignKeys, joinTables := ModelRelations(blogModels())
+
+	var got []string
+	for _, fk := range foreignKeys {
+		got = append(got, fk.Name+": "+fk.Model+"."+fk.Field+" -> "+fk.RefModel+"."+fk.RefField+" "+fk.OnDelete)
+	}
+	want := []string{
+		"fk_comments_post: Comment.PostID -> Post.ID CASCADE",
+		"fk_users_manager: User.ManagerID -> User.ID ",
+		"fk_users_posts: Post.UserID -> User.ID ",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("foreign keys\n got %q\nwant %q", got, want)
+	}
+
+	if len(joinTables) != 1 {
+		t.Fatalf("join tables %v", joinTables)
+	}
+	join := joinTables[0]
+	if join.Name != "post_tags" || join.Keys[0].Field != "PostID" || join.Keys[0].RefModel != "Post" ||
+		join.Keys[1].Field != "TagID" || join.Keys[1].RefModel != "Tag" {
+		t.Errorf("join table %+v", join)
+	}
+
+	// constraint:- opts out, and relations to models of other apps are not followed
+	models := blogModels()
+	models[0].Fields[2].Tag = "`gorm:\"constraint:-\"`"
+	models[3].Fields = append(models[3].Fields, FieldInfo{Name: "Profile", Type: "accounts.Profile"})
+	foreignKeys, _ = ModelRelations(models)
+	for _, fk := range foreignKeys {
+		if fk.Model == "Comment" || fk.RefModel == "Profile" {
+			t.Errorf("unexpected foreign key %+v", fk)
+		}
+	}
+}
+
+func TestRelationChanges(t *testing.T) {
+	changes := detect(t, nil, blogModels())
+
+	// Referenced tables are created first, with their foreign keys
+	var order []string
+	for _, model := range changes.NewModels {
+		order = append(order, model.Name)
+	}
+	if want := []string{"Tag", "User", "Post", "Comment"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("create order %v, want %v", order, want)
+	}
+	for _, model := range changes.NewModels {
+		for _, field := range model.Fields {
+			if strings.HasPrefix(field.Type, "[]") || field.Type == "Post" || field.Type == "*User" {
+				t.Errorf("relation field %s.%s created as a column", model.Name, field.Name)
+			}
+		}
+	}
+	if len(changes.TableForeignKeys["Comment"]) != 1 || len(changes.TableForeignKeys["Post"]) != 1 || len(changes.NewForeignKeys) != 0 {
+		t.Errorf("table foreign keys %v, added later %v", changes.TableForeignKeys, changes.NewForeignKeys)
+	}
+
+	up, down := migrationCode(t, changes)
+	assertContains(t, "up", up,
+		"Post post `gorm:\"foreignKey:PostID;references:ID;constraint:fk_comments_post,OnDelete:CASCADE\"`",
+		"Manager *user `gorm:\"foreignKey:ManagerID;references:ID;constraint:fk_users_manager,\"`",
+		`tx.Table("post_tags").Migrator().CreateTable(&join_table{})`,
+	)
+	assertContains(t, "down", down, `tx.Migrator().DropTable("post_tags")`, `tx.Migrator().DropTable("comments")`)
+	if strings.Index(down, `DropTable("comments")`) > strings.Index(down, `DropTable("posts")`) {
+		t.Errorf("a referenced table is dropped first:\n%s", down)
+	}
+
+	// Removing a relation drops its key from the remaining table
+	models := blogModels()
+	models[0].Fields = models[0].Fields[:2]
+	models[1].Fields = models[1].Fields[:2]
+	changes = detect(t, blogModels(), models)
+	if len(changes.DroppedForeignKeys) != 1 || changes.DroppedForeignKeys[0].Name != "fk_comments_post" ||
+		len(changes.DroppedJoinTables) != 1 || len(changes.NewForeignKeys) != 0 {
+		t.Errorf("dropped keys %v, join tables %v", changes.DroppedForeignKeys, changes.DroppedJoinTables)
+	}
+	up, down = migrationCode(t, changes)
+	assertContains(t, "up", up, `tx.Migrator().DropConstraint(&comment{}, "fk_comments_post")`, `tx.Migrator().DropTable("post_tags")`)
+	assertContains(t, "down", down, `tx.Migrator().CreateConstraint(&comment{}, "fk_comments_post")`)
+}
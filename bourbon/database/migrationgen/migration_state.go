@@ -1,4 +1,4 @@
-package cmd
+package migrationgen
 
 import (
 	"crypto/sha256"
@@ -17,6 +17,7 @@ type FieldState struct {
 
 type ModelState struct {
 	Name   string       `json:"name"`
+	Table  string       `json:"table,omitempty"` // set by a TableName method
 	Hash   string       `json:"hash"`
 	Fields []FieldState `json:"fields"`
 }
@@ -90,8 +91,11 @@ func ComputeModelsHash(models []ModelInfo) string {
 	})
 
 	for _, model := range sortedModels {
-		// Hash: ModelName
+		// Hash: ModelName, and the table name when TableName overrides it
 		h.Write([]byte(model.Name))
+		if model.TableName != "" {
+			h.Write([]byte("table:" + model.TableName))
+		}
 
 		// Sort fields for consistent hashing
 		sortedFields := make([]FieldInfo, len(model.Fields))
@@ -112,6 +116,9 @@ func ComputeModelsHash(models []ModelInfo) string {
 func ComputeSingleModelHash(model ModelInfo) string {
 	h := sha256.New()
 	h.Write([]byte(model.Name))
+	if model.TableName != "" {
+		h.Write([]byte("table:" + model.TableName))
+	}
 
 	// Sort fields for consistent hashing
 	sortedFields := make([]FieldInfo, len(model.Fields))
@@ -127,6 +134,24 @@ func ComputeSingleModelHash(model ModelInfo) string {
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
+// ComputeFieldSetHash hashes a model's fields without its name, so a renamed model
+// with unchanged fields hashes the same
+func ComputeFieldSetHash(fields []FieldInfo) string {
+	h := sha256.New()
+
+	sortedFields := make([]FieldInfo, len(fields))
+	copy(sortedFields, fields)
+	sort.Slice(sortedFields, func(i, j int) bool {
+		return sortedFields[i].Name < sortedFields[j].Name
+	})
+
+	for _, field := range sortedFields {
+		h.Write([]byte(fmt.Sprintf("%s:%s:%s", field.Name, field.Type, field.Tag)))
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
 func DetectModelChanges(appName string, models []ModelInfo) (bool, error) {
 	state, err := LoadMigrationState()
 	if err != nil {
@@ -171,6 +196,7 @@ func UpdateMigrationState(appName string, models []ModelInfo, migrationID string
 
 		state.Apps[appName].Models[model.Name] = &ModelState{
 			Name:   model.Name,
+			Table:  model.TableName,
 			Hash:   ComputeSingleModelHash(model),
 			Fields: fields,
 		}
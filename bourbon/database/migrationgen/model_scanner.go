@@ -0,0 +1,810 @@
+package migrationgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+// ModelInfo represents a Go struct model
+type ModelInfo struct {
+	Name         string
+	Fields       []FieldInfo
+	PackageName  string
+	FilePath     string
+	HasBaseModel bool   // embeds BaseModel (ID, timestamps, soft delete)
+	TableName    string // returned by the model's TableName method, if it has one
+}
+
+// Table returns the model's table name: its TableName override, or the name GORM's
+// default naming strategy gives it (Person -> people)
+func (m ModelInfo) Table() string {
+	if m.TableName != "" {
+		return m.TableName
+	}
+	return columnNamer.TableName(m.Name)
+}
+
+// FieldInfo represents a struct field
+type FieldInfo struct {
+	Name      string
+	Type      string
+	Tag       string
+	IsPointer bool
+}
+
+// modelsPackage is the optional subpackage of an app that holds its models
+const modelsPackage = "models"
+
+// ScanModels scans an app for model structs: every Go file in apps/<app> and in
+// the optional apps/<app>/models subpackage, so models can be split across files.
+// Structs the models embed are expanded into their fields.
+func ScanModels(appName string) ([]ModelInfo, error) {
+	appDir := filepath.Join("apps", appName)
+	modelsDir := filepath.Join(appDir, modelsPackage)
+
+	var files []*sourceFile
+	for _, dir := range []string{appDir, modelsDir} {
+		parsed, err := parseGoFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, parsed...)
+	}
+
+	// Import paths of the app's own packages, to resolve embedded structs
+	// declared in the other one (e.g. models.Timestamps)
+	packages := map[string]string{
+		"apps/" + appName:                       appDir,
+		"apps/" + appName + "/" + modelsPackage: modelsDir,
+	}
+	return scanSourceFiles(files, packages), nil
+}
+
+// scanModelFile returns the model structs declared anywhere in a Go file
+func scanModelFile(path string) ([]ModelInfo, error) {
+	file, err := parseGoFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return scanSourceFiles([]*sourceFile{file}, nil), nil
+}
+
+// sourceFile is a parsed Go file and the directory (package) it belongs to
+type sourceFile struct {
+	path string
+	dir  string
+	node *ast.File
+}
+
+// parseGoFiles parses the non-test Go files of a directory, which may not exist
+func parseGoFiles(dir string) ([]*sourceFile, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*sourceFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parseGoFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func parseGoFile(path string) (*sourceFile, error) {
+	node, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &sourceFile{path: path, dir: filepath.Dir(path), node: node}, nil
+}
+
+// ignoreDirective in a type's doc comment excludes it from the models, e.g. for
+// query result structs that embed a model
+const ignoreDirective = "//bourbon:ignore"
+
+func hasIgnoreDirective(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, comment := range doc.List {
+		if strings.TrimSpace(comment.Text) == ignoreDirective {
+			return true
+		}
+	}
+	return false
+}
+
+// structDecl is a struct type declared in a scanned file
+type structDecl struct {
+	name string
+	file *sourceFile
+	typ  *ast.StructType
+}
+
+// structScanner reads the fields of structs, resolving embedded structs among
+// the scanned files
+type structScanner struct {
+	structs  map[string]structDecl // by "<dir>.<name>"
+	packages map[string]string     // import path suffix -> dir
+	embedded map[string]bool       // structs embedded by the struct being read
+}
+
+// scanSourceFiles returns the models declared in files: structs embedding
+// BaseModel (or gorm.Model), and structs declaring their own primary key (e.g.
+// composite-key join tables). Structs embedded by models are not models themselves,
+// and types marked //bourbon:ignore are skipped.
+func scanSourceFiles(files []*sourceFile, packages map[string]string) []ModelInfo {
+	s := &structScanner{
+		structs:  make(map[string]structDecl),
+		packages: packages,
+	}
+
+	var decls []structDecl
+	ignored := make(map[*ast.TypeSpec]bool)
+	tableNames := make(map[string]ast.Expr)     // "<dir>.<type>" -> TableName's return value
+	constants := make(map[string]*ast.BasicLit) // "<dir>.<name>" -> value
+	for _, file := range files {
+		ast.Inspect(file.node, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				if receiver, value, ok := tableNameMethod(n); ok {
+					tableNames[file.dir+"."+receiver] = value
+				}
+			case *ast.ValueSpec:
+				for i, name := range n.Names {
+					if i < len(n.Values) {
+						if lit, ok := n.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+							constants[file.dir+"."+name.Name] = lit
+						}
+					}
+				}
+			case *ast.GenDecl:
+				if hasIgnoreDirective(n.Doc) {
+					for _, spec := range n.Specs {
+						if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+							ignored[typeSpec] = true
+						}
+					}
+				}
+			case *ast.TypeSpec:
+				structType, ok := n.Type.(*ast.StructType)
+				if !ok {
+					return true
+				}
+				decl := structDecl{name: n.Name.Name, file: file, typ: structType}
+				key := file.dir + "." + decl.name
+				if _, exists := s.structs[key]; !exists {
+					s.structs[key] = decl
+				}
+				if !ignored[n] && !hasIgnoreDirective(n.Doc) {
+					decls = append(decls, decl)
+				}
+			}
+			return true
+		})
+	}
+
+	type scanned struct {
+		decl         structDecl
+		fields       []FieldInfo
+		hasBaseModel bool
+	}
+	var candidates []scanned
+	embeddedByModels := make(map[string]bool)
+	for _, decl := range decls {
+		s.embedded = make(map[string]bool)
+		fields, hasBaseModel := s.fields(decl, map[string]bool{})
+		if hasBaseModel || hasPrimaryKeyField(fields) {
+			candidates = append(candidates, scanned{decl, fields, hasBaseModel})
+			for key := range s.embedded {
+				embeddedByModels[key] = true
+			}
+		}
+	}
+
+	var models []ModelInfo
+	for _, c := range candidates {
+		key := c.decl.file.dir + "." + c.decl.name
+		if embeddedByModels[key] {
+			continue
+		}
+		model := ModelInfo{
+			Name:         c.decl.name,
+			Fields:       c.fields,
+			PackageName:  c.decl.file.node.Name.Name,
+			FilePath:     c.decl.file.path,
+			HasBaseModel: c.hasBaseModel,
+		}
+		if value, ok := tableNames[key]; ok {
+			if ident, ok := value.(*ast.Ident); ok && constants[c.decl.file.dir+"."+ident.Name] != nil {
+				value = constants[c.decl.file.dir+"."+ident.Name]
+			}
+			lit, ok := value.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				fmt.Fprintf(os.Stderr, "Warning: %s.TableName() does not return a string constant; using table %s\n", model.Name, model.Table())
+			} else {
+				model.TableName, _ = strconv.Unquote(lit.Value)
+			}
+		}
+		models = append(models, model)
+	}
+	return models
+}
+
+// tableNameMethod returns the receiver type and returned value of a
+// TableName() string method that consists of a single return statement
+func tableNameMethod(fn *ast.FuncDecl) (string, ast.Expr, bool) {
+	if fn.Name.Name != "TableName" || fn.Recv == nil || len(fn.Recv.List) != 1 || fn.Body == nil {
+		return "", nil, false
+	}
+	if fn.Type.Params.NumFields() != 0 || fn.Type.Results.NumFields() != 1 {
+		return "", nil, false
+	}
+
+	receiver := fn.Recv.List[0].Type
+	if star, ok := receiver.(*ast.StarExpr); ok {
+		receiver = star.X
+	}
+	ident, ok := receiver.(*ast.Ident)
+	if !ok {
+		return "", nil, false
+	}
+
+	var value ast.Expr
+	if len(fn.Body.List) == 1 {
+		if ret, ok := fn.Body.List[0].(*ast.ReturnStmt); ok && len(ret.Results) == 1 {
+			value = ret.Results[0]
+		}
+	}
+	return ident.Name, value, true
+}
+
+// fields returns a struct's fields, with embedded structs expanded the way GORM
+// flattens them: anonymous fields and fields tagged embedded, with any
+// embeddedPrefix applied to their columns. resolving guards against cycles.
+func (s *structScanner) fields(decl structDecl, resolving map[string]bool) ([]FieldInfo, bool) {
+	hasBaseModel := false
+	var fields []FieldInfo
+
+	for _, field := range decl.typ.Fields.List {
+		tag := ""
+		if field.Tag != nil {
+			tag = field.Tag.Value
+		}
+
+		if len(field.Names) == 0 {
+			if s.isBaseModel(decl.file, field.Type) {
+				hasBaseModel = true
+				continue
+			}
+			// Embedded types declared outside the app are skipped
+			if embedded, base, ok := s.embed(decl.file, field.Type, resolving); ok {
+				prefix, _ := FieldInfo{Tag: tag}.GormSetting("embeddedPrefix")
+				fields = append(fields, prefixColumns(embedded, "", prefix)...)
+				hasBaseModel = hasBaseModel || base
+			}
+			continue
+		}
+
+		for _, name := range field.Names {
+			fieldInfo := FieldInfo{
+				Name: name.Name,
+				Type: exprToString(field.Type),
+				Tag:  tag,
+			}
+
+			// Check if pointer
+			if _, ok := field.Type.(*ast.StarExpr); ok {
+				fieldInfo.IsPointer = true
+			}
+
+			if _, ok := fieldInfo.GormSetting("embedded"); ok {
+				if embedded, _, ok := s.embed(decl.file, field.Type, resolving); ok {
+					prefix, _ := fieldInfo.GormSetting("embeddedPrefix")
+					fields = append(fields, prefixColumns(embedded, name.Name, prefix)...)
+					continue
+				}
+			}
+
+			fields = append(fields, fieldInfo)
+		}
+	}
+	return fields, hasBaseModel
+}
+
+// embed returns the fields of an embedded struct declared in the scanned files
+func (s *structScanner) embed(file *sourceFile, expr ast.Expr, resolving map[string]bool) ([]FieldInfo, bool, bool) {
+	key := s.structKey(file, expr)
+	decl, ok := s.structs[key]
+	if !ok || resolving[key] {
+		return nil, false, false
+	}
+	s.embedded[key] = true
+
+	resolving[key] = true
+	defer delete(resolving, key)
+	fields, hasBaseModel := s.fields(decl, resolving)
+	return fields, hasBaseModel, true
+}
+
+// structKey returns the key a type expression would have in s.structs
+func (s *structScanner) structKey(file *sourceFile, expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return file.dir + "." + t.Name
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		if !ok {
+			return ""
+		}
+		path := importPath(file.node, pkg.Name)
+		for suffix, dir := range s.packages {
+			if path == suffix || strings.HasSuffix(path, "/"+suffix) {
+				return dir + "." + t.Sel.Name
+			}
+		}
+	}
+	return ""
+}
+
+// isBaseModel reports whether an embedded type is Bourbon's BaseModel or
+// gorm.Model, which have the same fields
+func (s *structScanner) isBaseModel(file *sourceFile, expr ast.Expr) bool {
+	selector, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	if selector.Sel.Name == "BaseModel" {
+		return true
+	}
+	pkg, ok := selector.X.(*ast.Ident)
+	return ok && selector.Sel.Name == "Model" && importPath(file.node, pkg.Name) == "gorm.io/gorm"
+}
+
+// importPath returns the path a file imports under the given package name
+func importPath(file *ast.File, name string) string {
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		local := pathpkg.Base(path)
+		if imp.Name != nil {
+			local = imp.Name.Name
+		}
+		if local == name {
+			return path
+		}
+	}
+	return ""
+}
+
+// prefixColumns renames embedded fields after the field embedding them (for
+// named embedded fields) and prefixes their columns with embeddedPrefix
+func prefixColumns(fields []FieldInfo, namePrefix, columnPrefix string) []FieldInfo {
+	if namePrefix == "" && columnPrefix == "" {
+		return fields
+	}
+	prefixed := make([]FieldInfo, 0, len(fields))
+	for _, field := range fields {
+		column := columnPrefix + field.ColumnName()
+		field.Name = namePrefix + field.Name
+		prefixed = append(prefixed, field.WithColumn(column))
+	}
+	return prefixed
+}
+
+// constraintNamePattern matches explicit constraint names in check tags
+var constraintNamePattern = regexp.MustCompile(`^[A-Za-z-_]+$`)
+
+// columnNamer derives column names the same way GORM does by default
+var columnNamer = schema.NamingStrategy{}
+
+// hasPrimaryKeyField reports whether any field is tagged as a primary key
+func hasPrimaryKeyField(fields []FieldInfo) bool {
+	for _, field := range fields {
+		if field.IsPrimaryKey() {
+			return true
+		}
+	}
+	return false
+}
+
+// GormSetting returns the value of a gorm tag setting (case-insensitive key)
+// and whether the setting is present, e.g. GormSetting("check") for `gorm:"check:age > 0"`
+func (f FieldInfo) GormSetting(key string) (string, bool) {
+	for _, setting := range f.gormSettings() {
+		name, value, _ := strings.Cut(setting, ":")
+		if strings.EqualFold(strings.TrimSpace(name), key) {
+			return strings.TrimSpace(value), true
+		}
+	}
+	return "", false
+}
+
+// gormSettings returns the raw "name:value" settings of the field's gorm tag
+func (f FieldInfo) gormSettings() []string {
+	if f.Tag == "" {
+		return nil
+	}
+
+	raw, err := strconv.Unquote(f.Tag)
+	if err != nil {
+		raw = strings.Trim(f.Tag, "`")
+	}
+	return strings.Split(reflect.StructTag(raw).Get("gorm"), ";")
+}
+
+// IndexInfo is an index declared with index or uniqueIndex tags. Fields tagged
+// with the same index name form a composite index.
+type IndexInfo struct {
+	Name       string
+	Unique     bool
+	Fields     []FieldInfo // in column order
+	definition string      // columns and options, to detect changed indexes
+}
+
+// ModelIndexes returns the indexes declared by a model's field tags, named and
+// ordered the same way GORM does
+func ModelIndexes(tableName string, fields []FieldInfo) []IndexInfo {
+	type indexField struct {
+		field    FieldInfo
+		priority int
+		options  string
+	}
+	var names []string
+	unique := make(map[string]bool)
+	members := make(map[string][]indexField)
+
+	for _, field := range fields {
+		for _, setting := range field.gormSettings() {
+			key, value, _ := strings.Cut(setting, ":")
+			key = strings.ToUpper(strings.TrimSpace(key))
+			if key != "INDEX" && key != "UNIQUEINDEX" {
+				continue
+			}
+
+			name, options, _ := strings.Cut(value, ",")
+			settings := schema.ParseTagSetting(options, ",")
+			if name == "" {
+				subName := field.Name
+				if composite := settings["COMPOSITE"]; composite != "" {
+					subName = composite
+				}
+				name = columnNamer.IndexName(tableName, subName)
+			}
+			if _, seen := members[name]; !seen {
+				names = append(names, name)
+			}
+			if key == "UNIQUEINDEX" || settings["UNIQUE"] != "" || strings.EqualFold(settings["CLASS"], "UNIQUE") {
+				unique[name] = true
+			}
+
+			priority, err := strconv.Atoi(settings["PRIORITY"])
+			if err != nil {
+				priority = 10
+			}
+			members[name] = append(members[name], indexField{field: field, priority: priority, options: options})
+		}
+	}
+
+	indexes := make([]IndexInfo, 0, len(names))
+	for _, name := range names {
+		sort.SliceStable(members[name], func(i, j int) bool {
+			return members[name][i].priority < members[name][j].priority
+		})
+
+		index := IndexInfo{Name: name, Unique: unique[name]}
+		var definition strings.Builder
+		fmt.Fprintf(&definition, "unique=%t", index.Unique)
+		for _, member := range members[name] {
+			index.Fields = append(index.Fields, member.field)
+			fmt.Fprintf(&definition, ";%s:%s", columnNamer.ColumnName("", member.field.Name), member.options)
+		}
+		index.definition = definition.String()
+		indexes = append(indexes, index)
+	}
+	return indexes
+}
+
+// IsNotNull reports whether the field is tagged not null
+func (f FieldInfo) IsNotNull() bool {
+	if _, ok := f.GormSetting("not null"); ok {
+		return true
+	}
+	_, ok := f.GormSetting("notnull")
+	return ok
+}
+
+// NeedsBackfill reports whether adding the field's column to a table with rows
+// fails: it is NOT NULL without a default
+func (f FieldInfo) NeedsBackfill() bool {
+	_, hasDefault := f.GormSetting("default")
+	return f.IsNotNull() && !hasDefault && !f.IsPrimaryKey()
+}
+
+// notNullSetting matches the not null setting of a gorm tag
+var notNullSetting = regexp.MustCompile(`(?i)\bnot ?null\b;?`)
+
+// Nullable returns the field without its not null setting
+func (f FieldInfo) Nullable() FieldInfo {
+	f.Tag = notNullSetting.ReplaceAllString(f.Tag, "")
+	return f
+}
+
+// columnSetting matches the column setting of a gorm tag
+var columnSetting = regexp.MustCompile(`(?i)\bcolumn:[^;"]*;?`)
+
+// gormTagStart matches the start of a struct tag's gorm key
+var gormTagStart = regexp.MustCompile(`\bgorm:"`)
+
+// WithColumn returns the field with its column name set in its gorm tag
+func (f FieldInfo) WithColumn(column string) FieldInfo {
+	tag, err := strconv.Unquote(f.Tag)
+	if err != nil {
+		tag = strings.Trim(f.Tag, "`")
+	}
+	tag = columnSetting.ReplaceAllString(tag, "")
+
+	setting := "column:" + column
+	if loc := gormTagStart.FindStringIndex(tag); loc != nil {
+		if !strings.HasPrefix(tag[loc[1]:], `"`) {
+			setting += ";"
+		}
+		tag = tag[:loc[1]] + setting + tag[loc[1]:]
+	} else {
+		tag = strings.TrimSpace(`gorm:"` + setting + `" ` + tag)
+	}
+	f.Tag = "`" + tag + "`"
+	return f
+}
+
+// ColumnName returns the field's column name: its column tag, or GORM's default
+func (f FieldInfo) ColumnName() string {
+	if column, ok := f.GormSetting("column"); ok && column != "" {
+		return column
+	}
+	return columnNamer.ColumnName("", f.Name)
+}
+
+// IsPrimaryKey reports whether the field is tagged as (part of) the primary key
+func (f FieldInfo) IsPrimaryKey() bool {
+	if _, ok := f.GormSetting("primaryKey"); ok {
+		return true
+	}
+	_, ok := f.GormSetting("primary_key")
+	return ok
+}
+
+// CheckConstraintName returns the name GORM gives the field's check constraint,
+// and false when the field has no check tag
+func (f FieldInfo) CheckConstraintName(tableName string) (string, bool) {
+	check, ok := f.GormSetting("check")
+	if !ok {
+		return "", false
+	}
+
+	// check:name,expression uses an explicit constraint name (same rule as GORM)
+	if name, _, found := strings.Cut(check, ","); found && constraintNamePattern.MatchString(name) {
+		return name, true
+	}
+	return fmt.Sprintf("chk_%s_%s", tableName, columnNamer.ColumnName("", f.Name)), true
+}
+
+// exprToString converts an AST expression to a type string
+func exprToString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return "*" + exprToString(t.X)
+	case *ast.SelectorExpr:
+		return exprToString(t.X) + "." + t.Sel.Name
+	case *ast.ArrayType:
+		return "[]" + exprToString(t.Elt)
+	default:
+		return "interface{}"
+	}
+}
+
+// GenerateMigrationCode generates migration code for models
+func GenerateMigrationCode(models []ModelInfo, appName string) string {
+	if len(models) == 0 {
+		return ""
+	}
+
+	var code strings.Builder
+
+	// Generate AutoMigrate call
+	code.WriteString("\t\t\t// Auto-migrate models\n")
+	code.WriteString("\t\t\treturn db.AutoMigrate(\n")
+
+	for _, model := range models {
+		code.WriteString(fmt.Sprintf("\t\t\t\t&%s.%s{},\n", appName, model.Name))
+	}
+
+	code.WriteString("\t\t\t)")
+
+	return code.String()
+}
+
+// GenerateRollbackCode generates rollback code for models
+func GenerateRollbackCode(models []ModelInfo) string {
+	if len(models) == 0 {
+		return ""
+	}
+
+	var code strings.Builder
+
+	code.WriteString("\t\t\t// Drop tables\n")
+	code.WriteString("\t\t\treturn db.Migrator().DropTable(\n")
+
+	for _, model := range models {
+		code.WriteString(fmt.Sprintf("\t\t\t\t\"%s\",\n", model.Table()))
+	}
+
+	code.WriteString("\t\t\t)")
+
+	return code.String()
+}
+
+// toSnakeCase returns the table name GORM's default naming strategy gives a model
+// name: snake_case and pluralized (Person -> people)
+func toSnakeCase(s string) string {
+	return columnNamer.TableName(s)
+}
+
+// GetTableNames extracts table names from models
+func GetTableNames(models []ModelInfo) []string {
+	var tables []string
+	for _, model := range models {
+		tables = append(tables, model.Table())
+	}
+	return tables
+}
+
+// GenerateInlineStructs generates inline struct definitions for migration
+func GenerateInlineStructs(models []ModelInfo) string {
+	if len(models) == 0 {
+		return ""
+	}
+
+	var code strings.Builder
+
+	for _, model := range models {
+		code.WriteString(fmt.Sprintf("\t\ttype %s struct {\n", model.Name))
+
+		// Add BaseModel fields
+		if model.HasBaseModel {
+			code.WriteString("\t\t\tID        uint      `gorm:\"primarykey\"`\n")
+			code.WriteString("\t\t\tCreatedAt time.Time\n")
+			code.WriteString("\t\t\tUpdatedAt time.Time\n")
+			code.WriteString("\t\t\tDeletedAt gorm.DeletedAt `gorm:\"index\"`\n")
+		}
+
+		// Add model-specific fields
+		for _, field := range model.Fields {
+			tagStr := ""
+			if field.Tag != "" {
+				tagStr = " " + field.Tag
+			}
+			code.WriteString(fmt.Sprintf("\t\t\t%s %s%s\n", field.Name, field.Type, tagStr))
+		}
+
+		code.WriteString("\t\t}\n")
+	}
+
+	return code.String()
+}
+
+// GenerateInlineAutoMigrate generates AutoMigrate call with inline types
+func GenerateInlineAutoMigrate(models []ModelInfo) string {
+	if len(models) == 0 {
+		return ""
+	}
+
+	var code strings.Builder
+	code.WriteString("\t\treturn db.AutoMigrate(\n")
+
+	for _, model := range models {
+		code.WriteString(fmt.Sprintf("\t\t\t&%s{},\n", model.Name))
+	}
+
+	code.WriteString("\t\t)")
+
+	return code.String()
+}
+
+// GenerateInlineDropTable generates DropTable call with inline table names
+func GenerateInlineDropTable(models []ModelInfo) string {
+	if len(models) == 0 {
+		return ""
+	}
+
+	var code strings.Builder
+	code.WriteString("\t\treturn db.Migrator().DropTable(\n")
+
+	for _, model := range models {
+		code.WriteString(fmt.Sprintf("\t\t\t\"%s\",\n", model.Table()))
+	}
+
+	code.WriteString("\t\t)")
+
+	return code.String()
+}
+
+// GenerateDropColumnCode generates code to drop deleted columns
+func GenerateDropColumnCode(deletedFields map[string][]string) string {
+	if len(deletedFields) == 0 {
+		return ""
+	}
+
+	var code strings.Builder
+	code.WriteString("\t\t// Drop deleted columns\n")
+
+	for modelName, fields := range deletedFields {
+		tableName := toSnakeCase(modelName)
+		for _, fieldName := range fields {
+			columnName := fieldToSnakeCase(fieldName)
+			// Use table name in string format for DropColumn
+			code.WriteString(fmt.Sprintf("\t\tif err := db.Migrator().DropColumn(\"%s\", \"%s\"); err != nil {\n",
+				tableName, columnName))
+			code.WriteString("\t\t\treturn err\n")
+			code.WriteString("\t\t}\n")
+		}
+	}
+
+	return code.String()
+}
+
+// fieldToSnakeCase converts CamelCase to snake_case WITHOUT pluralization (for
+// columns), the way GORM does (HTTPStatus -> http_status)
+func fieldToSnakeCase(s string) string {
+	return columnNamer.ColumnName("", s)
+}
+
+// GenerateInlineAutoMigrateNoReturn generates AutoMigrate call without return statement
+func GenerateInlineAutoMigrateNoReturn(models []ModelInfo) string {
+	if len(models) == 0 {
+		return ""
+	}
+
+	var code strings.Builder
+	code.WriteString("\t\tif err := db.AutoMigrate(\n")
+
+	for _, model := range models {
+		code.WriteString(fmt.Sprintf("\t\t\t&%s{},\n", model.Name))
+	}
+
+	code.WriteString("\t\t); err != nil {\n")
+	code.WriteString("\t\t\treturn err\n")
+	code.WriteString("\t\t}\n")
+
+	return code.String()
+}
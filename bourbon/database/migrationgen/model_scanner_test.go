@@ -0,0 +1,226 @@
+package migrationgen
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestModelIndexes(t *testing.T) {
+	fields := []FieldInfo{
+		{Name: "Email", Type: "string", Tag: "`gorm:\"uniqueIndex\"`"},
+		{Name: "Slug", Type: "string", Tag: "`gorm:\"index:idx_slug_site,priority:2\"`"},
+		{Name: "SiteID", Type: "uint", Tag: "`gorm:\"index:idx_slug_site,priority:1\"`"},
+		{Name: "Code", Type: "string", Tag: "`gorm:\"index:,unique\"`"},
+		{Name: "Name", Type: "string", Tag: "`gorm:\"index:,composite:name_kind\"`"},
+		{Name: "Kind", Type: "string", Tag: "`gorm:\"index:,composite:name_kind\"`"},
+		{Name: "Body", Type: "string"},
+	}
+
+	type index struct {
+		Name    string
+		Unique  bool
+		Columns []string
+	}
+	var got []index
+	for _, i := range ModelIndexes("users", fields) {
+		got = append(got, index{i.Name, i.Unique, nil})
+		for _, field := range i.Fields {
+			got[len(got)-1].Columns = append(got[len(got)-1].Columns, field.Name)
+		}
+	}
+	want := []index{
+		{"idx_users_email", true, []string{"Email"}},
+		{"idx_slug_site", false, []string{"SiteID", "Slug"}},
+		{"idx_users_code", true, []string{"Code"}},
+		{"idx_users_name_kind", false, []string{"Name", "Kind"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ModelIndexes\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestCheckConstraintName(t *testing.T) {
+	tests := []struct {
+		tag  string
+		name string
+		ok   bool
+	}{
+		{"`gorm:\"check:age >= 0\"`", "chk_users_age", true},
+		{"`gorm:\"check:adult,age >= 18\"`", "adult", true},
+		{"`gorm:\"check:age >= 0, age < 200\"`", "chk_users_age", true},
+		{"`gorm:\"not null\"`", "", false},
+	}
+	for _, tt := range tests {
+		name, ok := FieldInfo{Name: "Age", Type: "int", Tag: tt.tag}.CheckConstraintName("users")
+		if name != tt.name || ok != tt.ok {
+			t.Errorf("CheckConstraintName(%s) = %q, %v; want %q, %v", tt.tag, name, ok, tt.name, tt.ok)
+		}
+	}
+}
+
+func TestNeedsBackfill(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want bool
+	}{
+		{"`gorm:\"not null\"`", true},
+		{"`gorm:\"size:20;NOT NULL\"`", true},
+		{"`gorm:\"notnull\"`", true},
+		{"`gorm:\"not null;default:0\"`", false},
+		{"`gorm:\"primaryKey;not null\"`", false},
+		{"`gorm:\"size:20\"`", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := (FieldInfo{Name: "Code", Type: "string", Tag: tt.tag}).NeedsBackfill(); got != tt.want {
+			t.Errorf("NeedsBackfill(%s) = %v, want %v", tt.tag, got, tt.want)
+		}
+	}
+}
+
+// writeApp writes Go files of an app under the current directory
+func writeApp(t *testing.T, files map[string]string) {
+	t.Helper()
+	for path, source := range files {
+		path = filepath.Join("apps", "blog", path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestScanModels(t *testing.T) {
+	inTempDir(t)
+	writeApp(t, map[string]string{
+		"post.go": `package blog
+
+import (
+	"example.com/site/apps/blog/models"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+)
+
+type Post struct {
+	orm.BaseModel
+	models.Timestamps
+	Title  string ` + "`gorm:\"size:200\"`" + `
+	Author Author ` + "`gorm:\"embedded;embeddedPrefix:author_\"`" + `
+}
+
+type Author struct {
+	Name  string
+	Email string
+}
+
+// PostSummary is a query result, not a table
+//
+//bourbon:ignore
+type PostSummary struct {
+	Post
+	Comments int
+}
+
+type listParams struct {
+	Page int
+}
+`,
+		"post_test.go": `package blog
+
+import "github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+
+type Fixture struct {
+	orm.BaseModel
+}
+`,
+		"models/tag.go": `package models
+
+import "time"
+
+type Timestamps struct {
+	PublishedAt *time.Time
+}
+
+type PostTag struct {
+	PostID uint ` + "`gorm:\"primaryKey\"`" + `
+	TagID  uint ` + "`gorm:\"primaryKey\"`" + `
+}
+`,
+	})
+
+	models, err := ScanModels("blog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string][]string)
+	for _, model := range models {
+		for _, field := range model.Fields {
+			got[model.Name] = append(got[model.Name], field.Name+":"+field.ColumnName())
+		}
+	}
+	want := map[string][]string{
+		"Post":    {"PublishedAt:published_at", "Title:title", "AuthorName:author_name", "AuthorEmail:author_email"},
+		"PostTag": {"PostID:post_id", "TagID:tag_id"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ScanModels\n got %v\nwant %v", got, want)
+	}
+	for _, model := range models {
+		if model.Name == "Post" && (!model.HasBaseModel || model.PackageName != "blog") {
+			t.Errorf("Post scanned as %+v", model)
+		}
+	}
+
+	// An app without Go files has no models
+	if models, err := ScanModels("shop"); err != nil || len(models) != 0 {
+		t.Errorf("ScanModels of a missing app = %v, %v", models, err)
+	}
+}
+
+func TestTableNames(t *testing.T) {
+	inTempDir(t)
+	writeApp(t, map[string]string{
+		"models.go": `package blog
+
+import "github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+
+const categoryTable = "blog_categories"
+
+type Person struct {
+	orm.BaseModel
+}
+
+type HTTPLog struct {
+	orm.BaseModel
+}
+
+type Category struct {
+	orm.BaseModel
+}
+
+func (Category) TableName() string { return categoryTable }
+
+type Entry struct {
+	orm.BaseModel
+}
+
+func (*Entry) TableName() string { return "journal" }
+`,
+	})
+
+	models, err := ScanModels("blog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, model := range models {
+		got[model.Name] = model.Table()
+	}
+	want := map[string]string{"Person": "people", "HTTPLog": "http_logs", "Category": "blog_categories", "Entry": "journal"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tables %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,273 @@
+package orm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/auth"
+	"gorm.io/gorm"
+)
+
+// Audit actions recorded in AuditLog.Action
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// auditOldValuesKey stores pre-change values on the statement between before/after callbacks
+const auditOldValuesKey = "bourbon:audit_old_values"
+
+// auditSkippedColumns are timestamp columns left out of diffs
+var auditSkippedColumns = map[string]bool{"created_at": true, "updated_at": true, "deleted_at": true}
+
+// Auditable marks a model whose creates, updates and deletes are recorded in audit_logs
+type Auditable interface {
+	// AuditIgnore lists column names excluded from recorded values, e.g. password hashes
+	AuditIgnore() []string
+}
+
+// AuditLog records one change to an auditable model
+type AuditLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Table     string    `gorm:"column:table_name;size:100;index:idx_audit_record" json:"table_name"`
+	RecordID  string    `gorm:"size:100;index:idx_audit_record" json:"record_id"`
+	Action    string    `gorm:"size:10;index" json:"action"`
+	OldValues string    `gorm:"type:text" json:"old_values,omitempty"`
+	NewValues string    `gorm:"type:text" json:"new_values,omitempty"`
+	ActorID   *uint     `gorm:"index" json:"actor_id,omitempty"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// Old decodes the recorded values before the change
+func (a *AuditLog) Old() map[string]interface{} {
+	return decodeAuditValues(a.OldValues)
+}
+
+// New decodes the recorded values after the change
+func (a *AuditLog) New() map[string]interface{} {
+	return decodeAuditValues(a.NewValues)
+}
+
+// EnableAuditing creates the audit_logs table and registers callbacks that record changes
+// to Auditable models. The actor is read from the statement context, so pass the request
+// context with db.WithContext(ctx) in handlers.
+func EnableAuditing(db *gorm.DB) error {
+	if err := Primary(db).AutoMigrate(&AuditLog{}); err != nil {
+		return fmt.Errorf("failed to migrate audit_logs: %w", err)
+	}
+
+	if err := db.Callback().Create().After("gorm:create").Register("bourbon:audit_create", auditAfter(AuditCreate)); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("bourbon:audit_before_update", auditBefore); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("bourbon:audit_update", auditAfter(AuditUpdate)); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("bourbon:audit_before_delete", auditBefore); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().After("gorm:delete").Register("bourbon:audit_delete", auditAfter(AuditDelete)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AuditHistory returns the audit trail of one record, oldest first
+func AuditHistory(db *gorm.DB, model interface{}) ([]AuditLog, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	field := stmt.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return nil, fmt.Errorf("model %s has no primary key", stmt.Schema.Name)
+	}
+	value, _ := field.ValueOf(db.Statement.Context, reflect.Indirect(reflect.ValueOf(model)))
+
+	var logs []AuditLog
+	err := db.Where("table_name = ? AND record_id = ?", stmt.Schema.Table, fmt.Sprint(value)).
+		Order("created_at ASC, id ASC").Find(&logs).Error
+	return logs, err
+}
+
+// AuditsByActor returns the most recent changes made by a user
+func AuditsByActor(db *gorm.DB, actorID uint, limit int) ([]AuditLog, error) {
+	var logs []AuditLog
+	err := db.Where("actor_id = ?", actorID).Order("created_at DESC, id DESC").Limit(limit).Find(&logs).Error
+	return logs, err
+}
+
+// AuditsForTable returns the most recent changes to a table
+func AuditsForTable(db *gorm.DB, table string, limit int) ([]AuditLog, error) {
+	var logs []AuditLog
+	err := db.Where("table_name = ?", table).Order("created_at DESC, id DESC").Limit(limit).Find(&logs).Error
+	return logs, err
+}
+
+// auditable returns the model's Auditable implementation, or nil if the statement is not audited
+func auditable(db *gorm.DB) Auditable {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Schema == nil || stmt.Schema.PrioritizedPrimaryField == nil {
+		return nil
+	}
+	if a, ok := reflect.New(stmt.Schema.ModelType).Interface().(Auditable); ok {
+		return a
+	}
+	return nil
+}
+
+// auditBefore loads the current row values of each record about to change
+func auditBefore(db *gorm.DB) {
+	if auditable(db) == nil {
+		return
+	}
+	old := make(map[string]map[string]interface{})
+	for _, id := range auditRecordIDs(db) {
+		if values := loadAuditValues(db, id); values != nil {
+			old[fmt.Sprint(id)] = values
+		}
+	}
+	db.InstanceSet(auditOldValuesKey, old)
+}
+
+// auditAfter writes an AuditLog row for each changed record in the same transaction
+func auditAfter(action string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		model := auditable(db)
+		if model == nil {
+			return
+		}
+
+		ignored := make(map[string]bool)
+		for _, col := range model.AuditIgnore() {
+			ignored[col] = true
+		}
+
+		var oldByID map[string]map[string]interface{}
+		if v, ok := db.InstanceGet(auditOldValuesKey); ok {
+			oldByID, _ = v.(map[string]map[string]interface{})
+		}
+
+		var actor *uint
+		if userID, ok := auth.UserIDFromContext(db.Statement.Context); ok {
+			actor = &userID
+		}
+
+		for _, id := range auditRecordIDs(db) {
+			key := fmt.Sprint(id)
+			var oldValues, newValues map[string]interface{}
+			switch action {
+			case AuditCreate:
+				newValues = loadAuditValues(db, id)
+			case AuditUpdate:
+				oldValues = oldByID[key]
+				newValues = loadAuditValues(db, id)
+				oldValues, newValues = auditDiff(oldValues, newValues)
+				if len(newValues) == 0 {
+					continue
+				}
+			case AuditDelete:
+				oldValues = oldByID[key]
+			}
+
+			entry := &AuditLog{
+				Table:     db.Statement.Schema.Table,
+				RecordID:  key,
+				Action:    action,
+				OldValues: encodeAuditValues(oldValues, ignored),
+				NewValues: encodeAuditValues(newValues, ignored),
+				ActorID:   actor,
+			}
+			if err := db.Session(&gorm.Session{NewDB: true}).Create(entry).Error; err != nil {
+				_ = db.AddError(fmt.Errorf("failed to write audit log: %w", err))
+				return
+			}
+		}
+	}
+}
+
+// auditRecordIDs returns the primary key values of the records in the statement.
+// Bulk operations without model values (e.g. Where(...).Delete(&Post{})) are not audited.
+func auditRecordIDs(db *gorm.DB) []interface{} {
+	stmt := db.Statement
+	field := stmt.Schema.PrioritizedPrimaryField
+	rv := reflect.Indirect(stmt.ReflectValue)
+
+	var ids []interface{}
+	collect := func(v reflect.Value) {
+		if value, zero := field.ValueOf(stmt.Context, v); !zero {
+			ids = append(ids, value)
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			collect(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		collect(rv)
+	}
+	return ids
+}
+
+// loadAuditValues reads a row as a column map, including soft-deleted rows
+func loadAuditValues(db *gorm.DB, id interface{}) map[string]interface{} {
+	stmt := db.Statement
+	values := make(map[string]interface{})
+	err := db.Session(&gorm.Session{NewDB: true}).Unscoped().Table(stmt.Schema.Table).
+		Where(map[string]interface{}{stmt.Schema.PrioritizedPrimaryField.DBName: id}).
+		Take(&values).Error
+	if err != nil {
+		return nil
+	}
+	for col := range values {
+		if auditSkippedColumns[col] {
+			delete(values, col)
+		}
+	}
+	return values
+}
+
+// auditDiff keeps only the columns whose values changed
+func auditDiff(oldValues, newValues map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	oldDiff := make(map[string]interface{})
+	newDiff := make(map[string]interface{})
+	for col, newVal := range newValues {
+		oldVal := oldValues[col]
+		oldJSON, _ := json.Marshal(oldVal)
+		newJSON, _ := json.Marshal(newVal)
+		if string(oldJSON) != string(newJSON) {
+			oldDiff[col] = oldVal
+			newDiff[col] = newVal
+		}
+	}
+	return oldDiff, newDiff
+}
+
+func encodeAuditValues(values map[string]interface{}, ignored map[string]bool) string {
+	if len(values) == 0 {
+		return ""
+	}
+	for col := range ignored {
+		delete(values, col)
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func decodeAuditValues(data string) map[string]interface{} {
+	values := make(map[string]interface{})
+	if data != "" {
+		_ = json.Unmarshal([]byte(data), &values)
+	}
+	return values
+}
@@ -2,6 +2,8 @@ package orm
 
 import (
 	"time"
+
+	"gorm.io/gorm/logger"
 )
 
 // DatabaseConfig holds database configuration
@@ -19,10 +21,21 @@ type DatabaseConfig struct {
 	ConnMaxLifetime time.Duration
 
 	Options DatabaseOptions
+
+	// Replicas lists read replicas; reads go to a replica and writes to the primary
+	Replicas ReplicasConfig
+
+	// Logger receives GORM's SQL logs. When nil, GORM's default logger is used (verbose in debug mode).
+	Logger logger.Interface
 }
 
 // DatabaseOptions holds database connection options
 type DatabaseOptions struct {
-	SSLMode    string
-	LogQueries bool
+	SSLMode            string
+	LogQueries         bool
+	SlowQueryThreshold time.Duration // log queries slower than this at warn level; 0 disables
+
+	// Params are driver connection parameters such as charset, search_path, timezone or
+	// connect_timeout. Keys are snake_case; each DSN builder translates them for its driver.
+	Params map[string]string
 }
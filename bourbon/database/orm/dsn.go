@@ -0,0 +1,207 @@
+package orm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Driver-independent TLS option keys, translated for each driver
+const (
+	OptionTLSCA   = "tls_ca"
+	OptionTLSCert = "tls_cert"
+	OptionTLSKey  = "tls_key"
+)
+
+// MySQLTLSConfigName is the name MySQL dialectors register a custom TLS config under
+// when tls_ca, tls_cert or tls_key is set
+const MySQLTLSConfigName = "bourbon"
+
+// mysqlDefaultParams are applied unless overridden in the options
+var mysqlDefaultParams = map[string]string{
+	"charset":   "utf8mb4",
+	"parseTime": "True",
+	"loc":       "UTC",
+}
+
+// MySQLDSN builds a go-sql-driver/mysql DSN. Option keys are converted from snake_case
+// to the driver's camelCase (parse_time -> parseTime) and connect_timeout maps to timeout.
+// When TLS files are given, tls=MySQLTLSConfigName is set; register the config from
+// TLSConfig with mysql.RegisterTLSConfig.
+func MySQLDSN(cfg DatabaseConfig) string {
+	params := make(map[string]string, len(mysqlDefaultParams))
+	for key, value := range mysqlDefaultParams {
+		params[key] = value
+	}
+	for key, value := range cfg.Options.Params {
+		switch key {
+		case OptionTLSCA, OptionTLSCert, OptionTLSKey:
+			params["tls"] = MySQLTLSConfigName
+		case "connect_timeout":
+			// Seconds, as for the other drivers; the MySQL driver wants a duration
+			if _, err := strconv.Atoi(value); err == nil {
+				value += "s"
+			}
+			params["timeout"] = value
+		default:
+			params[snakeToCamel(key)] = value
+		}
+	}
+
+	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?%s",
+		cfg.User,
+		cfg.Password,
+		cfg.Host,
+		cfg.Port,
+		cfg.Name,
+		encodeParams(params),
+	)
+}
+
+// PostgresDSN builds a PostgreSQL connection URL. Options are passed as URL parameters:
+// libpq keywords (connect_timeout, sslrootcert, application_name) configure the connection,
+// and other keys (search_path, timezone) are sent as run-time parameters.
+func PostgresDSN(cfg DatabaseConfig) string {
+	params := map[string]string{"sslmode": cfg.Options.SSLMode}
+	if params["sslmode"] == "" {
+		params["sslmode"] = "disable"
+	}
+	for key, value := range cfg.Options.Params {
+		switch key {
+		case OptionTLSCA:
+			params["sslrootcert"] = value
+		case OptionTLSCert:
+			params["sslcert"] = value
+		case OptionTLSKey:
+			params["sslkey"] = value
+		default:
+			params[key] = value
+		}
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.User, cfg.Password),
+		Host:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Path:     "/" + cfg.Name,
+		RawQuery: encodeParams(params),
+	}
+	return dsn.String()
+}
+
+// SQLiteDSN builds a SQLite DSN. Options become mattn/go-sqlite3 parameters, with the
+// leading underscore added when missing (foreign_keys -> _foreign_keys).
+func SQLiteDSN(cfg DatabaseConfig) string {
+	path := cfg.Path
+	if path == "" {
+		path = cfg.Name
+	}
+	if path == "" {
+		path = "bourbon.db"
+	}
+	if len(cfg.Options.Params) == 0 {
+		return path
+	}
+
+	params := make(map[string]string, len(cfg.Options.Params))
+	for key, value := range cfg.Options.Params {
+		if !strings.HasPrefix(key, "_") && key != "mode" && key != "cache" {
+			key = "_" + key
+		}
+		params[key] = value
+	}
+
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	if !strings.HasPrefix(path, "file:") {
+		path = "file:" + path
+	}
+	return path + sep + encodeParams(params)
+}
+
+// SQLServerDSN builds a go-mssqldb connection URL. Options are passed as URL parameters
+// (encrypt, trustservercertificate, app name); connect_timeout maps to "connection timeout".
+func SQLServerDSN(cfg DatabaseConfig) string {
+	params := map[string]string{"database": cfg.Name}
+	for key, value := range cfg.Options.Params {
+		switch key {
+		case "connect_timeout":
+			params["connection timeout"] = value
+		case OptionTLSCA:
+			params["certificate"] = value
+		default:
+			params[key] = value
+		}
+	}
+
+	dsn := url.URL{
+		Scheme:   "sqlserver",
+		User:     url.UserPassword(cfg.User, cfg.Password),
+		Host:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		RawQuery: encodeParams(params),
+	}
+	return dsn.String()
+}
+
+// TLSConfig builds a client TLS config from the tls_ca, tls_cert and tls_key options.
+// It returns nil when none are set.
+func TLSConfig(options DatabaseOptions) (*tls.Config, error) {
+	ca, cert, key := options.Params[OptionTLSCA], options.Params[OptionTLSCert], options.Params[OptionTLSKey]
+	if ca == "" && cert == "" && key == "" {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if ca != "" {
+		pem, err := os.ReadFile(ca)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", OptionTLSCA, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", ca)
+		}
+		cfg.RootCAs = pool
+	}
+	if cert != "" || key != "" {
+		pair, err := tls.LoadX509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{pair}
+	}
+	return cfg, nil
+}
+
+// encodeParams joins parameters in key order so DSNs are stable
+func encodeParams(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, url.QueryEscape(key)+"="+url.QueryEscape(params[key]))
+	}
+	return strings.Join(parts, "&")
+}
+
+// snakeToCamel converts parse_time to parseTime; keys without underscores are unchanged
+func snakeToCamel(s string) string {
+	parts := strings.Split(s, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,112 @@
+package orm
+
+import "testing"
+
+func TestMySQLDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  DatabaseConfig
+		want string
+	}{
+		{
+			name: "defaults",
+			cfg:  DatabaseConfig{Host: "db", Port: 3306, Name: "shop", User: "app", Password: "secret"},
+			want: "app:secret@tcp(db:3306)/shop?charset=utf8mb4&loc=UTC&parseTime=True",
+		},
+		{
+			name: "options",
+			cfg: DatabaseConfig{Host: "db", Port: 3306, Name: "shop", User: "app", Password: "secret",
+				Options: DatabaseOptions{Params: map[string]string{
+					"charset":         "latin1",
+					"connect_timeout": "5",
+					"read_timeout":    "30s",
+					"tls_ca":          "/etc/ca.pem",
+				}}},
+			want: "app:secret@tcp(db:3306)/shop?charset=latin1&loc=UTC&parseTime=True&readTimeout=30s&timeout=5s&tls=bourbon",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MySQLDSN(tt.cfg); got != tt.want {
+				t.Errorf("MySQLDSN() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostgresDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  DatabaseConfig
+		want string
+	}{
+		{
+			name: "defaults",
+			cfg:  DatabaseConfig{Host: "db", Port: 5432, Name: "shop", User: "app", Password: "secret"},
+			want: "postgres://app:secret@db:5432/shop?sslmode=disable",
+		},
+		{
+			name: "escaped password and options",
+			cfg: DatabaseConfig{Host: "db", Port: 5432, Name: "shop", User: "app", Password: "p@ss/word",
+				Options: DatabaseOptions{SSLMode: "verify-full", Params: map[string]string{
+					"search_path": "tenant,public",
+					"tls_ca":      "/etc/ca.pem",
+					"tls_cert":    "/etc/client.pem",
+					"tls_key":     "/etc/client.key",
+				}}},
+			want: "postgres://app:p%40ss%2Fword@db:5432/shop?search_path=tenant%2Cpublic&sslcert=%2Fetc%2Fclient.pem&sslkey=%2Fetc%2Fclient.key&sslmode=verify-full&sslrootcert=%2Fetc%2Fca.pem",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PostgresDSN(tt.cfg); got != tt.want {
+				t.Errorf("PostgresDSN() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLiteDSN(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  DatabaseConfig
+		want string
+	}{
+		{name: "default path", cfg: DatabaseConfig{}, want: "bourbon.db"},
+		{name: "name as path", cfg: DatabaseConfig{Name: "shop.db"}, want: "shop.db"},
+		{
+			name: "options",
+			cfg: DatabaseConfig{Path: "storage/app.db", Options: DatabaseOptions{Params: map[string]string{
+				"foreign_keys":  "on",
+				"_busy_timeout": "5000",
+				"mode":          "rwc",
+			}}},
+			want: "file:storage/app.db?_busy_timeout=5000&_foreign_keys=on&mode=rwc",
+		},
+		{
+			name: "path with parameters",
+			cfg:  DatabaseConfig{Path: "file:app.db?cache=shared", Options: DatabaseOptions{Params: map[string]string{"journal_mode": "WAL"}}},
+			want: "file:app.db?cache=shared&_journal_mode=WAL",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SQLiteDSN(tt.cfg); got != tt.want {
+				t.Errorf("SQLiteDSN() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLServerDSN(t *testing.T) {
+	cfg := DatabaseConfig{Host: "db", Port: 1433, Name: "shop", User: "sa", Password: "Secret!1",
+		Options: DatabaseOptions{Params: map[string]string{
+			"connect_timeout": "10",
+			"encrypt":         "true",
+			"tls_ca":          "/etc/ca.pem",
+		}}}
+	want := "sqlserver://sa:Secret%211@db:1433?certificate=%2Fetc%2Fca.pem&connection+timeout=10&database=shop&encrypt=true"
+	if got := SQLServerDSN(cfg); got != want {
+		t.Errorf("SQLServerDSN() = %q, want %q", got, want)
+	}
+}
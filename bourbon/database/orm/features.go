@@ -0,0 +1,40 @@
+package orm
+
+import "sync"
+
+// DriverFeatures describes behaviour that differs between databases, so migrations,
+// locking and transactions can adapt to the connected driver
+type DriverFeatures struct {
+	// TransactionalDDL is true when schema changes can be rolled back inside a transaction
+	TransactionalDDL bool
+	// AdvisoryLocks is true when the database has named locks (pg_advisory_lock, GET_LOCK, sp_getapplock)
+	AdvisoryLocks bool
+	// Retryable reports whether a failed transaction should be retried, e.g. on
+	// serialization failures that CockroachDB expects clients to retry
+	Retryable func(err error) bool
+}
+
+var (
+	featureRegistry = map[string]DriverFeatures{
+		"sqlite":   {TransactionalDDL: true},
+		"postgres": {TransactionalDDL: true, AdvisoryLocks: true},
+		"mysql":    {AdvisoryLocks: true},
+	}
+	featureMutex sync.RWMutex
+)
+
+// RegisterDriverFeatures records the features of a driver, usually from the init
+// function that calls RegisterDriver
+func RegisterDriverFeatures(name string, features DriverFeatures) {
+	featureMutex.Lock()
+	defer featureMutex.Unlock()
+	featureRegistry[name] = features
+}
+
+// FeaturesFor returns the features of a driver by name, as returned by db.Dialector.Name().
+// Unknown drivers report no optional features.
+func FeaturesFor(name string) DriverFeatures {
+	featureMutex.RLock()
+	defer featureMutex.RUnlock()
+	return featureRegistry[name]
+}
@@ -0,0 +1,210 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// HealthConfig configures the database health monitor
+type HealthConfig struct {
+	Interval   time.Duration // time between pings while healthy (default 15s)
+	Timeout    time.Duration // timeout of each ping (default 3s)
+	MaxBackoff time.Duration // longest wait between reconnect attempts (default 1m)
+}
+
+// PoolStats is a snapshot of the connection pool
+type PoolStats struct {
+	MaxOpen      int           `json:"max_open"`
+	Open         int           `json:"open"`
+	InUse        int           `json:"in_use"`
+	Idle         int           `json:"idle"`
+	WaitCount    int64         `json:"wait_count"`
+	WaitDuration time.Duration `json:"wait_duration"`
+}
+
+// HealthStatus reports the monitor's view of the database
+type HealthStatus struct {
+	Healthy   bool      `json:"healthy"`
+	Error     string    `json:"error,omitempty"`
+	LastCheck time.Time `json:"last_check"`
+	Failures  int       `json:"consecutive_failures"`
+	Pool      PoolStats `json:"pool"`
+}
+
+// HealthMonitor pings the database periodically. After a failed ping it retries with
+// exponential backoff; each attempt lets database/sql discard broken connections and open
+// new ones, so the pool recovers before requests hit a dead connection.
+type HealthMonitor struct {
+	db     *gorm.DB
+	cfg    HealthConfig
+	logger *logging.Logger
+
+	mu        sync.RWMutex
+	healthy   bool
+	lastErr   error
+	lastCheck time.Time
+	failures  int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewHealthMonitor creates a monitor for db; call Start to begin pinging
+func NewHealthMonitor(db *gorm.DB, cfg HealthConfig, l *logging.Logger) *HealthMonitor {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 15 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 3 * time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = time.Minute
+	}
+	return &HealthMonitor{
+		db:      db,
+		cfg:     cfg,
+		logger:  l.Module(logging.ModuleDatabase),
+		healthy: true,
+	}
+}
+
+// Start runs the ping loop in the background until Stop is called
+func (m *HealthMonitor) Start() {
+	m.mu.Lock()
+	if m.stop != nil {
+		m.mu.Unlock()
+		return
+	}
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	stop, done := m.stop, m.done
+	m.mu.Unlock()
+
+	go m.run(stop, done)
+}
+
+// Stop ends the ping loop and waits for it to exit
+func (m *HealthMonitor) Stop() {
+	m.mu.Lock()
+	stop, done := m.stop, m.done
+	m.stop, m.done = nil, nil
+	m.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+func (m *HealthMonitor) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	backoff := time.Second
+	for {
+		wait := m.cfg.Interval
+		if err := m.Check(context.Background()); err != nil {
+			wait = backoff
+			backoff *= 2
+			if backoff > m.cfg.MaxBackoff {
+				backoff = m.cfg.MaxBackoff
+			}
+		} else {
+			backoff = time.Second
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Check pings the database now and records the result
+func (m *HealthMonitor) Check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, m.cfg.Timeout)
+	defer cancel()
+
+	err := m.ping(ctx)
+
+	m.mu.Lock()
+	wasHealthy := m.healthy
+	m.healthy = err == nil
+	m.lastErr = err
+	m.lastCheck = time.Now()
+	if err != nil {
+		m.failures++
+	} else {
+		m.failures = 0
+	}
+	failures := m.failures
+	m.mu.Unlock()
+
+	switch {
+	case err != nil && wasHealthy:
+		m.logger.Error("Database unreachable, reconnecting", zap.Error(err))
+	case err != nil:
+		m.logger.Warn("Database reconnect failed", zap.Int("attempt", failures), zap.Error(err))
+	case !wasHealthy:
+		m.logger.Info("Database connection restored")
+	}
+	return err
+}
+
+func (m *HealthMonitor) ping(ctx context.Context) error {
+	sqlDB, err := m.db.DB()
+	if err != nil {
+		return err
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fmt.Errorf("ping failed: %w", err)
+	}
+	return nil
+}
+
+// Healthy reports whether the last ping succeeded
+func (m *HealthMonitor) Healthy() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.healthy
+}
+
+// Stats returns a snapshot of the primary's connection pool
+func (m *HealthMonitor) Stats() PoolStats {
+	sqlDB, err := m.db.DB()
+	if err != nil {
+		return PoolStats{}
+	}
+	s := sqlDB.Stats()
+	return PoolStats{
+		MaxOpen:      s.MaxOpenConnections,
+		Open:         s.OpenConnections,
+		InUse:        s.InUse,
+		Idle:         s.Idle,
+		WaitCount:    s.WaitCount,
+		WaitDuration: s.WaitDuration,
+	}
+}
+
+// Status returns the last check result with current pool stats
+func (m *HealthMonitor) Status() HealthStatus {
+	m.mu.RLock()
+	status := HealthStatus{
+		Healthy:   m.healthy,
+		LastCheck: m.lastCheck,
+		Failures:  m.failures,
+	}
+	if m.lastErr != nil {
+		status.Error = m.lastErr.Error()
+	}
+	m.mu.RUnlock()
+
+	status.Pool = m.Stats()
+	return status
+}
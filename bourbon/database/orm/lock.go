@@ -0,0 +1,152 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// lockPollInterval is how often Lock retries while another process holds the lock
+const lockPollInterval = 500 * time.Millisecond
+
+// Lock is a named lock shared by every process using the same database, e.g. so
+// only one instance runs migrations during a rolling deploy. It uses
+// pg_advisory_lock on Postgres, GET_LOCK on MySQL, sp_getapplock on SQL Server and
+// a lock file next to the database on SQLite. Other databases, such as
+// CockroachDB, hold it as a lease row in the bourbon_locks table.
+type Lock struct {
+	name   string
+	try    func(ctx context.Context) (bool, error)
+	unlock func() error
+}
+
+// NewLock prepares a lock named name on db. Server-side locks belong to a
+// connection, so one is taken from the pool and held until Unlock.
+func NewLock(ctx context.Context, db *gorm.DB, name string) (*Lock, error) {
+	driver := db.Dialector.Name()
+	if driver == "sqlite" {
+		return newSQLiteLock(db, name)
+	}
+	if !FeaturesFor(driver).AdvisoryLocks {
+		return newLeaseLock(db, name)
+	}
+
+	var tryQuery, unlockQuery string
+	var arg interface{} = name
+	switch driver {
+	case "postgres":
+		tryQuery = "SELECT pg_try_advisory_lock($1)"
+		unlockQuery = "SELECT pg_advisory_unlock($1)"
+		arg = lockKey(name)
+	case "mysql":
+		tryQuery = "SELECT GET_LOCK(?, 0)"
+		unlockQuery = "SELECT RELEASE_LOCK(?)"
+	case "sqlserver":
+		tryQuery = "DECLARE @r int; EXEC @r = sp_getapplock @Resource = @p1, @LockMode = 'Exclusive', @LockOwner = 'Session', @LockTimeout = 0; SELECT CASE WHEN @r >= 0 THEN 1 ELSE 0 END"
+		unlockQuery = "EXEC sp_releaseapplock @Resource = @p1, @LockOwner = 'Session'"
+	default:
+		return newLeaseLock(db, name)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Lock{
+		name: name,
+		try: func(ctx context.Context) (bool, error) {
+			var acquired sql.NullBool
+			if err := conn.QueryRowContext(ctx, tryQuery, arg).Scan(&acquired); err != nil {
+				return false, err
+			}
+			return acquired.Valid && acquired.Bool, nil
+		},
+		unlock: func() error {
+			_, err := conn.ExecContext(context.Background(), unlockQuery, arg)
+			if closeErr := conn.Close(); err == nil {
+				err = closeErr
+			}
+			return err
+		},
+	}, nil
+}
+
+// TryLock takes the lock if it is free, without waiting
+func (l *Lock) TryLock(ctx context.Context) (bool, error) {
+	return l.try(ctx)
+}
+
+// Lock waits for the lock until ctx is done
+func (l *Lock) Lock(ctx context.Context) error {
+	ticker := time.NewTicker(lockPollInterval)
+	defer ticker.Stop()
+	for {
+		acquired, err := l.try(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to take lock %s: %w", l.name, err)
+		}
+		if acquired {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for lock %s: %w", l.name, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Unlock releases the lock. The lock cannot be taken again after Unlock.
+func (l *Lock) Unlock() error {
+	return l.unlock()
+}
+
+// lockKey maps a lock name to the integer key Postgres advisory locks use
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+// newSQLiteLock locks a file next to the database, since SQLite has no named
+// locks. In-memory databases belong to one process and need no lock.
+func newSQLiteLock(db *gorm.DB, name string) (*Lock, error) {
+	var path string
+	rows, err := db.Raw("PRAGMA database_list").Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var seq int
+		var schema, file string
+		if err := rows.Scan(&seq, &schema, &file); err != nil {
+			return nil, err
+		}
+		if schema == "main" {
+			path = file
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if path == "" {
+		return &Lock{
+			name:   name,
+			try:    func(context.Context) (bool, error) { return true, nil },
+			unlock: func() error { return nil },
+		}, nil
+	}
+	lock := &fileLock{path: path + "." + name + ".lock"}
+	return &Lock{name: name, try: lock.try, unlock: lock.unlock}, nil
+}
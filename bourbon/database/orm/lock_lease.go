@@ -0,0 +1,101 @@
+package orm
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// lockLeaseTTL is how long a lease keeps its lock once the holder stops renewing
+// it, e.g. because its process died
+const lockLeaseTTL = 30 * time.Second
+
+// LockLease is a lock held in the bourbon_locks table, for databases without
+// advisory locks such as CockroachDB
+type LockLease struct {
+	Name      string    `gorm:"primaryKey;size:191"`
+	Owner     string    `gorm:"size:32"`
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+// TableName specifies the table name for LockLease
+func (LockLease) TableName() string {
+	return "bourbon_locks"
+}
+
+// leaseLock takes a lock by inserting its row, and renews the row's expiry while
+// it holds the lock
+type leaseLock struct {
+	db    *gorm.DB
+	name  string
+	owner string
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// newLeaseLock prepares a lock held in the bourbon_locks table, creating it if needed
+func newLeaseLock(db *gorm.DB, name string) (*Lock, error) {
+	if err := db.AutoMigrate(&LockLease{}); err != nil {
+		// Another instance may have created the table at the same time
+		if !db.Migrator().HasTable(&LockLease{}) {
+			return nil, fmt.Errorf("failed to create the lock table: %w", err)
+		}
+	}
+	owner := make([]byte, 16)
+	_, _ = rand.Read(owner)
+	lock := &leaseLock{db: db, name: name, owner: hex.EncodeToString(owner)}
+	return &Lock{name: name, try: lock.try, unlock: lock.unlock}, nil
+}
+
+func (l *leaseLock) try(ctx context.Context) (bool, error) {
+	db := l.db.WithContext(ctx)
+	now := time.Now().UTC()
+	if err := db.Where("name = ? AND expires_at < ?", l.name, now).Delete(&LockLease{}).Error; err != nil {
+		return false, err
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&LockLease{Name: l.name, Owner: l.owner, ExpiresAt: now.Add(lockLeaseTTL)})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	l.stop, l.done = make(chan struct{}), make(chan struct{})
+	go l.renew()
+	return true, nil
+}
+
+// renew extends the lease until unlock, so the lock outlives lockLeaseTTL while
+// its holder is running
+func (l *leaseLock) renew() {
+	defer close(l.done)
+	ticker := time.NewTicker(lockLeaseTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			l.db.Model(&LockLease{}).
+				Where("name = ? AND owner = ?", l.name, l.owner).
+				Update("expires_at", time.Now().UTC().Add(lockLeaseTTL))
+		}
+	}
+}
+
+func (l *leaseLock) unlock() error {
+	if l.stop == nil {
+		return nil
+	}
+	close(l.stop)
+	<-l.done
+	l.stop = nil
+	return l.db.Where("name = ? AND owner = ?", l.name, l.owner).Delete(&LockLease{}).Error
+}
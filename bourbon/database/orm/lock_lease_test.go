@@ -0,0 +1,101 @@
+package orm
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openLeaseDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "locks.db")
+	db, err := gorm.Open(sqlite.Open(path+"?_busy_timeout=5000"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func TestLeaseLock(t *testing.T) {
+	db := openLeaseDB(t)
+	ctx := context.Background()
+
+	first, err := newLeaseLock(db, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := newLeaseLock(db, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := newLeaseLock(db, "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := first.TryLock(ctx); err != nil || !ok {
+		t.Fatalf("first TryLock = %v, %v", ok, err)
+	}
+	if ok, err := second.TryLock(ctx); err != nil || ok {
+		t.Fatalf("second TryLock while held = %v, %v", ok, err)
+	}
+	if ok, err := other.TryLock(ctx); err != nil || !ok {
+		t.Fatalf("TryLock of another name = %v, %v", ok, err)
+	}
+	if err := second.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := second.TryLock(ctx); ok {
+		t.Fatal("unlocking a lock that was not acquired released the holder's lease")
+	}
+
+	if err := first.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := second.TryLock(ctx); err != nil || !ok {
+		t.Fatalf("TryLock after unlock = %v, %v", ok, err)
+	}
+	if err := second.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLeaseLockExpired(t *testing.T) {
+	db := openLeaseDB(t)
+	ctx := context.Background()
+
+	// A holder that died leaves its lease behind until it expires
+	if err := db.AutoMigrate(&LockLease{}); err != nil {
+		t.Fatal(err)
+	}
+	stale := &LockLease{Name: "migrations", Owner: "dead", ExpiresAt: time.Now().UTC().Add(-time.Second)}
+	if err := db.Create(stale).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := newLeaseLock(db, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := lock.TryLock(ctx); err != nil || !ok {
+		t.Fatalf("TryLock over an expired lease = %v, %v", ok, err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	db.Model(&LockLease{}).Count(&count)
+	if count != 0 {
+		t.Errorf("%d leases left after unlock", count)
+	}
+}
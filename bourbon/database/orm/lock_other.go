@@ -0,0 +1,35 @@
+//go:build windows || plan9
+
+package orm
+
+import (
+	"context"
+	"os"
+)
+
+// fileLock is a file created exclusively and removed on unlock. A process that
+// dies while holding it leaves the file behind; delete it to release the lock.
+type fileLock struct {
+	path string
+	held bool
+}
+
+func (l *fileLock) try(context.Context) (bool, error) {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if os.IsExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	l.held = true
+	return true, file.Close()
+}
+
+func (l *fileLock) unlock() error {
+	if !l.held {
+		return nil
+	}
+	l.held = false
+	return os.Remove(l.path)
+}
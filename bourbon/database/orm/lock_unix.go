@@ -0,0 +1,44 @@
+//go:build !windows && !plan9
+
+package orm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+)
+
+// fileLock is an flock on a file, released by the OS if the process dies
+type fileLock struct {
+	path string
+	file *os.File
+}
+
+func (l *fileLock) try(context.Context) (bool, error) {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return false, err
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return false, nil
+		}
+		return false, err
+	}
+	l.file = file
+	return true, nil
+}
+
+func (l *fileLock) unlock() error {
+	if l.file == nil {
+		return nil
+	}
+	err := syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	return err
+}
@@ -0,0 +1,262 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/jobs"
+	"gorm.io/gorm"
+)
+
+// Event is a point in a model's lifecycle that observers can handle
+type Event string
+
+// Model events
+const (
+	BeforeCreate Event = "before_create"
+	AfterCreate  Event = "after_create"
+	BeforeUpdate Event = "before_update"
+	AfterUpdate  Event = "after_update"
+	BeforeDelete Event = "before_delete"
+	AfterDelete  Event = "after_delete"
+)
+
+// IsAfter reports whether the event fires once the statement has run
+func (e Event) IsAfter() bool {
+	return e == AfterCreate || e == AfterUpdate || e == AfterDelete
+}
+
+// ObserverFunc handles a model event. record is a pointer to the model, e.g. *User.
+type ObserverFunc func(ctx context.Context, record interface{}) error
+
+// ObserverQueue runs async observers off the request path. The default is a
+// jobs.LocalQueue; the application sets app.Jobs.
+type ObserverQueue = jobs.Queue
+
+type observer struct {
+	fn    ObserverFunc
+	async bool
+}
+
+var (
+	observers     = make(map[reflect.Type]map[Event][]observer)
+	observerMutex sync.RWMutex
+
+	observerQueue      ObserverQueue
+	observerQueueMutex sync.RWMutex
+)
+
+// Observe calls fn synchronously on event for model's type:
+//
+//	orm.Observe(&User{}, orm.BeforeCreate, func(ctx context.Context, record interface{}) error {
+//	    user := record.(*User)
+//	    user.Email = strings.ToLower(user.Email)
+//	    return nil
+//	})
+//
+// An error from a Before observer cancels the statement. An error from an After observer
+// fails the statement and rolls back its transaction.
+func Observe(model interface{}, event Event, fn ObserverFunc) {
+	addObserver(model, event, observer{fn: fn})
+}
+
+// ObserveAsync calls fn on an After event through the observer queue, once the
+// statement's transaction has committed. fn receives a copy of the record, and its
+// errors are logged instead of failing the statement:
+//
+//	orm.ObserveAsync(&User{}, orm.AfterCreate, func(ctx context.Context, record interface{}) error {
+//	    return mailer.SendWelcome(ctx, record.(*User))
+//	})
+func ObserveAsync(model interface{}, event Event, fn ObserverFunc) {
+	if !event.IsAfter() {
+		panic(fmt.Sprintf("orm: async observers need an After event, got %s", event))
+	}
+	addObserver(model, event, observer{fn: fn, async: true})
+}
+
+// ClearObservers removes the observers of model's type, or of every model when model is nil
+func ClearObservers(model interface{}) {
+	observerMutex.Lock()
+	defer observerMutex.Unlock()
+	if model == nil {
+		observers = make(map[reflect.Type]map[Event][]observer)
+		return
+	}
+	delete(observers, modelType(model))
+}
+
+// SetObserverQueue sets the queue async observers are delivered through
+func SetObserverQueue(q ObserverQueue) {
+	observerQueueMutex.Lock()
+	defer observerQueueMutex.Unlock()
+	observerQueue = q
+}
+
+// EnableObservers registers the callbacks that deliver model events. ConnectDatabase
+// calls it; call it yourself for connections opened with gorm.Open.
+func EnableObservers(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().After("gorm:before_create").Before("gorm:create").Register("bourbon:observe_before_create", notifySync(BeforeCreate)); err != nil {
+		return err
+	}
+	if err := callbacks.Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").Register("bourbon:observe_after_create", notifySync(AfterCreate)); err != nil {
+		return err
+	}
+	if err := callbacks.Create().After("gorm:commit_or_rollback_transaction").Register("bourbon:observe_async_create", notifyAsync(AfterCreate)); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:before_update").Before("gorm:update").Register("bourbon:observe_before_update", notifySync(BeforeUpdate)); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").Register("bourbon:observe_after_update", notifySync(AfterUpdate)); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:commit_or_rollback_transaction").Register("bourbon:observe_async_update", notifyAsync(AfterUpdate)); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("gorm:before_delete").Before("gorm:delete").Register("bourbon:observe_before_delete", notifySync(BeforeDelete)); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").Register("bourbon:observe_after_delete", notifySync(AfterDelete)); err != nil {
+		return err
+	}
+	return callbacks.Delete().After("gorm:commit_or_rollback_transaction").Register("bourbon:observe_async_delete", notifyAsync(AfterDelete))
+}
+
+func addObserver(model interface{}, event Event, o observer) {
+	t := modelType(model)
+	observerMutex.Lock()
+	defer observerMutex.Unlock()
+	if observers[t] == nil {
+		observers[t] = make(map[Event][]observer)
+	}
+	observers[t][event] = append(observers[t][event], o)
+}
+
+func modelType(model interface{}) reflect.Type {
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// observersFor returns the sync or async observers of the statement's model for event
+func observersFor(db *gorm.DB, event Event, async bool) []observer {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil
+	}
+	observerMutex.RLock()
+	defer observerMutex.RUnlock()
+	var result []observer
+	for _, o := range observers[db.Statement.Schema.ModelType][event] {
+		if o.async == async {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+func notifySync(event Event) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		list := observersFor(db, event, false)
+		if len(list) == 0 {
+			return
+		}
+		ctx := db.Statement.Context
+		for _, record := range statementRecords(db) {
+			for _, o := range list {
+				if err := o.fn(ctx, record); err != nil {
+					_ = db.AddError(err)
+					return
+				}
+			}
+		}
+	}
+}
+
+func notifyAsync(event Event) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		list := observersFor(db, event, true)
+		if len(list) == 0 {
+			return
+		}
+		ctx := context.WithoutCancel(db.Statement.Context)
+		name := fmt.Sprintf("%s.%s", db.Statement.Schema.Table, event)
+		gormLogger := db.Logger
+
+		// Copy now; the caller may change the records before the transaction commits
+		var records []interface{}
+		for _, record := range statementRecords(db) {
+			records = append(records, copyRecord(record))
+		}
+
+		AfterCommit(ctx, func() {
+			queue := currentObserverQueue()
+			for _, record := range records {
+				for _, o := range list {
+					fn, record := o.fn, record
+					err := queue.Enqueue(ctx, name, func(ctx context.Context) error {
+						return fn(ctx, record)
+					})
+					if err != nil {
+						gormLogger.Error(ctx, "failed to enqueue %s observer: %v", name, err)
+					}
+				}
+			}
+		})
+	}
+}
+
+// statementRecords returns pointers to the records of the statement; bulk operations
+// without model values (Where(...).Delete(&User{})) pass the model given to the statement
+func statementRecords(db *gorm.DB) []interface{} {
+	rv := db.Statement.ReflectValue
+	var records []interface{}
+	add := func(v reflect.Value) {
+		v = reflect.Indirect(v)
+		if v.Kind() != reflect.Struct {
+			return
+		}
+		if v.CanAddr() {
+			records = append(records, v.Addr().Interface())
+		} else {
+			records = append(records, copyRecord(v.Interface()))
+		}
+	}
+
+	switch reflect.Indirect(rv).Kind() {
+	case reflect.Slice, reflect.Array:
+		rv = reflect.Indirect(rv)
+		for i := 0; i < rv.Len(); i++ {
+			add(rv.Index(i))
+		}
+	case reflect.Struct:
+		add(rv)
+	}
+	return records
+}
+
+// copyRecord returns a pointer to a shallow copy of record
+func copyRecord(record interface{}) interface{} {
+	v := reflect.Indirect(reflect.ValueOf(record))
+	cp := reflect.New(v.Type())
+	cp.Elem().Set(v)
+	return cp.Interface()
+}
+
+var defaultObserverQueue = sync.OnceValue(func() ObserverQueue {
+	return jobs.NewLocalQueue(4, nil)
+})
+
+func currentObserverQueue() ObserverQueue {
+	observerQueueMutex.RLock()
+	q := observerQueue
+	observerQueueMutex.RUnlock()
+	if q == nil {
+		return defaultObserverQueue()
+	}
+	return q
+}
@@ -52,12 +52,16 @@ func ConnectDatabase(cfg DatabaseConfig, debug bool) (*gorm.DB, error) {
 	}
 
 	gormLogger := logger.Default.LogMode(logger.Silent)
-	if debug {
+	if cfg.Logger != nil {
+		gormLogger = cfg.Logger
+	} else if debug || cfg.Options.LogQueries {
 		gormLogger = logger.Default.LogMode(logger.Info)
 	}
 
 	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: gormLogger,
+		// Timestamps are stored in UTC; convert them with app.Localize for display
+		NowFunc: func() time.Time { return time.Now().UTC() },
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -86,6 +90,23 @@ func ConnectDatabase(cfg DatabaseConfig, debug bool) (*gorm.DB, error) {
 	sqlDB.SetMaxIdleConns(maxIdleConns)
 	sqlDB.SetConnMaxLifetime(connMaxLifetime)
 
+	if err := EnableObservers(db); err != nil {
+		return nil, fmt.Errorf("failed to register observers: %w", err)
+	}
+	if err := EnableUTC(db); err != nil {
+		return nil, fmt.Errorf("failed to register UTC conversion: %w", err)
+	}
+	if err := EnableQueryRecording(db); err != nil {
+		return nil, fmt.Errorf("failed to register query recording: %w", err)
+	}
+
+	if len(cfg.Replicas.Nodes) > 0 {
+		cfg.MaxOpenConns, cfg.MaxIdleConns, cfg.ConnMaxLifetime = maxOpenConns, maxIdleConns, connMaxLifetime
+		if err := registerReplicas(db, cfg, driverFunc); err != nil {
+			return nil, err
+		}
+	}
+
 	return db, nil
 }
 
@@ -3,7 +3,7 @@
 package orm
 
 import (
-	"fmt"
+	mysqldriver "github.com/go-sql-driver/mysql"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
@@ -13,13 +13,15 @@ func init() {
 }
 
 func mysqlDialector(cfg DatabaseConfig) (gorm.Dialector, error) {
-	dsn := fmt.Sprintf(
-		"%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		cfg.User,
-		cfg.Password,
-		cfg.Host,
-		cfg.Port,
-		cfg.Name,
-	)
-	return mysql.Open(dsn), nil
+	tlsConfig, err := TLSConfig(cfg.Options)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		tlsConfig.ServerName = cfg.Host
+		if err := mysqldriver.RegisterTLSConfig(MySQLTLSConfigName, tlsConfig); err != nil {
+			return nil, err
+		}
+	}
+	return mysql.Open(MySQLDSN(cfg)), nil
 }
@@ -12,12 +12,5 @@ func init() {
 }
 
 func sqliteDialector(cfg DatabaseConfig) (gorm.Dialector, error) {
-	path := cfg.Path
-	if path == "" {
-		path = cfg.Name
-	}
-	if path == "" {
-		path = "bourbon.db"
-	}
-	return sqlite.Open(path), nil
+	return sqlite.Open(SQLiteDSN(cfg)), nil
 }
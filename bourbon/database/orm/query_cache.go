@@ -0,0 +1,224 @@
+package orm
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/cache"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	queryCache      *cache.Cache
+	queryCacheMutex sync.RWMutex
+
+	// queryCacheObserved records the model types whose writes invalidate the query cache
+	queryCacheObserved sync.Map
+)
+
+// SetQueryCache sets the cache used by cached repositories; the application sets app.Cache
+func SetQueryCache(c *cache.Cache) {
+	queryCacheMutex.Lock()
+	defer queryCacheMutex.Unlock()
+	queryCache = c
+}
+
+func currentQueryCache() *cache.Cache {
+	queryCacheMutex.RLock()
+	defer queryCacheMutex.RUnlock()
+	return queryCache
+}
+
+// QueryCacheTag is the cache tag of the cached queries on a table
+func QueryCacheTag(table string) string {
+	return "orm:" + table
+}
+
+// InvalidateQueryCache drops the cached queries of model's table. Writes through GORM
+// do this automatically; call it after raw SQL changes.
+func InvalidateQueryCache(ctx context.Context, db *gorm.DB, model interface{}) error {
+	c := currentQueryCache()
+	if c == nil {
+		return nil
+	}
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+	return c.InvalidateTags(ctx, QueryCacheTag(stmt.Schema.Table))
+}
+
+// CachedRepository serves reads from the query cache and passes writes through.
+// Reads in a transaction skip the cache, so they see the transaction's own writes.
+type CachedRepository[T any] struct {
+	*GormRepository[T]
+	ttl   time.Duration
+	table string
+}
+
+// Cached returns a repository whose reads are cached for ttl (zero uses the cache's
+// default). Cached results are dropped when a T is created, updated or deleted through GORM.
+//
+//	posts := orm.NewRepository[Post](app.DB).Cached(time.Minute)
+//	recent, err := posts.Find(ctx, orm.Eq("published", true), orm.Limit(10))
+func (r *GormRepository[T]) Cached(ttl time.Duration) *CachedRepository[T] {
+	cached := &CachedRepository[T]{GormRepository: r, ttl: ttl}
+	stmt := &gorm.Statement{DB: r.db}
+	if err := stmt.Parse(new(T)); err == nil {
+		cached.table = stmt.Schema.Table
+		observeQueryCacheWrites(new(T), cached.table)
+	}
+	return cached
+}
+
+// Find returns all records matching the options
+func (r *CachedRepository[T]) Find(ctx context.Context, opts ...QueryOption) ([]T, error) {
+	var items []T
+	err := r.remember(ctx, "find", opts, &items, func(db *gorm.DB) *gorm.DB {
+		return db.Find(&items)
+	})
+	return items, err
+}
+
+// First returns the first record matching the options, or ErrNotFound
+func (r *CachedRepository[T]) First(ctx context.Context, opts ...QueryOption) (*T, error) {
+	var item T
+	if err := r.remember(ctx, "first", opts, &item, func(db *gorm.DB) *gorm.DB {
+		return db.First(&item)
+	}); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// FindByID returns the record with the given primary key, or ErrNotFound
+func (r *CachedRepository[T]) FindByID(ctx context.Context, id interface{}, opts ...QueryOption) (*T, error) {
+	var item T
+	if err := r.remember(ctx, "first", opts, &item, func(db *gorm.DB) *gorm.DB {
+		return db.First(&item, id)
+	}); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Count returns the number of records matching the options
+func (r *CachedRepository[T]) Count(ctx context.Context, opts ...QueryOption) (int64, error) {
+	var count int64
+	err := r.remember(ctx, "count", opts, &count, func(db *gorm.DB) *gorm.DB {
+		return db.Count(&count)
+	})
+	return count, err
+}
+
+// Paginate returns one page of records; page numbers start at 1
+func (r *CachedRepository[T]) Paginate(ctx context.Context, page, perPage int, opts ...QueryOption) (*Page[T], error) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	total, err := r.Count(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	items, err := r.Find(ctx, append(opts[:len(opts):len(opts)], Limit(perPage), Offset((page-1)*perPage))...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Page[T]{
+		Items:      items,
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: int((total + int64(perPage) - 1) / int64(perPage)),
+	}, nil
+}
+
+// WithDB returns an uncached repository bound to another connection, e.g. a transaction
+func (r *CachedRepository[T]) WithDB(db *gorm.DB) Repository[T] {
+	return r.GormRepository.WithDB(db)
+}
+
+// remember decodes the cached result of the query into dest, or runs it and caches dest.
+// The key is derived from the SQL the query would run, so any option change gets its own entry.
+func (r *CachedRepository[T]) remember(ctx context.Context, op string, opts []QueryOption, dest interface{}, run func(db *gorm.DB) *gorm.DB) error {
+	c := currentQueryCache()
+	if _, inTx := TxFromContext(ctx); c == nil || inTx || r.table == "" {
+		return run(r.query(ctx, opts)).Error
+	}
+
+	key, err := r.cacheKey(ctx, op, opts, run)
+	if err != nil {
+		return run(r.query(ctx, opts)).Error
+	}
+
+	if data, err := c.GetBytes(ctx, key); err == nil {
+		if gob.NewDecoder(bytes.NewReader(data)).Decode(dest) == nil {
+			return nil
+		}
+		reflect.ValueOf(dest).Elem().SetZero()
+	}
+
+	if err := run(r.query(ctx, opts)).Error; err != nil {
+		return err
+	}
+
+	// Types gob cannot encode are simply not cached
+	var buf bytes.Buffer
+	if gob.NewEncoder(&buf).Encode(dest) == nil {
+		_ = c.Tags(QueryCacheTag(r.table)).SetBytes(ctx, key, buf.Bytes(), r.ttl)
+	}
+	return nil
+}
+
+// cacheKey builds the query in dry-run mode and hashes its SQL, variables and preloads
+func (r *CachedRepository[T]) cacheKey(ctx context.Context, op string, opts []QueryOption, run func(db *gorm.DB) *gorm.DB) (string, error) {
+	stmt := run(r.query(ctx, opts).Session(&gorm.Session{DryRun: true, Logger: logger.Discard}))
+	if stmt.Error != nil {
+		return "", stmt.Error
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00%v", op, stmt.Statement.SQL.String(), stmt.Statement.Vars)
+
+	preloads := make([]string, 0, len(stmt.Statement.Preloads))
+	for name, args := range stmt.Statement.Preloads {
+		preloads = append(preloads, fmt.Sprintf("%s%v", name, args))
+	}
+	sort.Strings(preloads)
+	fmt.Fprintf(hash, "\x00%v", preloads)
+
+	return fmt.Sprintf("query:%s:%s", r.table, hex.EncodeToString(hash.Sum(nil))), nil
+}
+
+// observeQueryCacheWrites registers, once per model type, observers that drop the
+// table's cached queries after a write commits
+func observeQueryCacheWrites(model interface{}, table string) {
+	if _, loaded := queryCacheObserved.LoadOrStore(modelType(model), true); loaded {
+		return
+	}
+	invalidate := func(ctx context.Context, record interface{}) error {
+		AfterCommit(ctx, func() {
+			if c := currentQueryCache(); c != nil {
+				_ = c.InvalidateTags(context.WithoutCancel(ctx), QueryCacheTag(table))
+			}
+		})
+		return nil
+	}
+	Observe(model, AfterCreate, invalidate)
+	Observe(model, AfterUpdate, invalidate)
+	Observe(model, AfterDelete, invalidate)
+}
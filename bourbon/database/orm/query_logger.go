@@ -0,0 +1,93 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+// QueryLogger routes GORM's SQL logging through the Bourbon logger.
+// Failed queries are logged at error level, queries slower than SlowThreshold at warn,
+// and every query at info (LogQueries) or debug (debug mode).
+type QueryLogger struct {
+	log           *zap.Logger
+	LogQueries    bool
+	Debug         bool
+	SlowThreshold time.Duration
+	level         logger.LogLevel
+}
+
+// NewQueryLogger creates a GORM logger writing to the "database" module of l
+func NewQueryLogger(l *logging.Logger, options DatabaseOptions, debug bool) *QueryLogger {
+	return &QueryLogger{
+		// The caller is taken from GORM, pointing at the code that ran the query
+		log:           l.Module(logging.ModuleDatabase).WithOptions(zap.WithCaller(false)),
+		LogQueries:    options.LogQueries,
+		Debug:         debug,
+		SlowThreshold: options.SlowQueryThreshold,
+		level:         logger.Info,
+	}
+}
+
+// LogMode returns a copy with the given GORM log level; logger.Silent disables all output
+func (q *QueryLogger) LogMode(level logger.LogLevel) logger.Interface {
+	clone := *q
+	clone.level = level
+	return &clone
+}
+
+func (q *QueryLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if q.level >= logger.Info {
+		q.log.Info(fmt.Sprintf(msg, args...), zap.String("caller", utils.FileWithLineNum()))
+	}
+}
+
+func (q *QueryLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if q.level >= logger.Warn {
+		q.log.Warn(fmt.Sprintf(msg, args...), zap.String("caller", utils.FileWithLineNum()))
+	}
+}
+
+func (q *QueryLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if q.level >= logger.Error {
+		q.log.Error(fmt.Sprintf(msg, args...), zap.String("caller", utils.FileWithLineNum()))
+	}
+}
+
+// Trace logs a completed SQL statement
+func (q *QueryLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if q.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	fields := func() []zap.Field {
+		sql, rows := fc()
+		return []zap.Field{
+			zap.String("sql", sql),
+			zap.Duration("duration", elapsed),
+			zap.Int64("rows", rows),
+			zap.String("caller", utils.FileWithLineNum()),
+		}
+	}
+
+	switch {
+	case err != nil && q.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		q.log.Error("Query failed", append(fields(), zap.Error(err))...)
+	case q.SlowThreshold > 0 && elapsed > q.SlowThreshold && q.level >= logger.Warn:
+		q.log.Warn("Slow query", append(fields(), zap.Duration("threshold", q.SlowThreshold))...)
+	case q.LogQueries && q.level >= logger.Info:
+		q.log.Info("Query", fields()...)
+	case q.Debug && q.level >= logger.Info:
+		if ce := q.log.Check(zap.DebugLevel, "Query"); ce != nil {
+			ce.Write(fields()...)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// RecordedQuery is a SQL statement run with a context from WithQueryRecorder
+type RecordedQuery struct {
+	// SQL is the statement with its placeholders, the same for every run of a query
+	SQL string `json:"sql"`
+	// Vars are the bind values, formatted for display
+	Vars     []string      `json:"vars,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Rows     int64         `json:"rows"`
+	Error    string        `json:"error,omitempty"`
+}
+
+type queryRecorderKey struct{}
+
+// queryStartKey holds when a statement's callbacks started
+const queryStartKey = "bourbon:query_start"
+
+// WithQueryRecorder returns a context whose queries are passed to record, which
+// may be called from several goroutines at once. Recorders of the contexts it is
+// derived from still get them. Only the queries run with the context, e.g. through
+// db.WithContext(ctx) or Conn(ctx, db), are seen.
+func WithQueryRecorder(ctx context.Context, record func(RecordedQuery)) context.Context {
+	if outer := queryRecorder(ctx); outer != nil {
+		inner := record
+		record = func(query RecordedQuery) {
+			outer(query)
+			inner(query)
+		}
+	}
+	return context.WithValue(ctx, queryRecorderKey{}, record)
+}
+
+func queryRecorder(ctx context.Context) func(RecordedQuery) {
+	if ctx == nil {
+		return nil
+	}
+	record, _ := ctx.Value(queryRecorderKey{}).(func(RecordedQuery))
+	return record
+}
+
+// EnableQueryRecording registers the callbacks that pass statements to the
+// recorders of WithQueryRecorder. ConnectDatabase calls it; call it yourself for
+// connections opened with gorm.Open.
+func EnableQueryRecording(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("*").Register("bourbon:record_create_start", startQuery); err != nil {
+		return err
+	}
+	if err := callbacks.Create().After("*").Register("bourbon:record_create", recordQuery); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("*").Register("bourbon:record_query_start", startQuery); err != nil {
+		return err
+	}
+	if err := callbacks.Query().After("*").Register("bourbon:record_query", recordQuery); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("*").Register("bourbon:record_update_start", startQuery); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("*").Register("bourbon:record_update", recordQuery); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("*").Register("bourbon:record_delete_start", startQuery); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("*").Register("bourbon:record_delete", recordQuery); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("*").Register("bourbon:record_row_start", startQuery); err != nil {
+		return err
+	}
+	if err := callbacks.Row().After("*").Register("bourbon:record_row", recordQuery); err != nil {
+		return err
+	}
+	if err := callbacks.Raw().Before("*").Register("bourbon:record_raw_start", startQuery); err != nil {
+		return err
+	}
+	return callbacks.Raw().After("*").Register("bourbon:record_raw", recordQuery)
+}
+
+func startQuery(db *gorm.DB) {
+	if queryRecorder(db.Statement.Context) != nil {
+		db.InstanceSet(queryStartKey, time.Now())
+	}
+}
+
+func recordQuery(db *gorm.DB) {
+	record := queryRecorder(db.Statement.Context)
+	// Statements stopped by an earlier callback, e.g. a failed hook, have no SQL
+	if record == nil || db.Statement.SQL.Len() == 0 {
+		return
+	}
+
+	query := RecordedQuery{SQL: db.Statement.SQL.String(), Rows: db.RowsAffected}
+	if start, ok := db.InstanceGet(queryStartKey); ok {
+		query.Duration = time.Since(start.(time.Time))
+	}
+	for _, v := range db.Statement.Vars {
+		query.Vars = append(query.Vars, formatVar(v))
+	}
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		query.Error = db.Error.Error()
+	}
+	record(query)
+}
+
+// formatVar formats a bind value the way it would be written in SQL
+func formatVar(v interface{}) string {
+	if valuer, ok := v.(driver.Valuer); ok {
+		if value, err := valuer.Value(); err == nil {
+			v = value
+		}
+	}
+	switch value := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return fmt.Sprintf("%q", value)
+	case []byte:
+		if utf8.Valid(value) {
+			return fmt.Sprintf("%q", value)
+		}
+		return fmt.Sprintf("<%d bytes>", len(value))
+	case time.Time:
+		return value.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,145 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// Replica selection policies for ReplicasConfig.Policy
+const (
+	ReplicaPolicyRandom     = "random"
+	ReplicaPolicyRoundRobin = "round_robin"
+)
+
+// ReplicaConfig describes one read replica. Empty fields inherit the primary's settings.
+type ReplicaConfig struct {
+	Host     string
+	Port     int
+	Name     string
+	User     string
+	Password string
+	Path     string
+}
+
+// ReplicasConfig routes reads to replicas and writes to the primary
+type ReplicasConfig struct {
+	Nodes  []ReplicaConfig
+	Policy string
+}
+
+// registerReplicas installs the dbresolver plugin so SELECTs use a replica and writes,
+// transactions and locking reads use the primary
+func registerReplicas(db *gorm.DB, cfg DatabaseConfig, driverFunc DialectorFunc) error {
+	replicas := make([]gorm.Dialector, 0, len(cfg.Replicas.Nodes))
+	for i, node := range cfg.Replicas.Nodes {
+		dialector, err := driverFunc(replicaDatabaseConfig(cfg, node))
+		if err != nil {
+			return fmt.Errorf("failed to create dialector for replica %d: %w", i, err)
+		}
+		replicas = append(replicas, dialector)
+	}
+
+	var policy dbresolver.Policy
+	switch strings.ToLower(cfg.Replicas.Policy) {
+	case "", ReplicaPolicyRandom:
+		policy = dbresolver.RandomPolicy{}
+	case ReplicaPolicyRoundRobin:
+		policy = dbresolver.StrictRoundRobinPolicy()
+	default:
+		return fmt.Errorf("unknown replica policy: %s", cfg.Replicas.Policy)
+	}
+
+	resolver := dbresolver.Register(dbresolver.Config{Replicas: replicas, Policy: policy}).
+		SetMaxOpenConns(cfg.MaxOpenConns).
+		SetMaxIdleConns(cfg.MaxIdleConns).
+		SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	if err := db.Use(resolver); err != nil {
+		return fmt.Errorf("failed to register read replicas: %w", err)
+	}
+	return registerStickyPrimary(db)
+}
+
+// replicaDatabaseConfig merges a replica's settings over the primary's
+func replicaDatabaseConfig(primary DatabaseConfig, node ReplicaConfig) DatabaseConfig {
+	cfg := primary
+	cfg.Replicas = ReplicasConfig{}
+	if node.Host != "" {
+		cfg.Host = node.Host
+	}
+	if node.Port != 0 {
+		cfg.Port = node.Port
+	}
+	if node.Name != "" {
+		cfg.Name = node.Name
+	}
+	if node.User != "" {
+		cfg.User = node.User
+	}
+	if node.Password != "" {
+		cfg.Password = node.Password
+	}
+	if node.Path != "" {
+		cfg.Path = node.Path
+	}
+	return cfg
+}
+
+// Primary returns a connection whose queries all go to the primary, e.g. to read a row
+// just written elsewhere or to run migrations, whose schema checks would otherwise read
+// a replica. Without replicas it behaves like db.
+func Primary(db *gorm.DB) *gorm.DB {
+	return db.Clauses(dbresolver.Write).Session(&gorm.Session{})
+}
+
+// stickyContextKey is the context key holding a request's stickyPrimary state
+type stickyContextKey struct{}
+
+type stickyPrimary struct {
+	wrote atomic.Bool
+}
+
+// WithStickyPrimary returns a context in which reads go to the primary once a write has
+// been made with it, so a request sees its own writes despite replication lag
+func WithStickyPrimary(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(stickyContextKey{}).(*stickyPrimary); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, stickyContextKey{}, &stickyPrimary{})
+}
+
+// registerStickyPrimary marks sticky contexts on writes and routes their later reads to the primary
+func registerStickyPrimary(db *gorm.DB) error {
+	markWrite := func(db *gorm.DB) {
+		if state, ok := db.Statement.Context.Value(stickyContextKey{}).(*stickyPrimary); ok && db.Error == nil {
+			state.wrote.Store(true)
+		}
+	}
+	readPrimary := func(db *gorm.DB) {
+		if state, ok := db.Statement.Context.Value(stickyContextKey{}).(*stickyPrimary); ok && state.wrote.Load() {
+			dbresolver.Write.ModifyStatement(db.Statement)
+		}
+	}
+
+	callbacks := db.Callback()
+	if err := callbacks.Create().After("gorm:create").Register("bourbon:sticky_create", markWrite); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("bourbon:sticky_update", markWrite); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("gorm:delete").Register("bourbon:sticky_delete", markWrite); err != nil {
+		return err
+	}
+	if err := callbacks.Raw().After("gorm:raw").Register("bourbon:sticky_raw", markWrite); err != nil {
+		return err
+	}
+	if err := callbacks.Query().After("gorm:db_resolver").Before("gorm:query").Register("bourbon:sticky_query", readPrimary); err != nil {
+		return err
+	}
+	return callbacks.Row().After("gorm:db_resolver").Before("gorm:row").Register("bourbon:sticky_row", readPrimary)
+}
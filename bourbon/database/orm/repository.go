@@ -0,0 +1,306 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrNotFound is returned when a query matches no record. It is gorm.ErrRecordNotFound,
+// so errors.Is works with either.
+var ErrNotFound = gorm.ErrRecordNotFound
+
+// Repository is a typed data-access API for a model. Controllers depend on this
+// interface so tests can substitute a fake instead of a database.
+type Repository[T any] interface {
+	Find(ctx context.Context, opts ...QueryOption) ([]T, error)
+	First(ctx context.Context, opts ...QueryOption) (*T, error)
+	FindByID(ctx context.Context, id interface{}, opts ...QueryOption) (*T, error)
+	Count(ctx context.Context, opts ...QueryOption) (int64, error)
+	Paginate(ctx context.Context, page, perPage int, opts ...QueryOption) (*Page[T], error)
+	Create(ctx context.Context, model *T) error
+	Update(ctx context.Context, model *T) error
+	Delete(ctx context.Context, model *T) error
+	Restore(ctx context.Context, model *T) error
+	ForceDelete(ctx context.Context, model *T) error
+	// WithDB returns a repository bound to another connection, e.g. a transaction
+	WithDB(db *gorm.DB) Repository[T]
+}
+
+// Page is one page of results
+type Page[T any] struct {
+	Items      []T   `json:"items"`
+	Total      int64 `json:"total"`
+	Page       int   `json:"page"`
+	PerPage    int   `json:"per_page"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// HasNext reports whether there is a page after this one
+func (p *Page[T]) HasNext() bool {
+	return p.Page < p.TotalPages
+}
+
+// QueryOption narrows, orders or eager-loads a repository query
+type QueryOption func(*gorm.DB) *gorm.DB
+
+// Where filters on a column with an operator: =, !=, >, >=, <, <=, like, in, not in, is null, is not null.
+// Column names are quoted, so they are safe to take from user input after whitelisting.
+func Where(column, op string, value interface{}) QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		expr, err := filterExpr(column, op, value)
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		return db.Where(expr)
+	}
+}
+
+// Eq filters on column = value
+func Eq(column string, value interface{}) QueryOption {
+	return Where(column, "=", value)
+}
+
+// In filters on column IN values
+func In(column string, values interface{}) QueryOption {
+	return Where(column, "in", values)
+}
+
+// Like filters on column LIKE pattern
+func Like(column, pattern string) QueryOption {
+	return Where(column, "like", pattern)
+}
+
+// Or matches records satisfying any of the given filters
+func Or(opts ...QueryOption) QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		var group *gorm.DB
+		for _, opt := range opts {
+			cond := opt(db.Session(&gorm.Session{NewDB: true}))
+			if group == nil {
+				group = cond
+			} else {
+				group = group.Or(cond)
+			}
+		}
+		if group == nil {
+			return db
+		}
+		return db.Where(group)
+	}
+}
+
+// OrderBy sorts by a column; desc selects descending order
+func OrderBy(column string, desc bool) QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: desc})
+	}
+}
+
+// Preload eager-loads an association, optionally with conditions
+func Preload(association string, args ...interface{}) QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Preload(association, args...)
+	}
+}
+
+// Limit caps the number of records returned
+func Limit(n int) QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Limit(n)
+	}
+}
+
+// Offset skips the first n records
+func Offset(n int) QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Offset(n)
+	}
+}
+
+// Scope applies an arbitrary GORM scope for anything the DSL does not cover
+func Scope(fn func(*gorm.DB) *gorm.DB) QueryOption {
+	return QueryOption(fn)
+}
+
+// filterExpr builds a quoted clause expression for a column filter
+func filterExpr(column, op string, value interface{}) (clause.Expression, error) {
+	col := clause.Column{Name: column}
+	switch strings.ToLower(strings.TrimSpace(op)) {
+	case "=", "==", "eq":
+		return clause.Eq{Column: col, Value: value}, nil
+	case "!=", "<>", "ne":
+		return clause.Neq{Column: col, Value: value}, nil
+	case ">", "gt":
+		return clause.Gt{Column: col, Value: value}, nil
+	case ">=", "gte":
+		return clause.Gte{Column: col, Value: value}, nil
+	case "<", "lt":
+		return clause.Lt{Column: col, Value: value}, nil
+	case "<=", "lte":
+		return clause.Lte{Column: col, Value: value}, nil
+	case "like":
+		return clause.Like{Column: col, Value: value}, nil
+	case "in":
+		return clause.IN{Column: col, Values: toInterfaces(value)}, nil
+	case "not in":
+		return clause.Not(clause.IN{Column: col, Values: toInterfaces(value)}), nil
+	case "is null":
+		return clause.Eq{Column: col, Value: nil}, nil
+	case "is not null":
+		return clause.Neq{Column: col, Value: nil}, nil
+	default:
+		return nil, fmt.Errorf("unsupported filter operator: %s", op)
+	}
+}
+
+// toInterfaces expands a slice value for IN clauses
+func toInterfaces(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case []string:
+		out := make([]interface{}, len(v))
+		for i := range v {
+			out[i] = v[i]
+		}
+		return out
+	case []int:
+		out := make([]interface{}, len(v))
+		for i := range v {
+			out[i] = v[i]
+		}
+		return out
+	case []uint:
+		out := make([]interface{}, len(v))
+		for i := range v {
+			out[i] = v[i]
+		}
+		return out
+	default:
+		return []interface{}{value}
+	}
+}
+
+// GormRepository implements Repository with GORM. Calls join the transaction carried
+// by ctx (see Transaction), so repositories need no rebinding inside transactions.
+type GormRepository[T any] struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a repository for model type T
+//
+//	posts := orm.NewRepository[Post](app.DB)
+//	recent, err := posts.Find(ctx, orm.Eq("published", true), orm.OrderBy("created_at", true), orm.Limit(10))
+func NewRepository[T any](db *gorm.DB) *GormRepository[T] {
+	return &GormRepository[T]{db: db}
+}
+
+// query starts a statement for T with the given options applied
+func (r *GormRepository[T]) query(ctx context.Context, opts []QueryOption) *gorm.DB {
+	db := Conn(ctx, r.db).Model(new(T))
+	for _, opt := range opts {
+		db = opt(db)
+	}
+	return db
+}
+
+// Find returns all records matching the options
+func (r *GormRepository[T]) Find(ctx context.Context, opts ...QueryOption) ([]T, error) {
+	var items []T
+	err := r.query(ctx, opts).Find(&items).Error
+	return items, err
+}
+
+// First returns the first record matching the options, or ErrNotFound
+func (r *GormRepository[T]) First(ctx context.Context, opts ...QueryOption) (*T, error) {
+	var item T
+	if err := r.query(ctx, opts).First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// FindByID returns the record with the given primary key, or ErrNotFound
+func (r *GormRepository[T]) FindByID(ctx context.Context, id interface{}, opts ...QueryOption) (*T, error) {
+	var item T
+	if err := r.query(ctx, opts).First(&item, id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Count returns the number of records matching the options
+func (r *GormRepository[T]) Count(ctx context.Context, opts ...QueryOption) (int64, error) {
+	var count int64
+	err := r.query(ctx, opts).Count(&count).Error
+	return count, err
+}
+
+// Paginate returns one page of records; page numbers start at 1
+func (r *GormRepository[T]) Paginate(ctx context.Context, page, perPage int, opts ...QueryOption) (*Page[T], error) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 20
+	}
+
+	total, err := r.Count(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []T
+	err = r.query(ctx, opts).Limit(perPage).Offset((page - 1) * perPage).Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &Page[T]{
+		Items:      items,
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: int((total + int64(perPage) - 1) / int64(perPage)),
+	}, nil
+}
+
+// Create inserts a record
+func (r *GormRepository[T]) Create(ctx context.Context, model *T) error {
+	return Conn(ctx, r.db).Create(model).Error
+}
+
+// Update saves all fields of a record
+func (r *GormRepository[T]) Update(ctx context.Context, model *T) error {
+	return Conn(ctx, r.db).Save(model).Error
+}
+
+// Delete removes a record (soft delete for models with DeletedAt)
+func (r *GormRepository[T]) Delete(ctx context.Context, model *T) error {
+	return Conn(ctx, r.db).Delete(model).Error
+}
+
+// Restore undoes a soft delete
+func (r *GormRepository[T]) Restore(ctx context.Context, model *T) error {
+	return Restore(Conn(ctx, r.db), model)
+}
+
+// ForceDelete permanently removes a record, bypassing soft delete
+func (r *GormRepository[T]) ForceDelete(ctx context.Context, model *T) error {
+	return ForceDelete(Conn(ctx, r.db), model)
+}
+
+// WithDB returns a repository bound to another connection, e.g. a transaction
+func (r *GormRepository[T]) WithDB(db *gorm.DB) Repository[T] {
+	return &GormRepository[T]{db: db}
+}
+
+// DB returns the underlying connection for queries the repository does not cover
+func (r *GormRepository[T]) DB() *gorm.DB {
+	return r.db
+}
@@ -0,0 +1,116 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// DeletedAtColumn is the soft-delete column of BaseModel
+const DeletedAtColumn = "deleted_at"
+
+var deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+
+// WithTrashed includes soft-deleted records. It works as a repository option or a GORM scope:
+//
+//	posts.Find(ctx, orm.WithTrashed())
+//	db.Scopes(orm.WithTrashed()).Find(&posts)
+func WithTrashed() QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}
+}
+
+// OnlyTrashed returns only soft-deleted records
+func OnlyTrashed() QueryOption {
+	return func(db *gorm.DB) *gorm.DB {
+		column := deletedAtColumnOf(db)
+		return db.Unscoped().Where(clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Value: nil})
+	}
+}
+
+// Restore clears the soft-delete timestamp of a record, or of every trashed record
+// matching the conditions already on db:
+//
+//	orm.Restore(app.DB, &post)
+//	orm.Restore(app.DB.Where("author_id = ?", id), &Post{})
+func Restore(db *gorm.DB, model interface{}) error {
+	tx := db.Unscoped().Model(model)
+	column := deletedAtColumnOf(tx)
+	return tx.Where(clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Value: nil}).
+		Update(column, nil).Error
+}
+
+// ForceDelete permanently deletes a record, bypassing soft delete
+func ForceDelete(db *gorm.DB, model interface{}) error {
+	return db.Unscoped().Delete(model).Error
+}
+
+// Prune permanently deletes records of model soft-deleted before cutoff and returns how many were removed
+func Prune(db *gorm.DB, model interface{}, cutoff time.Time) (int64, error) {
+	tx := db.Unscoped().Model(model)
+	column := deletedAtColumnOf(tx)
+	result := tx.Where(clause.Lt{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Value: cutoff}).
+		Delete(model)
+	return result.RowsAffected, result.Error
+}
+
+// PruneTable is Prune for a table by name, for tools that have no model type.
+// The table must have a deleted_at column.
+func PruneTable(db *gorm.DB, table string, cutoff time.Time) (int64, error) {
+	if !db.Migrator().HasColumn(table, DeletedAtColumn) {
+		return 0, fmt.Errorf("table %s has no %s column", table, DeletedAtColumn)
+	}
+	result := db.Exec("DELETE FROM ? WHERE ? < ?", clause.Table{Name: table}, clause.Column{Name: DeletedAtColumn}, cutoff)
+	return result.RowsAffected, result.Error
+}
+
+// CountPrunable counts rows of a table soft-deleted before cutoff
+func CountPrunable(db *gorm.DB, table string, cutoff time.Time) (int64, error) {
+	var count int64
+	err := db.Table(table).Where(clause.Lt{Column: clause.Column{Name: DeletedAtColumn}, Value: cutoff}).Count(&count).Error
+	return count, err
+}
+
+// SoftDeleteTables lists the tables with a deleted_at column
+func SoftDeleteTables(db *gorm.DB) ([]string, error) {
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, table := range tables {
+		if db.Migrator().HasColumn(table, DeletedAtColumn) {
+			result = append(result, table)
+		}
+	}
+	return result, nil
+}
+
+// deletedAtColumnOf finds the gorm.DeletedAt column of the statement's model,
+// falling back to deleted_at when the model is unknown
+func deletedAtColumnOf(db *gorm.DB) string {
+	if db.Statement.Model == nil {
+		return DeletedAtColumn
+	}
+	if err := db.Statement.Parse(db.Statement.Model); err != nil {
+		return DeletedAtColumn
+	}
+	if field := softDeleteField(db.Statement.Schema); field != nil {
+		return field.DBName
+	}
+	return DeletedAtColumn
+}
+
+func softDeleteField(s *schema.Schema) *schema.Field {
+	for _, field := range s.Fields {
+		if field.FieldType == deletedAtType && field.DBName != "" {
+			return field
+		}
+	}
+	return nil
+}
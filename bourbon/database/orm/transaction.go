@@ -0,0 +1,143 @@
+package orm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxTransactionAttempts bounds retries of transactions failing with a retryable error
+const maxTransactionAttempts = 5
+
+// txContextKey is the context key holding the current transaction
+type txContextKey struct{}
+
+// ContextWithTx returns a copy of ctx carrying tx, so Transaction, Conn and
+// repositories called with it join the transaction
+func ContextWithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// TxFromContext returns the transaction carried by ctx, if any
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*gorm.DB)
+	return tx, ok && tx != nil
+}
+
+// Conn returns the transaction carried by ctx, or db bound to ctx when there is none
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// commitHooksKey is the context key holding callbacks deferred until the transaction commits
+type commitHooksKey struct{}
+
+type commitHooks struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+func (h *commitHooks) add(fns ...func()) {
+	h.mu.Lock()
+	h.fns = append(h.fns, fns...)
+	h.mu.Unlock()
+}
+
+func (h *commitHooks) run() {
+	h.mu.Lock()
+	fns := h.fns
+	h.fns = nil
+	h.mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// AfterCommit runs fn once the transaction carried by ctx commits, or right away when
+// ctx is not in a transaction. Hooks of a transaction or savepoint that rolls back are dropped.
+func AfterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(commitHooksKey{}).(*commitHooks); ok {
+		hooks.add(fn)
+		return
+	}
+	fn()
+}
+
+// ContextWithCommitHooks returns a copy of ctx that collects AfterCommit callbacks and a
+// function running them, for code that begins transactions without Transaction
+func ContextWithCommitHooks(ctx context.Context) (context.Context, func()) {
+	hooks := &commitHooks{}
+	return context.WithValue(ctx, commitHooksKey{}, hooks), hooks.run
+}
+
+// Transaction runs fn in a transaction that commits when fn returns nil and rolls back
+// when it returns an error or panics (the panic is re-raised after the rollback).
+//
+// When ctx already carries a transaction, or db is itself a transaction, fn runs in a
+// savepoint instead: an error rolls back only fn's work and the outer transaction continues.
+// The tx passed to fn carries a context holding itself, so nested calls made with
+// tx.Statement.Context join it.
+//
+// On drivers whose features mark errors as retryable (CockroachDB serialization failures),
+// an outermost transaction is retried a few times, so fn must be safe to run again.
+// Use AfterCommit for side effects that must only happen once the data is committed.
+//
+//	err := orm.Transaction(ctx, app.DB, func(tx *gorm.DB) error {
+//	    if err := tx.Create(&order).Error; err != nil {
+//	        return err
+//	    }
+//	    return tx.Model(&stock).Update("quantity", gorm.Expr("quantity - ?", order.Quantity)).Error
+//	})
+func Transaction(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	conn := Conn(ctx, db)
+	run := func() error {
+		hooks := &commitHooks{}
+		txCtx := context.WithValue(ctx, commitHooksKey{}, hooks)
+
+		// GORM uses SAVEPOINT / ROLLBACK TO when conn is already a transaction,
+		// and rolls back before re-panicking if fn panics
+		err := conn.Transaction(func(tx *gorm.DB) error {
+			return fn(tx.WithContext(ContextWithTx(txCtx, tx)))
+		})
+		if err != nil {
+			return err
+		}
+
+		// A savepoint's hooks wait for the enclosing transaction
+		if parent, ok := ctx.Value(commitHooksKey{}).(*commitHooks); ok {
+			parent.add(hooks.fns...)
+		} else {
+			hooks.run()
+		}
+		return nil
+	}
+
+	// Only outermost transactions can be retried; a savepoint failure belongs to the caller
+	retryable := FeaturesFor(conn.Dialector.Name()).Retryable
+	if _, nested := conn.Statement.ConnPool.(gorm.TxCommitter); nested || retryable == nil {
+		return run()
+	}
+
+	var err error
+	for attempt := 0; attempt < maxTransactionAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt*attempt) * 10 * time.Millisecond):
+			}
+		}
+		if err = run(); err == nil || !retryable(err) {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,72 @@
+package orm
+
+import (
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	timePtrType = reflect.TypeOf(&time.Time{})
+)
+
+// EnableUTC converts the time fields of records to UTC before they are created or
+// updated, so the database holds UTC whatever timezone the application works in.
+// ConnectDatabase enables it.
+func EnableUTC(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("bourbon:utc", toUTC); err != nil {
+		return err
+	}
+	return db.Callback().Update().Before("gorm:update").Register("bourbon:utc", toUTC)
+}
+
+func toUTC(db *gorm.DB) {
+	if db.Statement.Schema == nil || !db.Statement.ReflectValue.IsValid() {
+		return
+	}
+	var fields []*schema.Field
+	for _, field := range db.Statement.Schema.Fields {
+		if field.FieldType == timeType || field.FieldType == timePtrType {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+
+	ctx := db.Statement.Context
+	convert := func(record reflect.Value) {
+		for _, field := range fields {
+			value, zero := field.ValueOf(ctx, record)
+			if zero {
+				continue
+			}
+			switch t := value.(type) {
+			case time.Time:
+				if t.Location() != time.UTC {
+					_ = field.Set(ctx, record, t.UTC())
+				}
+			case *time.Time:
+				if t != nil && t.Location() != time.UTC {
+					utc := t.UTC()
+					_ = field.Set(ctx, record, &utc)
+				}
+			}
+		}
+	}
+
+	rv := reflect.Indirect(db.Statement.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if record := reflect.Indirect(rv.Index(i)); record.Kind() == reflect.Struct {
+				convert(record)
+			}
+		}
+	case reflect.Struct:
+		convert(rv)
+	}
+}
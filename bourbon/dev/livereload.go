@@ -0,0 +1,139 @@
+package dev
+
+import (
+	"net/http"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+// LiveReloadEnv is set on the app process started by the dev server to the URL of
+// the live-reload server. Apps in debug mode inject the browser script when it is set.
+const LiveReloadEnv = "BOURBON_LIVERELOAD"
+
+// Events sent to browsers
+const (
+	eventReload  = "reload"  // templates or static files changed
+	eventCSS     = "css"     // only stylesheets changed; swapped without a reload
+	eventRestart = "restart" // the app is restarting; reload once it answers again
+)
+
+// ScriptURL returns the URL of the browser script served by the live-reload server
+// at base, e.g. os.Getenv(LiveReloadEnv)
+func ScriptURL(base string) string {
+	return base + "/livereload.js"
+}
+
+// hub broadcasts events to connected browsers over websockets
+type hub struct {
+	mu      sync.Mutex
+	clients map[chan string]struct{}
+	ws      websocket.Server
+}
+
+func newHub() *hub {
+	h := &hub{clients: make(map[chan string]struct{})}
+	// No Handshake: pages are served from another origin than the dev server
+	h.ws = websocket.Server{Handler: h.serve}
+	return h
+}
+
+func (h *hub) broadcast(event string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for client := range h.clients {
+		select {
+		case client <- event:
+		default:
+			// A slow browser misses the event; it gets the next one
+		}
+	}
+}
+
+func (h *hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/livereload.js":
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write([]byte(clientScript))
+	case "/livereload":
+		h.ws.ServeHTTP(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *hub) serve(conn *websocket.Conn) {
+	client := make(chan string, 4)
+	h.mu.Lock()
+	h.clients[client] = struct{}{}
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, client)
+		h.mu.Unlock()
+	}()
+
+	// Browsers send nothing; a failed read means the tab went away
+	closed := make(chan struct{})
+	go func() {
+		var discard string
+		for websocket.Message.Receive(conn, &discard) == nil {
+		}
+		close(closed)
+	}()
+
+	for {
+		select {
+		case event := <-client:
+			if websocket.Message.Send(conn, event) != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+// clientScript listens for events. The socket URL is derived from the script's own
+// URL, so it works whatever address the script is served from. When the socket
+// drops, e.g. because the app was restarted by hand, it reconnects and reloads.
+const clientScript = `(function () {
+  if (window.__bourbonLiveReload) return;
+  window.__bourbonLiveReload = true;
+  var base = document.currentScript.src.replace(/\/livereload\.js.*$/, "").replace(/^http/, "ws");
+
+  function reloadWhenUp() {
+    var tries = 0;
+    (function poll() {
+      fetch(location.href, { method: "HEAD", cache: "no-store" })
+        .then(function () { location.reload(); })
+        .catch(function () { if (++tries < 100) setTimeout(poll, 200); });
+    })();
+  }
+
+  function swapStylesheets() {
+    document.querySelectorAll('link[rel="stylesheet"]').forEach(function (link) {
+      var url = new URL(link.href);
+      url.searchParams.set("livereload", Date.now());
+      link.href = url.toString();
+    });
+  }
+
+  (function connect(reconnecting, tries) {
+    var socket = new WebSocket(base + "/livereload");
+    socket.onopen = function () {
+      if (reconnecting) location.reload();
+      tries = 0;
+    };
+    socket.onmessage = function (event) {
+      if (event.data === "reload") location.reload();
+      else if (event.data === "css") swapStylesheets();
+      else if (event.data === "restart") reloadWhenUp();
+    };
+    socket.onclose = function () {
+      if (tries < 100) setTimeout(function () { connect(true, tries + 1); }, 500);
+    };
+  })(false, 0);
+})();
+`
@@ -0,0 +1,92 @@
+package dev
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/middleware"
+)
+
+// ReloaderPath is where an app's own Reloader serves the browser script and socket
+const ReloaderPath = "/__bourbon"
+
+// Reloader refreshes browsers from inside a running app, for apps started with
+// `go run .` rather than the dev server. It watches template and static
+// directories; Go changes still need a restart, after which open tabs reconnect
+// and reload.
+type Reloader struct {
+	hub     *hub
+	watcher *watcher
+}
+
+// NewReloader watches dirs, skipping any that do not exist
+func NewReloader(dirs ...string) (*Reloader, error) {
+	var roots []string
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, abs)
+	}
+
+	w, err := newWatcher(roots, nil, 100*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	r := &Reloader{hub: newHub(), watcher: w}
+	go r.run()
+	return r, nil
+}
+
+func (r *Reloader) run() {
+	for {
+		select {
+		case batch, ok := <-r.watcher.batches:
+			if !ok {
+				return
+			}
+			if classify(batch) == changeCSS {
+				r.hub.broadcast(eventCSS)
+			} else {
+				r.hub.broadcast(eventReload)
+			}
+		case _, ok := <-r.watcher.errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// Middleware serves the script and socket under ReloaderPath and injects the script
+// into HTML pages. It must run outermost so the socket can take over the connection.
+func (r *Reloader) Middleware() middleware.Middleware {
+	inject := middleware.LiveReload(ScriptURL(ReloaderPath))
+	endpoints := http.StripPrefix(ReloaderPath, r.hub)
+
+	return func(next http.Handler) http.Handler {
+		injected := inject(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if strings.HasPrefix(req.URL.Path, ReloaderPath+"/") {
+				endpoints.ServeHTTP(w, req)
+				return
+			}
+			injected.ServeHTTP(w, req)
+		})
+	}
+}
+
+// Close stops watching
+func (r *Reloader) Close() error {
+	return r.watcher.Close()
+}
@@ -0,0 +1,235 @@
+// Package dev runs an application under a development server that rebuilds and
+// restarts it when Go code changes, and reloads browsers when templates or static
+// files change. Start it with `bourbon serve` or `go run . dev`.
+package dev
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"syscall"
+	"time"
+)
+
+// Config configures the dev server
+type Config struct {
+	Dir            string        // project directory (default ".")
+	Package        string        // package to build (default ".")
+	Args           []string      // arguments passed to the app
+	BuildFlags     []string      // extra go build flags, e.g. "-tags", "dev"
+	LiveReloadAddr string        // browser live-reload address (default "127.0.0.1:35729"); "-" disables it
+	Delay          time.Duration // quiet time before acting on changes (default 200ms)
+	Ignore         []string      // directories not watched, besides tmp, vendor, node_modules and hidden ones
+	Output         io.Writer     // dev server messages (default os.Stdout)
+}
+
+// server builds, runs and restarts the app
+type server struct {
+	cfg     Config
+	binary  string
+	hub     *hub
+	reload  string // live-reload base URL, "" when disabled
+	process *exec.Cmd
+	exited  chan error
+}
+
+// Run starts the dev server and blocks until it is interrupted
+func Run(cfg Config) error {
+	s, err := newServer(cfg)
+	if err != nil {
+		return err
+	}
+	return s.run()
+}
+
+func newServer(cfg Config) (*server, error) {
+	if cfg.Dir == "" {
+		cfg.Dir = "."
+	}
+	if cfg.Package == "" {
+		cfg.Package = "."
+	}
+	if cfg.LiveReloadAddr == "" {
+		cfg.LiveReloadAddr = "127.0.0.1:35729"
+	}
+	if cfg.Delay <= 0 {
+		cfg.Delay = 200 * time.Millisecond
+	}
+	if cfg.Output == nil {
+		cfg.Output = os.Stdout
+	}
+	dir, err := filepath.Abs(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Dir = dir
+
+	binary := filepath.Join(dir, "tmp", "bourbon-dev")
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	return &server{cfg: cfg, binary: binary, hub: newHub()}, nil
+}
+
+func (s *server) logf(format string, args ...interface{}) {
+	fmt.Fprintf(s.cfg.Output, "[dev] "+format+"\n", args...)
+}
+
+func (s *server) run() error {
+	if s.cfg.LiveReloadAddr != "-" {
+		listener, err := net.Listen("tcp", s.cfg.LiveReloadAddr)
+		if err != nil {
+			s.logf("Live reload disabled: %v", err)
+		} else {
+			s.reload = "http://" + listener.Addr().String()
+			go func() { _ = http.Serve(listener, s.hub) }()
+			defer listener.Close()
+		}
+	}
+
+	w, err := newWatcher([]string{s.cfg.Dir}, s.cfg.Ignore, s.cfg.Delay)
+	if err != nil {
+		return fmt.Errorf("failed to watch %s: %w", s.cfg.Dir, err)
+	}
+	defer w.Close()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	s.logf("Watching %s for changes", s.cfg.Dir)
+	if err := s.build(); err == nil {
+		s.start()
+	}
+
+	for {
+		select {
+		case batch := <-w.batches:
+			s.handle(batch)
+		case err := <-w.errors:
+			s.logf("Watch error: %v", err)
+		case err := <-s.exited:
+			s.exited = nil
+			s.process = nil
+			s.logf("App exited (%v); waiting for changes", err)
+		case <-quit:
+			s.stop()
+			return nil
+		}
+	}
+}
+
+func (s *server) handle(batch []string) {
+	for i, path := range batch {
+		if rel, err := filepath.Rel(s.cfg.Dir, path); err == nil {
+			batch[i] = rel
+		}
+	}
+
+	switch classify(batch) {
+	case changeRebuild:
+		s.logf("Changed: %s; rebuilding", s.summary(batch))
+		if err := s.build(); err != nil {
+			if s.process != nil {
+				s.logf("Keeping the previous build running")
+			}
+			return
+		}
+		s.stop()
+		s.start()
+		s.hub.broadcast(eventRestart)
+	case changeRestart:
+		s.logf("Changed: %s; restarting", s.summary(batch))
+		s.stop()
+		s.start()
+		s.hub.broadcast(eventRestart)
+	case changeCSS:
+		s.hub.broadcast(eventCSS)
+	default:
+		s.hub.broadcast(eventReload)
+	}
+}
+
+// summary names the changed files, leaving out temporary files that editors have
+// already renamed or removed
+func (s *server) summary(paths []string) string {
+	var existing []string
+	for _, path := range paths {
+		if _, err := os.Stat(filepath.Join(s.cfg.Dir, path)); err == nil {
+			existing = append(existing, path)
+		}
+	}
+	if len(existing) > 0 {
+		paths = existing
+	}
+	sort.Strings(paths)
+	if len(paths) == 1 {
+		return paths[0]
+	}
+	return fmt.Sprintf("%s and %d more", paths[0], len(paths)-1)
+}
+
+// build compiles the app, printing compiler errors
+func (s *server) build() error {
+	started := time.Now()
+	args := append([]string{"build", "-o", s.binary}, s.cfg.BuildFlags...)
+	cmd := exec.Command("go", append(args, s.cfg.Package)...)
+	cmd.Dir = s.cfg.Dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		s.cfg.Output.Write(output)
+		s.logf("Build failed: %v", err)
+		return err
+	}
+	s.logf("Built in %s", time.Since(started).Round(time.Millisecond))
+	return nil
+}
+
+func (s *server) start() {
+	cmd := exec.Command(s.binary, s.cfg.Args...)
+	cmd.Dir = s.cfg.Dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if s.reload != "" {
+		cmd.Env = append(cmd.Env, LiveReloadEnv+"="+s.reload)
+	}
+	if err := cmd.Start(); err != nil {
+		s.logf("Failed to start app: %v", err)
+		return
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	s.process = cmd
+	s.exited = exited
+}
+
+// stop interrupts the app so it shuts down gracefully, and kills it if it is still
+// running after 10 seconds
+func (s *server) stop() {
+	if s.process == nil {
+		return
+	}
+	cmd, exited := s.process, s.exited
+	s.process, s.exited = nil, nil
+
+	// Windows cannot deliver interrupts to other processes
+	if runtime.GOOS == "windows" || cmd.Process.Signal(os.Interrupt) != nil {
+		_ = cmd.Process.Kill()
+	}
+	select {
+	case <-exited:
+	case <-time.After(10 * time.Second):
+		s.logf("App did not stop in time; killing it")
+		_ = cmd.Process.Kill()
+		<-exited
+	}
+}
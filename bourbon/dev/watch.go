@@ -0,0 +1,167 @@
+package dev
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// defaultIgnore lists directories that are never watched, besides hidden ones
+var defaultIgnore = []string{"tmp", "vendor", "node_modules"}
+
+// watcher reports changed files in batches, once no change has been seen for delay,
+// so saving many files at once causes a single rebuild
+type watcher struct {
+	fs      *fsnotify.Watcher
+	roots   []string
+	ignore  map[string]bool
+	delay   time.Duration
+	batches chan []string
+	errors  chan error
+}
+
+func newWatcher(roots []string, ignore []string, delay time.Duration) (*watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &watcher{
+		fs:      fsw,
+		roots:   roots,
+		ignore:  make(map[string]bool),
+		delay:   delay,
+		batches: make(chan []string),
+		errors:  make(chan error),
+	}
+	for _, name := range append(defaultIgnore, ignore...) {
+		w.ignore[filepath.Clean(name)] = true
+	}
+	for _, root := range roots {
+		if err := w.addTree(root, root); err != nil {
+			fsw.Close()
+			return nil, err
+		}
+	}
+	go w.run()
+	return w, nil
+}
+
+// addTree watches dir and its subdirectories
+func (w *watcher) addTree(root, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && w.skipDir(root, path) {
+			return filepath.SkipDir
+		}
+		return w.fs.Add(path)
+	})
+}
+
+func (w *watcher) skipDir(root, path string) bool {
+	if strings.HasPrefix(filepath.Base(path), ".") {
+		return true
+	}
+	rel, err := filepath.Rel(root, path)
+	return err == nil && (w.ignore[rel] || w.ignore[filepath.Base(path)])
+}
+
+// rootOf returns the watched root containing path
+func (w *watcher) rootOf(path string) string {
+	for _, root := range w.roots {
+		if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return root
+		}
+	}
+	return filepath.Dir(path)
+}
+
+func (w *watcher) run() {
+	defer close(w.batches)
+	defer close(w.errors)
+
+	pending := make(map[string]bool)
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod || editorFile(event.Name) {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if root := w.rootOf(event.Name); !w.skipDir(root, event.Name) {
+						_ = w.addTree(root, event.Name)
+					}
+					continue
+				}
+			}
+			pending[event.Name] = true
+			timer.Reset(w.delay)
+
+		case <-timer.C:
+			batch := make([]string, 0, len(pending))
+			for path := range pending {
+				batch = append(batch, path)
+			}
+			pending = make(map[string]bool)
+			w.batches <- batch
+
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+			w.errors <- err
+		}
+	}
+}
+
+func (w *watcher) Close() error {
+	return w.fs.Close()
+}
+
+// editorFile reports swap and backup files that editors write while saving
+func editorFile(path string) bool {
+	base := filepath.Base(path)
+	return strings.HasSuffix(base, "~") || strings.HasPrefix(base, ".#") || base == "4913" ||
+		strings.HasSuffix(base, ".swp") || strings.HasSuffix(base, ".swx") || strings.HasSuffix(base, ".tmp")
+}
+
+// change is what a batch of changed files requires
+type change int
+
+const (
+	changeCSS     change = iota // only stylesheets: swap them in browsers
+	changeAssets                // templates or static files: reload browsers
+	changeRestart               // settings: restart the app
+	changeRebuild               // Go code or modules: rebuild and restart
+)
+
+func classify(paths []string) change {
+	result := changeCSS
+	for _, path := range paths {
+		base := filepath.Base(path)
+		switch {
+		case filepath.Ext(base) == ".go" || base == "go.mod" || base == "go.sum":
+			return changeRebuild
+		case filepath.Ext(base) == ".toml" || strings.HasPrefix(base, ".env"):
+			result = max(result, changeRestart)
+		case filepath.Ext(base) != ".css":
+			result = max(result, changeAssets)
+		}
+	}
+	return result
+}
@@ -0,0 +1,49 @@
+// Package cockroach provides CockroachDB database driver for Bourbon framework.
+// Import this package to enable CockroachDB support:
+//
+// import _ "github.com/ishubhamsingh2e/bourbon/bourbon/drivers/cockroach"
+//
+// CockroachDB speaks the PostgreSQL protocol, so the postgres dialect is used for
+// queries, but the driver reports its own name so migrations and transactions can
+// account for the differences: schema changes are not transactional, there are no
+// advisory locks (orm.NewLock holds a lease row instead), and serialization
+// failures (SQLSTATE 40001) must be retried.
+package cockroach
+
+import (
+	"errors"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// serializationFailure is the SQLSTATE of transactions CockroachDB asks clients to retry
+const serializationFailure = "40001"
+
+func init() {
+	orm.RegisterDriver("cockroach", cockroachDialector)
+	orm.RegisterDriverFeatures("cockroach", orm.DriverFeatures{
+		Retryable: isRetryable,
+	})
+}
+
+// Dialector is the postgres dialector reporting the name "cockroach"
+type Dialector struct {
+	*postgres.Dialector
+}
+
+// Name returns the driver name used to look up its features
+func (Dialector) Name() string {
+	return "cockroach"
+}
+
+func cockroachDialector(cfg orm.DatabaseConfig) (gorm.Dialector, error) {
+	return Dialector{Dialector: postgres.Open(orm.PostgresDSN(cfg)).(*postgres.Dialector)}, nil
+}
+
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == serializationFailure
+}
@@ -5,8 +5,7 @@
 package mysql
 
 import (
-	"fmt"
-
+	mysqldriver "github.com/go-sql-driver/mysql"
 	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -17,13 +16,15 @@ func init() {
 }
 
 func mysqlDialector(cfg orm.DatabaseConfig) (gorm.Dialector, error) {
-	dsn := fmt.Sprintf(
-		"%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		cfg.User,
-		cfg.Password,
-		cfg.Host,
-		cfg.Port,
-		cfg.Name,
-	)
-	return mysql.Open(dsn), nil
+	tlsConfig, err := orm.TLSConfig(cfg.Options)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		tlsConfig.ServerName = cfg.Host
+		if err := mysqldriver.RegisterTLSConfig(orm.MySQLTLSConfigName, tlsConfig); err != nil {
+			return nil, err
+		}
+	}
+	return mysql.Open(orm.MySQLDSN(cfg)), nil
 }
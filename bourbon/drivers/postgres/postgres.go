@@ -5,8 +5,6 @@
 package postgres
 
 import (
-	"fmt"
-
 	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -17,18 +15,5 @@ func init() {
 }
 
 func postgresDialector(cfg orm.DatabaseConfig) (gorm.Dialector, error) {
-	sslMode := cfg.Options.SSLMode
-	if sslMode == "" {
-		sslMode = "disable"
-	}
-	dsn := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Host,
-		cfg.Port,
-		cfg.User,
-		cfg.Password,
-		cfg.Name,
-		sslMode,
-	)
-	return postgres.Open(dsn), nil
+	return postgres.Open(orm.PostgresDSN(cfg)), nil
 }
@@ -15,12 +15,5 @@ func init() {
 }
 
 func sqliteDialector(cfg orm.DatabaseConfig) (gorm.Dialector, error) {
-	path := cfg.Path
-	if path == "" {
-		path = cfg.Name
-	}
-	if path == "" {
-		path = "bourbon.db"
-	}
-	return sqlite.Open(path), nil
+	return sqlite.Open(orm.SQLiteDSN(cfg)), nil
 }
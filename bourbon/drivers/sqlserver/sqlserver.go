@@ -0,0 +1,23 @@
+// Package sqlserver provides Microsoft SQL Server database driver for Bourbon framework.
+// Import this package to enable SQL Server support:
+//
+// import _ "github.com/ishubhamsingh2e/bourbon/bourbon/drivers/sqlserver"
+package sqlserver
+
+import (
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+	"gorm.io/driver/sqlserver"
+	"gorm.io/gorm"
+)
+
+func init() {
+	orm.RegisterDriver("sqlserver", sqlserverDialector)
+	orm.RegisterDriverFeatures("sqlserver", orm.DriverFeatures{
+		TransactionalDDL: true,
+		AdvisoryLocks:    true, // sp_getapplock
+	})
+}
+
+func sqlserverDialector(cfg orm.DatabaseConfig) (gorm.Dialector, error) {
+	return sqlserver.Open(orm.SQLServerDSN(cfg)), nil
+}
@@ -0,0 +1,226 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/jobs"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+	"go.uber.org/zap"
+)
+
+// Event is something that happened in the application. Names are dotted,
+// e.g. "user.registered" or "order.paid".
+type Event interface {
+	EventName() string
+}
+
+// Named is an event without a dedicated type:
+//
+//	app.Events.Dispatch(ctx, events.Named{Name: "cache.cleared"})
+type Named struct {
+	Name    string
+	Payload interface{}
+}
+
+func (e Named) EventName() string {
+	return e.Name
+}
+
+// Listener handles an event
+type Listener func(ctx context.Context, event Event) error
+
+// Binding subscribes a listener registered with Register to an event pattern.
+// The application reads them from [[events.listeners]].
+type Binding struct {
+	Event    string
+	Listener string
+	Queued   bool
+}
+
+type subscription struct {
+	pattern  string
+	name     string // listener name, for logs
+	listener Listener
+	queued   bool
+	accepts  func(Event) bool // matches by type instead of pattern when set
+}
+
+// Bus delivers events to listeners. Sync listeners run in Dispatch, in the order
+// they subscribed. Queued listeners run on the job queue once the dispatching
+// transaction, if any, has committed.
+type Bus struct {
+	mu            sync.RWMutex
+	subscriptions []subscription
+	named         map[string]Listener
+	bindings      []Binding
+	queue         jobs.Queue
+	logger        *logging.Logger
+}
+
+// NewBus creates a bus. Queued listeners run on queue, or synchronously when it is nil.
+func NewBus(queue jobs.Queue, l *logging.Logger) *Bus {
+	b := &Bus{named: make(map[string]Listener), queue: queue}
+	if l != nil {
+		b.logger = l.Module(logging.ModuleEvents)
+	}
+	return b
+}
+
+// Listen calls listener synchronously for events matching pattern. A pattern is an
+// event name, a prefix ending in ".*" ("user.*") or "*" for every event.
+func (b *Bus) Listen(pattern string, listener Listener) {
+	b.subscribe(subscription{pattern: pattern, name: pattern, listener: listener})
+}
+
+// ListenQueued calls listener on the job queue for events matching pattern
+func (b *Bus) ListenQueued(pattern string, listener Listener) {
+	b.subscribe(subscription{pattern: pattern, name: pattern, listener: listener, queued: true})
+}
+
+// Register names a listener so settings can subscribe it with [[events.listeners]]
+func (b *Bus) Register(name string, listener Listener) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.named[name] = listener
+}
+
+// Bind subscribes the listener registered under binding.Listener. The listener
+// may be registered after Bind; it is looked up when an event is dispatched.
+func (b *Bus) Bind(bindings ...Binding) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bindings = append(b.bindings, bindings...)
+}
+
+// UnboundListeners returns the binding listener names that were never registered
+func (b *Bus) UnboundListeners() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var missing []string
+	for _, binding := range b.bindings {
+		if _, ok := b.named[binding.Listener]; !ok {
+			missing = append(missing, binding.Listener)
+		}
+	}
+	return missing
+}
+
+func (b *Bus) subscribe(s subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions = append(b.subscriptions, s)
+}
+
+// Dispatch delivers event to its listeners. The first sync listener error stops
+// delivery and is returned; queued listener errors are logged.
+func (b *Bus) Dispatch(ctx context.Context, event Event) error {
+	name := event.EventName()
+	var queued []subscription
+	for _, s := range b.matching(event) {
+		if s.queued {
+			queued = append(queued, s)
+			continue
+		}
+		if err := s.listener(ctx, event); err != nil {
+			return fmt.Errorf("events: %s listener for %s: %w", s.name, name, err)
+		}
+	}
+	if len(queued) == 0 {
+		return nil
+	}
+
+	orm.AfterCommit(ctx, func() {
+		for _, s := range queued {
+			listener := s.listener
+			job := func(ctx context.Context) error { return listener(ctx, event) }
+			if b.queue == nil {
+				if err := job(ctx); err != nil {
+					b.logError("Event listener failed", name, s.name, err)
+				}
+				continue
+			}
+			if err := b.queue.Enqueue(ctx, "event."+name, job); err != nil {
+				b.logError("Failed to queue event listener", name, s.name, err)
+			}
+		}
+	})
+	return nil
+}
+
+// matching returns the subscriptions and bindings for event
+func (b *Bus) matching(event Event) []subscription {
+	name := event.EventName()
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var result []subscription
+	for _, s := range b.subscriptions {
+		if s.accepts != nil && s.accepts(event) || s.accepts == nil && Match(s.pattern, name) {
+			result = append(result, s)
+		}
+	}
+	for _, binding := range b.bindings {
+		if !Match(binding.Event, name) {
+			continue
+		}
+		listener, ok := b.named[binding.Listener]
+		if !ok {
+			if b.logger != nil {
+				b.logger.Warn("Event listener is not registered", zap.String("event", name), zap.String("listener", binding.Listener))
+			}
+			continue
+		}
+		result = append(result, subscription{pattern: binding.Event, name: binding.Listener, listener: listener, queued: binding.Queued})
+	}
+	return result
+}
+
+func (b *Bus) logError(msg, event, listener string, err error) {
+	if b.logger != nil {
+		b.logger.Error(msg, zap.String("event", event), zap.String("listener", listener), zap.Error(err))
+	}
+}
+
+// Match reports whether pattern matches an event name. "*" matches every event and
+// "user.*" matches every event starting with "user.".
+func Match(pattern, name string) bool {
+	switch {
+	case pattern == "*":
+		return true
+	case strings.HasSuffix(pattern, ".*"):
+		return strings.HasPrefix(name, strings.TrimSuffix(pattern, "*"))
+	default:
+		return pattern == name
+	}
+}
+
+// On subscribes a sync listener to events of type T, whatever their name:
+//
+//	events.On(app.Events, func(ctx context.Context, e UserRegistered) error {
+//	    return audit.Record(ctx, "signup", e.UserID)
+//	})
+func On[T Event](b *Bus, fn func(ctx context.Context, event T) error) {
+	b.subscribe(typed(fn, false))
+}
+
+// OnQueued subscribes a queued listener to events of type T
+func OnQueued[T Event](b *Bus, fn func(ctx context.Context, event T) error) {
+	b.subscribe(typed(fn, true))
+}
+
+func typed[T Event](fn func(ctx context.Context, event T) error, queued bool) subscription {
+	return subscription{
+		name:   fmt.Sprintf("%T", *new(T)),
+		queued: queued,
+		accepts: func(event Event) bool {
+			_, ok := event.(T)
+			return ok
+		},
+		listener: func(ctx context.Context, event Event) error {
+			return fn(ctx, event.(T))
+		},
+	}
+}
@@ -0,0 +1,465 @@
+// Package forms renders HTML forms from structs and binds their submissions back,
+// in the spirit of Django's ModelForm:
+//
+//	var PostForm = forms.Auto[Post](
+//	    forms.Field("Body").Widget(forms.Textarea),
+//	    forms.Field("Status").Choices("draft", "published"),
+//	)
+//
+//	func edit(c *http.Context) error {
+//	    post := loadPost(c)
+//	    form := PostForm.New(c, &post)
+//	    if c.Method() == "POST" {
+//	        if form = PostForm.Bind(c, &post); form.Valid() {
+//	            db.Save(&post)
+//	            return c.Redirect(303, "/posts")
+//	        }
+//	    }
+//	    return c.Render("post_form.html", http.H{"form": form})
+//	}
+//
+// and in the template:
+//
+//	<form method="post">{{ .form.HTML }}<button>Save</button></form>
+//
+// Field names come from `form` tags, then json tags; labels from `label` tags;
+// rules from `validate` tags, as checked by the validation package.
+package forms
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/validation"
+	"gorm.io/gorm"
+)
+
+// NonFieldErrors is the error key for failures that belong to no single field
+const NonFieldErrors = "non_field_errors"
+
+// Widgets select how a field is rendered
+const (
+	TextInput     = "text"
+	EmailInput    = "email"
+	URLInput      = "url"
+	PasswordInput = "password" // never re-rendered with its value
+	NumberInput   = "number"
+	DateInput     = "date"
+	DateTimeInput = "datetime-local"
+	HiddenInput   = "hidden"
+	CheckboxInput = "checkbox"
+	Textarea      = "textarea"
+	Select        = "select"
+)
+
+// Choice is an option of a select field
+type Choice struct {
+	Value string
+	Label string
+}
+
+// FieldSpec declares one form field
+type FieldSpec struct {
+	source   string
+	name     string
+	label    string
+	help     string
+	widget   string
+	choices  []Choice
+	rules    string
+	hasRules bool
+	omit     bool
+}
+
+// Field declares a field bound to the struct field source, a Go field name
+func Field(source string) *FieldSpec {
+	return &FieldSpec{source: source}
+}
+
+// As sets the input name; the default is the field's form or json tag, or Go name
+func (f *FieldSpec) As(name string) *FieldSpec {
+	f.name = name
+	return f
+}
+
+// Label sets the label text; the default is the `label` tag or the Go name in words
+func (f *FieldSpec) Label(label string) *FieldSpec {
+	f.label = label
+	return f
+}
+
+// Help sets a hint shown below the input
+func (f *FieldSpec) Help(text string) *FieldSpec {
+	f.help = text
+	return f
+}
+
+// Widget sets how the field is rendered, e.g. forms.Textarea; the default depends
+// on the field type and rules
+func (f *FieldSpec) Widget(widget string) *FieldSpec {
+	f.widget = widget
+	return f
+}
+
+// Choices renders the field as a select of the given values
+func (f *FieldSpec) Choices(values ...string) *FieldSpec {
+	f.choices = nil
+	for _, value := range values {
+		f.choices = append(f.choices, Choice{Value: value, Label: value})
+	}
+	return f
+}
+
+// LabeledChoices renders the field as a select with separate option labels
+func (f *FieldSpec) LabeledChoices(choices ...Choice) *FieldSpec {
+	f.choices = choices
+	return f
+}
+
+// Validate sets the rules, e.g. "required,min=3"; the default is the `validate` tag
+func (f *FieldSpec) Validate(rules string) *FieldSpec {
+	f.rules = rules
+	f.hasRules = true
+	return f
+}
+
+// Omit leaves a field out of an Auto form
+func (f *FieldSpec) Omit() *FieldSpec {
+	f.omit = true
+	return f
+}
+
+// field is a FieldSpec resolved against the model type
+type field struct {
+	FieldSpec
+	index []int
+	typ   reflect.Type
+	rules validation.Rules
+}
+
+// Form describes the fields of a form for T. Create one per model with New or Auto
+// and share it; New and Bind create the per-request BoundForm.
+type Form[T any] struct {
+	fields     []*field
+	validators []func(*T) error
+}
+
+// New creates a form with exactly the given fields. It panics if a field, rule or
+// field type is not supported, since that is a programming error.
+func New[T any](fields ...*FieldSpec) *Form[T] {
+	f := &Form[T]{}
+	for _, spec := range fields {
+		if !spec.omit {
+			f.fields = append(f.fields, f.resolve(spec))
+		}
+	}
+	return f
+}
+
+// Auto creates a form with every editable field of T. Primary keys, timestamps
+// managed by gorm, fields tagged `form:"-"` or `json:"-"` and types without an HTML
+// input, such as relations, are left out. Specs change how a field is handled; use
+// Omit to leave one out.
+func Auto[T any](overrides ...*FieldSpec) *Form[T] {
+	specs := make(map[string]*FieldSpec)
+	for _, spec := range overrides {
+		specs[spec.source] = spec
+	}
+
+	var fields []*FieldSpec
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for _, sf := range reflect.VisibleFields(t) {
+		if spec, ok := specs[sf.Name]; ok {
+			fields = append(fields, spec)
+			delete(specs, sf.Name)
+			continue
+		}
+		if !sf.IsExported() || sf.Anonymous || sf.Tag.Get("form") == "-" || managed(sf) {
+			continue
+		}
+		if sf.Tag.Get("json") == "-" && sf.Tag.Get("form") == "" {
+			continue
+		}
+		if _, ok := defaultWidget(sf.Type, nil); !ok {
+			continue
+		}
+		fields = append(fields, Field(sf.Name))
+	}
+	for _, spec := range overrides {
+		if _, ok := specs[spec.source]; ok {
+			fields = append(fields, spec)
+		}
+	}
+	return New[T](fields...)
+}
+
+// managed reports whether gorm sets the field: primary keys and timestamps
+func managed(sf reflect.StructField) bool {
+	switch sf.Name {
+	case "ID", "CreatedAt", "UpdatedAt":
+		return true
+	}
+	return sf.Type == reflect.TypeOf(gorm.DeletedAt{}) || strings.Contains(strings.ToLower(sf.Tag.Get("gorm")), "primarykey")
+}
+
+func (f *Form[T]) resolve(spec *FieldSpec) *field {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	sf, ok := t.FieldByName(spec.source)
+	if !ok || !sf.IsExported() {
+		panic(fmt.Sprintf("forms: %s has no exported field %s", t, spec.source))
+	}
+	fd := &field{FieldSpec: *spec, index: sf.Index, typ: sf.Type}
+
+	if fd.name == "" {
+		fd.name = strings.Split(sf.Tag.Get("form"), ",")[0]
+	}
+	if fd.name == "" {
+		fd.name = validation.FieldName(sf)
+	}
+	if fd.label == "" {
+		fd.label = sf.Tag.Get("label")
+	}
+	if fd.label == "" {
+		fd.label = humanize(sf.Name)
+	}
+
+	rules := spec.rules
+	if !spec.hasRules {
+		rules = sf.Tag.Get("validate")
+	}
+	parsed, err := validation.Parse(rules)
+	if err != nil {
+		panic(fmt.Sprintf("forms: %s: %v", spec.source, err))
+	}
+	fd.rules = parsed
+
+	if len(fd.choices) == 0 {
+		for _, rule := range parsed {
+			if rule.Name == "oneof" {
+				fd.Choices(strings.Fields(rule.Param)...)
+			}
+		}
+	}
+	elem := sf.Type
+	if elem.Kind() == reflect.Slice && len(fd.choices) > 0 {
+		elem = elem.Elem()
+	}
+	widget, ok := defaultWidget(elem, parsed)
+	if !ok {
+		panic(fmt.Sprintf("forms: %s: unsupported field type %s", spec.source, sf.Type))
+	}
+	if fd.widget == "" {
+		fd.widget = widget
+		if len(fd.choices) > 0 {
+			fd.widget = Select
+		}
+	}
+	return fd
+}
+
+// ValidateWith adds a check that runs on the bound model after the field rules
+// pass. Return validation.Errors to report fields; other errors are shown above
+// the fields.
+func (f *Form[T]) ValidateWith(fn func(*T) error) *Form[T] {
+	f.validators = append(f.validators, fn)
+	return f
+}
+
+// New returns an unbound form showing the values of model, e.g. for an edit page.
+// A nil model shows an empty form.
+func (f *Form[T]) New(c *bourbon.Context, model *T) *BoundForm {
+	if model == nil {
+		model = new(T)
+	}
+	form := f.bound(c)
+	v := reflect.ValueOf(model).Elem()
+	for i, fd := range f.fields {
+		form.fields[i].Value, form.fields[i].Values = formatValue(v.FieldByIndex(fd.index), fd.widget, form.location)
+	}
+	return form
+}
+
+// Bind reads a submitted form into model. When any field is invalid, model is left
+// unchanged and the returned form shows the submitted values with their errors.
+func (f *Form[T]) Bind(c *bourbon.Context, model *T) *BoundForm {
+	form := f.bound(c)
+	form.bound = true
+	if err := c.Request.ParseForm(); err != nil {
+		form.errors[NonFieldErrors] = "The form could not be read."
+		return form
+	}
+
+	// Bind into a copy so an invalid submission leaves model untouched
+	working := *model
+	v := reflect.ValueOf(&working).Elem()
+	for i, fd := range f.fields {
+		bf := form.fields[i]
+		raw := c.Request.PostForm[fd.name]
+		if len(raw) > 0 {
+			bf.Value = raw[0]
+		}
+		bf.Values = raw
+
+		decoded := reflect.New(fd.typ).Elem()
+		if err := parseValue(decoded, raw, form.location); err != nil {
+			bf.Error = err.Error()
+			continue
+		}
+		if message := fd.rules.Check(decoded); message != "" {
+			bf.Error = message
+			continue
+		}
+		v.FieldByIndex(fd.index).Set(decoded)
+	}
+
+	if form.collectErrors() == 0 {
+		for _, validate := range f.validators {
+			err := validate(&working)
+			if err == nil {
+				continue
+			}
+			if fieldErrs, ok := err.(validation.Errors); ok {
+				for name, message := range fieldErrs {
+					if bf := form.Field(name); bf != nil {
+						bf.Error = message
+					} else {
+						form.errors[name] = message
+					}
+				}
+			} else {
+				form.errors[NonFieldErrors] = err.Error()
+			}
+		}
+		form.collectErrors()
+	}
+	if len(form.errors) == 0 {
+		*model = working
+	}
+	return form
+}
+
+func (f *Form[T]) bound(c *bourbon.Context) *BoundForm {
+	form := &BoundForm{
+		csrfToken: c.CSRFToken(),
+		location:  c.Location(),
+		errors:    validation.Errors{},
+	}
+	for _, fd := range f.fields {
+		form.fields = append(form.fields, &BoundField{
+			Name:     fd.name,
+			ID:       "id_" + fd.name,
+			Label:    fd.label,
+			Help:     fd.help,
+			Widget:   fd.widget,
+			Choices:  fd.choices,
+			Required: fd.rules.Required(),
+			Multiple: fd.typ.Kind() == reflect.Slice,
+		})
+	}
+	return form
+}
+
+// BoundForm is a form for one request: its values, errors and HTML
+type BoundForm struct {
+	fields    []*BoundField
+	errors    validation.Errors
+	bound     bool
+	csrfToken string
+	location  *time.Location
+}
+
+// BoundField is one field of a BoundForm
+type BoundField struct {
+	Name     string
+	ID       string
+	Label    string
+	Help     string
+	Widget   string
+	Choices  []Choice
+	Required bool
+	Multiple bool     // a select of several values
+	Value    string   // the value shown in the input
+	Values   []string // all values of a multiple select
+	Error    string
+}
+
+// Valid reports whether the form was submitted and every field passed
+func (f *BoundForm) Valid() bool {
+	return f.bound && len(f.errors) == 0
+}
+
+// Errors returns the failures keyed by field name, with NonFieldErrors for the
+// others, or nil when there are none
+func (f *BoundForm) Errors() validation.Errors {
+	if len(f.errors) == 0 {
+		return nil
+	}
+	return f.errors
+}
+
+// AddError reports a failure found after binding, e.g. a unique constraint the
+// database rejected. Use NonFieldErrors for errors that belong to no field.
+func (f *BoundForm) AddError(name, message string) {
+	if bf := f.Field(name); bf != nil {
+		bf.Error = message
+	}
+	f.errors[name] = message
+}
+
+// Fields returns the fields in declaration order, for rendering them one by one
+func (f *BoundForm) Fields() []*BoundField {
+	return f.fields
+}
+
+// Field returns the field with the given input name, or nil
+func (f *BoundForm) Field(name string) *BoundField {
+	for _, bf := range f.fields {
+		if bf.Name == name {
+			return bf
+		}
+	}
+	return nil
+}
+
+// NonFieldError returns the error that belongs to no single field, or ""
+func (f *BoundForm) NonFieldError() string {
+	return f.errors[NonFieldErrors]
+}
+
+// collectErrors copies the field errors into f.errors and returns their count
+func (f *BoundForm) collectErrors() int {
+	for _, bf := range f.fields {
+		if bf.Error != "" {
+			f.errors[bf.Name] = bf.Error
+		}
+	}
+	return len(f.errors)
+}
+
+// humanize turns a Go field name such as "HomepageURL" into "Homepage URL"
+func humanize(name string) string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		upper := isUpper(runes[i])
+		if upper && (!isUpper(runes[i-1]) || i+1 < len(runes) && !isUpper(runes[i+1])) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	words = append(words, string(runes[start:]))
+	for i, word := range words {
+		if i > 0 && strings.ToUpper(word) != word {
+			words[i] = strings.ToLower(word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func isUpper(r rune) bool {
+	return r >= 'A' && r <= 'Z'
+}
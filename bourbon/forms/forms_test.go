@@ -0,0 +1,245 @@
+package forms
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/validation"
+	"gorm.io/gorm"
+)
+
+type post struct {
+	gorm.Model
+	Title       string `validate:"required,max=20"`
+	Email       string `form:"contact" validate:"email"`
+	Body        string
+	Status      string `validate:"oneof=draft published"`
+	Views       int    `label:"View count" validate:"min=0"`
+	Featured    bool
+	PublishedAt *time.Time
+	Tags        []string
+	Secret      string `form:"-"`
+}
+
+var postForm = Auto[post](
+	Field("Body").Widget(Textarea).Help("Markdown is allowed."),
+	Field("Tags").Choices("go", "web"),
+)
+
+// formContext returns a context for a request posting values, or a GET when values is nil
+func formContext(values url.Values, loc *time.Location) *bourbon.Context {
+	req := httptest.NewRequest(http.MethodGet, "/posts", nil)
+	if values != nil {
+		req = httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	ctx := bourbon.WithCSRFToken(req.Context(), "token")
+	if loc != nil {
+		ctx = bourbon.WithLocation(ctx, loc)
+	}
+	return bourbon.NewContext(httptest.NewRecorder(), req.WithContext(ctx))
+}
+
+func TestAutoFields(t *testing.T) {
+	form := postForm.New(formContext(nil, nil), nil)
+	var got []string
+	for _, bf := range form.Fields() {
+		got = append(got, bf.Name+":"+bf.Widget)
+	}
+	want := []string{"Title:text", "contact:email", "Body:textarea", "Status:select", "Views:number",
+		"Featured:checkbox", "PublishedAt:datetime-local", "Tags:select"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fields\n got %v\nwant %v", got, want)
+	}
+	if bf := form.Field("Views"); bf.Label != "View count" {
+		t.Errorf("Views label = %q", bf.Label)
+	}
+	if bf := form.Field("PublishedAt"); bf.Label != "Published at" {
+		t.Errorf("PublishedAt label = %q", bf.Label)
+	}
+	if !form.Field("Title").Required || form.Field("Body").Required {
+		t.Error("required follows the validate tags")
+	}
+	if form.Valid() {
+		t.Error("an unbound form is valid")
+	}
+}
+
+func TestBind(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	values := url.Values{
+		"Title":       {"Hello"},
+		"contact":     {"a@example.com"},
+		"Body":        {"text"},
+		"Status":      {"published"},
+		"Views":       {"3"},
+		"Featured":    {"on"},
+		"PublishedAt": {"2024-05-01T10:30"},
+		"Tags":        {"go", "web"},
+		"Secret":      {"ignored"},
+	}
+	var p post
+	form := postForm.Bind(formContext(values, paris), &p)
+	if !form.Valid() {
+		t.Fatalf("Bind errors: %v", form.Errors())
+	}
+	want := time.Date(2024, 5, 1, 10, 30, 0, 0, paris)
+	if p.Title != "Hello" || p.Email != "a@example.com" || p.Status != "published" || p.Views != 3 || !p.Featured ||
+		p.PublishedAt == nil || !p.PublishedAt.Equal(want) || !reflect.DeepEqual(p.Tags, []string{"go", "web"}) || p.Secret != "" {
+		t.Errorf("bound %+v", p)
+	}
+
+	// Empty values clear pointers and unchecked boxes are not posted
+	values = url.Values{"Title": {"Hello"}, "Status": {"draft"}, "PublishedAt": {""}}
+	form = postForm.Bind(formContext(values, time.UTC), &p)
+	if !form.Valid() {
+		t.Fatalf("Bind errors: %v", form.Errors())
+	}
+	if p.PublishedAt != nil || p.Featured || p.Views != 0 || p.Tags == nil || len(p.Tags) != 0 {
+		t.Errorf("rebound %+v", p)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	values := url.Values{
+		"Title":       {"This title is far too long"},
+		"contact":     {"not-an-email"},
+		"Status":      {"archived"},
+		"Views":       {"-1"},
+		"PublishedAt": {"yesterday"},
+	}
+	original := post{Title: "Kept"}
+	p := original
+	form := postForm.Bind(formContext(values, nil), &p)
+	if form.Valid() {
+		t.Fatal("an invalid submission is valid")
+	}
+	want := validation.Errors{
+		"Title":       "Ensure this field has no more than 20 characters or items.",
+		"contact":     "Enter a valid email address.",
+		"Status":      "Must be one of: draft, published.",
+		"Views":       "Ensure this value is greater than or equal to 0.",
+		"PublishedAt": "Enter a valid date and time.",
+	}
+	if !reflect.DeepEqual(form.Errors(), want) {
+		t.Errorf("errors\n got %v\nwant %v", form.Errors(), want)
+	}
+	if !reflect.DeepEqual(p, original) {
+		t.Errorf("an invalid submission changed the model to %+v", p)
+	}
+
+	// The form shows the submitted values with their errors
+	html := string(form.HTML())
+	for _, fragment := range []string{
+		`<div class="field field-Title has-error">`,
+		`value="This title is far too long"`,
+		`<span class="field-error">Enter a valid email address.</span>`,
+		`<input type="hidden" name="` + bourbon.CSRFFieldName + `" value="token">`,
+	} {
+		if !strings.Contains(html, fragment) {
+			t.Errorf("HTML is missing %s:\n%s", fragment, html)
+		}
+	}
+
+	form = postForm.Bind(formContext(url.Values{}, nil), &p)
+	if form.Errors()["Title"] != "This field is required." {
+		t.Errorf("missing title error = %q", form.Errors()["Title"])
+	}
+}
+
+func TestValidateWith(t *testing.T) {
+	form := New[post](Field("Title").Validate(""), Field("Status").Validate("")).ValidateWith(func(p *post) error {
+		if p.Status == "published" && p.Title == "" {
+			return validation.Errors{"Title": "Published posts need a title."}
+		}
+		return nil
+	}).ValidateWith(func(p *post) error {
+		if p.Title == "spam" {
+			return errors.New("This post was rejected.")
+		}
+		return nil
+	})
+
+	var p post
+	bound := form.Bind(formContext(url.Values{"Status": {"published"}}, nil), &p)
+	if bound.Field("Title").Error != "Published posts need a title." {
+		t.Errorf("field error = %q", bound.Field("Title").Error)
+	}
+	bound = form.Bind(formContext(url.Values{"Title": {"spam"}}, nil), &p)
+	if bound.NonFieldError() != "This post was rejected." || !strings.Contains(string(bound.HTML()), `<div class="form-error">This post was rejected.</div>`) {
+		t.Errorf("non-field error = %q", bound.NonFieldError())
+	}
+	if p.Title != "" {
+		t.Error("a rejected submission changed the model")
+	}
+
+	bound = form.Bind(formContext(url.Values{"Title": {"Hello"}}, nil), &p)
+	bound.AddError("Title", "That title is taken.")
+	if bound.Valid() || bound.Errors()["Title"] != "That title is taken." {
+		t.Errorf("AddError left errors %v", bound.Errors())
+	}
+}
+
+func TestRender(t *testing.T) {
+	published := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+	p := post{Title: `<b>"Hi"</b>`, Status: "draft", Featured: true, PublishedAt: &published, Tags: []string{"web"}}
+	form := postForm.New(formContext(nil, nil), &p)
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"Title", `<input type="text" name="Title" id="id_Title" required value="&lt;b&gt;&#34;Hi&#34;&lt;/b&gt;">`},
+		{"Body", `<textarea name="Body" id="id_Body"></textarea>`},
+		{"Status", `<select name="Status" id="id_Status"><option value=""></option><option value="draft" selected>draft</option><option value="published">published</option></select>`},
+		{"Featured", `<input type="checkbox" name="Featured" id="id_Featured" checked>`},
+		{"PublishedAt", `<input type="datetime-local" name="PublishedAt" id="id_PublishedAt" value="2024-05-01T08:30">`},
+		{"Tags", `<select name="Tags" id="id_Tags" multiple><option value="go">go</option><option value="web" selected>web</option></select>`},
+	}
+	for _, tt := range tests {
+		if got := string(form.Field(tt.field).Input()); got != tt.want {
+			t.Errorf("%s input\n got %s\nwant %s", tt.field, got, tt.want)
+		}
+	}
+	if html := string(form.Field("Body").HTML()); !strings.Contains(html, `<small class="field-help">Markdown is allowed.</small>`) {
+		t.Errorf("Body HTML is missing its help text: %s", html)
+	}
+
+	passwords := New[struct{ Password string }](Field("Password").Widget(PasswordInput))
+	bound := passwords.Bind(formContext(url.Values{"Password": {"secret"}}, nil), &struct{ Password string }{})
+	if html := string(bound.HTML()); strings.Contains(html, "secret") {
+		t.Errorf("a password was rendered back: %s", html)
+	}
+}
+
+func TestNewPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"unknown field", func() { New[post](Field("Missing")) }},
+		{"unknown rule", func() { New[post](Field("Title").Validate("shiny")) }},
+		{"unsupported type", func() { New[post](Field("Model")) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("New did not panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package forms
+
+import (
+	"html/template"
+	"strings"
+
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+)
+
+// HTML renders the CSRF token, the error that belongs to no field and every field.
+// The <form> element and submit button are left to the template.
+func (f *BoundForm) HTML() template.HTML {
+	var b strings.Builder
+	if f.csrfToken != "" {
+		b.WriteString(`<input type="hidden" name="` + bourbon.CSRFFieldName + `" value="` + esc(f.csrfToken) + `">`)
+	}
+	if message := f.NonFieldError(); message != "" {
+		b.WriteString(`<div class="form-error">` + esc(message) + `</div>`)
+	}
+	for _, bf := range f.fields {
+		b.WriteString(string(bf.HTML()))
+	}
+	return template.HTML(b.String())
+}
+
+// HTML renders the field's label, input, error and help text in a div with the
+// classes "field", "field-<name>" and, when invalid, "has-error"
+func (bf *BoundField) HTML() template.HTML {
+	if bf.Widget == HiddenInput {
+		return bf.Input()
+	}
+	class := "field field-" + bf.Name
+	if bf.Error != "" {
+		class += " has-error"
+	}
+	var b strings.Builder
+	b.WriteString(`<div class="` + esc(class) + `">`)
+	b.WriteString(string(bf.LabelTag()))
+	b.WriteString(string(bf.Input()))
+	if bf.Error != "" {
+		b.WriteString(`<span class="field-error">` + esc(bf.Error) + `</span>`)
+	}
+	if bf.Help != "" {
+		b.WriteString(`<small class="field-help">` + esc(bf.Help) + `</small>`)
+	}
+	b.WriteString(`</div>`)
+	return template.HTML(b.String())
+}
+
+// LabelTag renders the field's <label>
+func (bf *BoundField) LabelTag() template.HTML {
+	return template.HTML(`<label for="` + esc(bf.ID) + `">` + esc(bf.Label) + `</label>`)
+}
+
+// Input renders the field's input, textarea or select with its current value
+func (bf *BoundField) Input() template.HTML {
+	attrs := ` name="` + esc(bf.Name) + `" id="` + esc(bf.ID) + `"`
+	if bf.Required && bf.Widget != CheckboxInput {
+		attrs += ` required`
+	}
+	if bf.Error != "" {
+		attrs += ` aria-invalid="true"`
+	}
+
+	switch bf.Widget {
+	case Textarea:
+		return template.HTML(`<textarea` + attrs + `>` + esc(bf.Value) + `</textarea>`)
+	case Select:
+		var b strings.Builder
+		if bf.Multiple {
+			attrs += ` multiple`
+		}
+		b.WriteString(`<select` + attrs + `>`)
+		if !bf.Multiple && !bf.Required {
+			b.WriteString(`<option value=""></option>`)
+		}
+		for _, choice := range bf.Choices {
+			selected := ""
+			if bf.selected(choice.Value) {
+				selected = ` selected`
+			}
+			b.WriteString(`<option value="` + esc(choice.Value) + `"` + selected + `>` + esc(choice.Label) + `</option>`)
+		}
+		b.WriteString(`</select>`)
+		return template.HTML(b.String())
+	case CheckboxInput:
+		if bf.Value != "" {
+			attrs += ` checked`
+		}
+		return template.HTML(`<input type="checkbox"` + attrs + `>`)
+	case PasswordInput:
+		return template.HTML(`<input type="password"` + attrs + `>`)
+	}
+	return template.HTML(`<input type="` + esc(bf.Widget) + `"` + attrs + ` value="` + esc(bf.Value) + `">`)
+}
+
+func (bf *BoundField) selected(value string) bool {
+	if !bf.Multiple {
+		return bf.Value == value
+	}
+	for _, v := range bf.Values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func esc(s string) string {
+	return template.HTMLEscapeString(s)
+}
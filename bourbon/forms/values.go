@@ -0,0 +1,168 @@
+package forms
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/validation"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Value formats of <input type="date"> and <input type="datetime-local">
+const (
+	dateLayout     = "2006-01-02"
+	datetimeLayout = "2006-01-02T15:04"
+)
+
+// defaultWidget returns the widget for a field type, or false when no HTML input
+// can edit it. Slices are only supported as selects with choices.
+func defaultWidget(t reflect.Type, rules validation.Rules) (string, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return DateTimeInput, true
+	}
+	switch t.Kind() {
+	case reflect.String:
+		for _, rule := range rules {
+			switch rule.Name {
+			case "email":
+				return EmailInput, true
+			case "url":
+				return URLInput, true
+			}
+		}
+		return TextInput, true
+	case reflect.Bool:
+		return CheckboxInput, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return NumberInput, true
+	}
+	return "", false
+}
+
+// formatValue formats v for its input; slices are returned as values of a
+// multiple select
+func formatValue(v reflect.Value, widget string, loc *time.Location) (string, []string) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Slice {
+		values := make([]string, v.Len())
+		for i := range values {
+			values[i], _ = formatValue(v.Index(i), widget, loc)
+		}
+		return "", values
+	}
+
+	switch value := v.Interface().(type) {
+	case time.Time:
+		if value.IsZero() {
+			return "", nil
+		}
+		if widget == DateInput {
+			return value.In(loc).Format(dateLayout), nil
+		}
+		return value.In(loc).Format(datetimeLayout), nil
+	case bool:
+		if value {
+			return "on", nil
+		}
+		return "", nil
+	}
+	if widget == PasswordInput {
+		return "", nil
+	}
+	return fmt.Sprint(v.Interface()), nil
+}
+
+// parseValue sets v from the submitted values. Empty values set pointers to nil and
+// other non-string types to their zero value; times are read in loc.
+func parseValue(v reflect.Value, raw []string, loc *time.Location) error {
+	if v.Kind() == reflect.Slice {
+		out := reflect.MakeSlice(v.Type(), 0, len(raw))
+		for _, item := range raw {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := parseValue(elem, []string{item}, loc); err != nil {
+				return err
+			}
+			out = reflect.Append(out, elem)
+		}
+		v.Set(out)
+		return nil
+	}
+
+	value := ""
+	if len(raw) > 0 {
+		value = strings.TrimSpace(raw[0])
+	}
+	if v.Kind() == reflect.Ptr {
+		if value == "" {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		elem := reflect.New(v.Type().Elem())
+		if err := parseValue(elem.Elem(), raw, loc); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+	if value == "" && v.Kind() != reflect.String {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	if v.Type() == timeType {
+		for _, layout := range []string{datetimeLayout, "2006-01-02T15:04:05", dateLayout} {
+			if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+				v.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return errors.New("Enter a valid date and time.")
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			// Checkboxes post "on"
+			b = value == "on"
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("Enter a whole number.")
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("Enter a positive whole number.")
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return errors.New("Enter a number.")
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("Unsupported type %s.", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+// Package gomod reads a project's go.mod for the code generators: the module
+// path of the project, the path the framework is imported under, which differs
+// from the upstream one for forks and vanity import paths, and whether the
+// modules generated code imports are required and, in a vendored project,
+// vendored.
+package gomod
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Upstream is the framework's own module path, which generators write imports
+// with before Rewrite moves them to the project's
+const Upstream = "github.com/ishubhamsingh2e/bourbon"
+
+// File is a parsed go.mod
+type File struct {
+	Dir      string            // directory of the go.mod
+	Module   string            // module path
+	Requires map[string]string // required module paths and their versions
+	Replaces map[string]string // replaced module paths and their replacements
+}
+
+// Read parses the go.mod in dir
+func Read(dir string) (*File, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("must run from project root (go.mod not found)")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f := &File{Dir: dir, Requires: make(map[string]string), Replaces: make(map[string]string)}
+	block := ""
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case block != "" && fields[0] == ")":
+			block = ""
+		case block != "":
+			f.directive(block, fields)
+		case len(fields) == 2 && fields[1] == "(":
+			block = fields[0]
+		default:
+			f.directive(fields[0], fields[1:])
+		}
+	}
+	if f.Module == "" {
+		return nil, fmt.Errorf("go.mod has no module directive")
+	}
+	return f, nil
+}
+
+func (f *File) directive(verb string, args []string) {
+	switch verb {
+	case "module":
+		if len(args) > 0 {
+			f.Module = unquote(args[0])
+		}
+	case "require":
+		if len(args) >= 2 {
+			f.Requires[unquote(args[0])] = args[1]
+		}
+	case "replace":
+		for i, arg := range args {
+			if arg == "=>" && i > 0 && i+1 < len(args) {
+				f.Replaces[unquote(args[0])] = unquote(args[i+1])
+			}
+		}
+	}
+}
+
+func unquote(s string) string {
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s
+}
+
+// Framework returns the module path the project imports the framework under:
+// Upstream, or the fork or vanity path it requires instead. A fork is recognized
+// by the project importing its bourbon/ packages, or else by its name.
+func (f *File) Framework() string {
+	if f.Module == Upstream {
+		return Upstream
+	}
+	if _, ok := f.Requires[Upstream]; ok {
+		return Upstream
+	}
+
+	modules := make([]string, 0, len(f.Requires))
+	for module := range f.Requires {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+
+	imports, _ := Imports(filepath.Join(f.Dir, "main.go"))
+	for _, module := range modules {
+		for _, imp := range imports {
+			if strings.HasPrefix(imp, module+"/bourbon/") {
+				return module
+			}
+		}
+	}
+	for _, module := range modules {
+		if path.Base(module) == "bourbon" {
+			return module
+		}
+	}
+	return Upstream
+}
+
+// Rewrite moves the framework imports of generated source from Upstream to the
+// framework path the project uses
+func (f *File) Rewrite(source []byte) []byte {
+	framework := f.Framework()
+	if framework == Upstream {
+		return source
+	}
+	return []byte(strings.ReplaceAll(string(source), Upstream+"/bourbon/", framework+"/bourbon/"))
+}
+
+// Vendored reports whether the project vendors its dependencies
+func (f *File) Vendored() bool {
+	_, err := os.Stat(filepath.Join(f.Dir, "vendor", "modules.txt"))
+	return err == nil
+}
+
+// Missing returns the packages the files import that no module go.mod requires
+// provides or, in a vendored project, that are not in vendor/
+func (f *File) Missing(files ...string) ([]string, error) {
+	seen := make(map[string]bool)
+	var missing []string
+	for _, file := range files {
+		imports, err := Imports(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, imp := range imports {
+			if seen[imp] || !strings.Contains(strings.SplitN(imp, "/", 2)[0], ".") || f.inModule(imp) {
+				continue
+			}
+			seen[imp] = true
+			if !f.provided(imp) {
+				missing = append(missing, imp)
+			}
+		}
+	}
+	return missing, nil
+}
+
+// inModule reports whether pkg is one of the project's own packages
+func (f *File) inModule(pkg string) bool {
+	return pkg == f.Module || strings.HasPrefix(pkg, f.Module+"/")
+}
+
+// provided reports whether a required module provides pkg
+func (f *File) provided(pkg string) bool {
+	for module := range f.Requires {
+		if pkg == module || strings.HasPrefix(pkg, module+"/") {
+			if !f.Vendored() {
+				return true
+			}
+			_, err := os.Stat(filepath.Join(f.Dir, "vendor", filepath.FromSlash(pkg)))
+			return err == nil
+		}
+	}
+	return false
+}
+
+// Verify prints what to run when the project's modules don't provide the
+// packages the generated files import
+func (f *File) Verify(files ...string) {
+	missing, err := f.Missing(files...)
+	if err != nil || len(missing) == 0 {
+		return
+	}
+	command := "go mod tidy"
+	if f.Vendored() {
+		command += " && go mod vendor"
+	}
+	fmt.Printf("The project's modules don't provide %s yet: run %s, or generate with --tidy\n", strings.Join(missing, ", "), command)
+}
+
+// Tidy runs go mod tidy in the project, and go mod vendor when it is vendored.
+// Its output goes to stderr, leaving stdout to the generators' results.
+func (f *File) Tidy() error {
+	commands := [][]string{{"go", "mod", "tidy"}}
+	if f.Vendored() {
+		commands = append(commands, []string{"go", "mod", "vendor"})
+	}
+	for _, args := range commands {
+		fmt.Fprintf(os.Stderr, "Running %s\n", strings.Join(args, " "))
+		run := exec.Command(args[0], args[1:]...)
+		run.Dir = f.Dir
+		run.Stdout = os.Stderr
+		run.Stderr = os.Stderr
+		if err := run.Run(); err != nil {
+			return fmt.Errorf("%s failed: %w", strings.Join(args, " "), err)
+		}
+	}
+	return nil
+}
+
+// Imports returns the import paths of a Go file
+func Imports(file string) ([]string, error) {
+	node, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+	imports := make([]string, 0, len(node.Imports))
+	for _, spec := range node.Imports {
+		if imp, err := strconv.Unquote(spec.Path.Value); err == nil {
+			imports = append(imports, imp)
+		}
+	}
+	return imports, nil
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ResolveClientIP returns the client IP for a request. X-Forwarded-For is only
+// consulted when the direct peer is a trusted proxy, and is walked right to left
+// past any further trusted proxies. An entry that isn't an address stops the
+// walk at the last trusted hop. X-Real-IP is only used when a trusted peer sent
+// no X-Forwarded-For.
+func ResolveClientIP(r *http.Request, trusted []*net.IPNet) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	peer := net.ParseIP(host)
+	if peer == nil || !containsIP(trusted, peer) {
+		return peer
+	}
+
+	header := r.Header.Get("X-Forwarded-For")
+	if header == "" {
+		if realIP := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); realIP != nil {
+			return realIP
+		}
+		return peer
+	}
+
+	forwarded := strings.Split(header, ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if ip == nil {
+			// Whatever is left of it could have been written by the client
+			return peer
+		}
+		if !containsIP(trusted, ip) {
+			return ip
+		}
+		peer = ip
+	}
+	return peer
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolveClientIP(t *testing.T) {
+	var trusted []*net.IPNet
+	for _, cidr := range []string{"10.0.0.0/8", "127.0.0.1/32"} {
+		_, n, _ := net.ParseCIDR(cidr)
+		trusted = append(trusted, n)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		{name: "untrusted peer", remoteAddr: "203.0.113.5:4000", forwarded: "1.2.3.4", realIP: "5.6.7.8", want: "203.0.113.5"},
+		{name: "trusted peer without headers", remoteAddr: "10.0.0.2:4000", want: "10.0.0.2"},
+		{name: "trusted peer", remoteAddr: "10.0.0.2:4000", forwarded: "198.51.100.7", want: "198.51.100.7"},
+		{name: "spoofed left entries", remoteAddr: "10.0.0.2:4000", forwarded: "1.1.1.1, 198.51.100.7", want: "198.51.100.7"},
+		{name: "trusted hops", remoteAddr: "127.0.0.1:4000", forwarded: "198.51.100.7, 10.1.1.1, 10.2.2.2", want: "198.51.100.7"},
+		{name: "all hops trusted", remoteAddr: "10.0.0.2:4000", forwarded: "10.1.1.1, 10.2.2.2", want: "10.1.1.1"},
+		{name: "garbage after trusted hop", remoteAddr: "10.0.0.2:4000", forwarded: "1.1.1.1, garbage, 10.3.3.3", want: "10.3.3.3"},
+		{name: "garbage entry", remoteAddr: "10.0.0.2:4000", forwarded: "unknown", want: "10.0.0.2"},
+		{name: "real ip from trusted peer", remoteAddr: "10.0.0.2:4000", realIP: "198.51.100.7", want: "198.51.100.7"},
+		{name: "forwarded wins over real ip", remoteAddr: "10.0.0.2:4000", forwarded: "198.51.100.7", realIP: "1.1.1.1", want: "198.51.100.7"},
+		{name: "invalid real ip", remoteAddr: "10.0.0.2:4000", realIP: "nope", want: "10.0.0.2"},
+		{name: "ipv6 peer", remoteAddr: "[2001:db8::1]:4000", forwarded: "1.2.3.4", want: "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := ResolveClientIP(r, trusted); got.String() != tt.want {
+				t.Errorf("ResolveClientIP() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.2:4000"
+	r.Header.Set("X-Forwarded-For", "198.51.100.7")
+	if got := ResolveClientIP(r, nil); got.String() != "10.0.0.2" {
+		t.Errorf("ResolveClientIP() without trusted proxies = %v, want 10.0.0.2", got)
+	}
+}
@@ -3,20 +3,33 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"net"
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/auth"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/cache"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/validation"
 )
 
 type H map[string]interface{}
 
+type contextKey string
+
 type Context struct {
 	Writer          http.ResponseWriter
 	Request         *http.Request
 	Params          map[string]string
 	store           map[string]interface{}
-	TemplateEngine  *TemplateEngine
+	TemplateEngine  Renderer
+	processors      []ContextProcessor
+	location        *time.Location
 	asyncDispatcher AsyncDispatcher // For dispatching async jobs
+	cache           *cache.Cache
+	logger          *logging.Logger
+	trustedProxies  []*net.IPNet
 }
 
 // AsyncDispatcher is an interface for dispatching async jobs
@@ -124,13 +137,56 @@ func (c *Context) Path() string {
 	return c.Request.URL.Path
 }
 
+// clientIPKey stores the client IP resolved by proxy-aware middleware
+const clientIPKey contextKey = "bourbon.http.client_ip"
+
+// WithClientIP returns a copy of ctx carrying the resolved client IP
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey, ip)
+}
+
+// ClientIPFromContext returns the client IP resolved by proxy-aware middleware, if any
+func ClientIPFromContext(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(clientIPKey).(string)
+	return ip, ok && ip != ""
+}
+
+// CSRF token names shared by the CSRF middleware and templates
+const (
+	CSRFFieldName  = "csrf_token"
+	CSRFHeaderName = "X-CSRF-Token"
+)
+
+// csrfTokenKey stores the CSRF token issued by the CSRF middleware
+const csrfTokenKey contextKey = "bourbon.http.csrf_token"
+
+// WithCSRFToken returns a copy of ctx carrying the request's CSRF token
+func WithCSRFToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, csrfTokenKey, token)
+}
+
+// CSRFTokenFromContext returns the CSRF token issued by the CSRF middleware, if any
+func CSRFTokenFromContext(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(csrfTokenKey).(string)
+	return token, ok && token != ""
+}
+
+// CSRFToken returns the request's CSRF token, or "" when the CSRF middleware is not in use
+func (c *Context) CSRFToken() string {
+	token, _ := CSRFTokenFromContext(c.Request.Context())
+	return token
+}
+
+// ClientIP returns the client IP. When the TrustedProxies or IPFilter middleware
+// has resolved it, that value is used; otherwise it is resolved through the
+// router's TrustedProxies, set from security.trusted_proxies.
 func (c *Context) ClientIP() string {
-	if ip := c.Request.Header.Get("X-Forwarded-For"); ip != "" {
-		return strings.Split(ip, ",")[0]
-	}
-	if ip := c.Request.Header.Get("X-Real-IP"); ip != "" {
+	if ip, ok := ClientIPFromContext(c.Request.Context()); ok {
 		return ip
 	}
+	if ip := ResolveClientIP(c.Request, c.trustedProxies); ip != nil {
+		return ip.String()
+	}
 	return c.Request.RemoteAddr
 }
 
@@ -139,7 +195,7 @@ func (c *Context) Render(templateName string, data interface{}) error {
 		return c.HTML(http.StatusInternalServerError, "Template engine not configured")
 	}
 
-	html, err := c.TemplateEngine.Render(templateName, data)
+	html, err := c.renderTemplate(templateName, data)
 	if err != nil {
 		return err
 	}
@@ -152,7 +208,7 @@ func (c *Context) RenderWithStatus(status int, templateName string, data interfa
 		return c.HTML(http.StatusInternalServerError, "Template engine not configured")
 	}
 
-	html, err := c.TemplateEngine.Render(templateName, data)
+	html, err := c.renderTemplate(templateName, data)
 	if err != nil {
 		return err
 	}
@@ -160,8 +216,33 @@ func (c *Context) RenderWithStatus(status int, templateName string, data interfa
 	return c.HTML(status, html)
 }
 
+// renderTemplate renders with the template engine, timing it for the debug toolbar
+func (c *Context) renderTemplate(templateName string, data interface{}) (string, error) {
+	debug, ok := DebugFromContext(c.Request.Context())
+	if !ok {
+		return c.TemplateEngine.Render(templateName, c.templateData(data))
+	}
+	start := time.Now()
+	html, err := c.TemplateEngine.Render(templateName, c.templateData(data))
+	debug.rendered(templateName, time.Since(start), err)
+	return html, err
+}
+
+// UserID returns the ID of the authenticated user, if any
+func (c *Context) UserID() (uint, bool) {
+	return auth.UserIDFromContext(c.Request.Context())
+}
+
+// Can reports whether the authenticated user holds the given permission. A
+// failure to load the permissions counts as a denial; use auth.Check to tell them apart.
+func (c *Context) Can(permission string) bool {
+	return auth.Can(c.Request.Context(), permission)
+}
+
+// Validate checks v against its `validate` struct tags and returns the failing
+// fields keyed by json name, or nil when v is valid
 func (c *Context) Validate(v interface{}) map[string]string {
-	return nil
+	return validation.Struct(v)
 }
 
 // DispatchAsync dispatches an async job and returns job ID
@@ -202,6 +283,23 @@ func (c *Context) GetAsyncResult(jobID string) (interface{}, error) {
 	return c.asyncDispatcher.GetResult(c.Request.Context(), jobID)
 }
 
+// Cache returns the application cache, or nil when the router has none
+func (c *Context) Cache() *cache.Cache {
+	return c.cache
+}
+
+// Logger returns the request's logger: the one middleware such as the debug
+// toolbar set with logging.NewContext, else the application logger
+func (c *Context) Logger() *logging.Logger {
+	if l, ok := logging.FromContext(c.Request.Context()); ok {
+		return l
+	}
+	if c.logger != nil {
+		return c.logger
+	}
+	return logging.Nop()
+}
+
 // SetAsyncDispatcher sets the async dispatcher (called by middleware)
 func (c *Context) SetAsyncDispatcher(dispatcher AsyncDispatcher) {
 	c.asyncDispatcher = dispatcher
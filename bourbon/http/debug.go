@@ -0,0 +1,94 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TemplateRender is a template rendered while serving a request
+type TemplateRender struct {
+	Name     string
+	Duration time.Duration
+	Error    string
+}
+
+// Debug collects what the router knows about a request for the debug toolbar:
+// the matched route, the request as the handler saw it, the values set on the
+// Context and the templates rendered
+type Debug struct {
+	mu      sync.Mutex
+	route   *Route
+	request *http.Request
+	values  map[string]interface{}
+	renders []TemplateRender
+}
+
+type debugKey struct{}
+
+// WithDebug returns a copy of ctx whose requests are described to d
+func WithDebug(ctx context.Context, d *Debug) context.Context {
+	return context.WithValue(ctx, debugKey{}, d)
+}
+
+// DebugFromContext returns the collector set with WithDebug, if any
+func DebugFromContext(ctx context.Context) (*Debug, bool) {
+	d, ok := ctx.Value(debugKey{}).(*Debug)
+	return d, ok && d != nil
+}
+
+// Route returns the route that served the request, or nil when none matched
+func (d *Debug) Route() *Route {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.route
+}
+
+// Request returns the request as the handler last saw it, carrying the values
+// middleware added to its context, or nil when no route matched
+func (d *Debug) Request() *http.Request {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.request
+}
+
+// Values returns the values set on the Context with Set
+func (d *Debug) Values() map[string]interface{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	values := make(map[string]interface{}, len(d.values))
+	for key, value := range d.values {
+		values[key] = value
+	}
+	return values
+}
+
+// Renders returns the templates rendered, in order
+func (d *Debug) Renders() []TemplateRender {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]TemplateRender(nil), d.renders...)
+}
+
+// served records the route and the Context once its handler returns
+func (d *Debug) served(route *Route, c *Context) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.route = route
+	d.request = c.Request
+	d.values = make(map[string]interface{}, len(c.store))
+	for key, value := range c.store {
+		d.values[key] = value
+	}
+}
+
+func (d *Debug) rendered(name string, duration time.Duration, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	render := TemplateRender{Name: name, Duration: duration}
+	if err != nil {
+		render.Error = err.Error()
+	}
+	d.renders = append(d.renders, render)
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// FlashCookieName is the cookie carrying flash messages to the next request
+const FlashCookieName = "bourbon_flash"
+
+// Flash is a one-time message shown on the next rendered page, e.g. after a redirect
+type Flash struct {
+	Level   string `json:"level"` // e.g. "success", "info", "warning", "error"
+	Message string `json:"message"`
+}
+
+const (
+	flashOutKey      = "bourbon.http.flash_out"
+	flashConsumedKey = "bourbon.http.flash_consumed"
+)
+
+// AddFlash stores a message for the next page the client renders:
+//
+//	c.AddFlash("success", "Post published.")
+//	return c.Redirect(http.StatusSeeOther, "/posts")
+func (c *Context) AddFlash(level, message string) {
+	out, _ := c.Get(flashOutKey).([]Flash)
+	out = append(out, Flash{Level: level, Message: message})
+	c.Set(flashOutKey, out)
+	c.setFlashCookie(out)
+}
+
+// Flashes returns the pending flash messages and clears them, so each message is
+// shown once. Messages added during this request are included.
+func (c *Context) Flashes() []Flash {
+	var flashes []Flash
+	if consumed := c.Get(flashConsumedKey); consumed == nil {
+		c.Set(flashConsumedKey, true)
+		if cookie, err := c.Request.Cookie(FlashCookieName); err == nil {
+			if data, err := base64.RawURLEncoding.DecodeString(cookie.Value); err == nil {
+				_ = json.Unmarshal(data, &flashes)
+			}
+			if c.Get(flashOutKey) == nil {
+				c.setFlashCookie(nil)
+			}
+		}
+	}
+	if out, _ := c.Get(flashOutKey).([]Flash); len(out) > 0 {
+		flashes = append(flashes, out...)
+		c.Set(flashOutKey, []Flash{})
+		c.setFlashCookie(nil)
+	}
+	return flashes
+}
+
+// setFlashCookie replaces the flash cookie of the response; nil deletes it
+func (c *Context) setFlashCookie(flashes []Flash) {
+	header := c.Writer.Header()
+	cookies := header.Values("Set-Cookie")
+	header.Del("Set-Cookie")
+	for _, cookie := range cookies {
+		if !strings.HasPrefix(cookie, FlashCookieName+"=") {
+			header.Add("Set-Cookie", cookie)
+		}
+	}
+
+	cookie := &http.Cookie{Name: FlashCookieName, Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode}
+	if len(flashes) == 0 {
+		cookie.MaxAge = -1
+	} else {
+		data, _ := json.Marshal(flashes)
+		cookie.Value = base64.RawURLEncoding.EncodeToString(data)
+	}
+	http.SetCookie(c.Writer, cookie)
+}
@@ -0,0 +1,262 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/markup"
+)
+
+// DefaultFuncs returns the functions every template engine starts with:
+//
+//	{{ .CreatedAt | date "Jan 2, 2006" }}       formats a time.Time or *time.Time
+//	{{ .CreatedAt | dateIn .timezone "15:04" }} formats in another timezone
+//	{{ .Total | number 2 }}                     1234.5 -> "1,234.50"
+//	{{ .Body | truncate 100 }}                  at most 100 characters, ending in "…"
+//	{{ .Body | truncatewords 20 }}              at most 20 words
+//	{{ .Count }} comment{{ pluralize .Count }}  "s" unless the count is 1
+//	{{ pluralize .Count "person" "people" }}    singular and plural forms
+//	{{ template "card" dict "Title" .Name "Items" (list 1 2 3) }}
+//	{{ markdown .Comment.Body }}                user markdown as sanitized HTML
+//	{{ sanitize .Post.HTML }}                   user HTML reduced to an allowlist
+//	{{ safeHTML .RenderedMarkdown }}            trusted HTML, not escaped
+//	<script>const data = {{ json .Data }};</script>
+//	{{ asset "css/site.css" }}                  static file URL
+//	{{ url "/posts/{id}" .ID }}                 fills path parameters, escaped
+//	{{ csrf_field .csrf_token }}                hidden csrf_token input for forms
+//
+// Functions added with AddFunc replace defaults of the same name.
+func DefaultFuncs() template.FuncMap {
+	return template.FuncMap{
+		"date":          DateFunc(nil),
+		"dateIn":        dateIn,
+		"number":        formatNumber,
+		"truncate":      truncate,
+		"truncatewords": truncateWords,
+		"pluralize":     pluralize,
+		"dict":          dict,
+		"list":          func(items ...interface{}) []interface{} { return items },
+		"markdown":      markup.Render,
+		"sanitize":      markup.Sanitize,
+		"safeHTML":      func(s string) template.HTML { return template.HTML(s) },
+		"safeURL":       func(s string) template.URL { return template.URL(s) },
+		"json":          toJSON,
+		"asset":         NewStaticAssets("/static", "").URL,
+		"url":           buildURL,
+		"csrf_field":    csrfField,
+	}
+}
+
+// DateFunc returns the "date" template function, which formats times in loc.
+// With a nil loc times are formatted in their own location.
+func DateFunc(loc *time.Location) func(layout string, t interface{}) string {
+	return func(layout string, t interface{}) string {
+		return formatDate(loc, layout, t)
+	}
+}
+
+// formatDate formats t with a Go layout; zero and nil times render as ""
+func formatDate(loc *time.Location, layout string, t interface{}) string {
+	var tm time.Time
+	switch v := t.(type) {
+	case time.Time:
+		tm = v
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		tm = *v
+	default:
+		return fmt.Sprint(t)
+	}
+	if tm.IsZero() {
+		return ""
+	}
+	if loc != nil {
+		tm = tm.In(loc)
+	}
+	return tm.Format(layout)
+}
+
+// dateIn formats t in a timezone given as a *time.Location or an IANA name
+func dateIn(zone interface{}, layout string, t interface{}) (string, error) {
+	switch z := zone.(type) {
+	case *time.Location:
+		return formatDate(z, layout, t), nil
+	case string:
+		loc, err := LoadLocation(z)
+		if err != nil {
+			return "", err
+		}
+		return formatDate(loc, layout, t), nil
+	}
+	return "", fmt.Errorf("dateIn: not a timezone: %T", zone)
+}
+
+// formatNumber formats a number with comma thousands separators and the given decimals
+func formatNumber(decimals int, n interface{}) (string, error) {
+	v := reflect.ValueOf(n)
+	var s string
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s = strconv.FormatInt(v.Int(), 10)
+		if decimals > 0 {
+			s += "." + strings.Repeat("0", decimals)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = strconv.FormatUint(v.Uint(), 10)
+		if decimals > 0 {
+			s += "." + strings.Repeat("0", decimals)
+		}
+	case reflect.Float32, reflect.Float64:
+		if math.IsNaN(v.Float()) || math.IsInf(v.Float(), 0) {
+			return fmt.Sprint(v.Float()), nil
+		}
+		s = strconv.FormatFloat(v.Float(), 'f', max(decimals, 0), 64)
+	default:
+		return "", fmt.Errorf("number: not a number: %T", n)
+	}
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, fraction, hasFraction := strings.Cut(s, ".")
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	if hasFraction {
+		b.WriteString("." + fraction)
+	}
+	return b.String(), nil
+}
+
+// truncate shortens s to at most n characters, including the trailing ellipsis
+func truncate(n int, s string) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	if n <= 0 {
+		return ""
+	}
+	return strings.TrimRight(string(runes[:n-1]), " ") + "…"
+}
+
+// truncateWords keeps the first n words of s
+func truncateWords(n int, s string) string {
+	words := strings.Fields(s)
+	if len(words) <= n {
+		return s
+	}
+	return strings.Join(words[:max(n, 0)], " ") + "…"
+}
+
+// pluralize picks a form for count. Without forms it returns "" or "s"; with one
+// form it returns "" or that suffix; with two it returns the singular or plural.
+func pluralize(count interface{}, forms ...string) (string, error) {
+	singular, plural := "", "s"
+	switch len(forms) {
+	case 0:
+	case 1:
+		plural = forms[0]
+	case 2:
+		singular, plural = forms[0], forms[1]
+	default:
+		return "", fmt.Errorf("pluralize: at most two forms, got %d", len(forms))
+	}
+
+	v := reflect.ValueOf(count)
+	one := false
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		one = v.Int() == 1
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		one = v.Uint() == 1
+	case reflect.Float32, reflect.Float64:
+		one = v.Float() == 1
+	case reflect.Slice, reflect.Array, reflect.Map:
+		one = v.Len() == 1
+	default:
+		return "", fmt.Errorf("pluralize: not a count: %T", count)
+	}
+	if one {
+		return singular, nil
+	}
+	return plural, nil
+}
+
+// dict builds a map from key/value pairs, e.g. for passing several values to a template
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict: odd number of arguments")
+	}
+	m := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: key %v is not a string", pairs[i])
+		}
+		m[key] = pairs[i+1]
+	}
+	return m, nil
+}
+
+// toJSON encodes v for use inside <script>; <, > and & are escaped by encoding/json
+func toJSON(v interface{}) (template.JS, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return template.JS(data), nil
+}
+
+// buildURL replaces the {name} segments of a route pattern with params, in order
+func buildURL(pattern string, params ...interface{}) (string, error) {
+	segments := strings.Split(pattern, "/")
+	next := 0
+	for i, segment := range segments {
+		if segment == "{$}" {
+			segments[i] = ""
+			continue
+		}
+		if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+			continue
+		}
+		if next >= len(params) {
+			return "", fmt.Errorf("url: missing value for %s in %s", segment, pattern)
+		}
+		value := fmt.Sprint(params[next])
+		if strings.HasSuffix(segment, "...}") {
+			parts := strings.Split(value, "/")
+			for j := range parts {
+				parts[j] = url.PathEscape(parts[j])
+			}
+			segments[i] = strings.Join(parts, "/")
+		} else {
+			segments[i] = url.PathEscape(value)
+		}
+		next++
+	}
+	if next < len(params) {
+		return "", fmt.Errorf("url: %d unused values for %s", len(params)-next, pattern)
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// csrfField renders the hidden input the CSRF middleware checks on form posts
+func csrfField(token string) template.HTML {
+	return template.HTML(`<input type="hidden" name="` + CSRFFieldName + `" value="` + template.HTMLEscapeString(token) + `">`)
+}
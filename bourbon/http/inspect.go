@@ -0,0 +1,43 @@
+package http
+
+import (
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// FuncName returns a function's name without its package's directory, e.g.
+// middleware.RequireAuth.func1 for a closure RequireAuth returns or
+// blog.(*PostController).Index for a method value, and "" for nil
+func FuncName(fn interface{}) string {
+	value := reflect.ValueOf(fn)
+	if fn == nil || value.Kind() != reflect.Func || value.IsNil() {
+		return ""
+	}
+	f := runtime.FuncForPC(value.Pointer())
+	if f == nil {
+		return ""
+	}
+	name := strings.TrimSuffix(f.Name(), "-fm")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// MiddlewareNames returns the names of the middleware added with Use, outermost first
+func (r *Router) MiddlewareNames() []string {
+	return append([]string{}, r.middlewareNames...)
+}
+
+// StaticPrefixes returns the URL prefixes served from static files, which take
+// precedence over routes
+func (r *Router) StaticPrefixes() []string {
+	prefixes := make([]string, 0, len(r.staticHandlers))
+	for prefix := range r.staticHandlers {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
@@ -0,0 +1,11 @@
+package http
+
+import (
+	"github.com/ishubhamsingh2e/bourbon/bourbon/markup"
+)
+
+// Markdown renders user-supplied markdown as a sanitized HTML response, e.g. for a
+// live preview of a comment form. The allowlist is markup.DefaultPolicy().
+func (c *Context) Markdown(status int, src string) error {
+	return c.HTML(status, string(markup.Render(src)))
+}
@@ -0,0 +1,167 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Renderer renders named templates for ctx.Render. *TemplateEngine is the default
+// implementation; other template languages plug in through RegisterRenderer.
+type Renderer interface {
+	Render(name string, data interface{}) (string, error)
+}
+
+// Optional renderer methods, used by TemplateNames, HasTemplate, AddRendererFunc
+// and SetRendererAutoReload
+type templateLister interface {
+	Names() []string
+}
+
+type templateChecker interface {
+	Has(name string) bool
+}
+
+type funcAdder interface {
+	AddFunc(name string, fn interface{})
+}
+
+type templateLoader interface {
+	Load() error
+}
+
+type autoReloader interface {
+	SetAutoReload(on bool)
+}
+
+// RendererConfig is passed to renderer constructors, from the [templates] settings
+type RendererConfig struct {
+	FS         fs.FS // read templates from here instead of Directory, e.g. an embed.FS
+	Directory  string
+	Extension  string
+	AutoReload bool
+	Funcs      template.FuncMap // framework functions such as static; engines without functions may ignore them
+}
+
+// RendererFunc creates a renderer
+type RendererFunc func(cfg RendererConfig) (Renderer, error)
+
+var (
+	rendererRegistry = make(map[string]RendererFunc)
+	rendererMutex    sync.RWMutex
+)
+
+func init() {
+	RegisterRenderer("html", newHTMLRenderer)
+}
+
+// RegisterRenderer makes a template engine selectable with [templates] engine = "<name>"
+func RegisterRenderer(name string, fn RendererFunc) {
+	rendererMutex.Lock()
+	defer rendererMutex.Unlock()
+	rendererRegistry[name] = fn
+}
+
+// GetRenderer returns the constructor registered as name
+func GetRenderer(name string) (RendererFunc, bool) {
+	rendererMutex.RLock()
+	defer rendererMutex.RUnlock()
+	fn, ok := rendererRegistry[name]
+	return fn, ok
+}
+
+// ListRenderers returns the registered engine names
+func ListRenderers() []string {
+	rendererMutex.RLock()
+	defer rendererMutex.RUnlock()
+	names := make([]string, 0, len(rendererRegistry))
+	for name := range rendererRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewRenderer creates the renderer registered as name; "" selects "html"
+func NewRenderer(name string, cfg RendererConfig) (Renderer, error) {
+	if name == "" {
+		name = "html"
+	}
+	fn, ok := GetRenderer(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown template engine %q (available: %v)", name, ListRenderers())
+	}
+	return fn(cfg)
+}
+
+// newHTMLRenderer creates the html/template engine
+func newHTMLRenderer(cfg RendererConfig) (Renderer, error) {
+	engine := NewTemplateEngine(cfg.Directory, cfg.Extension, cfg.AutoReload)
+	if cfg.FS != nil {
+		engine = NewTemplateEngineFS(cfg.FS, cfg.Extension)
+	}
+	engine.AddFuncs(cfg.Funcs)
+	if err := engine.Load(); err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
+
+// TemplateNames returns the templates r can render, sorted. It is empty for
+// renderers without a Names method.
+func TemplateNames(r Renderer) []string {
+	if lister, ok := r.(templateLister); ok {
+		return lister.Names()
+	}
+	return nil
+}
+
+// HasTemplate reports whether r has the named template. It is false for renderers
+// without a Has method.
+func HasTemplate(r Renderer, name string) bool {
+	checker, ok := r.(templateChecker)
+	return ok && checker.Has(name)
+}
+
+// AddRendererFunc adds a template function to r if it supports functions,
+// reloading the templates so they can use it. It reports whether r took the function.
+func AddRendererFunc(r Renderer, name string, fn interface{}) bool {
+	adder, ok := r.(funcAdder)
+	if !ok {
+		return false
+	}
+	adder.AddFunc(name, fn)
+	if loader, ok := r.(templateLoader); ok {
+		_ = loader.Load()
+	}
+	return true
+}
+
+// SetRendererAutoReload turns auto-reload on or off at runtime, e.g. after the
+// settings change. It reports whether r supports it.
+func SetRendererAutoReload(r Renderer, on bool) bool {
+	reloader, ok := r.(autoReloader)
+	if ok {
+		reloader.SetAutoReload(on)
+	}
+	return ok
+}
+
+// Component is a value that renders itself, such as a templ component
+type Component interface {
+	Render(ctx context.Context, w io.Writer) error
+}
+
+// RenderComponent writes a component as an HTML response
+func (c *Context) RenderComponent(status int, component Component) error {
+	var buf strings.Builder
+	if err := component.Render(c.Request.Context(), &buf); err != nil {
+		return err
+	}
+	return c.HTML(status, buf.String())
+}
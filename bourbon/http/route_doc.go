@@ -0,0 +1,69 @@
+package http
+
+// RouteDoc describes a route for generated API documentation such as OpenAPI
+type RouteDoc struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Request     interface{}         // sample request body, e.g. CreateUserInput{}
+	Responses   map[int]interface{} // sample response body per status code; nil for no body
+	Query       interface{}         // struct whose `query` tagged fields are query parameters
+	Deprecated  bool
+	Hidden      bool // leave the route out of generated documentation
+}
+
+// Summary sets a short description of the route:
+//
+//	router.Post("/users", create).
+//	    Summary("Create a user").
+//	    Request(CreateUserInput{}).
+//	    Response(201, User{})
+func (rt *Route) Summary(summary string) *Route {
+	rt.Doc.Summary = summary
+	return rt
+}
+
+// Description sets a longer description of the route
+func (rt *Route) Description(description string) *Route {
+	rt.Doc.Description = description
+	return rt
+}
+
+// Tags groups the route in generated documentation
+func (rt *Route) Tags(tags ...string) *Route {
+	rt.Doc.Tags = append(rt.Doc.Tags, tags...)
+	return rt
+}
+
+// Request documents the request body with a sample value of its type
+func (rt *Route) Request(body interface{}) *Route {
+	rt.Doc.Request = body
+	return rt
+}
+
+// Response documents the body returned with status; pass nil for an empty body
+func (rt *Route) Response(status int, body interface{}) *Route {
+	if rt.Doc.Responses == nil {
+		rt.Doc.Responses = make(map[int]interface{})
+	}
+	rt.Doc.Responses[status] = body
+	return rt
+}
+
+// QueryParams documents query parameters with a struct whose fields carry `query` tags
+func (rt *Route) QueryParams(params interface{}) *Route {
+	rt.Doc.Query = params
+	return rt
+}
+
+// Deprecated marks the route as deprecated
+func (rt *Route) Deprecated() *Route {
+	rt.Doc.Deprecated = true
+	return rt
+}
+
+// Hidden leaves the route out of generated documentation
+func (rt *Route) Hidden() *Route {
+	rt.Doc.Hidden = true
+	return rt
+}
@@ -2,33 +2,77 @@ package http
 
 import (
 	"fmt"
+	"io/fs"
+	"net"
 	"net/http"
 	"path"
 	"strings"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/cache"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
 )
 
 type HandlerFunc func(*Context) error
 
 type Router struct {
 	mux            *http.ServeMux
-	routes         []Route
+	routes         []*Route
 	middlewares    []MiddlewareFunc
-	TemplateEngine *TemplateEngine
+	TemplateEngine Renderer
+	processors     []ContextProcessor
+	Location       *time.Location // application timezone, see Context.Location
+	Cache          *cache.Cache
+	Logger         *logging.Logger // see Context.Logger
+	// TrustedProxies are the proxies whose X-Forwarded-For header Context.ClientIP
+	// trusts; without them the direct peer is the client
+	TrustedProxies []*net.IPNet
 	staticHandlers map[string]http.Handler
+
+	middlewareNames []string // of middlewares, for MiddlewareNames
+
+	// MiddlewareResolver looks up named middleware for Group.UseNamed.
+	// The application wires this to its middleware registry.
+	MiddlewareResolver func(name string) (func(http.Handler) http.Handler, bool)
 }
 
 type Route struct {
 	Method  string
 	Pattern string
 	Handler HandlerFunc
+	Doc     RouteDoc
+
+	// HandlerName is the handler's function, e.g. blog.(*PostController).Index
+	HandlerName string
+	// Group is the prefix of the group the route was registered in, if any
+	Group string
+	// Middleware names the group's middleware wrapping the handler, outermost first
+	Middleware []string
 }
 
 type MiddlewareFunc func(HandlerFunc) HandlerFunc
 
+// Adapt converts a standard http.Handler middleware into a route/group MiddlewareFunc,
+// so global-style middleware can be applied to individual groups
+func Adapt(mw func(http.Handler) http.Handler) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			var err error
+			handler := mw(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				c.Writer = w
+				c.Request = req
+				err = next(c)
+			}))
+			handler.ServeHTTP(c.Writer, c.Request)
+			return err
+		}
+	}
+}
+
 func NewRouter() *Router {
 	return &Router{
 		mux:            http.NewServeMux(),
-		routes:         make([]Route, 0),
+		routes:         make([]*Route, 0),
 		middlewares:    make([]MiddlewareFunc, 0),
 		TemplateEngine: nil,
 		staticHandlers: make(map[string]http.Handler),
@@ -37,40 +81,51 @@ func NewRouter() *Router {
 
 func (r *Router) Use(middleware ...MiddlewareFunc) {
 	r.middlewares = append(r.middlewares, middleware...)
+	for _, mw := range middleware {
+		r.middlewareNames = append(r.middlewareNames, FuncName(mw))
+	}
 }
 
-func (r *Router) Get(pattern string, handler HandlerFunc) {
-	r.addRoute("GET", pattern, handler)
+func (r *Router) Get(pattern string, handler HandlerFunc) *Route {
+	return r.addRoute("GET", pattern, handler)
 }
 
-func (r *Router) Post(pattern string, handler HandlerFunc) {
-	r.addRoute("POST", pattern, handler)
+func (r *Router) Post(pattern string, handler HandlerFunc) *Route {
+	return r.addRoute("POST", pattern, handler)
 }
 
-func (r *Router) Put(pattern string, handler HandlerFunc) {
-	r.addRoute("PUT", pattern, handler)
+func (r *Router) Put(pattern string, handler HandlerFunc) *Route {
+	return r.addRoute("PUT", pattern, handler)
 }
 
-func (r *Router) Patch(pattern string, handler HandlerFunc) {
-	r.addRoute("PATCH", pattern, handler)
+func (r *Router) Patch(pattern string, handler HandlerFunc) *Route {
+	return r.addRoute("PATCH", pattern, handler)
 }
 
-func (r *Router) Delete(pattern string, handler HandlerFunc) {
-	r.addRoute("DELETE", pattern, handler)
+func (r *Router) Delete(pattern string, handler HandlerFunc) *Route {
+	return r.addRoute("DELETE", pattern, handler)
 }
 
-func (r *Router) addRoute(method, pattern string, handler HandlerFunc) {
-	r.routes = append(r.routes, Route{
-		Method:  method,
-		Pattern: pattern,
-		Handler: handler,
-	})
+func (r *Router) Options(pattern string, handler HandlerFunc) *Route {
+	return r.addRoute("OPTIONS", pattern, handler)
+}
+
+func (r *Router) addRoute(method, pattern string, handler HandlerFunc) *Route {
+	route := &Route{
+		Method:      method,
+		Pattern:     pattern,
+		Handler:     handler,
+		HandlerName: FuncName(handler),
+	}
+	r.routes = append(r.routes, route)
 
 	key := fmt.Sprintf("%s %s", method, pattern)
-	r.mux.HandleFunc(key, r.wrapHandler(method, pattern, handler))
+	r.mux.HandleFunc(key, r.wrapHandler(route))
+	return route
 }
 
-func (r *Router) wrapHandler(method, pattern string, handler HandlerFunc) http.HandlerFunc {
+func (r *Router) wrapHandler(route *Route) http.HandlerFunc {
+	method, pattern, handler := route.Method, route.Pattern, route.Handler
 	return func(w http.ResponseWriter, req *http.Request) {
 		if req.Method != method {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -80,9 +135,17 @@ func (r *Router) wrapHandler(method, pattern string, handler HandlerFunc) http.H
 		ctx := &Context{
 			Writer:         w,
 			Request:        req,
-			Params:         extractParams(pattern, req.URL.Path),
+			Params:         extractParams(pattern, req),
 			store:          make(map[string]interface{}),
 			TemplateEngine: r.TemplateEngine,
+			processors:     r.processors,
+			location:       r.Location,
+			cache:          r.Cache,
+			logger:         r.Logger,
+			trustedProxies: r.TrustedProxies,
+		}
+		if debug, ok := DebugFromContext(req.Context()); ok {
+			defer debug.served(route, ctx)
 		}
 
 		finalHandler := handler
@@ -107,6 +170,17 @@ func (r *Router) Static(prefix, root string) {
 	r.staticHandlers[prefix] = handler
 }
 
+// StaticFS serves the files of fsys, such as an embed.FS, under prefix
+func (r *Router) StaticFS(prefix string, fsys fs.FS) {
+	handler := http.StripPrefix(prefix, http.FileServer(http.FS(fsys)))
+
+	if !strings.HasSuffix(prefix, "/") {
+		prefix = prefix + "/"
+	}
+
+	r.staticHandlers[prefix] = handler
+}
+
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	for prefix, handler := range r.staticHandlers {
 		if strings.HasPrefix(req.URL.Path, prefix) {
@@ -119,20 +193,28 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 }
 
 func (r *Router) GetRoutes() []Route {
-	return r.routes
+	routes := make([]Route, len(r.routes))
+	for i, route := range r.routes {
+		routes[i] = *route
+	}
+	return routes
 }
 
-func extractParams(pattern, path string) map[string]string {
+// extractParams returns the values of a route's path parameters. {name} and
+// {name...} come from the ServeMux match, so a wildcard gets the rest of the path.
+func extractParams(pattern string, req *http.Request) map[string]string {
 	params := make(map[string]string)
 
 	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
-	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+	pathParts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
 
 	for i, part := range patternParts {
-		if i >= len(pathParts) {
-			break
-		}
-		if strings.HasPrefix(part, ":") {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			name := strings.TrimSuffix(part[1:len(part)-1], "...")
+			if name != "$" {
+				params[name] = req.PathValue(name)
+			}
+		} else if strings.HasPrefix(part, ":") && i < len(pathParts) {
 			params[part[1:]] = pathParts[i]
 		}
 	}
@@ -144,14 +226,51 @@ type Group struct {
 	router      *Router
 	prefix      string
 	middlewares []MiddlewareFunc
+	names       []string // of middlewares, for Route.Middleware
 }
 
 func (r *Router) Group(prefix string, middleware ...MiddlewareFunc) *Group {
-	return &Group{
-		router:      r,
-		prefix:      prefix,
-		middlewares: middleware,
+	g := &Group{
+		router: r,
+		prefix: prefix,
 	}
+	g.Use(middleware...)
+	return g
+}
+
+// Use appends middleware to the group; it applies to routes registered afterwards
+func (g *Group) Use(middleware ...MiddlewareFunc) {
+	for _, mw := range middleware {
+		g.UseAs(FuncName(mw), mw)
+	}
+}
+
+// UseAs appends middleware under a name, which the group's routes list it as in
+// Route.Middleware; it applies to routes registered afterwards
+func (g *Group) UseAs(name string, middleware MiddlewareFunc) {
+	g.middlewares = append(g.middlewares, middleware)
+	g.names = append(g.names, name)
+}
+
+// UseNamed appends registered middleware to the group by name; it applies to routes registered afterwards
+func (g *Group) UseNamed(names ...string) error {
+	if g.router.MiddlewareResolver == nil {
+		return fmt.Errorf("no middleware resolver configured on router")
+	}
+
+	for _, name := range names {
+		mw, ok := g.router.MiddlewareResolver(name)
+		if !ok {
+			return fmt.Errorf("middleware '%s' not registered", name)
+		}
+		g.UseAs(name, Adapt(mw))
+	}
+	return nil
+}
+
+// Prefix returns the group's URL prefix
+func (g *Group) Prefix() string {
+	return g.prefix
 }
 
 // cleanPath ensures the path is clean and doesn't have double slashes
@@ -164,44 +283,60 @@ func cleanPath(prefix, pattern string) string {
 	return cleaned
 }
 
-func (g *Group) Get(pattern string, handler HandlerFunc) {
+// describe records the group and the unwrapped handler on a route it registered
+func (g *Group) describe(route *Route, handler HandlerFunc) *Route {
+	route.HandlerName = FuncName(handler)
+	route.Group = g.prefix
+	route.Middleware = append([]string(nil), g.names...)
+	return route
+}
+
+func (g *Group) Get(pattern string, handler HandlerFunc) *Route {
+	finalHandler := handler
+	for i := len(g.middlewares) - 1; i >= 0; i-- {
+		finalHandler = g.middlewares[i](finalHandler)
+	}
+	return g.describe(g.router.Get(cleanPath(g.prefix, pattern), finalHandler), handler)
+}
+
+func (g *Group) Post(pattern string, handler HandlerFunc) *Route {
 	finalHandler := handler
 	for i := len(g.middlewares) - 1; i >= 0; i-- {
 		finalHandler = g.middlewares[i](finalHandler)
 	}
-	g.router.Get(cleanPath(g.prefix, pattern), finalHandler)
+	return g.describe(g.router.Post(cleanPath(g.prefix, pattern), finalHandler), handler)
 }
 
-func (g *Group) Post(pattern string, handler HandlerFunc) {
+func (g *Group) Put(pattern string, handler HandlerFunc) *Route {
 	finalHandler := handler
 	for i := len(g.middlewares) - 1; i >= 0; i-- {
 		finalHandler = g.middlewares[i](finalHandler)
 	}
-	g.router.Post(cleanPath(g.prefix, pattern), finalHandler)
+	return g.describe(g.router.Put(cleanPath(g.prefix, pattern), finalHandler), handler)
 }
 
-func (g *Group) Put(pattern string, handler HandlerFunc) {
+func (g *Group) Patch(pattern string, handler HandlerFunc) *Route {
 	finalHandler := handler
 	for i := len(g.middlewares) - 1; i >= 0; i-- {
 		finalHandler = g.middlewares[i](finalHandler)
 	}
-	g.router.Put(cleanPath(g.prefix, pattern), finalHandler)
+	return g.describe(g.router.Patch(cleanPath(g.prefix, pattern), finalHandler), handler)
 }
 
-func (g *Group) Patch(pattern string, handler HandlerFunc) {
+func (g *Group) Delete(pattern string, handler HandlerFunc) *Route {
 	finalHandler := handler
 	for i := len(g.middlewares) - 1; i >= 0; i-- {
 		finalHandler = g.middlewares[i](finalHandler)
 	}
-	g.router.Patch(cleanPath(g.prefix, pattern), finalHandler)
+	return g.describe(g.router.Delete(cleanPath(g.prefix, pattern), finalHandler), handler)
 }
 
-func (g *Group) Delete(pattern string, handler HandlerFunc) {
+func (g *Group) Options(pattern string, handler HandlerFunc) *Route {
 	finalHandler := handler
 	for i := len(g.middlewares) - 1; i >= 0; i-- {
 		finalHandler = g.middlewares[i](finalHandler)
 	}
-	g.router.Delete(cleanPath(g.prefix, pattern), finalHandler)
+	return g.describe(g.router.Options(cleanPath(g.prefix, pattern), finalHandler), handler)
 }
 
 func (r *Router) Resource(path string, controller interface{}) {
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+// ManifestFile is the name of the manifest written by collectstatic
+const ManifestFile = "staticfiles.json"
+
+// StaticAssets builds public URLs for static files.
+// It prepends the asset host (e.g. a CDN) when configured and maps names to
+// their fingerprinted versions when a collectstatic manifest has been loaded.
+type StaticAssets struct {
+	host     string
+	prefix   string
+	manifest map[string]string
+	mu       sync.RWMutex
+}
+
+// NewStaticAssets creates a resolver for the given URL prefix and optional asset host
+func NewStaticAssets(prefix, host string) *StaticAssets {
+	return &StaticAssets{
+		host:     strings.TrimSuffix(host, "/"),
+		prefix:   "/" + strings.Trim(prefix, "/"),
+		manifest: make(map[string]string),
+	}
+}
+
+// LoadManifest loads a collectstatic manifest mapping original names to fingerprinted names
+func (s *StaticAssets) LoadManifest(manifestPath string) error {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	manifest := make(map[string]string)
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("failed to parse static manifest %s: %w", manifestPath, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.manifest = manifest
+	return nil
+}
+
+// URL returns the public URL for a static file, e.g. URL("css/style.css")
+func (s *StaticAssets) URL(name string) string {
+	name = strings.TrimPrefix(name, "/")
+
+	s.mu.RLock()
+	if hashed, ok := s.manifest[name]; ok {
+		name = hashed
+	}
+	s.mu.RUnlock()
+
+	return s.host + path.Join(s.prefix, name)
+}
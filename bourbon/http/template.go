@@ -5,17 +5,33 @@ import (
 	"html/template"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
 )
 
+// TemplateEngine renders html/template files. All templates are parsed once into a
+// set; with auto-reload on, the set is only reparsed after a template file changes.
 type TemplateEngine struct {
 	templates  *template.Template
 	directory  string
+	fsys       fs.FS
 	extension  string
 	autoReload bool
 	funcs      template.FuncMap
 	mu         sync.RWMutex
+
+	// Change detection for auto-reload: a file watcher marks the set stale, or, when
+	// watching is unavailable, modification times are compared before each render
+	stale    atomic.Bool
+	watcher  *fsnotify.Watcher
+	closed   bool
+	modTimes map[string]time.Time
 }
 
 func NewTemplateEngine(directory, extension string, autoReload bool) *TemplateEngine {
@@ -23,11 +39,27 @@ func NewTemplateEngine(directory, extension string, autoReload bool) *TemplateEn
 		directory:  directory,
 		extension:  extension,
 		autoReload: autoReload,
-		funcs:      template.FuncMap{},
+		funcs:      DefaultFuncs(),
 	}
 	return engine
 }
 
+// NewTemplateEngineFS creates an engine over a file system such as an embed.FS, so
+// production binaries ship their templates precompiled and never touch the disk:
+//
+//	//go:embed templates
+//	var templateFiles embed.FS
+//
+//	sub, _ := fs.Sub(templateFiles, "templates")
+//	engine := http.NewTemplateEngineFS(sub, ".html")
+func NewTemplateEngineFS(fsys fs.FS, extension string) *TemplateEngine {
+	return &TemplateEngine{
+		fsys:      fsys,
+		extension: extension,
+		funcs:     DefaultFuncs(),
+	}
+}
+
 func (e *TemplateEngine) AddFunc(name string, fn interface{}) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -42,17 +74,33 @@ func (e *TemplateEngine) AddFuncs(funcs template.FuncMap) {
 	}
 }
 
+// source returns the file system templates are read from
+func (e *TemplateEngine) source() fs.FS {
+	if e.fsys != nil {
+		return e.fsys
+	}
+	return os.DirFS(e.directory)
+}
+
+// Load parses every template into a new set. With auto-reload on, it also starts
+// watching the template directory for changes.
 func (e *TemplateEngine) Load() error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	if _, err := os.Stat(e.directory); os.IsNotExist(err) {
-		return fmt.Errorf("template directory does not exist: %s", e.directory)
+	if e.fsys == nil {
+		if _, err := os.Stat(e.directory); os.IsNotExist(err) {
+			return fmt.Errorf("template directory does not exist: %s", e.directory)
+		}
 	}
 
+	// Cleared first, so a change made while parsing triggers another reload
+	e.stale.Store(false)
+
 	tmpl := template.New("").Funcs(e.funcs)
+	modTimes := make(map[string]time.Time)
 
-	err := filepath.WalkDir(e.directory, func(path string, d fs.DirEntry, err error) error {
+	err := fs.WalkDir(e.source(), ".", func(name string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -61,19 +109,16 @@ func (e *TemplateEngine) Load() error {
 			return nil
 		}
 
-		if filepath.Ext(path) == e.extension {
-			content, err := os.ReadFile(path)
+		if path.Ext(name) == e.extension {
+			content, err := fs.ReadFile(e.source(), name)
 			if err != nil {
-				return fmt.Errorf("failed to read template %s: %w", path, err)
+				return fmt.Errorf("failed to read template %s: %w", name, err)
 			}
 
-			relPath, err := filepath.Rel(e.directory, path)
-			if err != nil {
-				return err
+			if info, err := d.Info(); err == nil {
+				modTimes[name] = info.ModTime()
 			}
 
-			name := filepath.ToSlash(relPath)
-
 			_, err = tmpl.New(name).Parse(string(content))
 			if err != nil {
 				return fmt.Errorf("failed to parse template %s: %w", name, err)
@@ -84,18 +129,180 @@ func (e *TemplateEngine) Load() error {
 	})
 
 	if err != nil {
+		// Retried on the next render, so the error shows until the template is fixed
+		e.stale.Store(true)
 		return err
 	}
 
 	e.templates = tmpl
+	e.modTimes = modTimes
+	if e.autoReload && e.fsys == nil && e.watcher == nil && !e.closed {
+		e.watch()
+	}
 	return nil
 }
 
-func (e *TemplateEngine) Render(name string, data interface{}) (string, error) {
-	if e.autoReload {
-		if err := e.Load(); err != nil {
-			return "", err
+// watch starts a file watcher on the template directory and its subdirectories.
+// When watching fails, e.g. because the OS limit on watches is reached, changes are
+// detected by comparing modification times instead.
+func (e *TemplateEngine) watch() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return
+	}
+	err = filepath.WalkDir(e.directory, func(dir string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		return watcher.Add(dir)
+	})
+	if err != nil {
+		watcher.Close()
+		return
+	}
+	e.watcher = watcher
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Has(fsnotify.Create) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						_ = watcher.Add(event.Name)
+					}
+				}
+				if event.Op != fsnotify.Chmod {
+					e.stale.Store(true)
+				}
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				// Events may have been dropped
+				e.stale.Store(true)
+			}
+		}
+	}()
+}
+
+// SetAutoReload turns auto-reload on or off. Turning it on marks the set stale, so
+// changes made while it was off are picked up by the next render.
+func (e *TemplateEngine) SetAutoReload(on bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.autoReload == on || e.fsys != nil {
+		return
+	}
+	e.autoReload = on
+	if on {
+		e.stale.Store(true)
+		if e.watcher == nil && !e.closed {
+			e.watch()
 		}
+	} else if e.watcher != nil {
+		e.watcher.Close()
+		e.watcher = nil
+	}
+}
+
+// Close stops watching the template directory. Auto-reload then falls back to
+// comparing modification times.
+func (e *TemplateEngine) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.closed = true
+	if e.watcher == nil {
+		return nil
+	}
+	err := e.watcher.Close()
+	e.watcher = nil
+	return err
+}
+
+// refresh loads the templates when they have not been loaded, or, with auto-reload
+// on, when a template file changed since the last load
+func (e *TemplateEngine) refresh() error {
+	e.mu.RLock()
+	loaded := e.templates != nil
+	changed := false
+	if loaded && e.autoReload {
+		if e.watcher != nil {
+			changed = e.stale.Load()
+		} else {
+			changed = e.modified()
+		}
+	}
+	e.mu.RUnlock()
+
+	if loaded && !changed {
+		return nil
+	}
+	if !loaded && !e.autoReload {
+		return fmt.Errorf("templates not loaded, call Load() first")
+	}
+	return e.Load()
+}
+
+// modified compares the template files with the modification times of the last
+// load; the caller holds e.mu
+func (e *TemplateEngine) modified() bool {
+	seen := 0
+	changed := false
+	err := fs.WalkDir(e.source(), ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(name) != e.extension {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		seen++
+		if modTime, ok := e.modTimes[name]; !ok || !modTime.Equal(info.ModTime()) {
+			changed = true
+			return fs.SkipAll
+		}
+		return nil
+	})
+	return err != nil || changed || seen != len(e.modTimes)
+}
+
+// Has reports whether a template with the given name is loaded
+func (e *TemplateEngine) Has(name string) bool {
+	if err := e.refresh(); err != nil {
+		return false
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.templates != nil && e.templates.Lookup(name) != nil
+}
+
+// Names returns the names of the loaded templates, files and the templates
+// they define, sorted
+func (e *TemplateEngine) Names() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.templates == nil {
+		return nil
+	}
+	var names []string
+	for _, tmpl := range e.templates.Templates() {
+		if tmpl.Name() != "" {
+			names = append(names, tmpl.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (e *TemplateEngine) Render(name string, data interface{}) (string, error) {
+	if err := e.refresh(); err != nil {
+		return "", err
 	}
 
 	e.mu.RLock()
@@ -0,0 +1,50 @@
+package http
+
+// ContextProcessor returns data merged into every template rendered with ctx.Render,
+// like a Django context processor
+type ContextProcessor func(c *Context) H
+
+// TemplateContext adds a context processor. Processors run in the order they were
+// added; later ones override earlier keys, and the data passed to Render overrides both.
+//
+//	router.TemplateContext(func(c *http.Context) http.H {
+//	    return http.H{"year": time.Now().Year()}
+//	})
+func (r *Router) TemplateContext(fn ContextProcessor) {
+	r.processors = append(r.processors, fn)
+}
+
+// templateData merges the CSRF token and the context processors' data into map data.
+// Data of other types, such as structs, is passed through unchanged. The caller's map
+// is copied, not modified.
+func (c *Context) templateData(data interface{}) interface{} {
+	token := c.CSRFToken()
+	if token == "" && len(c.processors) == 0 {
+		return data
+	}
+
+	var m map[string]interface{}
+	switch v := data.(type) {
+	case nil:
+	case H:
+		m = v
+	case map[string]interface{}:
+		m = v
+	default:
+		return data
+	}
+
+	out := make(H, len(m)+1)
+	if token != "" {
+		out[CSRFFieldName] = token
+	}
+	for _, processor := range c.processors {
+		for k, v := range processor(c) {
+			out[k] = v
+		}
+	}
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// locationKey stores the request's timezone, e.g. the signed-in user's
+const locationKey contextKey = "bourbon.http.location"
+
+var locations sync.Map // IANA name -> *time.Location
+
+// LoadLocation is time.LoadLocation with caching, for resolving timezones per request
+func LoadLocation(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// WithLocation returns a copy of ctx carrying the request's timezone
+func WithLocation(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, locationKey, loc)
+}
+
+// LocationFromContext returns the timezone set with WithLocation, if any
+func LocationFromContext(ctx context.Context) (*time.Location, bool) {
+	loc, ok := ctx.Value(locationKey).(*time.Location)
+	return loc, ok && loc != nil
+}
+
+// Location returns the request's timezone: the one set by the Timezone middleware,
+// else the application timezone, else UTC
+func (c *Context) Location() *time.Location {
+	if loc, ok := LocationFromContext(c.Request.Context()); ok {
+		return loc
+	}
+	if c.location != nil {
+		return c.location
+	}
+	return time.UTC
+}
+
+// Localize converts t to the request's timezone for display
+func (c *Context) Localize(t time.Time) time.Time {
+	return t.In(c.Location())
+}
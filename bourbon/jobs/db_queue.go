@@ -0,0 +1,391 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// DefaultQueue is the queue jobs are pushed to unless OnQueue names another
+const DefaultQueue = "default"
+
+// Handler runs a job pushed to a DBQueue, with the JSON its payload was encoded to
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+// StoredJob is a job waiting in, or failed out of, the bourbon_jobs table
+type StoredJob struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	Queue       string    `gorm:"size:100;index:idx_bourbon_jobs_pending,priority:1" json:"queue"`
+	Name        string    `gorm:"size:200" json:"name"`
+	Payload     string    `gorm:"type:text" json:"payload"`
+	Attempts    int       `json:"attempts"`
+	MaxAttempts int       `json:"max_attempts"`
+	AvailableAt time.Time `gorm:"index:idx_bourbon_jobs_pending,priority:2" json:"available_at"`
+	// ReservedAt is when a worker took the job; it is run again once reserved
+	// for longer than the queue's timeout
+	ReservedAt *time.Time `json:"reserved_at,omitempty"`
+	// Token identifies the reservation, so a worker whose job was taken over
+	// doesn't finish it
+	Token     string     `gorm:"size:32" json:"-"`
+	FailedAt  *time.Time `gorm:"index" json:"failed_at,omitempty"`
+	LastError string     `gorm:"type:text" json:"last_error,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (StoredJob) TableName() string {
+	return "bourbon_jobs"
+}
+
+// DBQueueConfig configures a DBQueue
+type DBQueueConfig struct {
+	// MaxAttempts is how many times a failing job runs before it is kept as
+	// failed (default 3)
+	MaxAttempts int
+	// Timeout bounds a run; a job reserved for longer, e.g. by a worker that
+	// died, is run again (default 10m)
+	Timeout time.Duration
+	// PollInterval is how long an idle worker waits before looking for jobs (default 1s)
+	PollInterval time.Duration
+	// Conn returns the connection to push with, e.g. orm.Conn to push within the
+	// request's transaction; db.WithContext(ctx) is used when nil
+	Conn func(ctx context.Context, db *gorm.DB) *gorm.DB
+}
+
+// DBQueue keeps jobs in the bourbon_jobs table, so they survive restarts and
+// run in worker processes started with Work, apart from the web servers that
+// push them. Jobs are pushed by name with a payload, and run by the Handler
+// registered under that name in the worker.
+type DBQueue struct {
+	db       *gorm.DB
+	cfg      DBQueueConfig
+	logger   *logging.Logger
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	migrated bool
+}
+
+// NewDBQueue creates a queue over db, which may be set later with SetDB.
+// Failed and panicking jobs are logged to l when it is not nil.
+func NewDBQueue(db *gorm.DB, cfg DBQueueConfig, l *logging.Logger) *DBQueue {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Minute
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	q := &DBQueue{db: db, cfg: cfg, handlers: make(map[string]Handler)}
+	if l != nil {
+		q.logger = l.Module(logging.ModuleJobs)
+	}
+	return q
+}
+
+// SetDB attaches the database once it is connected
+func (q *DBQueue) SetDB(db *gorm.DB) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.db = db
+	q.migrated = false
+}
+
+// Migrate creates the bourbon_jobs table. Push and Work call it when needed.
+func (q *DBQueue) Migrate() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.migrate()
+}
+
+func (q *DBQueue) migrate() error {
+	if q.db == nil {
+		return fmt.Errorf("job queue: database not connected")
+	}
+	if q.migrated {
+		return nil
+	}
+	if err := q.db.AutoMigrate(&StoredJob{}); err != nil {
+		return fmt.Errorf("job queue: %w", err)
+	}
+	q.migrated = true
+	return nil
+}
+
+// database returns the connected database, creating the table on first use
+func (q *DBQueue) database() (*gorm.DB, error) {
+	q.mu.RLock()
+	db, migrated := q.db, q.migrated
+	q.mu.RUnlock()
+	if migrated {
+		return db, nil
+	}
+	if err := q.Migrate(); err != nil {
+		return nil, err
+	}
+	return q.database()
+}
+
+// Register sets the handler of the jobs pushed under name. Register every
+// handler in the web and worker processes alike, e.g. from the app's init.
+func (q *DBQueue) Register(name string, handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[name] = handler
+}
+
+// Decode adapts a handler taking its payload decoded from JSON into T:
+//
+//	app.Queue.Register("welcome_email", jobs.Decode(func(ctx context.Context, p WelcomeEmail) error {
+//	    return sendWelcome(ctx, p.UserID)
+//	}))
+func Decode[T any](handler func(ctx context.Context, payload T) error) Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var value T
+		if err := json.Unmarshal(payload, &value); err != nil {
+			return fmt.Errorf("decoding payload: %w", err)
+		}
+		return handler(ctx, value)
+	}
+}
+
+func (q *DBQueue) handler(name string) (Handler, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	handler, ok := q.handlers[name]
+	return handler, ok
+}
+
+// PushOption changes how a job is pushed
+type PushOption func(*StoredJob)
+
+// OnQueue pushes the job to a named queue, for workers started with --queue
+func OnQueue(queue string) PushOption {
+	return func(job *StoredJob) { job.Queue = queue }
+}
+
+// Delay makes the job wait d before it runs
+func Delay(d time.Duration) PushOption {
+	return func(job *StoredJob) { job.AvailableAt = job.AvailableAt.Add(d) }
+}
+
+// Attempts overrides the queue's MaxAttempts for the job
+func Attempts(n int) PushOption {
+	return func(job *StoredJob) { job.MaxAttempts = n }
+}
+
+// Push stores a job for the handler registered under name, with payload encoded
+// as JSON. It returns once the job is stored; a worker runs it.
+func (q *DBQueue) Push(ctx context.Context, name string, payload interface{}, opts ...PushOption) error {
+	db, err := q.database()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("job %s: encoding payload: %w", name, err)
+	}
+
+	job := &StoredJob{
+		Queue:       DefaultQueue,
+		Name:        name,
+		Payload:     string(data),
+		MaxAttempts: q.cfg.MaxAttempts,
+		AvailableAt: time.Now().UTC(),
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = 1
+	}
+
+	conn := db.WithContext(ctx)
+	if q.cfg.Conn != nil {
+		conn = q.cfg.Conn(ctx, db)
+	}
+	if err := conn.Create(job).Error; err != nil {
+		return fmt.Errorf("job %s: %w", name, err)
+	}
+	return nil
+}
+
+// WorkOptions configures Work
+type WorkOptions struct {
+	// Queues are the queues to take jobs from, in no particular order
+	// (default: DefaultQueue)
+	Queues []string
+	// Concurrency is how many jobs run at once (default 1)
+	Concurrency int
+}
+
+// Work runs the jobs of the given queues until ctx is done, then waits for
+// the running ones to finish. A job that fails or panics runs again after a
+// backoff of 10s, 40s, 90s... until it has run MaxAttempts times; it is then
+// kept with FailedAt set.
+func (q *DBQueue) Work(ctx context.Context, opts WorkOptions) error {
+	if len(opts.Queues) == 0 {
+		opts.Queues = []string{DefaultQueue}
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if _, err := q.database(); err != nil {
+		return err
+	}
+
+	slots := make(chan struct{}, opts.Concurrency)
+	var running sync.WaitGroup
+	defer running.Wait()
+
+	for {
+		free := cap(slots) - len(slots)
+		claimed := 0
+		if free > 0 {
+			jobs, err := q.reserve(ctx, opts.Queues, free)
+			if err != nil && ctx.Err() == nil && q.logger != nil {
+				q.logger.Error("Failed to reserve jobs", zap.Error(err))
+			}
+			for _, job := range jobs {
+				slots <- struct{}{}
+				running.Add(1)
+				go func(job StoredJob) {
+					defer func() {
+						<-slots
+						running.Done()
+					}()
+					q.run(job)
+				}(job)
+			}
+			claimed = len(jobs)
+		}
+
+		// Look again at once while jobs keep coming and slots are free
+		wait := q.cfg.PollInterval
+		if claimed > 0 && claimed == free {
+			wait = 10 * time.Millisecond
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
+
+// reserve takes up to n available jobs. A job is taken by updating its row
+// only while it is still free, so concurrent workers never run the same job.
+func (q *DBQueue) reserve(ctx context.Context, queues []string, n int) ([]StoredJob, error) {
+	db, err := q.database()
+	if err != nil {
+		return nil, err
+	}
+	db = db.WithContext(ctx)
+	now := time.Now().UTC()
+	stale := now.Add(-q.cfg.Timeout)
+
+	var candidates []StoredJob
+	err = db.Where("queue IN ? AND failed_at IS NULL AND available_at <= ?", queues, now).
+		Where("reserved_at IS NULL OR reserved_at < ?", stale).
+		Order("available_at, id").Limit(n).Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var reserved []StoredJob
+	for _, job := range candidates {
+		token := newToken()
+		result := db.Model(&StoredJob{}).
+			Where("id = ? AND failed_at IS NULL", job.ID).
+			Where("reserved_at IS NULL OR reserved_at < ?", stale).
+			Updates(map[string]interface{}{
+				"reserved_at": now,
+				"token":       token,
+				"attempts":    gorm.Expr("attempts + 1"),
+			})
+		if result.Error != nil {
+			return reserved, result.Error
+		}
+		if result.RowsAffected == 1 {
+			job.ReservedAt, job.Token = &now, token
+			job.Attempts++
+			reserved = append(reserved, job)
+		}
+	}
+	return reserved, nil
+}
+
+// run runs a reserved job and records how it went
+func (q *DBQueue) run(job StoredJob) {
+	// The job finishes even when the worker is stopping
+	ctx, cancel := context.WithTimeout(context.Background(), q.cfg.Timeout)
+	defer cancel()
+
+	err := q.call(ctx, job)
+	db, dbErr := q.database()
+	if dbErr != nil {
+		return
+	}
+	if err == nil {
+		if result := db.Where("id = ? AND token = ?", job.ID, job.Token).Delete(&StoredJob{}); result.Error != nil {
+			q.logError("Failed to delete finished job", job, result.Error)
+		}
+		return
+	}
+
+	updates := map[string]interface{}{"reserved_at": nil, "token": "", "last_error": err.Error()}
+	if job.Attempts >= job.MaxAttempts {
+		updates["failed_at"] = time.Now().UTC()
+		q.logError("Job failed", job, err)
+	} else {
+		backoff := time.Duration(job.Attempts*job.Attempts) * 10 * time.Second
+		updates["available_at"] = time.Now().UTC().Add(backoff)
+		q.logError("Job failed, retrying", job, err, zap.Duration("retry_in", backoff))
+	}
+	result := db.Model(&StoredJob{}).Where("id = ? AND token = ?", job.ID, job.Token).Updates(updates)
+	if result.Error != nil {
+		q.logError("Failed to record job failure", job, result.Error)
+	}
+}
+
+// call runs the job's handler, turning a panic into an error
+func (q *DBQueue) call(ctx context.Context, job StoredJob) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	handler, ok := q.handler(job.Name)
+	if !ok {
+		return fmt.Errorf("no handler registered for job %q", job.Name)
+	}
+	return handler(ctx, json.RawMessage(job.Payload))
+}
+
+func (q *DBQueue) logError(msg string, job StoredJob, err error, fields ...zap.Field) {
+	if q.logger == nil {
+		return
+	}
+	q.logger.Error(msg, append([]zap.Field{
+		zap.String("job", job.Name),
+		zap.Uint("id", job.ID),
+		zap.String("queue", job.Queue),
+		zap.Int("attempt", job.Attempts),
+		zap.Error(err),
+	}, fields...)...)
+}
+
+// newToken returns a random reservation token
+func newToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,141 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB opens a connection to a database file shared by the test's queues,
+// like the connections of separate worker processes
+func openTestDB(t *testing.T, path string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(path+"?_busy_timeout=5000&_journal_mode=WAL"), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func TestDBQueueReserveClaimsOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.db")
+	ctx := context.Background()
+
+	pusher := NewDBQueue(openTestDB(t, path), DBQueueConfig{}, nil)
+	const jobs = 40
+	for i := 0; i < jobs; i++ {
+		if err := pusher.Push(ctx, "send", map[string]int{"n": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		claimed = make(map[uint]int)
+		wg      sync.WaitGroup
+	)
+	for w := 0; w < 4; w++ {
+		q := NewDBQueue(openTestDB(t, path), DBQueueConfig{}, nil)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				reserved, err := q.reserve(ctx, []string{DefaultQueue}, 5)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if len(reserved) == 0 {
+					return
+				}
+				mu.Lock()
+				for _, job := range reserved {
+					claimed[job.ID]++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(claimed) != jobs {
+		t.Errorf("%d jobs reserved, want %d", len(claimed), jobs)
+	}
+	for id, n := range claimed {
+		if n != 1 {
+			t.Errorf("job %d reserved %d times", id, n)
+		}
+	}
+}
+
+func TestDBQueueStaleReservation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.db")
+	ctx := context.Background()
+	db := openTestDB(t, path)
+
+	var runs []string
+	handler := func(worker string) Handler {
+		return func(ctx context.Context, payload json.RawMessage) error {
+			runs = append(runs, worker)
+			return nil
+		}
+	}
+	first := NewDBQueue(db, DBQueueConfig{Timeout: time.Minute}, nil)
+	first.Register("send", handler("first"))
+	second := NewDBQueue(openTestDB(t, path), DBQueueConfig{Timeout: time.Minute}, nil)
+	second.Register("send", handler("second"))
+
+	if err := first.Push(ctx, "send", nil); err != nil {
+		t.Fatal(err)
+	}
+	stalled, err := first.reserve(ctx, []string{DefaultQueue}, 1)
+	if err != nil || len(stalled) != 1 {
+		t.Fatalf("reserve() = %v, %v", stalled, err)
+	}
+
+	// A job reserved within the timeout is not taken again
+	if again, _ := second.reserve(ctx, []string{DefaultQueue}, 1); len(again) != 0 {
+		t.Fatalf("a reserved job was taken again")
+	}
+
+	// Once the reservation is stale, another worker takes the job over
+	db.Model(&StoredJob{}).Where("id = ?", stalled[0].ID).Update("reserved_at", time.Now().UTC().Add(-2*time.Minute))
+	takeover, err := second.reserve(ctx, []string{DefaultQueue}, 1)
+	if err != nil || len(takeover) != 1 {
+		t.Fatalf("reserve() of a stale job = %v, %v", takeover, err)
+	}
+	if takeover[0].Token == stalled[0].Token {
+		t.Fatal("the takeover reused the stalled reservation's token")
+	}
+	if takeover[0].Attempts != 2 {
+		t.Errorf("attempts = %d, want 2", takeover[0].Attempts)
+	}
+
+	// The stalled worker finishing late must not remove the job it no longer owns
+	first.run(stalled[0])
+	var count int64
+	db.Model(&StoredJob{}).Count(&count)
+	if count != 1 {
+		t.Fatalf("%d jobs left after the stale run, want 1", count)
+	}
+
+	second.run(takeover[0])
+	db.Model(&StoredJob{}).Count(&count)
+	if count != 0 {
+		t.Errorf("%d jobs left after the current run, want 0", count)
+	}
+	if len(runs) != 2 || runs[0] != "first" || runs[1] != "second" {
+		t.Errorf("runs = %v", runs)
+	}
+}
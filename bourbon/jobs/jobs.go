@@ -0,0 +1,97 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+	"go.uber.org/zap"
+)
+
+// Func is a background job
+type Func func(ctx context.Context) error
+
+// Queue runs jobs off the request path. The name identifies the job in logs.
+type Queue interface {
+	Enqueue(ctx context.Context, name string, job Func) error
+}
+
+// ErrQueueClosed is returned when enqueueing on a closed LocalQueue
+var ErrQueueClosed = errors.New("job queue closed")
+
+type localJob struct {
+	ctx  context.Context
+	name string
+	fn   Func
+}
+
+// LocalQueue runs jobs in a pool of goroutines. Jobs are lost if the process
+// exits first; call Close on shutdown to finish the pending ones.
+type LocalQueue struct {
+	jobs   chan localJob
+	logger *logging.Logger
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewLocalQueue starts a queue with the given number of workers.
+// Failed and panicking jobs are logged to l when it is not nil.
+func NewLocalQueue(workers int, l *logging.Logger) *LocalQueue {
+	if workers <= 0 {
+		workers = 1
+	}
+	q := &LocalQueue{jobs: make(chan localJob, 256)}
+	if l != nil {
+		q.logger = l.Module(logging.ModuleJobs)
+	}
+	q.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+// Enqueue schedules job; it blocks while the buffer is full. The job's context
+// keeps ctx's values but is not cancelled with it.
+func (q *LocalQueue) Enqueue(ctx context.Context, name string, job Func) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	q.jobs <- localJob{ctx: context.WithoutCancel(ctx), name: name, fn: job}
+	return nil
+}
+
+// Close stops accepting jobs and waits for the queued ones to finish
+func (q *LocalQueue) Close() {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+	q.closed = true
+	close(q.jobs)
+	q.mu.Unlock()
+	q.wg.Wait()
+}
+
+func (q *LocalQueue) work() {
+	defer q.wg.Done()
+	for job := range q.jobs {
+		q.run(job)
+	}
+}
+
+func (q *LocalQueue) run(job localJob) {
+	defer func() {
+		if r := recover(); r != nil && q.logger != nil {
+			q.logger.Error("Job panicked", zap.String("job", job.name), zap.Any("panic", r))
+		}
+	}()
+	if err := job.fn(job.ctx); err != nil && q.logger != nil {
+		q.logger.Error("Job failed", zap.String("job", job.name), zap.Error(err))
+	}
+}
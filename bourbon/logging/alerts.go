@@ -0,0 +1,158 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// alertRecentLimit is how many recent errors are kept for alert summaries
+const alertRecentLimit = 10
+
+// ErrorAlert summarizes a spike of server errors
+type ErrorAlert struct {
+	AppName   string
+	Count     int
+	Threshold int
+	Window    time.Duration
+	FiredAt   time.Time
+	Recent    []ErrorLog
+}
+
+// Subject returns a one-line summary of the alert
+func (a *ErrorAlert) Subject() string {
+	name := a.AppName
+	if name == "" {
+		name = "Bourbon"
+	}
+	return fmt.Sprintf("[%s] %d server errors in the last %s", name, a.Count, a.Window)
+}
+
+// Summary returns a plain-text description including the recent errors
+func (a *ErrorAlert) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (threshold %d)\n\nRecent errors:\n", a.Subject(), a.Threshold)
+	for _, e := range a.Recent {
+		fmt.Fprintf(&b, "- %s %d %s %s: %s\n", e.Timestamp.Format(time.RFC3339), e.Status, e.Method, e.Path, e.Message)
+	}
+	return b.String()
+}
+
+// AlertNotifier delivers error alerts. Alerts are emailed by mail.AlertNotifier,
+// through the application's mailer.
+type AlertNotifier interface {
+	Notify(alert *ErrorAlert) error
+}
+
+// AlertConfig configures threshold-based error alerting
+type AlertConfig struct {
+	AppName   string
+	Threshold int           // alert when this many 5xx errors happen within Window
+	Window    time.Duration // sliding window (default 1 minute)
+	Cooldown  time.Duration // minimum time between alerts (default 10 minutes)
+	Notifiers []AlertNotifier
+	OnError   func(error) // called when a notifier fails
+}
+
+// errorAlerter tracks 5xx timestamps in a sliding window and fires notifiers
+type errorAlerter struct {
+	mu        sync.Mutex
+	config    AlertConfig
+	hits      []time.Time
+	recent    []ErrorLog
+	lastFired time.Time
+}
+
+func newErrorAlerter(config AlertConfig) *errorAlerter {
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = 10 * time.Minute
+	}
+	if config.Threshold <= 0 {
+		config.Threshold = 10
+	}
+	return &errorAlerter{config: config}
+}
+
+// record counts a server error and sends an alert asynchronously when the threshold is crossed
+func (a *errorAlerter) record(log ErrorLog) {
+	if log.Status < 500 {
+		return
+	}
+
+	a.mu.Lock()
+	now := time.Now()
+	cutoff := now.Add(-a.config.Window)
+	kept := a.hits[:0]
+	for _, t := range a.hits {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	a.hits = append(kept, now)
+
+	a.recent = append(a.recent, log)
+	if len(a.recent) > alertRecentLimit {
+		a.recent = a.recent[len(a.recent)-alertRecentLimit:]
+	}
+
+	if len(a.hits) < a.config.Threshold || now.Sub(a.lastFired) < a.config.Cooldown {
+		a.mu.Unlock()
+		return
+	}
+	a.lastFired = now
+
+	alert := &ErrorAlert{
+		AppName:   a.config.AppName,
+		Count:     len(a.hits),
+		Threshold: a.config.Threshold,
+		Window:    a.config.Window,
+		FiredAt:   now,
+		Recent:    append([]ErrorLog(nil), a.recent...),
+	}
+	a.mu.Unlock()
+
+	go a.notify(alert)
+}
+
+func (a *errorAlerter) notify(alert *ErrorAlert) {
+	for _, n := range a.config.Notifiers {
+		if err := n.Notify(alert); err != nil && a.config.OnError != nil {
+			a.config.OnError(err)
+		}
+	}
+}
+
+// SlackNotifier posts alerts to a Slack incoming webhook
+type SlackNotifier struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+// NewSlackNotifier creates a notifier for the given webhook URL
+func NewSlackNotifier(webhookURL string) *SlackNotifier {
+	return &SlackNotifier{WebhookURL: webhookURL, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Notify posts the alert summary to the webhook
+func (n *SlackNotifier) Notify(alert *ErrorAlert) error {
+	payload, err := json.Marshal(map[string]string{"text": "```" + alert.Summary() + "```"})
+	if err != nil {
+		return err
+	}
+	resp, err := n.Client.Post(n.WebhookURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("slack alert failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("slack alert failed: status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package logging
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying l, for the code serving a request
+// to log through with FromContext
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger set with NewContext, if any
+func FromContext(ctx context.Context) (*Logger, bool) {
+	l, ok := ctx.Value(contextKey{}).(*Logger)
+	return l, ok && l != nil
+}
+
+// Nop returns a logger that discards everything
+func Nop() *Logger {
+	nop := zap.NewNop()
+	return &Logger{
+		Logger: nop,
+		config: DefaultConfig(),
+		sugar:  nop.Sugar(),
+		levels: newLevelState(zapcore.InfoLevel),
+	}
+}
+
+// Entry is a message passed to the function of Capture
+type Entry struct {
+	Time    time.Time
+	Level   string
+	Logger  string
+	Message string
+	Fields  map[string]interface{}
+}
+
+// Capture returns a logger that also passes every message to capture, whatever
+// the configured levels, e.g. to show a request's messages in the debug toolbar.
+// Capture may be called from several goroutines at once.
+func (l *Logger) Capture(capture func(Entry)) *Logger {
+	captured := l.Logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, &captureCore{capture: capture})
+	}))
+	return &Logger{
+		Logger: captured,
+		config: l.config,
+		sugar:  captured.Sugar(),
+		levels: l.levels,
+	}
+}
+
+// captureCore passes entries to a function, with the fields of the logger and the entry
+type captureCore struct {
+	capture func(Entry)
+	fields  []zapcore.Field
+}
+
+func (c *captureCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c *captureCore) With(fields []zapcore.Field) zapcore.Core {
+	return &captureCore{capture: c.capture, fields: append(append([]zapcore.Field(nil), c.fields...), fields...)}
+}
+
+func (c *captureCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checked.AddCore(entry, c)
+}
+
+func (c *captureCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	encoder := zapcore.NewMapObjectEncoder()
+	for _, field := range c.fields {
+		field.AddTo(encoder)
+	}
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+	c.capture(Entry{
+		Time:    entry.Time,
+		Level:   entry.Level.CapitalString(),
+		Logger:  entry.LoggerName,
+		Message: entry.Message,
+		Fields:  encoder.Fields,
+	})
+	return nil
+}
+
+func (c *captureCore) Sync() error {
+	return nil
+}
@@ -26,6 +26,7 @@ type ErrorLog struct {
 type ErrorStore struct {
 	db      *gorm.DB
 	enabled bool
+	alerter *errorAlerter
 }
 
 // NewErrorStore creates a new error store
@@ -36,16 +37,36 @@ func NewErrorStore(db *gorm.DB, enabled bool) *ErrorStore {
 	}
 }
 
+// SetDB attaches the database once it is connected
+func (s *ErrorStore) SetDB(db *gorm.DB) {
+	s.db = db
+}
+
+// Enabled reports whether errors are persisted to the database
+func (s *ErrorStore) Enabled() bool {
+	return s.enabled && s.db != nil
+}
+
+// EnableAlerts turns on threshold-based alerting for 5xx errors passed to Store.
+// Alerting works even when database storage is disabled.
+func (s *ErrorStore) EnableAlerts(config AlertConfig) {
+	s.alerter = newErrorAlerter(config)
+}
+
 // Store saves an error log to database
 func (s *ErrorStore) Store(log *ErrorLog) error {
-	if !s.enabled || s.db == nil {
-		return nil
-	}
-
 	if log.Timestamp.IsZero() {
 		log.Timestamp = time.Now()
 	}
 
+	if s.alerter != nil {
+		s.alerter.record(*log)
+	}
+
+	if !s.enabled || s.db == nil {
+		return nil
+	}
+
 	return s.db.Create(log).Error
 }
 
@@ -99,3 +120,59 @@ func (s *ErrorStore) GetServerErrors(limit int) ([]ErrorLog, error) {
 	err := s.db.Where("status >= ? AND status < ?", 500, 600).Order("timestamp DESC").Limit(limit).Find(&logs).Error
 	return logs, err
 }
+
+// ErrorFilter narrows an ErrorStore query. Zero values are ignored.
+type ErrorFilter struct {
+	Status int
+	Path   string // substring match
+	From   time.Time
+	To     time.Time
+	Limit  int
+	Offset int
+}
+
+// Query returns error logs matching the filter, newest first, and the total number of matches
+func (s *ErrorStore) Query(filter ErrorFilter) ([]ErrorLog, int64, error) {
+	if !s.enabled || s.db == nil {
+		return nil, 0, nil
+	}
+
+	query := s.db.Model(&ErrorLog{})
+	if filter.Status != 0 {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Path != "" {
+		query = query.Where("path LIKE ?", "%"+filter.Path+"%")
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("timestamp >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("timestamp < ?", filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+	var logs []ErrorLog
+	err := query.Order("timestamp DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&logs).Error
+	return logs, total, err
+}
+
+// Find retrieves a single error log by ID
+func (s *ErrorStore) Find(id uint) (*ErrorLog, error) {
+	if !s.enabled || s.db == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var log ErrorLog
+	if err := s.db.First(&log, id).Error; err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
@@ -0,0 +1,164 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Well-known module names used by the framework's own loggers
+const (
+	ModuleHTTP     = "http"
+	ModuleDatabase = "database"
+	ModuleJobs     = "jobs"
+	ModuleMail     = "mail"
+	ModuleNotify   = "notifications"
+	ModuleEvents   = "events"
+	ModuleStream   = "stream"
+)
+
+// levelState holds the global and per-module levels shared by a logger and its module loggers.
+// Levels can be changed at runtime.
+type levelState struct {
+	mu         sync.RWMutex
+	global     zap.AtomicLevel
+	configured zapcore.Level
+	modules    map[string]zapcore.Level
+}
+
+func newLevelState(global zapcore.Level) *levelState {
+	return &levelState{
+		global:     zap.NewAtomicLevelAt(global),
+		configured: global,
+		modules:    make(map[string]zapcore.Level),
+	}
+}
+
+// levelFor returns the effective level for a logger name. "http.router" falls back to "http".
+func (s *levelState) levelFor(name string) zapcore.Level {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for name != "" {
+		if level, ok := s.modules[name]; ok {
+			return level
+		}
+		idx := strings.LastIndex(name, ".")
+		if idx < 0 {
+			break
+		}
+		name = name[:idx]
+	}
+	return s.global.Level()
+}
+
+// minimum returns the most verbose level enabled anywhere, for zap's fast-path Enabled check
+func (s *levelState) minimum() zapcore.Level {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	min := s.global.Level()
+	for _, level := range s.modules {
+		if level < min {
+			min = level
+		}
+	}
+	return min
+}
+
+// moduleLevelCore filters entries by the level of the module (logger name) that produced them
+type moduleLevelCore struct {
+	zapcore.Core
+	levels *levelState
+}
+
+func (c *moduleLevelCore) Enabled(level zapcore.Level) bool {
+	return level >= c.levels.minimum()
+}
+
+func (c *moduleLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &moduleLevelCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+func (c *moduleLevelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.Level < c.levels.levelFor(entry.LoggerName) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
+func parseLevel(level string) (zapcore.Level, error) {
+	var l zapcore.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return l, fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+	return l, nil
+}
+
+// Module returns a logger for a named module (e.g. "http", "database") whose
+// level can be set independently with [logging.levels] or SetModuleLevel
+func (l *Logger) Module(name string) *Logger {
+	named := l.Logger.Named(name)
+	return &Logger{
+		Logger: named,
+		config: l.config,
+		sugar:  named.Sugar(),
+		levels: l.levels,
+	}
+}
+
+// SetLevel changes the global log level at runtime
+func (l *Logger) SetLevel(level string) error {
+	parsed, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.levels.global.SetLevel(parsed)
+	return nil
+}
+
+// SetModuleLevel overrides the level of one module at runtime. An empty level removes the override.
+func (l *Logger) SetModuleLevel(module, level string) error {
+	if level == "" {
+		l.levels.mu.Lock()
+		delete(l.levels.modules, module)
+		l.levels.mu.Unlock()
+		return nil
+	}
+	parsed, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.levels.mu.Lock()
+	l.levels.modules[module] = parsed
+	l.levels.mu.Unlock()
+	return nil
+}
+
+// Level returns the current global level
+func (l *Logger) Level() string {
+	return l.levels.global.Level().String()
+}
+
+// Levels returns the global level under "default" plus all module overrides
+func (l *Logger) Levels() map[string]string {
+	l.levels.mu.RLock()
+	defer l.levels.mu.RUnlock()
+	out := map[string]string{"default": l.levels.global.Level().String()}
+	for module, level := range l.levels.modules {
+		out[module] = level.String()
+	}
+	return out
+}
+
+// ToggleDebug switches the global level between debug and the configured level,
+// returning the new level. Used by the SIGUSR1 handler.
+func (l *Logger) ToggleDebug() string {
+	if l.levels.global.Level() == zapcore.DebugLevel {
+		l.levels.global.SetLevel(l.levels.configured)
+	} else {
+		l.levels.global.SetLevel(zapcore.DebugLevel)
+	}
+	return l.levels.global.Level().String()
+}
@@ -2,7 +2,6 @@ package logging
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"time"
 
@@ -31,14 +30,30 @@ type LoggerConfig struct {
 	MaxBackups  int
 	Compress    bool
 	Level       string
+	Format      string // "json" or "console"; empty picks console in development, json otherwise
+	Output      string // comma-separated sink types used when Sinks is empty (default "stdout")
+	Sinks       []SinkConfig
+	Levels      map[string]string // per-module level overrides, e.g. {"http": "warn"}
 	Development bool
 }
 
+// encoderFormat resolves the default encoding for sinks without their own format
+func (c *LoggerConfig) encoderFormat() string {
+	if c.Format != "" {
+		return c.Format
+	}
+	if c.Development {
+		return "console"
+	}
+	return "json"
+}
+
 // Logger wraps zap.Logger with additional functionality
 type Logger struct {
 	*zap.Logger
 	config *LoggerConfig
 	sugar  *zap.SugaredLogger
+	levels *levelState
 }
 
 // NewLogger creates a new logger with the given configuration
@@ -61,13 +76,6 @@ func NewLogger(config *LoggerConfig) (*Logger, error) {
 		config.MaxBackups = 10
 	}
 
-	// Ensure storage directory exists
-	if config.FileLogging {
-		if err := os.MkdirAll(config.StoragePath, 0755); err != nil {
-			return nil, fmt.Errorf("failed to create log directory: %w", err)
-		}
-	}
-
 	// Parse log level
 	level := zapcore.InfoLevel
 	if config.Level != "" {
@@ -85,44 +93,40 @@ func NewLogger(config *LoggerConfig) (*Logger, error) {
 	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 	encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
 
-	// Create encoder
-	var encoder zapcore.Encoder
-	if config.Development {
-		encoder = zapcore.NewConsoleEncoder(encoderConfig)
-	} else {
-		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	levels := newLevelState(level)
+	for module, moduleLevel := range config.Levels {
+		parsed, err := parseLevel(moduleLevel)
+		if err != nil {
+			return nil, fmt.Errorf("logging.levels.%s: %w", module, err)
+		}
+		levels.modules[module] = parsed
 	}
 
-	// Create cores
-	var cores []zapcore.Core
+	// Create one core per sink, each with its own minimum level and format.
+	// Global and per-module levels are applied once, by the root core.
+	sinks := config.Sinks
+	if len(sinks) == 0 {
+		sinks = sinksFromOutput(config)
+	}
 
-	// Console output
-	consoleCore := zapcore.NewCore(
-		encoder,
-		zapcore.AddSync(os.Stdout),
-		level,
-	)
-	cores = append(cores, consoleCore)
-
-	// File output
-	if config.FileLogging {
-		fileWriter := getLogWriter(config)
-		fileCore := zapcore.NewCore(
-			zapcore.NewJSONEncoder(encoderConfig),
-			zapcore.AddSync(fileWriter),
-			level,
-		)
-		cores = append(cores, fileCore)
+	var cores []zapcore.Core
+	for _, sink := range sinks {
+		core, err := buildSinkCore(sink, config, encoderConfig, zapcore.DebugLevel)
+		if err != nil {
+			return nil, err
+		}
+		cores = append(cores, core)
 	}
 
 	// Create logger
-	core := zapcore.NewTee(cores...)
+	core := &moduleLevelCore{Core: zapcore.NewTee(cores...), levels: levels}
 	zapLogger := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
 
 	return &Logger{
 		Logger: zapLogger,
 		config: config,
 		sugar:  zapLogger.Sugar(),
+		levels: levels,
 	}, nil
 }
 
@@ -172,6 +176,11 @@ func DefaultConfig() *LoggerConfig {
 	}
 }
 
+// Development reports whether the logger was created in development (debug) mode
+func (l *Logger) Development() bool {
+	return l.config != nil && l.config.Development
+}
+
 // Sugar returns the sugared logger for easier logging
 func (l *Logger) Sugar() *zap.SugaredLogger {
 	return l.sugar
@@ -188,6 +197,7 @@ func (l *Logger) WithContext(fields ...zap.Field) *Logger {
 		Logger: l.Logger.With(fields...),
 		config: l.config,
 		sugar:  l.Logger.With(fields...).Sugar(),
+		levels: l.levels,
 	}
 }
 
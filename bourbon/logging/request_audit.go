@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RequestAudit is a sanitized record of an HTTP request and its response
+type RequestAudit struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	Timestamp    time.Time `gorm:"index" json:"timestamp"`
+	Method       string    `gorm:"size:10" json:"method"`
+	Path         string    `gorm:"size:500;index" json:"path"`
+	Query        string    `gorm:"size:1000" json:"query,omitempty"`
+	Status       int       `gorm:"index" json:"status"`
+	DurationMs   int64     `json:"duration_ms"`
+	IP           string    `gorm:"size:45" json:"ip"`
+	UserID       *uint     `gorm:"index" json:"user_id,omitempty"`
+	RequestBody  string    `gorm:"type:text" json:"request_body,omitempty"`
+	ResponseBody string    `gorm:"type:text" json:"response_body,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// AuditSink receives request audit records
+type AuditSink interface {
+	WriteAudit(record *RequestAudit) error
+}
+
+// DBAuditSink stores request audits in the request_audits table
+type DBAuditSink struct {
+	db *gorm.DB
+}
+
+// NewDBAuditSink creates an audit sink backed by the database
+func NewDBAuditSink(db *gorm.DB) *DBAuditSink {
+	return &DBAuditSink{db: db}
+}
+
+// Migrate creates the request_audits table
+func (s *DBAuditSink) Migrate() error {
+	return s.db.AutoMigrate(&RequestAudit{})
+}
+
+// WriteAudit saves the record
+func (s *DBAuditSink) WriteAudit(record *RequestAudit) error {
+	return s.db.Create(record).Error
+}
+
+// Clean removes audit records older than the retention window
+func (s *DBAuditSink) Clean(olderThan time.Duration) error {
+	return s.db.Where("timestamp < ?", time.Now().Add(-olderThan)).Delete(&RequestAudit{}).Error
+}
+
+// LogAuditSink writes request audits as structured log entries
+type LogAuditSink struct {
+	logger *Logger
+}
+
+// NewLogAuditSink creates an audit sink that writes to the logger
+func NewLogAuditSink(logger *Logger) *LogAuditSink {
+	return &LogAuditSink{logger: logger}
+}
+
+// WriteAudit logs the record at info level
+func (s *LogAuditSink) WriteAudit(record *RequestAudit) error {
+	fields := []zap.Field{
+		zap.Time("timestamp", record.Timestamp),
+		zap.String("method", record.Method),
+		zap.String("path", record.Path),
+		zap.String("query", record.Query),
+		zap.Int("status", record.Status),
+		zap.Int64("duration_ms", record.DurationMs),
+		zap.String("ip", record.IP),
+		zap.String("request_body", record.RequestBody),
+		zap.String("response_body", record.ResponseBody),
+	}
+	if record.UserID != nil {
+		fields = append(fields, zap.Uint("user_id", *record.UserID))
+	}
+	s.logger.Info("Request audit", fields...)
+	return nil
+}
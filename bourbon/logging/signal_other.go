@@ -0,0 +1,6 @@
+//go:build windows || plan9
+
+package logging
+
+// ToggleDebugOnSignal is a no-op on platforms without SIGUSR1
+func (l *Logger) ToggleDebugOnSignal() {}
@@ -0,0 +1,23 @@
+//go:build !windows && !plan9
+
+package logging
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+)
+
+// ToggleDebugOnSignal toggles debug logging each time the process receives SIGUSR1
+func (l *Logger) ToggleDebugOnSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	go func() {
+		for range signals {
+			level := l.ToggleDebug()
+			l.Info("Log level changed by SIGUSR1", zap.String("level", level))
+		}
+	}()
+}
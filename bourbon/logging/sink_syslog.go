@@ -0,0 +1,58 @@
+//go:build !windows && !plan9
+
+package logging
+
+import (
+	"fmt"
+	"log/syslog"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// syslogSink writes entries to syslog with a priority matching the entry level
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+// newSyslogSink connects to the local syslog daemon, or to Address ("udp://host:514")
+func newSyslogSink(sink SinkConfig) (*syslogSink, error) {
+	tag := sink.Tag
+	if tag == "" {
+		tag = "bourbon"
+	}
+
+	network, raddr := "", ""
+	if sink.Address != "" {
+		network, raddr = "udp", sink.Address
+		if scheme, rest, ok := strings.Cut(sink.Address, "://"); ok {
+			network, raddr = scheme, rest
+		}
+	}
+
+	writer, err := syslog.Dial(network, raddr, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	return &syslogSink{writer: writer}, nil
+}
+
+func (s *syslogSink) WriteEntry(entry zapcore.Entry, line []byte) error {
+	msg := string(line)
+	switch syslogPriority(entry.Level) {
+	case 2:
+		return s.writer.Crit(msg)
+	case 3:
+		return s.writer.Err(msg)
+	case 4:
+		return s.writer.Warning(msg)
+	case 6:
+		return s.writer.Info(msg)
+	default:
+		return s.writer.Debug(msg)
+	}
+}
+
+func (s *syslogSink) Sync() error {
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build windows || plan9
+
+package logging
+
+import "fmt"
+
+// newSyslogSink is unavailable on platforms without log/syslog
+func newSyslogSink(sink SinkConfig) (entryWriter, error) {
+	return nil, fmt.Errorf("syslog sink is not supported on this platform")
+}
@@ -0,0 +1,424 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Sink types selectable in [[logging.sinks]] or logging.output
+const (
+	SinkStdout   = "stdout"
+	SinkStderr   = "stderr"
+	SinkFile     = "file"
+	SinkSyslog   = "syslog"
+	SinkJournald = "journald"
+	SinkLoki     = "loki"
+	SinkOTLP     = "otlp"
+)
+
+// SinkConfig configures one log destination
+type SinkConfig struct {
+	Type    string            // stdout, stderr, file, syslog, journald, loki, otlp
+	Level   string            // minimum level for this sink; empty uses the logger level
+	Format  string            // json or console; empty uses the logger format
+	Address string            // syslog "udp://host:514" (empty = local), Loki/OTLP base URL
+	Tag     string            // syslog tag, journald identifier, OTLP service.name
+	Labels  map[string]string // Loki stream labels
+	Headers map[string]string // extra HTTP headers for Loki/OTLP, e.g. authorization
+}
+
+// sinksFromOutput converts the legacy logging.output / file_logging settings into sinks.
+// Output may list several comma-separated sink types, e.g. "stdout,syslog".
+func sinksFromOutput(config *LoggerConfig) []SinkConfig {
+	var sinks []SinkConfig
+	output := config.Output
+	if output == "" {
+		output = SinkStdout
+	}
+	hasFile := false
+	for _, name := range strings.Split(output, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || name == "none" {
+			continue
+		}
+		if name == SinkFile {
+			hasFile = true
+		}
+		sinks = append(sinks, SinkConfig{Type: name})
+	}
+	if config.FileLogging && !hasFile {
+		sinks = append(sinks, SinkConfig{Type: SinkFile, Format: "json"})
+	}
+	return sinks
+}
+
+// buildSinkCore creates the zap core for one sink
+func buildSinkCore(sink SinkConfig, config *LoggerConfig, encoderConfig zapcore.EncoderConfig, level zapcore.LevelEnabler) (zapcore.Core, error) {
+	if sink.Level != "" {
+		var sinkLevel zapcore.Level
+		if err := sinkLevel.UnmarshalText([]byte(sink.Level)); err != nil {
+			return nil, fmt.Errorf("invalid level for %s sink: %w", sink.Type, err)
+		}
+		level = minLevel{parent: level, min: sinkLevel}
+	}
+
+	format := sink.Format
+	if format == "" {
+		format = config.encoderFormat()
+	}
+	encoder := zapcore.NewJSONEncoder(encoderConfig)
+	if format == "console" {
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	}
+
+	switch sink.Type {
+	case SinkStdout:
+		return zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), level), nil
+	case SinkStderr:
+		return zapcore.NewCore(encoder, zapcore.AddSync(os.Stderr), level), nil
+	case SinkFile:
+		if err := os.MkdirAll(config.StoragePath, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create log directory: %w", err)
+		}
+		return zapcore.NewCore(encoder, zapcore.AddSync(getLogWriter(config)), level), nil
+	case SinkSyslog:
+		writer, err := newSyslogSink(sink)
+		if err != nil {
+			return nil, err
+		}
+		return newEntryCore(encoder, writer, level), nil
+	case SinkJournald:
+		writer, err := newJournaldSink(sink)
+		if err != nil {
+			return nil, err
+		}
+		return newEntryCore(encoder, writer, level), nil
+	case SinkLoki:
+		if sink.Address == "" {
+			return nil, fmt.Errorf("loki sink requires an address")
+		}
+		return newEntryCore(encoder, newHTTPBatchSink(sink, lokiPayload(sink)), level), nil
+	case SinkOTLP:
+		if sink.Address == "" {
+			return nil, fmt.Errorf("otlp sink requires an address")
+		}
+		return newEntryCore(encoder, newHTTPBatchSink(sink, otlpPayload(sink)), level), nil
+	default:
+		return nil, fmt.Errorf("unknown log sink type: %s", sink.Type)
+	}
+}
+
+// minLevel enables a level only when both the parent enabler and the sink minimum allow it
+type minLevel struct {
+	parent zapcore.LevelEnabler
+	min    zapcore.Level
+}
+
+func (l minLevel) Enabled(level zapcore.Level) bool {
+	return level >= l.min && l.parent.Enabled(level)
+}
+
+// entryWriter receives encoded log entries along with their metadata
+type entryWriter interface {
+	WriteEntry(entry zapcore.Entry, line []byte) error
+	Sync() error
+}
+
+// entryCore is a zapcore.Core for sinks that need the entry level, such as syslog priorities
+type entryCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	writer  entryWriter
+}
+
+func newEntryCore(encoder zapcore.Encoder, writer entryWriter, level zapcore.LevelEnabler) zapcore.Core {
+	return &entryCore{LevelEnabler: level, encoder: encoder, writer: writer}
+}
+
+func (c *entryCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &entryCore{LevelEnabler: c.LevelEnabler, encoder: c.encoder.Clone(), writer: c.writer}
+	for _, f := range fields {
+		f.AddTo(clone.encoder)
+	}
+	return clone
+}
+
+func (c *entryCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *entryCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+	return c.writer.WriteEntry(entry, bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+func (c *entryCore) Sync() error {
+	return c.writer.Sync()
+}
+
+// journaldSocket is the systemd journal native protocol socket
+const journaldSocket = "/run/systemd/journal/socket"
+
+// journaldSink writes entries using the systemd journal native protocol
+type journaldSink struct {
+	conn       net.Conn
+	identifier string
+}
+
+func newJournaldSink(sink SinkConfig) (*journaldSink, error) {
+	address := sink.Address
+	if address == "" {
+		address = journaldSocket
+	}
+	conn, err := net.Dial("unixgram", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to journald: %w", err)
+	}
+	identifier := sink.Tag
+	if identifier == "" {
+		identifier = "bourbon"
+	}
+	return &journaldSink{conn: conn, identifier: identifier}, nil
+}
+
+func (s *journaldSink) WriteEntry(entry zapcore.Entry, line []byte) error {
+	var msg bytes.Buffer
+	writeJournalField(&msg, "MESSAGE", line)
+	writeJournalField(&msg, "PRIORITY", []byte(strconv.Itoa(syslogPriority(entry.Level))))
+	writeJournalField(&msg, "SYSLOG_IDENTIFIER", []byte(s.identifier))
+	if entry.LoggerName != "" {
+		writeJournalField(&msg, "LOGGER", []byte(entry.LoggerName))
+	}
+	_, err := s.conn.Write(msg.Bytes())
+	return err
+}
+
+func (s *journaldSink) Sync() error {
+	return nil
+}
+
+// writeJournalField encodes a field, using the binary length form for multi-line values
+func writeJournalField(buf *bytes.Buffer, key string, value []byte) {
+	if !bytes.ContainsRune(value, '\n') {
+		buf.WriteString(key + "=")
+		buf.Write(value)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteString(key + "\n")
+	size := uint64(len(value))
+	for i := 0; i < 8; i++ {
+		buf.WriteByte(byte(size >> (8 * i)))
+	}
+	buf.Write(value)
+	buf.WriteByte('\n')
+}
+
+// syslogPriority maps zap levels to syslog severities
+func syslogPriority(level zapcore.Level) int {
+	switch {
+	case level >= zapcore.DPanicLevel:
+		return 2 // crit
+	case level == zapcore.ErrorLevel:
+		return 3 // err
+	case level == zapcore.WarnLevel:
+		return 4 // warning
+	case level == zapcore.InfoLevel:
+		return 6 // info
+	default:
+		return 7 // debug
+	}
+}
+
+// httpBatchSize and httpBatchInterval control when buffered entries are pushed
+const (
+	httpBatchSize     = 100
+	httpBatchInterval = 2 * time.Second
+)
+
+type bufferedEntry struct {
+	time  time.Time
+	level zapcore.Level
+	line  string
+}
+
+// httpBatchSink buffers entries and pushes them in batches to an HTTP log collector
+type httpBatchSink struct {
+	mu      sync.Mutex
+	url     string
+	headers map[string]string
+	client  *http.Client
+	payload func([]bufferedEntry) ([]byte, error)
+	entries []bufferedEntry
+	ticker  *time.Ticker
+}
+
+func newHTTPBatchSink(sink SinkConfig, payload func([]bufferedEntry) ([]byte, error)) *httpBatchSink {
+	url := strings.TrimRight(sink.Address, "/")
+	switch sink.Type {
+	case SinkLoki:
+		if !strings.HasSuffix(url, "/loki/api/v1/push") {
+			url += "/loki/api/v1/push"
+		}
+	case SinkOTLP:
+		if !strings.HasSuffix(url, "/v1/logs") {
+			url += "/v1/logs"
+		}
+	}
+
+	s := &httpBatchSink{
+		url:     url,
+		headers: sink.Headers,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		payload: payload,
+		ticker:  time.NewTicker(httpBatchInterval),
+	}
+	go func() {
+		for range s.ticker.C {
+			_ = s.Sync()
+		}
+	}()
+	return s
+}
+
+func (s *httpBatchSink) WriteEntry(entry zapcore.Entry, line []byte) error {
+	s.mu.Lock()
+	s.entries = append(s.entries, bufferedEntry{time: entry.Time, level: entry.Level, line: string(line)})
+	full := len(s.entries) >= httpBatchSize
+	s.mu.Unlock()
+	if full {
+		go func() { _ = s.Sync() }()
+	}
+	return nil
+}
+
+// Sync pushes all buffered entries
+func (s *httpBatchSink) Sync() error {
+	s.mu.Lock()
+	entries := s.entries
+	s.entries = nil
+	s.mu.Unlock()
+	if len(entries) == 0 {
+		return nil
+	}
+
+	body, err := s.payload(entries)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push logs to %s: %w", s.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to push logs to %s: status %d", s.url, resp.StatusCode)
+	}
+	return nil
+}
+
+// lokiPayload encodes entries for the Loki push API, one stream per level
+func lokiPayload(sink SinkConfig) func([]bufferedEntry) ([]byte, error) {
+	return func(entries []bufferedEntry) ([]byte, error) {
+		type stream struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		}
+		streams := make(map[zapcore.Level]*stream)
+		var order []zapcore.Level
+		for _, e := range entries {
+			st, ok := streams[e.level]
+			if !ok {
+				labels := map[string]string{"level": e.level.String()}
+				for k, v := range sink.Labels {
+					labels[k] = v
+				}
+				st = &stream{Stream: labels}
+				streams[e.level] = st
+				order = append(order, e.level)
+			}
+			st.Values = append(st.Values, [2]string{strconv.FormatInt(e.time.UnixNano(), 10), e.line})
+		}
+		payload := struct {
+			Streams []*stream `json:"streams"`
+		}{}
+		for _, level := range order {
+			payload.Streams = append(payload.Streams, streams[level])
+		}
+		return json.Marshal(payload)
+	}
+}
+
+// otlpPayload encodes entries as an OTLP/HTTP JSON logs export request
+func otlpPayload(sink SinkConfig) func([]bufferedEntry) ([]byte, error) {
+	service := sink.Tag
+	if service == "" {
+		service = "bourbon"
+	}
+	return func(entries []bufferedEntry) ([]byte, error) {
+		records := make([]map[string]interface{}, 0, len(entries))
+		for _, e := range entries {
+			records = append(records, map[string]interface{}{
+				"timeUnixNano":   strconv.FormatInt(e.time.UnixNano(), 10),
+				"severityNumber": otlpSeverity(e.level),
+				"severityText":   strings.ToUpper(e.level.String()),
+				"body":           map[string]string{"stringValue": e.line},
+			})
+		}
+		return json.Marshal(map[string]interface{}{
+			"resourceLogs": []interface{}{map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": []interface{}{map[string]interface{}{
+						"key":   "service.name",
+						"value": map[string]string{"stringValue": service},
+					}},
+				},
+				"scopeLogs": []interface{}{map[string]interface{}{
+					"scope":      map[string]string{"name": "bourbon"},
+					"logRecords": records,
+				}},
+			}},
+		})
+	}
+}
+
+// otlpSeverity maps zap levels to OTLP severity numbers
+func otlpSeverity(level zapcore.Level) int {
+	switch {
+	case level >= zapcore.DPanicLevel:
+		return 21 // FATAL
+	case level == zapcore.ErrorLevel:
+		return 17 // ERROR
+	case level == zapcore.WarnLevel:
+		return 13 // WARN
+	case level == zapcore.InfoLevel:
+		return 9 // INFO
+	default:
+		return 5 // DEBUG
+	}
+}
@@ -0,0 +1,32 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+)
+
+// AlertNotifier emails error alerts through a Mailer, so they use its transport
+// and default sender
+type AlertNotifier struct {
+	Mailer *Mailer
+	To     []string
+}
+
+// NewAlertNotifier creates a notifier emailing alerts to the given addresses
+func NewAlertNotifier(m *Mailer, to ...string) *AlertNotifier {
+	return &AlertNotifier{Mailer: m, To: to}
+}
+
+// Notify emails the alert summary to all recipients
+func (n *AlertNotifier) Notify(alert *logging.ErrorAlert) error {
+	msg := NewMessage().
+		AddTo(n.To...).
+		SetSubject(alert.Subject()).
+		SetText(alert.Summary())
+	if err := n.Mailer.Send(context.Background(), msg); err != nil {
+		return fmt.Errorf("email alert failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/jobs"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+	"go.uber.org/zap"
+)
+
+// Renderer renders a named template; *bourbon.TemplateEngine implements it
+type Renderer interface {
+	Render(name string, data interface{}) (string, error)
+}
+
+// Config selects and configures a transport
+type Config struct {
+	Driver     string // smtp (default), log or file
+	Host       string
+	Port       int
+	Username   string
+	Password   string
+	Encryption string // starttls (default), tls or none
+	Directory  string // file driver output directory
+	Timeout    time.Duration
+}
+
+// NewTransport creates the transport for cfg.Driver
+func NewTransport(cfg Config, l *logging.Logger) (Transport, error) {
+	switch cfg.Driver {
+	case "", "smtp":
+		return &SMTPTransport{
+			Host:       cfg.Host,
+			Port:       cfg.Port,
+			Username:   cfg.Username,
+			Password:   cfg.Password,
+			Encryption: cfg.Encryption,
+			Timeout:    cfg.Timeout,
+		}, nil
+	case "log":
+		return &LogTransport{Logger: l}, nil
+	case "file":
+		if cfg.Directory == "" {
+			return nil, errors.New("mail: the file driver needs a directory")
+		}
+		return &FileTransport{Directory: cfg.Directory}, nil
+	default:
+		return nil, fmt.Errorf("mail: unknown driver %q", cfg.Driver)
+	}
+}
+
+// Options configures a Mailer
+type Options struct {
+	From      string     // sender used when a message has none
+	Templates Renderer   // renders message views; views fail to send without it
+	Queue     jobs.Queue // runs SendAsync; without it SendAsync sends synchronously
+	Logger    *logging.Logger
+}
+
+// Mailer renders and sends messages through a transport
+type Mailer struct {
+	transport Transport
+	opts      Options
+	logger    *logging.Logger
+}
+
+// NewMailer creates a mailer sending through transport
+func NewMailer(transport Transport, opts Options) *Mailer {
+	m := &Mailer{transport: transport, opts: opts}
+	if opts.Logger != nil {
+		m.logger = opts.Logger.Module(logging.ModuleMail)
+	}
+	return m
+}
+
+// Transport returns the transport messages are sent through
+func (m *Mailer) Transport() Transport {
+	return m.transport
+}
+
+// Send renders msg's views and sends it:
+//
+//	err := app.Mailer.Send(ctx, mail.NewMessage().
+//	    AddTo(user.Email).
+//	    SetSubject("Reset your password").
+//	    SetView("emails/reset.html", bourbon.H{"Link": link}))
+func (m *Mailer) Send(ctx context.Context, msg *Message) error {
+	if err := m.prepare(msg); err != nil {
+		return err
+	}
+	if err := m.transport.Send(ctx, msg); err != nil {
+		return err
+	}
+	if m.logger != nil {
+		m.logger.Debug("Mail sent", zap.Strings("to", msg.To), zap.String("subject", msg.Subject))
+	}
+	return nil
+}
+
+// SendAsync renders msg now and sends it through the job queue. Delivery errors are
+// logged by the queue; errors rendering or validating the message are returned.
+func (m *Mailer) SendAsync(ctx context.Context, msg *Message) error {
+	if m.opts.Queue == nil {
+		return m.Send(ctx, msg)
+	}
+	if err := m.prepare(msg); err != nil {
+		return err
+	}
+	return m.opts.Queue.Enqueue(ctx, "mail.send", func(ctx context.Context) error {
+		return m.transport.Send(ctx, msg)
+	})
+}
+
+// prepare fills the default sender, renders the views and validates msg
+func (m *Mailer) prepare(msg *Message) error {
+	if msg.From == "" {
+		msg.From = m.opts.From
+	}
+	for _, view := range []struct {
+		name string
+		body *string
+	}{
+		{msg.View, &msg.HTMLBody},
+		{msg.TextView, &msg.TextBody},
+	} {
+		if view.name == "" {
+			continue
+		}
+		if m.opts.Templates == nil {
+			return fmt.Errorf("mail: cannot render %s: templates are not configured", view.name)
+		}
+		body, err := m.opts.Templates.Render(view.name, msg.Data)
+		if err != nil {
+			return fmt.Errorf("mail: %w", err)
+		}
+		*view.body = body
+	}
+	msg.View, msg.TextView = "", ""
+	return msg.Validate()
+}
@@ -0,0 +1,323 @@
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var headerNewlines = strings.NewReplacer("\r", "", "\n", "")
+
+// Attachment is a file sent with a message
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Message is an email. Build it with the setters or fill the fields directly:
+//
+//	msg := mail.NewMessage().
+//	    AddTo("ada@example.com").
+//	    SetSubject("Welcome").
+//	    SetView("emails/welcome.html", bourbon.H{"Name": "Ada"})
+type Message struct {
+	From        string
+	To          []string
+	Cc          []string
+	Bcc         []string
+	ReplyTo     string
+	Subject     string
+	TextBody    string
+	HTMLBody    string
+	View        string      // template rendered into HTMLBody when sent
+	TextView    string      // template rendered into TextBody when sent
+	Data        interface{} // data passed to View and TextView
+	Headers     map[string]string
+	Attachments []Attachment
+}
+
+// NewMessage creates an empty message
+func NewMessage() *Message {
+	return &Message{}
+}
+
+func (m *Message) SetFrom(address string) *Message {
+	m.From = address
+	return m
+}
+
+func (m *Message) AddTo(addresses ...string) *Message {
+	m.To = append(m.To, addresses...)
+	return m
+}
+
+func (m *Message) AddCc(addresses ...string) *Message {
+	m.Cc = append(m.Cc, addresses...)
+	return m
+}
+
+func (m *Message) AddBcc(addresses ...string) *Message {
+	m.Bcc = append(m.Bcc, addresses...)
+	return m
+}
+
+func (m *Message) SetReplyTo(address string) *Message {
+	m.ReplyTo = address
+	return m
+}
+
+func (m *Message) SetSubject(subject string) *Message {
+	m.Subject = subject
+	return m
+}
+
+// SetText sets the plain-text body
+func (m *Message) SetText(body string) *Message {
+	m.TextBody = body
+	return m
+}
+
+// SetHTML sets the HTML body
+func (m *Message) SetHTML(body string) *Message {
+	m.HTMLBody = body
+	return m
+}
+
+// SetView renders the HTML body from a template when the message is sent
+func (m *Message) SetView(name string, data interface{}) *Message {
+	m.View = name
+	m.Data = data
+	return m
+}
+
+// SetTextView renders the plain-text body from a template when the message is sent
+func (m *Message) SetTextView(name string) *Message {
+	m.TextView = name
+	return m
+}
+
+func (m *Message) SetHeader(key, value string) *Message {
+	if m.Headers == nil {
+		m.Headers = make(map[string]string)
+	}
+	m.Headers[key] = value
+	return m
+}
+
+// Attach adds a file from memory. contentType is detected from the filename when empty.
+func (m *Message) Attach(filename string, data []byte, contentType string) *Message {
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(filename))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	m.Attachments = append(m.Attachments, Attachment{Filename: filename, ContentType: contentType, Data: data})
+	return m
+}
+
+// AttachFile reads a file from disk and attaches it
+func (m *Message) AttachFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("mail: failed to read attachment: %w", err)
+	}
+	m.Attach(filepath.Base(path), data, "")
+	return nil
+}
+
+// Validate checks that the message has a sender, a recipient and a body
+func (m *Message) Validate() error {
+	if m.From == "" {
+		return errors.New("mail: message has no sender")
+	}
+	if _, err := mail.ParseAddress(m.From); err != nil {
+		return fmt.Errorf("mail: invalid sender %q: %w", m.From, err)
+	}
+	recipients, err := m.Recipients()
+	if err != nil {
+		return err
+	}
+	if len(recipients) == 0 {
+		return errors.New("mail: message has no recipients")
+	}
+	if m.TextBody == "" && m.HTMLBody == "" {
+		return errors.New("mail: message has no body")
+	}
+	return nil
+}
+
+// Recipients returns the bare addresses of To, Cc and Bcc, for the SMTP envelope
+func (m *Message) Recipients() ([]string, error) {
+	var result []string
+	for _, list := range [][]string{m.To, m.Cc, m.Bcc} {
+		for _, address := range list {
+			parsed, err := mail.ParseAddress(address)
+			if err != nil {
+				return nil, fmt.Errorf("mail: invalid recipient %q: %w", address, err)
+			}
+			result = append(result, parsed.Address)
+		}
+	}
+	return result, nil
+}
+
+// Bytes encodes the message as MIME. Bcc recipients are left out of the headers.
+func (m *Message) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		if value != "" {
+			// Newlines in a value would start new headers
+			fmt.Fprintf(&buf, "%s: %s\r\n", key, headerNewlines.Replace(value))
+		}
+	}
+
+	header("From", m.From)
+	header("To", strings.Join(m.To, ", "))
+	header("Cc", strings.Join(m.Cc, ", "))
+	header("Reply-To", m.ReplyTo)
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", messageID(m.From))
+	header("MIME-Version", "1.0")
+	keys := make([]string, 0, len(m.Headers))
+	for key := range m.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		header(textproto.CanonicalMIMEHeaderKey(key), m.Headers[key])
+	}
+
+	if len(m.Attachments) == 0 {
+		if err := m.writeBody(&buf); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	w := multipart.NewWriter(&buf)
+	header("Content-Type", `multipart/mixed; boundary="`+w.Boundary()+`"`)
+	buf.WriteString("\r\n")
+
+	var body bytes.Buffer
+	if err := m.writeBody(&body); err != nil {
+		return nil, err
+	}
+	bodyHeader, bodyContent, _ := bytes.Cut(body.Bytes(), []byte("\r\n\r\n"))
+	part, err := w.CreatePart(parseHeader(bodyHeader))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(bodyContent); err != nil {
+		return nil, err
+	}
+
+	for _, a := range m.Attachments {
+		part, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {a.ContentType},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64(part, a.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeBody writes the Content-Type header, a blank line and the text and/or HTML body
+func (m *Message) writeBody(buf *bytes.Buffer) error {
+	if m.TextBody != "" && m.HTMLBody != "" {
+		w := multipart.NewWriter(buf)
+		fmt.Fprintf(buf, "Content-Type: multipart/alternative; boundary=\"%s\"\r\n\r\n", w.Boundary())
+		for _, alt := range []struct{ contentType, body string }{
+			{"text/plain; charset=utf-8", m.TextBody},
+			{"text/html; charset=utf-8", m.HTMLBody},
+		} {
+			part, err := w.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {alt.contentType},
+				"Content-Transfer-Encoding": {"quoted-printable"},
+			})
+			if err != nil {
+				return err
+			}
+			if err := writeQuotedPrintable(part, alt.body); err != nil {
+				return err
+			}
+		}
+		return w.Close()
+	}
+
+	contentType, body := "text/plain; charset=utf-8", m.TextBody
+	if m.HTMLBody != "" {
+		contentType, body = "text/html; charset=utf-8", m.HTMLBody
+	}
+	fmt.Fprintf(buf, "Content-Type: %s\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n", contentType)
+	return writeQuotedPrintable(buf, body)
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// writeBase64 writes data base64-encoded in 76-character lines
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := fmt.Fprintf(w, "%s\r\n", encoded[:76]); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err := fmt.Fprintf(w, "%s\r\n", encoded)
+	return err
+}
+
+func parseHeader(raw []byte) textproto.MIMEHeader {
+	header := textproto.MIMEHeader{}
+	for _, line := range strings.Split(string(raw), "\r\n") {
+		if key, value, ok := strings.Cut(line, ": "); ok {
+			header.Add(key, value)
+		}
+	}
+	return header
+}
+
+// messageID builds a unique Message-ID on the sender's domain
+func messageID(from string) string {
+	domain := "localhost"
+	if parsed, err := mail.ParseAddress(from); err == nil {
+		if _, d, ok := strings.Cut(parsed.Address, "@"); ok {
+			domain = d
+		}
+	}
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(b), domain)
+}
@@ -0,0 +1,186 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+	"go.uber.org/zap"
+)
+
+// Transport delivers messages
+type Transport interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// SMTPTransport sends messages through an SMTP server
+type SMTPTransport struct {
+	Host       string
+	Port       int
+	Username   string
+	Password   string
+	Encryption string        // "starttls" (used when the server offers it, the default), "tls" or "none"
+	Timeout    time.Duration // dial and send timeout, default 30s
+}
+
+func (t *SMTPTransport) Send(ctx context.Context, msg *Message) error {
+	recipients, err := msg.Recipients()
+	if err != nil {
+		return err
+	}
+	from, err := envelopeAddress(msg.From)
+	if err != nil {
+		return err
+	}
+	data, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+
+	timeout := t.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(t.Host, fmt.Sprint(t.Port))
+	dialer := &net.Dialer{}
+	var conn net.Conn
+	if t.Encryption == "tls" {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: t.Host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("mail: failed to connect to %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, t.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("mail: %w", err)
+	}
+	defer client.Close()
+
+	if t.Encryption == "" || t.Encryption == "starttls" {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: t.Host}); err != nil {
+				return fmt.Errorf("mail: STARTTLS failed: %w", err)
+			}
+		}
+	}
+	if t.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", t.Username, t.Password, t.Host)); err != nil {
+			return fmt.Errorf("mail: authentication failed: %w", err)
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("mail: MAIL FROM rejected: %w", err)
+	}
+	for _, rcpt := range recipients {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("mail: recipient %s rejected: %w", rcpt, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("mail: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("mail: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("mail: %w", err)
+	}
+	return client.Quit()
+}
+
+// LogTransport logs messages instead of sending them, for development
+type LogTransport struct {
+	Logger *logging.Logger
+}
+
+func (t *LogTransport) Send(ctx context.Context, msg *Message) error {
+	recipients, err := msg.Recipients()
+	if err != nil {
+		return err
+	}
+	if t.Logger == nil {
+		return nil
+	}
+	t.Logger.Module(logging.ModuleMail).Info("Mail not sent (log transport)",
+		zap.String("from", msg.From),
+		zap.Strings("to", recipients),
+		zap.String("subject", msg.Subject),
+		zap.Int("attachments", len(msg.Attachments)),
+		zap.String("body", firstNonEmpty(msg.TextBody, msg.HTMLBody)),
+	)
+	return nil
+}
+
+// FileTransport writes each message to an .eml file in Directory, for development
+type FileTransport struct {
+	Directory string
+}
+
+func (t *FileTransport) Send(ctx context.Context, msg *Message) error {
+	data, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(t.Directory, 0755); err != nil {
+		return fmt.Errorf("mail: failed to create %s: %w", t.Directory, err)
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000000000"), slug(msg.Subject))
+	return os.WriteFile(filepath.Join(t.Directory, name), data, 0644)
+}
+
+// envelopeAddress returns the bare address of a "Name <address>" string
+func envelopeAddress(address string) (string, error) {
+	recipients, err := (&Message{To: []string{address}}).Recipients()
+	if err != nil {
+		return "", fmt.Errorf("mail: invalid sender %q", address)
+	}
+	return recipients[0], nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// slug turns a subject into a short file-name-safe string
+func slug(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteByte('-')
+		}
+		if b.Len() >= 40 {
+			break
+		}
+	}
+	result := strings.Trim(b.String(), "-")
+	if result == "" {
+		return "message"
+	}
+	return result
+}
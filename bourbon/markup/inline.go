@@ -0,0 +1,301 @@
+package markup
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	entity     = regexp.MustCompile(`^&(#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+	inlineTag  = regexp.MustCompile(`^</?[a-zA-Z][a-zA-Z0-9-]*(\s+[a-zA-Z_:][a-zA-Z0-9_.:-]*(\s*=\s*("[^"]*"|'[^']*'|[^\s"'=<>` + "`" + `]+))?)*\s*/?>|^<!--.*?-->`)
+	autolink   = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^\s<>]*|[a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9-]+(\.[a-zA-Z0-9-]+)*)>`)
+	linkTarget = regexp.MustCompile(`^\(\s*(<[^<>\n]*>|[^\s()]*(\([^\s()]*\)[^\s()]*)*)(\s+("[^"]*"|'[^']*'|\([^)]*\)))?\s*\)`)
+)
+
+const punctuation = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+// renderInline renders the inline syntax of a block's text
+func renderInline(s string) string {
+	var b strings.Builder
+	inline(&b, s)
+	return b.String()
+}
+
+func inline(b *strings.Builder, s string) {
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch c {
+		case '\\':
+			if i+1 < len(s) && strings.IndexByte(punctuation, s[i+1]) >= 0 {
+				b.WriteString(html.EscapeString(s[i+1 : i+2]))
+				i += 2
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '\n' {
+				b.WriteString("<br>\n")
+				i += 2
+				continue
+			}
+
+		case '`':
+			run := countRun(s[i:], '`')
+			if end := strings.Index(s[i+run:], strings.Repeat("`", run)); end >= 0 {
+				code := strings.ReplaceAll(s[i+run:i+run+end], "\n", " ")
+				if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+					code = code[1 : len(code)-1]
+				}
+				b.WriteString("<code>" + html.EscapeString(code) + "</code>")
+				i += run + end + run
+				continue
+			}
+			b.WriteString(s[i : i+run])
+			i += run
+			continue
+
+		case '*', '_', '~':
+			if n := emphasis(b, s, i); n > 0 {
+				i += n
+				continue
+			}
+			run := countRun(s[i:], c)
+			b.WriteString(s[i : i+run])
+			i += run
+			continue
+
+		case '!':
+			if i+1 < len(s) && s[i+1] == '[' {
+				if text, dest, title, n, ok := link(s[i+1:]); ok {
+					b.WriteString(`<img src="` + html.EscapeString(dest) + `" alt="` + html.EscapeString(plainText(text)) + `"`)
+					if title != "" {
+						b.WriteString(` title="` + html.EscapeString(title) + `"`)
+					}
+					b.WriteString(">")
+					i += 1 + n
+					continue
+				}
+			}
+
+		case '[':
+			if text, dest, title, n, ok := link(s[i:]); ok {
+				b.WriteString(`<a href="` + html.EscapeString(dest) + `"`)
+				if title != "" {
+					b.WriteString(` title="` + html.EscapeString(title) + `"`)
+				}
+				b.WriteString(">")
+				inline(b, text)
+				b.WriteString("</a>")
+				i += n
+				continue
+			}
+
+		case '<':
+			if m := autolink.FindStringSubmatch(s[i:]); m != nil {
+				dest := m[1]
+				if !strings.Contains(dest, ":") {
+					dest = "mailto:" + dest
+				}
+				b.WriteString(`<a href="` + html.EscapeString(dest) + `">` + html.EscapeString(m[1]) + "</a>")
+				i += len(m[0])
+				continue
+			}
+			if m := inlineTag.FindString(s[i:]); m != "" {
+				// Raw HTML, left for the sanitizer
+				b.WriteString(m)
+				i += len(m)
+				continue
+			}
+			b.WriteString("&lt;")
+			i++
+			continue
+
+		case '&':
+			if m := entity.FindString(s[i:]); m != "" {
+				b.WriteString(m)
+				i += len(m)
+				continue
+			}
+			b.WriteString("&amp;")
+			i++
+			continue
+
+		case ' ':
+			// Two trailing spaces make a hard line break
+			n := countRun(s[i:], ' ')
+			if i+n == len(s) {
+				i += n
+				continue
+			}
+			if s[i+n] == '\n' {
+				if n >= 2 {
+					b.WriteString("<br>")
+				}
+				i += n
+				continue
+			}
+			b.WriteString(s[i : i+n])
+			i += n
+			continue
+		}
+
+		switch c {
+		case '>':
+			b.WriteString("&gt;")
+		case '"':
+			b.WriteString("&#34;")
+		default:
+			b.WriteByte(c)
+		}
+		i++
+	}
+}
+
+// emphasis renders *em*, **strong**, _em_, __strong__ or ~~del~~ starting at s[i]
+// and returns the number of bytes consumed, or 0 when the delimiters do not match
+func emphasis(b *strings.Builder, s string, i int) int {
+	c := s[i]
+	run := countRun(s[i:], c)
+	if c == '~' && run != 2 {
+		return 0
+	}
+	size := min(run, 2)
+	if c != '~' && run == 3 {
+		size = 3
+	}
+	open := i + size
+	if open >= len(s) || s[open] == ' ' || s[open] == '\n' {
+		return 0
+	}
+	// Underscores inside words are literal, as in snake_case
+	if c == '_' && i > 0 && isWordByte(s[i-1]) {
+		return 0
+	}
+
+	// Runs of the same delimiter inside open and close a nested span
+	nested := 0
+	for j := open; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+			continue
+		case '`':
+			// Delimiters inside code spans do not count
+			n := countRun(s[j:], '`')
+			if end := strings.Index(s[j+n:], strings.Repeat("`", n)); end >= 0 {
+				j += n + end + n - 1
+			} else {
+				j += n - 1
+			}
+			continue
+		case c:
+		default:
+			continue
+		}
+
+		n := countRun(s[j:], c)
+		after := j + n
+		opener := after < len(s) && s[after] != ' ' && s[after] != '\n'
+		closer := s[j-1] != ' ' && s[j-1] != '\n'
+		if c == '_' {
+			opener = opener && !isWordByte(s[j-1])
+			closer = closer && (after >= len(s) || !isWordByte(s[after]))
+		}
+		switch {
+		case closer && nested > 0:
+			nested--
+			j = after - 1
+			continue
+		case closer && n >= size:
+		case opener:
+			nested++
+			j = after - 1
+			continue
+		default:
+			j = after - 1
+			continue
+		}
+		end := after
+		j = after - size
+
+		inner := s[open:j]
+		switch {
+		case c == '~':
+			b.WriteString("<del>")
+			inline(b, inner)
+			b.WriteString("</del>")
+		case size == 3:
+			b.WriteString("<em><strong>")
+			inline(b, inner)
+			b.WriteString("</strong></em>")
+		case size == 2:
+			b.WriteString("<strong>")
+			inline(b, inner)
+			b.WriteString("</strong>")
+		default:
+			b.WriteString("<em>")
+			inline(b, inner)
+			b.WriteString("</em>")
+		}
+		return end - i
+	}
+	return 0
+}
+
+// link parses [text](destination "title") at the start of s
+func link(s string) (text, dest, title string, n int, ok bool) {
+	depth := 0
+	closeAt := -1
+	for j := 0; j < len(s) && closeAt < 0; j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '[':
+			depth++
+		case ']':
+			if depth--; depth == 0 {
+				closeAt = j
+			}
+		}
+	}
+	if closeAt < 0 {
+		return "", "", "", 0, false
+	}
+	m := linkTarget.FindStringSubmatch(s[closeAt+1:])
+	if m == nil {
+		return "", "", "", 0, false
+	}
+	dest = strings.TrimSuffix(strings.TrimPrefix(m[1], "<"), ">")
+	if m[4] != "" {
+		title = m[4][1 : len(m[4])-1]
+	}
+	return s[1:closeAt], unescape(dest), unescape(title), closeAt + 1 + len(m[0]), true
+}
+
+// unescape removes backslash escapes and decodes entities in link destinations and titles
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte(punctuation, s[i+1]) >= 0 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return html.UnescapeString(b.String())
+}
+
+// plainText strips markdown punctuation from image descriptions used as alt text
+func plainText(s string) string {
+	return strings.NewReplacer("*", "", "_", "", "`", "", "~", "", "[", "", "]", "").Replace(s)
+}
+
+func countRun(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	return n
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
@@ -0,0 +1,357 @@
+package markup
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Markdown converts markdown to HTML without sanitizing it. It supports the common
+// syntax: headings, paragraphs, emphasis, links, images, code spans and fenced or
+// indented code, block quotes, nested lists, horizontal rules and tables. Raw HTML
+// is passed through, so sanitize the result before showing user input; Render
+// does both.
+func Markdown(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\t", "    ")
+	var b strings.Builder
+	renderBlocks(&b, strings.Split(src, "\n"))
+	return b.String()
+}
+
+var (
+	atxHeading   = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ ]+(.*?))?(?:[ ]+#+)?[ ]*$`)
+	hrLine       = regexp.MustCompile(`^ {0,3}((\*[ ]*){3,}|(-[ ]*){3,}|(_[ ]*){3,})$`)
+	fenceOpen    = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ ]*([^`\\s]*)")
+	bulletItem   = regexp.MustCompile(`^( {0,3})([-+*])( +|$)`)
+	orderedItem  = regexp.MustCompile(`^( {0,3})(\d{1,9})([.)])( +|$)`)
+	tableDivider = regexp.MustCompile(`^ {0,3}\|?[ ]*:?-+:?[ ]*(\|[ ]*:?-+:?[ ]*)*\|?[ ]*$`)
+	htmlBlock    = regexp.MustCompile(`^ {0,3}</?[a-zA-Z][a-zA-Z0-9-]*(\s|/?>|$)|^ {0,3}<!--`)
+	setextLine   = regexp.MustCompile(`^ {0,3}(=+|-+)[ ]*$`)
+)
+
+func blank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// listMarker parses a list item marker, returning whether the list is ordered, the
+// marker character, the start number and the width of the marker with its spacing
+func listMarker(line string) (ordered bool, marker string, start int, width int, ok bool) {
+	if m := bulletItem.FindStringSubmatch(line); m != nil {
+		if hrLine.MatchString(line) {
+			return false, "", 0, 0, false
+		}
+		return false, m[2], 0, len(m[0]), true
+	}
+	if m := orderedItem.FindStringSubmatch(line); m != nil {
+		n, _ := strconv.Atoi(m[2])
+		return true, m[3], n, len(m[0]), true
+	}
+	return false, "", 0, 0, false
+}
+
+// interrupts reports whether line starts a block that ends a paragraph
+func interrupts(line string) bool {
+	if atxHeading.MatchString(line) || hrLine.MatchString(line) || fenceOpen.MatchString(line) ||
+		htmlBlock.MatchString(line) || strings.HasPrefix(strings.TrimLeft(line, " "), ">") {
+		return true
+	}
+	// Only lists starting at 1, or bullets, interrupt a paragraph, with content
+	ordered, _, start, width, ok := listMarker(line)
+	return ok && width < len(line) && (!ordered || start == 1)
+}
+
+func renderBlocks(b *strings.Builder, lines []string) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case blank(line):
+			i++
+
+		case fenceOpen.MatchString(line):
+			m := fenceOpen.FindStringSubmatch(line)
+			indent, fence, lang := len(m[1]), m[2], m[3]
+			var code []string
+			i++
+			for ; i < len(lines); i++ {
+				trimmed := strings.TrimSpace(lines[i])
+				if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" && indentOf(lines[i]) < 4 {
+					i++
+					break
+				}
+				code = append(code, trimLeftUpTo(lines[i], indent))
+			}
+			writeCode(b, code, lang)
+
+		case indentOf(line) >= 4:
+			var code []string
+			for ; i < len(lines) && (indentOf(lines[i]) >= 4 || blank(lines[i])); i++ {
+				code = append(code, trimLeftUpTo(lines[i], 4))
+			}
+			for len(code) > 0 && blank(code[len(code)-1]) {
+				code = code[:len(code)-1]
+			}
+			writeCode(b, code, "")
+
+		case atxHeading.MatchString(line):
+			m := atxHeading.FindStringSubmatch(line)
+			level := strconv.Itoa(len(m[1]))
+			b.WriteString("<h" + level + ">" + renderInline(m[2]) + "</h" + level + ">\n")
+			i++
+
+		case hrLine.MatchString(line):
+			b.WriteString("<hr>\n")
+			i++
+
+		case strings.HasPrefix(strings.TrimLeft(line, " "), ">"):
+			var quoted []string
+			for ; i < len(lines) && !blank(lines[i]); i++ {
+				text := strings.TrimLeft(lines[i], " ")
+				if strings.HasPrefix(text, ">") {
+					text = strings.TrimPrefix(strings.TrimPrefix(text, ">"), " ")
+				} else if interrupts(lines[i]) {
+					break
+				}
+				quoted = append(quoted, text)
+			}
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, quoted)
+			b.WriteString("</blockquote>\n")
+
+		case htmlBlock.MatchString(line):
+			for ; i < len(lines) && !blank(lines[i]); i++ {
+				b.WriteString(lines[i] + "\n")
+			}
+
+		default:
+			if _, _, _, _, ok := listMarker(line); ok {
+				i = renderList(b, lines, i)
+				continue
+			}
+			if i+1 < len(lines) && strings.Contains(line, "|") && tableDivider.MatchString(lines[i+1]) {
+				if next, ok := renderTable(b, lines, i); ok {
+					i = next
+					continue
+				}
+			}
+			i = renderParagraph(b, lines, i)
+		}
+	}
+}
+
+func renderParagraph(b *strings.Builder, lines []string, i int) int {
+	var para []string
+	for ; i < len(lines) && !blank(lines[i]); i++ {
+		if len(para) > 0 {
+			if setextLine.MatchString(lines[i]) {
+				level := "1"
+				if strings.Contains(lines[i], "-") {
+					level = "2"
+				}
+				b.WriteString("<h" + level + ">" + renderInline(strings.Join(para, "\n")) + "</h" + level + ">\n")
+				return i + 1
+			}
+			if interrupts(lines[i]) {
+				break
+			}
+		}
+		para = append(para, strings.TrimLeft(lines[i], " "))
+	}
+	b.WriteString("<p>" + renderInline(strings.Join(para, "\n")) + "</p>\n")
+	return i
+}
+
+// renderList renders the list starting at lines[i] and returns the index after it
+func renderList(b *strings.Builder, lines []string, i int) int {
+	ordered, marker, start, _, _ := listMarker(lines[i])
+	type item struct {
+		lines []string
+	}
+	var items []item
+	tight := true
+	sawBlank := false
+
+	for i < len(lines) {
+		line := lines[i]
+		o, m, _, width, ok := listMarker(line)
+		if !ok || o != ordered || m != marker {
+			break
+		}
+		if sawBlank {
+			tight = false
+		}
+		sawBlank = false
+
+		// Content lines belong to the item while indented past the marker
+		contentIndent := width
+		if width == len(line) {
+			contentIndent = indentOf(line) + 2
+		}
+		itemLines := []string{line[min(width, len(line)):]}
+		i++
+		for i < len(lines) {
+			next := lines[i]
+			if blank(next) {
+				itemLines = append(itemLines, "")
+				i++
+				continue
+			}
+			if indentOf(next) >= contentIndent {
+				itemLines = append(itemLines, next[contentIndent:])
+				i++
+				continue
+			}
+			// A lazy continuation of the item's paragraph
+			last := itemLines[len(itemLines)-1]
+			if last != "" && !interrupts(next) {
+				if _, _, _, _, isItem := listMarker(next); !isItem {
+					itemLines = append(itemLines, strings.TrimLeft(next, " "))
+					i++
+					continue
+				}
+			}
+			break
+		}
+
+		// Trailing blank lines separate this item from the next one
+		for len(itemLines) > 1 && itemLines[len(itemLines)-1] == "" {
+			itemLines = itemLines[:len(itemLines)-1]
+			sawBlank = true
+		}
+		for _, l := range itemLines {
+			if l == "" {
+				tight = false
+			}
+		}
+		items = append(items, item{lines: itemLines})
+	}
+
+	if ordered {
+		if start != 1 {
+			b.WriteString(`<ol start="` + strconv.Itoa(start) + `">` + "\n")
+		} else {
+			b.WriteString("<ol>\n")
+		}
+	} else {
+		b.WriteString("<ul>\n")
+	}
+	for _, it := range items {
+		var inner strings.Builder
+		renderBlocks(&inner, it.lines)
+		content := inner.String()
+		if tight {
+			content = unwrapParagraphs(content)
+		}
+		b.WriteString("<li>" + strings.TrimSuffix(content, "\n") + "</li>\n")
+	}
+	if ordered {
+		b.WriteString("</ol>\n")
+	} else {
+		b.WriteString("</ul>\n")
+	}
+	return i
+}
+
+// unwrapParagraphs removes the <p> tags of a tight list item
+func unwrapParagraphs(s string) string {
+	s = strings.ReplaceAll(s, "<p>", "")
+	return strings.ReplaceAll(s, "</p>", "")
+}
+
+// renderTable renders a GitHub-style table, or reports false when the header and
+// divider do not match
+func renderTable(b *strings.Builder, lines []string, i int) (int, bool) {
+	header := splitRow(lines[i])
+	dividers := splitRow(lines[i+1])
+	if len(header) != len(dividers) {
+		return i, false
+	}
+	aligns := make([]string, len(dividers))
+	for j, d := range dividers {
+		left, right := strings.HasPrefix(d, ":"), strings.HasSuffix(d, ":")
+		switch {
+		case left && right:
+			aligns[j] = "center"
+		case right:
+			aligns[j] = "right"
+		case left:
+			aligns[j] = "left"
+		}
+	}
+
+	writeRow := func(cells []string, tag string) {
+		b.WriteString("<tr>")
+		for j := range aligns {
+			cell := ""
+			if j < len(cells) {
+				cell = cells[j]
+			}
+			open := "<" + tag
+			if aligns[j] != "" {
+				open += ` align="` + aligns[j] + `"`
+			}
+			b.WriteString(open + ">" + renderInline(cell) + "</" + tag + ">")
+		}
+		b.WriteString("</tr>\n")
+	}
+
+	b.WriteString("<table>\n<thead>\n")
+	writeRow(header, "th")
+	b.WriteString("</thead>\n")
+	i += 2
+	if i < len(lines) && !blank(lines[i]) && strings.Contains(lines[i], "|") {
+		b.WriteString("<tbody>\n")
+		for ; i < len(lines) && !blank(lines[i]) && strings.Contains(lines[i], "|"); i++ {
+			writeRow(splitRow(lines[i]), "td")
+		}
+		b.WriteString("</tbody>\n")
+	}
+	b.WriteString("</table>\n")
+	return i, true
+}
+
+// splitRow splits a table row on unescaped pipes
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	var cell strings.Builder
+	for j := 0; j < len(line); j++ {
+		switch {
+		case line[j] == '\\' && j+1 < len(line) && line[j+1] == '|':
+			cell.WriteByte('|')
+			j++
+		case line[j] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[j])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func writeCode(b *strings.Builder, code []string, lang string) {
+	b.WriteString("<pre><code")
+	if lang != "" {
+		b.WriteString(` class="language-` + html.EscapeString(lang) + `"`)
+	}
+	b.WriteString(">")
+	for _, line := range code {
+		b.WriteString(html.EscapeString(line) + "\n")
+	}
+	b.WriteString("</code></pre>\n")
+}
+
+// trimLeftUpTo removes up to n leading spaces
+func trimLeftUpTo(line string, n int) string {
+	return line[min(indentOf(line), n):]
+}
@@ -0,0 +1,78 @@
+package markup
+
+import "testing"
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"heading and paragraph", "# Title\n\nSome *text*.", "<h1>Title</h1>\n<p>Some <em>text</em>.</p>\n"},
+		{"setext heading", "Title\n---", "<h2>Title</h2>\n"},
+		{"emphasis and code", "**bold _em_** `a<b`", "<p><strong>bold <em>em</em></strong> <code>a&lt;b</code></p>\n"},
+		{"snake_case", "snake_case_name", "<p>snake_case_name</p>\n"},
+		{"link", `[docs](/docs "Read")`, `<p><a href="/docs" title="Read">docs</a></p>` + "\n"},
+		{"image", "![a *cat*](cat.png)", `<p><img src="cat.png" alt="a cat"></p>` + "\n"},
+		{"autolink", "<https://example.com>", `<p><a href="https://example.com">https://example.com</a></p>` + "\n"},
+		{"escapes", `\*not em\* & 1 < 2`, "<p>*not em* &amp; 1 &lt; 2</p>\n"},
+		{"hard break", "one  \ntwo", "<p>one<br>\ntwo</p>\n"},
+		{"rule", "a\n\n***", "<p>a</p>\n<hr>\n"},
+
+		{"nested bullets", "- a\n  - b\n    - c\n- d",
+			"<ul>\n<li>a\n<ul>\n<li>b\n<ul>\n<li>c</li>\n</ul></li>\n</ul></li>\n<li>d</li>\n</ul>\n"},
+		{"bullets in ordered list", "1. one\n2. two\n   - nested\n3. three",
+			"<ol>\n<li>one</li>\n<li>two\n<ul>\n<li>nested</li>\n</ul></li>\n<li>three</li>\n</ol>\n"},
+		{"ordered start", "3. three\n4. four", "<ol start=\"3\">\n<li>three</li>\n<li>four</li>\n</ol>\n"},
+		{"loose list", "- a\n\n- b", "<ul>\n<li><p>a</p></li>\n<li><p>b</p></li>\n</ul>\n"},
+		{"lazy continuation", "- a\nb", "<ul>\n<li>a\nb</li>\n</ul>\n"},
+		{"code in list item", "- item\n\n  ```\n  code\n  ```", "<ul>\n<li><p>item</p>\n<pre><code>code\n</code></pre></li>\n</ul>\n"},
+		{"list in quote", "> quote\n> - list", "<blockquote>\n<p>quote</p>\n<ul>\n<li>list</li>\n</ul>\n</blockquote>\n"},
+
+		{"fenced code", "```go\nfunc main() {\n\t<b>x</b>\n}\n```",
+			"<pre><code class=\"language-go\">func main() {\n    &lt;b&gt;x&lt;/b&gt;\n}\n</code></pre>\n"},
+		{"unclosed fence", "~~~\ncode", "<pre><code>code\n</code></pre>\n"},
+		{"longer fence", "````\n```\ninner\n```\n````", "<pre><code>```\ninner\n```\n</code></pre>\n"},
+		{"indented code", "    <script>x</script>\n\n    y", "<pre><code>&lt;script&gt;x&lt;/script&gt;\n\ny\n</code></pre>\n"},
+		{"table", "| a | b |\n|---|:-:|\n| 1 | 2 |",
+			"<table>\n<thead>\n<tr><th>a</th><th align=\"center\">b</th></tr>\n</thead>\n<tbody>\n<tr><td>1</td><td align=\"center\">2</td></tr>\n</tbody>\n</table>\n"},
+
+		{"raw html passes through", "<div>x</div>", "<div>x</div>\n"},
+		{"inline html passes through", "a <img src=x onerror=alert(1)> b", "<p>a <img src=x onerror=alert(1)> b</p>\n"},
+		{"link entities are decoded", "[x](&#106;avascript:alert(1))", `<p><a href="javascript:alert(1)">x</a></p>` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Markdown(tt.in); got != tt.want {
+				t.Errorf("Markdown(%q)\n got %q\nwant %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"script block", "<script>alert(1)</script>\n\ntext", "\n<p>text</p>\n"},
+		{"inline event handler", "a <img src=x onerror=alert(1)> b", `<p>a <img src="x"> b</p>` + "\n"},
+		{"javascript link", "[x](javascript:alert(1))", `<p><a rel="nofollow noopener">x</a></p>` + "\n"},
+		{"javascript link uppercase", "[x](JAVASCRIPT:alert(1))", `<p><a rel="nofollow noopener">x</a></p>` + "\n"},
+		{"javascript link entity", "[x](&#106;avascript:alert(1))", `<p><a rel="nofollow noopener">x</a></p>` + "\n"},
+		{"javascript autolink", "<javascript:alert(1)>", `<p><a rel="nofollow noopener">javascript:alert(1)</a></p>` + "\n"},
+		{"data image", "![x](data:image/svg+xml;base64,AAA)", `<p><img alt="x"></p>` + "\n"},
+		{"unclosed raw html", "<div><b>bold", "<div><b>bold\n</b></div>"},
+		{"safe link", "[x](https://example.com)", `<p><a href="https://example.com" rel="nofollow noopener">x</a></p>` + "\n"},
+		{"code is kept", "```html\n<script>x</script>\n```", "<pre><code class=\"language-html\">&lt;script&gt;x&lt;/script&gt;\n</code></pre>\n"},
+	}
+	p := UGCPolicy()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(p.Render(tt.in)); got != tt.want {
+				t.Errorf("Render(%q)\n got %q\nwant %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
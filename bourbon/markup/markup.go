@@ -0,0 +1,50 @@
+// Package markup renders user-supplied markdown and sanitizes HTML, so comments,
+// posts and other user content can be shown without opening the page to XSS:
+//
+//	html := markup.Render(post.Body)      // markdown -> sanitized template.HTML
+//	safe := markup.Sanitize(post.RawHTML) // HTML reduced to the allowlist
+//
+// Templates use the markdown and sanitize functions:
+//
+//	{{ markdown .Post.Body }}
+//
+// Both apply the default policy, UGCPolicy unless replaced with SetPolicy.
+package markup
+
+import (
+	"html/template"
+	"sync/atomic"
+)
+
+var defaultPolicy atomic.Pointer[Policy]
+
+func init() {
+	defaultPolicy.Store(UGCPolicy())
+}
+
+// SetPolicy replaces the policy used by Render, Sanitize and the template functions:
+//
+//	markup.SetPolicy(markup.UGCPolicy().AllowAttrs("span", "class"))
+func SetPolicy(p *Policy) {
+	defaultPolicy.Store(p)
+}
+
+// DefaultPolicy returns the policy used by Render and Sanitize
+func DefaultPolicy() *Policy {
+	return defaultPolicy.Load()
+}
+
+// Render converts markdown to HTML and sanitizes it with the default policy
+func Render(src string) template.HTML {
+	return DefaultPolicy().Render(src)
+}
+
+// Sanitize reduces HTML to what the default policy allows
+func Sanitize(s string) template.HTML {
+	return template.HTML(DefaultPolicy().Sanitize(s))
+}
+
+// Render converts markdown to HTML and sanitizes it with p
+func (p *Policy) Render(src string) template.HTML {
+	return template.HTML(p.Sanitize(Markdown(src)))
+}
@@ -0,0 +1,238 @@
+package markup
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Policy is an allowlist of the elements, attributes and URL schemes that survive
+// sanitizing. Disallowed elements are removed but their text is kept, except for
+// elements such as script and style, which are removed with their content.
+type Policy struct {
+	elements  map[string]map[string]bool // element -> allowed attributes
+	global    map[string]bool            // attributes allowed on every allowed element
+	schemes   map[string]bool
+	nofollow  bool
+	targetNew bool
+}
+
+// NewPolicy returns a policy that allows nothing; all markup is stripped and only
+// text remains. Add to it with AllowElements, AllowAttrs and AllowURLSchemes.
+func NewPolicy() *Policy {
+	return &Policy{
+		elements: make(map[string]map[string]bool),
+		global:   make(map[string]bool),
+		schemes:  map[string]bool{"http": true, "https": true, "mailto": true},
+	}
+}
+
+// UGCPolicy returns the policy for user-generated content, and the default: the
+// formatting markdown produces, plus tables, with links marked rel="nofollow"
+func UGCPolicy() *Policy {
+	p := NewPolicy().
+		AllowElements("p", "br", "hr", "h1", "h2", "h3", "h4", "h5", "h6",
+			"strong", "b", "em", "i", "u", "s", "del", "ins", "mark", "small", "sub", "sup",
+			"code", "pre", "kbd", "blockquote", "ul", "ol", "li", "dl", "dt", "dd",
+			"a", "img", "table", "thead", "tbody", "tfoot", "tr", "th", "td", "caption",
+			"abbr", "span", "div").
+		AllowAttrs("a", "href", "title").
+		AllowAttrs("img", "src", "alt", "title", "width", "height").
+		AllowAttrs("ol", "start").
+		AllowAttrs("th", "align").
+		AllowAttrs("td", "align").
+		AllowAttrs("abbr", "title").
+		AllowAttrs("code", "class")
+	p.nofollow = true
+	return p
+}
+
+// AllowElements allows elements, without attributes
+func (p *Policy) AllowElements(names ...string) *Policy {
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if p.elements[name] == nil {
+			p.elements[name] = make(map[string]bool)
+		}
+	}
+	return p
+}
+
+// AllowAttrs allows attributes on an element, which is allowed as well. The element
+// "*" allows the attributes on every allowed element.
+func (p *Policy) AllowAttrs(element string, attrs ...string) *Policy {
+	allowed := p.global
+	if element != "*" {
+		p.AllowElements(element)
+		allowed = p.elements[strings.ToLower(element)]
+	}
+	for _, attr := range attrs {
+		allowed[strings.ToLower(attr)] = true
+	}
+	return p
+}
+
+// AllowURLSchemes replaces the schemes allowed in href and src, by default http,
+// https and mailto. Relative URLs are always allowed.
+func (p *Policy) AllowURLSchemes(schemes ...string) *Policy {
+	p.schemes = make(map[string]bool, len(schemes))
+	for _, scheme := range schemes {
+		p.schemes[strings.ToLower(scheme)] = true
+	}
+	return p
+}
+
+// RequireNofollow sets rel="nofollow noopener" on links, so search engines do not
+// credit links posted by users
+func (p *Policy) RequireNofollow(on bool) *Policy {
+	p.nofollow = on
+	return p
+}
+
+// OpenLinksInNewTab sets target="_blank" on absolute links
+func (p *Policy) OpenLinksInNewTab(on bool) *Policy {
+	p.targetNew = on
+	return p
+}
+
+// Elements returns the allowed element names
+func (p *Policy) Elements() []string {
+	names := make([]string, 0, len(p.elements))
+	for name := range p.elements {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// dropContent lists elements that are removed together with their content
+var dropContent = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+	"noscript": true, "template": true, "textarea": true, "select": true, "svg": true, "math": true,
+}
+
+// voidElements have no end tag
+var voidElements = map[string]bool{
+	"area": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// urlAttrs hold URLs, whose scheme is checked
+var urlAttrs = map[string]bool{"href": true, "src": true, "cite": true, "action": true, "poster": true}
+
+// Sanitize returns s with everything the policy does not allow removed. Open
+// elements are closed, so the result can be embedded in a page safely.
+func (p *Policy) Sanitize(s string) string {
+	var b strings.Builder
+	var open []string
+	skip := ""
+	depth := 0
+
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		token := z.Token()
+
+		if skip != "" {
+			switch {
+			case tt == html.StartTagToken && token.Data == skip:
+				depth++
+			case tt == html.EndTagToken && token.Data == skip:
+				if depth--; depth == 0 {
+					skip = ""
+				}
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			b.WriteString(html.EscapeString(token.Data))
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if dropContent[token.Data] {
+				if tt == html.StartTagToken && !voidElements[token.Data] {
+					skip, depth = token.Data, 1
+				}
+				continue
+			}
+			attrs, ok := p.elements[token.Data]
+			if !ok {
+				continue
+			}
+			b.WriteString("<" + token.Data)
+			p.writeAttrs(&b, token, attrs)
+			b.WriteString(">")
+			if !voidElements[token.Data] && tt == html.StartTagToken {
+				open = append(open, token.Data)
+			}
+		case html.EndTagToken:
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] == token.Data {
+					for j := len(open) - 1; j >= i; j-- {
+						b.WriteString("</" + open[j] + ">")
+					}
+					open = open[:i]
+					break
+				}
+			}
+		}
+	}
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+	return b.String()
+}
+
+func (p *Policy) writeAttrs(b *strings.Builder, token html.Token, allowed map[string]bool) {
+	external := false
+	for _, attr := range token.Attr {
+		name := strings.ToLower(attr.Key)
+		if attr.Namespace != "" || !allowed[name] && !p.global[name] {
+			continue
+		}
+		if token.Data == "a" && p.nofollow && name == "rel" {
+			continue
+		}
+		value := attr.Val
+		if urlAttrs[name] {
+			var ok bool
+			if value, ok = p.safeURL(value); !ok {
+				continue
+			}
+			if u, err := url.Parse(value); err == nil && u.Scheme != "" {
+				external = true
+			}
+		}
+		b.WriteString(" " + name + `="` + html.EscapeString(value) + `"`)
+	}
+	if token.Data == "a" {
+		if p.nofollow {
+			b.WriteString(` rel="nofollow noopener"`)
+		}
+		if p.targetNew && external {
+			b.WriteString(` target="_blank"`)
+		}
+	}
+}
+
+// safeURL reports whether a URL is relative or uses an allowed scheme
+func (p *Policy) safeURL(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	if u.Scheme == "" {
+		// A colon before any slash would be read as a scheme by browsers
+		if i := strings.IndexAny(raw, ":/?#"); i >= 0 && raw[i] == ':' {
+			return "", false
+		}
+		return raw, true
+	}
+	return raw, p.schemes[strings.ToLower(u.Scheme)]
+}
@@ -0,0 +1,79 @@
+package markup
+
+import "testing"
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"script", `<script>alert(1)</script>hi`, `hi`},
+		{"script uppercase", `<SCRIPT>alert(1)</SCRIPT>hi`, `hi`},
+		{"nested script", `<p>a<script>x<script>y</script>z</script>b</p>`, `<p>azb</p>`},
+		{"script in svg", `<svg><script>alert(1)</script></svg>ok`, `ok`},
+		{"style", `<style>body{}</style>ok`, `ok`},
+		{"iframe", `<iframe src="https://example.com"></iframe>ok`, `ok`},
+		{"unknown element keeps text", `<marquee>hi</marquee>`, `hi`},
+		{"text is escaped", `1 < 2 & "x"`, `1 &lt; 2 &amp; &#34;x&#34;`},
+
+		{"onerror", `<img src="x.png" onerror="alert(1)">`, `<img src="x.png">`},
+		{"on attributes mixed case", `<a href="/x" onclick="alert(1)" OnMouseOver="y">x</a>`, `<a href="/x" rel="nofollow noopener">x</a>`},
+		{"unquoted onerror", `<img src=x onerror=alert(1)>`, `<img src="x">`},
+		{"style attribute", `<p style="background:url(x)">x</p>`, `<p>x</p>`},
+		{"user rel is replaced", `<a href="/x" rel="author">x</a>`, `<a href="/x" rel="nofollow noopener">x</a>`},
+
+		{"javascript url", `<a href="javascript:alert(1)">x</a>`, `<a rel="nofollow noopener">x</a>`},
+		{"javascript mixed case", `<a href="JaVaScRiPt:alert(1)">x</a>`, `<a rel="nofollow noopener">x</a>`},
+		{"javascript leading space", `<a href=" javascript:alert(1)">x</a>`, `<a rel="nofollow noopener">x</a>`},
+		{"javascript hex entity", `<a href="jav&#x61;script:alert(1)">x</a>`, `<a rel="nofollow noopener">x</a>`},
+		{"javascript decimal entities", `<a href="&#106;&#97;&#118;&#97;&#115;&#99;&#114;&#105;&#112;&#116;&#58;alert(1)">x</a>`, `<a rel="nofollow noopener">x</a>`},
+		{"javascript named colon", `<a href="javascript&colon;alert(1)">x</a>`, `<a rel="nofollow noopener">x</a>`},
+		{"javascript tab entity", `<a href="java&#9;script:alert(1)">x</a>`, `<a rel="nofollow noopener">x</a>`},
+		{"data url", `<img src="data:image/png;base64,AAAA">`, `<img>`},
+		{"data url uppercase", `<img src="DATA:text/html,<script>">`, `<img>`},
+		{"https url", `<a href="https://example.com">x</a>`, `<a href="https://example.com" rel="nofollow noopener">x</a>`},
+		{"relative url", `<a href="/path?q=1&amp;r=2">x</a>`, `<a href="/path?q=1&amp;r=2" rel="nofollow noopener">x</a>`},
+		{"mailto", `<a href="mailto:a@example.com">x</a>`, `<a href="mailto:a@example.com" rel="nofollow noopener">x</a>`},
+
+		{"unclosed element", `<b>bold`, `<b>bold</b>`},
+		{"misnested elements", `<p><em>x</p>after`, `<p><em>x</em></p>after`},
+		{"unclosed nesting", `<div><ul><li>a`, `<div><ul><li>a</li></ul></div>`},
+		{"stray end tag", `</b>stray`, `stray`},
+		{"unterminated tag", `<b onclick=x`, ``},
+		{"unclosed script", `ok<script>alert(1)`, `ok`},
+	}
+	p := UGCPolicy()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Sanitize(tt.in); got != tt.want {
+				t.Errorf("Sanitize(%q)\n got %q\nwant %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *Policy
+		in     string
+		want   string
+	}{
+		{"empty policy keeps text", NewPolicy(), `<p><b>hi</b></p>`, `hi`},
+		{"allowed attribute", NewPolicy().AllowAttrs("span", "class"), `<span class="x" id="y">hi</span>`, `<span class="x">hi</span>`},
+		{"global attribute", NewPolicy().AllowElements("p").AllowAttrs("*", "title"), `<p title="t">hi</p>`, `<p title="t">hi</p>`},
+		{"new tab for absolute links", UGCPolicy().OpenLinksInNewTab(true),
+			`<a href="https://example.com">x</a><a href="/local">y</a>`,
+			`<a href="https://example.com" rel="nofollow noopener" target="_blank">x</a><a href="/local" rel="nofollow noopener">y</a>`},
+		{"without nofollow", UGCPolicy().RequireNofollow(false), `<a href="/x">x</a>`, `<a href="/x">x</a>`},
+		{"custom schemes", UGCPolicy().AllowURLSchemes("https"), `<a href="mailto:a@example.com">x</a>`, `<a rel="nofollow noopener">x</a>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Sanitize(tt.in); got != tt.want {
+				t.Errorf("Sanitize(%q)\n got %q\nwant %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Metric types written in the exposition output
+const (
+	TypeGauge   = "gauge"
+	TypeCounter = "counter"
+)
+
+// Default is the registry the framework registers its metrics in
+var Default = NewRegistry()
+
+// metric is a value read when metrics are scraped
+type metric struct {
+	name  string
+	help  string
+	kind  string
+	value func() float64
+}
+
+// Registry holds metrics and serves them in the Prometheus text format
+type Registry struct {
+	mu      sync.RWMutex
+	metrics map[string]*metric
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]*metric)}
+}
+
+// GaugeFunc registers a gauge whose value is read from fn on every scrape.
+// Registering an existing name replaces it.
+func (r *Registry) GaugeFunc(name, help string, fn func() float64) {
+	r.register(name, help, TypeGauge, fn)
+}
+
+// CounterFunc registers a monotonically increasing value read from fn on every scrape
+func (r *Registry) CounterFunc(name, help string, fn func() float64) {
+	r.register(name, help, TypeCounter, fn)
+}
+
+// Unregister removes a metric
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.metrics, name)
+}
+
+// Names returns the registered metric names in sorted order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *Registry) register(name, help, kind string, fn func() float64) {
+	if !validName(name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", name))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics[name] = &metric{name: name, help: help, kind: kind, value: fn}
+}
+
+// Handler serves all metrics in the Prometheus text exposition format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+		r.mu.RLock()
+		metrics := make([]*metric, 0, len(r.metrics))
+		for _, m := range r.metrics {
+			metrics = append(metrics, m)
+		}
+		r.mu.RUnlock()
+		sort.Slice(metrics, func(i, j int) bool { return metrics[i].name < metrics[j].name })
+
+		var b strings.Builder
+		for _, m := range metrics {
+			if m.help != "" {
+				fmt.Fprintf(&b, "# HELP %s %s\n", m.name, strings.ReplaceAll(m.help, "\n", " "))
+			}
+			fmt.Fprintf(&b, "# TYPE %s %s\n", m.name, m.kind)
+			fmt.Fprintf(&b, "%s %s\n", m.name, strconv.FormatFloat(m.value(), 'g', -1, 64))
+		}
+		_, _ = w.Write([]byte(b.String()))
+	})
+}
+
+// validName reports whether name matches [a-zA-Z_:][a-zA-Z0-9_:]*
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
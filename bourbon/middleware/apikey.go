@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/auth"
+)
+
+// APIKeyHeader is the request header carrying the API key
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuth middleware authenticates requests by the X-API-Key header against the key store.
+// Requests must present a valid, unexpired key holding every listed scope.
+// When the key belongs to a user, that user becomes the authenticated user for the request.
+func APIKeyAuth(store *auth.APIKeyStore, scopes ...string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw := r.Header.Get(APIKeyHeader)
+			if raw == "" {
+				http.Error(w, "Missing API key", http.StatusUnauthorized)
+				return
+			}
+
+			key, err := store.Validate(raw)
+			if err != nil {
+				switch {
+				case errors.Is(err, auth.ErrInvalidAPIKey),
+					errors.Is(err, auth.ErrAPIKeyExpired),
+					errors.Is(err, auth.ErrAPIKeyRevoked):
+					http.Error(w, err.Error(), http.StatusUnauthorized)
+				default:
+					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				}
+				return
+			}
+
+			for _, scope := range scopes {
+				if !key.HasScope(scope) {
+					http.Error(w, "API key lacks scope: "+scope, http.StatusForbidden)
+					return
+				}
+			}
+
+			ctx := auth.WithAPIKey(r.Context(), key)
+			if key.UserID != nil {
+				ctx = auth.WithUserID(ctx, *key.UserID)
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
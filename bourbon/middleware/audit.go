@@ -0,0 +1,256 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/auth"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+)
+
+// RedactedValue replaces the value of redacted fields
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactFields are always redacted from audited bodies
+var DefaultRedactFields = []string{
+	"password", "password_confirmation", "token", "access_token", "refresh_token",
+	"secret", "api_key", "authorization", "card_number", "cvv", "ssn",
+}
+
+// AuditConfig configures the Audit middleware
+type AuditConfig struct {
+	Sink            logging.AuditSink // where records are written (required)
+	RedactFields    []string          // extra field names to redact, matched case-insensitively
+	MaxBodySize     int               // bytes captured per body (default 64KB)
+	CaptureResponse bool              // also capture response bodies
+	Methods         []string          // only audit these methods (default: all)
+	OnError         func(error)       // called when the sink fails
+	// TrustedProxies resolve the recorded client IP from X-Forwarded-For, see
+	// ParseCIDRs. An IP resolved by IPFilter or TrustedProxies is used first.
+	TrustedProxies []*net.IPNet
+}
+
+// Audit middleware records sanitized request (and optionally response) bodies to a sink.
+// Apply it globally, or to a single group with http.Adapt to toggle auditing per route group.
+func Audit(cfg AuditConfig) Middleware {
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = 64 * 1024
+	}
+	redact := NewRedactor(cfg.RedactFields...)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(cfg.Methods) > 0 && !containsFold(cfg.Methods, r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+
+			var reqBody []byte
+			if r.Body != nil {
+				reqBody, _ = io.ReadAll(io.LimitReader(r.Body, int64(cfg.MaxBodySize)))
+				r.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(reqBody), r.Body), r.Body}
+			}
+
+			recorder := &bodyRecorder{ResponseWriter: w, statusCode: http.StatusOK, limit: cfg.MaxBodySize, capture: cfg.CaptureResponse}
+			next.ServeHTTP(recorder, r)
+
+			record := &logging.RequestAudit{
+				Timestamp:   start,
+				Method:      r.Method,
+				Path:        r.URL.Path,
+				Query:       redact.Query(r.URL.RawQuery),
+				Status:      recorder.statusCode,
+				DurationMs:  time.Since(start).Milliseconds(),
+				IP:          clientIP(r, cfg.TrustedProxies),
+				RequestBody: redact.Body(reqBody, r.Header.Get("Content-Type")),
+			}
+			if cfg.CaptureResponse {
+				record.ResponseBody = redact.Body(recorder.body.Bytes(), w.Header().Get("Content-Type"))
+			}
+			if userID, ok := auth.UserIDFromContext(r.Context()); ok {
+				record.UserID = &userID
+			}
+
+			if err := cfg.Sink.WriteAudit(record); err != nil && cfg.OnError != nil {
+				cfg.OnError(err)
+			}
+		})
+	}
+}
+
+// UnparseableBody replaces JSON bodies that can't be decoded, e.g. when cut off
+// at MaxBodySize, since their fields can't be redacted reliably
+const UnparseableBody = "[body redacted: unparseable]"
+
+// Redactor masks sensitive fields in request and response payloads
+type Redactor struct {
+	fields map[string]bool
+	// quoted and assigned find `"field": value` and `field=value` in text
+	// bodies, whatever their format
+	quoted   *regexp.Regexp
+	assigned *regexp.Regexp
+}
+
+// NewRedactor creates a redactor for DefaultRedactFields plus the given names
+func NewRedactor(extra ...string) *Redactor {
+	fields := make(map[string]bool)
+	var names []string
+	for _, name := range append(append([]string{}, DefaultRedactFields...), extra...) {
+		fields[strings.ToLower(name)] = true
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	alternatives := strings.Join(names, "|")
+	return &Redactor{
+		fields: fields,
+		// A string value may be missing its closing quote when the body was cut off
+		quoted:   regexp.MustCompile(`(?i)("(?:` + alternatives + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]*)`),
+		assigned: regexp.MustCompile(`(?i)\b((?:` + alternatives + `)\s*[=:]\s*)[^&\s,;]*`),
+	}
+}
+
+// Body returns a sanitized copy of a body. JSON that can't be decoded is
+// replaced by UnparseableBody; other text is scrubbed of `"field": value` and
+// `field=value` pairs, and binary content is omitted. The body is never kept
+// as it was received.
+func (rd *Redactor) Body(body []byte, contentType string) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	switch {
+	case strings.Contains(contentType, "json"):
+		var data interface{}
+		if err := json.Unmarshal(body, &data); err != nil {
+			return UnparseableBody
+		}
+		out, err := json.Marshal(rd.value(data))
+		if err != nil {
+			return UnparseableBody
+		}
+		return string(out)
+	case strings.Contains(contentType, "application/x-www-form-urlencoded"):
+		return rd.Query(string(body))
+	case strings.HasPrefix(contentType, "multipart/"):
+		return "[multipart body omitted]"
+	case !utf8.Valid(body):
+		return "[binary body omitted]"
+	default:
+		return rd.Text(string(body))
+	}
+}
+
+// Text masks the values of `"field": value` and `field=value` pairs in free-form text
+func (rd *Redactor) Text(text string) string {
+	text = rd.quoted.ReplaceAllString(text, `${1}"`+RedactedValue+`"`)
+	return rd.assigned.ReplaceAllString(text, "${1}"+RedactedValue)
+}
+
+// Query returns a sanitized URL-encoded query or form string. Pairs that can't
+// be decoded are dropped.
+func (rd *Redactor) Query(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	// ParseQuery returns the pairs it could decode along with the first error
+	values, _ := url.ParseQuery(raw)
+	for key := range values {
+		if rd.fields[strings.ToLower(key)] {
+			values[key] = []string{RedactedValue}
+		}
+	}
+	return values.Encode()
+}
+
+// Headers returns a flattened copy of headers with sensitive values masked
+func (rd *Redactor) Headers(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for key, values := range h {
+		lower := strings.ToLower(key)
+		if rd.fields[lower] || rd.fields[strings.ReplaceAll(lower, "-", "_")] || lower == "cookie" || lower == "set-cookie" {
+			out[key] = RedactedValue
+			continue
+		}
+		out[key] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+func (rd *Redactor) value(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for key, val := range t {
+			if rd.fields[strings.ToLower(key)] {
+				t[key] = RedactedValue
+			} else {
+				t[key] = rd.value(val)
+			}
+		}
+		return t
+	case []interface{}:
+		for i, val := range t {
+			t[i] = rd.value(val)
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+// bodyRecorder captures the status code and, optionally, the first bytes of the response body
+type bodyRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+	limit      int
+	capture    bool
+}
+
+func (br *bodyRecorder) WriteHeader(code int) {
+	br.statusCode = code
+	br.ResponseWriter.WriteHeader(code)
+}
+
+// Flush lets streaming handlers, such as server-sent events, flush through the recorder
+func (br *bodyRecorder) Flush() {
+	if f, ok := br.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap gives http.ResponseController the underlying writer
+func (br *bodyRecorder) Unwrap() http.ResponseWriter {
+	return br.ResponseWriter
+}
+
+func (br *bodyRecorder) Write(b []byte) (int, error) {
+	if br.capture && br.body.Len() < br.limit {
+		remaining := br.limit - br.body.Len()
+		if remaining > len(b) {
+			remaining = len(b)
+		}
+		br.body.Write(b[:remaining])
+	}
+	return br.ResponseWriter.Write(b)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
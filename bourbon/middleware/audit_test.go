@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+)
+
+func TestRedactorBody(t *testing.T) {
+	rd := NewRedactor("iban")
+
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		want        string
+	}{
+		{
+			name:        "json",
+			body:        `{"email":"a@example.com","password":"hunter2","card":{"card_number":"4111"},"items":[{"IBAN":"DE89"}]}`,
+			contentType: "application/json",
+			want:        `{"card":{"card_number":"[REDACTED]"},"email":"a@example.com","items":[{"IBAN":"[REDACTED]"}],"password":"[REDACTED]"}`,
+		},
+		{
+			name:        "truncated json",
+			body:        `{"email":"a@example.com","password":"hun`,
+			contentType: "application/json; charset=utf-8",
+			want:        UnparseableBody,
+		},
+		{
+			name:        "unparseable json",
+			body:        `password=hunter2`,
+			contentType: "application/json",
+			want:        UnparseableBody,
+		},
+		{
+			name:        "form",
+			body:        "email=a%40example.com&password=hunter2",
+			contentType: "application/x-www-form-urlencoded",
+			want:        "email=a%40example.com&password=%5BREDACTED%5D",
+		},
+		{
+			name:        "form with undecodable pair",
+			body:        "token=%zz&email=a",
+			contentType: "application/x-www-form-urlencoded",
+			want:        "email=a",
+		},
+		{
+			name:        "text",
+			body:        `user=bob password=hunter2 {"token": "abc", "api_key":42}`,
+			contentType: "text/plain",
+			want:        `user=bob password=[REDACTED] {"token": "[REDACTED]", "api_key":"[REDACTED]"}`,
+		},
+		{
+			name:        "text cut off in a quoted value",
+			body:        `<xml>{"secret": "abc`,
+			contentType: "application/xml",
+			want:        `<xml>{"secret": "[REDACTED]"`,
+		},
+		{
+			name:        "multipart",
+			body:        "--x\r\npassword\r\n--x--",
+			contentType: "multipart/form-data; boundary=x",
+			want:        "[multipart body omitted]",
+		},
+		{
+			name:        "binary",
+			body:        "\xff\xfepassword=hunter2",
+			contentType: "application/octet-stream",
+			want:        "[binary body omitted]",
+		},
+		{
+			name:        "empty",
+			contentType: "application/json",
+			want:        "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rd.Body([]byte(tt.body), tt.contentType)
+			if got != tt.want {
+				t.Errorf("Body() = %q, want %q", got, tt.want)
+			}
+			if strings.Contains(got, "hunter2") {
+				t.Errorf("Body() leaked a secret: %q", got)
+			}
+		})
+	}
+}
+
+type auditRecords struct {
+	mu      sync.Mutex
+	records []*logging.RequestAudit
+}
+
+func (a *auditRecords) WriteAudit(record *logging.RequestAudit) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.records = append(a.records, record)
+	return nil
+}
+
+func (a *auditRecords) all() []*logging.RequestAudit {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.records
+}
+
+func TestAuditClientIP(t *testing.T) {
+	trusted, _ := ParseCIDRs([]string{"10.0.0.0/8"})
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{"direct", "203.0.113.5:4000", "", "203.0.113.5"},
+		{"untrusted peer", "203.0.113.5:4000", "198.51.100.7", "203.0.113.5"},
+		{"trusted proxy", "10.0.0.2:4000", "198.51.100.7", "198.51.100.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var records auditRecords
+			h := Audit(AuditConfig{Sink: &records, TrustedProxies: trusted})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+			if got := records.all(); len(got) != 1 || got[0].IP != tt.want {
+				t.Errorf("recorded %v, want IP %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditStreaming(t *testing.T) {
+	var records auditRecords
+	h := Audit(AuditConfig{Sink: &records, CaptureResponse: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: 1\n\n"))
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Flush() = %v", err)
+		}
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(time.Minute)); err != nil {
+			t.Errorf("SetWriteDeadline() = %v", err)
+		}
+	}))
+
+	server := httptest.NewServer(h)
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if got := records.all(); len(got) != 1 || got[0].ResponseBody != "data: 1\n\n" {
+		t.Errorf("recorded %v", got)
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/auth"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+	"go.uber.org/zap"
+)
+
+// Authorization middleware attaches the authorizer to every request so that
+// RequirePermission and ctx.Can can resolve permissions
+func Authorization(authorizer *auth.Authorizer) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(auth.WithAuthorizer(r.Context(), authorizer)))
+		})
+	}
+}
+
+// RequirePermission middleware rejects requests whose authenticated user lacks the permission.
+// Unauthenticated requests receive 401 and authenticated users without the permission receive 403.
+// When the permissions can't be loaded the request fails with 500, so the outage
+// is reported as a server error rather than passing for a denial.
+func RequirePermission(permission string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := auth.UserIDFromContext(r.Context()); !ok {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			allowed, err := auth.Check(r.Context(), permission)
+			if err != nil {
+				if logger, ok := logging.FromContext(r.Context()); ok {
+					logger.Error("Failed to check permission", zap.String("permission", permission), zap.Error(err))
+				}
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+			if !allowed {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package middleware
+
+import (
+	"container/list"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BasicAuthFunc validates a username and password
+type BasicAuthFunc func(username, password string) bool
+
+// BasicAuth middleware protects routes with HTTP Basic authentication.
+// Intended for internal endpoints such as metrics, admin or staging sites.
+func BasicAuth(realm string, validate BasicAuthFunc) Middleware {
+	challenge := fmt.Sprintf(`Basic realm=%q, charset="UTF-8"`, realm)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			username, password, ok := r.BasicAuth()
+			if !ok || !validate(username, password) {
+				w.Header().Set("WWW-Authenticate", challenge)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// basicAuthNoUser is compared against for unknown users, so they take as long to
+// reject as a wrong password
+var basicAuthNoUser = sha256.Sum256([]byte("bourbon: unknown user"))
+
+// BasicAuthUsers returns a BasicAuthFunc checking against a fixed username/password map.
+// Passwords are compared as SHA-256 hashes in constant time, so neither their
+// content nor their length shows in the response time.
+func BasicAuthUsers(users map[string]string) BasicAuthFunc {
+	hashes := make(map[string][sha256.Size]byte, len(users))
+	for username, password := range users {
+		hashes[username] = sha256.Sum256([]byte(password))
+	}
+	return func(username, password string) bool {
+		expected, ok := hashes[username]
+		if !ok {
+			expected = basicAuthNoUser
+		}
+		given := sha256.Sum256([]byte(password))
+		return subtle.ConstantTimeCompare(given[:], expected[:]) == 1 && ok
+	}
+}
+
+// DigestSecretFunc returns the plaintext password for a user, or false if the user is unknown
+type DigestSecretFunc func(username string) (string, bool)
+
+// digestNonceTTL is how long a server nonce remains valid
+const digestNonceTTL = 5 * time.Minute
+
+// digestMaxNonces bounds the nonces a DigestAuth middleware tracks request
+// counts for; the counts first used longest ago are dropped first
+const digestMaxNonces = 10000
+
+// digestNonces issues nonces signed with a random key, so challenging a client
+// keeps no state. The last request count (nc) is tracked per nonce from its first
+// successful use, so a captured request can't be replayed.
+type digestNonces struct {
+	key    []byte
+	mu     sync.Mutex
+	counts map[string]*list.Element // of order
+	order  *list.List               // *digestCount, in the order of first use
+	// floor is the latest issue time of a nonce whose count was dropped before it
+	// expired; nonces issued until then are treated as stale
+	floor time.Time
+}
+
+type digestCount struct {
+	nonce  string
+	issued time.Time
+	nc     uint64
+}
+
+// digestNonceState is the outcome of using a nonce
+type digestNonceState int
+
+const (
+	digestNonceValid digestNonceState = iota
+	// digestNonceStale nonces were ours but expired; the client may retry with a
+	// new nonce without asking the user again
+	digestNonceStale
+	digestNonceInvalid
+)
+
+func newDigestNonces() *digestNonces {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	return &digestNonces{key: key, counts: make(map[string]*list.Element), order: list.New()}
+}
+
+// issue returns a new nonce: the issue time and random bytes, followed by their MAC
+func (dn *digestNonces) issue() string {
+	b := make([]byte, 16, 16+sha256.Size)
+	binary.BigEndian.PutUint64(b, uint64(time.Now().UnixNano()))
+	_, _ = rand.Read(b[8:])
+	return hex.EncodeToString(append(b, dn.sign(b)...))
+}
+
+func (dn *digestNonces) sign(b []byte) []byte {
+	mac := hmac.New(sha256.New, dn.key)
+	mac.Write(b)
+	return mac.Sum(nil)
+}
+
+// issued returns when nonce was issued, or false if it wasn't issued by dn
+func (dn *digestNonces) issued(nonce string) (time.Time, bool) {
+	b, err := hex.DecodeString(nonce)
+	if err != nil || len(b) != 16+sha256.Size || !hmac.Equal(b[16:], dn.sign(b[:16])) {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(b))), true
+}
+
+// use records a request count for nonce. Counts that don't increase are invalid,
+// as are nonces that weren't issued by dn.
+func (dn *digestNonces) use(nonce, nc string) digestNonceState {
+	count, err := strconv.ParseUint(nc, 16, 64)
+	if err != nil {
+		return digestNonceInvalid
+	}
+	issued, ok := dn.issued(nonce)
+	if !ok {
+		return digestNonceInvalid
+	}
+	if time.Since(issued) >= digestNonceTTL {
+		return digestNonceStale
+	}
+
+	dn.mu.Lock()
+	defer dn.mu.Unlock()
+	if elem, ok := dn.counts[nonce]; ok {
+		entry := elem.Value.(*digestCount)
+		if count <= entry.nc {
+			return digestNonceInvalid
+		}
+		entry.nc = count
+		return digestNonceValid
+	}
+	if !issued.After(dn.floor) {
+		return digestNonceStale
+	}
+	dn.evict()
+	dn.counts[nonce] = dn.order.PushBack(&digestCount{nonce: nonce, issued: issued, nc: count})
+	return digestNonceValid
+}
+
+// evict drops the counts of expired nonces from the front of the order, and the
+// oldest counts while there are too many; dn.mu must be held
+func (dn *digestNonces) evict() {
+	for front := dn.order.Front(); front != nil; front = dn.order.Front() {
+		entry := front.Value.(*digestCount)
+		expired := time.Since(entry.issued) >= digestNonceTTL
+		if !expired && dn.order.Len() < digestMaxNonces {
+			return
+		}
+		if !expired && entry.issued.After(dn.floor) {
+			dn.floor = entry.issued
+		}
+		dn.order.Remove(front)
+		delete(dn.counts, entry.nonce)
+	}
+}
+
+// DigestAuth middleware protects routes with HTTP Digest authentication (RFC 7616, MD5, qop=auth).
+// Nonces are signed and valid for 5 minutes; the last request count (nc) used with
+// each is kept in memory, and a request repeating a count, such as a replayed
+// capture, is challenged again. An expired nonce is challenged with stale=true, so
+// clients retry without asking for the password. Behind a load balancer, route
+// each client to one instance, since the counts aren't shared.
+func DigestAuth(realm string, secret DigestSecretFunc) Middleware {
+	nonces := newDigestNonces()
+
+	challenge := func(w http.ResponseWriter, stale bool) {
+		value := fmt.Sprintf(`Digest realm=%q, qop="auth", algorithm=MD5, nonce=%q`, realm, nonces.issue())
+		if stale {
+			value += ", stale=true"
+		}
+		w.Header().Set("WWW-Authenticate", value)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if !strings.HasPrefix(header, "Digest ") {
+				challenge(w, false)
+				return
+			}
+
+			// qop=auth is required: without it there is no request count to stop replays
+			params := parseDigestParams(strings.TrimPrefix(header, "Digest "))
+			if params["realm"] != realm || params["uri"] != r.URL.RequestURI() || params["qop"] != "auth" {
+				challenge(w, false)
+				return
+			}
+
+			password, ok := secret(params["username"])
+			if !ok {
+				challenge(w, false)
+				return
+			}
+
+			ha1 := md5Hex(params["username"] + ":" + realm + ":" + password)
+			ha2 := md5Hex(r.Method + ":" + params["uri"])
+			expected := md5Hex(strings.Join([]string{ha1, params["nonce"], params["nc"], params["cnonce"], "auth", ha2}, ":"))
+			if subtle.ConstantTimeCompare([]byte(expected), []byte(params["response"])) != 1 {
+				challenge(w, false)
+				return
+			}
+
+			// Only a correct response uses up its count, so guesses can't block a client
+			if state := nonces.use(params["nonce"], params["nc"]); state != digestNonceValid {
+				challenge(w, state == digestNonceStale)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// parseDigestParams parses the comma-separated key=value pairs of a Digest Authorization header
+func parseDigestParams(s string) map[string]string {
+	params := make(map[string]string)
+	for _, part := range splitDigestParams(s) {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		params[strings.ToLower(key)] = strings.Trim(value, `"`)
+	}
+	return params
+}
+
+// splitDigestParams splits on commas that are not inside quoted values
+func splitDigestParams(s string) []string {
+	var parts []string
+	inQuotes := false
+	start := 0
+	for i, c := range s {
+		switch c {
+		case '"':
+			inQuotes = !inQuotes
+		case ',':
+			if !inQuotes {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDigestAuthReplay(t *testing.T) {
+	h := DigestAuth("Metrics", func(user string) (string, bool) {
+		return "s3cret", user == "ops"
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status without credentials = %d, want 401", rec.Code)
+	}
+	nonce := parseDigestParams(strings.TrimPrefix(rec.Header().Get("WWW-Authenticate"), "Digest "))["nonce"]
+
+	authorization := func(nonce, nc, qop string) string {
+		ha1 := md5Hex("ops:Metrics:s3cret")
+		ha2 := md5Hex("GET:/metrics")
+		response := md5Hex(strings.Join([]string{ha1, nonce, nc, "abc", qop, ha2}, ":"))
+		return fmt.Sprintf(`Digest username="ops", realm="Metrics", nonce=%q, uri="/metrics", qop=%s, nc=%s, cnonce="abc", response=%q`,
+			nonce, qop, nc, response)
+	}
+	status := func(header string) int {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.Header.Set("Authorization", header)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"first use", authorization(nonce, "00000001", "auth"), http.StatusOK},
+		{"replay", authorization(nonce, "00000001", "auth"), http.StatusUnauthorized},
+		{"next count", authorization(nonce, "00000002", "auth"), http.StatusOK},
+		{"lower count", authorization(nonce, "00000001", "auth"), http.StatusUnauthorized},
+		{"unknown nonce", authorization("0123456789abcdef", "00000001", "auth"), http.StatusUnauthorized},
+		{"without qop", authorization(nonce, "00000003", ""), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		if got := status(tt.header); got != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+// oldNonce returns a nonce signed by dn as if it had been issued at issued
+func oldNonce(dn *digestNonces, issued time.Time) string {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b, uint64(issued.UnixNano()))
+	return hex.EncodeToString(append(b, dn.sign(b)...))
+}
+
+func TestDigestNonces(t *testing.T) {
+	dn := newDigestNonces()
+	nonce := dn.issue()
+	if len(dn.counts) != 0 {
+		t.Fatal("issuing a nonce kept state")
+	}
+
+	tampered := []byte(nonce)
+	tampered[0] ^= 1
+	tests := []struct {
+		name  string
+		nonce string
+		nc    string
+		want  digestNonceState
+	}{
+		{"first use", nonce, "00000001", digestNonceValid},
+		{"replay", nonce, "00000001", digestNonceInvalid},
+		{"next count", nonce, "00000002", digestNonceValid},
+		{"bad count", nonce, "zz", digestNonceInvalid},
+		{"tampered", string(tampered), "00000001", digestNonceInvalid},
+		{"expired", oldNonce(dn, time.Now().Add(-digestNonceTTL-time.Second)), "00000001", digestNonceStale},
+	}
+	for _, tt := range tests {
+		if got := dn.use(tt.nonce, tt.nc); got != tt.want {
+			t.Errorf("%s: use() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDigestNoncesEviction(t *testing.T) {
+	dn := newDigestNonces()
+	first := dn.issue()
+	dn.use(first, "1")
+	for i := 0; i < digestMaxNonces; i++ {
+		dn.use(dn.issue(), "1")
+	}
+	if len(dn.counts) > digestMaxNonces {
+		t.Fatalf("%d counts kept, want at most %d", len(dn.counts), digestMaxNonces)
+	}
+	// The dropped count must not make its nonce usable again
+	if got := dn.use(first, "1"); got != digestNonceStale {
+		t.Errorf("replay of an evicted nonce = %v, want stale", got)
+	}
+	if got := dn.use(dn.issue(), "1"); got != digestNonceValid {
+		t.Errorf("new nonce after eviction = %v, want valid", got)
+	}
+}
+
+func TestBasicAuthUsers(t *testing.T) {
+	validate := BasicAuthUsers(map[string]string{"ops": "s3cret"})
+	tests := []struct {
+		username, password string
+		want               bool
+	}{
+		{"ops", "s3cret", true},
+		{"ops", "wrong", false},
+		{"ops", "", false},
+		{"nobody", "s3cret", false},
+		{"nobody", "bourbon: unknown user", false},
+	}
+	for _, tt := range tests {
+		if got := validate(tt.username, tt.password); got != tt.want {
+			t.Errorf("validate(%q, %q) = %v, want %v", tt.username, tt.password, got, tt.want)
+		}
+	}
+}
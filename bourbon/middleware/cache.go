@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CacheControl middleware sets a public Cache-Control max-age on GET and HEAD responses
+// unless the handler has already set its own Cache-Control header
+func CacheControl(maxAge time.Duration) Middleware {
+	return cacheControl(fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+}
+
+// PrivateCacheControl middleware is CacheControl for per-user responses: browsers
+// may keep them, but shared caches such as CDNs and proxies may not
+func PrivateCacheControl(maxAge time.Duration) Middleware {
+	return cacheControl(fmt.Sprintf("private, max-age=%d", int(maxAge.Seconds())))
+}
+
+func cacheControl(value string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				w = &cacheControlWriter{ResponseWriter: w, value: value}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// cacheControlWriter adds the Cache-Control header just before the response is written
+type cacheControlWriter struct {
+	http.ResponseWriter
+	value       string
+	wroteHeader bool
+}
+
+func (cw *cacheControlWriter) WriteHeader(code int) {
+	if !cw.wroteHeader {
+		cw.wroteHeader = true
+		if code < 400 && cw.Header().Get("Cache-Control") == "" {
+			cw.Header().Set("Cache-Control", cw.value)
+		}
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *cacheControlWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	return cw.ResponseWriter.Write(b)
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// CoalesceHeader is set to "shared" on responses that were fanned out from another request
+const CoalesceHeader = "X-Coalesced"
+
+// CoalesceKeyFunc returns the key identifying identical requests, or "" to bypass coalescing
+type CoalesceKeyFunc func(r *http.Request) string
+
+// negotiatedHeaders are the request headers responses are commonly negotiated
+// on; requests share a response only when they send the same values
+var negotiatedHeaders = []string{"Accept", "Accept-Encoding", "Accept-Language"}
+
+// negotiationKey appends the negotiated headers of r to a request key
+func negotiationKey(key string, r *http.Request) string {
+	var b strings.Builder
+	b.WriteString(key)
+	for _, name := range negotiatedHeaders {
+		b.WriteString("\n" + name + ": " + strings.Join(r.Header.Values(name), ", "))
+	}
+	return b.String()
+}
+
+// DefaultCoalesceKey keys GET and HEAD requests by method, path, query and the
+// Accept, Accept-Encoding and Accept-Language headers, so a response negotiated
+// for one client, e.g. gzipped, isn't sent to another. Requests carrying
+// credentials are never coalesced, since their responses may be user-specific.
+func DefaultCoalesceKey(r *http.Request) string {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return ""
+	}
+	if r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != "" {
+		return ""
+	}
+	return negotiationKey(r.Method+" "+r.URL.RequestURI(), r)
+}
+
+// Coalesce middleware deduplicates concurrent identical requests: the first request runs
+// the handler while the others wait and receive a copy of its response.
+// Pass nil to use DefaultCoalesceKey.
+func Coalesce(keyFunc CoalesceKeyFunc) Middleware {
+	if keyFunc == nil {
+		keyFunc = DefaultCoalesceKey
+	}
+	group := &flightGroup{calls: make(map[string]*flightCall)}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := keyFunc(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			resp, shared := group.do(key, func() *recordedResponse {
+				rec := newRecordedResponse()
+				next.ServeHTTP(rec, r)
+				return rec
+			})
+			if shared {
+				w.Header().Set(CoalesceHeader, "shared")
+			}
+			resp.writeTo(w)
+		})
+	}
+}
+
+// flightGroup is a minimal singleflight: concurrent calls with the same key share one execution
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	wg   sync.WaitGroup
+	resp *recordedResponse
+}
+
+func (g *flightGroup) do(key string, fn func() *recordedResponse) (*recordedResponse, bool) {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.resp, true
+	}
+	call := &flightCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		// Always release waiters, even if the handler panics
+		if call.resp == nil {
+			call.resp = &recordedResponse{header: http.Header{}, statusCode: http.StatusInternalServerError}
+		}
+		call.wg.Done()
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+	}()
+
+	call.resp = fn()
+	return call.resp, false
+}
+
+// recordedResponse buffers a complete response so it can be replayed to several writers
+type recordedResponse struct {
+	header      http.Header
+	statusCode  int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func newRecordedResponse() *recordedResponse {
+	return &recordedResponse{header: http.Header{}, statusCode: http.StatusOK}
+}
+
+func (rr *recordedResponse) Header() http.Header {
+	return rr.header
+}
+
+func (rr *recordedResponse) WriteHeader(code int) {
+	if !rr.wroteHeader {
+		rr.statusCode = code
+		rr.wroteHeader = true
+	}
+}
+
+func (rr *recordedResponse) Write(b []byte) (int, error) {
+	rr.wroteHeader = true
+	return rr.body.Write(b)
+}
+
+// writeTo copies the recorded headers, status and body to w
+func (rr *recordedResponse) writeTo(w http.ResponseWriter) {
+	for key, values := range rr.header {
+		w.Header()[key] = append([]string(nil), values...)
+	}
+	w.WriteHeader(rr.statusCode)
+	_, _ = w.Write(rr.body.Bytes())
+}
@@ -0,0 +1,230 @@
+package middleware
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// debugSourceContext is the number of source lines shown on each side of a frame's line
+const debugSourceContext = 5
+
+// debugSourceFrames limits how many application frames get source excerpts
+const debugSourceFrames = 10
+
+type debugFrame struct {
+	Function string
+	File     string
+	Line     int
+	Stdlib   bool
+	Source   []debugSourceLine
+}
+
+type debugSourceLine struct {
+	Number  int
+	Text    string
+	Current bool
+}
+
+type debugHeader struct {
+	Name  string
+	Value string
+}
+
+type debugPageData struct {
+	Error     string
+	Method    string
+	Path      string
+	Query     string
+	Pattern   string
+	Remote    string
+	GoVersion string
+	Frames    []debugFrame
+	Headers   []debugHeader
+	Routes    []string
+}
+
+// renderDebugPage writes a detailed HTML error page for a recovered panic
+func renderDebugPage(w http.ResponseWriter, r *http.Request, recovered interface{}, stack string, routes []string) {
+	redact := NewRedactor()
+	var headers []debugHeader
+	for name, value := range redact.Headers(r.Header) {
+		headers = append(headers, debugHeader{Name: name, Value: value})
+	}
+	sort.Slice(headers, func(i, j int) bool { return headers[i].Name < headers[j].Name })
+
+	data := debugPageData{
+		Error:     fmt.Sprint(recovered),
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Query:     redact.Query(r.URL.RawQuery),
+		Pattern:   r.Pattern,
+		Remote:    r.RemoteAddr,
+		GoVersion: runtime.Version(),
+		Frames:    parseStack(stack),
+		Headers:   headers,
+		Routes:    routes,
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusInternalServerError)
+	if err := debugPageTemplate.Execute(w, data); err != nil {
+		fmt.Fprintf(w, "panic: %s\n\n%s", data.Error, stack)
+	}
+}
+
+// parseStack converts a debug.Stack() trace into frames, starting at the function that panicked
+func parseStack(stack string) []debugFrame {
+	lines := strings.Split(stack, "\n")
+	goroot := runtime.GOROOT()
+
+	var frames []debugFrame
+	for i := 1; i+1 < len(lines); i += 2 {
+		function := strings.TrimSpace(lines[i])
+		location := strings.TrimSpace(lines[i+1])
+		if function == "" || location == "" {
+			continue
+		}
+		if idx := strings.LastIndex(location, " +0x"); idx >= 0 {
+			location = location[:idx]
+		}
+		file, lineStr, ok := cutLast(location, ":")
+		if !ok {
+			continue
+		}
+		line, _ := strconv.Atoi(lineStr)
+
+		// Everything up to and including the runtime panic frame belongs to the recovery machinery
+		if strings.HasPrefix(function, "panic(") {
+			frames = frames[:0]
+			continue
+		}
+
+		frames = append(frames, debugFrame{
+			Function: function,
+			File:     file,
+			Line:     line,
+			Stdlib:   goroot != "" && strings.HasPrefix(file, goroot),
+		})
+	}
+
+	excerpts := 0
+	for i := range frames {
+		if frames[i].Stdlib || excerpts >= debugSourceFrames {
+			continue
+		}
+		frames[i].Source = readSourceLines(frames[i].File, frames[i].Line)
+		if frames[i].Source != nil {
+			excerpts++
+		}
+	}
+	return frames
+}
+
+// readSourceLines returns the lines around line in file, or nil if the file is unreadable
+func readSourceLines(file string, line int) []debugSourceLine {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var out []debugSourceLine
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		if n < line-debugSourceContext {
+			continue
+		}
+		if n > line+debugSourceContext {
+			break
+		}
+		out = append(out, debugSourceLine{Number: n, Text: scanner.Text(), Current: n == line})
+	}
+	return out
+}
+
+func cutLast(s, sep string) (string, string, bool) {
+	idx := strings.LastIndex(s, sep)
+	if idx < 0 {
+		return s, "", false
+	}
+	return s[:idx], s[idx+len(sep):], true
+}
+
+var debugPageTemplate = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Error}} at {{.Path}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 0; color: #222; background: #fafafa; }
+header { background: #b32d2e; color: #fff; padding: 20px 32px; }
+header h1 { margin: 0 0 8px; font-size: 22px; }
+header p { margin: 0; opacity: .9; }
+section { padding: 16px 32px; border-bottom: 1px solid #e5e5e5; }
+h2 { font-size: 16px; margin: 8px 0 12px; }
+table { border-collapse: collapse; width: 100%; font-size: 13px; }
+td { padding: 4px 8px; vertical-align: top; border-bottom: 1px solid #eee; }
+td:first-child { font-weight: 600; width: 220px; }
+.frame { margin-bottom: 12px; background: #fff; border: 1px solid #e5e5e5; border-radius: 4px; }
+.frame.stdlib { opacity: .6; }
+.frame .fn { padding: 6px 10px; font-family: monospace; font-size: 13px; }
+.frame .loc { color: #666; }
+pre { margin: 0; padding: 6px 0; background: #272822; color: #f8f8f2; font-size: 12px; overflow-x: auto; }
+pre span { display: block; padding: 0 10px; }
+pre span.current { background: #75262a; }
+pre em { color: #888; font-style: normal; display: inline-block; width: 48px; }
+code { font-size: 13px; }
+</style>
+</head>
+<body>
+<header>
+<h1>panic: {{.Error}}</h1>
+<p>{{.Method}} {{.Path}}{{if .Query}}?{{.Query}}{{end}}</p>
+</header>
+<section>
+<h2>Request</h2>
+<table>
+<tr><td>Method</td><td>{{.Method}}</td></tr>
+<tr><td>Path</td><td>{{.Path}}</td></tr>
+{{if .Query}}<tr><td>Query</td><td>{{.Query}}</td></tr>{{end}}
+<tr><td>Matched route</td><td>{{if .Pattern}}<code>{{.Pattern}}</code>{{else}}-{{end}}</td></tr>
+<tr><td>Remote address</td><td>{{.Remote}}</td></tr>
+<tr><td>Go version</td><td>{{.GoVersion}}</td></tr>
+</table>
+</section>
+<section>
+<h2>Traceback</h2>
+{{range .Frames}}
+<div class="frame{{if .Stdlib}} stdlib{{end}}">
+<div class="fn">{{.Function}}<br><span class="loc">{{.File}}:{{.Line}}</span></div>
+{{if .Source}}<pre>{{range .Source}}<span{{if .Current}} class="current"{{end}}><em>{{.Number}}</em>{{.Text}}</span>{{end}}</pre>{{end}}
+</div>
+{{end}}
+</section>
+<section>
+<h2>Request headers</h2>
+<table>
+{{range .Headers}}<tr><td>{{.Name}}</td><td>{{.Value}}</td></tr>{{end}}
+</table>
+</section>
+{{if .Routes}}
+<section>
+<h2>Registered routes</h2>
+<table>
+{{range .Routes}}<tr><td colspan="2"><code>{{.}}</code></td></tr>{{end}}
+</table>
+</section>
+{{end}}
+<section>
+<p>You're seeing this page because <code>app.debug = true</code> in settings.toml. Turn it off in production.</p>
+</section>
+</body>
+</html>
+`))
@@ -0,0 +1,447 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/auth"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+	bourbonHttp "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+)
+
+// QueriesHeader summarizes the SQL a request ran, e.g. "count=12; time=3.41ms; repeated=1"
+const QueriesHeader = "X-Bourbon-Queries"
+
+// RepeatedQueriesHeader names the statement a request ran the most times, when it
+// ran at least RepeatThreshold times, e.g. `10x SELECT * FROM "comments" WHERE "post_id" = ?`
+const RepeatedQueriesHeader = "X-Bourbon-Queries-Repeated"
+
+// maxToolbarLogs limits the log messages kept per request
+const maxToolbarLogs = 200
+
+// DebugToolbarConfig configures the DebugToolbar middleware
+type DebugToolbarConfig struct {
+	// RepeatThreshold is how many runs of the same statement in a request flag it
+	// as a likely N+1 query (default 3)
+	RepeatThreshold int
+	// RequestsURL links the toolbar to the recorded requests, if they are recorded
+	RequestsURL string
+	// Logger is given to handlers through Context.Logger and logging.FromContext;
+	// what they log with it is shown on the toolbar whatever its level
+	Logger *logging.Logger
+	// Middleware names the middleware a request to a route runs through,
+	// outermost first; without it only the route's group middleware is listed
+	Middleware func(r *http.Request, route bourbonHttp.Route) []string
+}
+
+// DebugToolbar adds a toolbar to HTML pages, before </body>, showing how the
+// request was served: its time, the route and middleware, the SQL it ran with
+// likely N+1 queries flagged, the templates rendered, the user and cookies, the
+// values set on the Context and the messages logged. Every response also gets
+// the X-Bourbon-Queries headers. It is meant for debug mode.
+func DebugToolbar(cfg DebugToolbarConfig) Middleware {
+	if cfg.RepeatThreshold <= 0 {
+		cfg.RepeatThreshold = 3
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = logging.Nop()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			collector := &toolbarCollector{start: time.Now(), request: r, debug: &bourbonHttp.Debug{}}
+			ctx := orm.WithQueryRecorder(r.Context(), collector.add)
+			ctx = bourbonHttp.WithDebug(ctx, collector.debug)
+			ctx = logging.NewContext(ctx, cfg.Logger.Capture(collector.log))
+
+			tw := &toolbarWriter{ResponseWriter: w, cfg: cfg, collector: collector}
+			next.ServeHTTP(tw, r.WithContext(ctx))
+			tw.finish()
+		})
+	}
+}
+
+// toolbarCollector keeps what a request did
+type toolbarCollector struct {
+	start   time.Time
+	request *http.Request
+	debug   *bourbonHttp.Debug
+
+	mu      sync.Mutex
+	queries []orm.RecordedQuery
+	logs    []logging.Entry
+}
+
+func (tc *toolbarCollector) add(query orm.RecordedQuery) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if len(tc.queries) < maxRecordedQueries {
+		tc.queries = append(tc.queries, query)
+	}
+}
+
+func (tc *toolbarCollector) log(entry logging.Entry) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if len(tc.logs) < maxToolbarLogs {
+		tc.logs = append(tc.logs, entry)
+	}
+}
+
+// inspectedQueries are a request's queries, as the toolbar shows them
+type inspectedQueries struct {
+	Queries  []inspectedQuery
+	Time     time.Duration
+	Repeated []repeatedQuery // most runs first
+}
+
+type inspectedQuery struct {
+	orm.RecordedQuery
+	Repeated bool
+}
+
+type repeatedQuery struct {
+	SQL   string
+	Count int
+	Time  time.Duration
+}
+
+// inspect summarizes the queries collected so far
+func (tc *toolbarCollector) inspect(cfg DebugToolbarConfig) inspectedQueries {
+	tc.mu.Lock()
+	queries := append([]orm.RecordedQuery(nil), tc.queries...)
+	tc.mu.Unlock()
+
+	var result inspectedQueries
+	runs := make(map[string]*repeatedQuery)
+	var order []string
+	for _, query := range queries {
+		result.Time += query.Duration
+		run, ok := runs[query.SQL]
+		if !ok {
+			run = &repeatedQuery{SQL: query.SQL}
+			runs[query.SQL] = run
+			order = append(order, query.SQL)
+		}
+		run.Count++
+		run.Time += query.Duration
+	}
+	for _, sql := range order {
+		if runs[sql].Count >= cfg.RepeatThreshold {
+			result.Repeated = append(result.Repeated, *runs[sql])
+		}
+	}
+	sort.SliceStable(result.Repeated, func(i, j int) bool {
+		return result.Repeated[i].Count > result.Repeated[j].Count
+	})
+	for _, query := range queries {
+		result.Queries = append(result.Queries, inspectedQuery{
+			RecordedQuery: query,
+			Repeated:      runs[query.SQL].Count >= cfg.RepeatThreshold,
+		})
+	}
+	return result
+}
+
+// setHeaders adds the X-Bourbon-Queries headers
+func (iq inspectedQueries) setHeaders(h http.Header) {
+	h.Set(QueriesHeader, fmt.Sprintf("count=%d; time=%s; repeated=%d",
+		len(iq.Queries), roundDuration(iq.Time), len(iq.Repeated)))
+	if len(iq.Repeated) > 0 {
+		top := iq.Repeated[0]
+		h.Set(RepeatedQueriesHeader, strconv.Itoa(top.Count)+"x "+strings.Join(strings.Fields(top.SQL), " "))
+	}
+}
+
+// toolbar is what the toolbar template shows
+type toolbar struct {
+	Method string
+	Path   string
+	Status int
+	Time   time.Duration
+
+	// Route is nil when no route matched
+	Route      *bourbonHttp.Route
+	Middleware []string
+
+	Queries      inspectedQueries
+	Templates    []bourbonHttp.TemplateRender
+	TemplateTime time.Duration
+	// OtherTime is the time spent outside SQL and templates
+	OtherTime     time.Duration
+	UserID        uint
+	Authenticated bool
+	Cookies       []toolbarCookie
+	SetCookies    []toolbarCookie
+	Values        []toolbarValue
+	Logs          []logging.Entry
+
+	// RequestsURL links to the recorded requests, if any
+	RequestsURL string
+}
+
+// toolbarCookie shows a cookie without its value, which HttpOnly keeps from scripts
+type toolbarCookie struct {
+	Name       string
+	Size       int
+	Attributes string
+}
+
+type toolbarValue struct {
+	Key   string
+	Value string
+}
+
+// toolbar gathers what the request did once its response is complete
+func (tc *toolbarCollector) toolbar(cfg DebugToolbarConfig, status int, header http.Header) toolbar {
+	tb := toolbar{
+		Method:      tc.request.Method,
+		Path:        tc.request.URL.Path,
+		Status:      status,
+		Time:        time.Since(tc.start),
+		Queries:     tc.inspect(cfg),
+		Templates:   tc.debug.Renders(),
+		RequestsURL: cfg.RequestsURL,
+	}
+
+	if route := tc.debug.Route(); route != nil {
+		tb.Route = route
+		if cfg.Middleware != nil {
+			tb.Middleware = cfg.Middleware(tc.request, *route)
+		} else {
+			tb.Middleware = route.Middleware
+		}
+	}
+	for _, render := range tb.Templates {
+		tb.TemplateTime += render.Duration
+	}
+	if other := tb.Time - tb.Queries.Time - tb.TemplateTime; other > 0 {
+		tb.OtherTime = other
+	}
+
+	// The handler's request carries what authentication middleware added
+	request := tc.request
+	if served := tc.debug.Request(); served != nil {
+		request = served
+	}
+	tb.UserID, tb.Authenticated = auth.UserIDFromContext(request.Context())
+	for _, cookie := range tc.request.Cookies() {
+		tb.Cookies = append(tb.Cookies, toolbarCookie{Name: cookie.Name, Size: len(cookie.Value)})
+	}
+	for _, line := range header.Values("Set-Cookie") {
+		if cookie, err := http.ParseSetCookie(line); err == nil {
+			tb.SetCookies = append(tb.SetCookies, toolbarCookie{
+				Name:       cookie.Name,
+				Size:       len(cookie.Value),
+				Attributes: cookieAttributes(cookie),
+			})
+		}
+	}
+	for key, value := range tc.debug.Values() {
+		tb.Values = append(tb.Values, toolbarValue{Key: key, Value: formatToolbarValue(value)})
+	}
+	sort.Slice(tb.Values, func(i, j int) bool { return tb.Values[i].Key < tb.Values[j].Key })
+
+	tc.mu.Lock()
+	tb.Logs = append([]logging.Entry(nil), tc.logs...)
+	tc.mu.Unlock()
+	return tb
+}
+
+// cookieAttributes describes how a cookie is set, e.g. "Path=/; Max-Age=3600; HttpOnly"
+func cookieAttributes(cookie *http.Cookie) string {
+	var attrs []string
+	if cookie.Path != "" {
+		attrs = append(attrs, "Path="+cookie.Path)
+	}
+	if cookie.Domain != "" {
+		attrs = append(attrs, "Domain="+cookie.Domain)
+	}
+	switch {
+	case cookie.MaxAge < 0:
+		attrs = append(attrs, "deleted")
+	case cookie.MaxAge > 0:
+		attrs = append(attrs, "Max-Age="+strconv.Itoa(cookie.MaxAge))
+	case !cookie.Expires.IsZero():
+		attrs = append(attrs, "Expires="+cookie.Expires.UTC().Format(time.RFC1123))
+	}
+	if cookie.HttpOnly {
+		attrs = append(attrs, "HttpOnly")
+	}
+	if cookie.Secure {
+		attrs = append(attrs, "Secure")
+	}
+	return strings.Join(attrs, "; ")
+}
+
+// formatToolbarValue formats a Context value, cut short when long
+func formatToolbarValue(value interface{}) string {
+	s := fmt.Sprintf("%+v", value)
+	if len(s) > 300 {
+		s = s[:300] + "…"
+	}
+	return s
+}
+
+// toolbarWriter sets the headers once the response starts, holding HTML
+// responses back to add the toolbar
+type toolbarWriter struct {
+	http.ResponseWriter
+	cfg       DebugToolbarConfig
+	collector *toolbarCollector
+	status    int
+	decided   bool
+	html      bool
+	buf       bytes.Buffer
+}
+
+func (tw *toolbarWriter) WriteHeader(code int) {
+	if tw.decided {
+		return
+	}
+	tw.decided = true
+	tw.status = code
+	header := tw.Header()
+	tw.html = code >= http.StatusOK && code != http.StatusNoContent && code != http.StatusNotModified &&
+		strings.HasPrefix(header.Get("Content-Type"), "text/html") &&
+		header.Get("Content-Encoding") == ""
+	if !tw.html {
+		tw.collector.inspect(tw.cfg).setHeaders(header)
+		tw.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (tw *toolbarWriter) Write(b []byte) (int, error) {
+	if !tw.decided {
+		if tw.Header().Get("Content-Type") == "" {
+			tw.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		tw.WriteHeader(http.StatusOK)
+	}
+	if tw.html {
+		return tw.buf.Write(b)
+	}
+	return tw.ResponseWriter.Write(b)
+}
+
+// Flush sends buffered output when the handler streams, giving up on the toolbar
+func (tw *toolbarWriter) Flush() {
+	if tw.html {
+		tw.html = false
+		tw.Header().Del("Content-Length")
+		tw.collector.inspect(tw.cfg).setHeaders(tw.Header())
+		tw.ResponseWriter.WriteHeader(tw.status)
+		_, _ = tw.ResponseWriter.Write(tw.buf.Bytes())
+		tw.buf.Reset()
+	}
+	if f, ok := tw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap gives http.ResponseController the underlying writer
+func (tw *toolbarWriter) Unwrap() http.ResponseWriter {
+	return tw.ResponseWriter
+}
+
+func (tw *toolbarWriter) finish() {
+	if !tw.decided {
+		// Nothing was written; net/http sends the headers when the handler returns
+		tw.collector.inspect(tw.cfg).setHeaders(tw.Header())
+		return
+	}
+	if !tw.html {
+		return
+	}
+
+	tb := tw.collector.toolbar(tw.cfg, tw.status, tw.Header())
+	tb.Queries.setHeaders(tw.Header())
+	body := tw.buf.Bytes()
+	// Fragments, e.g. for htmx, have no </body> and get no toolbar
+	if i := bytes.LastIndex(bytes.ToLower(body), []byte("</body>")); i >= 0 {
+		var out bytes.Buffer
+		if err := debugToolbarTemplate.Execute(&out, tb); err == nil {
+			body = append(body[:i:i], append(out.Bytes(), body[i:]...)...)
+		}
+	}
+	tw.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	tw.ResponseWriter.WriteHeader(tw.status)
+	_, _ = tw.ResponseWriter.Write(body)
+}
+
+// roundDuration rounds to what matters when reading a request's timings
+func roundDuration(d time.Duration) time.Duration {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+	return d.Round(10 * time.Microsecond)
+}
+
+// formatLogFields formats a log message's fields as key=value pairs
+func formatLogFields(fields map[string]interface{}) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+formatToolbarValue(fields[key]))
+	}
+	return strings.Join(pairs, " ")
+}
+
+var debugToolbarTemplate = template.Must(template.New("toolbar").Funcs(template.FuncMap{
+	"duration": roundDuration,
+	"fields":   formatLogFields,
+}).Parse(`<details id="bourbon-toolbar" style="position:fixed;right:12px;bottom:12px;z-index:2147483647;width:min(960px,calc(100vw - 24px));max-height:70vh;overflow:auto;background:#2d3748;color:#f7fafc;font:12px/1.4 -apple-system,BlinkMacSystemFont,'Segoe UI',sans-serif;border-radius:6px;box-shadow:0 2px 12px rgba(0,0,0,.3)">
+<summary style="cursor:pointer;padding:6px 10px;{{if .Queries.Repeated}}background:#b32d2e;border-radius:6px;{{end}}">{{.Method}} {{with .Route}}{{.Pattern}}{{else}}{{.Path}}{{end}} &middot; {{.Status}} &middot; {{duration .Time}} &middot; {{len .Queries.Queries}} queries{{with .Queries.Repeated}} ({{len .}} repeated){{end}} &middot; {{len .Templates}} templates &middot; {{len .Logs}} logs</summary>
+<div style="padding:0 10px 10px">
+<details open style="margin-top:6px"><summary style="cursor:pointer;font-weight:600">Time</summary>
+<table style="border-collapse:collapse;color:inherit;font-size:12px;margin:4px 0">
+<tr><td style="padding:2px 12px 2px 0">Total</td><td>{{duration .Time}}</td></tr>
+<tr><td style="padding:2px 12px 2px 0">SQL</td><td>{{duration .Queries.Time}}</td></tr>
+<tr><td style="padding:2px 12px 2px 0">Templates</td><td>{{duration .TemplateTime}}</td></tr>
+<tr><td style="padding:2px 12px 2px 0">Other</td><td>{{duration .OtherTime}}</td></tr>
+</table></details>
+<details style="margin-top:6px"><summary style="cursor:pointer;font-weight:600">Route</summary>
+{{with .Route}}<p style="margin:4px 0"><code>{{.Method}} {{.Pattern}}</code> handled by <code>{{.HandlerName}}</code>{{with .Group}} in group <code>{{.}}</code>{{end}}</p>
+{{else}}<p style="margin:4px 0">No route matched {{.Path}}</p>
+{{end}}{{if .Middleware}}<ol style="margin:4px 0;padding-left:20px">{{range .Middleware}}<li><code>{{.}}</code></li>{{end}}</ol>{{end}}
+</details>
+<details{{if .Queries.Repeated}} open{{end}} style="margin-top:6px"><summary style="cursor:pointer;font-weight:600">Queries ({{len .Queries.Queries}} in {{duration .Queries.Time}})</summary>
+{{range .Queries.Repeated}}<p style="margin:6px 0;color:#feb2b2">Ran {{.Count}} times in {{duration .Time}}, likely an N+1 query: <code>{{.SQL}}</code></p>{{end}}
+<table style="border-collapse:collapse;width:100%;color:inherit;font-size:12px">
+{{range .Queries.Queries}}<tr style="border-top:1px solid #4a5568{{if .Repeated}};color:#feb2b2{{end}}">
+<td style="padding:4px 6px;font-family:monospace;word-break:break-all">{{.SQL}}{{if .Vars}}<br><span style="color:#a0aec0">{{range $i, $v := .Vars}}{{if $i}}, {{end}}{{$v}}{{end}}</span>{{end}}{{if .Error}}<br><span style="color:#fc8181">{{.Error}}</span>{{end}}</td>
+<td style="padding:4px 6px;text-align:right;white-space:nowrap">{{duration .Duration}}</td>
+<td style="padding:4px 6px;text-align:right;white-space:nowrap">{{if ge .Rows 0}}{{.Rows}} rows{{end}}</td>
+</tr>{{end}}
+</table></details>
+<details style="margin-top:6px"><summary style="cursor:pointer;font-weight:600">Templates ({{len .Templates}} in {{duration .TemplateTime}})</summary>
+<table style="border-collapse:collapse;color:inherit;font-size:12px;margin:4px 0">
+{{range .Templates}}<tr><td style="padding:2px 12px 2px 0"><code>{{.Name}}</code>{{with .Error}}<br><span style="color:#fc8181">{{.}}</span>{{end}}</td><td>{{duration .Duration}}</td></tr>{{end}}
+</table></details>
+<details style="margin-top:6px"><summary style="cursor:pointer;font-weight:600">Session</summary>
+<p style="margin:4px 0">{{if .Authenticated}}User {{.UserID}}{{else}}No authenticated user{{end}}</p>
+{{if .Cookies}}<p style="margin:4px 0">Cookies received: {{range $i, $c := .Cookies}}{{if $i}}, {{end}}<code>{{$c.Name}}</code> ({{$c.Size}} bytes){{end}}</p>{{end}}
+{{if .SetCookies}}<p style="margin:4px 0">Cookies set: {{range $i, $c := .SetCookies}}{{if $i}}, {{end}}<code>{{$c.Name}}</code> ({{$c.Size}} bytes{{with $c.Attributes}}; {{.}}{{end}}){{end}}</p>{{end}}
+{{if .Values}}<table style="border-collapse:collapse;color:inherit;font-size:12px;margin:4px 0">
+{{range .Values}}<tr><td style="padding:2px 12px 2px 0;vertical-align:top"><code>{{.Key}}</code></td><td style="font-family:monospace;word-break:break-all">{{.Value}}</td></tr>{{end}}
+</table>{{end}}
+</details>
+<details style="margin-top:6px"><summary style="cursor:pointer;font-weight:600">Logs ({{len .Logs}})</summary>
+<table style="border-collapse:collapse;width:100%;color:inherit;font-size:12px;margin:4px 0">
+{{range .Logs}}<tr style="border-top:1px solid #4a5568"><td style="padding:2px 6px;white-space:nowrap;vertical-align:top">{{.Level}}</td><td style="padding:2px 6px;word-break:break-all">{{.Message}}{{with fields .Fields}} <span style="color:#a0aec0;font-family:monospace">{{.}}</span>{{end}}</td></tr>{{end}}
+</table></details>
+{{with .RequestsURL}}<p style="margin:8px 0 0"><a href="{{.}}" style="color:#90cdf4">Recorded requests</a></p>{{end}}
+</div>
+</details>`))
@@ -0,0 +1,330 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/auth"
+	"gorm.io/gorm"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header carrying the client-chosen key
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set to "true" on replayed responses
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// ErrIdempotencyKeyInUse is returned by IdempotencyStore.Begin when the key is already reserved
+var ErrIdempotencyKeyInUse = errors.New("idempotency key already in use")
+
+// IdempotencyRecord is a stored response for an idempotency key
+type IdempotencyRecord struct {
+	Key         string    `gorm:"column:idempotency_key;primaryKey;size:255" json:"key"`
+	Fingerprint string    `gorm:"size:64" json:"fingerprint"`
+	Completed   bool      `json:"completed"`
+	StatusCode  int       `json:"status_code"`
+	Header      string    `gorm:"type:text" json:"header"`
+	Body        []byte    `json:"body"`
+	ExpiresAt   time.Time `gorm:"index" json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for IdempotencyRecord
+func (IdempotencyRecord) TableName() string {
+	return "idempotency_keys"
+}
+
+// IdempotencyStore persists idempotency records
+type IdempotencyStore interface {
+	// Get returns the unexpired record for key, or nil if there is none
+	Get(key string) (*IdempotencyRecord, error)
+	// Begin reserves key for an in-flight request until ttl passes, returning
+	// ErrIdempotencyKeyInUse if taken
+	Begin(key, fingerprint string, ttl time.Duration) error
+	// Complete stores the final response for a reserved key
+	Complete(record *IdempotencyRecord) error
+	// Release drops a reservation so the request can be retried
+	Release(key string) error
+}
+
+// IdempotencyConfig configures the Idempotency middleware
+type IdempotencyConfig struct {
+	Store   IdempotencyStore // where responses are kept (default: in-memory)
+	TTL     time.Duration    // how long responses are replayed (default 24h)
+	Methods []string         // methods honoring the header (default POST and PATCH)
+	// LockTimeout is how long a key stays reserved by a request that hasn't
+	// finished, e.g. because its process crashed (default 1m). Keep it above the
+	// longest request, or a retry may run alongside the first one.
+	LockTimeout time.Duration
+	// MaxBodySize limits the request bodies read to fingerprint them; larger
+	// requests get 413 (default 1MB)
+	MaxBodySize int64
+	// Required rejects requests to the protected methods that lack the header with 400
+	Required bool
+}
+
+// Idempotency middleware honors the Idempotency-Key header. The first response for a key is
+// stored and replayed on retries; a retry with a different body gets 422, and a retry while
+// the first request is still running gets 409. Server errors are not stored, so they can be retried.
+func Idempotency(cfg IdempotencyConfig) Middleware {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryIdempotencyStore()
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	if cfg.LockTimeout <= 0 {
+		cfg.LockTimeout = time.Minute
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = 1 << 20
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !containsFold(cfg.Methods, r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := r.Header.Get(IdempotencyKeyHeader)
+			if header == "" {
+				if cfg.Required {
+					http.Error(w, "Idempotency-Key header required", http.StatusBadRequest)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, cfg.MaxBodySize))
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					http.Error(w, "Request Entity Too Large", http.StatusRequestEntityTooLarge)
+					return
+				}
+				http.Error(w, "Bad Request", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			key := idempotencyScope(r, header)
+			fingerprint := idempotencyFingerprint(r, body)
+
+			record, err := cfg.Store.Get(key)
+			if err != nil {
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+			if record != nil {
+				replayIdempotent(w, record, fingerprint)
+				return
+			}
+
+			// The reservation is short, so a crashed request doesn't hold the key
+			// for the whole TTL; Complete extends it to TTL
+			if err := cfg.Store.Begin(key, fingerprint, cfg.LockTimeout); err != nil {
+				if errors.Is(err, ErrIdempotencyKeyInUse) {
+					http.Error(w, "A request with this Idempotency-Key is in progress", http.StatusConflict)
+					return
+				}
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+
+			rec := newRecordedResponse()
+			completed := false
+			defer func() {
+				if !completed {
+					_ = cfg.Store.Release(key)
+				}
+			}()
+			next.ServeHTTP(rec, r)
+
+			if rec.statusCode < http.StatusInternalServerError {
+				headerJSON, _ := json.Marshal(rec.header)
+				err := cfg.Store.Complete(&IdempotencyRecord{
+					Key:         key,
+					Fingerprint: fingerprint,
+					Completed:   true,
+					StatusCode:  rec.statusCode,
+					Header:      string(headerJSON),
+					Body:        rec.body.Bytes(),
+					ExpiresAt:   time.Now().Add(cfg.TTL),
+				})
+				completed = err == nil
+			}
+			rec.writeTo(w)
+		})
+	}
+}
+
+// idempotencyScope namespaces the client key by user, method and path so keys cannot collide across endpoints
+func idempotencyScope(r *http.Request, key string) string {
+	scope := r.Method + " " + r.URL.Path + " " + key
+	if userID, ok := auth.UserIDFromContext(r.Context()); ok {
+		scope = fmt.Sprintf("user:%d %s", userID, scope)
+	}
+	return scope
+}
+
+func idempotencyFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.URL.RawQuery))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func replayIdempotent(w http.ResponseWriter, record *IdempotencyRecord, fingerprint string) {
+	if record.Fingerprint != fingerprint {
+		http.Error(w, "Idempotency-Key reused with a different request", http.StatusUnprocessableEntity)
+		return
+	}
+	if !record.Completed {
+		http.Error(w, "A request with this Idempotency-Key is in progress", http.StatusConflict)
+		return
+	}
+
+	var header http.Header
+	_ = json.Unmarshal([]byte(record.Header), &header)
+	for key, values := range header {
+		w.Header()[key] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(record.StatusCode)
+	_, _ = w.Write(record.Body)
+}
+
+// MemoryIdempotencyStore keeps idempotency records in process memory
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*IdempotencyRecord
+}
+
+// NewMemoryIdempotencyStore creates an in-memory store, suitable for single-instance deployments
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{records: make(map[string]*IdempotencyRecord)}
+}
+
+// Get returns the unexpired record for key
+func (s *MemoryIdempotencyStore) Get(key string) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key]
+	if !ok {
+		return nil, nil
+	}
+	if time.Now().After(record.ExpiresAt) {
+		delete(s.records, key)
+		return nil, nil
+	}
+	copied := *record
+	return &copied, nil
+}
+
+// Begin reserves key
+func (s *MemoryIdempotencyStore) Begin(key, fingerprint string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.records[key]; ok && time.Now().Before(record.ExpiresAt) {
+		return ErrIdempotencyKeyInUse
+	}
+	s.records[key] = &IdempotencyRecord{Key: key, Fingerprint: fingerprint, ExpiresAt: time.Now().Add(ttl), CreatedAt: time.Now()}
+	return nil
+}
+
+// Complete stores the final response
+func (s *MemoryIdempotencyStore) Complete(record *IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *record
+	s.records[record.Key] = &copied
+	return nil
+}
+
+// Release drops a reservation
+func (s *MemoryIdempotencyStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+// DBIdempotencyStore keeps idempotency records in the idempotency_keys table,
+// so replays work across multiple instances
+type DBIdempotencyStore struct {
+	db *gorm.DB
+}
+
+// NewDBIdempotencyStore creates a database-backed store
+func NewDBIdempotencyStore(db *gorm.DB) *DBIdempotencyStore {
+	return &DBIdempotencyStore{db: db}
+}
+
+// Migrate creates the idempotency_keys table
+func (s *DBIdempotencyStore) Migrate() error {
+	return s.db.AutoMigrate(&IdempotencyRecord{})
+}
+
+// Get returns the unexpired record for key
+func (s *DBIdempotencyStore) Get(key string) (*IdempotencyRecord, error) {
+	var record IdempotencyRecord
+	err := s.db.Where("idempotency_key = ? AND expires_at > ?", key, time.Now()).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Begin reserves key; the primary key constraint makes the reservation atomic
+func (s *DBIdempotencyStore) Begin(key, fingerprint string, ttl time.Duration) error {
+	if err := s.db.Where("idempotency_key = ? AND expires_at <= ?", key, time.Now()).Delete(&IdempotencyRecord{}).Error; err != nil {
+		return err
+	}
+	record := &IdempotencyRecord{Key: key, Fingerprint: fingerprint, ExpiresAt: time.Now().Add(ttl)}
+	if err := s.db.Create(record).Error; err != nil {
+		if existing, getErr := s.Get(key); getErr == nil && existing != nil {
+			return ErrIdempotencyKeyInUse
+		}
+		return err
+	}
+	return nil
+}
+
+// Complete stores the final response
+func (s *DBIdempotencyStore) Complete(record *IdempotencyRecord) error {
+	return s.db.Model(&IdempotencyRecord{}).Where("idempotency_key = ?", record.Key).Updates(map[string]interface{}{
+		"completed":   true,
+		"status_code": record.StatusCode,
+		"header":      record.Header,
+		"body":        record.Body,
+		"expires_at":  record.ExpiresAt,
+	}).Error
+}
+
+// Release drops a reservation
+func (s *DBIdempotencyStore) Release(key string) error {
+	return s.db.Where("idempotency_key = ?", key).Delete(&IdempotencyRecord{}).Error
+}
+
+// Clean removes expired records
+func (s *DBIdempotencyStore) Clean() error {
+	return s.db.Where("expires_at <= ?", time.Now()).Delete(&IdempotencyRecord{}).Error
+}
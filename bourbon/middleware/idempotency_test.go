@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func idempotentRequest(h http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	var calls int32
+	h := Idempotency(IdempotencyConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Call", strconv.Itoa(int(n)))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(body)
+	}))
+
+	first := idempotentRequest(h, "k1", `{"amount":10}`)
+	if first.Code != http.StatusCreated || first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Fatalf("first response = %d %v", first.Code, first.Header())
+	}
+
+	retry := idempotentRequest(h, "k1", `{"amount":10}`)
+	if retry.Code != http.StatusCreated {
+		t.Fatalf("retry status = %d, want %d", retry.Code, http.StatusCreated)
+	}
+	if retry.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Error("retry is missing the Idempotent-Replayed header")
+	}
+	if retry.Header().Get("X-Call") != "1" || retry.Body.String() != `{"amount":10}` {
+		t.Errorf("retry replayed %q %q, want the first response", retry.Header().Get("X-Call"), retry.Body.String())
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+
+	if rec := idempotentRequest(h, "k2", `{"amount":10}`); rec.Header().Get(IdempotentReplayedHeader) != "" || calls != 2 {
+		t.Error("a different key was replayed")
+	}
+}
+
+func TestIdempotencyDifferentBody(t *testing.T) {
+	h := Idempotency(IdempotencyConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	idempotentRequest(h, "k1", `{"amount":10}`)
+	if rec := idempotentRequest(h, "k1", `{"amount":99}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h := Idempotency(IdempotencyConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- idempotentRequest(h, "k1", `{"amount":10}`) }()
+	<-started
+
+	if rec := idempotentRequest(h, "k1", `{"amount":10}`); rec.Code != http.StatusConflict {
+		t.Errorf("same body status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if rec := idempotentRequest(h, "k1", `{"amount":99}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("different body status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+
+	close(release)
+	if rec := <-done; rec.Code != http.StatusCreated {
+		t.Fatalf("first request status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if rec := idempotentRequest(h, "k1", `{"amount":10}`); rec.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Error("the finished request was not replayed")
+	}
+}
+
+func TestIdempotencyServerErrorNotStored(t *testing.T) {
+	var calls int32
+	h := Idempotency(IdempotencyConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	idempotentRequest(h, "k1", "{}")
+	if rec := idempotentRequest(h, "k1", "{}"); rec.Code != http.StatusCreated || calls != 2 {
+		t.Errorf("retry after 500 = %d after %d calls, want 201 after 2", rec.Code, calls)
+	}
+}
+
+func TestIdempotencyRequired(t *testing.T) {
+	h := Idempotency(IdempotencyConfig{Required: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	if rec := idempotentRequest(h, "", "{}"); rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestIdempotencyCrashedRequestLock(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	h := Idempotency(IdempotencyConfig{Store: store, LockTimeout: 20 * time.Millisecond})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	// A process that crashed mid-request leaves its reservation behind
+	req := httptest.NewRequest(http.MethodPost, "/payments", nil)
+	if err := store.Begin(idempotencyScope(req, "k1"), idempotencyFingerprint(req, []byte("{}")), 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if rec := idempotentRequest(h, "k1", "{}"); rec.Code != http.StatusConflict {
+		t.Fatalf("status while reserved = %d, want %d", rec.Code, http.StatusConflict)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if rec := idempotentRequest(h, "k1", "{}"); rec.Code != http.StatusCreated {
+		t.Fatalf("status after the lock expired = %d, want %d", rec.Code, http.StatusCreated)
+	}
+
+	// The completed response is kept for the TTL, not the lock timeout
+	time.Sleep(30 * time.Millisecond)
+	if rec := idempotentRequest(h, "k1", "{}"); rec.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Error("the completed response expired with the lock")
+	}
+}
+
+func TestIdempotencyBodyLimit(t *testing.T) {
+	calls := 0
+	h := Idempotency(IdempotencyConfig{MaxBodySize: 8})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	if rec := idempotentRequest(h, "k1", `{"amount":10}`); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+	if rec := idempotentRequest(h, "k2", `{}`); rec.Code != http.StatusOK || calls != 1 {
+		t.Errorf("small body status = %d after %d calls", rec.Code, calls)
+	}
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// LiveReload adds <script src="scriptURL"> to HTML responses, before </body>, error
+// pages included. The dev server sets it up in debug mode, so browsers reload when
+// templates, static files or Go code change.
+func LiveReload(scriptURL string) Middleware {
+	tag := []byte(`<script src="` + scriptURL + `" defer></script>`)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || r.Header.Get("HX-Request") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			lw := &liveReloadWriter{ResponseWriter: w}
+			next.ServeHTTP(lw, r)
+			lw.finish(tag)
+		})
+	}
+}
+
+// liveReloadWriter buffers HTML responses so the script can be inserted; other
+// responses are passed through
+type liveReloadWriter struct {
+	http.ResponseWriter
+	status  int
+	decided bool
+	html    bool
+	buf     bytes.Buffer
+}
+
+func (lw *liveReloadWriter) WriteHeader(code int) {
+	if lw.decided {
+		return
+	}
+	lw.decided = true
+	lw.status = code
+	header := lw.Header()
+	lw.html = code >= http.StatusOK && code != http.StatusNoContent && code != http.StatusNotModified &&
+		strings.HasPrefix(header.Get("Content-Type"), "text/html") &&
+		header.Get("Content-Encoding") == ""
+	if !lw.html {
+		lw.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (lw *liveReloadWriter) Write(b []byte) (int, error) {
+	if !lw.decided {
+		if lw.Header().Get("Content-Type") == "" {
+			lw.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		lw.WriteHeader(http.StatusOK)
+	}
+	if lw.html {
+		return lw.buf.Write(b)
+	}
+	return lw.ResponseWriter.Write(b)
+}
+
+// Flush sends buffered output when the handler streams, giving up on the script
+func (lw *liveReloadWriter) Flush() {
+	if lw.html {
+		lw.html = false
+		lw.Header().Del("Content-Length")
+		lw.ResponseWriter.WriteHeader(lw.status)
+		_, _ = lw.ResponseWriter.Write(lw.buf.Bytes())
+		lw.buf.Reset()
+	}
+	if f, ok := lw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (lw *liveReloadWriter) finish(tag []byte) {
+	if !lw.html {
+		return
+	}
+	body := lw.buf.Bytes()
+	if i := bytes.LastIndex(bytes.ToLower(body), []byte("</body>")); i >= 0 {
+		body = append(body[:i:i], append(tag, body[i:]...)...)
+	} else {
+		body = append(body, tag...)
+	}
+	lw.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	lw.ResponseWriter.WriteHeader(lw.status)
+	_, _ = lw.ResponseWriter.Write(body)
+}
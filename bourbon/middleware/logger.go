@@ -2,46 +2,106 @@ package middleware
 
 import (
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
 	"go.uber.org/zap"
 )
 
-// Logger middleware logs incoming HTTP requests with method, path, status code, duration, and client IP
+// RequestIDHeader is read (from the request or response) for the request_id access log field
+const RequestIDHeader = "X-Request-ID"
+
+// DefaultAccessLogFields are logged when AccessLogConfig.Fields is empty
+var DefaultAccessLogFields = []string{"latency", "bytes", "ip", "user_agent", "request_id"}
+
+// AccessLogConfig configures the AccessLog middleware
+type AccessLogConfig struct {
+	// Fields selects optional fields: latency, bytes, ip, user_agent, referer, request_id, query
+	Fields []string
+	// Sample logs only 1 in N successful requests whose path starts with the key.
+	// Requests with status >= 400 are always logged.
+	Sample map[string]int
+	// SkipPaths are never logged, e.g. health checks
+	SkipPaths []string
+	// Pretty prints a colored one-line summary instead of a structured record (for development)
+	Pretty bool
+	// TrustedProxies resolve the logged client IP from X-Forwarded-For, see
+	// ParseCIDRs. An IP resolved by IPFilter or TrustedProxies is used first.
+	TrustedProxies []*net.IPNet
+}
+
+// Logger middleware logs incoming HTTP requests with the default access log fields.
+// Output is a colored console line when the logger is in development mode, structured records otherwise.
 func Logger(logger *logging.Logger, errorStore *logging.ErrorStore) Middleware {
+	return AccessLog(logger, errorStore, AccessLogConfig{Pretty: logger.Development()})
+}
+
+// AccessLog middleware emits one structured record per request through the Bourbon logger,
+// so it follows logging.format and the configured outputs. Server errors are also stored in the error store.
+func AccessLog(logger *logging.Logger, errorStore *logging.ErrorStore, cfg AccessLogConfig) Middleware {
+	fields := cfg.Fields
+	if len(fields) == 0 {
+		fields = DefaultAccessLogFields
+	}
+	enabled := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		enabled[f] = true
+	}
+	sampler := newPathSampler(cfg.Sample)
+	logger = logger.Module(logging.ModuleHTTP)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, path := range cfg.SkipPaths {
+				if r.URL.Path == path {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
 			start := time.Now()
 
-			wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			wrapped := &accessWriter{ResponseWriter: w, statusCode: http.StatusOK}
 
 			next.ServeHTTP(wrapped, r)
 
 			duration := time.Since(start)
 
-			// Human-readable console output for development
-			statusColor := getStatusColor(wrapped.statusCode)
-			methodColor := getMethodColor(r.Method)
+			if wrapped.statusCode < 400 && !sampler.allow(r.URL.Path) {
+				return
+			}
+
+			if cfg.Pretty {
+				// Human-readable console output for development
+				statusColor := getStatusColor(wrapped.statusCode)
+				methodColor := getMethodColor(r.Method)
 
-			fmt.Printf("%s %s%-6s\x1b[0m | %s%3d\x1b[0m | %10s | %s\n",
-				time.Now().Format("15:04:05"),
-				methodColor,
-				r.Method,
-				statusColor,
-				wrapped.statusCode,
-				duration.Round(time.Millisecond),
-				r.URL.Path,
-			)
+				fmt.Printf("%s %s%-6s\x1b[0m | %s%3d\x1b[0m | %10s | %s\n",
+					time.Now().Format("15:04:05"),
+					methodColor,
+					r.Method,
+					statusColor,
+					wrapped.statusCode,
+					duration.Round(time.Millisecond),
+					r.URL.Path,
+				)
+			} else {
+				logger.HTTP(r.Method, r.URL.Path, wrapped.statusCode, duration, accessFields(enabled, cfg, r, w, wrapped, duration)...)
+			}
 
 			// Store server errors (5xx) in database
 			if wrapped.statusCode >= 500 && errorStore != nil {
-				// Only log errors to structured logger (for file/database)
-				logger.HTTP(r.Method, r.URL.Path, wrapped.statusCode, duration,
-					zap.String("ip", r.RemoteAddr),
-					zap.String("user_agent", r.UserAgent()),
-				)
+				if cfg.Pretty {
+					// Pretty output bypasses the structured logger, so record the error there too
+					logger.HTTP(r.Method, r.URL.Path, wrapped.statusCode, duration,
+						zap.String("ip", clientIP(r, cfg.TrustedProxies)),
+						zap.String("user_agent", r.UserAgent()),
+					)
+				}
 
 				errorLog := &logging.ErrorLog{
 					Timestamp: start,
@@ -50,7 +110,7 @@ func Logger(logger *logging.Logger, errorStore *logging.ErrorStore) Middleware {
 					Method:    r.Method,
 					Path:      r.URL.Path,
 					Status:    wrapped.statusCode,
-					IP:        r.RemoteAddr,
+					IP:        clientIP(r, cfg.TrustedProxies),
 					UserAgent: r.UserAgent(),
 				}
 				_ = errorStore.Store(errorLog)
@@ -59,6 +119,98 @@ func Logger(logger *logging.Logger, errorStore *logging.ErrorStore) Middleware {
 	}
 }
 
+// accessFields builds the optional zap fields enabled in the access log config
+func accessFields(enabled map[string]bool, cfg AccessLogConfig, r *http.Request, w http.ResponseWriter, aw *accessWriter, duration time.Duration) []zap.Field {
+	var fields []zap.Field
+	if enabled["latency"] {
+		fields = append(fields, zap.Float64("latency_ms", float64(duration.Microseconds())/1000))
+	}
+	if enabled["bytes"] {
+		fields = append(fields, zap.Int64("bytes", aw.bytes))
+	}
+	if enabled["ip"] {
+		fields = append(fields, zap.String("ip", clientIP(r, cfg.TrustedProxies)))
+	}
+	if enabled["user_agent"] {
+		fields = append(fields, zap.String("user_agent", r.UserAgent()))
+	}
+	if enabled["referer"] {
+		fields = append(fields, zap.String("referer", r.Referer()))
+	}
+	if enabled["query"] && r.URL.RawQuery != "" {
+		fields = append(fields, zap.String("query", r.URL.RawQuery))
+	}
+	if enabled["request_id"] {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" {
+			id = w.Header().Get(RequestIDHeader)
+		}
+		if id != "" {
+			fields = append(fields, zap.String("request_id", id))
+		}
+	}
+	return fields
+}
+
+// accessWriter captures the status code and number of bytes written
+type accessWriter struct {
+	http.ResponseWriter
+	statusCode int
+	bytes      int64
+}
+
+func (aw *accessWriter) WriteHeader(code int) {
+	aw.statusCode = code
+	aw.ResponseWriter.WriteHeader(code)
+}
+
+func (aw *accessWriter) Write(b []byte) (int, error) {
+	n, err := aw.ResponseWriter.Write(b)
+	aw.bytes += int64(n)
+	return n, err
+}
+
+// Flush lets streaming handlers, such as server-sent events, flush through the logger
+func (aw *accessWriter) Flush() {
+	if f, ok := aw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap gives http.ResponseController the underlying writer
+func (aw *accessWriter) Unwrap() http.ResponseWriter {
+	return aw.ResponseWriter
+}
+
+// pathSampler keeps per-prefix counters for access log sampling
+type pathSampler struct {
+	rates    map[string]int
+	counters map[string]*atomic.Uint64
+}
+
+func newPathSampler(rates map[string]int) *pathSampler {
+	s := &pathSampler{rates: rates, counters: make(map[string]*atomic.Uint64, len(rates))}
+	for prefix := range rates {
+		s.counters[prefix] = &atomic.Uint64{}
+	}
+	return s
+}
+
+// allow reports whether a request to path should be logged. The longest matching prefix wins.
+func (s *pathSampler) allow(path string) bool {
+	best := ""
+	for prefix := range s.rates {
+		if strings.HasPrefix(path, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	rate := s.rates[best]
+	if best == "" || rate <= 1 {
+		return true
+	}
+	return (s.counters[best].Add(1)-1)%uint64(rate) == 0
+}
+
 // getStatusColor returns ANSI color code based on HTTP status
 func getStatusColor(status int) string {
 	switch {
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	bourbonHttp "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+)
+
+func TestAccessLogClientIP(t *testing.T) {
+	trusted, _ := ParseCIDRs([]string{"10.0.0.0/8"})
+	cfg := AccessLogConfig{TrustedProxies: trusted}
+	enabled := map[string]bool{"ip": true}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		resolved   string
+		want       string
+	}{
+		{"direct", "203.0.113.5:4000", "", "", "203.0.113.5"},
+		{"untrusted peer", "203.0.113.5:4000", "198.51.100.7", "", "203.0.113.5"},
+		{"trusted proxy", "10.0.0.2:4000", "198.51.100.7", "", "198.51.100.7"},
+		{"resolved earlier", "10.0.0.2:4000", "198.51.100.7", "192.0.2.1", "192.0.2.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.resolved != "" {
+				r = r.WithContext(bourbonHttp.WithClientIP(r.Context(), tt.resolved))
+			}
+			fields := accessFields(enabled, cfg, r, httptest.NewRecorder(), &accessWriter{}, time.Millisecond)
+			if len(fields) != 1 || fields[0].Key != "ip" || fields[0].String != tt.want {
+				t.Errorf("fields = %v, want ip %s", fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessLogStreaming(t *testing.T) {
+	h := AccessLog(logging.Nop(), nil, AccessLogConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("data: 1\n\n"))
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Flush() = %v", err)
+		}
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(time.Minute)); err != nil {
+			t.Errorf("SetWriteDeadline() = %v", err)
+		}
+	}))
+
+	server := httptest.NewServer(h)
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,23 @@
+package middleware
+
+// QueryInspectorConfig configures the QueryInspector middleware.
+//
+// Deprecated: use DebugToolbarConfig.
+type QueryInspectorConfig struct {
+	// RepeatThreshold is how many runs of the same statement in a request flag it
+	// as a likely N+1 query (default 3)
+	RepeatThreshold int
+	// RequestsURL links the toolbar to the recorded requests, if they are recorded
+	RequestsURL string
+}
+
+// QueryInspector reports the SQL each request runs in the X-Bourbon-Queries
+// headers and, on HTML pages, in the debug toolbar.
+//
+// Deprecated: use DebugToolbar, which shows the queries with the rest of the request.
+func QueryInspector(cfg QueryInspectorConfig) Middleware {
+	return DebugToolbar(DebugToolbarConfig{
+		RepeatThreshold: cfg.RepeatThreshold,
+		RequestsURL:     cfg.RequestsURL,
+	})
+}
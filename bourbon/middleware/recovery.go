@@ -10,8 +10,22 @@ import (
 	"go.uber.org/zap"
 )
 
-// Recovery middleware recovers from panics in the request handling chain and logs the error with stack trace
+// RecoveryConfig configures the RecoveryWithConfig middleware
+type RecoveryConfig struct {
+	// Debug renders a detailed HTML error page instead of a bare 500. Never enable in production.
+	Debug bool
+	// Routes lists the registered routes ("GET /users/{id}") shown on the debug page
+	Routes func() []string
+}
+
+// Recovery middleware recovers from panics in the request handling chain and logs the error with stack trace.
+// When the logger is in development mode, a debug error page is rendered.
 func Recovery(logger *logging.Logger, errorStore *logging.ErrorStore) Middleware {
+	return RecoveryWithConfig(logger, errorStore, RecoveryConfig{Debug: logger.Development()})
+}
+
+// RecoveryWithConfig is Recovery with explicit debug page settings
+func RecoveryWithConfig(logger *logging.Logger, errorStore *logging.ErrorStore, cfg RecoveryConfig) Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
@@ -41,6 +55,15 @@ func Recovery(logger *logging.Logger, errorStore *logging.ErrorStore) Middleware
 						_ = errorStore.Store(errorLog)
 					}
 
+					if cfg.Debug {
+						var routes []string
+						if cfg.Routes != nil {
+							routes = cfg.Routes()
+						}
+						renderDebugPage(w, r, err, stack, routes)
+						return
+					}
+
 					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 				}
 			}()
@@ -0,0 +1,329 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+)
+
+// maxRecordedQueries limits the SQL statements kept per recorded request
+const maxRecordedQueries = 200
+
+// RequestRecorderConfig configures a RequestRecorder
+type RequestRecorderConfig struct {
+	Size         int      // requests kept, the oldest dropped first (default 100)
+	MaxBodySize  int      // bytes kept per request and response body (default 64KB)
+	RedactFields []string // extra field and header names to redact, matched case-insensitively
+}
+
+// RecordedRequest is a request captured by a RequestRecorder. Its query, headers
+// and bodies are redacted like audited ones.
+type RecordedRequest struct {
+	ID         uint64
+	Time       time.Time
+	Method     string
+	Path       string
+	Query      string
+	RemoteAddr string
+	Duration   time.Duration
+
+	RequestHeaders       map[string]string
+	RequestBody          string
+	RequestBodyTruncated bool
+
+	Status                int
+	ResponseHeaders       map[string]string
+	ResponseBody          string
+	ResponseBodyTruncated bool
+
+	// Queries are the SQL statements run with the request's context, in order
+	Queries []orm.RecordedQuery
+	// QueriesDropped counts the statements beyond the first 200
+	QueriesDropped int
+
+	// ReplayOf is the ID of the request this one replays, 0 for a real one
+	ReplayOf uint64
+
+	// replay is the request as it came in, unredacted; nil when its body was truncated
+	replay *replayRequest
+}
+
+// QueryTime returns the time spent in the recorded SQL statements
+func (rr *RecordedRequest) QueryTime() time.Duration {
+	var total time.Duration
+	for _, query := range rr.Queries {
+		total += query.Duration
+	}
+	return total
+}
+
+// Replayable reports whether Replay can serve the request again
+func (rr *RecordedRequest) Replayable() bool {
+	return rr.replay != nil
+}
+
+type replayRequest struct {
+	method     string
+	target     string
+	host       string
+	remoteAddr string
+	header     http.Header
+	body       []byte
+}
+
+type replayKey struct{}
+
+// replayResult receives the record of a replayed request
+type replayResult struct {
+	of     uint64
+	record *RecordedRequest
+}
+
+// RequestRecorder keeps the most recent requests in memory, with their headers,
+// bodies, timings and SQL, for inspecting them while developing. It keeps the
+// unredacted request to replay it, so only use it in debug mode.
+type RequestRecorder struct {
+	cfg    RequestRecorderConfig
+	redact *Redactor
+
+	mu      sync.RWMutex
+	entries []*RecordedRequest // ring buffer, next is the oldest once full
+	next    int
+	lastID  uint64
+}
+
+// NewRequestRecorder creates an empty recorder
+func NewRequestRecorder(cfg RequestRecorderConfig) *RequestRecorder {
+	if cfg.Size <= 0 {
+		cfg.Size = 100
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = 64 * 1024
+	}
+	return &RequestRecorder{
+		cfg:     cfg,
+		redact:  NewRedactor(cfg.RedactFields...),
+		entries: make([]*RecordedRequest, 0, cfg.Size),
+	}
+}
+
+// Middleware records each request once it has been served
+func (rr *RequestRecorder) Middleware() Middleware {
+	limit := rr.cfg.MaxBodySize
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			record := &RecordedRequest{
+				Time:           start,
+				Method:         r.Method,
+				Path:           r.URL.Path,
+				Query:          rr.redact.Query(r.URL.RawQuery),
+				RemoteAddr:     r.RemoteAddr,
+				RequestHeaders: rr.redact.Headers(r.Header),
+			}
+
+			var body []byte
+			if r.Body != nil && r.Body != http.NoBody {
+				// One byte over the limit tells a truncated body from one that fits
+				read, _ := io.ReadAll(io.LimitReader(r.Body, int64(limit)+1))
+				r.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(read), r.Body), r.Body}
+				body = read
+				if len(read) > limit {
+					body, record.RequestBodyTruncated = read[:limit], true
+				}
+			}
+			record.RequestBody = rr.body(body, r.Header.Get("Content-Type"))
+			if !record.RequestBodyTruncated {
+				record.replay = &replayRequest{
+					method:     r.Method,
+					target:     r.URL.RequestURI(),
+					host:       r.Host,
+					remoteAddr: r.RemoteAddr,
+					header:     r.Header.Clone(),
+					body:       body,
+				}
+			}
+
+			// Queries from goroutines outliving the request are dropped once it is recorded
+			var queriesMu sync.Mutex
+			done := false
+			ctx := orm.WithQueryRecorder(r.Context(), func(query orm.RecordedQuery) {
+				queriesMu.Lock()
+				defer queriesMu.Unlock()
+				switch {
+				case done:
+				case len(record.Queries) >= maxRecordedQueries:
+					record.QueriesDropped++
+				default:
+					record.Queries = append(record.Queries, query)
+				}
+			})
+
+			recorder := &recorderWriter{ResponseWriter: w, limit: limit}
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			queriesMu.Lock()
+			done = true
+			queriesMu.Unlock()
+
+			record.Duration = time.Since(start)
+			record.Status = recorder.status
+			if record.Status == 0 {
+				record.Status = http.StatusOK
+			}
+			record.ResponseHeaders = rr.redact.Headers(w.Header())
+			record.ResponseBody = rr.body(recorder.body.Bytes(), w.Header().Get("Content-Type"))
+			record.ResponseBodyTruncated = recorder.truncated
+			if result, ok := r.Context().Value(replayKey{}).(*replayResult); ok {
+				record.ReplayOf = result.of
+				result.record = record
+			}
+			rr.add(record)
+		})
+	}
+}
+
+// body returns a redacted body for display, or a summary of a binary one
+func (rr *RequestRecorder) body(body []byte, contentType string) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if !utf8.Valid(body) && !strings.HasPrefix(contentType, "multipart/") {
+		if contentType == "" {
+			contentType = http.DetectContentType(body)
+		}
+		return fmt.Sprintf("[%d bytes of %s]", len(body), contentType)
+	}
+	return rr.redact.Body(body, contentType)
+}
+
+func (rr *RequestRecorder) add(record *RecordedRequest) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	rr.lastID++
+	record.ID = rr.lastID
+	if len(rr.entries) < rr.cfg.Size {
+		rr.entries = append(rr.entries, record)
+		return
+	}
+	rr.entries[rr.next] = record
+	rr.next = (rr.next + 1) % rr.cfg.Size
+}
+
+// Recent returns the recorded requests, newest first
+func (rr *RequestRecorder) Recent() []*RecordedRequest {
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+
+	recent := make([]*RecordedRequest, 0, len(rr.entries))
+	for i := 1; i <= len(rr.entries); i++ {
+		recent = append(recent, rr.entries[(rr.next-i+len(rr.entries))%len(rr.entries)])
+	}
+	return recent
+}
+
+// Find returns a recorded request by ID, unless it has been dropped since
+func (rr *RequestRecorder) Find(id uint64) (*RecordedRequest, bool) {
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+
+	for _, record := range rr.entries {
+		if record.ID == id {
+			return record, true
+		}
+	}
+	return nil, false
+}
+
+// Clear drops the recorded requests
+func (rr *RequestRecorder) Clear() {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	rr.entries = rr.entries[:0]
+	rr.next = 0
+}
+
+// Replay serves a recorded request again through handler, with its original
+// headers and body, and returns the record of the replay. Handler must include
+// the recorder's middleware, usually as the application's handler.
+func (rr *RequestRecorder) Replay(id uint64, handler http.Handler) (*RecordedRequest, error) {
+	original, ok := rr.Find(id)
+	if !ok {
+		return nil, fmt.Errorf("request %d is no longer recorded", id)
+	}
+	if original.replay == nil {
+		return nil, fmt.Errorf("request %d has a body over %d bytes, which was not kept", id, rr.cfg.MaxBodySize)
+	}
+
+	src := original.replay
+	result := &replayResult{of: id}
+	ctx := context.WithValue(context.Background(), replayKey{}, result)
+	req, err := http.NewRequestWithContext(ctx, src.method, src.target, bytes.NewReader(src.body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = src.header.Clone()
+	req.Host = src.host
+	req.RemoteAddr = src.remoteAddr
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if result.record == nil {
+		return nil, fmt.Errorf("the replay of request %d was not recorded", id)
+	}
+	return result.record, nil
+}
+
+// recorderWriter captures the status code and the first bytes of the response body
+type recorderWriter struct {
+	http.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (rw *recorderWriter) WriteHeader(code int) {
+	if rw.status == 0 {
+		rw.status = code
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recorderWriter) Write(b []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	captured := b
+	if remaining := rw.limit - rw.body.Len(); remaining < len(b) {
+		captured, rw.truncated = b[:max(remaining, 0)], true
+	}
+	rw.body.Write(captured)
+	return rw.ResponseWriter.Write(b)
+}
+
+// Flush lets streaming handlers flush through the recorder
+func (rw *recorderWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap gives http.ResponseController the underlying writer
+func (rw *recorderWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/cache"
+)
+
+// ResponseCacheConfig configures full-response caching of GET requests
+type ResponseCacheConfig struct {
+	Cache *cache.Cache
+	TTL   time.Duration // default: the cache's default TTL
+	Tags  []string      // tags for invalidating the cached pages, e.g. "posts"
+	// Key builds the cache key; defaults to the path, query string and the
+	// Accept, Accept-Encoding and Accept-Language headers
+	Key func(r *http.Request) string
+	// Skip bypasses the cache; defaults to skipping requests with an
+	// Authorization or Cookie header, whose pages may be user-specific
+	Skip func(r *http.Request) bool
+}
+
+// cachedResponse is the stored form of a response
+type cachedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// ResponseCache middleware serves GET responses from the cache. Only 200 responses
+// are stored, and not when they set cookies or are marked private or no-store.
+// Responses carry X-Cache: HIT or MISS.
+func ResponseCache(cfg ResponseCacheConfig) Middleware {
+	if cfg.Key == nil {
+		cfg.Key = func(r *http.Request) string {
+			return negotiationKey("response:"+r.URL.RequestURI(), r)
+		}
+	}
+	if cfg.Skip == nil {
+		cfg.Skip = func(r *http.Request) bool {
+			return r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != ""
+		}
+	}
+	store := cfg.Cache
+	if len(cfg.Tags) > 0 {
+		store = store.Tags(cfg.Tags...)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || cfg.Skip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			key := cfg.Key(r)
+			var cached cachedResponse
+			if err := store.Get(ctx, key, &cached); err == nil {
+				for name, values := range cached.Header {
+					w.Header()[name] = values
+				}
+				w.Header().Set("X-Cache", "HIT")
+				w.WriteHeader(cached.Status)
+				_, _ = w.Write(cached.Body)
+				return
+			}
+
+			rec := newRecordedResponse()
+			next.ServeHTTP(rec, r)
+			rec.header.Set("X-Cache", "MISS")
+
+			if cacheableResponse(rec) {
+				header := rec.header.Clone()
+				header.Del("X-Cache")
+				_ = store.Set(ctx, key, cachedResponse{
+					Status: rec.statusCode,
+					Header: header,
+					Body:   rec.body.Bytes(),
+				}, cfg.TTL)
+			}
+			rec.writeTo(w)
+		})
+	}
+}
+
+func cacheableResponse(rec *recordedResponse) bool {
+	if rec.statusCode != http.StatusOK || rec.header.Get("Set-Cookie") != "" {
+		return false
+	}
+	control := strings.ToLower(rec.header.Get("Cache-Control"))
+	return !strings.Contains(control, "private") && !strings.Contains(control, "no-store")
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/cache"
+)
+
+func cachedPages(t *testing.T, handler http.HandlerFunc) http.Handler {
+	t.Helper()
+	c := cache.New(cache.NewMemoryStore(0), time.Minute)
+	return ResponseCache(ResponseCacheConfig{Cache: c})(handler)
+}
+
+func getPage(h http.Handler, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/page", nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestResponseCacheSkipsCookies(t *testing.T) {
+	h := cachedPages(t, func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("session"); err == nil {
+			_, _ = w.Write([]byte("hello " + c.Value))
+			return
+		}
+		_, _ = w.Write([]byte("hello guest"))
+	})
+
+	rec := getPage(h, http.Header{"Cookie": {"session=alice"}})
+	if rec.Body.String() != "hello alice" || rec.Header().Get("X-Cache") != "" {
+		t.Fatalf("cookie request = %q X-Cache %q, want an uncached page", rec.Body.String(), rec.Header().Get("X-Cache"))
+	}
+	if rec := getPage(h, nil); rec.Body.String() != "hello guest" {
+		t.Errorf("anonymous request got %q", rec.Body.String())
+	}
+	if rec := getPage(h, http.Header{"Authorization": {"Bearer x"}}); rec.Header().Get("X-Cache") != "" {
+		t.Error("a request with Authorization was cached")
+	}
+}
+
+func TestResponseCacheSkipsPrivateResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+	}{
+		{"set-cookie", http.Header{"Set-Cookie": {"session=abc"}}},
+		{"private", http.Header{"Cache-Control": {"private, max-age=60"}}},
+		{"no-store", http.Header{"Cache-Control": {"no-store"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			h := cachedPages(t, func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				for name, values := range tt.header {
+					w.Header()[name] = values
+				}
+				_, _ = w.Write([]byte("page"))
+			})
+			getPage(h, nil)
+			if rec := getPage(h, nil); rec.Header().Get("X-Cache") != "MISS" || calls != 2 {
+				t.Errorf("second request X-Cache = %q after %d calls, want MISS after 2", rec.Header().Get("X-Cache"), calls)
+			}
+		})
+	}
+}
+
+func TestResponseCacheKeyNegotiation(t *testing.T) {
+	calls := 0
+	h := cachedPages(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Vary", "Accept, Accept-Encoding, Accept-Language")
+		_, _ = w.Write([]byte(r.Header.Get("Accept") + "|" + r.Header.Get("Accept-Encoding") + "|" + r.Header.Get("Accept-Language")))
+	})
+
+	variants := []http.Header{
+		{"Accept": {"text/html"}},
+		{"Accept": {"application/json"}},
+		{"Accept": {"text/html"}, "Accept-Encoding": {"gzip"}},
+		{"Accept": {"text/html"}, "Accept-Language": {"fr"}},
+	}
+	for _, header := range variants {
+		getPage(h, header)
+	}
+	if calls != len(variants) {
+		t.Fatalf("handler ran %d times for %d variants", calls, len(variants))
+	}
+
+	for _, header := range variants {
+		rec := getPage(h, header)
+		want := header.Get("Accept") + "|" + header.Get("Accept-Encoding") + "|" + header.Get("Accept-Language")
+		if rec.Header().Get("X-Cache") != "HIT" || rec.Body.String() != want {
+			t.Errorf("%v: X-Cache %q body %q, want HIT %q", header, rec.Header().Get("X-Cache"), rec.Body.String(), want)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+)
+
+// StickyPrimary sends a request's reads to the primary database once it has written,
+// so it reads its own writes when read replicas lag. Queries must use the request
+// context, e.g. orm.Conn(r.Context(), db) or a repository.
+func StickyPrimary(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(orm.WithStickyPrimary(r.Context())))
+	})
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+)
+
+// Timezone sets the request's timezone to the IANA name returned by resolve, such as
+// the signed-in user's profile setting or a cookie set by the browser. Handlers then
+// get it from ctx.Location() and ctx.Localize(t), and templates as .timezone.
+// Empty or unknown names leave the application timezone in place.
+func Timezone(resolve func(r *http.Request) string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if name := resolve(r); name != "" {
+				if loc, err := bourbon.LoadLocation(name); err == nil {
+					r = r.WithContext(bourbon.WithLocation(r.Context(), loc))
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+	"gorm.io/gorm"
+)
+
+// TransactionConfig configures the Transaction middleware
+type TransactionConfig struct {
+	DB *gorm.DB
+	// Methods wrapped in a transaction; defaults to POST, PUT, PATCH and DELETE
+	Methods []string
+	// Commit decides whether to commit after the handler ran; defaults to status < 400
+	Commit func(status int) bool
+}
+
+// Transaction wraps each mutating request in a database transaction carried on the
+// request context. Handlers reach it with orm.Conn(r.Context(), db), and repositories
+// join it automatically. The transaction commits when the response status is below 400
+// and rolls back otherwise or when the handler panics.
+//
+// The response is buffered until the commit succeeds, so a failed commit is reported
+// as a 500 instead of a success the database never recorded.
+func Transaction(cfg TransactionConfig) Middleware {
+	methods := make(map[string]bool)
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+	for _, m := range cfg.Methods {
+		methods[m] = true
+	}
+	if cfg.Commit == nil {
+		cfg.Commit = func(status int) bool { return status < http.StatusBadRequest }
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !methods[r.Method] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if _, ok := orm.TxFromContext(r.Context()); ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			tx := cfg.DB.WithContext(r.Context()).Begin()
+			if tx.Error != nil {
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+
+			rec := newRecordedResponse()
+			committed := false
+			defer func() {
+				if !committed {
+					tx.Rollback()
+				}
+			}()
+
+			ctx, runCommitHooks := orm.ContextWithCommitHooks(orm.ContextWithTx(r.Context(), tx))
+			next.ServeHTTP(rec, r.WithContext(ctx))
+
+			if !cfg.Commit(rec.statusCode) {
+				rec.writeTo(w)
+				return
+			}
+			if err := tx.Commit().Error; err != nil {
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+			committed = true
+			runCommitHooks()
+			rec.writeTo(w)
+		})
+	}
+}
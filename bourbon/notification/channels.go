@@ -0,0 +1,223 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/mail"
+)
+
+// MailNotification is a notification sent through the mail channel
+type MailNotification interface {
+	ToMail(to Notifiable) *mail.Message
+}
+
+// SlackMessage is the payload of a Slack incoming webhook
+type SlackMessage struct {
+	Text      string        `json:"text"`
+	Username  string        `json:"username,omitempty"`
+	IconEmoji string        `json:"icon_emoji,omitempty"`
+	Channel   string        `json:"channel,omitempty"`
+	Blocks    []interface{} `json:"blocks,omitempty"`
+}
+
+// SlackNotification is a notification sent through the slack channel
+type SlackNotification interface {
+	ToSlack(to Notifiable) *SlackMessage
+}
+
+// WebhookNotification is a notification posted as JSON through the webhook channel
+type WebhookNotification interface {
+	ToWebhook(to Notifiable) interface{}
+}
+
+// SMSNotification is a notification sent as a text message through the sms channel
+type SMSNotification interface {
+	ToSMS(to Notifiable) string
+}
+
+var defaultHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// MailChannel sends MailNotifications through a mailer. Recipients come from the
+// message, or else from the mail route, which may list several addresses.
+type MailChannel struct {
+	Mailer *mail.Mailer
+}
+
+func (c *MailChannel) Send(ctx context.Context, to Notifiable, n Notification) error {
+	payload, ok := n.(MailNotification)
+	if !ok {
+		return fmt.Errorf("%T does not implement MailNotification", n)
+	}
+	msg := payload.ToMail(to)
+	if msg == nil {
+		return nil
+	}
+	if len(msg.To) == 0 {
+		route := to.NotificationRoute(Mail)
+		if route == "" {
+			return errors.New("recipient has no mail route")
+		}
+		for _, address := range strings.Split(route, ",") {
+			msg.AddTo(strings.TrimSpace(address))
+		}
+	}
+	return c.Mailer.Send(ctx, msg)
+}
+
+// SlackChannel posts SlackNotifications to the recipient's Slack webhook URL, or to
+// WebhookURL when the recipient has none
+type SlackChannel struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+func (c *SlackChannel) Send(ctx context.Context, to Notifiable, n Notification) error {
+	payload, ok := n.(SlackNotification)
+	if !ok {
+		return fmt.Errorf("%T does not implement SlackNotification", n)
+	}
+	msg := payload.ToSlack(to)
+	if msg == nil {
+		return nil
+	}
+	target := firstRoute(to.NotificationRoute(Slack), c.WebhookURL)
+	if target == "" {
+		return errors.New("no Slack webhook URL")
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return post(ctx, c.Client, target, "application/json", body, nil)
+}
+
+// WebhookChannel posts WebhookNotifications as JSON to the recipient's webhook URL,
+// or to URL when the recipient has none. With a Secret, the request carries an
+// X-Signature header: the hex HMAC-SHA256 of the body.
+type WebhookChannel struct {
+	URL     string
+	Secret  string
+	Headers map[string]string
+	Client  *http.Client
+}
+
+func (c *WebhookChannel) Send(ctx context.Context, to Notifiable, n Notification) error {
+	payload, ok := n.(WebhookNotification)
+	if !ok {
+		return fmt.Errorf("%T does not implement WebhookNotification", n)
+	}
+	target := firstRoute(to.NotificationRoute(Webhook), c.URL)
+	if target == "" {
+		return errors.New("no webhook URL")
+	}
+	body, err := json.Marshal(payload.ToWebhook(to))
+	if err != nil {
+		return err
+	}
+	header := http.Header{}
+	for key, value := range c.Headers {
+		header.Set(key, value)
+	}
+	if c.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(c.Secret))
+		mac.Write(body)
+		header.Set("X-Signature", hex.EncodeToString(mac.Sum(nil)))
+	}
+	return post(ctx, c.Client, target, "application/json", body, header)
+}
+
+// SMSSender sends a text message to a phone number
+type SMSSender interface {
+	SendSMS(ctx context.Context, to, body string) error
+}
+
+// SMSChannel sends SMSNotifications to the recipient's sms route
+type SMSChannel struct {
+	Sender SMSSender
+}
+
+func (c *SMSChannel) Send(ctx context.Context, to Notifiable, n Notification) error {
+	payload, ok := n.(SMSNotification)
+	if !ok {
+		return fmt.Errorf("%T does not implement SMSNotification", n)
+	}
+	number := to.NotificationRoute(SMS)
+	if number == "" {
+		return errors.New("recipient has no sms route")
+	}
+	body := payload.ToSMS(to)
+	if body == "" {
+		return nil
+	}
+	return c.Sender.SendSMS(ctx, number, body)
+}
+
+// TwilioSender sends text messages through the Twilio REST API
+type TwilioSender struct {
+	AccountSID string
+	AuthToken  string
+	From       string // sending phone number
+	Client     *http.Client
+}
+
+func (s *TwilioSender) SendSMS(ctx context.Context, to, body string) error {
+	form := url.Values{"To": {to}, "From": {s.From}, "Body": {body}}
+	endpoint := "https://api.twilio.com/2010-04-01/Accounts/" + url.PathEscape(s.AccountSID) + "/Messages.json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.AccountSID, s.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return do(s.Client, req)
+}
+
+// post sends body to target and fails on non-2xx responses
+func post(ctx context.Context, client *http.Client, target, contentType string, body []byte, header http.Header) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", contentType)
+	return do(client, req)
+}
+
+func do(client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = defaultHTTPClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %d: %s", req.URL.Host, resp.StatusCode, bytes.TrimSpace(data))
+	}
+	return nil
+}
+
+func firstRoute(routes ...string) string {
+	for _, route := range routes {
+		if route != "" {
+			return route
+		}
+	}
+	return ""
+}
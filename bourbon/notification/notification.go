@@ -0,0 +1,171 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/jobs"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+	"go.uber.org/zap"
+)
+
+// Built-in channel names
+const (
+	Mail    = "mail"
+	Slack   = "slack"
+	Webhook = "webhook"
+	SMS     = "sms"
+)
+
+// Notifiable receives notifications. NotificationRoute returns its address on a
+// channel: an email address for mail, a phone number for sms, a URL for slack and
+// webhook. An empty route uses the channel's default, if any.
+type Notifiable interface {
+	NotificationRoute(channel string) string
+}
+
+// Routes is a Notifiable for recipients that are not models, e.g. an on-call team:
+//
+//	notification.Routes{notification.Mail: "oncall@example.com", notification.Slack: webhookURL}
+type Routes map[string]string
+
+func (r Routes) NotificationRoute(channel string) string {
+	return r[channel]
+}
+
+// Notification declares the channels it is sent through. It also implements the
+// payload interface of each channel: MailNotification, SlackNotification,
+// WebhookNotification or SMSNotification.
+type Notification interface {
+	Via(to Notifiable) []string
+}
+
+// Channel delivers notifications. Channels return an error when the notification
+// does not implement their payload interface.
+type Channel interface {
+	Send(ctx context.Context, to Notifiable, n Notification) error
+}
+
+// Options configures a Notifier
+type Options struct {
+	Queue  jobs.Queue // runs SendAsync; without it SendAsync sends synchronously
+	Logger *logging.Logger
+}
+
+// Notifier sends notifications through registered channels
+type Notifier struct {
+	channels map[string]Channel
+	mu       sync.RWMutex
+	opts     Options
+	logger   *logging.Logger
+}
+
+// NewNotifier creates a notifier with no channels
+func NewNotifier(opts Options) *Notifier {
+	n := &Notifier{channels: make(map[string]Channel), opts: opts}
+	if opts.Logger != nil {
+		n.logger = opts.Logger.Module(logging.ModuleNotify)
+	}
+	return n
+}
+
+// Register adds or replaces the channel with the given name
+func (n *Notifier) Register(name string, channel Channel) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.channels[name] = channel
+}
+
+// Channel returns the channel registered under name
+func (n *Notifier) Channel(name string) (Channel, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	channel, ok := n.channels[name]
+	return channel, ok
+}
+
+// Channels returns the registered channel names, sorted
+func (n *Notifier) Channels() []string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	names := make([]string, 0, len(n.channels))
+	for name := range n.channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Send delivers notification to each recipient on every channel it declares. A failed
+// channel does not stop the others; the errors are joined.
+//
+//	err := app.Notifier.Send(ctx, &InvoicePaid{Invoice: invoice}, user)
+func (n *Notifier) Send(ctx context.Context, notification Notification, to ...Notifiable) error {
+	deliveries, err := n.deliveries(notification, to)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, d := range deliveries {
+		if err := d.send(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SendAsync queues one job per recipient and channel. Unknown channels are reported
+// now; delivery errors are logged by the queue. The notification is read from the
+// worker goroutines, so do not change it after calling SendAsync.
+func (n *Notifier) SendAsync(ctx context.Context, notification Notification, to ...Notifiable) error {
+	if n.opts.Queue == nil {
+		return n.Send(ctx, notification, to...)
+	}
+	deliveries, err := n.deliveries(notification, to)
+	if err != nil {
+		return err
+	}
+	for _, d := range deliveries {
+		if err := n.opts.Queue.Enqueue(ctx, "notification."+d.name, d.send); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// delivery is a notification bound to one recipient and channel
+type delivery struct {
+	name         string
+	channel      Channel
+	to           Notifiable
+	notification Notification
+	logger       *logging.Logger
+}
+
+func (d delivery) send(ctx context.Context) error {
+	if err := d.channel.Send(ctx, d.to, d.notification); err != nil {
+		return fmt.Errorf("notification: %s: %w", d.name, err)
+	}
+	if d.logger != nil {
+		d.logger.Debug("Notification sent", zap.String("channel", d.name), zap.String("notification", fmt.Sprintf("%T", d.notification)))
+	}
+	return nil
+}
+
+// deliveries resolves the channels of notification for each recipient
+func (n *Notifier) deliveries(notification Notification, to []Notifiable) ([]delivery, error) {
+	var result []delivery
+	for _, recipient := range to {
+		for _, name := range notification.Via(recipient) {
+			channel, ok := n.Channel(name)
+			if !ok {
+				return nil, fmt.Errorf("notification: channel %q is not registered", name)
+			}
+			result = append(result, delivery{name: name, channel: channel, to: recipient, notification: notification, logger: n.logger})
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,257 @@
+// Package openapi generates an OpenAPI 3 document from the router's routes and the
+// request and response types documented on them.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	bourbon "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+)
+
+// Version is the OpenAPI version of generated documents
+const Version = "3.0.3"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components *Components         `json:"components,omitempty"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Server is a base URL of the API
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps lower-case HTTP methods to operations
+type PathItem map[string]*Operation
+
+// Operation documents one route
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+}
+
+// Parameter is a path or query parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// ParameterProvider lets a Route.QueryParams value list its parameters itself
+type ParameterProvider interface {
+	OpenAPIParameters() []Parameter
+}
+
+// RequestBody documents a JSON request body
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response documents a response status
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas referenced by operations
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// Options configures Generate
+type Options struct {
+	Title       string
+	Version     string
+	Description string
+	Servers     []string
+	Prefix      string // only document routes under this path, e.g. "/api"
+}
+
+// Generate builds a document from routes. Routes marked Hidden and routes outside
+// opts.Prefix are left out; undocumented routes are listed with a bare 200 response.
+func Generate(routes []bourbon.Route, opts Options) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: opts.Title, Version: opts.Version, Description: opts.Description},
+		Paths:   make(map[string]PathItem),
+	}
+	if doc.Info.Title == "" {
+		doc.Info.Title = "API"
+	}
+	if doc.Info.Version == "" {
+		doc.Info.Version = "1.0.0"
+	}
+	for _, url := range opts.Servers {
+		doc.Servers = append(doc.Servers, Server{URL: url})
+	}
+
+	schemas := newSchemaGenerator()
+	prefix := strings.TrimSuffix(opts.Prefix, "/")
+	for _, route := range routes {
+		if route.Doc.Hidden {
+			continue
+		}
+		if prefix != "" && route.Pattern != prefix && !strings.HasPrefix(route.Pattern, prefix+"/") {
+			continue
+		}
+
+		path, params := convertPattern(route.Pattern)
+		op := &Operation{
+			OperationID: operationID(route.Method, path),
+			Summary:     route.Doc.Summary,
+			Description: route.Doc.Description,
+			Tags:        route.Doc.Tags,
+			Deprecated:  route.Doc.Deprecated,
+			Responses:   make(map[string]*Response),
+		}
+		for _, name := range params {
+			op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+		if provider, ok := route.Doc.Query.(ParameterProvider); ok {
+			op.Parameters = append(op.Parameters, provider.OpenAPIParameters()...)
+		} else if route.Doc.Query != nil {
+			op.Parameters = append(op.Parameters, schemas.queryParameters(reflect.TypeOf(route.Doc.Query))...)
+		}
+		if route.Doc.Request != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{"application/json": {Schema: schemas.bodySchema(route.Doc.Request)}},
+			}
+		}
+		for status, body := range route.Doc.Responses {
+			response := &Response{Description: http.StatusText(status)}
+			if response.Description == "" {
+				response.Description = "Response"
+			}
+			if body != nil {
+				response.Content = map[string]MediaType{"application/json": {Schema: schemas.bodySchema(body)}}
+			}
+			op.Responses[strconv.Itoa(status)] = response
+		}
+		if len(op.Responses) == 0 {
+			op.Responses["200"] = &Response{Description: "OK"}
+		}
+
+		item := doc.Paths[path]
+		if item == nil {
+			item = make(PathItem)
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = op
+	}
+
+	if len(schemas.components) > 0 {
+		doc.Components = &Components{Schemas: schemas.components}
+	}
+	return doc
+}
+
+// JSON returns the document as indented JSON
+func (d *Document) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// WriteFile writes the document as JSON to path, creating its directory if needed
+func (d *Document) WriteFile(path string) error {
+	data, err := d.JSON()
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Operations returns the documented "METHOD /path" pairs in sorted order
+func (d *Document) Operations() []string {
+	var ops []string
+	for path, item := range d.Paths {
+		for method := range item {
+			ops = append(ops, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(ops)
+	return ops
+}
+
+// convertPattern turns a router pattern into an OpenAPI path and its parameter names.
+// Both "{id}" and ":id" segments are parameters; "{path...}" becomes "{path}".
+func convertPattern(pattern string) (string, []string) {
+	var params []string
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		switch {
+		case segment == "{$}":
+			segments[i] = ""
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+			name := strings.TrimSuffix(segment[1:len(segment)-1], "...")
+			params = append(params, name)
+			segments[i] = "{" + name + "}"
+		case strings.HasPrefix(segment, ":"):
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	path := strings.Join(segments, "/")
+	if path == "" {
+		path = "/"
+	}
+	return path, params
+}
+
+// operationID derives an ID such as "get_users_id" from the method and path
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(path, "/") {
+		segment = strings.Trim(segment, "{}")
+		if segment == "" {
+			continue
+		}
+		b.WriteByte('_')
+		for _, r := range segment {
+			if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+				b.WriteRune(r)
+			} else {
+				b.WriteByte('_')
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,263 @@
+package openapi
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Schema is an OpenAPI schema object
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Example              interface{}        `json:"example,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	ReadOnly             bool               `json:"readOnly,omitempty"`
+	WriteOnly            bool               `json:"writeOnly,omitempty"`
+}
+
+// SchemaProvider lets a type describe its own schema
+type SchemaProvider interface {
+	OpenAPISchema() *Schema
+}
+
+var (
+	timeType           = reflect.TypeOf(time.Time{})
+	rawMessageType     = reflect.TypeOf(json.RawMessage{})
+	schemaProviderType = reflect.TypeOf((*SchemaProvider)(nil)).Elem()
+)
+
+// knownTypes are struct types that serialize as scalars
+var knownTypes = map[reflect.Type]Schema{
+	timeType:                          {Type: "string", Format: "date-time"},
+	reflect.TypeOf(gorm.DeletedAt{}):  {Type: "string", Format: "date-time", Nullable: true},
+	reflect.TypeOf(sql.NullTime{}):    {Type: "string", Format: "date-time", Nullable: true},
+	reflect.TypeOf(sql.NullString{}):  {Type: "string", Nullable: true},
+	reflect.TypeOf(sql.NullInt64{}):   {Type: "integer", Format: "int64", Nullable: true},
+	reflect.TypeOf(sql.NullInt32{}):   {Type: "integer", Format: "int32", Nullable: true},
+	reflect.TypeOf(sql.NullBool{}):    {Type: "boolean", Nullable: true},
+	reflect.TypeOf(sql.NullFloat64{}): {Type: "number", Format: "double", Nullable: true},
+}
+
+// schemaGenerator converts Go types to schemas. Named structs become components
+// referenced with $ref, unless the generator inlines them.
+type schemaGenerator struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+	inline     bool
+	visiting   map[reflect.Type]bool
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{components: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+}
+
+// SchemaOf returns the schema of a Go type with nested structs inlined. A struct
+// that contains itself is cut off as a plain object.
+func SchemaOf(t reflect.Type) *Schema {
+	g := &schemaGenerator{inline: true, visiting: make(map[reflect.Type]bool)}
+	return g.schema(t)
+}
+
+// bodySchema describes a documented body. A body value implementing SchemaProvider,
+// such as a serializer, describes itself.
+func (g *schemaGenerator) bodySchema(body interface{}) *Schema {
+	if provider, ok := body.(SchemaProvider); ok {
+		return provider.OpenAPISchema()
+	}
+	return g.schema(reflect.TypeOf(body))
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) *Schema {
+	if provider, ok := schemaProvider(t); ok {
+		return provider.OpenAPISchema()
+	}
+	if t.Kind() == reflect.Ptr {
+		s := g.schema(t.Elem())
+		if s.Ref == "" {
+			s.Nullable = true
+		}
+		return s
+	}
+	if known, ok := knownTypes[t]; ok {
+		return &known
+	}
+	if t == rawMessageType {
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer"}
+	case reflect.Int32, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		if g.inline {
+			if g.visiting[t] {
+				return &Schema{Type: "object"}
+			}
+			g.visiting[t] = true
+			defer delete(g.visiting, t)
+			return g.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + g.component(t)}
+	}
+	// interface{} and other kinds accept any value
+	return &Schema{}
+}
+
+// component registers a named struct and returns its component name
+func (g *schemaGenerator) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := g.components[name]; taken {
+		// Same name in another package
+		name = strings.ReplaceAll(t.PkgPath(), "/", ".") + "." + t.Name()
+	}
+	g.names[t] = name
+	g.components[name] = &Schema{}
+	*g.components[name] = *g.object(t)
+	return name
+}
+
+// object builds the schema of a struct from its exported fields. Field names follow
+// the json tag; `doc` and `example` tags set the description and example, and
+// `validate:"required"` marks a field required.
+func (g *schemaGenerator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.addFields(s, t)
+	return s
+}
+
+func (g *schemaGenerator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, skip := jsonName(field)
+		if skip {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if _, known := knownTypes[embedded]; !known {
+					g.addFields(s, embedded)
+					continue
+				}
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := g.schema(field.Type)
+		if prop.Ref == "" {
+			prop.Description = field.Tag.Get("doc")
+			if example, ok := field.Tag.Lookup("example"); ok {
+				prop.Example = example
+			}
+			if enum, ok := field.Tag.Lookup("enum"); ok {
+				prop.Enum = strings.Split(enum, ",")
+			}
+		}
+		s.Properties[name] = prop
+		if hasRule(field.Tag.Get("validate"), "required") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// schemaProvider returns a zero value of t, or of the type t points to, if it implements SchemaProvider
+func schemaProvider(t reflect.Type) (SchemaProvider, bool) {
+	if t.Kind() != reflect.Ptr {
+		t = reflect.PointerTo(t)
+	}
+	if !t.Implements(schemaProviderType) {
+		return nil, false
+	}
+	provider, ok := reflect.New(t.Elem()).Interface().(SchemaProvider)
+	return provider, ok
+}
+
+// queryParameters lists the fields of a struct tagged `query:"name"`
+func (g *schemaGenerator) queryParameters(t reflect.Type) []Parameter {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var params []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("query"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		params = append(params, Parameter{
+			Name:        name,
+			In:          "query",
+			Description: field.Tag.Get("doc"),
+			Required:    hasRule(field.Tag.Get("validate"), "required"),
+			Schema:      g.schema(field.Type),
+		})
+	}
+	return params
+}
+
+// jsonName returns the field's json name, empty when untagged, and whether json skips it
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	return strings.Split(tag, ",")[0], false
+}
+
+func hasRule(tag, rule string) bool {
+	for _, r := range strings.Split(tag, ",") {
+		if strings.TrimSpace(r) == rule {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package openapi
+
+import (
+	"html/template"
+	"strings"
+)
+
+var swaggerUITemplate = template.Must(template.New("swagger").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+<script>
+window.onload = function () {
+  window.ui = SwaggerUIBundle({ url: {{.SpecURL}}, dom_id: "#swagger-ui", deepLinking: true });
+};
+</script>
+</body>
+</html>
+`))
+
+// SwaggerUI returns an HTML page that renders the document at specURL with Swagger UI.
+// The Swagger UI assets are loaded from unpkg.com.
+func SwaggerUI(title, specURL string) (string, error) {
+	var b strings.Builder
+	err := swaggerUITemplate.Execute(&b, struct{ Title, SpecURL string }{title, specURL})
+	return b.String(), err
+}
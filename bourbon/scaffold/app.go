@@ -0,0 +1,98 @@
+package scaffold
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+// CreateApp creates apps/<name> with models, controllers, routes and a
+// migrations package, then installs it with InstallApp so it is live right away
+func CreateApp(name string) error {
+	if _, err := ModulePath(); err != nil {
+		return err
+	}
+	if name == "" || name != filepath.Base(name) || name[0] == '.' {
+		return fmt.Errorf("invalid app name %q", name)
+	}
+	dir := filepath.Join("apps", name)
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("%s already exists", dir)
+	}
+
+	fmt.Printf("Creating app: %s\n", name)
+	if err := os.MkdirAll(filepath.Join(dir, "migrations"), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	t, err := appTarget(name)
+	if err != nil {
+		return err
+	}
+
+	files := []struct {
+		name string
+		tmpl *template.Template
+	}{
+		{"models.go", appModelsTemplate},
+		{"controllers.go", appControllersTemplate},
+		{"routes.go", appRoutesTemplate},
+		{filepath.Join("migrations", "migrations.go"), appMigrationsTemplate},
+	}
+	data := map[string]string{"Package": t.Package, "App": t.App}
+	for _, file := range files {
+		if _, err := t.write(file.name, file.tmpl, data); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("App created: %s\n", name)
+
+	if err := InstallApp(name); err != nil {
+		fmt.Printf("Add '%s' to settings.toml under [apps] installed and register its routes in main.go\n", name)
+		return fmt.Errorf("failed to register app: %w", err)
+	}
+	return nil
+}
+
+var appModelsTemplate = template.Must(template.New("models").Parse(`package {{.Package}}
+
+// Example model - uncomment and modify as needed, importing
+// github.com/ishubhamsingh2e/bourbon/bourbon/models
+// type YourModel struct {
+// 	models.BaseModel
+// 	Name string ` + "`gorm:\"size:255\" json:\"name\"`" + `
+// }
+`))
+
+var appControllersTemplate = template.Must(template.New("controllers").Parse(`package {{.Package}}
+
+// Add controllers here, or generate one with: bourbon make:controller <name> --app={{.App}}
+`))
+
+var appRoutesTemplate = template.Must(template.New("routes").Parse(`package {{.Package}}
+
+import (
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+)
+
+// RegisterRoutes registers all routes for this app under the given prefix
+// prefix examples: "/", "/api", "/admin", etc.
+func RegisterRoutes(app *core.Application, prefix string) {
+	// Create a route group for this app
+	group := app.Router.Group(prefix)
+
+	// Register your routes here
+	// Example, with a controller from make:controller:
+	// items := NewItemController(app)
+	// group.Get("/items", items.Index)
+	// group.Get("/items/{id}", items.Show)
+	_ = group
+}
+`))
+
+var appMigrationsTemplate = template.Must(template.New("migrations").Parse(`package migrations
+
+// Migrations variable is used to ensure this package is imported
+// All migration files in this directory will auto-register via init()
+var Migrations = "migrations"
+`))
@@ -0,0 +1,348 @@
+package scaffold
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+)
+
+// goImage is the Go image the generated Dockerfile builds in
+const goImage = "golang:1.24-alpine"
+
+// Deployment parameterizes the generated Docker and Kubernetes files
+type Deployment struct {
+	Name      string // lowercase name for the image, containers and resources
+	Port      int
+	Database  core.DatabaseConfig
+	Templates string // template directory to ship, empty when there is none
+	Static    string // static directory to ship, empty when there is none
+	Image     string // image the Kubernetes deployment runs; Name:latest if empty
+	Replicas  int    // Kubernetes replicas; 1 for SQLite, 2 otherwise if zero
+}
+
+// DeploymentFromSettings reads the deployment parameters from the settings file
+// of the project in dir
+func DeploymentFromSettings(dir, settingsPath string) (*Deployment, error) {
+	cfg, err := core.LoadConfig(filepath.Join(dir, settingsPath))
+	if err != nil {
+		return nil, err
+	}
+
+	name := cfg.App.Name
+	if name == "" {
+		abs, _ := filepath.Abs(dir)
+		name = filepath.Base(abs)
+	}
+	d := &Deployment{Name: dnsLabel(name), Port: cfg.Server.Port, Database: cfg.Database}
+	if d.Port == 0 {
+		d.Port = 8000
+	}
+	if isDir(filepath.Join(dir, cfg.Templates.Directory)) && cfg.Templates.Directory != "" {
+		d.Templates = filepath.ToSlash(cfg.Templates.Directory)
+	}
+	if isDir(filepath.Join(dir, cfg.Static.Directory)) && cfg.Static.Directory != "" {
+		d.Static = filepath.ToSlash(cfg.Static.Directory)
+	}
+	return d, nil
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// dnsLabel turns a name into a lowercase DNS label, as Kubernetes and Docker
+// Compose names need
+func dnsLabel(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteByte('-')
+		}
+	}
+	label := strings.Trim(b.String(), "-")
+	if label == "" {
+		return "app"
+	}
+	return label
+}
+
+func (d *Deployment) sqlite() bool {
+	return d.Database.Driver == "sqlite" || d.Database.Driver == ""
+}
+
+// sqliteDir is the directory holding the SQLite database, which is kept on a volume
+func (d *Deployment) sqliteDir() string {
+	dir := filepath.ToSlash(filepath.Dir(d.Database.Path))
+	if d.Database.Path == "" || dir == "." {
+		return "storage"
+	}
+	return dir
+}
+
+// Docker writes a multi-stage Dockerfile, a .dockerignore and a
+// docker-compose.yml running the project with its database into dir. Existing
+// files are only replaced with force.
+func Docker(dir string, d *Deployment, force bool) ([]string, error) {
+	return writeFiles(dir, force, map[string]string{
+		"Dockerfile":         d.dockerfile(),
+		".dockerignore":      dockerignore,
+		"docker-compose.yml": d.compose(),
+	})
+}
+
+// Kubernetes writes a deployment, a service and a configmap into dir/k8s, plus
+// a persistent volume claim for SQLite. The deployment's liveness and readiness
+// probes use /healthz and /readyz. Existing files are only replaced with force.
+func Kubernetes(dir string, d *Deployment, force bool) ([]string, error) {
+	files := map[string]string{
+		"k8s/deployment.yaml": d.k8sDeployment(),
+		"k8s/service.yaml":    d.k8sService(),
+		"k8s/configmap.yaml":  d.k8sConfigMap(),
+	}
+	if d.sqlite() {
+		files["k8s/pvc.yaml"] = d.k8sVolumeClaim()
+	}
+	return writeFiles(dir, force, files)
+}
+
+// writeFiles writes files under dir, checking first that none exist unless
+// force is set, and returns their paths in order
+func writeFiles(dir string, force bool, files map[string]string) ([]string, error) {
+	var paths []string
+	for name := range files {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil && !force {
+			return nil, fmt.Errorf("%s already exists (use --force to replace it)", path)
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		rel, _ := filepath.Rel(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, []byte(files[filepath.ToSlash(rel)]), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return paths, nil
+}
+
+const dockerignore = `.git
+.bourbon
+tmp
+dist
+k8s
+storage/database.db
+storage/logs
+staticfiles
+.env
+`
+
+// dockerfile builds the project in a Go image and runs it from Alpine. SQLite
+// needs cgo, and so a C compiler in the build stage.
+func (d *Deployment) dockerfile() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Build stage\nFROM %s AS builder\n", goImage)
+	cgo := "0"
+	if d.sqlite() {
+		b.WriteString("RUN apk add --no-cache build-base\n")
+		cgo = "1"
+	}
+	b.WriteString("WORKDIR /src\nCOPY go.mod go.sum ./\nRUN go mod download\nCOPY . .\n")
+	fmt.Fprintf(&b, "RUN CGO_ENABLED=%s go build -o /out/server .\n\n", cgo)
+
+	b.WriteString("# Final stage\nFROM alpine:3.20\nWORKDIR /app\nCOPY --from=builder /out/server .\nCOPY settings*.toml ./\n")
+	for _, dir := range []string{d.Templates, d.Static} {
+		if dir != "" {
+			fmt.Fprintf(&b, "COPY %s ./%s\n", dir, dir)
+		}
+	}
+	b.WriteString("RUN mkdir -p storage/logs\n\n")
+	b.WriteString("# Listen on every interface rather than settings.toml's host\nENV BOURBON_SERVER_HOST=0.0.0.0\n")
+	fmt.Fprintf(&b, "EXPOSE %d\nCMD [\"./server\"]\n", d.Port)
+	return b.String()
+}
+
+// compose runs the project, with a container for its database when the image
+// needs no further setup. Migrations run with
+// docker compose run --rm app ./server migrate.
+func (d *Deployment) compose() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "services:\n  app:\n    build: .\n    ports:\n      - \"%d:%d\"\n    restart: on-failure\n", d.Port, d.Port)
+
+	db := d.Database
+	var service, volume string
+	switch db.Driver {
+	case "sqlite", "":
+		fmt.Fprintf(&b, "    volumes:\n      - storage:/app/%s\n", d.sqliteDir())
+		b.WriteString("\nvolumes:\n  storage:\n")
+		return b.String()
+	case "postgres":
+		service = fmt.Sprintf("    image: postgres:16-alpine\n    environment:\n      POSTGRES_USER: %q\n      POSTGRES_PASSWORD: %q\n      POSTGRES_DB: %q\n", db.User, db.Password, db.Name)
+		volume = "/var/lib/postgresql/data"
+	case "mysql":
+		service = fmt.Sprintf("    image: mysql:8.4\n    environment:\n      MYSQL_DATABASE: %q\n", db.Name)
+		if db.User == "root" {
+			service += fmt.Sprintf("      MYSQL_ROOT_PASSWORD: %q\n", db.Password)
+		} else {
+			service += fmt.Sprintf("      MYSQL_RANDOM_ROOT_PASSWORD: \"yes\"\n      MYSQL_USER: %q\n      MYSQL_PASSWORD: %q\n", db.User, db.Password)
+		}
+		volume = "/var/lib/mysql"
+	case "cockroach":
+		service = fmt.Sprintf("    image: cockroachdb/cockroach:latest-v24.1\n    command: start-single-node --insecure\n    environment:\n      COCKROACH_DATABASE: %q\n", db.Name)
+		volume = "/cockroach/cockroach-data"
+	default:
+		b.WriteString("    # Point the app at your database server\n    environment:\n      DB_HOST: host.docker.internal\n")
+		return b.String()
+	}
+
+	b.WriteString("    environment:\n      DB_HOST: db\n    depends_on:\n      - db\n\n  db:\n")
+	b.WriteString(service)
+	fmt.Fprintf(&b, "    volumes:\n      - dbdata:%s\n\nvolumes:\n  dbdata:\n", volume)
+	return b.String()
+}
+
+func (d *Deployment) image() string {
+	if d.Image != "" {
+		return d.Image
+	}
+	return d.Name + ":latest"
+}
+
+func (d *Deployment) replicas() int {
+	switch {
+	case d.sqlite():
+		// One pod owns the SQLite file
+		return 1
+	case d.Replicas > 0:
+		return d.Replicas
+	}
+	return 2
+}
+
+func (d *Deployment) k8sDeployment() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: %[1]s
+  labels:
+    app: %[1]s
+spec:
+  replicas: %[2]d
+`, d.Name, d.replicas())
+	if d.sqlite() {
+		b.WriteString("  strategy:\n    type: Recreate\n")
+	}
+	fmt.Fprintf(&b, `  selector:
+    matchLabels:
+      app: %[1]s
+  template:
+    metadata:
+      labels:
+        app: %[1]s
+    spec:
+      containers:
+        - name: %[1]s
+          image: %[2]s
+          ports:
+            - name: http
+              containerPort: %[3]d
+          envFrom:
+            - configMapRef:
+                name: %[1]s-config
+            # SECRET_KEY, DB_PASSWORD and other secrets:
+            # kubectl create secret generic %[1]s-secrets --from-literal=SECRET_KEY=...
+            - secretRef:
+                name: %[1]s-secrets
+                optional: true
+          livenessProbe:
+            httpGet:
+              path: %[4]s
+              port: http
+            initialDelaySeconds: 5
+            periodSeconds: 10
+          readinessProbe:
+            httpGet:
+              path: %[5]s
+              port: http
+            periodSeconds: 5
+            failureThreshold: 3
+`, d.Name, d.image(), d.Port, core.HealthPath, core.ReadyPath)
+	if d.sqlite() {
+		fmt.Fprintf(&b, `          volumeMounts:
+            - name: storage
+              mountPath: /app/%[2]s
+      volumes:
+        - name: storage
+          persistentVolumeClaim:
+            claimName: %[1]s-storage
+`, d.Name, d.sqliteDir())
+	}
+	return b.String()
+}
+
+func (d *Deployment) k8sService() string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Service
+metadata:
+  name: %[1]s
+  labels:
+    app: %[1]s
+spec:
+  selector:
+    app: %[1]s
+  ports:
+    - name: http
+      port: 80
+      targetPort: http
+`, d.Name)
+}
+
+// k8sConfigMap overrides the settings that differ in the cluster through the
+// BOURBON_<SECTION>_<KEY> environment variables
+func (d *Deployment) k8sConfigMap() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %[1]s-config
+  labels:
+    app: %[1]s
+data:
+  BOURBON_ENV: "production"
+  BOURBON_SERVER_HOST: "0.0.0.0"
+  BOURBON_SERVER_PORT: "%[2]d"
+`, d.Name, d.Port)
+	if !d.sqlite() {
+		db := d.Database
+		fmt.Fprintf(&b, "  # The database server as seen from the cluster\n  DB_HOST: %q\n  DB_PORT: \"%d\"\n  DB_NAME: %q\n  DB_USER: %q\n", db.Host, db.Port, db.Name, db.User)
+	}
+	return b.String()
+}
+
+func (d *Deployment) k8sVolumeClaim() string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: %[1]s-storage
+  labels:
+    app: %[1]s
+spec:
+  accessModes:
+    - ReadWriteOnce
+  resources:
+    requests:
+      storage: 1Gi
+`, d.Name)
+}
@@ -0,0 +1,284 @@
+package scaffold
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Controller creates apps/<app>/<name>_controller.go with a controller holding
+// the application. With resource it has Index, Show, Store, Update and Destroy
+// handlers, otherwise only Index.
+func Controller(appName, name string, resource bool) error {
+	t, err := appTarget(appName)
+	if err != nil {
+		return err
+	}
+	base, err := baseName(name, "Controller")
+	if err != nil {
+		return err
+	}
+
+	data := map[string]any{
+		"Package":  t.Package,
+		"Name":     base + "Controller",
+		"Resource": resource,
+	}
+	path, err := t.write(snakeCase(base)+"_controller.go", controllerTemplate, data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created controller: %s\n", path)
+	route := "/" + namer.TableName(base)
+	fmt.Printf("Register its routes in %s/routes.go:\n\n", t.Dir)
+	fmt.Printf("  %s := New%sController(app)\n", lowerFirst(base), base)
+	fmt.Printf("  group.Get(\"%s\", %s.Index)\n", route, lowerFirst(base))
+	if resource {
+		fmt.Printf("  group.Get(\"%s/{id}\", %s.Show)\n", route, lowerFirst(base))
+		fmt.Printf("  group.Post(\"%s\", %s.Store)\n", route, lowerFirst(base))
+		fmt.Printf("  group.Put(\"%s/{id}\", %s.Update)\n", route, lowerFirst(base))
+		fmt.Printf("  group.Delete(\"%s/{id}\", %s.Destroy)\n", route, lowerFirst(base))
+	}
+	fmt.Println()
+	return nil
+}
+
+// Middleware creates apps/<app>/<name>_middleware.go with a middleware
+// constructor to register with app.RegisterMiddleware
+func Middleware(appName, name string) error {
+	t, err := appTarget(appName)
+	if err != nil {
+		return err
+	}
+	base, err := baseName(name, "Middleware")
+	if err != nil {
+		return err
+	}
+
+	data := map[string]any{
+		"Package": t.Package,
+		"Name":    base + "Middleware",
+	}
+	path, err := t.write(snakeCase(base)+"_middleware.go", middlewareTemplate, data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created middleware: %s\n", path)
+	fmt.Printf("Register it in SetupMiddleware (middleware.go) and add %q to enabled under [middleware] in settings.toml:\n\n", snakeCase(base))
+	fmt.Printf("  app.RegisterMiddleware(%q, %s.%sMiddleware())\n\n", snakeCase(base), t.Package, base)
+	t.printImportHint()
+	return nil
+}
+
+// Command creates apps/<app>/<name>_command.go registering a CLI command, run
+// with `go run . <app>:<name>`. A name containing a colon is used as is.
+func Command(appName, name string) error {
+	t, err := appTarget(appName)
+	if err != nil {
+		return err
+	}
+
+	command := strings.TrimSpace(name)
+	if !strings.Contains(command, ":") {
+		command = t.App + ":" + strings.ReplaceAll(snakeCase(command), "_", "-")
+	}
+	base, err := baseName(command[strings.Index(command, ":")+1:], "Command")
+	if err != nil {
+		return err
+	}
+
+	data := map[string]any{
+		"Package": t.Package,
+		"Command": command,
+		"Func":    "handle" + base + "Command",
+	}
+	path, err := t.write(snakeCase(base)+"_command.go", commandTemplate, data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created command: %s\n", path)
+	fmt.Printf("Run it with: go run . %s\n", command)
+	t.printImportHint()
+	return nil
+}
+
+// Job creates apps/<app>/<name>_job.go with a job type that dispatches itself
+// on app.Jobs
+func Job(appName, name string) error {
+	t, err := appTarget(appName)
+	if err != nil {
+		return err
+	}
+	base, err := baseName(name, "Job")
+	if err != nil {
+		return err
+	}
+
+	data := map[string]any{
+		"Package": t.Package,
+		"Name":    base + "Job",
+		"JobName": t.App + "." + snakeCase(base),
+	}
+	path, err := t.write(snakeCase(base)+"_job.go", jobTemplate, data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created job: %s\n", path)
+	fmt.Printf("Dispatch it with:\n\n  err := (&%s.%sJob{App: app}).Dispatch(context.Background())\n\n", t.Package, base)
+	return nil
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+var controllerTemplate = template.Must(template.New("controller").Parse(`package {{.Package}}
+
+import (
+	"net/http"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	bourbonHttp "github.com/ishubhamsingh2e/bourbon/bourbon/http"
+)
+
+type {{.Name}} struct {
+	App *core.Application
+}
+
+func New{{.Name}}(app *core.Application) *{{.Name}} {
+	return &{{.Name}}{App: app}
+}
+
+// Index lists the items
+func (c *{{.Name}}) Index(ctx *bourbonHttp.Context) error {
+	return ctx.JSON(http.StatusOK, bourbonHttp.H{"data": []any{}})
+}
+{{- if .Resource}}
+
+// Show returns the item with the id in the path
+func (c *{{.Name}}) Show(ctx *bourbonHttp.Context) error {
+	id := ctx.Param("id")
+	return ctx.JSON(http.StatusOK, bourbonHttp.H{"id": id})
+}
+
+// Store creates an item from the JSON body
+func (c *{{.Name}}) Store(ctx *bourbonHttp.Context) error {
+	var input map[string]any
+	if err := ctx.Bind(&input); err != nil {
+		return ctx.JSON(http.StatusBadRequest, bourbonHttp.H{"error": "invalid JSON"})
+	}
+	return ctx.JSON(http.StatusCreated, input)
+}
+
+// Update changes the item with the id in the path from the JSON body
+func (c *{{.Name}}) Update(ctx *bourbonHttp.Context) error {
+	id := ctx.Param("id")
+	var input map[string]any
+	if err := ctx.Bind(&input); err != nil {
+		return ctx.JSON(http.StatusBadRequest, bourbonHttp.H{"error": "invalid JSON"})
+	}
+	return ctx.JSON(http.StatusOK, bourbonHttp.H{"id": id})
+}
+
+// Destroy deletes the item with the id in the path
+func (c *{{.Name}}) Destroy(ctx *bourbonHttp.Context) error {
+	_ = ctx.Param("id")
+	ctx.Status(http.StatusNoContent)
+	return nil
+}
+{{- end}}
+`))
+
+var middlewareTemplate = template.Must(template.New("middleware").Parse(`package {{.Package}}
+
+import (
+	"net/http"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/middleware"
+)
+
+// {{.Name}} wraps every request once registered with app.RegisterMiddleware and
+// enabled in settings.toml. Wrap it with bourbonHttp.Adapt to use it on a
+// single route group instead.
+func {{.Name}}() middleware.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Before the handler
+
+			next.ServeHTTP(w, r)
+
+			// After the handler
+		})
+	}
+}
+`))
+
+var commandTemplate = template.Must(template.New("command").Parse(`package {{.Package}}
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/cmd"
+)
+
+func init() {
+	cmd.Register(&cmd.Command{
+		Name:        "{{.Command}}",
+		Description: "TODO: describe {{.Command}}",
+		Flags: func(fs *flag.FlagSet) {
+			fs.Bool("dry-run", false, "Report what would change without changing anything")
+		},
+		Args: cmd.NoArgs,
+		Run:  {{.Func}},
+	})
+}
+
+// {{.Func}} runs ` + "`go run . {{.Command}}`" + `
+func {{.Func}}(c *cmd.CommandContext) error {
+	app, err := c.App()
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("dry-run") {
+		fmt.Printf("{{.Command}}: would run against %s\n", app.Config.App.Name)
+		return nil
+	}
+	fmt.Println("{{.Command}}: done")
+	return nil
+}
+`))
+
+var jobTemplate = template.Must(template.New("job").Parse(`package {{.Package}}
+
+import (
+	"context"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+)
+
+// {{.Name}} runs in the background on app.Jobs. Add the fields it needs as its
+// payload.
+type {{.Name}} struct {
+	App *core.Application
+}
+
+// Dispatch queues the job. Pass context.Background() rather than a request's
+// context, which ends with the response.
+func (j *{{.Name}}) Dispatch(ctx context.Context) error {
+	return j.App.Jobs.Enqueue(ctx, "{{.JobName}}", j.Run)
+}
+
+// Run does the work; a returned error or a panic is logged
+func (j *{{.Name}}) Run(ctx context.Context) error {
+	return nil
+}
+`))
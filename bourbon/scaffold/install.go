@@ -0,0 +1,276 @@
+package scaffold
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// sectionHeader matches a TOML table header such as [apps] or [database.options]
+	sectionHeader = regexp.MustCompile(`^\s*\[`)
+	// appsHeader matches the [apps] table header
+	appsHeader = regexp.MustCompile(`^\s*\[apps\]\s*(#.*)?$`)
+	// installedKey matches the start of the installed array
+	installedKey = regexp.MustCompile(`^\s*installed\s*=`)
+	// quotedString matches the entries of a TOML string array
+	quotedString = regexp.MustCompile(`"([^"]*)"`)
+	// lastEntry matches the last entry on a line of a multi-line array, without
+	// its trailing comma
+	lastEntry = regexp.MustCompile(`^(.*"[^"]*")(\s*(#.*)?)$`)
+)
+
+// InstallApp makes a new app live: it adds the app to installed under [apps] in
+// settings.toml, and imports the app and its migrations in main.go, mounting its
+// routes at /<app> in the cmd.SetCustomInit function. What is already there is
+// left alone. When main.go doesn't have that shape, the lines to add are printed
+// instead.
+func InstallApp(appName string) error {
+	t, err := appTarget(appName)
+	if err != nil {
+		return err
+	}
+
+	added, err := addInstalledApp("settings.toml", t.App)
+	if err != nil {
+		return err
+	}
+	if added {
+		fmt.Printf("Added %s to [apps] installed in settings.toml\n", t.App)
+	}
+
+	patched, err := t.registerInMain("main.go")
+	if err != nil {
+		return err
+	}
+	switch {
+	case patched:
+		fmt.Printf("Registered %s in main.go, with its routes under /%s\n", t.App, t.App)
+	case !t.imported():
+		fmt.Printf("Register the app in main.go, mounting its routes in the function passed to cmd.SetCustomInit:\n\n")
+		for _, spec := range t.importSpecs() {
+			fmt.Printf("  import %s\n", spec)
+		}
+		fmt.Printf("\n  %s\n\n", t.routesCall())
+	}
+	return nil
+}
+
+// addInstalledApp adds appName to installed under [apps] in a settings file,
+// keeping its layout and comments. It reports whether the file changed.
+func addInstalledApp(settingsPath, appName string) (bool, error) {
+	data, err := os.ReadFile(settingsPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	entry := strconv.Quote(appName)
+	lines := strings.Split(string(data), "\n")
+
+	header := -1
+	for i, line := range lines {
+		if appsHeader.MatchString(line) {
+			header = i
+			break
+		}
+	}
+	if header < 0 {
+		content := strings.TrimRight(string(data), "\n")
+		content += fmt.Sprintf("\n\n[apps]\ninstalled = [%s]\n", entry)
+		return true, os.WriteFile(settingsPath, []byte(content), 0644)
+	}
+
+	// The installed array runs from its key to the line closing it
+	start, end := -1, -1
+	for i := header + 1; i < len(lines) && !sectionHeader.MatchString(lines[i]); i++ {
+		if installedKey.MatchString(lines[i]) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		lines = insertLines(lines, header+1, "installed = ["+entry+"]")
+		return true, os.WriteFile(settingsPath, []byte(strings.Join(lines, "\n")), 0644)
+	}
+	for i := start; i < len(lines); i++ {
+		if strings.Contains(stripComment(lines[i]), "]") {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return false, fmt.Errorf("%s: installed under [apps] is not a closed array", settingsPath)
+	}
+
+	for _, line := range lines[start : end+1] {
+		for _, match := range quotedString.FindAllStringSubmatch(stripComment(line), -1) {
+			if match[1] == appName {
+				return false, nil
+			}
+		}
+	}
+
+	if start == end {
+		// installed = ["a", "b"]
+		line := lines[start]
+		code := stripComment(line)
+		closing := strings.LastIndex(code, "]")
+		inside := strings.TrimSpace(code[strings.Index(code, "[")+1 : closing])
+		separator := ", "
+		if inside == "" {
+			separator = ""
+		} else if strings.HasSuffix(inside, ",") {
+			separator = " "
+		}
+		lines[start] = strings.TrimRight(line[:closing], " ") + separator + entry + line[closing:]
+	} else {
+		// A multi-line array: give the last entry a trailing comma and add
+		// the app after it, with the same indentation
+		indent := "    "
+		for i := end - 1; i > start; i-- {
+			if quotedString.MatchString(stripComment(lines[i])) {
+				indent = lines[i][:len(lines[i])-len(strings.TrimLeft(lines[i], " \t"))]
+				if code := strings.TrimSpace(stripComment(lines[i])); !strings.HasSuffix(code, ",") {
+					if match := lastEntry.FindStringSubmatch(lines[i]); match != nil {
+						lines[i] = match[1] + "," + match[2]
+					}
+				}
+				break
+			}
+		}
+		lines = insertLines(lines, end, indent+entry+",")
+	}
+	return true, os.WriteFile(settingsPath, []byte(strings.Join(lines, "\n")), 0644)
+}
+
+// stripComment removes a trailing TOML comment from a line outside of strings
+func stripComment(line string) string {
+	inString := false
+	for i, r := range line {
+		switch {
+		case r == '"':
+			inString = !inString
+		case r == '#' && !inString:
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func insertLines(lines []string, at int, inserted ...string) []string {
+	return append(lines[:at], append(inserted, lines[at:]...)...)
+}
+
+// importSpecs returns the imports main.go needs for the app: its package, and
+// its migrations when it has any Go files there
+func (t *target) importSpecs() []string {
+	spec := strconv.Quote(t.ImportPath())
+	if t.Package != path.Base(t.ImportPath()) {
+		spec = t.Package + " " + spec
+	}
+	specs := []string{spec}
+	if files, _ := filepath.Glob(filepath.Join(t.Dir, "migrations", "*.go")); len(files) > 0 {
+		specs = append(specs, "_ "+strconv.Quote(t.ImportPath()+"/migrations"))
+	}
+	return specs
+}
+
+// routesCall is the statement that mounts the app's routes
+func (t *target) routesCall() string {
+	return fmt.Sprintf("%s.RegisterRoutes(app, %q)", t.Package, "/"+t.App)
+}
+
+// registerInMain imports the app in main.go and mounts its routes at the end of
+// the function passed to cmd.SetCustomInit. The file is edited where the AST
+// locates those places, so the rest of it keeps its comments and layout. It
+// reports false when main.go already imports the app or has no such function.
+func (t *target) registerInMain(mainPath string) (bool, error) {
+	src, err := os.ReadFile(mainPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, mainPath, src, parser.ParseComments)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", mainPath, err)
+	}
+	for _, spec := range file.Imports {
+		if importPath, _ := strconv.Unquote(spec.Path.Value); importPath == t.ImportPath() {
+			return false, nil
+		}
+	}
+
+	var initFunc *ast.FuncLit
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || initFunc != nil {
+			return initFunc == nil
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "SetCustomInit" && len(call.Args) == 1 {
+			initFunc, _ = call.Args[0].(*ast.FuncLit)
+		}
+		return true
+	})
+	if initFunc == nil {
+		return false, nil
+	}
+
+	type insertion struct {
+		offset int
+		text   string
+	}
+	var edits []insertion
+
+	// Mount the routes before the closing return nil, or at the end
+	body := initFunc.Body
+	at := fset.Position(body.Rbrace).Offset
+	if n := len(body.List); n > 0 {
+		if ret, ok := body.List[n-1].(*ast.ReturnStmt); ok {
+			at = fset.Position(ret.Pos()).Offset
+		}
+	}
+	at = strings.LastIndex(string(src[:at]), "\n") + 1
+	edits = append(edits, insertion{at, "\t\t" + t.routesCall() + "\n"})
+
+	// Add the imports to the last import declaration, or after the package clause
+	specs := strings.Join(t.importSpecs(), "\n\t")
+	var lastImport *ast.GenDecl
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			lastImport = gen
+		}
+	}
+	switch {
+	case lastImport != nil && lastImport.Lparen.IsValid():
+		edits = append(edits, insertion{fset.Position(lastImport.Rparen).Offset, "\t" + specs + "\n"})
+	case lastImport != nil:
+		edits = append(edits, insertion{fset.Position(lastImport.End()).Offset, "\n\nimport (\n\t" + specs + "\n)"})
+	default:
+		edits = append(edits, insertion{fset.Position(file.Name.End()).Offset, "\n\nimport (\n\t" + specs + "\n)"})
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].offset > edits[j].offset })
+	out := string(src)
+	for _, edit := range edits {
+		out = out[:edit.offset] + edit.text + out[edit.offset:]
+	}
+	formatted, err := format.Source([]byte(out))
+	if err != nil {
+		return false, fmt.Errorf("failed to update %s: %w", mainPath, err)
+	}
+	return true, os.WriteFile(mainPath, formatted, 0644)
+}
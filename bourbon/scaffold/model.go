@@ -0,0 +1,186 @@
+package scaffold
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// fieldTypes maps the types accepted in field specs to Go types and gorm settings
+var fieldTypes = map[string]struct{ goType, gorm string }{
+	"string":   {"string", "size:255"},
+	"text":     {"string", "type:text"},
+	"int":      {"int", ""},
+	"int64":    {"int64", ""},
+	"uint":     {"uint", ""},
+	"float":    {"float64", ""},
+	"decimal":  {"float64", "type:decimal(10,2)"},
+	"bool":     {"bool", ""},
+	"time":     {"time.Time", ""},
+	"datetime": {"time.Time", ""},
+	"date":     {"time.Time", "type:date"},
+	"json":     {"map[string]any", "serializer:json"},
+}
+
+// fieldModifiers are the options a field spec may end with
+var fieldModifiers = map[string]bool{
+	"unique":   true,
+	"index":    true,
+	"required": true,
+	"nullable": true,
+}
+
+// modelField is a field of a generated model
+type modelField struct {
+	Name string
+	Type string
+	Tag  string
+}
+
+// ParseField parses a field spec name:type[:modifier...], e.g. title:string:required,
+// email:string:unique or published_at:time:nullable. The type references adds a
+// belongs-to relation: author:references[:Model] gives AuthorID and Author.
+// Modifiers are unique, index, required (not null), nullable (a pointer) and
+// default=<value>.
+func ParseField(spec string) ([]modelField, error) {
+	parts := strings.Split(strings.TrimSpace(spec), ":")
+	if len(parts) < 2 || parts[0] == "" {
+		return nil, fmt.Errorf("invalid field %q: use name:type[:modifier...]", spec)
+	}
+	name := pascalCase(parts[0])
+	if !token.IsIdentifier(name) {
+		return nil, fmt.Errorf("invalid field name %q", parts[0])
+	}
+	typ, modifiers := strings.ToLower(parts[1]), parts[2:]
+
+	if typ == "references" || typ == "belongs_to" {
+		name = pascalCase(strings.TrimSuffix(parts[0], "_id"))
+		target := name
+		if len(modifiers) > 0 && !isModifier(modifiers[0]) {
+			target, modifiers = pascalCase(modifiers[0]), modifiers[1:]
+		}
+		key, err := newField(name+"ID", "uint", "index", modifiers)
+		if err != nil {
+			return nil, err
+		}
+		return []modelField{key, {Name: name, Type: target, Tag: jsonTag(name, "")}}, nil
+	}
+
+	fieldType, ok := fieldTypes[typ]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q in field %q (use %s or references)", parts[1], spec, strings.Join(typeNames(), ", "))
+	}
+	field, err := newField(name, fieldType.goType, fieldType.gorm, modifiers)
+	if err != nil {
+		return nil, err
+	}
+	return []modelField{field}, nil
+}
+
+func isModifier(s string) bool {
+	return fieldModifiers[strings.ToLower(s)] || strings.HasPrefix(strings.ToLower(s), "default=")
+}
+
+func newField(name, goType, gorm string, modifiers []string) (modelField, error) {
+	settings := []string{}
+	if gorm != "" {
+		settings = append(settings, gorm)
+	}
+	for _, modifier := range modifiers {
+		switch lower := strings.ToLower(modifier); {
+		case lower == "unique":
+			settings = append(settings, "uniqueIndex")
+		case lower == "index":
+			if gorm != "index" {
+				settings = append(settings, "index")
+			}
+		case lower == "required":
+			settings = append(settings, "not null")
+		case lower == "nullable":
+			goType = "*" + goType
+		case strings.HasPrefix(lower, "default="):
+			settings = append(settings, "default:"+modifier[len("default="):])
+		default:
+			return modelField{}, fmt.Errorf("unknown modifier %q for field %s (use unique, index, required, nullable or default=<value>)", modifier, name)
+		}
+	}
+	return modelField{Name: name, Type: goType, Tag: jsonTag(name, strings.Join(settings, ";"))}, nil
+}
+
+// jsonTag returns the struct tag of a field: its gorm settings and JSON name
+func jsonTag(name, gorm string) string {
+	tag := fmt.Sprintf(`json:"%s"`, snakeCase(name))
+	if gorm != "" {
+		tag = fmt.Sprintf(`gorm:"%s" %s`, gorm, tag)
+	}
+	return "`" + tag + "`"
+}
+
+func typeNames() []string {
+	names := make([]string, 0, len(fieldTypes))
+	for name := range fieldTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Model creates apps/<app>/<name>.go with a model embedding BaseModel and the
+// fields described by specs (see ParseField). make:migration picks it up.
+func Model(appName, name string, specs []string) error {
+	t, err := appTarget(appName)
+	if err != nil {
+		return err
+	}
+	base, err := baseName(name, "Model")
+	if err != nil {
+		return err
+	}
+
+	var fields []modelField
+	usesTime := false
+	for _, spec := range specs {
+		parsed, err := ParseField(spec)
+		if err != nil {
+			return err
+		}
+		for _, field := range parsed {
+			usesTime = usesTime || strings.Contains(field.Type, "time.Time")
+		}
+		fields = append(fields, parsed...)
+	}
+
+	data := map[string]any{
+		"Package":  t.Package,
+		"Name":     base,
+		"Fields":   fields,
+		"UsesTime": usesTime,
+	}
+	path, err := t.write(snakeCase(base)+".go", modelTemplate, data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created model: %s\n", path)
+	fmt.Printf("Create its table with: go run . make:migration --app=%s\n", t.App)
+	return nil
+}
+
+var modelTemplate = template.Must(template.New("model").Parse(`package {{.Package}}
+
+import (
+{{- if .UsesTime}}
+	"time"
+{{end}}
+	"github.com/ishubhamsingh2e/bourbon/bourbon/models"
+)
+
+type {{.Name}} struct {
+	models.BaseModel
+{{- range .Fields}}
+	{{.Name}} {{.Type}} {{.Tag}}
+{{- end}}
+}
+`))
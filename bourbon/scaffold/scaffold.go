@@ -0,0 +1,151 @@
+// Package scaffold generates the source files of apps and of their controllers,
+// models, middleware, commands and jobs, wired to the framework. Both the bourbon
+// CLI and an application's own create:app and make:* commands use it. Files go
+// into apps/<app>, in the app's package.
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/gomod"
+	"gorm.io/gorm/schema"
+)
+
+// ModulePath returns the module path declared in the project's go.mod, which
+// generated import paths start with
+func ModulePath() (string, error) {
+	mod, err := gomod.Read(".")
+	if err != nil {
+		return "", err
+	}
+	return mod.Module, nil
+}
+
+// target is the app a generator writes into
+type target struct {
+	App     string // directory name under apps/
+	Package string // Go package name of the app
+	Module  string // project module path
+	Dir     string
+
+	mod *gomod.File
+}
+
+// appTarget checks that the app exists and finds its package name
+func appTarget(appName string) (*target, error) {
+	mod, err := gomod.Read(".")
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join("apps", appName)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("app %s not found in apps/ - create it with create:app first", appName)
+	}
+	return &target{App: appName, Package: packageName(dir, appName), Module: mod.Module, Dir: dir, mod: mod}, nil
+}
+
+// packageName returns the package the app's Go files declare, or the app name
+// when it has none yet
+func packageName(dir, appName string) string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		node, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.PackageClauseOnly)
+		if err == nil {
+			return node.Name.Name
+		}
+	}
+	return strings.NewReplacer("-", "_", ".", "_").Replace(appName)
+}
+
+// ImportPath returns the import path of the app's package
+func (t *target) ImportPath() string {
+	return t.Module + "/apps/" + t.App
+}
+
+// imported reports whether main.go imports the app's package, which init
+// functions in the generated files need to run
+func (t *target) imported() bool {
+	data, err := os.ReadFile("main.go")
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(data), `"`+t.ImportPath()+`"`)
+}
+
+// printImportHint tells how to import the app when main.go doesn't yet
+func (t *target) printImportHint() {
+	if !t.imported() {
+		fmt.Printf("Import the app in main.go so it is compiled in:\n\n  \"%s\"\n\n", t.ImportPath())
+	}
+}
+
+// write renders tmpl with data into a new file of the app, formatted with gofmt.
+// Framework imports follow the path go.mod requires the framework under, and a
+// hint is printed when go.mod lacks a module the file imports.
+func (t *target) write(fileName string, tmpl *template.Template, data any) (string, error) {
+	path := filepath.Join(t.Dir, fileName)
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("%s already exists", path)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", fileName, err)
+	}
+	source, err := format.Source(t.mod.Rewrite(buf.Bytes()))
+	if err != nil {
+		return "", fmt.Errorf("failed to format %s: %w", fileName, err)
+	}
+	if err := os.WriteFile(path, source, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	t.mod.Verify(path)
+	return path, nil
+}
+
+// namer derives snake_case names the same way GORM does
+var namer = schema.NamingStrategy{}
+
+// pascalCase converts post, blog_post, blog-post or BlogPost to BlogPost
+func pascalCase(s string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == '_' || r == '-' || r == ':' || r == '.' || unicode.IsSpace(r)
+	}) {
+		runes := []rune(part)
+		b.WriteRune(unicode.ToUpper(runes[0]))
+		b.WriteString(string(runes[1:]))
+	}
+	return b.String()
+}
+
+// snakeCase converts a name to snake_case
+func snakeCase(s string) string {
+	return namer.ColumnName("", pascalCase(s))
+}
+
+// baseName returns the PascalCase name of a generated type, without suffix
+// (PostController, post_controller and post all give Post)
+func baseName(name, suffix string) (string, error) {
+	base := pascalCase(name)
+	if trimmed := strings.TrimSuffix(base, suffix); trimmed != "" {
+		base = trimmed
+	}
+	if !token.IsIdentifier(base) {
+		return "", fmt.Errorf("invalid name: %q", name)
+	}
+	return base, nil
+}
@@ -0,0 +1,375 @@
+package scaffold
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// testRoute is a route a generated test requests
+type testRoute struct {
+	Func    string // router method: Get, Post, Put, Patch or Delete
+	Pattern string // as registered, e.g. /posts/{id}
+	Handler string // controller method
+}
+
+// Method returns the name of the net/http method constant
+func (r testRoute) Method() string {
+	return "Method" + r.Func
+}
+
+// Path returns the pattern with 1 for every parameter
+func (r testRoute) Path() string {
+	return path.Clean("/" + pathParam.ReplaceAllString(r.Pattern, "1"))
+}
+
+// Status returns the name of the net/http status constant the generated
+// controller answers with
+func (r testRoute) Status() string {
+	switch r.Handler {
+	case "Store":
+		return "StatusCreated"
+	case "Destroy":
+		return "StatusNoContent"
+	}
+	return "StatusOK"
+}
+
+// Body returns the JSON body to send, as Go source
+func (r testRoute) Body() string {
+	switch r.Func {
+	case "Post", "Put", "Patch":
+		return `map[string]any{"name": "example"}`
+	}
+	return "nil"
+}
+
+// pathParam matches a path parameter of a route pattern
+var pathParam = regexp.MustCompile(`\{[^}]*\}`)
+
+// restRoutes are the routes make:controller suggests for each handler
+var restRoutes = []testRoute{
+	{Func: "Get", Pattern: "", Handler: "Index"},
+	{Func: "Get", Pattern: "/{id}", Handler: "Show"},
+	{Func: "Post", Pattern: "", Handler: "Store"},
+	{Func: "Put", Pattern: "/{id}", Handler: "Update"},
+	{Func: "Delete", Pattern: "/{id}", Handler: "Destroy"},
+}
+
+// Test creates apps/<app>/<name>_controller_test.go with a table-driven test
+// requesting each route of the controller through bourbontest. The routes come
+// from where the app registers the controller, or are the ones make:controller
+// suggests when it doesn't yet.
+func Test(appName, name string) error {
+	t, err := appTarget(appName)
+	if err != nil {
+		return err
+	}
+	base, err := baseName(name, "Controller")
+	if err != nil {
+		return err
+	}
+	controller := base + "Controller"
+
+	files, err := parsePackage(t.Dir)
+	if err != nil {
+		return err
+	}
+	handlers := controllerHandlers(files, controller)
+	if handlers == nil {
+		return fmt.Errorf("no %s in %s - create it with make:controller %s first", controller, t.Dir, base)
+	}
+
+	routes := registeredRoutes(files, controller, handlers)
+	registered := len(routes) > 0
+	var unrouted []string
+	if !registered {
+		prefix := "/" + namer.TableName(base)
+		for _, route := range restRoutes {
+			if handlers[route.Handler] {
+				route.Pattern = prefix + route.Pattern
+				routes = append(routes, route)
+			}
+		}
+	}
+	for _, handler := range sortedHandlers(files, controller) {
+		routed := false
+		for _, route := range routes {
+			routed = routed || route.Handler == handler
+		}
+		if !routed {
+			unrouted = append(unrouted, handler)
+		}
+	}
+	if len(routes) == 0 {
+		return fmt.Errorf("%s has no routes to test - register them in %s/routes.go", controller, t.Dir)
+	}
+
+	model := ""
+	if hasType(files, base) {
+		model = base
+	}
+	// Templates and static files as the server has them, for HTML handlers
+	settings := ""
+	if _, err := os.Stat("settings.toml"); err == nil {
+		if rel, err := filepath.Rel(t.Dir, "settings.toml"); err == nil {
+			settings = filepath.ToSlash(rel)
+		}
+	}
+	data := map[string]any{
+		"Package":    t.Package,
+		"Settings":   settings,
+		"Name":       controller,
+		"Model":      model,
+		"Registered": registered,
+		"Routes":     routes,
+		"Unrouted":   strings.Join(unrouted, ", "),
+	}
+	path, err := t.write(snakeCase(base)+"_controller_test.go", testTemplate, data)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created test: %s\n", path)
+	if !registered {
+		fmt.Println("It registers the routes make:controller suggests, as routes.go doesn't register the controller yet")
+	}
+	fmt.Printf("Run it with: go test ./%s\n", filepath.ToSlash(t.Dir))
+	return nil
+}
+
+// parsePackage parses the app's Go files, without its tests
+func parsePackage(dir string) ([]*ast.File, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// controllerHandlers returns the handler methods of the controller, those taking
+// a context and returning an error, or nil when the package has no such type
+func controllerHandlers(files []*ast.File, controller string) map[string]bool {
+	if !hasType(files, controller) {
+		return nil
+	}
+	handlers := make(map[string]bool)
+	for _, name := range sortedHandlers(files, controller) {
+		handlers[name] = true
+	}
+	return handlers
+}
+
+// sortedHandlers returns the controller's handler methods in source order
+func sortedHandlers(files []*ast.File, controller string) []string {
+	var names []string
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || len(fn.Recv.List) != 1 || !fn.Name.IsExported() {
+				continue
+			}
+			recv := fn.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			if ident, ok := recv.(*ast.Ident); !ok || ident.Name != controller {
+				continue
+			}
+			params, results := fn.Type.Params.List, fn.Type.Results
+			if len(params) != 1 || len(params[0].Names) > 1 || results == nil || len(results.List) != 1 {
+				continue
+			}
+			context, ok := params[0].Type.(*ast.StarExpr)
+			if !ok {
+				continue
+			}
+			if sel, ok := context.X.(*ast.SelectorExpr); !ok || sel.Sel.Name != "Context" {
+				continue
+			}
+			if ident, ok := results.List[0].Type.(*ast.Ident); !ok || ident.Name != "error" {
+				continue
+			}
+			names = append(names, fn.Name.Name)
+		}
+	}
+	return names
+}
+
+// hasType reports whether the package declares a type of that name
+func hasType(files []*ast.File, name string) bool {
+	for _, file := range files {
+		if obj := file.Scope.Lookup(name); obj != nil && obj.Kind == ast.Typ {
+			return true
+		}
+	}
+	return false
+}
+
+// registeredRoutes finds the routes registered with the controller's handlers,
+// e.g. group.Get("/posts", posts.Index) after posts := NewPostController(app)
+func registeredRoutes(files []*ast.File, controller string, handlers map[string]bool) []testRoute {
+	var routes []testRoute
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			vars := make(map[string]bool)
+			groups := make(map[string]string) // group variable to its literal prefix
+			ast.Inspect(fn.Body, func(node ast.Node) bool {
+				switch node := node.(type) {
+				case *ast.AssignStmt:
+					for i, rhs := range node.Rhs {
+						call, ok := rhs.(*ast.CallExpr)
+						if !ok || i >= len(node.Lhs) {
+							continue
+						}
+						lhs, ok := node.Lhs[i].(*ast.Ident)
+						if !ok {
+							continue
+						}
+						switch fun := call.Fun.(type) {
+						case *ast.Ident:
+							if fun.Name == "New"+controller {
+								vars[lhs.Name] = true
+							}
+						case *ast.SelectorExpr:
+							// The prefix RegisterRoutes is given is "/" in the test
+							if fun.Sel.Name == "Group" && len(call.Args) == 1 {
+								groups[lhs.Name], _ = stringLit(call.Args[0])
+							}
+						}
+					}
+				case *ast.CallExpr:
+					if route, ok := routeCall(node, vars, handlers); ok {
+						if recv, ok := node.Fun.(*ast.SelectorExpr).X.(*ast.Ident); ok {
+							route.Pattern = groups[recv.Name] + "/" + route.Pattern
+						}
+						routes = append(routes, route)
+					}
+				}
+				return true
+			})
+		}
+	}
+	return routes
+}
+
+// routeCall reads a route registration such as group.Get("/posts", posts.Index)
+// whose handler belongs to one of the controller variables
+func routeCall(call *ast.CallExpr, vars, handlers map[string]bool) (testRoute, bool) {
+	fun, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) < 2 {
+		return testRoute{}, false
+	}
+	switch fun.Sel.Name {
+	case "Get", "Post", "Put", "Patch", "Delete":
+	default:
+		return testRoute{}, false
+	}
+	pattern, ok := stringLit(call.Args[0])
+	if !ok {
+		return testRoute{}, false
+	}
+	handler, ok := call.Args[1].(*ast.SelectorExpr)
+	if !ok {
+		return testRoute{}, false
+	}
+	if recv, ok := handler.X.(*ast.Ident); !ok || !vars[recv.Name] || !handlers[handler.Sel.Name] {
+		return testRoute{}, false
+	}
+	return testRoute{Func: fun.Sel.Name, Pattern: pattern, Handler: handler.Sel.Name}, true
+}
+
+// stringLit returns the value of a string literal
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
+
+var testTemplate = template.Must(template.New("test").Parse(`package {{.Package}}
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	bourbontest "github.com/ishubhamsingh2e/bourbon/bourbon/testing"
+)
+
+func Test{{.Name}}(t *testing.T) {
+	config := bourbontest.Config{
+{{- if .Settings}}
+		Settings: "{{.Settings}}",
+{{- end}}
+{{- if .Model}}
+		Models: []any{&{{.Model}}{}},
+{{- end}}
+		Setup: func(app *core.Application) error {
+{{- if .Registered}}
+			RegisterRoutes(app, "/")
+{{- else}}
+			// The routes make:controller suggests; call RegisterRoutes(app, "/")
+			// instead once routes.go registers the controller
+			controller := New{{.Name}}(app)
+{{- range .Routes}}
+			app.Router.{{.Func}}("{{.Pattern}}", controller.{{.Handler}})
+{{- end}}
+{{- end}}
+			return nil
+		},
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		status int
+	}{
+{{- range .Routes}}
+		{"{{.Handler}}", http.{{.Method}}, "{{.Path}}", {{.Body}}, http.{{.Status}}},
+{{- end}}
+{{- if .Unrouted}}
+		// Not routed yet: {{.Unrouted}}
+{{- end}}
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Each case has its own app, in a transaction rolled back at its end
+			testapp := bourbontest.New(t, config)
+			request := testapp.NewRequest(tt.method, tt.path)
+			if tt.body != nil {
+				request.WithJSON(tt.body)
+			}
+			request.Do().AssertStatus(tt.status)
+		})
+	}
+}
+`))
@@ -0,0 +1,142 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ElasticsearchEngine indexes documents in Elasticsearch (or OpenSearch), one
+// Elasticsearch index per search index, with the document fields as _source
+type ElasticsearchEngine struct {
+	client *httpClient
+}
+
+// NewElasticsearchEngine connects to Elasticsearch, e.g. http://localhost:9200.
+// apiKey is sent as an ApiKey authorization; put basic-auth credentials in the URL instead.
+func NewElasticsearchEngine(baseURL, apiKey string) *ElasticsearchEngine {
+	header := http.Header{}
+	if apiKey != "" {
+		header.Set("Authorization", "ApiKey "+apiKey)
+	}
+	return &ElasticsearchEngine{client: newHTTPClient(baseURL, header)}
+}
+
+// indexName lowercases the index, as Elasticsearch requires
+func (e *ElasticsearchEngine) indexName(index string) string {
+	return strings.ToLower(index)
+}
+
+func (e *ElasticsearchEngine) Index(ctx context.Context, index string, docs ...Document) error {
+	if err := validateIndex(index); err != nil {
+		return err
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, doc := range docs {
+		if err := enc.Encode(map[string]interface{}{"index": map[string]string{"_index": e.indexName(index), "_id": doc.ID}}); err != nil {
+			return err
+		}
+		if err := enc.Encode(doc.Fields); err != nil {
+			return err
+		}
+	}
+	return e.bulk(ctx, body.Bytes())
+}
+
+func (e *ElasticsearchEngine) Delete(ctx context.Context, index string, ids ...string) error {
+	if err := validateIndex(index); err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, id := range ids {
+		if err := enc.Encode(map[string]interface{}{"delete": map[string]string{"_index": e.indexName(index), "_id": id}}); err != nil {
+			return err
+		}
+	}
+	return e.bulk(ctx, body.Bytes())
+}
+
+// bulk sends a _bulk request and reports the first failed item
+func (e *ElasticsearchEngine) bulk(ctx context.Context, body []byte) error {
+	var resp struct {
+		Errors bool                                `json:"errors"`
+		Items  []map[string]map[string]interface{} `json:"items"`
+	}
+	if _, err := e.client.do(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", body, &resp); err != nil {
+		return err
+	}
+	if !resp.Errors {
+		return nil
+	}
+	for _, item := range resp.Items {
+		for action, result := range item {
+			if result["error"] != nil && action != "delete" {
+				return fmt.Errorf("search: bulk %s of %v failed: %v", action, result["_id"], result["error"])
+			}
+		}
+	}
+	return nil
+}
+
+func (e *ElasticsearchEngine) Search(ctx context.Context, index string, q Query) (*Result, error) {
+	if err := validateIndex(index); err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				ID     string                 `json:"_id"`
+				Score  float64                `json:"_score"`
+				Source map[string]interface{} `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	status, err := e.client.do(ctx, http.MethodPost, "/"+e.indexName(index)+"/_search", "application/json", map[string]interface{}{
+		"from": q.Offset,
+		"size": defaultLimit(q),
+		"query": map[string]interface{}{
+			"simple_query_string": map[string]interface{}{
+				"query":            q.Text,
+				"default_operator": "and",
+			},
+		},
+	}, &resp)
+	if status == http.StatusNotFound {
+		return &Result{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Total: resp.Hits.Total.Value}
+	for _, hit := range resp.Hits.Hits {
+		result.Hits = append(result.Hits, Hit{ID: hit.ID, Score: hit.Score, Fields: hit.Source})
+	}
+	return result, nil
+}
+
+func (e *ElasticsearchEngine) Reset(ctx context.Context, index string) error {
+	if err := validateIndex(index); err != nil {
+		return err
+	}
+	status, err := e.client.do(ctx, http.MethodPost, "/"+e.indexName(index)+"/_delete_by_query?refresh=true", "application/json",
+		map[string]interface{}{"query": map[string]interface{}{"match_all": map[string]interface{}{}}}, nil)
+	if status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// httpClient sends JSON requests to an external search server
+type httpClient struct {
+	baseURL string
+	header  http.Header
+	client  *http.Client
+}
+
+func newHTTPClient(baseURL string, header http.Header) *httpClient {
+	return &httpClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		header:  header,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// do sends body (JSON-encoded unless it is already []byte) and decodes a 2xx response
+// into out. It returns the status code; non-2xx responses are errors.
+func (c *httpClient) do(ctx context.Context, method, path, contentType string, body, out interface{}) (int, error) {
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		reader = bytes.NewReader(b)
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	if reader != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("search: %s %s returned %d: %s", method, path, resp.StatusCode, bytes.TrimSpace(data))
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("search: failed to decode response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,100 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// MeilisearchEngine indexes documents in a Meilisearch server. Documents are stored
+// with their fields and an "id" primary key. Meilisearch applies writes asynchronously,
+// so a document may take a moment to become searchable.
+type MeilisearchEngine struct {
+	client *httpClient
+}
+
+// NewMeilisearchEngine connects to a Meilisearch server, e.g. http://localhost:7700
+func NewMeilisearchEngine(baseURL, apiKey string) *MeilisearchEngine {
+	header := http.Header{}
+	if apiKey != "" {
+		header.Set("Authorization", "Bearer "+apiKey)
+	}
+	return &MeilisearchEngine{client: newHTTPClient(baseURL, header)}
+}
+
+func (e *MeilisearchEngine) Index(ctx context.Context, index string, docs ...Document) error {
+	if err := validateIndex(index); err != nil {
+		return err
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+	body := make([]map[string]interface{}, len(docs))
+	for i, doc := range docs {
+		fields := make(map[string]interface{}, len(doc.Fields)+1)
+		for key, value := range doc.Fields {
+			fields[key] = value
+		}
+		fields["id"] = doc.ID
+		body[i] = fields
+	}
+	_, err := e.client.do(ctx, http.MethodPost, "/indexes/"+index+"/documents?primaryKey=id", "application/json", body, nil)
+	return err
+}
+
+func (e *MeilisearchEngine) Delete(ctx context.Context, index string, ids ...string) error {
+	if err := validateIndex(index); err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := e.client.do(ctx, http.MethodPost, "/indexes/"+index+"/documents/delete-batch", "application/json", ids, nil)
+	return err
+}
+
+func (e *MeilisearchEngine) Search(ctx context.Context, index string, q Query) (*Result, error) {
+	if err := validateIndex(index); err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Hits               []map[string]interface{} `json:"hits"`
+		EstimatedTotalHits int64                    `json:"estimatedTotalHits"`
+	}
+	status, err := e.client.do(ctx, http.MethodPost, "/indexes/"+index+"/search", "application/json", map[string]interface{}{
+		"q":                q.Text,
+		"limit":            defaultLimit(q),
+		"offset":           q.Offset,
+		"showRankingScore": true,
+	}, &resp)
+	if status == http.StatusNotFound {
+		// Nothing indexed yet
+		return &Result{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Total: resp.EstimatedTotalHits}
+	for _, fields := range resp.Hits {
+		hit := Hit{ID: fmt.Sprint(fields["id"]), Fields: fields}
+		if score, ok := fields["_rankingScore"].(float64); ok {
+			hit.Score = score
+		}
+		delete(fields, "id")
+		delete(fields, "_rankingScore")
+		result.Hits = append(result.Hits, hit)
+	}
+	return result, nil
+}
+
+func (e *MeilisearchEngine) Reset(ctx context.Context, index string) error {
+	if err := validateIndex(index); err != nil {
+		return err
+	}
+	status, err := e.client.do(ctx, http.MethodDelete, "/indexes/"+index+"/documents", "", nil, nil)
+	if status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMeilisearchEngine(t *testing.T) {
+	var requests []string
+	var indexed []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		if r.Header.Get("Authorization") != "Bearer key" {
+			http.Error(w, "missing key", http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/indexes/posts/documents":
+			if r.Method == http.MethodPost {
+				json.Unmarshal(body, &indexed)
+			}
+			w.WriteHeader(http.StatusAccepted)
+		case "/indexes/posts/search":
+			var q map[string]interface{}
+			json.Unmarshal(body, &q)
+			if q["q"] != "go" || q["limit"] != float64(20) || q["showRankingScore"] != true {
+				http.Error(w, "unexpected query", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"hits":[{"id":"2","title":"Go web","_rankingScore":0.9},{"id":1,"title":"Go","_rankingScore":0.5}],"estimatedTotalHits":2}`))
+		case "/indexes/empty/search":
+			http.Error(w, `{"code":"index_not_found"}`, http.StatusNotFound)
+		case "/indexes/posts/documents/delete-batch":
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			http.Error(w, "unexpected request", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	e := NewMeilisearchEngine(server.URL, "key")
+	if err := e.Index(ctx, "posts", Document{ID: "1", Fields: map[string]interface{}{"title": "Go"}}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []map[string]interface{}{{"id": "1", "title": "Go"}}; !reflect.DeepEqual(indexed, want) {
+		t.Errorf("indexed %v, want %v", indexed, want)
+	}
+
+	result, err := e.Search(ctx, "posts", Query{Text: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Result{Total: 2, Hits: []Hit{
+		{ID: "2", Score: 0.9, Fields: map[string]interface{}{"title": "Go web"}},
+		{ID: "1", Score: 0.5, Fields: map[string]interface{}{"title": "Go"}},
+	}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("Search = %+v, want %+v", result, want)
+	}
+
+	if result, err := e.Search(ctx, "empty", Query{Text: "go"}); err != nil || len(result.Hits) != 0 {
+		t.Errorf("Search of a missing index = %+v, %v", result, err)
+	}
+	if err := e.Delete(ctx, "posts", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Index(ctx, "bad name", Document{ID: "1"}); err == nil {
+		t.Error("Index with an invalid index name succeeded")
+	}
+
+	wantRequests := []string{
+		"POST /indexes/posts/documents?primaryKey=id",
+		"POST /indexes/posts/search",
+		"POST /indexes/empty/search",
+		"POST /indexes/posts/documents/delete-batch",
+	}
+	if !reflect.DeepEqual(requests, wantRequests) {
+		t.Errorf("requests\n got %v\nwant %v", requests, wantRequests)
+	}
+
+	if _, err := NewMeilisearchEngine(server.URL, "").Search(ctx, "posts", Query{Text: "go"}); err == nil {
+		t.Error("an unauthorized search succeeded")
+	}
+}
@@ -0,0 +1,134 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// PostgresEngine indexes documents in a search_documents table with a tsvector
+// column and a GIN index. It also works on CockroachDB.
+type PostgresEngine struct {
+	db       *gorm.DB
+	language string
+	mu       sync.Mutex
+	ready    bool
+}
+
+// NewPostgresEngine creates an engine storing documents in db; language is the
+// text search configuration (default "english")
+func NewPostgresEngine(db *gorm.DB, language string) *PostgresEngine {
+	if language == "" {
+		language = "english"
+	}
+	return &PostgresEngine{db: db, language: language}
+}
+
+// ensure creates the documents table on first use
+func (e *PostgresEngine) ensure(ctx context.Context, index string) error {
+	if err := validateIndex(index); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.ready {
+		return nil
+	}
+
+	db := e.db.WithContext(ctx)
+	err := db.Exec(`CREATE TABLE IF NOT EXISTS search_documents (
+		index_name VARCHAR(100) NOT NULL,
+		doc_id VARCHAR(191) NOT NULL,
+		fields TEXT,
+		document TSVECTOR NOT NULL,
+		PRIMARY KEY (index_name, doc_id)
+	)`).Error
+	if err != nil {
+		return err
+	}
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_search_documents_document ON search_documents USING GIN (document)").Error; err != nil {
+		return err
+	}
+	e.ready = true
+	return nil
+}
+
+func (e *PostgresEngine) Index(ctx context.Context, index string, docs ...Document) error {
+	if err := e.ensure(ctx, index); err != nil {
+		return err
+	}
+	return e.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, doc := range docs {
+			fields, err := json.Marshal(doc.Fields)
+			if err != nil {
+				return err
+			}
+			err = tx.Exec(`INSERT INTO search_documents (index_name, doc_id, fields, document)
+				VALUES (?, ?, ?, to_tsvector(?::regconfig, ?))
+				ON CONFLICT (index_name, doc_id) DO UPDATE SET fields = EXCLUDED.fields, document = EXCLUDED.document`,
+				index, doc.ID, string(fields), e.language, documentContent(doc)).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (e *PostgresEngine) Delete(ctx context.Context, index string, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := e.ensure(ctx, index); err != nil {
+		return err
+	}
+	return e.db.WithContext(ctx).Exec("DELETE FROM search_documents WHERE index_name = ? AND doc_id IN ?", index, ids).Error
+}
+
+func (e *PostgresEngine) Search(ctx context.Context, index string, q Query) (*Result, error) {
+	if err := e.ensure(ctx, index); err != nil {
+		return nil, err
+	}
+	if q.Text == "" {
+		return &Result{}, nil
+	}
+
+	db := e.db.WithContext(ctx)
+	result := &Result{}
+	err := db.Raw(`SELECT count(*) FROM search_documents
+		WHERE index_name = ? AND document @@ plainto_tsquery(?::regconfig, ?)`,
+		index, e.language, q.Text).Scan(&result.Total).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		DocID  string
+		Fields string
+		Score  float64
+	}
+	err = db.Raw(`SELECT doc_id, fields, ts_rank(document, plainto_tsquery(?::regconfig, ?)) AS score
+		FROM search_documents
+		WHERE index_name = ? AND document @@ plainto_tsquery(?::regconfig, ?)
+		ORDER BY score DESC, doc_id
+		LIMIT ? OFFSET ?`,
+		e.language, q.Text, index, e.language, q.Text, defaultLimit(q), q.Offset).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		hit := Hit{ID: row.DocID, Score: row.Score}
+		_ = json.Unmarshal([]byte(row.Fields), &hit.Fields)
+		result.Hits = append(result.Hits, hit)
+	}
+	return result, nil
+}
+
+func (e *PostgresEngine) Reset(ctx context.Context, index string) error {
+	if err := e.ensure(ctx, index); err != nil {
+		return err
+	}
+	return e.db.WithContext(ctx).Exec("DELETE FROM search_documents WHERE index_name = ?", index).Error
+}
@@ -0,0 +1,259 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+	"gorm.io/gorm"
+)
+
+// Document is one searchable record of an index
+type Document struct {
+	ID     string
+	Fields map[string]interface{}
+}
+
+// Query is a full-text search request
+type Query struct {
+	Text   string
+	Limit  int // default 20
+	Offset int
+}
+
+// Hit is one matching document; Fields are the indexed fields
+type Hit struct {
+	ID     string                 `json:"id"`
+	Score  float64                `json:"score"`
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
+
+// Result is one page of hits; Total may be an estimate on external engines
+type Result struct {
+	Hits  []Hit `json:"hits"`
+	Total int64 `json:"total"`
+}
+
+// IDs returns the IDs of the hits in rank order
+func (r *Result) IDs() []string {
+	ids := make([]string, len(r.Hits))
+	for i, hit := range r.Hits {
+		ids[i] = hit.ID
+	}
+	return ids
+}
+
+// Engine is a full-text search backend
+type Engine interface {
+	// Index adds or replaces documents
+	Index(ctx context.Context, index string, docs ...Document) error
+	// Delete removes documents by ID
+	Delete(ctx context.Context, index string, ids ...string) error
+	// Search returns the documents matching the query, best first
+	Search(ctx context.Context, index string, q Query) (*Result, error)
+	// Reset removes every document of an index
+	Reset(ctx context.Context, index string) error
+}
+
+// Indexable is a model kept in a search index
+//
+//	func (p *Post) SearchIndex() string { return "posts" }
+//	func (p *Post) SearchDocument() search.Document {
+//	    return search.Document{
+//	        ID:     strconv.Itoa(int(p.ID)),
+//	        Fields: map[string]interface{}{"title": p.Title, "body": p.Body},
+//	    }
+//	}
+type Indexable interface {
+	SearchIndex() string
+	SearchDocument() Document
+}
+
+// ErrUnsupportedDatabase is returned by NewDatabaseEngine for databases without a full-text backend
+var ErrUnsupportedDatabase = errors.New("search: no full-text backend for this database")
+
+var indexNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+func validateIndex(index string) error {
+	if !indexNamePattern.MatchString(index) {
+		return fmt.Errorf("search: invalid index name %q (use letters, digits and underscores)", index)
+	}
+	return nil
+}
+
+func defaultLimit(q Query) int {
+	if q.Limit <= 0 {
+		return 20
+	}
+	return q.Limit
+}
+
+// Config selects the search engine
+type Config struct {
+	Driver   string // database (default), meilisearch or elasticsearch
+	URL      string // server URL of external engines
+	APIKey   string
+	Language string // PostgreSQL text search configuration (default english)
+}
+
+// Open creates the configured engine; the database engine uses db
+func Open(cfg Config, db *gorm.DB) (Engine, error) {
+	switch cfg.Driver {
+	case "", "database":
+		if db == nil {
+			return nil, fmt.Errorf("search: the database engine needs a database connection")
+		}
+		return NewDatabaseEngine(db, cfg.Language)
+	case "meilisearch":
+		return NewMeilisearchEngine(cfg.URL, cfg.APIKey), nil
+	case "elasticsearch":
+		return NewElasticsearchEngine(cfg.URL, cfg.APIKey), nil
+	default:
+		return nil, fmt.Errorf("unsupported search driver: %s (use database, meilisearch or elasticsearch)", cfg.Driver)
+	}
+}
+
+// NewDatabaseEngine returns the full-text engine built into the connected database:
+// SQLite FTS5, or PostgreSQL/CockroachDB tsvector
+func NewDatabaseEngine(db *gorm.DB, language string) (Engine, error) {
+	switch db.Dialector.Name() {
+	case "sqlite":
+		return NewSQLiteEngine(db), nil
+	case "postgres", "cockroach":
+		return NewPostgresEngine(db, language), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDatabase, db.Dialector.Name())
+	}
+}
+
+var (
+	engine      Engine
+	engineMutex sync.RWMutex
+
+	models      = make(map[string]reflect.Type)
+	modelsMutex sync.RWMutex
+)
+
+// SetEngine sets the engine registered models are indexed in; the application sets app.Search
+func SetEngine(e Engine) {
+	engineMutex.Lock()
+	defer engineMutex.Unlock()
+	engine = e
+}
+
+// Default returns the engine set with SetEngine, or nil
+func Default() Engine {
+	engineMutex.RLock()
+	defer engineMutex.RUnlock()
+	return engine
+}
+
+// Register keeps a model's index in sync: records are indexed after they are created
+// or updated and removed after they are deleted, once the transaction commits.
+// Registered models are rebuilt by the search:reindex command.
+//
+//	search.Register(&Post{})
+func Register(model Indexable) {
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	modelsMutex.Lock()
+	_, exists := models[model.SearchIndex()]
+	models[model.SearchIndex()] = t
+	modelsMutex.Unlock()
+	if exists {
+		return
+	}
+
+	index := func(ctx context.Context, record interface{}) error {
+		return IndexRecord(ctx, Default(), record)
+	}
+	orm.ObserveAsync(model, orm.AfterCreate, index)
+	orm.ObserveAsync(model, orm.AfterUpdate, index)
+	orm.ObserveAsync(model, orm.AfterDelete, func(ctx context.Context, record interface{}) error {
+		e, ok := record.(Indexable)
+		if !ok || Default() == nil {
+			return nil
+		}
+		doc := e.SearchDocument()
+		if doc.ID == "" {
+			return nil
+		}
+		return Default().Delete(ctx, e.SearchIndex(), doc.ID)
+	})
+}
+
+// Indexes lists the indexes of registered models
+func Indexes() []string {
+	modelsMutex.RLock()
+	defer modelsMutex.RUnlock()
+	names := make([]string, 0, len(models))
+	for name := range models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IndexRecord indexes one Indexable record; records without an ID are skipped
+func IndexRecord(ctx context.Context, e Engine, record interface{}) error {
+	m, ok := record.(Indexable)
+	if !ok || e == nil {
+		return nil
+	}
+	doc := m.SearchDocument()
+	if doc.ID == "" {
+		return nil
+	}
+	return e.Index(ctx, m.SearchIndex(), doc)
+}
+
+// Reindex clears an index and indexes every row of the model registered for it,
+// in batches of batchSize. It returns the number of documents indexed.
+func Reindex(ctx context.Context, db *gorm.DB, e Engine, index string, batchSize int) (int, error) {
+	modelsMutex.RLock()
+	t, ok := models[index]
+	modelsMutex.RUnlock()
+	if !ok {
+		return 0, fmt.Errorf("search: no model registered for index %s", index)
+	}
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	if err := e.Reset(ctx, index); err != nil {
+		return 0, err
+	}
+
+	total := 0
+	rows := reflect.New(reflect.SliceOf(t))
+	result := db.WithContext(ctx).Model(reflect.New(t).Interface()).FindInBatches(rows.Interface(), batchSize, func(tx *gorm.DB, batch int) error {
+		slice := rows.Elem()
+		docs := make([]Document, 0, slice.Len())
+		for i := 0; i < slice.Len(); i++ {
+			m, ok := slice.Index(i).Addr().Interface().(Indexable)
+			if !ok {
+				return fmt.Errorf("search: %s does not implement Indexable", t)
+			}
+			if doc := m.SearchDocument(); doc.ID != "" {
+				docs = append(docs, doc)
+			}
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+		if err := e.Index(ctx, index, docs...); err != nil {
+			return err
+		}
+		total += len(docs)
+		return nil
+	})
+	return total, result.Error
+}
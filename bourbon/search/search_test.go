@@ -0,0 +1,191 @@
+package search
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type note struct {
+	ID    uint
+	Title string
+	Draft bool
+}
+
+func (n *note) SearchIndex() string { return "notes" }
+
+func (n *note) SearchDocument() Document {
+	if n.Draft {
+		// Drafts are not searchable
+		return Document{}
+	}
+	return Document{ID: strconv.Itoa(int(n.ID)), Fields: map[string]interface{}{"title": n.Title}}
+}
+
+// recordingEngine keeps documents in memory and records resets
+type recordingEngine struct {
+	docs   map[string]map[string]Document
+	resets []string
+}
+
+func newRecordingEngine() *recordingEngine {
+	return &recordingEngine{docs: make(map[string]map[string]Document)}
+}
+
+func (e *recordingEngine) Index(ctx context.Context, index string, docs ...Document) error {
+	if e.docs[index] == nil {
+		e.docs[index] = make(map[string]Document)
+	}
+	for _, doc := range docs {
+		e.docs[index][doc.ID] = doc
+	}
+	return nil
+}
+
+func (e *recordingEngine) Delete(ctx context.Context, index string, ids ...string) error {
+	for _, id := range ids {
+		delete(e.docs[index], id)
+	}
+	return nil
+}
+
+func (e *recordingEngine) Search(ctx context.Context, index string, q Query) (*Result, error) {
+	return &Result{}, nil
+}
+
+func (e *recordingEngine) Reset(ctx context.Context, index string) error {
+	e.resets = append(e.resets, index)
+	delete(e.docs, index)
+	return nil
+}
+
+func (e *recordingEngine) ids(index string) []string {
+	var ids []string
+	for id := range e.docs[index] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func openSearchDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "search.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func TestReindex(t *testing.T) {
+	db := openSearchDB(t)
+	if err := db.AutoMigrate(&note{}); err != nil {
+		t.Fatal(err)
+	}
+	notes := []note{{Title: "one"}, {Title: "two", Draft: true}, {Title: "three"}, {Title: "four"}, {Title: "five"}}
+	if err := db.Create(&notes).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	modelsMutex.Lock()
+	models["notes"] = reflect.TypeOf(note{})
+	modelsMutex.Unlock()
+	t.Cleanup(func() {
+		modelsMutex.Lock()
+		delete(models, "notes")
+		modelsMutex.Unlock()
+	})
+
+	e := newRecordingEngine()
+	e.Index(context.Background(), "notes", Document{ID: "stale"})
+	n, err := Reindex(context.Background(), db, e, "notes", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 {
+		t.Errorf("Reindex indexed %d documents, want 4", n)
+	}
+	if want := []string{"1", "3", "4", "5"}; !reflect.DeepEqual(e.ids("notes"), want) {
+		t.Errorf("index holds %v, want %v", e.ids("notes"), want)
+	}
+	if !reflect.DeepEqual(e.resets, []string{"notes"}) {
+		t.Errorf("resets = %v", e.resets)
+	}
+
+	if _, err := Reindex(context.Background(), db, e, "unknown", 0); err == nil {
+		t.Error("Reindex of an unregistered index succeeded")
+	}
+}
+
+func TestIndexRecord(t *testing.T) {
+	e := newRecordingEngine()
+	ctx := context.Background()
+	if err := IndexRecord(ctx, e, &note{ID: 1, Title: "one"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := IndexRecord(ctx, e, &note{ID: 2, Draft: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := IndexRecord(ctx, e, "not indexable"); err != nil {
+		t.Fatal(err)
+	}
+	if err := IndexRecord(ctx, nil, &note{ID: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"1"}; !reflect.DeepEqual(e.ids("notes"), want) {
+		t.Errorf("index holds %v, want %v", e.ids("notes"), want)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	db := openSearchDB(t)
+	tests := []struct {
+		cfg  Config
+		db   *gorm.DB
+		want interface{}
+	}{
+		{Config{}, db, &SQLiteEngine{}},
+		{Config{Driver: "database"}, db, &SQLiteEngine{}},
+		{Config{Driver: "meilisearch", URL: "http://localhost:7700"}, nil, &MeilisearchEngine{}},
+		{Config{Driver: "elasticsearch", URL: "http://localhost:9200"}, nil, &ElasticsearchEngine{}},
+		{Config{}, nil, nil},
+		{Config{Driver: "solr"}, db, nil},
+	}
+	for _, tt := range tests {
+		e, err := Open(tt.cfg, tt.db)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("Open(%+v) succeeded", tt.cfg)
+			}
+			continue
+		}
+		if err != nil || reflect.TypeOf(e) != reflect.TypeOf(tt.want) {
+			t.Errorf("Open(%+v) = %T, %v, want %T", tt.cfg, e, err, tt.want)
+		}
+	}
+}
+
+func TestValidateIndex(t *testing.T) {
+	for _, index := range []string{"posts", "blog_posts", "Posts2"} {
+		if err := validateIndex(index); err != nil {
+			t.Errorf("validateIndex(%q) = %v", index, err)
+		}
+	}
+	for _, index := range []string{"", "posts;drop", `a"b`, "a-b", "a/b"} {
+		if err := validateIndex(index); err == nil {
+			t.Errorf("validateIndex(%q) accepted an unsafe name", index)
+		}
+	}
+	if _, err := NewSQLiteEngine(nil).Search(context.Background(), "bad name", Query{Text: "x"}); err == nil {
+		t.Error("Search with an invalid index name succeeded")
+	}
+}
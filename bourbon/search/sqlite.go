@@ -0,0 +1,157 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// SQLiteEngine indexes documents in FTS5 virtual tables named search_<index>.
+// The mattn/go-sqlite3 driver only includes FTS5 when built with -tags sqlite_fts5.
+type SQLiteEngine struct {
+	db      *gorm.DB
+	mu      sync.Mutex
+	created map[string]bool
+}
+
+// NewSQLiteEngine creates an engine storing indexes in db
+func NewSQLiteEngine(db *gorm.DB) *SQLiteEngine {
+	return &SQLiteEngine{db: db, created: make(map[string]bool)}
+}
+
+func (e *SQLiteEngine) table(index string) string {
+	return `"search_` + index + `"`
+}
+
+// ensure creates the index table on first use
+func (e *SQLiteEngine) ensure(ctx context.Context, index string) error {
+	if err := validateIndex(index); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.created[index] {
+		return nil
+	}
+	err := e.db.WithContext(ctx).Exec(fmt.Sprintf(
+		"CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(doc_id UNINDEXED, content, fields UNINDEXED, tokenize = 'porter unicode61')",
+		e.table(index),
+	)).Error
+	if err != nil {
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			return fmt.Errorf("search: SQLite was built without FTS5; build with -tags sqlite_fts5")
+		}
+		return err
+	}
+	e.created[index] = true
+	return nil
+}
+
+func (e *SQLiteEngine) Index(ctx context.Context, index string, docs ...Document) error {
+	if err := e.ensure(ctx, index); err != nil {
+		return err
+	}
+	return e.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, doc := range docs {
+			fields, err := json.Marshal(doc.Fields)
+			if err != nil {
+				return err
+			}
+			if err := tx.Exec("DELETE FROM "+e.table(index)+" WHERE doc_id = ?", doc.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("INSERT INTO "+e.table(index)+" (doc_id, content, fields) VALUES (?, ?, ?)",
+				doc.ID, documentContent(doc), string(fields)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (e *SQLiteEngine) Delete(ctx context.Context, index string, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := e.ensure(ctx, index); err != nil {
+		return err
+	}
+	return e.db.WithContext(ctx).Exec("DELETE FROM "+e.table(index)+" WHERE doc_id IN ?", ids).Error
+}
+
+func (e *SQLiteEngine) Search(ctx context.Context, index string, q Query) (*Result, error) {
+	if err := e.ensure(ctx, index); err != nil {
+		return nil, err
+	}
+	match := fts5Query(q.Text)
+	if match == "" {
+		return &Result{}, nil
+	}
+
+	db := e.db.WithContext(ctx)
+	result := &Result{}
+	if err := db.Raw("SELECT count(*) FROM "+e.table(index)+" WHERE "+e.table(index)+" MATCH ?", match).
+		Scan(&result.Total).Error; err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		DocID  string
+		Fields string
+		Score  float64
+	}
+	err := db.Raw("SELECT doc_id, fields, -bm25("+e.table(index)+") AS score FROM "+e.table(index)+
+		" WHERE "+e.table(index)+" MATCH ? ORDER BY score DESC LIMIT ? OFFSET ?",
+		match, defaultLimit(q), q.Offset).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		hit := Hit{ID: row.DocID, Score: row.Score}
+		_ = json.Unmarshal([]byte(row.Fields), &hit.Fields)
+		result.Hits = append(result.Hits, hit)
+	}
+	return result, nil
+}
+
+func (e *SQLiteEngine) Reset(ctx context.Context, index string) error {
+	if err := e.ensure(ctx, index); err != nil {
+		return err
+	}
+	return e.db.WithContext(ctx).Exec("DELETE FROM " + e.table(index)).Error
+}
+
+// fts5Query turns user input into an FTS5 query matching all words, the last one as
+// a prefix, so operators and quotes in the input cannot break the query syntax
+func fts5Query(text string) string {
+	words := strings.Fields(text)
+	for i, word := range words {
+		words[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+	}
+	if len(words) > 0 {
+		words[len(words)-1] += "*"
+	}
+	return strings.Join(words, " ")
+}
+
+// documentContent joins the field values into the searchable text, in field order
+func documentContent(doc Document) string {
+	keys := make([]string, 0, len(doc.Fields))
+	for key := range doc.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if value := doc.Fields[key]; value != nil {
+			parts = append(parts, fmt.Sprint(value))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,104 @@
+package search
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFTS5Query(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"", ""},
+		{"   ", ""},
+		{"go", `"go"*`},
+		{"go web", `"go" "web"*`},
+		{`say "hi"`, `"say" """hi"""*`},
+		{"a OR b NOT c", `"a" "OR" "b" "NOT" "c"*`},
+		{"title:x*", `"title:x*"*`},
+	}
+	for _, tt := range tests {
+		if got := fts5Query(tt.text); got != tt.want {
+			t.Errorf("fts5Query(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestDocumentContent(t *testing.T) {
+	doc := Document{ID: "1", Fields: map[string]interface{}{"title": "Hello", "body": "World", "views": 3, "empty": nil}}
+	if got, want := documentContent(doc), "World\nHello\n3"; got != want {
+		t.Errorf("documentContent = %q, want %q", got, want)
+	}
+}
+
+// TestSQLiteEngine needs FTS5: go test -tags sqlite_fts5
+func TestSQLiteEngine(t *testing.T) {
+	ctx := context.Background()
+	e := NewSQLiteEngine(openSearchDB(t))
+	err := e.Index(ctx, "posts",
+		Document{ID: "1", Fields: map[string]interface{}{"title": "Getting started with Go", "body": "Install the toolchain"}},
+		Document{ID: "2", Fields: map[string]interface{}{"title": "Go web servers", "body": "Routing in Go, and Go middleware"}},
+		Document{ID: "3", Fields: map[string]interface{}{"title": "Rust notes", "body": "Ownership"}},
+	)
+	if err != nil && strings.Contains(err.Error(), "without FTS5") {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	search := func(text string) *Result {
+		t.Helper()
+		result, err := e.Search(ctx, "posts", Query{Text: text})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	result := search("go")
+	if result.Total != 2 || !reflect.DeepEqual(result.IDs(), []string{"2", "1"}) {
+		t.Errorf("go = %v (total %d), want [2 1] ranked by matches", result.IDs(), result.Total)
+	}
+	if result.Hits[0].Fields["title"] != "Go web servers" {
+		t.Errorf("hit fields = %v", result.Hits[0].Fields)
+	}
+	if ids := search("rout").IDs(); !reflect.DeepEqual(ids, []string{"2"}) {
+		t.Errorf("prefix search = %v", ids)
+	}
+	if ids := search("installing").IDs(); !reflect.DeepEqual(ids, []string{"1"}) {
+		t.Errorf("stemmed search = %v", ids)
+	}
+	if ids := search(`go" OR "rust`).IDs(); len(ids) != 0 {
+		t.Errorf("query syntax in the input matched %v", ids)
+	}
+	if result := search(""); result.Total != 0 || len(result.Hits) != 0 {
+		t.Errorf("empty query = %+v", result)
+	}
+	if page, _ := e.Search(ctx, "posts", Query{Text: "go", Limit: 1, Offset: 1}); page.Total != 2 || !reflect.DeepEqual(page.IDs(), []string{"1"}) {
+		t.Errorf("second page = %v (total %d)", page.IDs(), page.Total)
+	}
+
+	// Indexing a document again replaces it
+	if err := e.Index(ctx, "posts", Document{ID: "3", Fields: map[string]interface{}{"title": "Go and Rust"}}); err != nil {
+		t.Fatal(err)
+	}
+	if ids := search("ownership").IDs(); len(ids) != 0 {
+		t.Errorf("the replaced document still matches: %v", ids)
+	}
+	if err := e.Delete(ctx, "posts", "1", "3"); err != nil {
+		t.Fatal(err)
+	}
+	if ids := search("go").IDs(); !reflect.DeepEqual(ids, []string{"2"}) {
+		t.Errorf("after delete = %v", ids)
+	}
+	if err := e.Reset(ctx, "posts"); err != nil {
+		t.Fatal(err)
+	}
+	if result := search("go"); result.Total != 0 {
+		t.Errorf("after reset = %v", result.IDs())
+	}
+}
@@ -0,0 +1,56 @@
+package serializer
+
+import (
+	"reflect"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/openapi"
+)
+
+// OpenAPISchema describes the serializer's JSON object, so a serializer can be passed
+// to Route.Request and Route.Response. Read-only and write-only fields are marked.
+func (s *Serializer[T]) OpenAPISchema() *openapi.Schema {
+	schema := &openapi.Schema{Type: "object", Properties: make(map[string]*openapi.Schema)}
+	for _, f := range s.fields {
+		var prop *openapi.Schema
+		if f.nested != nil {
+			prop = nestedSchema(f.nested, f.typ)
+		} else {
+			prop = openapi.SchemaOf(f.typ)
+		}
+		prop.ReadOnly = f.readOnly
+		prop.WriteOnly = f.writeOnly
+		schema.Properties[f.key] = prop
+		if f.rules.Required() && !f.readOnly {
+			schema.Required = append(schema.Required, f.key)
+		}
+	}
+	for _, c := range s.computed {
+		schema.Properties[c.key] = &openapi.Schema{ReadOnly: true}
+	}
+	return schema
+}
+
+// Many describes a JSON array of the serializer's objects for Route.Response
+func (s *Serializer[T]) Many() openapi.SchemaProvider {
+	return many{s}
+}
+
+type many struct {
+	item openapi.SchemaProvider
+}
+
+func (m many) OpenAPISchema() *openapi.Schema {
+	return &openapi.Schema{Type: "array", Items: m.item.OpenAPISchema()}
+}
+
+func nestedSchema(nested openapi.SchemaProvider, t reflect.Type) *openapi.Schema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := nestedSchema(nested, t.Elem())
+		schema.Nullable = true
+		return schema
+	case reflect.Slice:
+		return &openapi.Schema{Type: "array", Items: nestedSchema(nested, t.Elem())}
+	}
+	return nested.OpenAPISchema()
+}
@@ -0,0 +1,476 @@
+// Package serializer converts models to API output and validated input back to
+// models, in the spirit of Django REST framework serializers:
+//
+//	var UserSerializer = serializer.New[User](
+//	    serializer.Field("ID").As("id").ReadOnly(),
+//	    serializer.Field("Email").As("email").Validate("required,email"),
+//	    serializer.Field("Password").As("password").WriteOnly().Validate("required,min=8"),
+//	    serializer.Field("Profile").As("profile").Nested(ProfileSerializer),
+//	)
+//
+//	c.JSON(200, UserSerializer.Serialize(&user))
+//	err := UserSerializer.Decode(c.Request.Body, &user)
+package serializer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/openapi"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/validation"
+)
+
+// NonFieldErrors is the error key for failures that belong to no single field
+const NonFieldErrors = "non_field_errors"
+
+// FieldSpec declares one serialized field
+type FieldSpec struct {
+	source    string
+	key       string
+	readOnly  bool
+	writeOnly bool
+	omit      bool
+	rules     string
+	hasRules  bool
+	nested    Any
+}
+
+// Field declares a field read from and written to source, a Go field name or a
+// dotted path such as "Profile.Bio"
+func Field(source string) *FieldSpec {
+	return &FieldSpec{source: source}
+}
+
+// As sets the JSON key; the default is the field's json tag or Go name
+func (f *FieldSpec) As(key string) *FieldSpec {
+	f.key = key
+	return f
+}
+
+// ReadOnly includes the field in output and ignores it in input
+func (f *FieldSpec) ReadOnly() *FieldSpec {
+	f.readOnly = true
+	return f
+}
+
+// WriteOnly accepts the field in input and leaves it out of output, e.g. passwords
+func (f *FieldSpec) WriteOnly() *FieldSpec {
+	f.writeOnly = true
+	return f
+}
+
+// Validate sets the input rules, e.g. "required,min=3"; the default is the field's
+// `validate` tag
+func (f *FieldSpec) Validate(rules string) *FieldSpec {
+	f.rules = rules
+	f.hasRules = true
+	return f
+}
+
+// Nested serializes a struct, pointer or slice field with another serializer
+func (f *FieldSpec) Nested(s Any) *FieldSpec {
+	f.nested = s
+	return f
+}
+
+// Omit leaves a field out of an Auto serializer
+func (f *FieldSpec) Omit() *FieldSpec {
+	f.omit = true
+	return f
+}
+
+// Any is implemented by every *Serializer[T], so serializers of different models can nest
+type Any interface {
+	OpenAPISchema() *openapi.Schema
+	modelType() reflect.Type
+	serializeValue(v reflect.Value) map[string]interface{}
+	bindValue(raw json.RawMessage, v reflect.Value, partial bool, errs validation.Errors, prefix string)
+}
+
+// field is a FieldSpec resolved against the model type
+type field struct {
+	FieldSpec
+	index [][]int // field index per path segment
+	typ   reflect.Type
+	rules validation.Rules
+}
+
+type computed[T any] struct {
+	key string
+	fn  func(*T) interface{}
+}
+
+// Serializer converts between T and JSON objects
+type Serializer[T any] struct {
+	fields     []*field
+	computed   []computed[T]
+	validators []func(*T) error
+}
+
+// New creates a serializer with exactly the given fields. It panics if a field or
+// rule does not exist, since that is a programming error.
+func New[T any](fields ...*FieldSpec) *Serializer[T] {
+	s := &Serializer[T]{}
+	for _, spec := range fields {
+		if !spec.omit {
+			s.fields = append(s.fields, s.resolve(spec))
+		}
+	}
+	return s
+}
+
+// Auto creates a serializer with every exported field of T, keyed by json tags and
+// validated by `validate` tags. Specs change how a field is handled or add dotted
+// sources; use Omit to leave a field out.
+func Auto[T any](overrides ...*FieldSpec) *Serializer[T] {
+	specs := make(map[string]*FieldSpec)
+	for _, spec := range overrides {
+		specs[spec.source] = spec
+	}
+
+	var fields []*FieldSpec
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for _, sf := range reflect.VisibleFields(t) {
+		if !sf.IsExported() || sf.Anonymous && sf.Type.Kind() == reflect.Struct || sf.Tag.Get("json") == "-" {
+			continue
+		}
+		if spec, ok := specs[sf.Name]; ok {
+			fields = append(fields, spec)
+			delete(specs, sf.Name)
+			continue
+		}
+		fields = append(fields, Field(sf.Name))
+	}
+	for _, spec := range overrides {
+		if _, ok := specs[spec.source]; ok {
+			fields = append(fields, spec)
+		}
+	}
+	return New[T](fields...)
+}
+
+func (s *Serializer[T]) resolve(spec *FieldSpec) *field {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	f := &field{FieldSpec: *spec}
+
+	var last reflect.StructField
+	for _, name := range strings.Split(spec.source, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			panic(fmt.Sprintf("serializer: %s: %s is not a struct", spec.source, t))
+		}
+		sf, ok := t.FieldByName(name)
+		if !ok || !sf.IsExported() {
+			panic(fmt.Sprintf("serializer: %s has no exported field %s", t, name))
+		}
+		f.index = append(f.index, sf.Index)
+		t = sf.Type
+		last = sf
+	}
+	f.typ = t
+
+	if f.key == "" {
+		f.key = validation.FieldName(last)
+	}
+	rules := spec.rules
+	if !spec.hasRules {
+		rules = last.Tag.Get("validate")
+	}
+	parsed, err := validation.Parse(rules)
+	if err != nil {
+		panic(fmt.Sprintf("serializer: %s: %v", spec.source, err))
+	}
+	f.rules = parsed
+
+	if f.nested != nil {
+		elem := t
+		for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Slice {
+			elem = elem.Elem()
+		}
+		if elem != f.nested.modelType() {
+			panic(fmt.Sprintf("serializer: %s holds %s, not the nested serializer's %s", spec.source, elem, f.nested.modelType()))
+		}
+	}
+	return f
+}
+
+// Computed adds a read-only output field calculated from the model
+func (s *Serializer[T]) Computed(key string, fn func(*T) interface{}) *Serializer[T] {
+	s.computed = append(s.computed, computed[T]{key: key, fn: fn})
+	return s
+}
+
+// ValidateWith adds a check that runs on the decoded model after the field rules
+// pass. Return validation.Errors to report fields; other errors are reported
+// under NonFieldErrors.
+func (s *Serializer[T]) ValidateWith(fn func(*T) error) *Serializer[T] {
+	s.validators = append(s.validators, fn)
+	return s
+}
+
+// Serialize returns the output fields of model
+func (s *Serializer[T]) Serialize(model *T) map[string]interface{} {
+	if model == nil {
+		return nil
+	}
+	return s.serializeValue(reflect.ValueOf(model).Elem())
+}
+
+// SerializeMany serializes each model
+func (s *Serializer[T]) SerializeMany(models []T) []map[string]interface{} {
+	out := make([]map[string]interface{}, len(models))
+	for i := range models {
+		out[i] = s.Serialize(&models[i])
+	}
+	return out
+}
+
+// Decode reads a JSON object from r into model. Every writable field must pass
+// validation; model is left unchanged when it returns validation.Errors.
+func (s *Serializer[T]) Decode(r io.Reader, model *T) error {
+	return s.decode(r, model, false)
+}
+
+// DecodePartial is Decode for PATCH requests: missing fields keep their values and
+// "required" only applies to fields that are present
+func (s *Serializer[T]) DecodePartial(r io.Reader, model *T) error {
+	return s.decode(r, model, true)
+}
+
+func (s *Serializer[T]) decode(r io.Reader, model *T, partial bool) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || body[0] != '{' {
+		return validation.Errors{NonFieldErrors: "Expected a JSON object."}
+	}
+
+	// Bind into a copy so a failed decode leaves model untouched
+	working := *model
+	errs := validation.Errors{}
+	s.bindValue(body, reflect.ValueOf(&working).Elem(), partial, errs, "")
+	if len(errs) > 0 {
+		return errs
+	}
+	*model = working
+	return nil
+}
+
+func (s *Serializer[T]) modelType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (s *Serializer[T]) serializeValue(v reflect.Value) map[string]interface{} {
+	data := make(map[string]interface{}, len(s.fields)+len(s.computed))
+	for _, f := range s.fields {
+		if f.writeOnly {
+			continue
+		}
+		value, ok := f.get(v)
+		if !ok {
+			data[f.key] = nil
+			continue
+		}
+		if f.nested != nil {
+			data[f.key] = serializeNested(f.nested, value)
+		} else {
+			data[f.key] = value.Interface()
+		}
+	}
+	if len(s.computed) > 0 && v.CanAddr() {
+		model := v.Addr().Interface().(*T)
+		for _, c := range s.computed {
+			data[c.key] = c.fn(model)
+		}
+	}
+	return data
+}
+
+func serializeNested(nested Any, v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return serializeNested(nested, v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			return []interface{}{}
+		}
+		out := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out[i] = serializeNested(nested, v.Index(i))
+		}
+		return out
+	}
+	if !v.CanAddr() {
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		v = copied
+	}
+	return nested.serializeValue(v)
+}
+
+func (s *Serializer[T]) bindValue(raw json.RawMessage, v reflect.Value, partial bool, errs validation.Errors, prefix string) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &object); err != nil {
+		errs[errorKey(prefix, NonFieldErrors)] = "Expected a JSON object."
+		return
+	}
+
+	before := len(errs)
+	for _, f := range s.fields {
+		if f.readOnly {
+			continue
+		}
+		key := errorKey(prefix, f.key)
+		value, present := object[f.key]
+		if !present {
+			if !partial && f.rules.Required() {
+				errs[key] = "This field is required."
+			}
+			continue
+		}
+
+		target := f.set(v)
+		if f.nested != nil {
+			bindNested(f.nested, value, target, partial, errs, key)
+			if message := f.rules.Check(target); message != "" {
+				errs[key] = message
+			}
+			continue
+		}
+
+		decoded := reflect.New(f.typ)
+		if err := json.Unmarshal(value, decoded.Interface()); err != nil {
+			errs[key] = invalidMessage(f.typ)
+			continue
+		}
+		if message := f.rules.Check(decoded.Elem()); message != "" {
+			errs[key] = message
+			continue
+		}
+		target.Set(decoded.Elem())
+	}
+
+	if len(errs) > before || len(s.validators) == 0 {
+		return
+	}
+	model := v.Addr().Interface().(*T)
+	for _, validate := range s.validators {
+		err := validate(model)
+		if err == nil {
+			continue
+		}
+		if fieldErrs, ok := err.(validation.Errors); ok {
+			for key, message := range fieldErrs {
+				errs[errorKey(prefix, key)] = message
+			}
+		} else {
+			errs[errorKey(prefix, NonFieldErrors)] = err.Error()
+		}
+	}
+}
+
+func bindNested(nested Any, raw json.RawMessage, v reflect.Value, partial bool, errs validation.Errors, key string) {
+	if string(bytes.TrimSpace(raw)) == "null" {
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+		default:
+			errs[key] = "This field may not be null."
+		}
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if !v.IsNil() {
+			elem.Elem().Set(v.Elem())
+		}
+		bindNested(nested, raw, elem.Elem(), partial, errs, key)
+		v.Set(elem)
+	case reflect.Slice:
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			errs[key] = "Expected a list of objects."
+			return
+		}
+		// Items are new objects, so every required field must be present
+		out := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			bindNested(nested, item, out.Index(i), false, errs, key+"."+strconv.Itoa(i))
+		}
+		v.Set(out)
+	default:
+		nested.bindValue(raw, v, partial, errs, key)
+	}
+}
+
+// get walks the field path, reporting false if it crosses a nil pointer
+func (f *field) get(v reflect.Value) (reflect.Value, bool) {
+	for _, index := range f.index {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.FieldByIndex(index)
+	}
+	return v, true
+}
+
+// set walks the field path. Pointers on the way are replaced by copies, or allocated
+// when nil, so writes never reach values shared with the original model.
+func (f *field) set(v reflect.Value) reflect.Value {
+	for _, index := range f.index {
+		for v.Kind() == reflect.Ptr {
+			clone := reflect.New(v.Type().Elem())
+			if !v.IsNil() {
+				clone.Elem().Set(v.Elem())
+			}
+			v.Set(clone)
+			v = clone.Elem()
+		}
+		v = v.FieldByIndex(index)
+	}
+	return v
+}
+
+func errorKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func invalidMessage(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "Must be a string."
+	case reflect.Bool:
+		return "Must be a boolean."
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "Must be a whole number."
+	case reflect.Float32, reflect.Float64:
+		return "Must be a number."
+	case reflect.Slice, reflect.Array:
+		return "Must be a list."
+	}
+	return "Invalid value."
+}
@@ -0,0 +1,231 @@
+package serializer
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/validation"
+)
+
+type profile struct {
+	Bio string `json:"bio" validate:"max=10"`
+}
+
+type tag struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type user struct {
+	ID       uint
+	Email    string
+	Password string
+	Age      int
+	Profile  *profile
+	Tags     []tag
+}
+
+var (
+	profileSerializer = Auto[profile]()
+	tagSerializer     = Auto[tag]()
+	userSerializer    = New[user](
+		Field("ID").As("id").ReadOnly(),
+		Field("Email").As("email").Validate("required,email"),
+		Field("Password").As("password").WriteOnly().Validate("required,min=8"),
+		Field("Age").As("age").Validate("min=0,max=150"),
+		Field("Profile").As("profile").Nested(profileSerializer),
+		Field("Profile.Bio").As("bio").ReadOnly(),
+		Field("Tags").As("tags").Nested(tagSerializer),
+	).Computed("adult", func(u *user) interface{} { return u.Age >= 18 })
+)
+
+func TestSerialize(t *testing.T) {
+	u := &user{ID: 7, Email: "a@example.com", Password: "secret", Age: 30,
+		Profile: &profile{Bio: "hi"}, Tags: []tag{{Name: "go"}}}
+	want := map[string]interface{}{
+		"id":      uint(7),
+		"email":   "a@example.com",
+		"age":     30,
+		"profile": map[string]interface{}{"bio": "hi"},
+		"bio":     "hi",
+		"tags":    []interface{}{map[string]interface{}{"name": "go"}},
+		"adult":   true,
+	}
+	if got := userSerializer.Serialize(u); !reflect.DeepEqual(got, want) {
+		t.Errorf("Serialize\n got %#v\nwant %#v", got, want)
+	}
+
+	// Nil pointers on a dotted path and nil slices serialize as null and []
+	got := userSerializer.Serialize(&user{})
+	if got["profile"] != nil || got["bio"] != nil || !reflect.DeepEqual(got["tags"], []interface{}{}) {
+		t.Errorf("empty user serialized as %#v", got)
+	}
+	if _, ok := got["password"]; ok {
+		t.Error("a write-only field was serialized")
+	}
+	if userSerializer.Serialize(nil) != nil {
+		t.Error("Serialize(nil) is not nil")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		errs validation.Errors
+	}{
+		{"valid", `{"email":"a@example.com","password":"longenough","age":20,"profile":{"bio":"hi"},"tags":[{"name":"go"}]}`, nil},
+		{"missing required", `{}`, validation.Errors{"email": "This field is required.", "password": "This field is required."}},
+		{"rule failures", `{"email":"nope","password":"short","age":200}`, validation.Errors{
+			"email":    "Enter a valid email address.",
+			"password": "Ensure this field has at least 8 characters or items.",
+			"age":      "Ensure this value is less than or equal to 150.",
+		}},
+		{"wrong types", `{"email":1,"password":"longenough","age":"x"}`, validation.Errors{"email": "Must be a string.", "age": "Must be a whole number."}},
+		{"nested errors", `{"email":"a@example.com","password":"longenough","profile":{"bio":"far too long"},"tags":[{"name":"go"},{}]}`, validation.Errors{
+			"profile.bio": "Ensure this field has no more than 10 characters or items.",
+			"tags.1.name": "This field is required.",
+		}},
+		{"not an object", `[1]`, validation.Errors{NonFieldErrors: "Expected a JSON object."}},
+		{"nested list type", `{"email":"a@example.com","password":"longenough","tags":{}}`, validation.Errors{"tags": "Expected a list of objects."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := user{ID: 1, Email: "old@example.com"}
+			u := original
+			err := userSerializer.Decode(strings.NewReader(tt.body), &u)
+			if tt.errs == nil {
+				if err != nil {
+					t.Fatalf("Decode = %v", err)
+				}
+				return
+			}
+			var errs validation.Errors
+			if !errors.As(err, &errs) || !reflect.DeepEqual(errs, tt.errs) {
+				t.Fatalf("Decode errors\n got %#v\nwant %#v", err, tt.errs)
+			}
+			if !reflect.DeepEqual(u, original) {
+				t.Errorf("a failed decode changed the model to %+v", u)
+			}
+		})
+	}
+}
+
+func TestDecodeFields(t *testing.T) {
+	shared := &profile{Bio: "old"}
+	u := user{ID: 1, Profile: shared}
+	body := `{"id":99,"email":"a@example.com","password":"longenough","age":20,"profile":{"bio":"new"},"tags":[{"name":"go"}]}`
+	if err := userSerializer.Decode(strings.NewReader(body), &u); err != nil {
+		t.Fatal(err)
+	}
+	if u.ID != 1 {
+		t.Errorf("a read-only field was decoded: ID = %d", u.ID)
+	}
+	if u.Email != "a@example.com" || u.Password != "longenough" || u.Age != 20 || u.Profile.Bio != "new" || len(u.Tags) != 1 {
+		t.Errorf("decoded %+v", u)
+	}
+	if shared.Bio != "old" {
+		t.Error("decoding wrote through a pointer shared with the original model")
+	}
+}
+
+func TestDecodePartial(t *testing.T) {
+	u := user{Email: "a@example.com", Password: "longenough", Age: 20}
+	if err := userSerializer.DecodePartial(strings.NewReader(`{"age":21}`), &u); err != nil {
+		t.Fatal(err)
+	}
+	if u.Age != 21 || u.Email != "a@example.com" {
+		t.Errorf("partial decode gave %+v", u)
+	}
+
+	err := userSerializer.DecodePartial(strings.NewReader(`{"email":""}`), &u)
+	var errs validation.Errors
+	if !errors.As(err, &errs) || errs["email"] != "This field is required." {
+		t.Errorf("clearing a required field = %v", err)
+	}
+}
+
+func TestValidateWith(t *testing.T) {
+	s := New[user](
+		Field("Email").As("email"),
+		Field("Password").As("password"),
+	).ValidateWith(func(u *user) error {
+		if strings.Contains(u.Password, u.Email) {
+			return validation.Errors{"password": "Must not contain the email."}
+		}
+		return nil
+	}).ValidateWith(func(u *user) error {
+		if u.Email == "banned@example.com" {
+			return errors.New("This account is closed.")
+		}
+		return nil
+	})
+
+	tests := []struct {
+		body string
+		want validation.Errors
+	}{
+		{`{"email":"a","password":"xax"}`, validation.Errors{"password": "Must not contain the email."}},
+		{`{"email":"banned@example.com","password":"x"}`, validation.Errors{NonFieldErrors: "This account is closed."}},
+		{`{"email":"a@example.com","password":"x"}`, nil},
+	}
+	for _, tt := range tests {
+		var u user
+		err := s.Decode(strings.NewReader(tt.body), &u)
+		if tt.want == nil {
+			if err != nil {
+				t.Errorf("%s: Decode = %v", tt.body, err)
+			}
+			continue
+		}
+		var errs validation.Errors
+		if !errors.As(err, &errs) || !reflect.DeepEqual(errs, tt.want) {
+			t.Errorf("%s: errors %#v, want %#v", tt.body, err, tt.want)
+		}
+	}
+}
+
+func TestAuto(t *testing.T) {
+	type article struct {
+		ID     uint   `json:"id"`
+		Title  string `json:"title" validate:"required"`
+		Secret string `json:"-"`
+		Draft  bool   `json:"draft"`
+	}
+	s := Auto[article](Field("Draft").Omit(), Field("ID").As("id").ReadOnly())
+
+	got := s.Serialize(&article{ID: 1, Title: "Hello", Secret: "x", Draft: true})
+	want := map[string]interface{}{"id": uint(1), "title": "Hello"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Serialize\n got %#v\nwant %#v", got, want)
+	}
+
+	var a article
+	err := s.Decode(strings.NewReader(`{}`), &a)
+	var errs validation.Errors
+	if !errors.As(err, &errs) || errs["title"] != "This field is required." {
+		t.Errorf("Decode without the tagged required field = %v", err)
+	}
+}
+
+func TestNewPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func()
+	}{
+		{"unknown field", func() { New[user](Field("Missing")) }},
+		{"unknown rule", func() { New[user](Field("Email").Validate("shiny")) }},
+		{"wrong nested type", func() { New[user](Field("Profile").Nested(tagSerializer)) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("New did not panic")
+				}
+			}()
+			tt.fn()
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/logging"
+	"go.uber.org/zap"
+)
+
+// consumer is a handler registered with Consume
+type consumer struct {
+	topic       string
+	handler     Handler
+	group       string
+	maxAttempts int
+	deadLetter  string
+}
+
+// ConsumeOption changes how a consumer is run
+type ConsumeOption func(*consumer)
+
+// Group names the consumer group instead of stream.group. Each group gets
+// every message of the topic.
+func Group(name string) ConsumeOption {
+	return func(c *consumer) { c.group = name }
+}
+
+// MaxAttempts is how many times a failing message is handled in a row before
+// it is given back to the broker or sent to the dead letter topic (default 3)
+func MaxAttempts(n int) ConsumeOption {
+	return func(c *consumer) { c.maxAttempts = n }
+}
+
+// DeadLetter publishes messages still failing after MaxAttempts to topic, with
+// the error in the "x-error" header, and acknowledges them. Without it they are
+// delivered again, later, until they succeed.
+func DeadLetter(topic string) ConsumeOption {
+	return func(c *consumer) { c.deadLetter = topic }
+}
+
+var (
+	consumers      []*consumer
+	consumersMutex sync.RWMutex
+)
+
+// Consume registers handler for the messages of topic. Register consumers in
+// the app's init, before stream:consume or the server runs them.
+func Consume(topic string, handler Handler, opts ...ConsumeOption) {
+	c := &consumer{topic: topic, handler: handler, maxAttempts: 3}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.maxAttempts <= 0 {
+		c.maxAttempts = 1
+	}
+	consumersMutex.Lock()
+	defer consumersMutex.Unlock()
+	consumers = append(consumers, c)
+}
+
+// Topics lists the topics with registered consumers
+func Topics() []string {
+	consumersMutex.RLock()
+	defer consumersMutex.RUnlock()
+	seen := make(map[string]bool)
+	var topics []string
+	for _, c := range consumers {
+		if !seen[c.topic] {
+			seen[c.topic] = true
+			topics = append(topics, c.topic)
+		}
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// ErrNoConsumers is returned by Run when no consumer is registered for its topics
+var ErrNoConsumers = errors.New("stream: no consumers registered")
+
+// RunOptions configures Run
+type RunOptions struct {
+	// Group is the consumer group of consumers registered without Group
+	Group string
+	// Topics limits the consumers run to these topics (default: all)
+	Topics []string
+	// Logger receives failed messages and broker errors
+	Logger *logging.Logger
+}
+
+// Run runs the registered consumers on b until ctx is done. It then stops
+// reading and waits for the messages being handled, whose handlers get a
+// context that is not cancelled, to be acknowledged.
+func Run(ctx context.Context, b Broker, opts RunOptions) error {
+	consumersMutex.RLock()
+	var run []*consumer
+	for _, c := range consumers {
+		if len(opts.Topics) == 0 || contains(opts.Topics, c.topic) {
+			run = append(run, c)
+		}
+	}
+	consumersMutex.RUnlock()
+	if len(run) == 0 {
+		return ErrNoConsumers
+	}
+
+	logger := logging.Nop()
+	if opts.Logger != nil {
+		logger = opts.Logger.Module(logging.ModuleStream)
+	}
+
+	groups := make([]string, len(run))
+	for i, c := range run {
+		groups[i] = c.group
+		if groups[i] == "" {
+			groups[i] = opts.Group
+		}
+		if groups[i] == "" {
+			return fmt.Errorf("stream: no consumer group for topic %s", c.topic)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i, c := range run {
+		wg.Add(1)
+		go func(c *consumer, group string) {
+			defer wg.Done()
+			c.run(ctx, b, group, logger.WithContext(zap.String("topic", c.topic), zap.String("group", group)))
+		}(c, groups[i])
+	}
+	wg.Wait()
+	return nil
+}
+
+// run reads the topic until ctx is done, subscribing again after broker errors
+func (c *consumer) run(ctx context.Context, b Broker, group string, logger *logging.Logger) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		sub, err := b.Subscribe(ctx, c.topic, group)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Error("Failed to subscribe", zap.Error(err), zap.Duration("retry_in", backoff))
+			}
+			if !sleep(ctx, backoff) {
+				return
+			}
+			backoff = min(backoff*2, time.Minute)
+			continue
+		}
+		backoff = time.Second
+
+		for {
+			delivery, err := sub.Next(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.Error("Failed to read message", zap.Error(err))
+					sleep(ctx, time.Second)
+				}
+				break
+			}
+			c.handle(context.WithoutCancel(ctx), b, delivery, logger)
+		}
+		_ = sub.Close()
+	}
+}
+
+// handle runs the handler on a delivery, retrying up to maxAttempts times
+func (c *consumer) handle(ctx context.Context, b Broker, d Delivery, logger *logging.Logger) {
+	msg := d.Message()
+	var err error
+	for attempt := 1; attempt <= c.maxAttempts; attempt++ {
+		if err = c.call(ctx, msg); err == nil {
+			if ackErr := d.Ack(ctx); ackErr != nil {
+				logger.Error("Failed to acknowledge message", zap.String("id", msg.ID), zap.Error(ackErr))
+			}
+			return
+		}
+		logger.Warn("Message failed", zap.String("id", msg.ID), zap.Int("attempt", attempt), zap.Error(err))
+		if attempt < c.maxAttempts {
+			time.Sleep(time.Duration(attempt*attempt) * 100 * time.Millisecond)
+		}
+	}
+
+	if c.deadLetter != "" {
+		dead := &Message{Topic: c.deadLetter, Key: msg.Key, Value: msg.Value, Headers: map[string]string{}}
+		for key, value := range msg.Headers {
+			dead.Headers[key] = value
+		}
+		dead.Headers["x-error"] = err.Error()
+		dead.Headers["x-original-topic"] = msg.Topic
+		dead.Headers["x-original-id"] = msg.ID
+		pubErr := b.Publish(ctx, dead)
+		if pubErr == nil {
+			logger.Error("Message sent to dead letter topic", zap.String("id", msg.ID), zap.String("dead_letter", c.deadLetter), zap.Error(err))
+			if ackErr := d.Ack(ctx); ackErr != nil {
+				logger.Error("Failed to acknowledge message", zap.String("id", msg.ID), zap.Error(ackErr))
+			}
+			return
+		}
+		logger.Error("Failed to publish to dead letter topic", zap.String("id", msg.ID), zap.Error(pubErr))
+	}
+
+	logger.Error("Message failed, giving it back", zap.String("id", msg.ID), zap.Int("delivery", msg.Attempt), zap.Error(err))
+	if nackErr := d.Nack(ctx); nackErr != nil {
+		logger.Error("Failed to give message back", zap.String("id", msg.ID), zap.Error(nackErr))
+	}
+}
+
+// call runs the handler, turning a panic into an error
+func (c *consumer) call(ctx context.Context, msg *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return c.handler(ctx, msg)
+}
+
+// sleep waits d, returning false when ctx is done first
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
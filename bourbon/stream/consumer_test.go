@@ -0,0 +1,198 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// withConsumers replaces the registered consumers for the test
+func withConsumers(t *testing.T) {
+	t.Helper()
+	consumersMutex.Lock()
+	saved := consumers
+	consumers = nil
+	consumersMutex.Unlock()
+	t.Cleanup(func() {
+		consumersMutex.Lock()
+		consumers = saved
+		consumersMutex.Unlock()
+	})
+}
+
+// runUntil runs the consumers until done is closed
+func runUntil(t *testing.T, b Broker, opts RunOptions, done <-chan struct{}) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() { result <- Run(ctx, b, opts) }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("timed out waiting for the consumers")
+	}
+	cancel()
+	if err := <-result; err != nil {
+		t.Fatal(err)
+	}
+}
+
+type order struct {
+	ID    int    `json:"id"`
+	Total string `json:"total"`
+}
+
+func TestRunDecodes(t *testing.T) {
+	withConsumers(t)
+	b := NewMemoryBroker(time.Minute)
+	defer b.Close()
+
+	var mu sync.Mutex
+	var got []order
+	done := make(chan struct{})
+	Consume("orders", Decode(func(ctx context.Context, o order) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, o)
+		if len(got) == 2 {
+			close(done)
+		}
+		return nil
+	}))
+
+	for i, total := range []string{"9.99", "20.00"} {
+		msg, err := NewJSONMessage("orders", "", order{ID: i + 1, Total: total})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Headers["content-type"] != "application/json" {
+			t.Errorf("headers = %v", msg.Headers)
+		}
+		b.Publish(context.Background(), msg)
+	}
+	runUntil(t, b, RunOptions{Group: "billing"}, done)
+	if len(got) != 2 || got[0] != (order{1, "9.99"}) || got[1] != (order{2, "20.00"}) {
+		t.Errorf("decoded %v", got)
+	}
+
+	// Handled messages were acknowledged
+	sub, _ := b.Subscribe(context.Background(), "orders", "billing")
+	if !empty(sub) {
+		t.Error("a handled message was delivered again")
+	}
+}
+
+func TestRunRetries(t *testing.T) {
+	withConsumers(t)
+	b := NewMemoryBroker(time.Minute)
+	defer b.Close()
+
+	attempts := 0
+	done := make(chan struct{})
+	Consume("orders", func(ctx context.Context, msg *Message) error {
+		attempts++
+		if attempts == 1 {
+			return errors.New("database unavailable")
+		}
+		if attempts == 2 {
+			panic("handler bug")
+		}
+		close(done)
+		return nil
+	}, Group("billing"))
+
+	publish(t, b, "orders", "1")
+	runUntil(t, b, RunOptions{}, done)
+	if attempts != 3 {
+		t.Errorf("handled %d times, want 3", attempts)
+	}
+}
+
+func TestRunDeadLetter(t *testing.T) {
+	withConsumers(t)
+	b := NewMemoryBroker(time.Minute)
+	defer b.Close()
+	dead, _ := b.Subscribe(context.Background(), "orders.dead", "ops")
+
+	Consume("orders", func(ctx context.Context, msg *Message) error {
+		return errors.New("invalid order")
+	}, MaxAttempts(1), DeadLetter("orders.dead"))
+
+	b.Publish(context.Background(), &Message{Topic: "orders", Key: "k", Value: []byte("bad"), Headers: map[string]string{"trace": "t1"}})
+	done := make(chan struct{})
+	var got *Message
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if d, err := dead.Next(ctx); err == nil {
+			got = d.Message()
+		}
+		close(done)
+	}()
+	runUntil(t, b, RunOptions{Group: "billing"}, done)
+
+	if got == nil {
+		t.Fatal("no message on the dead letter topic")
+	}
+	want := map[string]string{"trace": "t1", "x-error": "invalid order", "x-original-topic": "orders", "x-original-id": "1"}
+	for key, value := range want {
+		if got.Headers[key] != value {
+			t.Errorf("dead letter header %s = %q, want %q", key, got.Headers[key], value)
+		}
+	}
+	if got.Key != "k" || string(got.Value) != "bad" {
+		t.Errorf("dead letter message %+v", got)
+	}
+
+	// The failed message was acknowledged once it was dead-lettered
+	sub, _ := b.Subscribe(context.Background(), "orders", "billing")
+	if !empty(sub) {
+		t.Error("a dead-lettered message was delivered again")
+	}
+}
+
+func TestRunGivesBack(t *testing.T) {
+	withConsumers(t)
+	b := NewMemoryBroker(50 * time.Millisecond)
+	defer b.Close()
+
+	var deliveries []int
+	done := make(chan struct{})
+	Consume("orders", func(ctx context.Context, msg *Message) error {
+		deliveries = append(deliveries, msg.Attempt)
+		if msg.Attempt == 1 {
+			return errors.New("not yet")
+		}
+		close(done)
+		return nil
+	}, MaxAttempts(1))
+
+	publish(t, b, "orders", "1")
+	runUntil(t, b, RunOptions{Group: "billing"}, done)
+	if len(deliveries) != 2 || deliveries[0] != 1 || deliveries[1] != 2 {
+		t.Errorf("deliveries = %v, want [1 2]", deliveries)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	withConsumers(t)
+	b := NewMemoryBroker(time.Minute)
+	defer b.Close()
+
+	if err := Run(context.Background(), b, RunOptions{Group: "g"}); !errors.Is(err, ErrNoConsumers) {
+		t.Errorf("Run without consumers = %v", err)
+	}
+	Consume("orders", func(ctx context.Context, msg *Message) error { return nil })
+	if err := Run(context.Background(), b, RunOptions{Group: "g", Topics: []string{"payments"}}); !errors.Is(err, ErrNoConsumers) {
+		t.Errorf("Run for other topics = %v", err)
+	}
+	if err := Run(context.Background(), b, RunOptions{}); err == nil || errors.Is(err, ErrNoConsumers) {
+		t.Errorf("Run without a group = %v", err)
+	}
+	Consume("payments", func(ctx context.Context, msg *Message) error { return nil })
+	if topics := Topics(); len(topics) != 2 || topics[0] != "orders" || topics[1] != "payments" {
+		t.Errorf("Topics = %v", topics)
+	}
+}
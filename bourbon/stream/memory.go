@@ -0,0 +1,241 @@
+package stream
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// memoryRetention is how many messages a memory topic keeps for consumer
+// groups that have not subscribed yet
+const memoryRetention = 10000
+
+// MemoryBroker keeps topics in memory, for development and tests. Messages are
+// only delivered within the process that published them, and lost when it exits.
+type MemoryBroker struct {
+	claimAfter time.Duration
+	mu         sync.Mutex
+	topics     map[string]*memoryTopic
+	closed     bool
+}
+
+// memoryTopic is a topic's retained messages; offsets count from 1
+type memoryTopic struct {
+	base     int64 // offset of messages[0]
+	messages []*Message
+	groups   map[string]*memoryGroup
+	wake     chan struct{} // closed when a message becomes available
+}
+
+// memoryGroup is a consumer group's position in a topic
+type memoryGroup struct {
+	next      int64 // offset of the next new message
+	redeliver []memoryRedelivery
+	attempts  map[int64]int
+}
+
+type memoryRedelivery struct {
+	offset int64
+	msg    *Message
+	at     time.Time
+}
+
+// NewMemoryBroker creates an empty broker; messages given back are delivered
+// again after claimAfter
+func NewMemoryBroker(claimAfter time.Duration) *MemoryBroker {
+	if claimAfter <= 0 {
+		claimAfter = time.Minute
+	}
+	return &MemoryBroker{claimAfter: claimAfter, topics: make(map[string]*memoryTopic)}
+}
+
+// topic returns the named topic, creating it; b.mu must be held
+func (b *MemoryBroker) topic(name string) *memoryTopic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &memoryTopic{base: 1, groups: make(map[string]*memoryGroup), wake: make(chan struct{})}
+		b.topics[name] = t
+	}
+	return t
+}
+
+// signal wakes the subscriptions waiting on t; b.mu must be held
+func (t *memoryTopic) signal() {
+	close(t.wake)
+	t.wake = make(chan struct{})
+}
+
+// trim drops the messages every group has read; b.mu must be held
+func (t *memoryTopic) trim() {
+	keep := t.base + int64(len(t.messages))
+	if len(t.groups) == 0 {
+		keep = max(t.base, keep-memoryRetention)
+	}
+	for _, g := range t.groups {
+		keep = min(keep, g.next)
+	}
+	if drop := keep - t.base; drop > 0 {
+		t.messages = append([]*Message(nil), t.messages[drop:]...)
+		t.base = keep
+	}
+}
+
+// Publish appends msg to its topic
+func (b *MemoryBroker) Publish(ctx context.Context, msg *Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+	t := b.topic(msg.Topic)
+	stored := copyMessage(msg)
+	stored.ID = strconv.FormatInt(t.base+int64(len(t.messages)), 10)
+	stored.Time = time.Now().UTC()
+	t.messages = append(t.messages, stored)
+	t.trim()
+	t.signal()
+	msg.ID, msg.Time = stored.ID, stored.Time
+	return nil
+}
+
+// Subscribe joins a consumer group, starting a new one at the oldest message kept
+func (b *MemoryBroker) Subscribe(ctx context.Context, topic, group string) (Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrClosed
+	}
+	t := b.topic(topic)
+	if _, ok := t.groups[group]; !ok {
+		t.groups[group] = &memoryGroup{next: t.base, attempts: make(map[int64]int)}
+	}
+	return &memorySubscription{broker: b, topic: topic, group: group}, nil
+}
+
+// Close stops the broker; waiting subscriptions return ErrClosed
+func (b *MemoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		b.closed = true
+		for _, t := range b.topics {
+			t.signal()
+		}
+	}
+	return nil
+}
+
+type memorySubscription struct {
+	broker *MemoryBroker
+	topic  string
+	group  string
+	closed bool
+}
+
+func (s *memorySubscription) Next(ctx context.Context) (Delivery, error) {
+	b := s.broker
+	for {
+		b.mu.Lock()
+		if b.closed || s.closed {
+			b.mu.Unlock()
+			return nil, ErrClosed
+		}
+		t := b.topics[s.topic]
+		g := t.groups[s.group]
+
+		var offset int64
+		var msg *Message
+		var retryAt time.Time
+		now := time.Now()
+		for i, r := range g.redeliver {
+			if !r.at.After(now) {
+				offset, msg = r.offset, r.msg
+				g.redeliver = append(g.redeliver[:i:i], g.redeliver[i+1:]...)
+				break
+			}
+			if retryAt.IsZero() || r.at.Before(retryAt) {
+				retryAt = r.at
+			}
+		}
+		if msg == nil && g.next < t.base+int64(len(t.messages)) {
+			offset, msg = g.next, t.messages[g.next-t.base]
+			g.next++
+			t.trim()
+		}
+		if msg != nil {
+			g.attempts[offset]++
+			delivered := copyMessage(msg)
+			delivered.Attempt = g.attempts[offset]
+			b.mu.Unlock()
+			return &memoryDelivery{sub: s, offset: offset, msg: delivered}, nil
+		}
+
+		wake := t.wake
+		b.mu.Unlock()
+		var retry <-chan time.Time
+		if !retryAt.IsZero() {
+			retry = time.After(time.Until(retryAt))
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-wake:
+		case <-retry:
+		}
+	}
+}
+
+func (s *memorySubscription) Close() error {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+type memoryDelivery struct {
+	sub    *memorySubscription
+	offset int64
+	msg    *Message
+	done   bool
+}
+
+func (d *memoryDelivery) Message() *Message {
+	return d.msg
+}
+
+func (d *memoryDelivery) Ack(ctx context.Context) error {
+	b := d.sub.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !d.done {
+		d.done = true
+		delete(b.topics[d.sub.topic].groups[d.sub.group].attempts, d.offset)
+	}
+	return nil
+}
+
+func (d *memoryDelivery) Nack(ctx context.Context) error {
+	b := d.sub.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !d.done {
+		d.done = true
+		g := b.topics[d.sub.topic].groups[d.sub.group]
+		g.redeliver = append(g.redeliver, memoryRedelivery{offset: d.offset, msg: d.msg, at: time.Now().Add(b.claimAfter)})
+	}
+	return nil
+}
+
+// copyMessage copies msg so the broker's and the caller's don't change each other
+func copyMessage(msg *Message) *Message {
+	copied := *msg
+	copied.Value = append([]byte(nil), msg.Value...)
+	if msg.Headers != nil {
+		copied.Headers = make(map[string]string, len(msg.Headers))
+		for key, value := range msg.Headers {
+			copied.Headers[key] = value
+		}
+	}
+	return &copied
+}
@@ -0,0 +1,166 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func next(t *testing.T, sub Subscription) Delivery {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	d, err := sub.Next(ctx)
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	return d
+}
+
+// empty reports whether sub has no message ready within a short wait
+func empty(sub Subscription) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := sub.Next(ctx)
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+func publish(t *testing.T, b Broker, topic string, values ...string) {
+	t.Helper()
+	for _, value := range values {
+		if err := b.Publish(context.Background(), &Message{Topic: topic, Value: []byte(value)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMemoryBrokerGroups(t *testing.T) {
+	b := NewMemoryBroker(time.Minute)
+	defer b.Close()
+	ctx := context.Background()
+
+	// Messages published before a group subscribes are kept for it
+	publish(t, b, "orders", "1", "2")
+	billing, _ := b.Subscribe(ctx, "orders", "billing")
+	billing2, _ := b.Subscribe(ctx, "orders", "billing")
+	shipping, _ := b.Subscribe(ctx, "orders", "shipping")
+	publish(t, b, "orders", "3")
+
+	// Each group gets every message, once across its subscriptions
+	var billed []string
+	for i := 0; i < 3; i++ {
+		sub := billing
+		if i%2 == 1 {
+			sub = billing2
+		}
+		d := next(t, sub)
+		billed = append(billed, string(d.Message().Value))
+		d.Ack(ctx)
+	}
+	if billed[0] != "1" || billed[1] != "2" || billed[2] != "3" {
+		t.Errorf("billing read %v", billed)
+	}
+	if !empty(billing) || !empty(billing2) {
+		t.Error("billing got a message twice")
+	}
+	for _, want := range []string{"1", "2", "3"} {
+		d := next(t, shipping)
+		if got := string(d.Message().Value); got != want {
+			t.Errorf("shipping read %s, want %s", got, want)
+		}
+		if d.Message().ID != want || d.Message().Attempt != 1 || d.Message().Time.IsZero() {
+			t.Errorf("message %+v", d.Message())
+		}
+		d.Ack(ctx)
+	}
+}
+
+func TestMemoryBrokerWaits(t *testing.T) {
+	b := NewMemoryBroker(time.Minute)
+	defer b.Close()
+	sub, _ := b.Subscribe(context.Background(), "orders", "billing")
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		b.Publish(context.Background(), &Message{Topic: "orders", Value: []byte("late")})
+	}()
+	if d := next(t, sub); string(d.Message().Value) != "late" {
+		t.Errorf("read %q", d.Message().Value)
+	}
+}
+
+func TestMemoryBrokerNack(t *testing.T) {
+	b := NewMemoryBroker(200 * time.Millisecond)
+	defer b.Close()
+	ctx := context.Background()
+	sub, _ := b.Subscribe(ctx, "orders", "billing")
+	publish(t, b, "orders", "1")
+
+	d := next(t, sub)
+	d.Nack(ctx)
+	if !empty(sub) {
+		t.Fatal("a message given back was delivered before claimAfter")
+	}
+	d = next(t, sub)
+	if string(d.Message().Value) != "1" || d.Message().Attempt != 2 {
+		t.Errorf("redelivered %+v", d.Message())
+	}
+	d.Ack(ctx)
+	if !empty(sub) {
+		t.Error("an acknowledged message was delivered again")
+	}
+}
+
+func TestMemoryBrokerCopiesMessages(t *testing.T) {
+	b := NewMemoryBroker(time.Minute)
+	defer b.Close()
+	sub, _ := b.Subscribe(context.Background(), "orders", "billing")
+
+	msg := &Message{Topic: "orders", Value: []byte("a"), Headers: map[string]string{"h": "1"}}
+	if err := b.Publish(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.ID != "1" {
+		t.Errorf("Publish set ID %q", msg.ID)
+	}
+	msg.Value[0] = 'b'
+	msg.Headers["h"] = "2"
+	got := next(t, sub).Message()
+	if string(got.Value) != "a" || got.Headers["h"] != "1" {
+		t.Errorf("the published message changed the stored one: %+v", got)
+	}
+}
+
+func TestMemoryBrokerClose(t *testing.T) {
+	b := NewMemoryBroker(time.Minute)
+	sub, _ := b.Subscribe(context.Background(), "orders", "billing")
+
+	errs := make(chan error)
+	go func() {
+		_, err := sub.Next(context.Background())
+		errs <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	b.Close()
+	if err := <-errs; !errors.Is(err, ErrClosed) {
+		t.Errorf("waiting Next = %v, want ErrClosed", err)
+	}
+	if err := b.Publish(context.Background(), &Message{Topic: "orders"}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Publish after Close = %v", err)
+	}
+	if _, err := b.Subscribe(context.Background(), "orders", "x"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Subscribe after Close = %v", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	if b, err := Open(Config{}); err != nil {
+		t.Errorf("Open(memory) = %v", err)
+	} else if _, ok := b.(*MemoryBroker); !ok {
+		t.Errorf("default broker is %T", b)
+	}
+	if _, err := Open(Config{Driver: "carrier-pigeon"}); err == nil {
+		t.Error("Open of an unknown driver succeeded")
+	}
+}
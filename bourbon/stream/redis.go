@@ -0,0 +1,243 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisConfig holds the Redis connection settings
+type RedisConfig struct {
+	Addr     string
+	Username string
+	Password string
+	DB       int
+	// MaxLen trims each stream to about this many entries when publishing (default: no limit)
+	MaxLen int64
+}
+
+// redisHeaderPrefix marks the fields of a stream entry holding headers
+const redisHeaderPrefix = "h:"
+
+// RedisBroker keeps topics in Redis streams, read by consumer groups
+// (XREADGROUP) and acknowledged with XACK. Entries left pending by a consumer
+// that stopped, or given back, are taken over once idle for ClaimAfter
+// (XAUTOCLAIM).
+type RedisBroker struct {
+	client     redis.UniversalClient
+	claimAfter time.Duration
+	maxLen     int64
+	consumer   string
+}
+
+// NewRedisBroker connects to Redis
+func NewRedisBroker(cfg RedisConfig, claimAfter time.Duration) *RedisBroker {
+	if cfg.Addr == "" {
+		cfg.Addr = "localhost:6379"
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+	b := NewRedisBrokerWithClient(client, claimAfter)
+	b.maxLen = cfg.MaxLen
+	return b
+}
+
+// NewRedisBrokerWithClient uses an existing client, e.g. a cluster or sentinel client
+func NewRedisBrokerWithClient(client redis.UniversalClient, claimAfter time.Duration) *RedisBroker {
+	if claimAfter <= 0 {
+		claimAfter = time.Minute
+	}
+	host, _ := os.Hostname()
+	return &RedisBroker{
+		client:     client,
+		claimAfter: claimAfter,
+		// Names the consumer within its group, for XPENDING and XINFO
+		consumer: fmt.Sprintf("%s-%d", host, os.Getpid()),
+	}
+}
+
+// Client returns the underlying Redis client
+func (b *RedisBroker) Client() redis.UniversalClient {
+	return b.client
+}
+
+// Publish adds msg to the stream named by its topic
+func (b *RedisBroker) Publish(ctx context.Context, msg *Message) error {
+	values := []interface{}{"key", msg.Key, "value", msg.Value}
+	for key, value := range msg.Headers {
+		values = append(values, redisHeaderPrefix+key, value)
+	}
+	args := &redis.XAddArgs{Stream: msg.Topic, Values: values}
+	if b.maxLen > 0 {
+		args.MaxLen, args.Approx = b.maxLen, true
+	}
+	id, err := b.client.XAdd(ctx, args).Result()
+	if err != nil {
+		return fmt.Errorf("stream: publishing to %s: %w", msg.Topic, err)
+	}
+	msg.ID, msg.Time = id, redisIDTime(id)
+	return nil
+}
+
+// Subscribe creates the consumer group when it doesn't exist, reading the
+// stream from its first entry
+func (b *RedisBroker) Subscribe(ctx context.Context, topic, group string) (Subscription, error) {
+	err := b.client.XGroupCreateMkStream(ctx, topic, group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil, fmt.Errorf("stream: creating group %s on %s: %w", group, topic, err)
+	}
+	return &redisSubscription{broker: b, topic: topic, group: group}, nil
+}
+
+// Close closes the Redis client
+func (b *RedisBroker) Close() error {
+	return b.client.Close()
+}
+
+type redisSubscription struct {
+	broker    *RedisBroker
+	topic     string
+	group     string
+	buffered  []*Message
+	lastClaim time.Time
+}
+
+// redisBatch is how many entries a subscription reads at once
+const redisBatch = 10
+
+func (s *redisSubscription) Next(ctx context.Context) (Delivery, error) {
+	b := s.broker
+	for len(s.buffered) == 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// Take over idle pending entries before reading new ones
+		if time.Since(s.lastClaim) >= b.claimAfter/2 {
+			s.lastClaim = time.Now()
+			claimed, _, err := b.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+				Stream:   s.topic,
+				Group:    s.group,
+				Consumer: b.consumer,
+				MinIdle:  b.claimAfter,
+				Start:    "0-0",
+				Count:    redisBatch,
+			}).Result()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				return nil, err
+			}
+			for _, entry := range claimed {
+				msg := s.message(entry)
+				msg.Attempt = s.deliveries(ctx, entry.ID)
+				s.buffered = append(s.buffered, msg)
+			}
+			if len(s.buffered) > 0 {
+				break
+			}
+		}
+
+		streams, err := b.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    s.group,
+			Consumer: b.consumer,
+			Streams:  []string{s.topic, ">"},
+			Count:    redisBatch,
+			Block:    time.Second,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, stream := range streams {
+			for _, entry := range stream.Messages {
+				msg := s.message(entry)
+				msg.Attempt = 1
+				s.buffered = append(s.buffered, msg)
+			}
+		}
+	}
+
+	msg := s.buffered[0]
+	s.buffered = s.buffered[1:]
+	return &redisDelivery{sub: s, msg: msg}, nil
+}
+
+// message converts a stream entry
+func (s *redisSubscription) message(entry redis.XMessage) *Message {
+	msg := &Message{Topic: s.topic, ID: entry.ID, Time: redisIDTime(entry.ID)}
+	for field, value := range entry.Values {
+		text, _ := value.(string)
+		switch {
+		case field == "key":
+			msg.Key = text
+		case field == "value":
+			msg.Value = []byte(text)
+		case strings.HasPrefix(field, redisHeaderPrefix):
+			if msg.Headers == nil {
+				msg.Headers = make(map[string]string)
+			}
+			msg.Headers[strings.TrimPrefix(field, redisHeaderPrefix)] = text
+		}
+	}
+	return msg
+}
+
+// deliveries returns how many times an entry has been delivered to the group
+func (s *redisSubscription) deliveries(ctx context.Context, id string) int {
+	pending, err := s.broker.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: s.topic,
+		Group:  s.group,
+		Start:  id,
+		End:    id,
+		Count:  1,
+	}).Result()
+	if err != nil || len(pending) == 0 {
+		return 0
+	}
+	return int(pending[0].RetryCount)
+}
+
+// Close leaves the entries read but not handled pending, for other consumers to claim
+func (s *redisSubscription) Close() error {
+	s.buffered = nil
+	return nil
+}
+
+type redisDelivery struct {
+	sub *redisSubscription
+	msg *Message
+}
+
+func (d *redisDelivery) Message() *Message {
+	return d.msg
+}
+
+func (d *redisDelivery) Ack(ctx context.Context) error {
+	return d.sub.broker.client.XAck(ctx, d.sub.topic, d.sub.group, d.msg.ID).Err()
+}
+
+// Nack leaves the entry pending; it is claimed again once idle for ClaimAfter
+func (d *redisDelivery) Nack(ctx context.Context) error {
+	return nil
+}
+
+// redisIDTime returns the time in a stream entry ID, "<milliseconds>-<sequence>"
+func redisIDTime(id string) time.Time {
+	ms, _, _ := strings.Cut(id, "-")
+	n, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(n).UTC()
+}
@@ -0,0 +1,153 @@
+// Package stream consumes and publishes messages on a message broker, for
+// event-driven services. Consumers are registered by topic:
+//
+//	stream.Consume("orders", stream.Decode(func(ctx context.Context, order OrderPlaced) error {
+//	    return fulfil(ctx, order)
+//	}))
+//
+// and run by the stream:consume command, or by the server with
+// stream.consume_in_server. A message is acknowledged, committing the
+// consumer group's offset, once its handler returns nil.
+//
+// Memory and Redis Streams brokers are built in. Others, such as Kafka, NATS
+// or RabbitMQ, are added with RegisterBroker.
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Message is a record read from or published to a topic
+type Message struct {
+	Topic   string
+	Key     string // e.g. the ID of the entity, for brokers that partition by key
+	Value   []byte
+	Headers map[string]string
+	// ID is the message's position given by the broker, e.g. a Kafka offset or
+	// a Redis stream entry ID; it is set on received and published messages
+	ID   string
+	Time time.Time
+	// Attempt counts the deliveries of a received message to its consumer
+	// group, from 1, when the broker tracks them
+	Attempt int
+}
+
+// NewJSONMessage returns a message for topic with value encoded as JSON
+func NewJSONMessage(topic, key string, value interface{}) (*Message, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("stream: encoding %s message: %w", topic, err)
+	}
+	return &Message{Topic: topic, Key: key, Value: data, Headers: map[string]string{"content-type": "application/json"}}, nil
+}
+
+// Handler processes a message. Returning nil acknowledges it; an error has it
+// delivered again.
+type Handler func(ctx context.Context, msg *Message) error
+
+// Decode adapts a handler taking the message's value decoded from JSON into T
+func Decode[T any](handler func(ctx context.Context, value T) error) Handler {
+	return func(ctx context.Context, msg *Message) error {
+		var value T
+		if err := json.Unmarshal(msg.Value, &value); err != nil {
+			return fmt.Errorf("decoding %s message %s: %w", msg.Topic, msg.ID, err)
+		}
+		return handler(ctx, value)
+	}
+}
+
+// Delivery is a received message, to acknowledge once it has been handled
+type Delivery interface {
+	Message() *Message
+	// Ack marks the message as handled by the consumer group, committing its offset
+	Ack(ctx context.Context) error
+	// Nack gives the message back to be delivered again, after the broker's ClaimAfter
+	Nack(ctx context.Context) error
+}
+
+// Subscription reads a topic's messages for a consumer group. Each message
+// goes to one subscription of the group.
+type Subscription interface {
+	// Next blocks until a message is available or ctx is done
+	Next(ctx context.Context) (Delivery, error)
+	Close() error
+}
+
+// Broker connects to a message broker
+type Broker interface {
+	// Subscribe joins the consumer group of a topic. A new group starts with
+	// the oldest message the broker keeps.
+	Subscribe(ctx context.Context, topic, group string) (Subscription, error)
+	// Publish sends msg to msg.Topic and sets msg.ID
+	Publish(ctx context.Context, msg *Message) error
+	Close() error
+}
+
+// Config selects and configures the broker
+type Config struct {
+	Driver string // memory (default), redis or one added with RegisterBroker
+	// ClaimAfter is how long a message given back, or delivered to a consumer
+	// that stopped without acknowledging it, waits to be delivered again (default 1m)
+	ClaimAfter time.Duration
+	Redis      RedisConfig
+	// Options are the settings of brokers added with RegisterBroker
+	Options map[string]string
+}
+
+// OpenFunc opens a broker added with RegisterBroker
+type OpenFunc func(cfg Config) (Broker, error)
+
+var (
+	brokers     = make(map[string]OpenFunc)
+	brokerMutex sync.RWMutex
+)
+
+func init() {
+	RegisterBroker("memory", func(cfg Config) (Broker, error) {
+		return NewMemoryBroker(cfg.ClaimAfter), nil
+	})
+	RegisterBroker("redis", func(cfg Config) (Broker, error) {
+		return NewRedisBroker(cfg.Redis, cfg.ClaimAfter), nil
+	})
+}
+
+// RegisterBroker adds a broker the stream.driver setting can name, e.g. an
+// adapter for a Kafka, NATS or RabbitMQ client
+func RegisterBroker(name string, open OpenFunc) {
+	brokerMutex.Lock()
+	defer brokerMutex.Unlock()
+	brokers[name] = open
+}
+
+// Open creates the configured broker
+func Open(cfg Config) (Broker, error) {
+	driver := cfg.Driver
+	if driver == "" {
+		driver = "memory"
+	}
+	if cfg.ClaimAfter <= 0 {
+		cfg.ClaimAfter = time.Minute
+	}
+
+	brokerMutex.RLock()
+	open, ok := brokers[driver]
+	names := make([]string, 0, len(brokers))
+	for name := range brokers {
+		names = append(names, name)
+	}
+	brokerMutex.RUnlock()
+	if !ok {
+		sort.Strings(names)
+		return nil, fmt.Errorf("unsupported stream driver: %s (registered: %v)", driver, names)
+	}
+	return open(cfg)
+}
+
+// ErrClosed is returned by a closed broker or subscription
+var ErrClosed = errors.New("stream: closed")
@@ -0,0 +1,209 @@
+// Package bourbontest runs an application inside Go tests: New sets it up with
+// an in-memory SQLite database, requests go through its router and middleware
+// without a server, and the responses have assertions. Each test runs in a
+// database transaction that is rolled back when it ends.
+//
+//	func TestCreatePost(t *testing.T) {
+//	    testapp := bourbontest.New(t, bourbontest.Config{
+//	        Models: []any{&blog.Post{}},
+//	        Setup:  blog.Setup,
+//	    })
+//	    testapp.POST("/posts").WithJSON(map[string]any{"title": "Hello"}).Do().
+//	        AssertStatus(http.StatusCreated).
+//	        AssertJSONPath("title", "Hello")
+//	}
+package bourbontest
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+	_ "github.com/ishubhamsingh2e/bourbon/bourbon/drivers/sqlite"
+)
+
+// Config configures the application New sets up
+type Config struct {
+	// Settings is a settings file to start from, relative to the test's package
+	// directory, e.g. "../../settings.toml". The defaults are used when empty.
+	// The templates and static directories in it are taken relative to it.
+	Settings string
+	// SettingsDatabase keeps the database of Settings, e.g. a PostgreSQL test
+	// database, instead of an in-memory SQLite one
+	SettingsDatabase bool
+	// Databases gives the test its own database on a PostgreSQL or MySQL server,
+	// copied from a migrated template, instead of an in-memory SQLite one
+	Databases *Databases
+	// Configure adjusts the settings before the application is created
+	Configure func(cfg *core.Config)
+	// Models are created with AutoMigrate
+	Models []any
+	// Migrate runs the registered migrations, which the test binary must import
+	Migrate bool
+	// Setup registers routes, middleware and services, like the function given
+	// to cmd.SetCustomInit
+	Setup func(app *core.Application) error
+}
+
+// App is an application under test. Its DB, and the *gorm.DB it provides as a
+// service, is the test's transaction.
+type App struct {
+	*core.Application
+
+	t       testing.TB
+	cookies sync.Map // name -> *http.Cookie, sent with every request
+}
+
+// databases numbers the in-memory databases, one per App
+var databases atomic.Int64
+
+// New sets up an application for the test. The database is migrated, then a
+// transaction is started that every query of the test, and of the requests it
+// makes, runs in; it is rolled back when the test ends.
+func New(t testing.TB, config Config) *App {
+	t.Helper()
+
+	cfg, err := core.LoadConfig(config.Settings)
+	if err != nil {
+		t.Fatalf("bourbontest: %v", err)
+	}
+	testSettings(cfg, config)
+	if config.Databases != nil {
+		cfg.Database = config.Databases.database(t)
+	}
+	if config.Configure != nil {
+		config.Configure(cfg)
+	}
+
+	app := core.NewApplicationWithConfig(cfg)
+	if err := app.ConnectDB(); err != nil {
+		t.Fatalf("bourbontest: failed to connect to database: %v", err)
+	}
+	db := app.DB
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if len(config.Models) > 0 {
+		if err := orm.Primary(db).AutoMigrate(config.Models...); err != nil {
+			t.Fatalf("bourbontest: failed to create tables: %v", err)
+		}
+	}
+	if config.Migrate {
+		if err := core.RunMigrations(app); err != nil {
+			t.Fatalf("bourbontest: migration failed: %v", err)
+		}
+	}
+
+	tx := orm.Primary(db).Begin()
+	if tx.Error != nil {
+		t.Fatalf("bourbontest: failed to start the test transaction: %v", tx.Error)
+	}
+	t.Cleanup(func() {
+		// Queued jobs finish inside the transaction before it is rolled back
+		if app.Jobs != nil {
+			app.Jobs.Close()
+		}
+		if app.Cache != nil {
+			app.Cache.Close()
+		}
+		tx.Rollback()
+	})
+	app.DB = tx
+
+	app.MountHealth()
+	if config.Setup != nil {
+		if err := config.Setup(app); err != nil {
+			t.Fatalf("bourbontest: setup failed: %v", err)
+		}
+	}
+	return &App{Application: app, t: t}
+}
+
+// testSettings adapts the settings to tests: an in-memory database unless the
+// settings' one is kept, no file or database logging below errors, and nothing
+// watching files
+func testSettings(cfg *core.Config, config Config) {
+	if !config.SettingsDatabase {
+		cfg.Database = core.DatabaseConfig{
+			Driver:       "sqlite",
+			Path:         fmt.Sprintf("file:bourbontest-%d", databases.Add(1)),
+			MaxOpenConns: cfg.Database.MaxOpenConns,
+			MaxIdleConns: cfg.Database.MaxIdleConns,
+			Options: core.DatabaseOptions{
+				// Shared, so every connection of the pool sees the same database
+				Params: map[string]interface{}{"mode": "memory", "cache": "shared"},
+			},
+		}
+	}
+	cfg.Database.Health.Enabled = false
+
+	cfg.App.WatchConfig = false
+	cfg.App.LiveReload = false
+	cfg.Logging.Level = "error"
+	cfg.Logging.FileLogging = false
+	cfg.Logging.StoreErrorsInDB = false
+	cfg.Logging.Alerts.Enabled = false
+	cfg.Logging.Sinks = nil
+
+	// Relative paths in the settings are relative to the project root, where the
+	// server runs, while tests run in their package's directory
+	if config.Settings != "" {
+		dir := filepath.Dir(config.Settings)
+		paths := []*string{&cfg.Templates.Directory, &cfg.Static.Directory}
+		if config.SettingsDatabase && cfg.Database.Driver == "sqlite" {
+			paths = append(paths, &cfg.Database.Path)
+		}
+		for _, path := range paths {
+			if *path != "" && !filepath.IsAbs(*path) && !strings.HasPrefix(*path, "file:") && !strings.HasPrefix(*path, ":") {
+				*path = filepath.Join(dir, *path)
+			}
+		}
+	}
+}
+
+// GET starts a GET request to path, which may have a query string
+func (a *App) GET(path string) *Request {
+	return a.NewRequest(http.MethodGet, path)
+}
+
+// POST starts a POST request to path
+func (a *App) POST(path string) *Request {
+	return a.NewRequest(http.MethodPost, path)
+}
+
+// PUT starts a PUT request to path
+func (a *App) PUT(path string) *Request {
+	return a.NewRequest(http.MethodPut, path)
+}
+
+// PATCH starts a PATCH request to path
+func (a *App) PATCH(path string) *Request {
+	return a.NewRequest(http.MethodPatch, path)
+}
+
+// DELETE starts a DELETE request to path
+func (a *App) DELETE(path string) *Request {
+	return a.NewRequest(http.MethodDelete, path)
+}
+
+// NewRequest starts a request with any method
+func (a *App) NewRequest(method, path string) *Request {
+	return &Request{app: a, method: method, path: path, header: make(http.Header)}
+}
+
+// ClearCookies forgets the cookies responses have set
+func (a *App) ClearCookies() {
+	a.cookies.Range(func(name, _ any) bool {
+		a.cookies.Delete(name)
+		return true
+	})
+}
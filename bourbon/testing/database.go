@@ -0,0 +1,269 @@
+package bourbontest
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/core"
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+	"gorm.io/gorm"
+)
+
+// Databases creates the test databases of a package on the PostgreSQL or MySQL
+// server of a settings file, so tests marked t.Parallel don't see each other's
+// data. A template database is created and migrated once, by the first test
+// that needs it, and every test gets a copy of it, dropped when the test ends:
+//
+//	var databases = &bourbontest.Databases{Settings: "../../settings.toml", Migrate: true}
+//
+//	func TestMain(m *testing.M) {
+//	    databases.Main(m)
+//	}
+//
+//	func TestCheckout(t *testing.T) {
+//	    t.Parallel()
+//	    testapp := bourbontest.New(t, bourbontest.Config{Databases: databases, Setup: shop.Setup})
+//	    ...
+//	}
+//
+// PostgreSQL copies the template with CREATE DATABASE ... TEMPLATE; MySQL, which
+// has no templates, recreates its tables and copies their rows. The test binary
+// must import the database driver, e.g. bourbon/drivers/postgres.
+type Databases struct {
+	// Settings is the settings file with the server and credentials, relative to
+	// the package directory. The databases are named after its database.
+	Settings string
+	// Models are created with AutoMigrate in the template
+	Models []any
+	// Migrate runs the registered migrations in the template
+	Migrate bool
+	// PerPackage runs every test in the template itself, each in its own
+	// transaction, instead of in a copy. It is faster, but parallel tests may
+	// wait on each other's locks.
+	PerPackage bool
+
+	once     sync.Once
+	err      error
+	mu       sync.Mutex // serializes copying the template
+	server   *gorm.DB   // connection to the server, outside the test databases
+	template core.DatabaseConfig
+	count    int
+	created  []string // copies not dropped yet
+}
+
+// Main runs the package's tests, then drops the databases. Call it from TestMain.
+func (d *Databases) Main(m *testing.M) {
+	code := m.Run()
+	if err := d.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "bourbontest: %v\n", err)
+		if code == 0 {
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
+// Close drops the template and any copies left, and disconnects from the server
+func (d *Databases) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.server == nil {
+		return nil
+	}
+	var errs []string
+	for _, name := range append(d.created, d.template.Name) {
+		if err := d.drop(name); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	d.created = nil
+	if sqlDB, err := d.server.DB(); err == nil {
+		sqlDB.Close()
+	}
+	d.server = nil
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to drop test databases: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// database returns the settings of a database for the test: a new copy of the
+// template, dropped when the test ends, or the template itself with PerPackage
+func (d *Databases) database(t testing.TB) core.DatabaseConfig {
+	t.Helper()
+	d.once.Do(func() { d.err = d.createTemplate() })
+	if d.err != nil {
+		t.Fatalf("bourbontest: %v", d.err)
+	}
+	if d.PerPackage {
+		return d.template
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.server == nil {
+		t.Fatalf("bourbontest: the test databases are closed")
+	}
+	d.count++
+	name := fmt.Sprintf("%s_%d", d.template.Name, d.count)
+	if err := d.copyTemplate(name); err != nil {
+		d.drop(name)
+		t.Fatalf("bourbontest: failed to create test database %s: %v", name, err)
+	}
+	d.created = append(d.created, name)
+	// Registered before New's cleanups, so it runs after the app disconnects
+	t.Cleanup(func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if d.server == nil {
+			return
+		}
+		if err := d.drop(name); err != nil {
+			t.Errorf("bourbontest: %v", err)
+		}
+		for i, created := range d.created {
+			if created == name {
+				d.created = append(d.created[:i], d.created[i+1:]...)
+				break
+			}
+		}
+	})
+
+	database := d.template
+	database.Name = name
+	return database
+}
+
+// createTemplate connects to the server and creates and migrates the template
+func (d *Databases) createTemplate() error {
+	cfg, err := core.LoadConfig(d.Settings)
+	if err != nil {
+		return err
+	}
+	testSettings(cfg, Config{Settings: d.Settings, SettingsDatabase: true})
+	switch cfg.Database.Driver {
+	case "postgres", "mysql":
+	default:
+		return fmt.Errorf("test databases need a postgres or mysql database in %s, not %q", d.Settings, cfg.Database.Driver)
+	}
+
+	// Connect to the server without using the project's database
+	server := cfg.Database.ORMConfig()
+	server.Name = ""
+	if cfg.Database.Driver == "postgres" {
+		server.Name = "postgres"
+	}
+	server.Replicas = orm.ReplicasConfig{}
+	if d.server, err = orm.ConnectDatabase(server, false); err != nil {
+		return err
+	}
+
+	// Unique to this test binary, as packages are tested in parallel
+	base := cfg.Database.Name
+	if len(base) > 30 {
+		base = base[:30]
+	}
+	cfg.Database.Name = fmt.Sprintf("%s_test_%d", base, os.Getpid())
+	cfg.Database.Replicas = core.ReplicasConfig{}
+	d.template = cfg.Database
+	if err := d.drop(cfg.Database.Name); err != nil {
+		return err
+	}
+	if err := d.server.Exec("CREATE DATABASE " + d.quote(cfg.Database.Name)).Error; err != nil {
+		return fmt.Errorf("failed to create test database %s: %w", cfg.Database.Name, err)
+	}
+
+	app := core.NewApplicationWithConfig(cfg)
+	defer func() {
+		app.Jobs.Close()
+		if app.Cache != nil {
+			app.Cache.Close()
+		}
+	}()
+	if err := app.ConnectDB(); err != nil {
+		return err
+	}
+	// PostgreSQL copies a template only while nobody is connected to it
+	defer func() {
+		if sqlDB, err := app.DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}()
+	if len(d.Models) > 0 {
+		if err := orm.Primary(app.DB).AutoMigrate(d.Models...); err != nil {
+			return fmt.Errorf("failed to create tables: %w", err)
+		}
+	}
+	if d.Migrate {
+		if err := core.RunMigrations(app); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// copyTemplate creates the database name as a copy of the template
+func (d *Databases) copyTemplate(name string) error {
+	if d.template.Driver == "postgres" {
+		return d.server.Exec("CREATE DATABASE " + d.quote(name) + " TEMPLATE " + d.quote(d.template.Name)).Error
+	}
+
+	var tables []string
+	err := d.server.Raw("SELECT table_name FROM information_schema.tables WHERE table_schema = ? AND table_type = 'BASE TABLE'", d.template.Name).
+		Scan(&tables).Error
+	if err != nil {
+		return err
+	}
+	if err := d.server.Exec("CREATE DATABASE " + d.quote(name)).Error; err != nil {
+		return err
+	}
+	// One connection, as USE and FOREIGN_KEY_CHECKS are per session. The tables
+	// are created in any order, so foreign keys are checked only afterwards.
+	return d.server.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("USE " + d.quote(name)).Error; err != nil {
+			return err
+		}
+		if err := conn.Exec("SET FOREIGN_KEY_CHECKS = 0").Error; err != nil {
+			return err
+		}
+		defer conn.Exec("SET FOREIGN_KEY_CHECKS = 1")
+		for _, table := range tables {
+			source := d.quote(d.template.Name) + "." + d.quote(table)
+			var created, ddl string
+			if err := conn.Raw("SHOW CREATE TABLE "+source).Row().Scan(&created, &ddl); err != nil {
+				return err
+			}
+			if err := conn.Exec(ddl).Error; err != nil {
+				return err
+			}
+			if err := conn.Exec("INSERT INTO " + d.quote(table) + " SELECT * FROM " + source).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// drop drops a database if it exists
+func (d *Databases) drop(name string) error {
+	statement := "DROP DATABASE IF EXISTS " + d.quote(name)
+	if d.template.Driver == "postgres" {
+		// Connections a test leaked would otherwise block the drop
+		statement += " WITH (FORCE)"
+	}
+	if err := d.server.Exec(statement).Error; err != nil {
+		return fmt.Errorf("failed to drop test database %s: %w", name, err)
+	}
+	return nil
+}
+
+// quote quotes a database or table name
+func (d *Databases) quote(name string) string {
+	if d.template.Driver == "mysql" {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
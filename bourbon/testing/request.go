@@ -0,0 +1,130 @@
+package bourbontest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/database/orm"
+)
+
+// Request is a request being built, sent by Do:
+//
+//	testapp.GET("/posts").WithQuery("page", "2").WithBearer(token).Do()
+type Request struct {
+	app    *App
+	method string
+	path   string
+	header http.Header
+	query  url.Values
+	body   []byte
+	err    error
+}
+
+// WithJSON sends v encoded as JSON
+func (r *Request) WithJSON(v any) *Request {
+	body, err := json.Marshal(v)
+	if err != nil {
+		r.err = err
+	}
+	r.body = body
+	r.header.Set("Content-Type", "application/json")
+	return r
+}
+
+// WithForm sends values as a URL-encoded form
+func (r *Request) WithForm(values url.Values) *Request {
+	r.body = []byte(values.Encode())
+	r.header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+// WithBody sends body with the given content type
+func (r *Request) WithBody(contentType string, body []byte) *Request {
+	r.body = body
+	r.header.Set("Content-Type", contentType)
+	return r
+}
+
+// WithHeader sets a header
+func (r *Request) WithHeader(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// WithBearer sets an Authorization: Bearer header
+func (r *Request) WithBearer(token string) *Request {
+	return r.WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithQuery adds a query parameter
+func (r *Request) WithQuery(key, value string) *Request {
+	if r.query == nil {
+		r.query = make(url.Values)
+	}
+	r.query.Add(key, value)
+	return r
+}
+
+// WithCookie sends a cookie with this request only
+func (r *Request) WithCookie(cookie *http.Cookie) *Request {
+	r.header.Add("Cookie", cookie.String())
+	return r
+}
+
+// Do serves the request with the application's router and middleware. The
+// request's context carries the test transaction, so handlers and middleware
+// starting transactions use savepoints in it. Cookies the response sets are sent
+// with the app's later requests, as a browser would.
+func (r *Request) Do() *Response {
+	t := r.app.t
+	t.Helper()
+	if r.err != nil {
+		t.Fatalf("bourbontest: %s %s: %v", r.method, r.path, r.err)
+	}
+
+	target := r.path
+	if len(r.query) > 0 {
+		separator := "?"
+		if strings.Contains(target, "?") {
+			separator = "&"
+		}
+		target += separator + r.query.Encode()
+	}
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+	req := httptest.NewRequest(r.method, target, body)
+	for key, values := range r.header {
+		req.Header[key] = values
+	}
+	r.app.cookies.Range(func(_, cookie any) bool {
+		req.AddCookie(cookie.(*http.Cookie))
+		return true
+	})
+	req = req.WithContext(orm.ContextWithTx(req.Context(), r.app.DB))
+
+	recorder := httptest.NewRecorder()
+	r.app.Handler().ServeHTTP(recorder, req)
+	result := recorder.Result()
+
+	for _, cookie := range result.Cookies() {
+		if cookie.MaxAge < 0 || cookie.Value == "" {
+			r.app.cookies.Delete(cookie.Name)
+		} else {
+			r.app.cookies.Store(cookie.Name, cookie)
+		}
+	}
+	return &Response{
+		t:          t,
+		request:    r.method + " " + target,
+		StatusCode: result.StatusCode,
+		Header:     result.Header,
+		Body:       recorder.Body.Bytes(),
+	}
+}
@@ -0,0 +1,150 @@
+package bourbontest
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Response is a served request's response. Its assertions report failures with
+// t.Errorf and return the response, so they chain:
+//
+//	res.AssertStatus(http.StatusOK).AssertJSONPath("data.0.title", "Hello")
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	t       testing.TB
+	request string // method and URL, for failure messages
+}
+
+// Text returns the body as a string
+func (r *Response) Text() string {
+	return string(r.Body)
+}
+
+// JSON decodes the body into v, failing the test when it isn't JSON
+func (r *Response) JSON(v any) {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		r.t.Fatalf("%s: response is not JSON: %v\n%s", r.request, err, r.Body)
+	}
+}
+
+// AssertStatus checks the status code
+func (r *Response) AssertStatus(code int) *Response {
+	r.t.Helper()
+	if r.StatusCode != code {
+		r.t.Errorf("%s: status %d, want %d\n%s", r.request, r.StatusCode, code, r.Body)
+	}
+	return r
+}
+
+// AssertHeader checks a header's value
+func (r *Response) AssertHeader(key, value string) *Response {
+	r.t.Helper()
+	if got := r.Header.Get(key); got != value {
+		r.t.Errorf("%s: header %s is %q, want %q", r.request, key, got, value)
+	}
+	return r
+}
+
+// AssertRedirect checks that the response redirects to location
+func (r *Response) AssertRedirect(location string) *Response {
+	r.t.Helper()
+	if r.StatusCode < 300 || r.StatusCode >= 400 {
+		r.t.Errorf("%s: status %d, want a redirect to %s", r.request, r.StatusCode, location)
+		return r
+	}
+	return r.AssertHeader("Location", location)
+}
+
+// AssertContains checks that the body contains text
+func (r *Response) AssertContains(text string) *Response {
+	r.t.Helper()
+	if !strings.Contains(string(r.Body), text) {
+		r.t.Errorf("%s: body doesn't contain %q\n%s", r.request, text, r.Body)
+	}
+	return r
+}
+
+// AssertJSON checks that the body is the JSON encoding of expected, comparing
+// values rather than text, so key order and spacing don't matter
+func (r *Response) AssertJSON(expected any) *Response {
+	r.t.Helper()
+	var got any
+	if err := json.Unmarshal(r.Body, &got); err != nil {
+		r.t.Errorf("%s: response is not JSON: %v\n%s", r.request, err, r.Body)
+		return r
+	}
+	want, err := normalizeJSON(expected)
+	if err != nil {
+		r.t.Fatalf("%s: %v", r.request, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		r.t.Errorf("%s: JSON is\n%s\nwant\n%s", r.request, r.Body, mustJSON(want))
+	}
+	return r
+}
+
+// AssertJSONPath checks the value at a dotted path of the JSON body, with
+// numbers for array indexes, e.g. "data.0.title"
+func (r *Response) AssertJSONPath(path string, expected any) *Response {
+	r.t.Helper()
+	var value any
+	if err := json.Unmarshal(r.Body, &value); err != nil {
+		r.t.Errorf("%s: response is not JSON: %v\n%s", r.request, err, r.Body)
+		return r
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := value.(type) {
+		case map[string]any:
+			next, ok := node[key]
+			if !ok {
+				r.t.Errorf("%s: JSON has no %s\n%s", r.request, path, r.Body)
+				return r
+			}
+			value = next
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				r.t.Errorf("%s: JSON has no %s\n%s", r.request, path, r.Body)
+				return r
+			}
+			value = node[i]
+		default:
+			r.t.Errorf("%s: JSON has no %s\n%s", r.request, path, r.Body)
+			return r
+		}
+	}
+
+	want, err := normalizeJSON(expected)
+	if err != nil {
+		r.t.Fatalf("%s: %v", r.request, err)
+	}
+	if !reflect.DeepEqual(value, want) {
+		r.t.Errorf("%s: %s is %s, want %s", r.request, path, mustJSON(value), mustJSON(want))
+	}
+	return r
+}
+
+// normalizeJSON converts v to what decoding its JSON into an any gives, so ints
+// compare equal to the float64s of a decoded body
+func normalizeJSON(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var normalized any
+	err = json.Unmarshal(data, &normalized)
+	return normalized, err
+}
+
+func mustJSON(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
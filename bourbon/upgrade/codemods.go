@@ -0,0 +1,104 @@
+package upgrade
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// bourbonModule prefixes the import paths of the framework's packages
+const bourbonModule = "github.com/ishubhamsingh2e/bourbon/"
+
+func init() {
+	Register(Codemod{
+		Name:    "route-params",
+		Summary: `Route parameters written as ":id" become "{id}", the form the router matches`,
+		Fix:     fixRouteParams,
+	})
+	Register(Codemod{
+		Name:    "dev-watcher",
+		Summary: "dev.Watcher was removed; the dev server rebuilds and restarts the project",
+		Fix:     reportDevWatcher,
+	})
+}
+
+// routeMethods are the Router and Group methods taking a route pattern first
+var routeMethods = map[string]bool{
+	"Get":    true,
+	"Post":   true,
+	"Put":    true,
+	"Patch":  true,
+	"Delete": true,
+	"Group":  true,
+}
+
+// importsBourbon reports whether the file uses the framework at all, so the
+// codemods leave other code alone
+func importsBourbon(f *File) bool {
+	for _, spec := range f.AST.Imports {
+		if strings.HasPrefix(strings.Trim(spec.Path.Value, `"`), bourbonModule) {
+			return true
+		}
+	}
+	return false
+}
+
+// fixRouteParams rewrites "/users/:id" route patterns to "/users/{id}". The
+// router registers routes on net/http's ServeMux, which matches ":id" as literal
+// text, so such routes only ever matched a path that was literally ":id".
+func fixRouteParams(f *File) {
+	if !importsBourbon(f) {
+		return
+	}
+	ast.Inspect(f.AST, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || !routeMethods[sel.Sel.Name] {
+			return true
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		pattern, err := strconv.Unquote(lit.Value)
+		if err != nil || !strings.HasPrefix(pattern, "/") {
+			return true
+		}
+
+		segments := strings.Split(pattern, "/")
+		changed := false
+		for i, segment := range segments {
+			if len(segment) > 1 && segment[0] == ':' {
+				segments[i] = "{" + segment[1:] + "}"
+				changed = true
+			}
+		}
+		if changed {
+			lit.Value = strconv.Quote(strings.Join(segments, "/"))
+			f.Changed()
+		}
+		return true
+	})
+}
+
+// reportDevWatcher points uses of the removed polling watcher at the dev server
+func reportDevWatcher(f *File) {
+	name := f.ImportName(bourbonModule + "bourbon/dev")
+	if name == "" {
+		return
+	}
+	ast.Inspect(f.AST, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if x, ok := sel.X.(*ast.Ident); ok && x.Name == name && (sel.Sel.Name == "Watcher" || sel.Sel.Name == "NewWatcher") {
+			f.Manual(sel.Pos(), "dev.%s was removed: run the project with `bourbon serve` or `go run . dev`, which rebuild and restart it when Go files change", sel.Sel.Name)
+		}
+		return true
+	})
+}
@@ -0,0 +1,163 @@
+// Package upgrade moves a project to a newer Bourbon release. Codemods rewrite
+// the Go code for breaking API changes where the new form is known, and report
+// the usages that need a person to change them. Start it with `bourbon upgrade`.
+package upgrade
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Codemod rewrites one breaking change. Codemods only match the old usage, so
+// running them again, or on a project that is already up to date, changes nothing.
+type Codemod struct {
+	Name    string
+	Summary string
+	Fix     func(f *File)
+}
+
+// File is a Go file a codemod works on
+type File struct {
+	Path string
+	Fset *token.FileSet
+	AST  *ast.File
+
+	codemod string
+	changes map[string]int
+	notes   []Note
+}
+
+// Changed records that the current codemod rewrote part of the file
+func (f *File) Changed() {
+	f.changes[f.codemod]++
+}
+
+// Manual records a usage the current codemod can't rewrite
+func (f *File) Manual(pos token.Pos, format string, args ...any) {
+	position := f.Fset.Position(pos)
+	f.notes = append(f.notes, Note{
+		Position: fmt.Sprintf("%s:%d", f.Path, position.Line),
+		Codemod:  f.codemod,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// ImportName returns the name path is imported under in the file, or "" when
+// the file doesn't import it
+func (f *File) ImportName(path string) string {
+	for _, spec := range f.AST.Imports {
+		if strings.Trim(spec.Path.Value, `"`) != path {
+			continue
+		}
+		if spec.Name != nil {
+			return spec.Name.Name
+		}
+		return path[strings.LastIndex(path, "/")+1:]
+	}
+	return ""
+}
+
+// Change is a file a codemod rewrote
+type Change struct {
+	Path    string
+	Codemod string
+	Count   int
+}
+
+// Note is a manual step
+type Note struct {
+	Position string // file:line, or a file name
+	Codemod  string
+	Message  string
+}
+
+// Report is the outcome of Run
+type Report struct {
+	Changes []Change
+	Manual  []Note
+}
+
+// codemods are applied in order to every file
+var codemods []Codemod
+
+// Register adds a codemod run by every upgrade
+func Register(codemod Codemod) {
+	codemods = append(codemods, codemod)
+}
+
+// Codemods returns the registered codemods in the order they run
+func Codemods() []Codemod {
+	return slices.Clone(codemods)
+}
+
+// Run applies the codemods to the Go files of the project in dir, skipping
+// vendor, testdata and hidden directories. Rewritten files are written back
+// unless dryRun is set.
+func Run(dir string, dryRun bool) (*Report, error) {
+	report := &Report{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != dir && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata" || name == "tmp" || name == "dist") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		return report.fixFile(path, dryRun)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (r *Report) fixFile(path string, dryRun bool) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		r.Manual = append(r.Manual, Note{Position: path, Message: fmt.Sprintf("not checked, it doesn't parse: %v", err)})
+		return nil
+	}
+
+	f := &File{Path: path, Fset: fset, AST: file, changes: make(map[string]int)}
+	for _, codemod := range codemods {
+		f.codemod = codemod.Name
+		codemod.Fix(f)
+		if n := f.changes[codemod.Name]; n > 0 {
+			r.Changes = append(r.Changes, Change{Path: path, Codemod: codemod.Name, Count: n})
+		}
+	}
+	r.Manual = append(r.Manual, f.notes...)
+	if len(f.changes) == 0 || dryRun {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return fmt.Errorf("failed to format %s: %w", path, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), info.Mode().Perm())
+}
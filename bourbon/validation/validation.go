@@ -0,0 +1,226 @@
+// Package validation checks values against rules written as in `validate` struct tags:
+//
+//	type SignupInput struct {
+//	    Email    string `json:"email" validate:"required,email"`
+//	    Password string `json:"password" validate:"required,min=8"`
+//	    Plan     string `json:"plan" validate:"oneof=free pro"`
+//	}
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ishubhamsingh2e/bourbon/bourbon/utils"
+)
+
+// Errors maps field names to messages
+type Errors map[string]string
+
+func (e Errors) Error() string {
+	keys := make([]string, 0, len(e))
+	for key := range e {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key + ": " + e[key]
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Rule is one parsed rule such as "min=8"
+type Rule struct {
+	Name  string
+	Param string
+}
+
+// Rules is a parsed rule list
+type Rules []Rule
+
+// knownRules lists the supported rules and whether they take a parameter
+var knownRules = map[string]bool{
+	"required": false,
+	"email":    false,
+	"url":      false,
+	"min":      true,
+	"max":      true,
+	"len":      true,
+	"oneof":    true,
+}
+
+// Parse parses a comma-separated rule list, e.g. "required,min=3,max=50"
+func Parse(tag string) (Rules, error) {
+	var rules Rules
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, param, _ := strings.Cut(part, "=")
+		needsParam, ok := knownRules[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown validation rule %q", name)
+		}
+		if needsParam && param == "" {
+			return nil, fmt.Errorf("validation rule %q needs a parameter", name)
+		}
+		if name == "min" || name == "max" || name == "len" {
+			if _, err := strconv.ParseFloat(param, 64); err != nil {
+				return nil, fmt.Errorf("validation rule %q needs a number", name)
+			}
+		}
+		rules = append(rules, Rule{Name: name, Param: param})
+	}
+	return rules, nil
+}
+
+// Required reports whether the rules include "required"
+func (rules Rules) Required() bool {
+	for _, rule := range rules {
+		if rule.Name == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns the message of the first rule v breaks, or "" when it passes.
+// Other rules are skipped for zero values unless the value is required.
+func (rules Rules) Check(v reflect.Value) string {
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v = reflect.Value{}
+			break
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.IsZero() {
+		if rules.Required() {
+			return "This field is required."
+		}
+		return ""
+	}
+
+	for _, rule := range rules {
+		if message := rule.check(v); message != "" {
+			return message
+		}
+	}
+	return ""
+}
+
+func (rule Rule) check(v reflect.Value) string {
+	switch rule.Name {
+	case "email":
+		if v.Kind() != reflect.String || !utils.IsValidEmail(v.String()) {
+			return "Enter a valid email address."
+		}
+	case "url":
+		u, err := url.ParseRequestURI(fmt.Sprint(v.Interface()))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "Enter a valid URL."
+		}
+	case "min", "max", "len":
+		limit, _ := strconv.ParseFloat(rule.Param, 64)
+		size, isLength := measure(v)
+		switch {
+		case rule.Name == "min" && size < limit:
+			if isLength {
+				return fmt.Sprintf("Ensure this field has at least %s characters or items.", rule.Param)
+			}
+			return fmt.Sprintf("Ensure this value is greater than or equal to %s.", rule.Param)
+		case rule.Name == "max" && size > limit:
+			if isLength {
+				return fmt.Sprintf("Ensure this field has no more than %s characters or items.", rule.Param)
+			}
+			return fmt.Sprintf("Ensure this value is less than or equal to %s.", rule.Param)
+		case rule.Name == "len" && size != limit:
+			return fmt.Sprintf("Ensure this field has exactly %s characters or items.", rule.Param)
+		}
+	case "oneof":
+		value := fmt.Sprint(v.Interface())
+		for _, choice := range strings.Fields(rule.Param) {
+			if value == choice {
+				return ""
+			}
+		}
+		return fmt.Sprintf("Must be one of: %s.", strings.Join(strings.Fields(rule.Param), ", "))
+	}
+	return ""
+}
+
+// measure returns the length of strings and collections, or the value of numbers
+func measure(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(len([]rune(v.String()))), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), false
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), false
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), false
+	}
+	return 0, false
+}
+
+// Struct validates the fields of a struct, or pointer to one, against their `validate`
+// tags. Errors are keyed by json name; it returns nil when every field passes.
+func Struct(v interface{}) Errors {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	errs := Errors{}
+	validateStruct(value, errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func validateStruct(value reflect.Value, errs Errors) {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			validateStruct(value.Field(i), errs)
+			continue
+		}
+		tag, ok := field.Tag.Lookup("validate")
+		if !ok || !field.IsExported() {
+			continue
+		}
+		rules, err := Parse(tag)
+		if err != nil {
+			errs[FieldName(field)] = err.Error()
+			continue
+		}
+		if message := rules.Check(value.Field(i)); message != "" {
+			errs[FieldName(field)] = message
+		}
+	}
+}
+
+// FieldName returns the json name of a struct field, or its Go name when untagged
+func FieldName(field reflect.StructField) string {
+	if name := strings.Split(field.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+		return name
+	}
+	return field.Name
+}